@@ -23,14 +23,21 @@ func explainTarget(
 	cache *buildexec.Cache, w io.Writer,
 ) int {
 	want := normalizeTargetName(name)
-	for _, bt := range targets {
+	for i, bt := range targets {
 		if len(bt.target.Outputs) == 0 {
 			continue
 		}
 		if normalizeTargetName(bt.target.Outputs[0]) != want {
 			continue
 		}
-		return printExplanation(bt, cfg, cache, w)
+		stin := stalenessFor(bt, cfg)
+		up, err := upstreamActionIDs(targets, targetGraph(targets, cfg), i, cfg, map[int]string{})
+		if err != nil {
+			_, _ = fmt.Fprintf(w, "mdsmith: %v\n", err)
+			return 2
+		}
+		stin.Upstream = up
+		return printExplanation(bt, stin, cache, w)
 	}
 	_, _ = fmt.Fprintf(w, "mdsmith: no target named %q\n", name)
 	return 2
@@ -44,9 +51,8 @@ func normalizeTargetName(p string) string {
 
 // printExplanation writes the full ActionID breakdown for one target.
 func printExplanation(
-	bt buildTarget, cfg *config.Config, cache *buildexec.Cache, w io.Writer,
+	bt buildTarget, stin buildexec.StalenessInput, cache *buildexec.Cache, w io.Writer,
 ) int {
-	stin := stalenessFor(bt, cfg)
 	ex, err := buildexec.Explain(stin)
 	if err != nil {
 		_, _ = fmt.Fprintf(w, "mdsmith: %v\n", err)
@@ -66,6 +72,12 @@ func printExplanation(
 	for _, o := range ex.Outputs {
 		_, _ = fmt.Fprintf(w, "    %s\n", o)
 	}
+	if len(ex.Upstream) > 0 {
+		_, _ = fmt.Fprintf(w, "  upstream:\n")
+		for _, id := range ex.Upstream {
+			_, _ = fmt.Fprintf(w, "    %s\n", id)
+		}
+	}
 	_, _ = fmt.Fprintf(w, "  cache.version: %d\n", ex.CacheVersion)
 	_, _ = fmt.Fprintf(w, "  action-id: %s\n", ex.ActionID)
 	printVerdict(stin, cache, w)
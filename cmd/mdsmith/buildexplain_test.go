@@ -80,7 +80,7 @@ func TestPrintExplanation_ExplainError(t *testing.T) {
 	cfg := buildPassCfg("    cp:\n      command: cp {inputs} {outputs}\n")
 	cache := buildexec.NewCache()
 	var buf strings.Builder
-	code := printExplanation(bt, cfg, cache, &buf)
+	code := printExplanation(bt, stalenessFor(bt, cfg), cache, &buf)
 	assert.Equal(t, 2, code)
 	assert.Contains(t, buf.String(), "mdsmith:")
 }
@@ -104,7 +104,7 @@ func TestPrintExplanation_WithParams(t *testing.T) {
 	}
 	cache := buildexec.NewCache()
 	var buf strings.Builder
-	code := printExplanation(bt, cfg, cache, &buf)
+	code := printExplanation(bt, stalenessFor(bt, cfg), cache, &buf)
 	require.Equal(t, 0, code)
 	out := buf.String()
 	assert.Contains(t, out, "params:")
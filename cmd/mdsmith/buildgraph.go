@@ -0,0 +1,128 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	buildexec "github.com/jeduden/mdsmith/internal/build"
+	"github.com/jeduden/mdsmith/internal/buildgraph"
+	"github.com/jeduden/mdsmith/internal/config"
+)
+
+// targetGraph links each target to the targets whose outputs it reads:
+// its directive inputs and its recipe's resolved default-inputs against
+// every other target's declared outputs.
+func targetGraph(targets []buildTarget, cfg *config.Config) buildgraph.Graph {
+	nodes := make([]buildgraph.Node, len(targets))
+	for i, bt := range targets {
+		stin := stalenessFor(bt, cfg)
+		inputs := append(append([]string(nil), bt.target.Inputs...), stin.DefaultInputs...)
+		nodes[i] = buildgraph.Node{Inputs: inputs, Outputs: bt.target.Outputs}
+	}
+	return buildgraph.New(nodes)
+}
+
+// reportCycles prints one MDS039 line per directive on a dependency
+// cycle and reports whether it found any. A cycle is a hard error like
+// overlapping outputs: no order runs every recipe after its inputs.
+func reportCycles(targets []buildTarget, g buildgraph.Graph, w io.Writer) bool {
+	cycles := g.Cycles()
+	for _, c := range cycles {
+		names := make([]string, 0, len(c)+1)
+		for _, i := range c {
+			names = append(names, targetName(targets[i]))
+		}
+		names = append(names, names[0])
+		msg := "build directive is part of a dependency cycle: " + strings.Join(names, " -> ")
+		for _, i := range c {
+			_, _ = fmt.Fprintf(w, "%s:%d: %s [MDS039]\n", targets[i].file, targets[i].line, msg)
+		}
+	}
+	return len(cycles) > 0
+}
+
+// nodeResult is one target's result in a graph-ordered dispatch, kept
+// for the targets that read its outputs.
+type nodeResult struct {
+	outcome  targetOutcome
+	entry    *buildexec.CacheEntry // cache entry to apply; nil when nothing changed
+	actionID string                // ActionID after the run; "" when no dependent needs it
+	pending  bool                  // stale but not rebuilt (dry-run or check-stale)
+}
+
+// runNode dispatches target i once every target it depends on has a
+// result in results. A failed upstream fails it without running its
+// recipe; a pending upstream makes it stale without checking, since its
+// inputs are about to change. Otherwise the upstream ActionIDs fold into
+// its own, so its verdict and cache entry move with theirs.
+func runNode(
+	builder buildexec.Builder, targets []buildTarget, g buildgraph.Graph, i int,
+	results []nodeResult, cfg *config.Config, opts buildPassOpts,
+	cache *buildexec.Cache, timeout time.Duration, allFinals []string, w io.Writer,
+) nodeResult {
+	bt := targets[i]
+	var upstream []string
+	for _, d := range g.Deps[i] {
+		up := results[d]
+		switch {
+		case up.outcome == outcomeFailed:
+			_, _ = fmt.Fprintf(w, "build %s: FAIL: upstream target %s failed\n",
+				targetLabel(bt), targetName(targets[d]))
+			return nodeResult{outcome: outcomeFailed}
+		case up.pending:
+			_, _ = fmt.Fprintf(w, "build %s: %s\n", targetLabel(bt), buildexec.Stale)
+			if opts.checkStale {
+				return nodeResult{outcome: outcomeStale, pending: true}
+			}
+			return nodeResult{outcome: outcomeNeutral, pending: true}
+		case up.actionID != "":
+			upstream = append(upstream, up.actionID)
+		}
+	}
+	stin := stalenessFor(bt, cfg)
+	stin.Upstream = upstream
+	verdict, serr := targetVerdict(stin, cache, opts)
+	outcome, entry := decideAndRun(builder, bt, opts, stin, verdict, serr, timeout, allFinals, w)
+	res := nodeResult{
+		outcome: outcome,
+		entry:   entry,
+		pending: serr == nil && verdict == buildexec.Stale && (opts.dryRun || opts.checkStale),
+	}
+	if len(g.Dependents[i]) == 0 || opts.noCache || outcome == outcomeFailed || res.pending {
+		return res
+	}
+	if entry != nil {
+		res.actionID = entry.ActionID
+	} else if id, err := buildexec.ComputeActionID(stin); err == nil {
+		res.actionID = id
+	}
+	return res
+}
+
+// upstreamActionIDs returns the ActionIDs of the targets target i reads,
+// each computed over its own upstream in turn. memo holds the IDs found
+// so far. The graph must be acyclic.
+func upstreamActionIDs(
+	targets []buildTarget, g buildgraph.Graph, i int, cfg *config.Config, memo map[int]string,
+) ([]string, error) {
+	var ids []string
+	for _, d := range g.Deps[i] {
+		id, ok := memo[d]
+		if !ok {
+			up, err := upstreamActionIDs(targets, g, d, cfg, memo)
+			if err != nil {
+				return nil, err
+			}
+			stin := stalenessFor(targets[d], cfg)
+			stin.Upstream = up
+			if id, err = buildexec.ComputeActionID(stin); err != nil {
+				return nil, fmt.Errorf("upstream target %s: %w", targetName(targets[d]), err)
+			}
+			memo[d] = id
+		}
+		ids = append(ids, id)
+	}
+	return ids, nil
+}
@@ -0,0 +1,158 @@
+package main
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	buildexec "github.com/jeduden/mdsmith/internal/build"
+)
+
+// chainTargets returns a reference page built from a schema that is
+// itself built from api.yaml. The consumer is declared first, so
+// declared order alone would build it before its input exists.
+func chainTargets(root string) []buildTarget {
+	mk := func(file string, line int, in, out string) buildTarget {
+		return buildTarget{file: file, line: line, target: buildexec.Target{
+			Recipe: "gen", Root: root, Inputs: []string{in}, Outputs: []string{out},
+		}}
+	}
+	return []buildTarget{
+		mk("docs/ref.md", 3, "gen/schema.json", "docs/ref.txt"),
+		mk("gen/README.md", 5, "api.yaml", "gen/schema.json"),
+	}
+}
+
+// concatBuilder writes each target's output as the concatenation of its
+// inputs and records the build order.
+func concatBuilder(order *[]string, mu *sync.Mutex) *mockBuilder {
+	return &mockBuilder{fn: func(_ context.Context, tg buildexec.Target) error {
+		var b strings.Builder
+		for _, in := range tg.Inputs {
+			data, err := os.ReadFile(filepath.Join(tg.Root, in))
+			if err != nil {
+				return err
+			}
+			b.Write(data)
+		}
+		out := filepath.Join(tg.Root, tg.Outputs[0])
+		if err := os.MkdirAll(filepath.Dir(out), 0o755); err != nil {
+			return err
+		}
+		mu.Lock()
+		*order = append(*order, tg.Outputs[0])
+		mu.Unlock()
+		return os.WriteFile(out, []byte(b.String()), 0o644)
+	}}
+}
+
+func TestDispatchTargets_BuildsUpstreamFirst(t *testing.T) {
+	for _, jobs := range []int{1, 4} {
+		root := t.TempDir()
+		writeRootFile(t, root, "api.yaml", "v1")
+		cfg := buildPassCfg("    gen:\n      command: gen {inputs} {outputs}\n")
+		var order []string
+		var mu sync.Mutex
+		var buf strings.Builder
+
+		code := dispatchTargets(concatBuilder(&order, &mu), chainTargets(root), cfg, root,
+			buildPassOpts{jobs: jobs}, buildexec.NewCache(), time.Second, &buf)
+
+		require.Equal(t, 0, code, buf.String())
+		assert.Equal(t, []string{"gen/schema.json", "docs/ref.txt"}, order, "jobs=%d", jobs)
+		data, err := os.ReadFile(filepath.Join(root, "docs", "ref.txt"))
+		require.NoError(t, err)
+		assert.Equal(t, "v1", string(data))
+	}
+}
+
+func TestDispatchTargets_CheckStaleCascades(t *testing.T) {
+	root := t.TempDir()
+	writeRootFile(t, root, "api.yaml", "v1")
+	cfg := buildPassCfg("    gen:\n      command: gen {inputs} {outputs}\n")
+	targets := chainTargets(root)
+	cache := buildexec.NewCache()
+	var order []string
+	var mu sync.Mutex
+	var buf strings.Builder
+	require.Equal(t, 0, dispatchTargets(concatBuilder(&order, &mu), targets, cfg, root,
+		buildPassOpts{}, cache, time.Second, &buf), buf.String())
+
+	buf.Reset()
+	require.Equal(t, 0, dispatchTargets(concatBuilder(&order, &mu), targets, cfg, root,
+		buildPassOpts{checkStale: true}, cache, time.Second, &buf))
+	assert.Empty(t, buf.String(), "a fresh chain reports nothing")
+
+	// Only the schema's input changed; the reference page reads the
+	// schema, so it is stale before the schema is rebuilt.
+	writeRootFile(t, root, "api.yaml", "v2")
+	buf.Reset()
+	code := dispatchTargets(concatBuilder(&order, &mu), targets, cfg, root,
+		buildPassOpts{checkStale: true}, cache, time.Second, &buf)
+	assert.Equal(t, 2, code)
+	assert.Equal(t,
+		"build gen/README.md:5 (gen): STALE\nbuild docs/ref.md:3 (gen): STALE\n", buf.String())
+}
+
+func TestDispatchTargets_UpstreamFailureSkipsDependents(t *testing.T) {
+	root := t.TempDir()
+	cfg := buildPassCfg("    gen:\n      command: gen {inputs} {outputs}\n")
+	var buf strings.Builder
+	var calls []string
+	mock := &mockBuilder{fn: func(_ context.Context, tg buildexec.Target) error {
+		calls = append(calls, tg.Outputs[0])
+		return assert.AnError
+	}}
+	writeRootFile(t, root, "api.yaml", "v1")
+
+	code := dispatchTargets(mock, chainTargets(root), cfg, root,
+		buildPassOpts{noCache: true}, buildexec.NewCache(), time.Second, &buf)
+
+	assert.Equal(t, 2, code)
+	assert.Equal(t, []string{"gen/schema.json"}, calls)
+	assert.Contains(t, buf.String(),
+		"build docs/ref.md:3 (gen): FAIL: upstream target gen/schema.json failed\n")
+}
+
+func TestReportCycles(t *testing.T) {
+	cfg := buildPassCfg("    gen:\n      command: gen {inputs} {outputs}\n")
+	targets := chainTargets("/repo")
+	targets[1].target.Inputs = []string{"docs/ref.txt"}
+	var buf strings.Builder
+
+	assert.True(t, reportCycles(targets, targetGraph(targets, cfg), &buf))
+	msg := "build directive is part of a dependency cycle: docs/ref.txt -> gen/schema.json -> docs/ref.txt [MDS039]\n"
+	assert.Equal(t, "docs/ref.md:3: "+msg+"gen/README.md:5: "+msg, buf.String())
+
+	buf.Reset()
+	assert.False(t, reportCycles(chainTargets("/repo"), targetGraph(chainTargets("/repo"), cfg), &buf))
+	assert.Empty(t, buf.String())
+}
+
+func TestExplainTarget_PrintsUpstream(t *testing.T) {
+	root := t.TempDir()
+	writeRootFile(t, root, "api.yaml", "v1")
+	writeRootFile(t, root, "gen/schema.json", "v1")
+	cfg := buildPassCfg("    gen:\n      command: gen {inputs} {outputs}\n")
+	var buf strings.Builder
+
+	code := explainTarget(chainTargets(root), "docs/ref.txt", cfg, buildexec.NewCache(), &buf)
+
+	require.Equal(t, 0, code, buf.String())
+	assert.Contains(t, buf.String(), "  upstream:\n    sha256-")
+}
+
+// writeRootFile writes content to root/rel, creating parent directories.
+func writeRootFile(t *testing.T, root, rel, content string) {
+	t.Helper()
+	p := filepath.Join(root, filepath.FromSlash(rel))
+	require.NoError(t, os.MkdirAll(filepath.Dir(p), 0o755))
+	require.NoError(t, os.WriteFile(p, []byte(content), 0o644))
+}
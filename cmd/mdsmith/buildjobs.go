@@ -10,21 +10,16 @@ import (
 	"github.com/jeduden/mdsmith/internal/config"
 )
 
-// concurrentResult is one target's outcome plus the cache entry to apply.
-// Each worker writes its result into the slot at its target index, so the
-// post-dispatch loop reads them back in declared order.
-type concurrentResult struct {
-	outcome targetOutcome
-	entry   *buildexec.CacheEntry
-}
-
-// runConcurrent dispatches targets through a worker pool of opts.jobs
-// goroutines. Each worker computes its verdict and runs its recipe; the
-// recipe streams are written through a mutex-synchronized writer so lines
-// stay coherent across concurrent recipes. Cache entries are collected
-// and applied serially in declared order after every recipe finishes, so
-// the shared cache is never touched concurrently. Plan 103 rejects
-// overlapping outputs at load, so the targets' writes are disjoint.
+// runConcurrent dispatches targets through a pool of opts.jobs
+// goroutines in dependency order: a target starts once every target
+// whose outputs it reads has finished, so independent branches of the
+// target graph run in parallel. Each worker computes its verdict and
+// runs its recipe; the recipe streams are written through a
+// mutex-synchronized writer so lines stay coherent across concurrent
+// recipes. Workers only read the shared cache; entries are collected
+// and applied serially in declared order after every recipe finishes.
+// Plan 103 rejects overlapping outputs at load, so the targets' writes
+// are disjoint.
 func runConcurrent(
 	builder buildexec.Builder, targets []buildTarget, cfg *config.Config,
 	opts buildPassOpts, cache *buildexec.Cache, timeout time.Duration,
@@ -40,31 +35,39 @@ func runConcurrent(
 		}
 	}
 
-	// Verdicts read the shared cache; compute them serially up front so the
-	// concurrent workers touch no shared cache state.
-	stins := make([]buildexec.StalenessInput, len(targets))
-	verdicts := make([]buildexec.Verdict, len(targets))
-	verdictErrs := make([]error, len(targets))
-	for i, bt := range targets {
-		stins[i] = stalenessFor(bt, cfg)
-		verdicts[i], verdictErrs[i] = targetVerdict(stins[i], cache, opts)
+	// A worker writes only its own slot and reads only the slots of the
+	// targets it depends on, which finished before it was started.
+	g := targetGraph(targets, cfg)
+	results := make([]nodeResult, len(targets))
+	remaining := make([]int, len(targets))
+	var ready []int
+	for i, deps := range g.Deps {
+		remaining[i] = len(deps)
+		if remaining[i] == 0 {
+			ready = append(ready, i)
+		}
 	}
-
-	results := make([]concurrentResult, len(targets))
-	sem := make(chan struct{}, opts.jobs)
-	var wg sync.WaitGroup
-	for i, bt := range targets {
-		wg.Add(1)
-		sem <- struct{}{}
-		go func(i int, bt buildTarget) {
-			defer wg.Done()
-			defer func() { <-sem }()
-			outcome, entry := decideAndRun(
-				builder, bt, opts, stins[i], verdicts[i], verdictErrs[i], timeout, allFinals, sw)
-			results[i] = concurrentResult{outcome: outcome, entry: entry}
-		}(i, bt)
+	done := make(chan int)
+	running := 0
+	for len(ready) > 0 || running > 0 {
+		for running < opts.jobs && len(ready) > 0 {
+			i := ready[0]
+			ready = ready[1:]
+			running++
+			go func(i int) {
+				results[i] = runNode(builder, targets, g, i, results, cfg, opts, cache, timeout, allFinals, sw)
+				done <- i
+			}(i)
+		}
+		i := <-done
+		running--
+		for _, d := range g.Dependents[i] {
+			remaining[d]--
+			if remaining[d] == 0 {
+				ready = append(ready, d)
+			}
+		}
 	}
-	wg.Wait()
 
 	for _, r := range results {
 		fold(r.outcome)
@@ -11,6 +11,7 @@ import (
 
 	"github.com/jeduden/mdsmith/internal/archetype/gensection"
 	buildexec "github.com/jeduden/mdsmith/internal/build"
+	"github.com/jeduden/mdsmith/internal/buildgraph"
 	"github.com/jeduden/mdsmith/internal/bytelimit"
 	"github.com/jeduden/mdsmith/internal/config"
 	"github.com/jeduden/mdsmith/internal/lint"
@@ -40,6 +41,10 @@ type buildPassOpts struct {
 	verify             bool          // --build-verify: run each recipe twice and diff outputs
 	jobs               int           // --build-jobs N: concurrent recipe dispatch (default 1)
 	explain            string        // --build-explain TARGET: print ActionID inputs; run nothing
+	sandbox            bool          // --build-sandbox: run recipes sandboxed even if config does not ask
+
+	remote       buildexec.RemoteCache // build.cache.url backend; nil when unset
+	remoteUpload bool                  // upload rebuilt targets to remote (build.cache.read-only unset)
 }
 
 // buildTarget pairs a resolved build.Target with the file and line it
@@ -172,6 +177,10 @@ func runBuildPass(
 		_, _ = fmt.Fprintf(w, "mdsmith: %v\n", err)
 		return 2
 	}
+	// So is a dependency cycle: no order builds every input first.
+	if reportCycles(targets, targetGraph(targets, cfg), w) {
+		return 2
+	}
 
 	if opts.explain != "" {
 		cache := loadBuildCache(root, opts, w)
@@ -189,13 +198,18 @@ func runBuildPass(
 		return 2
 	}
 
-	builder := buildexec.NewCustomBuilderExec(recipes, buildExecConfig(cfg))
+	ec := buildExecConfig(cfg)
+	if opts.sandbox {
+		ec.Sandbox.Enabled = true
+	}
+	builder := buildexec.NewCustomBuilderExec(recipes, ec)
 	timeout := opts.timeout
 	if timeout <= 0 {
 		timeout = 30 * time.Second
 	}
 
 	cache := loadBuildCache(root, opts, w)
+	opts = withRemoteCache(opts, cfg, root, w)
 	if !opts.noCache {
 		if err := pruneOrphanLogsFn(root, cache); err != nil {
 			_, _ = fmt.Fprintf(w, "mdsmith: %v\n", err)
@@ -218,7 +232,7 @@ func ensureTrusted(opts buildPassOpts, cfgPath, root string, w io.Writer) bool {
 	if trustPath == "" {
 		trustPath = buildexec.ConfigPathForRoot(root)
 	}
-	if trust := buildexec.CheckTrust(trustPath, envIsSet); !trust.Trusted {
+	if trust := buildexec.CheckTrust(trustPath, buildexec.EnvIsSet); !trust.Trusted {
 		_, _ = fmt.Fprintf(w, "mdsmith: %s\n", trust.Reason)
 		return false
 	}
@@ -318,8 +332,18 @@ func allFresh(targets []buildTarget, cfg *config.Config, cache *buildexec.Cache,
 	if opts.force || opts.noCache {
 		return false
 	}
-	for _, bt := range targets {
+	g := targetGraph(targets, cfg)
+	if len(g.Order()) < len(targets) {
+		return false
+	}
+	ids := map[int]string{}
+	for i, bt := range targets {
 		stin := stalenessFor(bt, cfg)
+		up, err := upstreamActionIDs(targets, g, i, cfg, ids)
+		if err != nil {
+			return false
+		}
+		stin.Upstream = up
 		verdict, err := buildexec.CheckStaleness(stin, cache)
 		if err != nil {
 			return false
@@ -378,7 +402,7 @@ func loadBuildCache(root string, opts buildPassOpts, w io.Writer) *buildexec.Cac
 // recipe's default-inputs (param tokens to their relative path values).
 func stalenessFor(bt buildTarget, cfg *config.Config) buildexec.StalenessInput {
 	recipeCfg := cfg.Build.Recipes[bt.target.Recipe]
-	defaults := resolveDefaultInputs(recipeCfg.DefaultInputs, bt.target.Params)
+	defaults := buildgraph.ResolveDefaultInputs(recipeCfg.DefaultInputs, bt.target.Params)
 	return buildexec.StalenessInput{
 		Target:        bt.target,
 		Command:       recipeCfg.Command,
@@ -386,27 +410,6 @@ func stalenessFor(bt buildTarget, cfg *config.Config) buildexec.StalenessInput {
 	}
 }
 
-// resolveDefaultInputs maps each default-inputs entry to a relative path.
-// A {param} token resolves to the param's value (the relative path it
-// supplies); a literal entry passes through unchanged.
-func resolveDefaultInputs(entries []string, params map[string]string) []string {
-	if len(entries) == 0 {
-		return nil
-	}
-	out := make([]string, 0, len(entries))
-	for _, e := range entries {
-		if len(e) > 2 && e[0] == '{' && e[len(e)-1] == '}' {
-			name := e[1 : len(e)-1]
-			if v, ok := params[name]; ok {
-				out = append(out, v)
-				continue
-			}
-		}
-		out = append(out, e)
-	}
-	return out
-}
-
 // targetOutcome is the per-target result of one dispatch loop iteration.
 type targetOutcome int
 
@@ -418,9 +421,10 @@ const (
 )
 
 // dispatchTargets runs the staleness check, dispatch, and cache refresh
-// loop. It returns the build pass exit code. With opts.jobs > 1 recipes
-// run concurrently; cache entries apply serially in declared order after
-// all recipes finish.
+// loop in dependency order: a target whose inputs another target writes
+// runs after it. It returns the build pass exit code. With opts.jobs > 1
+// independent recipes run concurrently; cache entries apply serially in
+// declared order after all recipes finish.
 func dispatchTargets(
 	builder buildexec.Builder, targets []buildTarget, cfg *config.Config,
 	root string, opts buildPassOpts, cache *buildexec.Cache,
@@ -448,8 +452,14 @@ func dispatchTargets(
 	if opts.jobs > 1 && !opts.checkStale && !opts.dryRun {
 		runConcurrent(builder, targets, cfg, opts, cache, timeout, w, fold)
 	} else {
-		for _, bt := range targets {
-			fold(dispatchOne(builder, bt, cfg, opts, cache, timeout, w))
+		g := targetGraph(targets, cfg)
+		results := make([]nodeResult, len(targets))
+		for _, i := range g.Order() {
+			results[i] = runNode(builder, targets, g, i, results, cfg, opts, cache, timeout, nil, w)
+			if results[i].entry != nil {
+				cache.Put(*results[i].entry)
+			}
+			fold(results[i].outcome)
 		}
 	}
 
@@ -471,22 +481,6 @@ func dispatchTargets(
 	return 0
 }
 
-// dispatchOne handles a single target end to end against a shared cache,
-// used by the serial path. It computes the verdict, reports or rebuilds,
-// and applies the cache entry directly.
-func dispatchOne(
-	builder buildexec.Builder, bt buildTarget, cfg *config.Config,
-	opts buildPassOpts, cache *buildexec.Cache, timeout time.Duration, w io.Writer,
-) targetOutcome {
-	stin := stalenessFor(bt, cfg)
-	verdict, serr := targetVerdict(stin, cache, opts)
-	outcome, entry := decideAndRun(builder, bt, opts, stin, verdict, serr, timeout, nil, w)
-	if entry != nil {
-		cache.Put(*entry)
-	}
-	return outcome
-}
-
 // decideAndRun decides the per-target action from a precomputed verdict
 // and, when a rebuild is warranted, runs the recipe. It returns the
 // outcome and an optional cache entry to apply (nil when nothing changed
@@ -499,7 +493,7 @@ func decideAndRun(
 	opts buildPassOpts, stin buildexec.StalenessInput, verdict buildexec.Verdict, verdictErr error,
 	timeout time.Duration, allFinals []string, w io.Writer,
 ) (targetOutcome, *buildexec.CacheEntry) {
-	label := fmt.Sprintf("%s:%d (%s)", bt.file, bt.line, bt.target.Recipe)
+	label := targetLabel(bt)
 
 	if verdictErr != nil {
 		_, _ = fmt.Fprintf(w, "build %s: FAIL: %v\n", label, verdictErr)
@@ -530,6 +524,10 @@ func decideAndRun(
 			return outcomeFailed, nil
 		}
 	}
+	if entry := restoreFromRemote(bt, stin, id, opts, timeout, w); entry != nil {
+		_, _ = fmt.Fprintf(w, "RESTORED %s\n", targetName(bt))
+		return outcomeRebuilt, entry
+	}
 	res := runOneTarget(builder, bt, id, opts, timeout, allFinals, w)
 	if res.Err != nil {
 		reportBuildFailure(bt, res, w)
@@ -543,10 +541,17 @@ func decideAndRun(
 		_, _ = fmt.Fprintf(w, "build %s: FAIL: %v\n", label, err)
 		return outcomeFailed, nil
 	}
+	uploadToRemote(bt, entry, opts, timeout, w)
 	_, _ = fmt.Fprintf(w, "OK %s\n", targetName(bt))
 	return outcomeRebuilt, entry
 }
 
+// targetLabel returns the target's source label for the per-target
+// summary: file, line, and recipe.
+func targetLabel(bt buildTarget) string {
+	return fmt.Sprintf("%s:%d (%s)", bt.file, bt.line, bt.target.Recipe)
+}
+
 // targetName returns the target's display name: its first declared output
 // path, or the source label when no output is declared.
 func targetName(bt buildTarget) string {
@@ -639,25 +644,10 @@ func buildExecConfig(cfg *config.Config) buildexec.ExecConfig {
 	return buildexec.ExecConfig{
 		Path:           cfg.Build.Exec.Path,
 		EnvPassThrough: cfg.Build.Exec.EnvPassThrough,
-	}
-}
-
-// envIsSet reports whether the named environment variable is set to a
-// truthy value. It is the production environment lookup passed to the
-// trust gate, so an explicit MDSMITH_TRUST_BUILD=0 (or false/no/off)
-// does NOT grant trust — only an affirmative value does. This avoids the
-// footgun where a user who sets the variable to a disabling value still
-// has the build pass run.
-func envIsSet(name string) bool {
-	v := strings.TrimSpace(os.Getenv(name))
-	if v == "" {
-		return false
-	}
-	switch strings.ToLower(v) {
-	case "0", "false", "no", "off":
-		return false
-	default:
-		return true
+		Sandbox: buildexec.Sandbox{
+			Enabled:      cfg.Build.Exec.Sandbox.Enabled,
+			AllowNetwork: cfg.Build.Exec.Sandbox.AllowNetwork,
+		},
 	}
 }
 
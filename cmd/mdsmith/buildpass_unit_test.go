@@ -3,6 +3,7 @@ package main
 import (
 	"context"
 	"errors"
+	"io"
 	"os"
 	"path/filepath"
 	"runtime"
@@ -44,23 +45,6 @@ func buildPassCfg(recipesYAML string) *config.Config {
 	return cfg
 }
 
-func TestEnvIsSet_Truthiness(t *testing.T) {
-	const name = "MDSMITH_TEST_TRUST_FLAG"
-	truthy := []string{"1", "true", "yes", "on", "anything", " 1 ", "TRUE"}
-	falsy := []string{"", "0", "false", "no", "off", "FALSE", " 0 ", "  "}
-	for _, v := range truthy {
-		t.Setenv(name, v)
-		assert.True(t, envIsSet(name), "value %q should grant", v)
-	}
-	for _, v := range falsy {
-		t.Setenv(name, v)
-		assert.False(t, envIsSet(name), "value %q should not grant", v)
-	}
-	// Unset is falsy.
-	require.NoError(t, os.Unsetenv(name))
-	assert.False(t, envIsSet(name))
-}
-
 // trustRoot writes a .mdsmith.yml file and an identical trust marker in
 // root so the build pass trust gate is satisfied. Unit tests that drive
 // runBuildPass to actually execute a recipe call this; the file bytes are
@@ -328,27 +312,21 @@ func TestCollectBuildTargets_MalformedDirectiveSkipped(t *testing.T) {
 	assert.Empty(t, targets, "directive with non-string recipe param must be skipped")
 }
 
-// --- resolveDefaultInputs ---
-
-func TestResolveDefaultInputs_ParamToken_Resolved(t *testing.T) {
-	out := resolveDefaultInputs([]string{"{tape}"}, map[string]string{"tape": "demo.tape"})
-	assert.Equal(t, []string{"demo.tape"}, out)
-}
+// --- dispatchOne ---
 
-func TestResolveDefaultInputs_ParamToken_NotInParams_PassesThrough(t *testing.T) {
-	// A {token} whose name is absent from params falls through as a literal.
-	out := resolveDefaultInputs([]string{"{unknown}"}, map[string]string{})
-	assert.Equal(t, []string{"{unknown}"}, out)
-}
-
-func TestResolveDefaultInputs_LiteralEntry(t *testing.T) {
-	out := resolveDefaultInputs([]string{"assets/logo.svg"}, map[string]string{"tape": "demo.tape"})
-	assert.Equal(t, []string{"assets/logo.svg"}, out)
-}
-
-func TestResolveDefaultInputs_Empty(t *testing.T) {
-	assert.Nil(t, resolveDefaultInputs(nil, nil))
-	assert.Nil(t, resolveDefaultInputs([]string{}, nil))
+// dispatchOne runs a single target through the graph-ordered dispatch
+// path and applies its cache entry, as the serial loop does.
+func dispatchOne(
+	builder buildexec.Builder, bt buildTarget, cfg *config.Config,
+	opts buildPassOpts, cache *buildexec.Cache, timeout time.Duration, w io.Writer,
+) targetOutcome {
+	targets := []buildTarget{bt}
+	r := runNode(builder, targets, targetGraph(targets, cfg), 0, make([]nodeResult, 1),
+		cfg, opts, cache, timeout, nil, w)
+	if r.entry != nil {
+		cache.Put(*r.entry)
+	}
+	return r.outcome
 }
 
 // --- loadBuildCache ---
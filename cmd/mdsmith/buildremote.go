@@ -0,0 +1,75 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"time"
+
+	buildexec "github.com/jeduden/mdsmith/internal/build"
+	"github.com/jeduden/mdsmith/internal/config"
+)
+
+// withRemoteCache opens the shared build cache named by build.cache.url
+// and attaches it to opts. Runs that execute no recipe, and
+// --build-no-cache, use no shared cache. A cache that cannot be opened
+// is reported and the build proceeds without it.
+func withRemoteCache(opts buildPassOpts, cfg *config.Config, root string, w io.Writer) buildPassOpts {
+	if cfg.Build.Cache.URL == "" || opts.noCache || opts.dryRun || opts.checkStale {
+		return opts
+	}
+	rc, err := buildexec.OpenRemoteCache(cfg.Build.Cache.URL, root)
+	if err != nil {
+		_, _ = fmt.Fprintf(w, "mdsmith: %v; building without the shared cache\n", err)
+		return opts
+	}
+	opts.remote = rc
+	opts.remoteUpload = !cfg.Build.Cache.ReadOnly
+	return opts
+}
+
+// restoreFromRemote tries to restore a stale target's outputs from the
+// shared cache under its ActionID. On a hit it returns the cache entry
+// recorded from the restored files. A miss returns nil; so does any
+// error, which is reported as a warning so the recipe runs instead.
+func restoreFromRemote(
+	bt buildTarget, stin buildexec.StalenessInput, id string,
+	opts buildPassOpts, timeout time.Duration, w io.Writer,
+) *buildexec.CacheEntry {
+	if opts.remote == nil || opts.force || id == "" {
+		return nil
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+	ok, err := buildexec.RestoreFromRemote(ctx, opts.remote, bt.target, id)
+	if err != nil {
+		_, _ = fmt.Fprintf(w, "mdsmith: shared build cache: %s: %v; running the recipe\n", targetName(bt), err)
+		return nil
+	}
+	if !ok {
+		return nil
+	}
+	entry, err := buildCacheEntry(stin, opts, false)
+	if err != nil || entry == nil || entry.ActionID != id {
+		// The inputs changed while the outputs were restored; the recipe
+		// decides what the outputs should be.
+		return nil
+	}
+	return entry
+}
+
+// uploadToRemote stores a rebuilt target's outputs in the shared cache.
+// An unstable target, whose recipe is not deterministic, is not shared.
+// A failed upload is a warning: the local build already succeeded.
+func uploadToRemote(
+	bt buildTarget, entry *buildexec.CacheEntry, opts buildPassOpts, timeout time.Duration, w io.Writer,
+) {
+	if opts.remote == nil || !opts.remoteUpload || entry == nil || entry.Unstable {
+		return
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+	if err := buildexec.UploadToRemote(ctx, opts.remote, bt.target.Root, *entry); err != nil {
+		_, _ = fmt.Fprintf(w, "mdsmith: shared build cache: %s: upload failed: %v\n", targetName(bt), err)
+	}
+}
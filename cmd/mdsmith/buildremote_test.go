@@ -0,0 +1,106 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	buildexec "github.com/jeduden/mdsmith/internal/build"
+)
+
+// remoteCfg returns a config with the "gen" recipe and a shared cache
+// at dir.
+func remoteCfg(dir string, readOnly bool) string {
+	yml := "    gen:\n      command: gen {inputs} {outputs}\n  cache:\n    url: " + dir + "\n"
+	if readOnly {
+		yml += "    read-only: true\n"
+	}
+	return yml
+}
+
+// runWithRemote runs the build pass over chainTargets(root) with the
+// shared cache configured by cfgYAML, and returns the exit code, the
+// output, and the outputs the builder produced.
+func runWithRemote(t *testing.T, root, cfgYAML string) (int, string, []string) {
+	t.Helper()
+	cfg := buildPassCfg(cfgYAML)
+	var built []string
+	var mu sync.Mutex
+	var buf strings.Builder
+	opts := withRemoteCache(buildPassOpts{}, cfg, root, &buf)
+	code := dispatchTargets(concatBuilder(&built, &mu), chainTargets(root), cfg, root,
+		opts, buildexec.NewCache(), time.Second, &buf)
+	return code, buf.String(), built
+}
+
+func TestDispatchTargets_RestoresFromSharedCache(t *testing.T) {
+	shared := t.TempDir()
+	first := t.TempDir()
+	writeRootFile(t, first, "api.yaml", "v1")
+	code, out, built := runWithRemote(t, first, remoteCfg(shared, false))
+	require.Equal(t, 0, code, out)
+	assert.Len(t, built, 2)
+
+	// A fresh clone with a cold local cache restores both targets,
+	// upstream first, without running a recipe.
+	clone := t.TempDir()
+	writeRootFile(t, clone, "api.yaml", "v1")
+	code, out, built = runWithRemote(t, clone, remoteCfg(shared, false))
+	require.Equal(t, 0, code, out)
+	assert.Empty(t, built)
+	assert.Equal(t, "RESTORED gen/schema.json\nRESTORED docs/ref.txt\n", out)
+	data, err := os.ReadFile(filepath.Join(clone, "docs", "ref.txt"))
+	require.NoError(t, err)
+	assert.Equal(t, "v1", string(data))
+}
+
+func TestDispatchTargets_ReadOnlySharedCacheDoesNotUpload(t *testing.T) {
+	shared := t.TempDir()
+	root := t.TempDir()
+	writeRootFile(t, root, "api.yaml", "v1")
+	code, out, _ := runWithRemote(t, root, remoteCfg(shared, true))
+	require.Equal(t, 0, code, out)
+	entries, err := os.ReadDir(shared)
+	require.NoError(t, err)
+	assert.Empty(t, entries)
+}
+
+func TestDispatchTargets_CorruptSharedCacheRunsRecipe(t *testing.T) {
+	shared := t.TempDir()
+	first := t.TempDir()
+	writeRootFile(t, first, "api.yaml", "v1")
+	code, out, _ := runWithRemote(t, first, remoteCfg(shared, false))
+	require.Equal(t, 0, code, out)
+	blobs, err := os.ReadDir(filepath.Join(shared, "cas"))
+	require.NoError(t, err)
+	for _, b := range blobs {
+		require.NoError(t, os.WriteFile(filepath.Join(shared, "cas", b.Name()), []byte("bad"), 0o644))
+	}
+
+	clone := t.TempDir()
+	writeRootFile(t, clone, "api.yaml", "v1")
+	code, out, built := runWithRemote(t, clone, remoteCfg(shared, false))
+	require.Equal(t, 0, code, out)
+	assert.Equal(t, []string{"gen/schema.json"}, built)
+	assert.Contains(t, out, "mdsmith: shared build cache: gen/schema.json: restoring gen/schema.json: "+
+		"integrity check failed")
+	// Both outputs hold "v1", so they share one blob; rebuilding the
+	// schema uploads it again and repairs it for the reference page.
+	assert.Contains(t, out, "OK gen/schema.json\nRESTORED docs/ref.txt\n")
+}
+
+func TestWithRemoteCache_SkippedWhenNoRecipeRuns(t *testing.T) {
+	cfg := buildPassCfg(remoteCfg(t.TempDir(), false))
+	var buf strings.Builder
+	for _, opts := range []buildPassOpts{{noCache: true}, {dryRun: true}, {checkStale: true}} {
+		assert.Nil(t, withRemoteCache(opts, cfg, t.TempDir(), &buf).remote)
+	}
+	assert.NotNil(t, withRemoteCache(buildPassOpts{}, cfg, t.TempDir(), &buf).remote)
+	assert.Empty(t, buf.String())
+}
@@ -268,3 +268,50 @@ func TestE2E_Build_TimeoutKillsSpawnedChild(t *testing.T) {
 		return !unixProcessAlive(childPID)
 	}, 6*time.Second, 100*time.Millisecond, "spawned child must not be orphaned")
 }
+
+// skipWithoutLandlock skips a sandbox test whose build failed only
+// because the kernel has no Landlock.
+func skipWithoutLandlock(t *testing.T, stderr string) {
+	t.Helper()
+	if strings.Contains(stderr, "landlock is not available") {
+		t.Skip("landlock is not available on this kernel")
+	}
+}
+
+func TestE2E_Build_SandboxDeniesWriteOutsideStaging(t *testing.T) {
+	if runtime.GOOS != "linux" {
+		t.Skip("the build sandbox is Linux only")
+	}
+	dir := writeBuildRepo(t, "")
+	script := "#!/bin/sh\nprintf ok > \"$1\"\nprintf evil > \"" +
+		filepath.Join(dir, "sneaky.txt") + "\"\n"
+	scriptPath := filepath.Join(dir, "run.sh")
+	require.NoError(t, os.WriteFile(scriptPath, []byte(script), 0o755))
+	reconfigureRecipe(t, dir, "    sneaky:\n      command: "+scriptPath+" {outputs}\n"+
+		"  exec:\n    sandbox:\n      enabled: true\n")
+	writeFixture(t, dir, "doc.md", buildDirective("sneaky", "", "out.txt"))
+
+	_, stderr, code := runBinaryInDir(t, dir, "", "fix", "--no-color", "--build-only", "doc.md")
+	skipWithoutLandlock(t, stderr)
+	assert.Equal(t, 2, code, stderr)
+	assert.Contains(t, stderr, "the build sandbox allows writes only under the staging dir")
+	assert.NoFileExists(t, filepath.Join(dir, "sneaky.txt"))
+	assert.NoFileExists(t, filepath.Join(dir, "out.txt"))
+}
+
+func TestE2E_Build_SandboxFlagRunsWellBehavedRecipe(t *testing.T) {
+	if runtime.GOOS != "linux" {
+		t.Skip("the build sandbox is Linux only")
+	}
+	dir := writeBuildRepo(t, "    copy:\n      command: cp {inputs} {outputs}\n")
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "src.txt"), []byte("hello"), 0o644))
+	writeFixture(t, dir, "doc.md", buildDirective("copy", "src.txt", "dst.txt"))
+
+	_, stderr, code := runBinaryInDir(t, dir, "",
+		"fix", "--no-color", "--build-only", "--build-sandbox", "doc.md")
+	skipWithoutLandlock(t, stderr)
+	require.Equal(t, 0, code, stderr)
+	got, err := os.ReadFile(filepath.Join(dir, "dst.txt"))
+	require.NoError(t, err)
+	assert.Equal(t, "hello", string(got))
+}
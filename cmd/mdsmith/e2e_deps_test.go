@@ -144,6 +144,22 @@ func TestE2E_Deps_BuildInputs_Incoming(t *testing.T) {
 	assert.Contains(t, lines[0], "build")
 }
 
+func TestE2E_Deps_SnippetInclude_Incoming(t *testing.T) {
+	dir := t.TempDir()
+	require.NoError(t, os.MkdirAll(filepath.Join(dir, ".git"), 0o755))
+	require.NoError(t, os.MkdirAll(filepath.Join(dir, "src"), 0o755))
+	wf := func(rel, body string) {
+		require.NoError(t, os.WriteFile(filepath.Join(dir, rel), []byte(body), 0o644))
+	}
+	wf(".mdsmith.yml", "files:\n  - \"**/*.md\"\n")
+	wf("src/client.go", "package src\n\n// region setup\nvar x = 1\n// endregion\n")
+	wf("guide.md", "# Guide\n\n<?include\nfile: src/client.go\nregion: setup\n?>\n<?/include?>\n")
+	stdout, _, code := runBinaryInDir(t, dir, "", "deps", "src/client.go", "--incoming")
+	require.Equal(t, 0, code, "stdout=%q", stdout)
+	assert.Equal(t, "guide.md:3: include src/client.go", strings.TrimSpace(stdout),
+		"a source file pulled in by region is an include edge target")
+}
+
 func TestE2E_Deps_HelpFlag(t *testing.T) {
 	dir := setupDepsWorkspace(t)
 	_, stderr, code := runBinaryInDir(t, dir, "", "deps", "--help")
@@ -2,6 +2,7 @@ package main_test
 
 import (
 	"encoding/json"
+	"strings"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
@@ -308,3 +309,72 @@ func TestE2E_Extract_InlineSoftBreak(t *testing.T) {
 	}
 	assert.Equal(t, want, got)
 }
+
+func TestE2E_ExtractAll_JSONLSkipsNonConformant(t *testing.T) {
+	dir := kindsTestDir(t, extractCfg, map[string]string{
+		"recipes/cake.md": conformantRecipe,
+		"recipes/flat.md": nonConformantRecipe,
+		"recipes/pie.md":  conformantRecipe,
+		"README.md":       "# Readme\n",
+	})
+	stdout, stderr, code := runBinaryInDir(t, dir, "", "extract", "recipe", "--all")
+	assert.Equal(t, 1, code)
+	assert.Contains(t, stderr, "recipes/flat.md")
+	assert.Contains(t, stderr, "skipped 1 of 3 files")
+
+	lines := strings.Split(strings.TrimSuffix(stdout, "\n"), "\n")
+	require.Len(t, lines, 2, stdout)
+	var rec struct {
+		Path string         `json:"path"`
+		Kind string         `json:"kind"`
+		Data map[string]any `json:"data"`
+	}
+	require.NoError(t, json.Unmarshal([]byte(lines[0]), &rec))
+	assert.Equal(t, "recipes/cake.md", rec.Path)
+	assert.Equal(t, "recipe", rec.Kind)
+	assert.Equal(t, expectedRecipeTree(), rec.Data)
+	assert.Contains(t, lines[1], `"path":"recipes/pie.md"`)
+}
+
+func TestE2E_ExtractAll_CombinedArrays(t *testing.T) {
+	dir := kindsTestDir(t, extractCfg, map[string]string{
+		"recipes/cake.md": "---\nstatus: ready\n---\n" + conformantRecipe,
+		"recipes/pie.md":  conformantRecipe,
+	})
+	stdout, stderr, code := runBinaryInDir(t, dir, "", "extract", "recipe", "--all",
+		"--format", "json", "--query", `status: "ready"`, "recipes")
+	require.Equal(t, 0, code, "stderr=%s", stderr)
+	var recs []map[string]any
+	require.NoError(t, json.Unmarshal([]byte(stdout), &recs))
+	require.Len(t, recs, 1)
+	assert.Equal(t, "recipes/cake.md", recs[0]["path"])
+
+	stdout, stderr, code = runBinaryInDir(t, dir, "", "extract", "recipe", "--all", "--format", "msgpack")
+	require.Equal(t, 0, code, "stderr=%s", stderr)
+	var mRecs []map[string]any
+	require.NoError(t, msgpack.Unmarshal([]byte(stdout), &mRecs))
+	require.Len(t, mRecs, 2)
+	assert.Equal(t, "recipes/pie.md", mRecs[1]["path"])
+}
+
+func TestE2E_ExtractAll_UsageErrors(t *testing.T) {
+	dir := kindsTestDir(t, extractCfg, map[string]string{
+		"recipes/cake.md": conformantRecipe,
+	})
+	cases := map[string]struct {
+		args []string
+		want string
+	}{
+		"no kind":      {[]string{"extract", "--all"}, "extract --all requires <kind>"},
+		"unknown kind": {[]string{"extract", "nope", "--all"}, `unknown kind "nope"`},
+		"bad query":    {[]string{"extract", "recipe", "--all", "--query", "status: ["}, "--query:"},
+		"query alone":  {[]string{"extract", "recipe", "recipes/cake.md", "--query", "a: 1"}, "--query requires --all"},
+	}
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			_, stderr, code := runBinaryInDir(t, dir, "", tc.args...)
+			assert.Equal(t, 2, code)
+			assert.Contains(t, stderr, tc.want)
+		})
+	}
+}
@@ -0,0 +1,104 @@
+package main_test
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// setupGraphWorkspace builds a workspace where README links to the
+// index, the index catalogs the guides, two guides include each other,
+// a wikilink reaches notes.md, and stray.md has no incoming edge.
+func setupGraphWorkspace(t *testing.T) string {
+	t.Helper()
+	dir := t.TempDir()
+	wf := func(rel, body string) {
+		require.NoError(t, os.MkdirAll(filepath.Dir(filepath.Join(dir, rel)), 0o755))
+		require.NoError(t, os.WriteFile(filepath.Join(dir, rel), []byte(body), 0o644))
+	}
+	require.NoError(t, os.MkdirAll(filepath.Join(dir, ".git"), 0o755))
+	wf(".mdsmith.yml", "files:\n  - \"**/*.md\"\n")
+	wf("README.md", "# Readme\n\nStart at the [index](docs/index.md).\n")
+	wf("docs/index.md", "# Index\n\n<?catalog\nglob: \"guide/*.md\"\nrow: \"- {filename}\"\n?>\n<?/catalog?>\n\n"+
+		"See [[notes]].\n")
+	wf("docs/guide/a.md", "# A\n\n<?include\nfile: b.md\n?>\n<?/include?>\n")
+	wf("docs/guide/b.md", "# B\n\n<?include\nfile: a.md\n?>\n<?/include?>\n")
+	wf("docs/notes.md", "# Notes\n")
+	wf("docs/stray.md", "# Stray\n\nBack to the [index](index.md).\n")
+	return dir
+}
+
+func TestE2E_Graph_TextAnalysis(t *testing.T) {
+	dir := setupGraphWorkspace(t)
+	stdout, stderr, code := runBinaryInDir(t, dir, "", "graph", "--hubs", "1")
+	require.Equal(t, 0, code, "stderr=%q", stderr)
+	assert.Equal(t, "orphan docs/stray.md\n"+
+		"unreachable docs/stray.md\n"+
+		"include-cycle docs/guide/a.md -> docs/guide/b.md -> docs/guide/a.md\n"+
+		"component docs/guide/a.md docs/guide/b.md\n"+
+		"hub 2 docs/guide/a.md\n", stdout)
+}
+
+func TestE2E_Graph_JSON(t *testing.T) {
+	dir := setupGraphWorkspace(t)
+	stdout, stderr, code := runBinaryInDir(t, dir, "", "graph", "--format", "json", "--entry", "docs/index.md")
+	require.Equal(t, 0, code, "stderr=%q", stderr)
+	var report struct {
+		Nodes []string `json:"nodes"`
+		Edges []struct {
+			From string `json:"from"`
+			To   string `json:"to"`
+			Kind string `json:"kind"`
+		} `json:"edges"`
+		Analysis struct {
+			Entries     []string `json:"entries"`
+			Orphans     []string `json:"orphans"`
+			Unreachable []string `json:"unreachable"`
+		} `json:"analysis"`
+	}
+	require.NoError(t, json.Unmarshal([]byte(stdout), &report))
+	assert.Len(t, report.Nodes, 6)
+	kinds := map[string]bool{}
+	for _, e := range report.Edges {
+		kinds[e.Kind] = true
+	}
+	assert.Equal(t, map[string]bool{"file-link": true, "catalog": true, "include": true, "wikilink": true}, kinds)
+	assert.Equal(t, []string{"docs/index.md"}, report.Analysis.Entries)
+	assert.Equal(t, []string{"README.md", "docs/stray.md"}, report.Analysis.Orphans)
+	assert.Equal(t, []string{"README.md", "docs/stray.md"}, report.Analysis.Unreachable)
+}
+
+func TestE2E_Graph_DOTWithKindFilter(t *testing.T) {
+	dir := setupGraphWorkspace(t)
+	stdout, _, code := runBinaryInDir(t, dir, "", "graph", "--format", "dot", "--kind", "include")
+	require.Equal(t, 0, code)
+	assert.True(t, strings.HasPrefix(stdout, "digraph mdsmith {\n"))
+	assert.Contains(t, stdout, `"docs/guide/a.md" -> "docs/guide/b.md" [label="include"];`)
+	assert.NotContains(t, stdout, "file-link")
+}
+
+func TestE2E_Graph_Errors(t *testing.T) {
+	dir := setupGraphWorkspace(t)
+	cases := map[string]struct {
+		args []string
+		want string
+	}{
+		"format":   {[]string{"--format", "svg"}, `unknown --format "svg"`},
+		"kind":     {[]string{"--kind", "embed"}, `unknown --kind "embed"`},
+		"entry":    {[]string{"--entry", "missing.md"}, `--entry "missing.md" is not a workspace Markdown file`},
+		"args":     {[]string{"README.md"}, "graph takes no file arguments"},
+		"negative": {[]string{"--hubs", "-1"}, "--hubs must be >= 0"},
+	}
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			_, stderr, code := runBinaryInDir(t, dir, "", append([]string{"graph"}, tc.args...)...)
+			assert.Equal(t, 2, code)
+			assert.Contains(t, stderr, tc.want)
+		})
+	}
+}
@@ -146,3 +146,123 @@ func TestE2E_Query_AnchorFrontMatterSkipped(t *testing.T) {
 	assert.NotContains(t, stdout, "anchor.md")
 	assert.Contains(t, stderr, "anchors/aliases are not permitted")
 }
+
+// setupQueryProjection writes three plans with a status, an owner, and
+// a hyphenated key, plus one file the expression does not match.
+func setupQueryProjection(t *testing.T) string {
+	t.Helper()
+	dir := t.TempDir()
+	writeFixture(t, dir, "a.md", "---\nid: 10\nstatus: done\nowner: {name: Ada}\nreview-date: \"2026-01-02\"\n---\n# A\n")
+	writeFixture(t, dir, "b.md", "---\nid: 9\nstatus: todo\nowner: {name: Bo}\n---\n# B\n")
+	writeFixture(t, dir, "c.md", "---\nid: 2\nstatus: done\n---\n# C\n")
+	writeFixture(t, dir, "note.md", "---\ntitle: Note\n---\n# Note\n")
+	return dir
+}
+
+func TestE2E_Query_FieldsText(t *testing.T) {
+	dir := setupQueryProjection(t)
+	stdout, stderr, code := runBinaryInDir(t, dir, "", "list", "query", "id: int",
+		"--fields", "status,owner.name", "--sort", "id")
+	assert.Equal(t, 0, code, "stderr=%q", stderr)
+	assert.Equal(t, "c.md\tdone\t\nb.md\ttodo\tBo\na.md\tdone\tAda\n", stdout)
+}
+
+func TestE2E_Query_FieldsJSONKeepsOrderAndTypes(t *testing.T) {
+	dir := setupQueryProjection(t)
+	stdout, _, code := runBinaryInDir(t, dir, "", "list", "query", "id: int",
+		"--fields", `id,"review-date"`, "--format", "json", "--sort", "-id")
+	assert.Equal(t, 0, code)
+	assert.JSONEq(t, `[
+  {"path": "a.md", "id": 10, "review-date": "2026-01-02"},
+  {"path": "b.md", "id": 9, "review-date": null},
+  {"path": "c.md", "id": 2, "review-date": null}
+]`, stdout)
+	assert.True(t, strings.HasPrefix(stdout, "[\n  {\n    \"path\": \"a.md\",\n    \"id\": 10,"), stdout)
+}
+
+func TestE2E_Query_GroupByYAML(t *testing.T) {
+	dir := setupQueryProjection(t)
+	stdout, _, code := runBinaryInDir(t, dir, "", "list", "query", "id: int",
+		"--group-by", "status", "--format", "yaml")
+	assert.Equal(t, 0, code)
+	assert.Equal(t, "- group: done\n  files:\n    - path: a.md\n    - path: c.md\n"+
+		"- group: todo\n  files:\n    - path: b.md\n", stdout)
+}
+
+func TestE2E_Query_GroupByCSVAndText(t *testing.T) {
+	dir := setupQueryProjection(t)
+	stdout, _, code := runBinaryInDir(t, dir, "", "list", "query", "id: int",
+		"--group-by", "status", "--fields", "owner.name", "--format", "csv")
+	assert.Equal(t, 0, code)
+	assert.Equal(t, "status,path,owner.name\ndone,a.md,Ada\ndone,c.md,\ntodo,b.md,Bo\n", stdout)
+
+	stdout, _, code = runBinaryInDir(t, dir, "", "list", "query", "id: int", "--group-by", "status")
+	assert.Equal(t, 0, code)
+	assert.Equal(t, "status: done\n  a.md\n  c.md\n\nstatus: todo\n  b.md\n", stdout)
+}
+
+func TestE2E_Query_CountBy(t *testing.T) {
+	dir := setupQueryProjection(t)
+	stdout, _, code := runBinaryInDir(t, dir, "", "list", "query", "id: int", "--count-by", "status")
+	assert.Equal(t, 0, code)
+	assert.Equal(t, "2\tdone\n1\ttodo\n", stdout)
+
+	stdout, _, code = runBinaryInDir(t, dir, "", "list", "query", "id: int",
+		"--count-by", "status", "--format", "tsv")
+	assert.Equal(t, 0, code)
+	assert.Equal(t, "status\tcount\ndone\t2\ntodo\t1\n", stdout)
+}
+
+func TestE2E_Query_ProjectionNoMatchExitsOne(t *testing.T) {
+	dir := setupQueryProjection(t)
+	stdout, _, code := runBinaryInDir(t, dir, "", "list", "query", `status: "gone"`, "--format", "json")
+	assert.Equal(t, 1, code)
+	assert.Equal(t, "[]\n", stdout)
+}
+
+func TestE2E_Query_ProjectionErrors(t *testing.T) {
+	dir := setupQueryProjection(t)
+	cases := map[string]struct {
+		args []string
+		want string
+	}{
+		"format":   {[]string{"--format", "xml"}, `unknown --format "xml"`},
+		"field":    {[]string{"--fields", "review-date"}, `--fields: invalid field path "review-date"`},
+		"sort":     {[]string{"--sort", "-"}, `--sort: invalid field path ""`},
+		"count":    {[]string{"--count-by", "status", "--fields", "id"}, "--count-by cannot be combined"},
+		"null":     {[]string{"-0", "--fields", "id"}, "--null only applies to the plain path list"},
+		"group-by": {[]string{"--group-by", "a b"}, `--group-by: invalid field path "a b"`},
+	}
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			args := append([]string{"list", "query", "id: int"}, tc.args...)
+			_, stderr, code := runBinaryInDir(t, dir, "", args...)
+			assert.Equal(t, 2, code)
+			assert.Contains(t, stderr, tc.want)
+		})
+	}
+}
+
+func TestE2E_Query_DocMatchesContent(t *testing.T) {
+	dir := t.TempDir()
+	writeFixture(t, dir, "bare.md", "# Bare\n\n## Usage\n\n```go\nx := 1\n```\n")
+	writeFixture(t, dir, "plan.md", "---\nstatus: draft\n---\n# Plan\n\nNo code here.\n")
+
+	// A file without front matter still matches on its content.
+	stdout, stderr, code := runBinaryInDir(t, dir, "", "list", "query", `$doc: languages: go: >0`, ".")
+	assert.Equal(t, 0, code, "stderr=%q", stderr)
+	assert.Equal(t, "bare.md\n", stdout)
+
+	stdout, _, code = runBinaryInDir(t, dir, "", "list", "query",
+		`status: "draft", $doc: headings: [...!="Usage"]`, ".")
+	assert.Equal(t, 0, code)
+	assert.Equal(t, "plan.md\n", stdout)
+}
+
+func TestE2E_Query_DocFields(t *testing.T) {
+	dir := setupQueryProjection(t)
+	stdout, stderr, code := runBinaryInDir(t, dir, "", "list", "query", "id: int",
+		"--fields", "$doc.headings,$doc.tasks.total", "--sort", "id")
+	assert.Equal(t, 0, code, "stderr=%q", stderr)
+	assert.Equal(t, "c.md\tC\t0\nb.md\tB\t0\na.md\tA\t0\n", stdout)
+}
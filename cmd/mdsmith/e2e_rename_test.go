@@ -59,3 +59,32 @@ func TestE2E_Rename_ExitCodes(t *testing.T) {
 	_, _, code = runBinaryInDir(t, dir, "", "rename", "a.md", "Setup", "Install")
 	assert.Equal(t, 2, code)
 }
+
+func TestE2E_Rename_Numbering_RewritesWorkspace(t *testing.T) {
+	dir := setupRenameWorkspace(t)
+	wf := func(rel, body string) {
+		require.NoError(t, os.WriteFile(filepath.Join(dir, rel), []byte(body), 0o644))
+	}
+	wf(".mdsmith.yml", "files:\n  - \"**/*.md\"\nrules:\n  cross-file-reference-integrity: false\n"+
+		"  heading-numbering:\n    suffix: \".\"\n")
+	wf("spec.md", "---\ntitle: Spec\n---\n# Spec\n\n## Intro\n\n## 1. Usage\n\nSee [usage](#1-usage).\n")
+	wf("b.md", "Read [usage](spec.md#1-usage).\n")
+
+	stdout, stderr, code := runBinaryInDir(t, dir, "", "rename", "spec.md", "--numbering")
+	require.Equal(t, 0, code, "stdout=%q stderr=%q", stdout, stderr)
+	assert.Contains(t, stdout, "b.md: 1 edit(s)")
+	assert.Contains(t, stdout, "spec.md: 3 edit(s)")
+
+	spec, _ := os.ReadFile(filepath.Join(dir, "spec.md"))
+	assert.Equal(t, "---\ntitle: Spec\n---\n# Spec\n\n## 1. Intro\n\n## 2. Usage\n\nSee [usage](#2-usage).\n", string(spec))
+	b, _ := os.ReadFile(filepath.Join(dir, "b.md"))
+	assert.Equal(t, "Read [usage](spec.md#2-usage).\n", string(b))
+
+	// Already numbered → exit 1; rule off for the file → exit 2.
+	_, _, code = runBinaryInDir(t, dir, "", "rename", "spec.md", "--numbering")
+	assert.Equal(t, 1, code)
+	wf(".mdsmith.yml", "files:\n  - \"**/*.md\"\n")
+	_, stderr, code = runBinaryInDir(t, dir, "", "rename", "spec.md", "--numbering")
+	assert.Equal(t, 2, code)
+	assert.Contains(t, stderr, "heading-numbering is not enabled for spec.md")
+}
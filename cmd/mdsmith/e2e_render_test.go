@@ -0,0 +1,109 @@
+package main_test
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestE2E_Render_FreshDirectiveFile(t *testing.T) {
+	dir := t.TempDir()
+	isolateDir(t, dir)
+	path := writeFixture(t, dir, "doc.md", freshTOCFile)
+
+	stdout, stderr, code := runBinary(t, "", "render", path)
+	require.Equal(t, 0, code, "stderr=%s", stderr)
+	assert.NotContains(t, stdout, "toc")
+	assert.Contains(t, stdout, `<a href="#section">Section</a>`)
+	assert.Contains(t, stdout, `<h2 id="section">Section</h2>`)
+	assert.NotContains(t, stdout, "<html", "no template means a fragment")
+}
+
+func TestE2E_Render_StaleBodyRefusesUnlessFix(t *testing.T) {
+	dir := t.TempDir()
+	isolateDir(t, dir)
+	path := writeFixture(t, dir, "doc.md", staleTOCFile)
+
+	stdout, stderr, code := runBinary(t, "", "render", path)
+	assert.Equal(t, 1, code)
+	assert.Empty(t, stdout)
+	assert.Contains(t, stderr, "MDS038")
+
+	stdout, stderr, code = runBinary(t, "", "render", "--fix", path)
+	require.Equal(t, 0, code, "stderr=%s", stderr)
+	assert.Contains(t, stdout, `href="#section"`)
+	assert.NotContains(t, stdout, "Wrong")
+}
+
+func TestE2E_Render_FlavorFromConfigAndFlag(t *testing.T) {
+	dir := t.TempDir()
+	isolateDir(t, dir)
+	require.NoError(t, os.WriteFile(filepath.Join(dir, ".mdsmith.yml"),
+		[]byte("rules:\n  markdown-flavor:\n    flavor: commonmark\n"), 0o644))
+	writeFixture(t, dir, "doc.md", "# T\n\n| a |\n| - |\n| b |\n")
+
+	stdout, stderr, code := runBinaryInDir(t, dir, "", "render", "doc.md")
+	require.Equal(t, 0, code, "stderr=%s", stderr)
+	assert.NotContains(t, stdout, "<table>")
+
+	stdout, stderr, code = runBinaryInDir(t, dir, "", "render", "--flavor", "gfm", "doc.md")
+	require.Equal(t, 0, code, "stderr=%s", stderr)
+	assert.Contains(t, stdout, "<table>")
+
+	_, stderr, code = runBinaryInDir(t, dir, "", "render", "--flavor", "GFM", "doc.md")
+	assert.Equal(t, 2, code)
+	assert.Contains(t, stderr, `unknown flavor "GFM"`)
+}
+
+func TestE2E_Render_TemplatesAndOutput(t *testing.T) {
+	dir := t.TempDir()
+	isolateDir(t, dir)
+	src := writeFixture(t, dir, "doc.md",
+		"---\ntitle: Guide\n---\n# Heading\n\nSee [the ref](ref.md#Usage).\n")
+	tmpl := writeFixture(t, dir, "page.html",
+		"<main data-title=\"{{.Title}}\">{{.Body}}</main>\n")
+	dst := filepath.Join(dir, "doc.html")
+
+	stdout, stderr, code := runBinary(t, "", "render", "--template", "minimal", src)
+	require.Equal(t, 0, code, "stderr=%s", stderr)
+	assert.Contains(t, stdout, "<!DOCTYPE html>")
+	assert.Contains(t, stdout, "<title>Guide</title>")
+	assert.NotContains(t, stdout, "title: Guide", "front matter is not rendered")
+	assert.Contains(t, stdout, `href="ref.html#usage"`)
+
+	_, stderr, code = runBinary(t, "", "render", "--template", tmpl, "-o", dst, src)
+	require.Equal(t, 0, code, "stderr=%s", stderr)
+	got, err := os.ReadFile(dst)
+	require.NoError(t, err)
+	assert.Contains(t, string(got), `<main data-title="Guide"><h1 id="heading">Heading</h1>`)
+
+	_, stderr, code = runBinary(t, "", "render", "--template",
+		filepath.Join(dir, "missing.html"), src)
+	assert.Equal(t, 2, code)
+	assert.Contains(t, stderr, "no such file")
+}
+
+func TestE2E_Render_UsageErrors(t *testing.T) {
+	dir := t.TempDir()
+	isolateDir(t, dir)
+	a := writeFixture(t, dir, "a.md", freshTOCFile)
+	b := writeFixture(t, dir, "b.md", freshTOCFile)
+
+	cases := []struct {
+		args []string
+		want string
+	}{
+		{nil, "requires a file argument"},
+		{[]string{a, b}, "single file argument"},
+		{[]string{"--fix", "--no-check", a}, "mutually exclusive"},
+		{[]string{filepath.Join(dir, "nope.md")}, "no such file"},
+	}
+	for _, c := range cases {
+		_, stderr, code := runBinary(t, "", append([]string{"render"}, c.args...)...)
+		assert.Equal(t, 2, code, "args=%v", c.args)
+		assert.Contains(t, stderr, c.want, "args=%v", c.args)
+	}
+}
@@ -0,0 +1,147 @@
+package main_test
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const scaffoldRecord = `{
+  "title": "Cake",
+  "goal": {},
+  "steps": {"step": [{"n": "1"}, {"n": "2"}]},
+  "notes": {"code": "preheat()", "items": ["cool it", "serve"]}
+}`
+
+func TestE2E_Scaffold_NewFileConforms(t *testing.T) {
+	dir := kindsTestDir(t, extractCfg, map[string]string{
+		"cake.json": scaffoldRecord,
+	})
+	_, stderr, code := runBinaryInDir(t, dir, "",
+		"scaffold", "recipe", "--from", "cake.json", "recipes/cake.md")
+	require.Equal(t, 0, code, "stderr=%s", stderr)
+
+	got, err := os.ReadFile(filepath.Join(dir, "recipes/cake.md"))
+	require.NoError(t, err)
+	// Sections the record leaves empty render as bare headings; the
+	// code block carries no language because the record names none.
+	want := "# Cake\n\n## Goal\n\n## Steps\n\n### Step 1\n\n### Step 2\n\n" +
+		"## Notes\n\n```\npreheat()\n```\n\n- cool it\n- serve\n"
+	assert.Equal(t, want, string(got))
+}
+
+func TestE2E_Scaffold_DryRunFromStdin(t *testing.T) {
+	dir := kindsTestDir(t, extractCfg, map[string]string{})
+	stdout, stderr, code := runBinaryInDir(t, dir, scaffoldRecord,
+		"scaffold", "recipe", "--from", "-", "--dry-run", "recipes/cake.md")
+	require.Equal(t, 0, code, "stderr=%s", stderr)
+	assert.Contains(t, stdout, "## Notes\n")
+	_, err := os.Stat(filepath.Join(dir, "recipes/cake.md"))
+	assert.True(t, os.IsNotExist(err), "dry run must not write the file")
+}
+
+func TestE2E_Scaffold_ExistingFileNeedsUpdate(t *testing.T) {
+	dir := kindsTestDir(t, extractCfg, map[string]string{
+		"recipes/cake.md": conformantRecipe,
+		"cake.json":       scaffoldRecord,
+	})
+	_, stderr, code := runBinaryInDir(t, dir, "",
+		"scaffold", "recipe", "--from", "cake.json", "recipes/cake.md")
+	assert.Equal(t, 2, code)
+	assert.Contains(t, stderr, "pass --update")
+}
+
+func TestE2E_Scaffold_UpdateKeepsProse(t *testing.T) {
+	record := `{"notes": {"code": "preheat()", "items": ["cool it", "plate it"]},
+  "title": "Cake", "goal": {}, "steps": {"step": [{"n": "1"}, {"n": "2"}]}}`
+	dir := kindsTestDir(t, extractCfg, map[string]string{
+		"recipes/cake.md": conformantRecipe,
+		"cake.json":       record,
+	})
+	_, stderr, code := runBinaryInDir(t, dir, "",
+		"scaffold", "recipe", "--from", "cake.json", "--update", "recipes/cake.md")
+	require.Equal(t, 0, code, "stderr=%s", stderr)
+
+	got, err := os.ReadFile(filepath.Join(dir, "recipes/cake.md"))
+	require.NoError(t, err)
+	// Only the changed list item moved; the step prose the record
+	// does not carry is left alone.
+	want := conformantRecipe[:len(conformantRecipe)-len("- serve\n")] + "- plate it\n"
+	assert.Equal(t, want, string(got))
+}
+
+const noteCfg = `kinds:
+  note:
+    schema:
+      sections:
+        - heading: "Summary"
+          content:
+            - kind: paragraph
+kind-assignment:
+  - glob: ["notes/*.md"]
+    kinds: [note]
+`
+
+func TestE2E_Scaffold_FrontMatterPassesCheck(t *testing.T) {
+	record := `{"frontmatter": {"id": "n1"}, "title": "Note",
+  "summary": {"text": "A short note."}}`
+	dir := kindsTestDir(t, noteCfg, map[string]string{
+		"notes/old.md": "# Note\n\n## Summary\n\nOld text.\n",
+		"n1.json":      record,
+	})
+	_, stderr, code := runBinaryInDir(t, dir, "",
+		"scaffold", "note", "--from", "n1.json", "notes/new.md")
+	require.Equal(t, 0, code, "stderr=%s", stderr)
+	_, stderr, code = runBinaryInDir(t, dir, "",
+		"scaffold", "note", "--from", "n1.json", "--update", "notes/old.md")
+	require.Equal(t, 0, code, "stderr=%s", stderr)
+
+	want := "---\nid: n1\n---\n# Note\n\n## Summary\n\nA short note.\n"
+	for _, name := range []string{"notes/new.md", "notes/old.md"} {
+		got, err := os.ReadFile(filepath.Join(dir, name))
+		require.NoError(t, err)
+		assert.Equal(t, want, string(got), name)
+
+		stdout, stderr, code := runBinaryInDir(t, dir, "", "check", "--no-color", name)
+		assert.Equal(t, 0, code, "%s: stdout=%s stderr=%s", name, stdout, stderr)
+		assert.NotContains(t, stderr, "MDS004", name)
+	}
+}
+
+func TestE2E_Scaffold_NonConformantRecordExitsOne(t *testing.T) {
+	dir := kindsTestDir(t, extractCfg, map[string]string{
+		"cake.json": `{"title": "Cake", "goal": {}, "steps": {"step": []},
+  "notes": {"code": "x", "items": ["a"]}}`,
+	})
+	_, stderr, code := runBinaryInDir(t, dir, "",
+		"scaffold", "recipe", "--from", "cake.json", "recipes/cake.md")
+	assert.Equal(t, 1, code)
+	assert.Contains(t, stderr, "MDS020")
+	_, err := os.Stat(filepath.Join(dir, "recipes/cake.md"))
+	assert.True(t, os.IsNotExist(err), "a failing record must not be written")
+}
+
+func TestE2E_Scaffold_UsageErrors(t *testing.T) {
+	dir := kindsTestDir(t, extractCfg, map[string]string{
+		"cake.json": scaffoldRecord,
+		"list.json": `[1, 2]`,
+	})
+	cases := []struct {
+		args []string
+		want string
+	}{
+		{[]string{"recipe", "recipes/cake.md"}, "requires --from"},
+		{[]string{"recipe", "--from", "cake.json"}, "requires <kind> and <file>"},
+		{[]string{"nope", "--from", "cake.json", "recipes/cake.md"}, `unknown kind "nope"`},
+		{[]string{"recipe", "--from", "list.json", "recipes/cake.md"}, "must be an object"},
+		{[]string{"recipe", "--from", "missing.json", "recipes/cake.md"}, "reading missing.json"},
+	}
+	for _, c := range cases {
+		_, stderr, code := runBinaryInDir(t, dir, "", append([]string{"scaffold"}, c.args...)...)
+		assert.Equal(t, 2, code, "args=%v", c.args)
+		assert.Contains(t, stderr, c.want, "args=%v", c.args)
+	}
+}
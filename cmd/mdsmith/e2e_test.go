@@ -521,6 +521,24 @@ func TestE2E_Fix_PreservesFrontMatter(t *testing.T) {
 	assert.Contains(t, body, "Hello", "file does not contain expected content after fix")
 }
 
+func TestE2E_Fix_HeadingNumberingUpdatesTOCAndAnchors(t *testing.T) {
+	dir := t.TempDir()
+	isolateDir(t, dir)
+	writeFixture(t, dir, ".mdsmith.yml", "rules:\n  heading-numbering: true\n")
+	path := writeFixture(t, dir, "spec.md",
+		"# Spec\n\n<?toc?>\n<?/toc?>\n\n## Intro\n\nText.\n\n### Scope\n\nText.\n\n## Usage\n\nSee [scope](#scope).\n")
+
+	_, stderr, exitCode := runBinaryInDir(t, dir, "", "fix", "--no-color", "spec.md")
+	assert.Equal(t, 0, exitCode, "stderr: %s", stderr)
+
+	got, err := os.ReadFile(path)
+	require.NoError(t, err)
+	assert.Equal(t, "# Spec\n\n<?toc?>\n\n"+
+		"- [1 Intro](#1-intro)\n  - [1.1 Scope](#11-scope)\n- [2 Usage](#2-usage)\n\n"+
+		"<?/toc?>\n\n## 1 Intro\n\nText.\n\n### 1.1 Scope\n\nText.\n\n## 2 Usage\n\nSee [scope](#11-scope).\n",
+		string(got))
+}
+
 func TestE2E_Fix_Stdin_Rejected(t *testing.T) {
 	_, stderr, exitCode := runBinary(t, "# Hello\n\nWorld   \n", "fix", "-")
 	assert.Equal(t, 2, exitCode, "expected exit code 2 for fix with stdin, got %d", exitCode)
@@ -1936,6 +1954,101 @@ func TestE2E_MergeDriver_IncludeConflict_Resolved(t *testing.T) {
 	assert.Contains(t, content, "included content", "expected include section to contain regenerated content")
 }
 
+func TestE2E_MergeDriver_BacklinksConflict_Resolved(t *testing.T) {
+	dir := t.TempDir()
+	require.NoError(t, os.MkdirAll(filepath.Join(dir, ".git"), 0o755))
+	writeFixture(t, dir, ".mdsmith.yml", "rules:\n  backlinks: true\n")
+
+	// A page in a subdirectory links to the glossary.
+	require.NoError(t, os.MkdirAll(filepath.Join(dir, "guides"), 0o755))
+	writeFixture(t, dir, "guides/setup.md", "# Setup\n\nSee the [glossary](../GLOSSARY.md).\n")
+
+	section := func(body string) string {
+		return "# Glossary\n\n<?backlinks\n?>\n" + body + "<?/backlinks?>\n"
+	}
+	base := writeFixture(t, dir, "base.md", section(""))
+	ours := writeFixture(t, dir, "ours.md", section("- ours\n"))
+	theirs := writeFixture(t, dir, "theirs.md", section("- theirs\n"))
+	writeFixture(t, dir, "GLOSSARY.md", section("- ours\n"))
+
+	_, stderr, exitCode := runBinaryInDir(t, dir, "",
+		"merge-driver", "run", base, ours, theirs, "GLOSSARY.md")
+	assert.Equal(t, 0, exitCode,
+		"expected exit 0 (backlinks conflict resolved), got %d; stderr: %s",
+		exitCode, stderr)
+
+	result, _ := os.ReadFile(ours)
+	assert.Equal(t, section("\n- [Setup](guides/setup.md)\n\n"), string(result))
+}
+
+func TestE2E_MergeDriver_UserDirectiveConflict_Resolved(t *testing.T) {
+	dir := t.TempDir()
+	require.NoError(t, os.MkdirAll(filepath.Join(dir, ".git"), 0o755))
+	writeFixture(t, dir, ".mdsmith.yml", "rules:\n  user-directive: true\n")
+	require.NoError(t, os.MkdirAll(filepath.Join(dir, ".mdsmith", "directives"), 0o755))
+	writeFixture(t, dir, ".mdsmith/directives/roster.yml",
+		"params:\n  required: [team]\nexpand: catalog\nwith:\n  glob: \"{team}/*.md\"\n  row: \"- {name}\"\n")
+
+	require.NoError(t, os.MkdirAll(filepath.Join(dir, "team"), 0o755))
+	writeFixture(t, dir, "team/ada.md", "---\nname: Ada\n---\n# Ada\n")
+
+	section := func(body string) string {
+		return "# Team\n\n<?roster\nteam: team\n?>\n" + body + "<?/roster?>\n"
+	}
+	base := writeFixture(t, dir, "base.md", section(""))
+	ours := writeFixture(t, dir, "ours.md", section("- ours\n"))
+	theirs := writeFixture(t, dir, "theirs.md", section("- theirs\n"))
+	writeFixture(t, dir, "TEAM.md", section("- ours\n"))
+
+	_, stderr, exitCode := runBinaryInDir(t, dir, "",
+		"merge-driver", "run", base, ours, theirs, "TEAM.md")
+	assert.Equal(t, 0, exitCode,
+		"expected exit 0 (user directive conflict resolved), got %d; stderr: %s",
+		exitCode, stderr)
+
+	result, _ := os.ReadFile(ours)
+	assert.Equal(t, section("- Ada\n"), string(result))
+}
+
+func TestE2E_MergeDriver_HistoryConflict_Resolved(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git not installed")
+	}
+	dir := t.TempDir()
+	git := func(args ...string) {
+		cmd := exec.Command("git", append([]string{"-C", dir,
+			"-c", "user.name=Ada", "-c", "user.email=ada@example.com",
+			"-c", "commit.gpgsign=false"}, args...)...)
+		cmd.Env = append(os.Environ(),
+			"GIT_AUTHOR_DATE=2026-01-02T12:00:00Z", "GIT_COMMITTER_DATE=2026-01-02T12:00:00Z")
+		out, err := cmd.CombinedOutput()
+		require.NoError(t, err, string(out))
+	}
+	git("init", "-q")
+	writeFixture(t, dir, ".mdsmith.yml", "rules:\n  history: true\n")
+	require.NoError(t, os.MkdirAll(filepath.Join(dir, "guides"), 0o755))
+	writeFixture(t, dir, "guides/setup.md", "# Setup\n")
+	git("add", "-A")
+	git("commit", "-q", "-m", "docs: add setup guide")
+
+	section := func(body string) string {
+		return "# Changes\n\n<?history\nrow: \"- {date} {subject}\"\n?>\n" + body + "<?/history?>\n"
+	}
+	base := writeFixture(t, dir, "base.md", section(""))
+	ours := writeFixture(t, dir, "ours.md", section("- ours\n"))
+	theirs := writeFixture(t, dir, "theirs.md", section("- theirs\n"))
+	writeFixture(t, dir, "CHANGES.md", section("- ours\n"))
+
+	_, stderr, exitCode := runBinaryInDirEnv(t, dir, "", []string{"MDSMITH_TRUST_BUILD=1"},
+		"merge-driver", "run", base, ours, theirs, "CHANGES.md")
+	assert.Equal(t, 0, exitCode,
+		"expected exit 0 (history conflict resolved), got %d; stderr: %s",
+		exitCode, stderr)
+
+	result, _ := os.ReadFile(ours)
+	assert.Equal(t, section("\n- 2026-01-02 docs: add setup guide\n\n"), string(result))
+}
+
 func TestE2E_MergeDriver_SetextInSection_Preserved(t *testing.T) {
 	dir := t.TempDir()
 	require.NoError(t, os.MkdirAll(filepath.Join(dir, ".git"), 0o755))
@@ -126,9 +126,9 @@ func doExport(path string, flags exportFlags) int {
 }
 
 // prepareExportFile mirrors fix.Fixer.prepareFile + fixableRules: it
-// parses the file, wires FS / RootDir / MaxInputBytes / GitignoreFunc,
-// resolves the effective rule config from .mdsmith.yml kinds and
-// overrides (and any front-matter `kinds:`), then returns the set of
+// parses the file, wires FS / RootDir / MaxInputBytes / GitignoreFunc
+// / KindsFunc, resolves the effective rule config from .mdsmith.yml
+// kinds and overrides (and any front-matter `kinds:`), then returns the set of
 // directive rules to consult for staleness/regeneration. Each
 // returned rule is a clone with its per-file settings applied via
 // checker.ConfigureRule, and disabled rules are excluded — matching
@@ -161,16 +161,22 @@ func prepareExportFile(
 	f.GitignoreFunc = func() *gitignore.Matcher {
 		return gitignore.NewMatcher(gitignoreDir)
 	}
-	// Match engine.Runner.processFile so staleness diagnostics inside
-	// an outer include/catalog body are suppressed: the host file is
-	// not responsible for those bytes.
-	f.GeneratedRanges = gensection.FindAllGeneratedRanges(f)
+	if cfg != nil {
+		f.KindsFunc = func(path string, fm []byte) []string {
+			return config.FileKinds(cfg, path, fm)
+		}
+	}
 
 	all := rule.All()
 	effective, err := effectiveExportConfig(cfg, path, f.FrontMatter, all)
 	if err != nil {
 		return nil, nil, err
 	}
+	// Match engine.Runner.processFile so staleness diagnostics inside
+	// an outer include/catalog body are suppressed: the host file is
+	// not responsible for those bytes.
+	f.GeneratedDirectives = checker.GeneratedDirectives(all, effective)
+	f.GeneratedRanges = gensection.FindAllGeneratedRanges(f)
 	rules, err := configuredEnabledRules(all, effective)
 	if err != nil {
 		return nil, nil, err
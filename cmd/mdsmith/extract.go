@@ -26,6 +26,9 @@ var (
 	extractReadFile = bytelimit.ReadFileLimited
 	extractNewFile  = lint.NewFileFromSource
 	extractEncode   = encode.Encode
+	// extractStreamWrite appends one `extract --all` record; tests
+	// fail it partway through a stream to drive the write-error path.
+	extractStreamWrite = (*encode.Stream).Write
 	// extractStdout nil means "resolve os.Stdout at call time" so a
 	// test (or captureStdout) that swaps os.Stdout still sees the
 	// write. Tests set it to a failing writer to drive the
@@ -50,16 +53,22 @@ func extractErr(code int, format string, a ...any) int {
 // runExtract implements the "extract" subcommand:
 //
 //	mdsmith extract <kind> --format <fmt> <file>
+//	mdsmith extract <kind> --all [--query <expr>] [paths...]
 //
 // It projects a schema-conformant file into a data tree whose shape
 // mirrors the composed schema. Extraction is gated on a clean
 // `check`: a non-conformant file prints the same diagnostics and
-// exits non-zero, never emitting partial data.
+// exits non-zero, never emitting partial data. --all streams one
+// record per file instead (see runExtractAll).
 func runExtract(args []string) int {
-	kindName, path, fmtEnum, stop := parseExtractArgs(args)
+	opts, stop := parseExtractArgs(args)
 	if stop >= 0 {
 		return stop
 	}
+	if opts.all {
+		return runExtractAll(opts)
+	}
+	kindName, path := opts.kind, opts.paths[0]
 
 	res, cfg, code := resolveFileFromCLI(path)
 	if code != 0 {
@@ -74,32 +83,47 @@ func runExtract(args []string) int {
 	// cfg.MaxInputSize (it resolves the same value before reading
 	// the file), so this cannot fail here.
 	maxBytes, _ := resolveMaxInputBytes(cfg, "")
-	if code := gateExtractCheck(cfg, cfgPath, path, maxBytes); code != 0 {
+	runner := newExtractRunner(cfg, cfgPath, maxBytes)
+	data, code := projectExtract(runner, res, kindName, path)
+	if code != 0 {
 		return code
 	}
+	return emit(extractStdout, opts.format, data)
+}
+
+// projectExtract gates one resolved file on a clean check and
+// projects it against its composed schema. A non-zero code means
+// the reason is already on stderr. The runner carries the config,
+// its path, and the input limit.
+func projectExtract(
+	runner *engine.Runner, res *config.FileResolution, kindName, path string,
+) (any, int) {
+	cfg, cfgPath, maxBytes := runner.Config, runner.ConfigPath, runner.MaxInputBytes
+	if code := gateResultCode(extractGateRun(runner, path)); code != 0 {
+		return nil, code
+	}
 
 	f, source, code := loadExtractFile(cfg, cfgPath, path, maxBytes)
 	if code != 0 {
-		return code
+		return nil, code
 	}
 	sch, code := composedSchemaFor(f, res, kindName)
 	if code != 0 {
-		return code
+		return nil, code
 	}
 
 	docFM, code := decodeDocFrontMatter(cfg, source, path)
 	if code != 0 {
-		return code
+		return nil, code
 	}
 
 	mt := schema.BuildMatchTree(f, sch, docFM)
 	data, diags := extract.Extract(f, sch, mt)
 	if len(diags) > 0 {
 		formatDiagnostics(diags, "text", false)
-		return 1
+		return nil, 1
 	}
-
-	return emit(extractStdout, fmtEnum, data)
+	return data, 0
 }
 
 // emit encodes data and writes it. Split out so its encode-error
@@ -120,37 +144,60 @@ func emit(w io.Writer, f encode.Format, data any) int {
 	return 0
 }
 
-// parseExtractArgs parses flags and the two positionals. The final
+// extractOptions holds the parsed extract command line.
+type extractOptions struct {
+	kind   string
+	paths  []string
+	format encode.Format
+	all    bool
+	query  string
+}
+
+// parseExtractArgs parses flags and the positionals: a kind and one
+// file, or with --all a kind and any number of paths. The final
 // return is -1 to continue, or a process exit code to stop on
 // (usage error, --help, or a bad --format value).
-func parseExtractArgs(
-	args []string,
-) (kindName, path string, fmtEnum encode.Format, stop int) {
+func parseExtractArgs(args []string) (extractOptions, int) {
 	fs := flag.NewFlagSet("extract", flag.ContinueOnError)
+	var opts extractOptions
 	var format string
 	fs.StringVarP(&format, "format", "f", "json",
-		"Output format: json, yaml, msgpack")
+		"Output format: json, jsonl, yaml, msgpack (jsonl with --all)")
+	fs.BoolVar(&opts.all, "all", false,
+		"Extract every file of the kind under the paths (default .)")
+	fs.StringVar(&opts.query, "query", "",
+		"With --all, only files whose front matter matches this CUE expression")
 	fs.Usage = func() {
 		fmt.Fprintf(os.Stderr,
-			"Usage: mdsmith extract <kind> --format <fmt> <file>\n\n"+
+			"Usage: mdsmith extract <kind> --format <fmt> <file>\n"+
+				"       mdsmith extract <kind> --all [--query <expr>] [paths...]\n\n"+
 				"Emit a kind-conformant file as a data tree whose nesting\n"+
-				"mirrors the schema hierarchy.\n\nFlags:\n")
+				"mirrors the schema hierarchy. --all emits one record per\n"+
+				"file and skips non-conformant files.\n\nFlags:\n")
 		fs.PrintDefaults()
 	}
 	if err := fs.Parse(args); err != nil {
 		if code := reportFlagParseErr(err, os.Stderr, "mdsmith: extract"); code >= 0 {
-			return "", "", "", code
+			return opts, code
 		}
 	}
-	if fs.NArg() != 2 {
-		return "", "", "",
-			extractErr(2, "extract requires <kind> and <file>")
+	switch {
+	case opts.all && fs.NArg() < 1:
+		return opts, extractErr(2, "extract --all requires <kind>")
+	case !opts.all && fs.NArg() != 2:
+		return opts, extractErr(2, "extract requires <kind> and <file>")
+	case !opts.all && opts.query != "":
+		return opts, extractErr(2, "--query requires --all")
+	}
+	if opts.all && !fs.Changed("format") {
+		format = string(encode.JSONL)
 	}
 	f, err := encode.ParseFormat(format)
 	if err != nil {
-		return "", "", "", extractErr(2, "%v", err)
+		return opts, extractErr(2, "%v", err)
 	}
-	return fs.Arg(0), fs.Arg(1), f, -1
+	opts.kind, opts.paths, opts.format = fs.Arg(0), fs.Args()[1:], f
+	return opts, -1
 }
 
 // validateExtractKind rejects an unknown kind or one not assigned
@@ -168,13 +215,12 @@ func validateExtractKind(
 	return 0
 }
 
-// gateExtractCheck runs the full check on the file and mirrors its
-// exit semantics: a non-conformant file prints the same diagnostics
-// and never reaches projection.
-func gateExtractCheck(
-	cfg *config.Config, cfgPath, path string, maxBytes int64,
-) int {
-	runner := &engine.Runner{
+// newExtractRunner returns the check runner extract gates on. Its
+// full check mirrors `mdsmith check`'s exit semantics: a
+// non-conformant file prints the same diagnostics and never reaches
+// projection.
+func newExtractRunner(cfg *config.Config, cfgPath string, maxBytes int64) *engine.Runner {
+	return &engine.Runner{
 		Config:           cfg,
 		Rules:            rule.All(),
 		StripFrontMatter: frontMatterEnabled(cfg),
@@ -182,7 +228,6 @@ func gateExtractCheck(
 		MaxInputBytes:    maxBytes,
 		ConfigPath:       cfgPath,
 	}
-	return gateResultCode(extractGateRun(runner, path))
 }
 
 // gateResultCode maps a check Result to extract's exit code,
@@ -266,7 +311,7 @@ func composedSchemaFor(
 ) (*schema.Schema, int) {
 	rr, ok := res.Rules["required-structure"]
 	if !ok || !rr.Final.Enabled {
-		// gateExtractCheck runs the normal engine, which skips
+		// The check gate runs the normal engine, which skips
 		// MDS020 when the rule is disabled. Projecting then would
 		// emit data for a never-validated file, breaking the
 		// "gated on a successful match" contract. Refuse instead.
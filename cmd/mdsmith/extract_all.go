@@ -0,0 +1,114 @@
+package main
+
+import (
+	"os"
+
+	"github.com/jeduden/mdsmith/internal/config"
+	"github.com/jeduden/mdsmith/internal/extract/encode"
+	"github.com/jeduden/mdsmith/internal/lint"
+	"github.com/jeduden/mdsmith/internal/query"
+)
+
+// extractRecord is one file's entry in an `extract --all` stream.
+// Struct fields, not a map, keep the keys in this order.
+type extractRecord struct {
+	Path string `json:"path" yaml:"path" msgpack:"path"`
+	Kind string `json:"kind" yaml:"kind" msgpack:"kind"`
+	Data any    `json:"data" yaml:"data" msgpack:"data"`
+}
+
+// runExtractAll implements `mdsmith extract <kind> --all`: it
+// extracts every file under the paths that has the kind and, with
+// --query, matches the expression, and streams one record per file.
+// A non-conformant file is skipped with its diagnostics on stderr;
+// the rest still stream. Exit 0 when every candidate extracted, 1
+// when any was skipped, 2 on a usage, config, or write error.
+//
+// Files are gated and projected one at a time, so memory holds one
+// data tree however large the tree is. The check runner shares one
+// read cache across files, as a single `mdsmith check` run does.
+func runExtractAll(opts extractOptions) int {
+	cfg, cfgPath, code := kindsConfig()
+	if code != 0 {
+		return code
+	}
+	if _, declared := cfg.Kinds[opts.kind]; !declared {
+		return extractErr(2, "unknown kind %q", opts.kind)
+	}
+	var matcher *query.Matcher
+	if opts.query != "" {
+		m, err := query.Compile(opts.query)
+		if err != nil {
+			return extractErr(2, "--query: %v", err)
+		}
+		matcher = m
+	}
+	maxBytes, err := resolveMaxInputBytes(cfg, "")
+	if err != nil {
+		return extractErr(2, "%v", err)
+	}
+	paths := opts.paths
+	if len(paths) == 0 {
+		paths = []string{"."}
+	}
+	files, err := lint.ResolveFilesWithOpts(paths, resolveOpts(cfg, walkCLI{}))
+	if err != nil {
+		return extractErr(2, "%v", err)
+	}
+
+	runner := newExtractRunner(cfg, cfgPath, maxBytes)
+	runner.RunCache = lint.NewRunCache()
+	sess := sessionForCLI(cfg, cfgPath)
+	defer sess.Dispose()
+
+	w := extractStdout
+	if w == nil {
+		w = os.Stdout
+	}
+	stream := encode.NewStream(w, opts.format)
+	defer stream.Abort()
+	extracted, skipped := 0, 0
+	for _, path := range files {
+		// An ignored file is never checked, so it cannot pass the
+		// gate; it is not a candidate, as in `mdsmith check`.
+		if config.IsIgnored(cfg.Ignore, path) {
+			continue
+		}
+		if matcher != nil && !extractQueryMatches(matcher, path, maxBytes) {
+			continue
+		}
+		res, err := resolveFileWith(cfg, sess, path, maxBytes)
+		if err != nil {
+			extractErr(1, "skipping %s: %v", path, err)
+			skipped++
+			continue
+		}
+		if !kindAssigned(res.Kinds, opts.kind) {
+			continue
+		}
+		data, code := projectExtract(runner, res, opts.kind, path)
+		if code != 0 {
+			skipped++
+			continue
+		}
+		if err := extractStreamWrite(stream, extractRecord{Path: path, Kind: opts.kind, Data: data}); err != nil {
+			return extractErr(2, "writing output: %v", err)
+		}
+		extracted++
+	}
+	if err := stream.Close(); err != nil {
+		return extractErr(2, "writing output: %v", err)
+	}
+	if skipped > 0 {
+		return extractErr(1, "extract: skipped %d of %d files", skipped, extracted+skipped)
+	}
+	return 0
+}
+
+// extractQueryMatches reports whether path satisfies the --query
+// expression, read the way `mdsmith list query` reads it. A file
+// the query cannot read is not selected.
+func extractQueryMatches(m *query.Matcher, path string, maxBytes int64) bool {
+	fm, err := readQueryInput(path, maxBytes, m.UsesDoc())
+	return err == nil && fm != nil && m.Match(fm)
+}
@@ -3,6 +3,8 @@ package main
 import (
 	"bytes"
 	"errors"
+	"os"
+	"path/filepath"
 	"testing"
 
 	"github.com/jeduden/mdsmith/internal/config"
@@ -56,13 +58,15 @@ func TestGateResultCode(t *testing.T) {
 	assert.Equal(t, 2, gateResultCode(&engine.Result{}))
 }
 
-func TestGateExtractCheck_ErrorsOnly(t *testing.T) {
+func TestProjectExtract_GateErrorsOnly(t *testing.T) {
 	orig := extractGateRun
 	extractGateRun = func(*engine.Runner, string) *engine.Result {
 		return &engine.Result{Errors: []error{errors.New("engine boom")}}
 	}
 	defer func() { extractGateRun = orig }()
-	assert.Equal(t, 2, gateExtractCheck(&config.Config{}, "", "p.md", 1<<20))
+	runner := newExtractRunner(&config.Config{}, "", 1<<20)
+	_, code := projectExtract(runner, &config.FileResolution{}, "k", "p.md")
+	assert.Equal(t, 2, code)
 }
 
 func TestLoadExtractFile_ReadAndParseErrors(t *testing.T) {
@@ -153,3 +157,45 @@ func TestComposedSchemaFor_ApplySettingsAndComposeErrors(t *testing.T) {
 	_, code = composedSchemaFor(f, missing, "k")
 	assert.Equal(t, 2, code)
 }
+
+func TestRunExtractAll_WriteErrorDropsSpool(t *testing.T) {
+	dir := t.TempDir()
+	files := map[string]string{
+		".mdsmith.yml": "kinds:\n  note:\n    schema:\n      sections:\n" +
+			"        - heading: \"Summary\"\n" +
+			"kind-assignment:\n  - glob: [\"notes/*.md\"]\n    kinds: [note]\n",
+		"notes/a.md": "# A\n\n## Summary\n\nFirst.\n",
+		"notes/b.md": "# B\n\n## Summary\n\nSecond.\n",
+	}
+	for name, content := range files {
+		path := filepath.Join(dir, name)
+		require.NoError(t, os.MkdirAll(filepath.Dir(path), 0o755))
+		require.NoError(t, os.WriteFile(path, []byte(content), 0o644))
+	}
+	t.Chdir(dir)
+	spoolDir := t.TempDir()
+	t.Setenv("TMPDIR", spoolDir)
+
+	// The first record reaches the msgpack spool; the second fails,
+	// so runExtractAll returns before Close.
+	writes := 0
+	origWrite := extractStreamWrite
+	extractStreamWrite = func(s *encode.Stream, v any) error {
+		if writes++; writes > 1 {
+			return errors.New("disk full")
+		}
+		return origWrite(s, v)
+	}
+	defer func() { extractStreamWrite = origWrite }()
+	var buf bytes.Buffer
+	extractStdout = &buf
+	defer func() { extractStdout = nil }()
+
+	code := runExtractAll(extractOptions{kind: "note", format: encode.Msgpack, all: true})
+	assert.Equal(t, 2, code)
+	assert.Equal(t, 2, writes)
+	assert.Empty(t, buf.String(), "an aborted stream writes no document")
+	left, err := os.ReadDir(spoolDir)
+	require.NoError(t, err)
+	assert.Empty(t, left, "the msgpack spool must be removed")
+}
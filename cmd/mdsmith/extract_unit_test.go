@@ -265,7 +265,7 @@ kind-assignment:
 }
 
 // composedSchemaFor must refuse when required-structure is absent
-// or disabled for the file: gateExtractCheck would skip MDS020 in
+// or disabled for the file: the check gate would skip MDS020 in
 // that configuration, so projecting would emit data for a
 // never-validated file (Copilot review on extract.go).
 func TestComposedSchemaFor_RefusesWhenRuleDisabled(t *testing.T) {
@@ -58,6 +58,7 @@ type buildFixFlags struct {
 	verify             bool
 	jobs               int
 	explain            string
+	sandbox            bool
 }
 
 func (b *buildFixFlags) register(fs *flag.FlagSet) {
@@ -83,6 +84,8 @@ func (b *buildFixFlags) register(fs *flag.FlagSet) {
 	fs.IntVar(&b.jobs, "build-jobs", 1, "Run up to N recipes concurrently")
 	fs.StringVar(&b.explain, "build-explain", "",
 		"Print the ActionID inputs and cache verdict for TARGET; run no recipe")
+	fs.BoolVar(&b.sandbox, "build-sandbox", false,
+		"Run every recipe in the Linux build sandbox, as build.exec.sandbox.enabled does")
 }
 
 // conflict returns a non-empty message when the build-flag combination is
@@ -125,6 +128,7 @@ func (b buildFixFlags) toPassOpts() buildPassOpts {
 		verify:             b.verify,
 		jobs:               b.jobs,
 		explain:            b.explain,
+		sandbox:            b.sandbox,
 	}
 }
 
@@ -0,0 +1,308 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"slices"
+	"strings"
+
+	flag "github.com/spf13/pflag"
+
+	"github.com/jeduden/mdsmith/internal/bytelimit"
+	"github.com/jeduden/mdsmith/internal/docgraph"
+	"github.com/jeduden/mdsmith/internal/linkgraph"
+	"github.com/jeduden/mdsmith/internal/lint"
+)
+
+// graphFormats are the values --format accepts for `graph`.
+var graphFormats = []string{"text", "json", "dot", "mermaid", "graphml"}
+
+// graphKinds are the edge kinds --kind accepts for `graph`.
+var graphKinds = []string{
+	docgraph.KindFileLink, docgraph.KindRefLink, docgraph.KindImage, docgraph.KindInclude,
+	docgraph.KindCatalog, docgraph.KindBuild, docgraph.KindWikilink,
+}
+
+// graphOptions bundles the parsed CLI flags for `graph`.
+type graphOptions struct {
+	configPath   string
+	format       string
+	maxInputSize string
+	entries      []string
+	kinds        []string
+	hubs         int
+	walk         walkCLI
+}
+
+// graphAnalysis is the analysis half of the `graph` report. The text
+// format prints it one row per finding; json nests it next to the
+// graph itself.
+type graphAnalysis struct {
+	Entries       []string       `json:"entries"`
+	Orphans       []string       `json:"orphans"`
+	Unreachable   []string       `json:"unreachable"`
+	IncludeCycles [][]string     `json:"include-cycles"`
+	Components    [][]string     `json:"components"`
+	Hubs          []docgraph.Hub `json:"hubs"`
+}
+
+// graphReport is the json output of `graph`.
+type graphReport struct {
+	Nodes    []string        `json:"nodes"`
+	Edges    []docgraph.Edge `json:"edges"`
+	Analysis graphAnalysis   `json:"analysis"`
+}
+
+// analyzeGraph runs every analysis over g. Unreachable is only
+// computed when there is an entry point to walk from.
+func analyzeGraph(g *docgraph.Graph, entries []string, hubs int) graphAnalysis {
+	a := graphAnalysis{
+		Entries:       entries,
+		Orphans:       g.Orphans(entries),
+		IncludeCycles: g.IncludeCycles(),
+		Components:    g.Components(),
+		Hubs:          g.Hubs(hubs),
+	}
+	if len(entries) > 0 {
+		a.Unreachable = g.Unreachable(entries)
+	}
+	return a
+}
+
+// emitGraph writes g in format. Exit code: 0 on success, 2 on a
+// write error.
+func emitGraph(w io.Writer, g *docgraph.Graph, a graphAnalysis, format string) int {
+	var err error
+	switch format {
+	case "dot":
+		err = docgraph.WriteDOT(w, g)
+	case "mermaid":
+		err = docgraph.WriteMermaid(w, g)
+	case "graphml":
+		err = docgraph.WriteGraphML(w, g)
+	case "json":
+		enc := json.NewEncoder(w)
+		enc.SetIndent("", "  ")
+		err = enc.Encode(graphReport{
+			Nodes:    nonNil(g.Nodes),
+			Edges:    nonNil(g.Edges),
+			Analysis: nonNilAnalysis(a),
+		})
+	default:
+		err = writeGraphText(w, a)
+	}
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "mdsmith: writing output: %v\n", err)
+		return 2
+	}
+	return 0
+}
+
+// writeGraphText prints one row per finding, prefixed by its analysis.
+func writeGraphText(w io.Writer, a graphAnalysis) error {
+	var b strings.Builder
+	for _, p := range a.Orphans {
+		fmt.Fprintf(&b, "orphan %s\n", p)
+	}
+	for _, p := range a.Unreachable {
+		fmt.Fprintf(&b, "unreachable %s\n", p)
+	}
+	for _, c := range a.IncludeCycles {
+		fmt.Fprintf(&b, "include-cycle %s -> %s\n", strings.Join(c, " -> "), c[0])
+	}
+	for _, c := range a.Components {
+		fmt.Fprintf(&b, "component %s\n", strings.Join(c, " "))
+	}
+	for _, h := range a.Hubs {
+		fmt.Fprintf(&b, "hub %d %s\n", h.Incoming, h.Path)
+	}
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+// nonNil returns s, or an empty slice when s is nil, so json emits []
+// rather than null.
+func nonNil[T any](s []T) []T {
+	if s == nil {
+		return []T{}
+	}
+	return s
+}
+
+func nonNilAnalysis(a graphAnalysis) graphAnalysis {
+	a.Entries = nonNil(a.Entries)
+	a.Orphans = nonNil(a.Orphans)
+	a.Unreachable = nonNil(a.Unreachable)
+	a.IncludeCycles = nonNil(a.IncludeCycles)
+	a.Components = nonNil(a.Components)
+	a.Hubs = nonNil(a.Hubs)
+	return a
+}
+
+// parseGraphFlags parses the flags for `mdsmith graph` and returns the
+// options plus the remaining positional arguments.
+func parseGraphFlags(args []string) (graphOptions, []string, error) {
+	fs := flag.NewFlagSet("graph", flag.ContinueOnError)
+	var (
+		opts                        graphOptions
+		noGitignore, followSymlinks bool
+	)
+	fs.StringVarP(&opts.configPath, "config", "c", "", "Override config file path")
+	fs.StringVarP(&opts.format, "format", "f", "text",
+		"Output format: text, json, dot, mermaid, graphml")
+	fs.StringArrayVar(&opts.entries, "entry", nil,
+		"Entry point for the reachability analysis (repeatable; default README.md)")
+	fs.StringSliceVar(&opts.kinds, "kind", nil,
+		"Keep only these edge kinds (comma-separated or repeated)")
+	fs.IntVar(&opts.hubs, "hubs", 10, "Number of most-linked files to report")
+	fs.BoolVar(&noGitignore, "no-gitignore", false, "Disable .gitignore filtering when walking directories")
+	fs.BoolVar(&followSymlinks, "follow-symlinks", false,
+		"Follow symlinks; omitted defers to follow-symlinks config (default skip); "+
+			"=false forces skip over any config opt-in")
+	fs.StringVar(&opts.maxInputSize, "max-input-size", "",
+		"Maximum file size to process (e.g. 2MB, 500KB, 0=unlimited)")
+
+	fs.Usage = func() {
+		fmt.Fprint(os.Stderr, "Usage: mdsmith graph [flags]\n\n"+
+			"Emit the whole workspace dependency graph — links, includes,\n"+
+			"catalogs, build inputs, and wikilinks — and analyze it:\n"+
+			"orphans, files unreachable from the entry points, include\n"+
+			"cycles, strongly connected components, and the most-linked\n"+
+			"files.\n\n"+
+			"Exit codes: 0 success, 2 error\n\nFlags:\n")
+		fs.PrintDefaults()
+	}
+
+	if err := fs.Parse(args); err != nil {
+		return opts, nil, err
+	}
+	opts.walk = walkCLI{
+		noGitignore:    noGitignore,
+		followSymlinks: followSymlinksOverride(fs, followSymlinks),
+	}
+	return opts, fs.Args(), nil
+}
+
+// validateGraphOptions rejects an unknown format, edge kind, or a
+// negative --hubs. It returns -1 when the options are valid.
+func validateGraphOptions(opts graphOptions, posArgs []string) int {
+	if len(posArgs) > 0 {
+		fmt.Fprint(os.Stderr, "mdsmith: graph takes no file arguments\n")
+		return 2
+	}
+	if !slices.Contains(graphFormats, opts.format) {
+		fmt.Fprintf(os.Stderr, "mdsmith: unknown --format %q (want %s)\n",
+			opts.format, strings.Join(graphFormats, ", "))
+		return 2
+	}
+	for _, k := range opts.kinds {
+		if !slices.Contains(graphKinds, k) {
+			fmt.Fprintf(os.Stderr, "mdsmith: unknown --kind %q (want %s)\n",
+				k, strings.Join(graphKinds, ", "))
+			return 2
+		}
+	}
+	if opts.hubs < 0 {
+		fmt.Fprintf(os.Stderr, "mdsmith: --hubs must be >= 0 (got %d)\n", opts.hubs)
+		return 2
+	}
+	return -1
+}
+
+// graphEntries resolves the entry points: the --entry values, each of
+// which must be a graph node, or README.md when present.
+func graphEntries(g *docgraph.Graph, flagged []string) ([]string, error) {
+	if len(flagged) == 0 {
+		if g.Has("README.md") {
+			return []string{"README.md"}, nil
+		}
+		return nil, nil
+	}
+	entries := make([]string, 0, len(flagged))
+	for _, e := range flagged {
+		p := normalizeWorkspacePath(e)
+		if !g.Has(p) {
+			return nil, fmt.Errorf("--entry %q is not a workspace Markdown file", e)
+		}
+		entries = append(entries, p)
+	}
+	return entries, nil
+}
+
+// collectGraph parses every file and returns the workspace graph.
+// Per-file read failures do not abort the walk; they are returned so
+// the caller can report them next to the partial graph.
+func collectGraph(
+	files []string, rootDir string, maxBytes int64, stripFrontMatter bool,
+) (*docgraph.Graph, []error) {
+	rels := make([]string, len(files))
+	for i, src := range files {
+		rels[i] = workspaceRelativePath(src, rootDir)
+	}
+	var wikilinks *linkgraph.WikilinkIndex
+	if rootDir != "" {
+		wikilinks = linkgraph.WikilinkIndexFor(nil, "", lint.OpenRootFS(rootDir))
+	}
+	var (
+		edges []docgraph.Edge
+		errs  []error
+	)
+	for i, src := range files {
+		data, err := bytelimit.ReadFileLimited(src, maxBytes)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("reading %s: %w", rels[i], err))
+			continue
+		}
+		// NewFileFromSource never errors; goldmark always produces an AST.
+		f, _ := lint.NewFileFromSource(src, data, stripFrontMatter) //nolint:errcheck
+		edges = append(edges, docgraph.FileEdges(rels[i], f, rels, wikilinks)...)
+	}
+	return docgraph.New(rels, edges), errs
+}
+
+// runGraph implements the "graph" subcommand: emit and analyze the
+// whole workspace dependency graph.
+func runGraph(args []string) int {
+	opts, posArgs, err := parseGraphFlags(args)
+	if err != nil {
+		if code := reportFlagParseErr(err, os.Stderr, "mdsmith: graph"); code >= 0 {
+			return code
+		}
+	}
+	if code := validateGraphOptions(opts, posArgs); code >= 0 {
+		return code
+	}
+
+	cfg, cfgPath, _, files, code := discoverFiles(opts.configPath, false, opts.walk)
+	if code > 0 {
+		return code
+	}
+	// code 0 means config + discovery found no files: an empty graph.
+	g, errs := docgraph.New(nil, nil), []error(nil)
+	if code < 0 {
+		maxBytes, err := resolveMaxInputBytes(cfg, opts.maxInputSize)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "mdsmith: %v\n", err)
+			return 2
+		}
+		g, errs = collectGraph(files, rootDirFromConfig(cfgPath), maxBytes, frontMatterEnabled(cfg))
+	}
+	for _, e := range errs {
+		fmt.Fprintf(os.Stderr, "mdsmith: %v\n", e)
+	}
+	if len(opts.kinds) > 0 {
+		g = g.Filter(opts.kinds)
+	}
+	entries, err := graphEntries(g, opts.entries)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "mdsmith: %v\n", err)
+		return 2
+	}
+	code = emitGraph(os.Stdout, g, analyzeGraph(g, entries, opts.hubs), opts.format)
+	if code == 0 && len(errs) > 0 {
+		return 2
+	}
+	return code
+}
@@ -13,6 +13,7 @@ import (
 	"github.com/jeduden/mdsmith/internal/config"
 	"github.com/jeduden/mdsmith/internal/kindsout"
 	"github.com/jeduden/mdsmith/internal/lint"
+	"github.com/jeduden/mdsmith/pkg/mdsmith"
 )
 
 const kindsUsage = `Usage: mdsmith kinds <subcommand> [args]
@@ -298,43 +299,49 @@ func resolveFileFromCLI(path string) (*config.FileResolution, *config.Config, in
 	if code != 0 {
 		return nil, nil, code
 	}
+	maxBytes, err := resolveMaxInputBytes(cfg, "")
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "mdsmith: %v\n", err)
+		return nil, nil, 2
+	}
 
+	sess := sessionForCLI(cfg, cfgPath)
+	defer sess.Dispose()
+	res, err := resolveFileWith(cfg, sess, path, maxBytes)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "mdsmith: %v\n", err)
+		return nil, nil, 2
+	}
+	return res, cfg, 0
+}
+
+// resolveFileWith is resolveFileFromCLI for a caller that already
+// holds the config and a session, such as a command resolving many
+// files. Errors are returned rather than printed.
+func resolveFileWith(
+	cfg *config.Config, sess *mdsmith.Session, path string, maxBytes int64,
+) (*config.FileResolution, error) {
 	var fmKinds []string
 	var fmFields map[string]any
 	if frontMatterEnabled(cfg) {
-		maxBytes, err := resolveMaxInputBytes(cfg, "")
-		if err != nil {
-			fmt.Fprintf(os.Stderr, "mdsmith: %v\n", err)
-			return nil, nil, 2
-		}
+		var err error
 		fmKinds, fmFields, err = readFrontMatter(cfg, path, maxBytes)
 		if err != nil {
-			fmt.Fprintf(os.Stderr, "mdsmith: reading %s: %v\n", path, err)
-			return nil, nil, 2
+			return nil, fmt.Errorf("reading %s: %w", path, err)
 		}
 		if err := config.ValidateFrontMatterKinds(cfg, path, fmKinds); err != nil {
-			fmt.Fprintf(os.Stderr, "mdsmith: %v\n", err)
-			return nil, nil, 2
+			return nil, err
 		}
 	} else {
 		// front-matter disabled: no kinds from front matter, but still
 		// attempt an open/read to mirror the engine's readability and
 		// max-input-size checks (os.Stat passes on directories and
 		// unreadable paths).
-		maxBytes, err := resolveMaxInputBytes(cfg, "")
-		if err != nil {
-			fmt.Fprintf(os.Stderr, "mdsmith: %v\n", err)
-			return nil, nil, 2
-		}
 		if _, err := bytelimit.ReadFileLimited(path, maxBytes); err != nil {
-			fmt.Fprintf(os.Stderr, "mdsmith: reading %s: %v\n", path, err)
-			return nil, nil, 2
+			return nil, fmt.Errorf("reading %s: %w", path, err)
 		}
 	}
-
-	sess := sessionForCLI(cfg, cfgPath)
-	defer sess.Dispose()
-	return sess.ResolveFile(path, fmKinds, fmFields), cfg, 0
+	return sess.ResolveFile(path, fmKinds, fmFields), nil
 }
 
 // runKindsResolve prints the resolved kind list and merged rule config
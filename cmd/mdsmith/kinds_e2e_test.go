@@ -727,3 +727,46 @@ func TestKinds_PathPreservesAbsoluteSchema(t *testing.T) {
 	require.Equal(t, 0, code)
 	assert.Equal(t, "/etc/passwd", strings.TrimSpace(stdout))
 }
+
+// A backlinks kind filter sees kinds assigned by kind-assignment
+// globs, not only front-matter kinds.
+func TestKinds_BacklinksKindFromAssignment(t *testing.T) {
+	cfg := `kinds:
+  note: {}
+kind-assignment:
+  - glob: ["notes/*.md"]
+    kinds: [note]
+`
+	dir := kindsTestDir(t, cfg, map[string]string{
+		"target.md":     "# Target\n\n<?backlinks\nkind: note\n?>\n<?/backlinks?>\n",
+		"notes/a.md":    "# Note A\n\nSee [target](../target.md).\n",
+		"other/page.md": "# Page\n\nSee [target](../target.md).\n",
+	})
+	_, stderr, code := runBinaryInDir(t, dir, "", "fix", "target.md")
+	require.Equal(t, 0, code, "stderr=%s", stderr)
+	got, err := os.ReadFile(filepath.Join(dir, "target.md"))
+	require.NoError(t, err)
+	assert.Equal(t, "# Target\n\n<?backlinks\nkind: note\n?>\n\n"+
+		"- [Note A](notes/a.md)\n\n<?/backlinks?>\n", string(got))
+}
+
+// A tree kind filter sees kinds assigned by kind-assignment globs.
+func TestKinds_TreeKindFromAssignment(t *testing.T) {
+	cfg := `kinds:
+  guide: {}
+kind-assignment:
+  - glob: ["docs/guides/*.md"]
+    kinds: [guide]
+`
+	dir := kindsTestDir(t, cfg, map[string]string{
+		"docs/index.md":        "# Docs\n\n<?tree\nkind: guide\n?>\n<?/tree?>\n",
+		"docs/guides/setup.md": "# Setup\n",
+		"docs/faq.md":          "# FAQ\n",
+	})
+	_, stderr, code := runBinaryInDir(t, dir, "", "fix", "docs/index.md")
+	require.Equal(t, 0, code, "stderr=%s", stderr)
+	got, err := os.ReadFile(filepath.Join(dir, "docs/index.md"))
+	require.NoError(t, err)
+	assert.Equal(t, "# Docs\n\n<?tree\nkind: guide\n?>\n\n"+
+		"- guides\n  - [Setup](guides/setup.md)\n\n<?/tree?>\n", string(got))
+}
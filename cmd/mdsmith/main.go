@@ -11,6 +11,7 @@ import (
 
 	flag "github.com/spf13/pflag"
 
+	buildexec "github.com/jeduden/mdsmith/internal/build"
 	"github.com/jeduden/mdsmith/internal/bytelimit"
 	"github.com/jeduden/mdsmith/internal/config"
 	"github.com/jeduden/mdsmith/internal/discovery"
@@ -32,6 +33,9 @@ import (
 )
 
 func main() {
+	if buildexec.IsSandboxChild() {
+		buildexec.SandboxChildMain()
+	}
 	os.Exit(run())
 }
 
@@ -41,10 +45,13 @@ Commands:
   check             Lint Markdown files (default when given file arguments)
   fix               Auto-fix lint issues in place
   export            Write a portable, directive-free copy of a Markdown file
+  render            Render a Markdown file to HTML as mdsmith parses it
   extract           Emit a kind-conformant file as a JSON/YAML/msgpack data tree
+  scaffold          Render or patch a kind-conformant file from a data record
   list              Walk the workspace and emit matches (files or link records)
   deps              Show a file's dependency-graph edges (includes, links, …)
-  rename            Rename a heading or link-ref label and rewrite dependents
+  graph             Export and analyze the whole workspace dependency graph
+  rename            Rename a heading or link-ref label, or renumber headings, and rewrite dependents
   help              Show help for rules and topics
   metrics           Show and rank shared Markdown metrics
   merge-driver      Git merge driver for regenerable sections
@@ -95,12 +102,18 @@ func dispatch(first string, args []string) int {
 		return runFix(args)
 	case "export":
 		return runExport(args)
+	case "render":
+		return runRender(args)
 	case "extract":
 		return runExtract(args)
+	case "scaffold":
+		return runScaffold(args)
 	case "list":
 		return runList(args)
 	case "deps":
 		return runDeps(args)
+	case "graph":
+		return runGraph(args)
 	case "rename":
 		return runRename(args)
 	case "help":
@@ -149,6 +162,11 @@ type queryOptions struct {
 	verbose      bool
 	configPath   string
 	maxInputSize string
+	fields       []string
+	format       string
+	sort         []string
+	groupBy      string
+	countBy      string
 }
 
 func parseQueryFlags(args []string) (queryOptions, []string, error) {
@@ -160,10 +178,18 @@ func parseQueryFlags(args []string) (queryOptions, []string, error) {
 	fs.StringVarP(&opts.configPath, "config", "c", "", "Override config file path")
 	fs.StringVar(&opts.maxInputSize, "max-input-size", "",
 		"Maximum file size to process (e.g. 2MB, 500KB, 0=unlimited)")
+	fs.StringArrayVar(&opts.fields, "fields", nil,
+		"Front-matter fields to print per file (CUE paths, comma-separated)")
+	fs.StringVarP(&opts.format, "format", "f", "text", "Output format: text, json, yaml, csv, tsv")
+	fs.StringArrayVar(&opts.sort, "sort", nil,
+		"Sort by these fields (prefix - for descending); default is path order")
+	fs.StringVar(&opts.groupBy, "group-by", "", "Group files by the value of this field")
+	fs.StringVar(&opts.countBy, "count-by", "", "Print how many files have each value of this field")
 
 	fs.Usage = func() {
 		fmt.Fprint(os.Stderr, "Usage: mdsmith list query [flags] <cue-expr> [files...]\n\n"+
 			"Print paths of Markdown files whose front matter satisfies a CUE expression.\n"+
+			"--fields, --sort, --group-by, and --count-by add front-matter values to the output.\n"+
 			"With no file arguments, searches the current directory recursively.\n\n"+
 			"Exit codes: 0 match, 1 no match, 2 error\n\nFlags:\n")
 		fs.PrintDefaults()
@@ -196,6 +222,11 @@ func runQuery(args []string) int {
 		fmt.Fprintf(os.Stderr, "mdsmith: %v\n", err)
 		return 2
 	}
+	report, err := newQueryReport(opts)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "mdsmith: list query: %v\n", err)
+		return 2
+	}
 
 	if len(fileArgs) == 0 {
 		fileArgs = []string{"."}
@@ -218,6 +249,19 @@ func runQuery(args []string) int {
 		return 2
 	}
 
+	if report.projects() {
+		withDoc := matcher.UsesDoc() || report.usesDoc()
+		rows := collectQueryRows(matcher, files, opts.verbose, maxBytes, withDoc)
+		if err := report.write(os.Stdout, rows); err != nil {
+			fmt.Fprintf(os.Stderr, "mdsmith: error writing output: %v\n", err)
+			return 2
+		}
+		if len(rows) > 0 {
+			return 0
+		}
+		return 1
+	}
+
 	delim := "\n"
 	if opts.nul {
 		delim = "\x00"
@@ -235,7 +279,7 @@ func runQuery(args []string) int {
 func queryFiles(matcher *query.Matcher, files []string, delim string, verbose bool, maxBytes int64) int {
 	matched := 0
 	for _, f := range files {
-		fm, err := readFrontMatterRaw(f, maxBytes)
+		fm, err := readQueryInput(f, maxBytes, matcher.UsesDoc())
 		if err != nil {
 			if verbose {
 				fmt.Fprintf(os.Stderr, "skip %s: %v\n", f, err)
@@ -258,6 +302,25 @@ func queryFiles(matcher *query.Matcher, files []string, delim string, verbose bo
 	return matched
 }
 
+// readQueryInput returns what a query reads from a file: its front
+// matter and, when withDoc is set, its document facts under $doc.
+// With $doc, a file without front matter still yields the facts, so
+// content-only expressions reach it.
+func readQueryInput(path string, maxBytes int64, withDoc bool) (map[string]any, error) {
+	if !withDoc {
+		return readFrontMatterRaw(path, maxBytes)
+	}
+	data, err := bytelimit.ReadFileLimited(path, maxBytes)
+	if err != nil {
+		return nil, err
+	}
+	fm, err := parseFrontMatterRaw(data)
+	if err != nil {
+		return nil, err
+	}
+	return query.WithDoc(fm, path, data), nil
+}
+
 // readFrontMatterRaw reads a file, strips front matter, and
 // unmarshals YAML into map[string]any (preserving numeric types).
 func readFrontMatterRaw(path string, maxBytes int64) (map[string]any, error) {
@@ -265,6 +328,12 @@ func readFrontMatterRaw(path string, maxBytes int64) (map[string]any, error) {
 	if err != nil {
 		return nil, err
 	}
+	return parseFrontMatterRaw(data)
+}
+
+// parseFrontMatterRaw unmarshals the front matter of data, or returns
+// nil when data has none.
+func parseFrontMatterRaw(data []byte) (map[string]any, error) {
 	prefix, _ := lint.StripFrontMatter(data)
 	if prefix == nil {
 		return nil, nil
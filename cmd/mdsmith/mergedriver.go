@@ -21,9 +21,10 @@ const mergeDriverUsage = `Usage: mdsmith merge-driver <subcommand> [args]
 Subcommands:
   run <base> <ours> <theirs> <pathname>
         Run as a git custom merge driver. Strips conflict
-        markers inside regenerable sections (catalog, include),
-        runs mdsmith fix in memory to regenerate them, and exits
-        non-zero if unresolved conflict markers remain. Only the
+        markers inside regenerable sections (catalog, include,
+        backlinks, and the other directives), runs mdsmith fix
+        in memory to regenerate them, and exits non-zero if
+        unresolved conflict markers remain. Only the
         <ours> temp file is written; the worktree <pathname> is
         never touched, so the parent merge or rebase never sees
         the path as locally modified.
@@ -330,17 +331,28 @@ func mergeDriverRules() []rule.Rule {
 // matching, and — because git invokes merge drivers from the
 // worktree root — the dirFS derived from it resolves neighbour
 // files (include sources, catalog globs) exactly as a fix of the
-// on-disk file would. Nothing is written to disk.
+// on-disk file would. RootDir gives workspace-wide directives
+// (backlinks) the same project root `mdsmith fix` uses; an absolute
+// path is made worktree-relative first, since root-relative lookups
+// join it onto the root. Nothing is written to disk.
 func fixMergedSource(path string, source []byte, maxBytes int64) ([]byte, error) {
-	cfg, _, err := loadConfig("")
+	cfg, cfgPath, err := loadConfig("")
 	if err != nil {
 		return nil, fmt.Errorf("loading config: %w", err)
 	}
+	if filepath.IsAbs(path) {
+		if cwd, err := os.Getwd(); err == nil {
+			if rel, err := filepath.Rel(cwd, path); err == nil && filepath.IsLocal(rel) {
+				path = rel
+			}
+		}
+	}
 
 	return fixpkg.Source(fixpkg.SourceOptions{
 		Config:           cfg,
 		Rules:            mergeDriverRules(),
 		Path:             path,
+		RootDir:          rootDirFromConfig(cfgPath),
 		Source:           source,
 		StripFrontMatter: frontMatterEnabled(cfg),
 		MaxInputBytes:    maxBytes,
@@ -348,16 +360,16 @@ func fixMergedSource(path string, source []byte, maxBytes int64) ([]byte, error)
 }
 
 // regenDirectiveNames returns the directive names whose content
-// is regenerated by mdsmith fix. Names are discovered from
-// registered rules that implement gensection.Directive.
+// is regenerated by mdsmith fix: the registered gensection.Directive
+// rules, plus the names the gensection.DirectiveSet rules serve under
+// the project config (see githooks.DirectiveNames). A config that
+// fails to load contributes none.
 func regenDirectiveNames() []string {
-	var names []string
-	for _, r := range rule.All() {
-		if d, ok := r.(gensection.Directive); ok {
-			names = append(names, d.Name())
-		}
+	cfg, _, err := loadConfig("")
+	if err != nil {
+		cfg = nil
 	}
-	return names
+	return githooks.DirectiveNames(cfg)
 }
 
 // stripSectionConflicts removes git conflict markers from lines
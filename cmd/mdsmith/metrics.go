@@ -21,9 +21,11 @@ import (
 const metricsUsageText = `Usage: mdsmith metrics <command> [flags] [files...]
 
 Commands:
-  get      Emit all metrics for a single file as a data object
-  list     List available metrics from the shared registry
-  rank     Rank files by selected metrics
+  get       Emit all metrics for a single file as a data object
+  list      List available metrics from the shared registry
+  rank      Rank files (or directories, or the workspace) by selected metrics
+  snapshot  Write a timestamped metrics record under .mdsmith/metrics/
+  diff      Compare two metrics snapshots and report regressions
 `
 
 func runMetrics(args []string) int {
@@ -39,6 +41,10 @@ func runMetrics(args []string) int {
 		return runMetricsList(args[1:])
 	case "rank":
 		return runMetricsRank(args[1:])
+	case "snapshot":
+		return runMetricsSnapshot(args[1:])
+	case "diff":
+		return runMetricsDiff(args[1:])
 	default:
 		fmt.Fprintf(os.Stderr, "mdsmith: metrics: unknown command %q\n", args[0])
 		return 2
@@ -106,6 +112,8 @@ type metricsRankOptions struct {
 	metricsRaw     string
 	byRaw          string
 	orderRaw       string
+	scopeRaw       string
+	statRaw        string
 	top            int
 	format         string
 	noGitignore    bool
@@ -135,7 +143,9 @@ func parseMetricsRankOptions(args []string) (metricsRankOptions, []string, error
 	fs.StringVar(&opts.metricsRaw, "metrics", "", "Comma-separated metrics (defaults to registry defaults)")
 	fs.StringVar(&opts.byRaw, "by", "", "Metric to sort by")
 	fs.StringVar(&opts.orderRaw, "order", "", "Sort order: asc or desc (defaults by metric)")
-	fs.IntVar(&opts.top, "top", 0, "Limit results to top N files (0 = all)")
+	fs.StringVar(&opts.scopeRaw, "scope", "file", "Row scope: file, dir, or workspace")
+	fs.StringVar(&opts.statRaw, "stat", "sum", "Statistic --by sorts dir/workspace rows on: sum, mean, p50, p90, max")
+	fs.IntVar(&opts.top, "top", 0, "Limit results to top N rows (0 = all)")
 	fs.StringVarP(&opts.format, "format", "f", "text", "Output format: text, json, yaml")
 	fs.BoolVar(&opts.noGitignore, "no-gitignore", false, "Disable .gitignore filtering when walking directories")
 	fs.BoolVar(&followSymlinks, "follow-symlinks", false,
@@ -149,6 +159,8 @@ func parseMetricsRankOptions(args []string) (metricsRankOptions, []string, error
 			os.Stderr,
 			"Usage: mdsmith metrics rank [flags] [files...]\n\n"+
 				"Compute selected metrics and rank Markdown files.\n"+
+				"--scope dir or workspace aggregates the files into one row per\n"+
+				"directory or one row overall (sum, mean, p50, p90, max).\n"+
 				"With no file arguments, defaults to the current directory.\n\n"+
 				"Flags:\n",
 		)
@@ -182,6 +194,11 @@ func executeMetricsRank(opts metricsRankOptions, fileArgs []string) int {
 		fmt.Fprintf(os.Stderr, "mdsmith: %v\n", err)
 		return 2
 	}
+	scope, stat, err := resolveRankAggregation(opts)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "mdsmith: %v\n", err)
+		return 2
+	}
 
 	cfg, _, err := loadConfig(opts.configPath)
 	if err != nil {
@@ -206,10 +223,18 @@ func executeMetricsRank(opts metricsRankOptions, fileArgs []string) int {
 		fmt.Fprintf(os.Stderr, "mdsmith: %v\n", err)
 		return 2
 	}
-	metricspkg.SortRows(rows, byDef, order)
-	rows = metricspkg.LimitRows(rows, opts.top)
 
-	if err := writeRankOutput(os.Stdout, opts.format, rows, defs); err != nil {
+	if scope != metricspkg.ScopeFile {
+		groups := metricspkg.Aggregate(rows, defs, scope)
+		metricspkg.SortGroups(groups, byDef, stat, order)
+		groups = metricspkg.LimitGroups(groups, opts.top)
+		err = writeAggregateOutput(os.Stdout, opts.format, groups, defs)
+	} else {
+		metricspkg.SortRows(rows, byDef, order)
+		rows = metricspkg.LimitRows(rows, opts.top)
+		err = writeRankOutput(os.Stdout, opts.format, rows, defs)
+	}
+	if err != nil {
 		fmt.Fprintf(os.Stderr, "mdsmith: writing output: %v\n", err)
 		return 2
 	}
@@ -217,6 +242,21 @@ func executeMetricsRank(opts metricsRankOptions, fileArgs []string) int {
 	return 0
 }
 
+// resolveRankAggregation parses --scope and --stat. --stat only
+// applies to aggregated scopes; it is still validated for file
+// scope so a typo never passes silently.
+func resolveRankAggregation(opts metricsRankOptions) (metricspkg.Scope, metricspkg.Stat, error) {
+	scope, err := metricspkg.ParseAggregateScope(opts.scopeRaw)
+	if err != nil {
+		return "", "", err
+	}
+	stat, err := metricspkg.ParseStat(opts.statRaw)
+	if err != nil {
+		return "", "", err
+	}
+	return scope, stat, nil
+}
+
 func validateOutputFormat(format string) error {
 	switch format {
 	case "text", "json", "yaml":
@@ -482,11 +522,23 @@ func writeGetOutput(w io.Writer, format string, row metricspkg.Row, defs []metri
 	}
 }
 
-func writeMetricsGetJSON(w io.Writer, row metricspkg.Row, defs []metricspkg.Definition) error {
+// getItem builds the JSON/YAML object for `metrics get`. A metric
+// that picked a formula (readability, reading-ease) also gets a
+// "<name>-formula" key naming it.
+func getItem(row metricspkg.Row, defs []metricspkg.Definition) map[string]any {
 	item := map[string]any{"path": row.Path}
 	for _, def := range defs {
-		item[def.Name] = metricspkg.JSONValue(def, row.Metrics[def.Name])
+		v := row.Metrics[def.Name]
+		item[def.Name] = metricspkg.JSONValue(def, v)
+		if v.Formula != "" {
+			item[def.Name+"-formula"] = v.Formula
+		}
 	}
+	return item
+}
+
+func writeMetricsGetJSON(w io.Writer, row metricspkg.Row, defs []metricspkg.Definition) error {
+	item := getItem(row, defs)
 	enc := json.NewEncoder(w)
 	enc.SetIndent("", "  ")
 	enc.SetEscapeHTML(false)
@@ -494,10 +546,7 @@ func writeMetricsGetJSON(w io.Writer, row metricspkg.Row, defs []metricspkg.Defi
 }
 
 func writeMetricsGetYAML(w io.Writer, row metricspkg.Row, defs []metricspkg.Definition) error {
-	item := map[string]any{"path": row.Path}
-	for _, def := range defs {
-		item[def.Name] = metricspkg.JSONValue(def, row.Metrics[def.Name])
-	}
+	item := getItem(row, defs)
 	enc := yaml.NewEncoder(w)
 	enc.SetIndent(2)
 	if err := enc.Encode(item); err != nil {
@@ -513,7 +562,12 @@ func writeMetricsGetText(w io.Writer, row metricspkg.Row, defs []metricspkg.Defi
 	_, _ = fmt.Fprintln(tw, "NAME\tVALUE")
 	_, _ = fmt.Fprintf(tw, "path\t%s\n", row.Path)
 	for _, def := range defs {
-		_, _ = fmt.Fprintf(tw, "%s\t%s\n", def.Name, metricspkg.FormatValue(def, row.Metrics[def.Name]))
+		v := row.Metrics[def.Name]
+		text := metricspkg.FormatValue(def, v)
+		if v.Formula != "" {
+			text += " (" + v.Formula + ")"
+		}
+		_, _ = fmt.Fprintf(tw, "%s\t%s\n", def.Name, text)
 	}
 	return tw.Flush()
 }
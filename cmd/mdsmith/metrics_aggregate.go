@@ -0,0 +1,90 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+
+	"gopkg.in/yaml.v3"
+
+	metricspkg "github.com/jeduden/mdsmith/internal/metrics"
+)
+
+func writeAggregateOutput(
+	w io.Writer,
+	format string,
+	groups []metricspkg.Group,
+	defs []metricspkg.Definition,
+) error {
+	switch format {
+	case "text":
+		return writeAggregateText(w, groups, defs)
+	case "json":
+		enc := json.NewEncoder(w)
+		enc.SetIndent("", "  ")
+		enc.SetEscapeHTML(false)
+		return enc.Encode(aggregateItems(groups, defs))
+	case "yaml":
+		enc := yaml.NewEncoder(w)
+		enc.SetIndent(2)
+		if err := enc.Encode(aggregateItems(groups, defs)); err != nil {
+			return err
+		}
+		return enc.Close()
+	default:
+		return fmt.Errorf("unknown format %q (supported: text, json, yaml)", format)
+	}
+}
+
+// writeAggregateText prints one line per group and metric, so the
+// five statistics line up as columns instead of multiplying the
+// header by the metric count.
+func writeAggregateText(w io.Writer, groups []metricspkg.Group, defs []metricspkg.Definition) error {
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	// tabwriter buffers all writes internally; only Flush reaches the underlying writer.
+	headers := []string{"PATH", "FILES", "METRIC"}
+	for _, stat := range metricspkg.Stats {
+		headers = append(headers, strings.ToUpper(string(stat)))
+	}
+	_, _ = fmt.Fprintln(tw, strings.Join(headers, "\t"))
+
+	for _, g := range groups {
+		for _, def := range defs {
+			cols := []string{g.Path, strconv.Itoa(g.Files), def.Name}
+			summary := g.Metrics[def.Name]
+			for _, stat := range metricspkg.Stats {
+				cols = append(cols, metricspkg.FormatValue(
+					metricspkg.StatDefinition(def, stat), summary.Value(stat)))
+			}
+			_, _ = fmt.Fprintln(tw, strings.Join(cols, "\t"))
+		}
+	}
+	return tw.Flush()
+}
+
+// aggregateItems builds the json/yaml shape: one object per group
+// with path, files, and one {sum, mean, p50, p90, max} object per
+// metric, mirroring rank's one-key-per-metric layout.
+func aggregateItems(groups []metricspkg.Group, defs []metricspkg.Definition) []map[string]any {
+	items := make([]map[string]any, 0, len(groups))
+	for _, g := range groups {
+		item := map[string]any{
+			"path":  g.Path,
+			"files": g.Files,
+		}
+		for _, def := range defs {
+			summary := g.Metrics[def.Name]
+			stats := make(map[string]any, len(metricspkg.Stats))
+			for _, stat := range metricspkg.Stats {
+				stats[string(stat)] = metricspkg.JSONValue(
+					metricspkg.StatDefinition(def, stat), summary.Value(stat))
+			}
+			item[def.Name] = stats
+		}
+		items = append(items, item)
+	}
+	return items
+}
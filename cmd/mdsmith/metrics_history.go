@@ -0,0 +1,395 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	flag "github.com/spf13/pflag"
+	"gopkg.in/yaml.v3"
+
+	"github.com/jeduden/mdsmith/internal/lint"
+	metricspkg "github.com/jeduden/mdsmith/internal/metrics"
+)
+
+// snapshotNow is the clock `metrics snapshot` stamps records with;
+// tests pin it to get a deterministic file name.
+var snapshotNow = time.Now
+
+type metricsSnapshotOptions struct {
+	configPath     string
+	metricsRaw     string
+	output         string
+	noGitignore    bool
+	followSymlinks *bool
+	maxInputSize   string
+}
+
+func runMetricsSnapshot(args []string) int {
+	opts, fileArgs, err := parseMetricsSnapshotOptions(args)
+	if err != nil {
+		return reportFlagParseErr(err, os.Stderr, "mdsmith: metrics snapshot")
+	}
+	return executeMetricsSnapshot(os.Stdout, opts, fileArgs)
+}
+
+func parseMetricsSnapshotOptions(args []string) (metricsSnapshotOptions, []string, error) {
+	fs := flag.NewFlagSet("metrics snapshot", flag.ContinueOnError)
+	var opts metricsSnapshotOptions
+	var followSymlinks bool
+
+	fs.StringVarP(&opts.configPath, "config", "c", "", "Override config file path")
+	fs.StringVar(&opts.metricsRaw, "metrics", "", "Comma-separated metrics (defaults to every file metric)")
+	fs.StringVarP(&opts.output, "output", "o", "",
+		"Snapshot path (default .mdsmith/metrics/<UTC timestamp>.json next to the config)")
+	fs.BoolVar(&opts.noGitignore, "no-gitignore", false, "Disable .gitignore filtering when walking directories")
+	fs.BoolVar(&followSymlinks, "follow-symlinks", false,
+		"Follow symlinks; omitted defers to follow-symlinks config (default skip); "+
+			"=false forces skip over any config opt-in")
+	fs.StringVar(&opts.maxInputSize, "max-input-size", "",
+		"Maximum file size to process (e.g. 2MB, 500KB, 0=unlimited)")
+
+	fs.Usage = func() {
+		fmt.Fprintf(
+			os.Stderr,
+			"Usage: mdsmith metrics snapshot [flags] [files...]\n\n"+
+				"Compute metrics for Markdown files and write them as a timestamped\n"+
+				"JSON record. Prints the path written. With no file arguments,\n"+
+				"defaults to the current directory.\n\n"+
+				"Flags:\n",
+		)
+		fs.PrintDefaults()
+	}
+
+	if err := fs.Parse(args); err != nil {
+		return metricsSnapshotOptions{}, nil, err
+	}
+	opts.followSymlinks = followSymlinksOverride(fs, followSymlinks)
+
+	fileArgs := fs.Args()
+	if len(fileArgs) == 0 {
+		fileArgs = []string{"."}
+	}
+	return opts, fileArgs, nil
+}
+
+func executeMetricsSnapshot(w io.Writer, opts metricsSnapshotOptions, fileArgs []string) int {
+	defs, err := resolveSnapshotMetrics(opts.metricsRaw)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "mdsmith: %v\n", err)
+		return 2
+	}
+
+	cfg, cfgPath, err := loadConfig(opts.configPath)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "mdsmith: %v\n", err)
+		return 2
+	}
+	files, err := lint.ResolveFilesWithOpts(fileArgs, resolveOpts(cfg, walkCLI{
+		noGitignore:    opts.noGitignore,
+		followSymlinks: opts.followSymlinks,
+	}))
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "mdsmith: %v\n", err)
+		return 2
+	}
+	maxBytes, err := resolveMaxInputBytes(cfg, opts.maxInputSize)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "mdsmith: %v\n", err)
+		return 2
+	}
+
+	rows, err := metricspkg.Collect(files, defs, maxBytes)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "mdsmith: %v\n", err)
+		return 2
+	}
+
+	now := snapshotNow()
+	out := opts.output
+	if out == "" {
+		out = filepath.Join(configRoot(cfgPath), metricspkg.SnapshotDir, metricspkg.SnapshotFileName(now))
+	}
+	if err := writeSnapshotFile(out, metricspkg.NewSnapshot(rows, defs, now)); err != nil {
+		fmt.Fprintf(os.Stderr, "mdsmith: %v\n", err)
+		return 2
+	}
+	_, _ = fmt.Fprintln(w, out)
+	return 0
+}
+
+// resolveSnapshotMetrics defaults to every file metric rather than
+// rank's default columns: a trend record should not need to be
+// re-taken when someone later wants readability history.
+func resolveSnapshotMetrics(raw string) ([]metricspkg.Definition, error) {
+	names := metricspkg.SplitList(raw)
+	if len(names) == 0 {
+		return metricspkg.ForScope(metricspkg.ScopeFile), nil
+	}
+	return metricspkg.Resolve(metricspkg.ScopeFile, names)
+}
+
+// configRoot returns the directory holding the loaded config, or
+// "." when no config file was found.
+func configRoot(cfgPath string) string {
+	if cfgPath == "" {
+		return "."
+	}
+	return filepath.Dir(cfgPath)
+}
+
+func writeSnapshotFile(path string, snap metricspkg.Snapshot) error {
+	data, err := json.MarshalIndent(snap, "", "  ")
+	if err != nil {
+		return fmt.Errorf("encoding snapshot: %w", err)
+	}
+	data = append(data, '\n')
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return fmt.Errorf("creating snapshot directory: %w", err)
+	}
+	if err := os.WriteFile(path, data, 0o644); err != nil {
+		return fmt.Errorf("writing snapshot: %w", err)
+	}
+	return nil
+}
+
+type metricsDiffOptions struct {
+	metricsRaw string
+	scopeRaw   string
+	statRaw    string
+	threshold  float64
+	format     string
+}
+
+func runMetricsDiff(args []string) int {
+	opts, refs, err := parseMetricsDiffOptions(args)
+	if err != nil {
+		return reportFlagParseErr(err, os.Stderr, "mdsmith: metrics diff")
+	}
+	return executeMetricsDiff(os.Stdout, opts, refs[0], refs[1])
+}
+
+func parseMetricsDiffOptions(args []string) (metricsDiffOptions, []string, error) {
+	fs := flag.NewFlagSet("metrics diff", flag.ContinueOnError)
+	var opts metricsDiffOptions
+
+	fs.StringVar(&opts.metricsRaw, "metrics", "", "Comma-separated metrics (defaults to every metric in both snapshots)")
+	fs.StringVar(&opts.scopeRaw, "scope", "file", "Compare per file, per dir, or for the workspace")
+	fs.StringVar(&opts.statRaw, "stat", "sum", "Statistic compared for dir/workspace: sum, mean, p50, p90, max")
+	fs.Float64Var(&opts.threshold, "threshold", 5,
+		"Percent a metric may worsen before it counts as a regression")
+	fs.StringVarP(&opts.format, "format", "f", "text", "Output format: text, json, yaml")
+
+	fs.Usage = func() {
+		fmt.Fprintf(
+			os.Stderr,
+			"Usage: mdsmith metrics diff [flags] <old> <new>\n\n"+
+				"Compare two metrics snapshots. Each argument is a snapshot file or a\n"+
+				"git object <rev>:<path> read with `git show`. Exits 1 when any\n"+
+				"metric worsens by more than --threshold percent.\n\n"+
+				"Flags:\n",
+		)
+		fs.PrintDefaults()
+	}
+
+	if err := fs.Parse(args); err != nil {
+		return metricsDiffOptions{}, nil, err
+	}
+	if fs.NArg() != 2 {
+		return metricsDiffOptions{}, nil, errors.New("requires exactly two snapshot arguments")
+	}
+	if opts.threshold < 0 {
+		return metricsDiffOptions{}, nil, errors.New("--threshold must be >= 0")
+	}
+	return opts, fs.Args(), nil
+}
+
+func executeMetricsDiff(w io.Writer, opts metricsDiffOptions, oldRef, newRef string) int {
+	if err := validateOutputFormat(opts.format); err != nil {
+		fmt.Fprintf(os.Stderr, "mdsmith: %v\n", err)
+		return 2
+	}
+	scope, err := metricspkg.ParseAggregateScope(opts.scopeRaw)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "mdsmith: %v\n", err)
+		return 2
+	}
+	stat, err := metricspkg.ParseStat(opts.statRaw)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "mdsmith: %v\n", err)
+		return 2
+	}
+
+	oldSnap, err := readSnapshotRef(oldRef)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "mdsmith: %v\n", err)
+		return 2
+	}
+	newSnap, err := readSnapshotRef(newRef)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "mdsmith: %v\n", err)
+		return 2
+	}
+
+	defs, err := resolveDiffMetrics(opts.metricsRaw, oldSnap, newSnap)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "mdsmith: %v\n", err)
+		return 2
+	}
+
+	oldRows, newRows := oldSnap.Rows(), newSnap.Rows()
+	if scope != metricspkg.ScopeFile {
+		oldRows = metricspkg.GroupRows(metricspkg.Aggregate(oldRows, defs, scope), stat)
+		newRows = metricspkg.GroupRows(metricspkg.Aggregate(newRows, defs, scope), stat)
+		for i := range defs {
+			defs[i] = metricspkg.StatDefinition(defs[i], stat)
+		}
+	}
+	changes := metricspkg.Compare(oldRows, newRows, defs, opts.threshold)
+
+	if err := writeDiffOutput(w, opts.format, changes); err != nil {
+		fmt.Fprintf(os.Stderr, "mdsmith: writing output: %v\n", err)
+		return 2
+	}
+	for _, c := range changes {
+		if c.Regression {
+			return 1
+		}
+	}
+	return 0
+}
+
+// readSnapshotRef loads a snapshot from a file path or, when no
+// such file exists and ref has the git object form <rev>:<path>,
+// from `git show <rev>:<path>`.
+func readSnapshotRef(ref string) (metricspkg.Snapshot, error) {
+	data, err := os.ReadFile(ref)
+	switch {
+	case err == nil:
+	case errors.Is(err, os.ErrNotExist) && strings.Contains(ref, ":"):
+		data, err = gitShow(ref)
+		if err != nil {
+			return metricspkg.Snapshot{}, err
+		}
+	default:
+		return metricspkg.Snapshot{}, fmt.Errorf("reading snapshot %q: %w", ref, err)
+	}
+	snap, err := metricspkg.ParseSnapshot(data)
+	if err != nil {
+		return metricspkg.Snapshot{}, fmt.Errorf("%s: %w", ref, err)
+	}
+	return snap, nil
+}
+
+func gitShow(object string) ([]byte, error) {
+	var stderr bytes.Buffer
+	cmd := exec.Command("git", "show", "--end-of-options", object)
+	cmd.Stderr = &stderr
+	out, err := cmd.Output()
+	if err != nil {
+		return nil, fmt.Errorf("git show %s: %v: %s", object, err, strings.TrimSpace(stderr.String()))
+	}
+	return out, nil
+}
+
+// resolveDiffMetrics selects the metrics both snapshots recorded,
+// narrowed by --metrics when given.
+func resolveDiffMetrics(raw string, a, b metricspkg.Snapshot) ([]metricspkg.Definition, error) {
+	inB := make(map[string]bool, len(b.Metrics))
+	for _, name := range b.Metrics {
+		inB[name] = true
+	}
+	var common []metricspkg.Definition
+	for _, def := range a.Definitions() {
+		if inB[def.Name] {
+			common = append(common, def)
+		}
+	}
+
+	names := metricspkg.SplitList(raw)
+	if len(names) == 0 {
+		if len(common) == 0 {
+			return nil, errors.New("snapshots share no metrics")
+		}
+		return common, nil
+	}
+	selected, err := metricspkg.Resolve(metricspkg.ScopeFile, names)
+	if err != nil {
+		return nil, err
+	}
+	for _, def := range selected {
+		if !containsMetric(common, def.ID) {
+			return nil, fmt.Errorf("metric %q is not recorded in both snapshots", def.Name)
+		}
+	}
+	return selected, nil
+}
+
+func writeDiffOutput(w io.Writer, format string, changes []metricspkg.Change) error {
+	switch format {
+	case "text":
+		return writeDiffText(w, changes)
+	case "json":
+		enc := json.NewEncoder(w)
+		enc.SetIndent("", "  ")
+		enc.SetEscapeHTML(false)
+		return enc.Encode(diffItems(changes))
+	case "yaml":
+		enc := yaml.NewEncoder(w)
+		enc.SetIndent(2)
+		if err := enc.Encode(diffItems(changes)); err != nil {
+			return err
+		}
+		return enc.Close()
+	default:
+		return fmt.Errorf("unknown format %q (supported: text, json, yaml)", format)
+	}
+}
+
+func writeDiffText(w io.Writer, changes []metricspkg.Change) error {
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	// tabwriter buffers all writes internally; only Flush reaches the underlying writer.
+	_, _ = fmt.Fprintln(tw, "PATH\tMETRIC\tOLD\tNEW\tDELTA\t")
+	for _, c := range changes {
+		delta := metricspkg.FormatValue(c.Metric, c.Delta())
+		if d := c.Delta(); d.Available && d.Number > 0 {
+			delta = "+" + delta
+		}
+		mark := ""
+		if c.Regression {
+			mark = "REGRESSION"
+		}
+		_, _ = fmt.Fprintf(tw, "%s\t%s\t%s\t%s\t%s\t%s\n",
+			c.Path,
+			c.Metric.Name,
+			metricspkg.FormatValue(c.Metric, c.Old),
+			metricspkg.FormatValue(c.Metric, c.New),
+			delta,
+			mark,
+		)
+	}
+	return tw.Flush()
+}
+
+func diffItems(changes []metricspkg.Change) []map[string]any {
+	items := make([]map[string]any, 0, len(changes))
+	for _, c := range changes {
+		items = append(items, map[string]any{
+			"path":       c.Path,
+			"metric":     c.Metric.Name,
+			"old":        metricspkg.JSONValue(c.Metric, c.Old),
+			"new":        metricspkg.JSONValue(c.Metric, c.New),
+			"delta":      metricspkg.JSONValue(c.Metric, c.Delta()),
+			"regression": c.Regression,
+		})
+	}
+	return items
+}
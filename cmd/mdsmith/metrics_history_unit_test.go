@@ -0,0 +1,224 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	metricspkg "github.com/jeduden/mdsmith/internal/metrics"
+)
+
+func pinSnapshotNow(t *testing.T, at time.Time) {
+	t.Helper()
+	orig := snapshotNow
+	snapshotNow = func() time.Time { return at }
+	t.Cleanup(func() { snapshotNow = orig })
+}
+
+func writeSnapshotFixture(t *testing.T, path string, bytesByFile map[string]float64) {
+	t.Helper()
+	def, ok := metricspkg.LookupScope(metricspkg.ScopeFile, "bytes")
+	require.True(t, ok)
+	rows := make([]metricspkg.Row, 0, len(bytesByFile))
+	for p, v := range bytesByFile {
+		rows = append(rows, metricspkg.Row{Path: p, Metrics: map[string]metricspkg.Value{
+			"bytes": metricspkg.AvailableValue(v),
+		}})
+	}
+	snap := metricspkg.NewSnapshot(rows, []metricspkg.Definition{def}, time.Unix(0, 0))
+	require.NoError(t, writeSnapshotFile(path, snap))
+}
+
+// --- metrics snapshot ---
+
+func TestParseMetricsSnapshotOptions_Defaults(t *testing.T) {
+	opts, files, err := parseMetricsSnapshotOptions(nil)
+	require.NoError(t, err)
+	assert.Empty(t, opts.output)
+	assert.Equal(t, []string{"."}, files)
+}
+
+func TestExecuteMetricsSnapshot_DefaultPathUnderConfigRoot(t *testing.T) {
+	dir := t.TempDir()
+	t.Chdir(dir)
+	require.NoError(t, os.WriteFile(".mdsmith.yml", []byte("rules: {}\n"), 0o644))
+	require.NoError(t, os.WriteFile("a.md", []byte("# A\n\nSome words here.\n"), 0o644))
+	pinSnapshotNow(t, time.Date(2026, 10, 18, 9, 30, 0, 0, time.UTC))
+
+	var out bytes.Buffer
+	code := executeMetricsSnapshot(&out, metricsSnapshotOptions{}, []string{"."})
+	require.Equal(t, 0, code)
+
+	want := filepath.Join(".mdsmith", "metrics", "20261018T093000Z.json")
+	assert.True(t, strings.HasSuffix(out.String(), want+"\n"), out.String())
+	data, err := os.ReadFile(want)
+	require.NoError(t, err)
+	snap, err := metricspkg.ParseSnapshot(data)
+	require.NoError(t, err)
+	require.Len(t, snap.Files, 1)
+	assert.Equal(t, "a.md", snap.Files[0].Path)
+	assert.Len(t, snap.Metrics, len(metricspkg.ForScope(metricspkg.ScopeFile)),
+		"a snapshot records every file metric by default")
+}
+
+func TestExecuteMetricsSnapshot_UnknownMetric_ExitsTwo(t *testing.T) {
+	captureStderr(func() {
+		code := executeMetricsSnapshot(&bytes.Buffer{}, metricsSnapshotOptions{metricsRaw: "nope"}, nil)
+		assert.Equal(t, 2, code)
+	})
+}
+
+func TestConfigRoot(t *testing.T) {
+	assert.Equal(t, ".", configRoot(""))
+	assert.Equal(t, filepath.Join("a", "b"), configRoot(filepath.Join("a", "b", ".mdsmith.yml")))
+}
+
+// --- metrics diff ---
+
+func TestParseMetricsDiffOptions_RequiresTwoArgs(t *testing.T) {
+	_, _, err := parseMetricsDiffOptions([]string{"a.json"})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "exactly two")
+}
+
+func TestParseMetricsDiffOptions_NegativeThreshold(t *testing.T) {
+	_, _, err := parseMetricsDiffOptions([]string{"--threshold", "-1", "a", "b"})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "--threshold")
+}
+
+func TestExecuteMetricsDiff_RegressionExitsOne(t *testing.T) {
+	dir := t.TempDir()
+	oldPath := filepath.Join(dir, "old.json")
+	newPath := filepath.Join(dir, "new.json")
+	writeSnapshotFixture(t, oldPath, map[string]float64{"a.md": 100, "b.md": 10})
+	writeSnapshotFixture(t, newPath, map[string]float64{"a.md": 150, "b.md": 10})
+
+	var out bytes.Buffer
+	code := executeMetricsDiff(&out, metricsDiffOptions{format: "text", threshold: 5}, oldPath, newPath)
+
+	assert.Equal(t, 1, code)
+	assert.Contains(t, out.String(), "a.md")
+	assert.Contains(t, out.String(), "+50")
+	assert.Contains(t, out.String(), "REGRESSION")
+	assert.NotContains(t, out.String(), "b.md", "unchanged values are not listed")
+}
+
+func TestExecuteMetricsDiff_WithinThresholdExitsZero(t *testing.T) {
+	dir := t.TempDir()
+	oldPath := filepath.Join(dir, "old.json")
+	newPath := filepath.Join(dir, "new.json")
+	writeSnapshotFixture(t, oldPath, map[string]float64{"a.md": 100})
+	writeSnapshotFixture(t, newPath, map[string]float64{"a.md": 104})
+
+	var out bytes.Buffer
+	code := executeMetricsDiff(&out, metricsDiffOptions{format: "json", threshold: 5}, oldPath, newPath)
+
+	assert.Equal(t, 0, code)
+	var items []map[string]any
+	require.NoError(t, json.Unmarshal(out.Bytes(), &items))
+	require.Len(t, items, 1)
+	assert.Equal(t, false, items[0]["regression"])
+	assert.InDelta(t, 4, items[0]["delta"], 1e-9)
+}
+
+func TestExecuteMetricsDiff_WorkspaceScopeComparesStat(t *testing.T) {
+	dir := t.TempDir()
+	oldPath := filepath.Join(dir, "old.json")
+	newPath := filepath.Join(dir, "new.json")
+	writeSnapshotFixture(t, oldPath, map[string]float64{"a.md": 10, "b.md": 30})
+	writeSnapshotFixture(t, newPath, map[string]float64{"a.md": 20, "b.md": 40})
+
+	var out bytes.Buffer
+	code := executeMetricsDiff(&out, metricsDiffOptions{
+		format: "text", scopeRaw: "workspace", statRaw: "mean", threshold: 50,
+	}, oldPath, newPath)
+
+	assert.Equal(t, 0, code, "mean 20 -> 30 is a 50%% change, not above the threshold")
+	assert.Contains(t, out.String(), "20.0")
+	assert.Contains(t, out.String(), "30.0")
+}
+
+func TestExecuteMetricsDiff_MissingSnapshot_ExitsTwo(t *testing.T) {
+	captureStderr(func() {
+		code := executeMetricsDiff(&bytes.Buffer{}, metricsDiffOptions{format: "text"},
+			filepath.Join(t.TempDir(), "missing.json"), "also-missing.json")
+		assert.Equal(t, 2, code)
+	})
+}
+
+func TestExecuteMetricsDiff_BadScope_ExitsTwo(t *testing.T) {
+	captureStderr(func() {
+		code := executeMetricsDiff(&bytes.Buffer{}, metricsDiffOptions{format: "text", scopeRaw: "repo"}, "a", "b")
+		assert.Equal(t, 2, code)
+	})
+}
+
+func TestReadSnapshotRef_GitObjectMissingRevision(t *testing.T) {
+	t.Chdir(t.TempDir())
+	_, err := readSnapshotRef("no-such-rev:.mdsmith/metrics/x.json")
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "git show")
+}
+
+func TestResolveDiffMetrics(t *testing.T) {
+	a := metricspkg.Snapshot{Metrics: []string{"bytes", "lines"}}
+	b := metricspkg.Snapshot{Metrics: []string{"lines", "words"}}
+
+	defs, err := resolveDiffMetrics("", a, b)
+	require.NoError(t, err)
+	require.Len(t, defs, 1)
+	assert.Equal(t, "lines", defs[0].Name)
+
+	_, err = resolveDiffMetrics("bytes", a, b)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "not recorded in both")
+
+	_, err = resolveDiffMetrics("", metricspkg.Snapshot{}, b)
+	require.Error(t, err)
+}
+
+// --- metrics rank --scope ---
+
+func TestResolveRankAggregation(t *testing.T) {
+	scope, stat, err := resolveRankAggregation(metricsRankOptions{scopeRaw: "dir", statRaw: "p90"})
+	require.NoError(t, err)
+	assert.Equal(t, metricspkg.ScopeDir, scope)
+	assert.Equal(t, metricspkg.StatP90, stat)
+
+	_, _, err = resolveRankAggregation(metricsRankOptions{statRaw: "median"})
+	require.Error(t, err)
+}
+
+func TestWriteAggregateOutput_TextAndJSON(t *testing.T) {
+	def, ok := metricspkg.LookupScope(metricspkg.ScopeFile, "bytes")
+	require.True(t, ok)
+	defs := []metricspkg.Definition{def}
+	groups := metricspkg.Aggregate([]metricspkg.Row{
+		{Path: "docs/a.md", Metrics: map[string]metricspkg.Value{"bytes": metricspkg.AvailableValue(3)}},
+		{Path: "docs/b.md", Metrics: map[string]metricspkg.Value{"bytes": metricspkg.AvailableValue(4)}},
+	}, defs, metricspkg.ScopeDir)
+
+	var text bytes.Buffer
+	require.NoError(t, writeAggregateOutput(&text, "text", groups, defs))
+	assert.Contains(t, text.String(), "P90")
+	assert.Contains(t, text.String(), "3.5")
+
+	var js bytes.Buffer
+	require.NoError(t, writeAggregateOutput(&js, "json", groups, defs))
+	var items []map[string]any
+	require.NoError(t, json.Unmarshal(js.Bytes(), &items))
+	require.Len(t, items, 1)
+	assert.Equal(t, "docs", items[0]["path"])
+	assert.InDelta(t, 7, items[0]["bytes"].(map[string]any)["sum"], 1e-9)
+
+	require.NoError(t, writeAggregateOutput(&bytes.Buffer{}, "yaml", groups, defs))
+	assert.Error(t, writeAggregateOutput(&bytes.Buffer{}, "xml", groups, defs))
+}
@@ -793,3 +793,23 @@ func TestWriteGetOutput_UnknownFormat_ReturnsError(t *testing.T) {
 	require.Error(t, err)
 	assert.Contains(t, err.Error(), "unknown format")
 }
+
+func TestWriteGetOutput_ReportsFormula(t *testing.T) {
+	defs := []metricspkg.Definition{{Name: "readability", Kind: metricspkg.KindFloat, Precision: 1}}
+	row := metricspkg.Row{
+		Path:    "de.md",
+		Metrics: map[string]metricspkg.Value{"readability": metricspkg.FormulaValue(8.25, "wiener-sachtextformel")},
+	}
+
+	var text bytes.Buffer
+	require.NoError(t, writeMetricsGetText(&text, row, defs))
+	assert.Contains(t, text.String(), "8.3 (wiener-sachtextformel)")
+
+	var js bytes.Buffer
+	require.NoError(t, writeMetricsGetJSON(&js, row, defs))
+	assert.Contains(t, js.String(), `"readability-formula": "wiener-sachtextformel"`)
+
+	var yml bytes.Buffer
+	require.NoError(t, writeMetricsGetYAML(&yml, row, defs))
+	assert.Contains(t, yml.String(), "readability-formula: wiener-sachtextformel")
+}
@@ -0,0 +1,356 @@
+package main
+
+import (
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"slices"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+
+	"github.com/jeduden/mdsmith/internal/query"
+)
+
+// queryFormats are the values --format accepts for `list query`.
+var queryFormats = []string{"text", "json", "yaml", "csv", "tsv"}
+
+// queryReport is the parsed projection a `list query` run prints:
+// which fields, in what order, grouped or counted by which field.
+type queryReport struct {
+	format  string
+	fields  []query.Field
+	sort    []query.SortKey
+	groupBy *query.Field
+	countBy *query.Field
+}
+
+// projects reports whether the run prints more than the matched
+// paths, so rows must be collected before anything is written.
+func (q queryReport) projects() bool {
+	return q.format != "text" || len(q.fields) > 0 || len(q.sort) > 0 ||
+		q.groupBy != nil || q.countBy != nil
+}
+
+// usesDoc reports whether a projected, sorted, grouped, or counted
+// field reads the document facts.
+func (q queryReport) usesDoc() bool {
+	fields := slices.Clone(q.fields)
+	for _, k := range q.sort {
+		fields = append(fields, k.Field)
+	}
+	for _, f := range []*query.Field{q.groupBy, q.countBy} {
+		if f != nil {
+			fields = append(fields, *f)
+		}
+	}
+	return slices.ContainsFunc(fields, query.Field.UsesDoc)
+}
+
+// newQueryReport validates the projection flags.
+func newQueryReport(opts queryOptions) (queryReport, error) {
+	q := queryReport{format: opts.format}
+	if !slices.Contains(queryFormats, q.format) {
+		return q, fmt.Errorf("unknown --format %q (want text, json, yaml, csv, or tsv)", q.format)
+	}
+	for _, name := range splitFieldList(opts.fields) {
+		f, err := query.ParseField(name)
+		if err != nil {
+			return q, fmt.Errorf("--fields: %w", err)
+		}
+		q.fields = append(q.fields, f)
+	}
+	for _, name := range splitFieldList(opts.sort) {
+		k, err := query.ParseSortKey(name)
+		if err != nil {
+			return q, fmt.Errorf("--sort: %w", err)
+		}
+		q.sort = append(q.sort, k)
+	}
+	for _, flagField := range []struct {
+		name, expr string
+		dst        **query.Field
+	}{{"--group-by", opts.groupBy, &q.groupBy}, {"--count-by", opts.countBy, &q.countBy}} {
+		if flagField.expr == "" {
+			continue
+		}
+		f, err := query.ParseField(flagField.expr)
+		if err != nil {
+			return q, fmt.Errorf("%s: %w", flagField.name, err)
+		}
+		*flagField.dst = &f
+	}
+	if q.countBy != nil && (len(q.fields) > 0 || len(q.sort) > 0 || q.groupBy != nil) {
+		return q, fmt.Errorf("--count-by cannot be combined with --fields, --sort, or --group-by")
+	}
+	if opts.nul && q.projects() {
+		return q, fmt.Errorf("--null only applies to the plain path list")
+	}
+	return q, nil
+}
+
+// splitFieldList splits each flag value on commas outside double
+// quotes, so a quoted key such as "a,b" stays one field. pflag's
+// string slices would split it and strip the quotes CUE paths need.
+func splitFieldList(values []string) []string {
+	var out []string
+	for _, v := range values {
+		start, quoted := 0, false
+		for i := 0; i < len(v); i++ {
+			switch v[i] {
+			case '\\':
+				i++
+			case '"':
+				quoted = !quoted
+			case ',':
+				if !quoted {
+					out = append(out, strings.TrimSpace(v[start:i]))
+					start = i + 1
+				}
+			}
+		}
+		out = append(out, strings.TrimSpace(v[start:]))
+	}
+	return out
+}
+
+// write prints rows in the report's shape.
+func (q queryReport) write(w io.Writer, rows []query.Row) error {
+	if q.countBy != nil {
+		return q.writeCounts(w, query.CountBy(rows, *q.countBy))
+	}
+	keys := q.sort
+	if q.groupBy != nil {
+		keys = append([]query.SortKey{{Field: *q.groupBy}}, keys...)
+	}
+	query.SortRows(rows, keys)
+	switch q.format {
+	case "json", "yaml":
+		var v any = q.records(rows)
+		if q.groupBy != nil {
+			v = q.groups(rows)
+		}
+		return writeStructured(w, q.format, v)
+	case "csv", "tsv":
+		return q.writeTable(w, rows)
+	}
+	return q.writeText(w, rows)
+}
+
+// header returns the column names: the group field, the path, then
+// the projected fields.
+func (q queryReport) header() []string {
+	var out []string
+	if q.groupBy != nil {
+		out = append(out, q.groupBy.Name)
+	}
+	out = append(out, "path")
+	for _, f := range q.fields {
+		out = append(out, f.Name)
+	}
+	return out
+}
+
+// cells returns one row's column values, as header orders them.
+func (q queryReport) cells(r query.Row) []string {
+	var out []string
+	if q.groupBy != nil {
+		out = append(out, query.Text(q.groupBy.Value(r.FrontMatter)))
+	}
+	out = append(out, r.Path)
+	for _, f := range q.fields {
+		out = append(out, query.Text(f.Value(r.FrontMatter)))
+	}
+	return out
+}
+
+func (q queryReport) writeTable(w io.Writer, rows []query.Row) error {
+	cw := csv.NewWriter(w)
+	if q.format == "tsv" {
+		cw.Comma = '\t'
+	}
+	_ = cw.Write(q.header()) //nolint:errcheck // surfaced by cw.Error below
+	for _, r := range rows {
+		_ = cw.Write(q.cells(r)) //nolint:errcheck // surfaced by cw.Error below
+	}
+	cw.Flush()
+	return cw.Error()
+}
+
+// writeText prints one line per file: the path, then the projected
+// values, tab-separated. With --group-by, each group opens with a
+// "<field>: <value>" line and its files follow, indented.
+func (q queryReport) writeText(w io.Writer, rows []query.Row) error {
+	var buf bytes.Buffer
+	group, started := "", false
+	for _, r := range rows {
+		cells := q.cells(r)
+		indent := ""
+		if q.groupBy != nil {
+			if !started || cells[0] != group {
+				if started {
+					buf.WriteByte('\n')
+				}
+				group, started = cells[0], true
+				fmt.Fprintf(&buf, "%s: %s\n", q.groupBy.Name, group)
+			}
+			cells, indent = cells[1:], "  "
+		}
+		buf.WriteString(indent)
+		for i, c := range cells {
+			if i > 0 {
+				buf.WriteByte('\t')
+			}
+			buf.WriteString(c)
+		}
+		buf.WriteByte('\n')
+	}
+	_, err := w.Write(buf.Bytes())
+	return err
+}
+
+func (q queryReport) writeCounts(w io.Writer, counts []query.Count) error {
+	switch q.format {
+	case "json", "yaml":
+		return writeStructured(w, q.format, counts)
+	case "csv", "tsv":
+		cw := csv.NewWriter(w)
+		if q.format == "tsv" {
+			cw.Comma = '\t'
+		}
+		_ = cw.Write([]string{q.countBy.Name, "count"}) //nolint:errcheck // surfaced by cw.Error below
+		for _, c := range counts {
+			_ = cw.Write([]string{c.Value, fmt.Sprint(c.Count)}) //nolint:errcheck // surfaced by cw.Error below
+		}
+		cw.Flush()
+		return cw.Error()
+	}
+	var buf bytes.Buffer
+	for _, c := range counts {
+		fmt.Fprintf(&buf, "%d\t%s\n", c.Count, c.Value)
+	}
+	_, err := w.Write(buf.Bytes())
+	return err
+}
+
+// queryRecord is one file's projected front matter. Keys keep the
+// order the user asked for, path first, which a Go map would lose.
+type queryRecord struct {
+	keys []string
+	vals []any
+}
+
+func (q queryReport) records(rows []query.Row) []queryRecord {
+	out := make([]queryRecord, 0, len(rows))
+	for _, r := range rows {
+		rec := queryRecord{keys: []string{"path"}, vals: []any{r.Path}}
+		for _, f := range q.fields {
+			rec.keys = append(rec.keys, f.Name)
+			rec.vals = append(rec.vals, f.Value(r.FrontMatter))
+		}
+		out = append(out, rec)
+	}
+	return out
+}
+
+// queryGroup is the files sharing one value of the --group-by field.
+type queryGroup struct {
+	Group any           `json:"group" yaml:"group"`
+	Files []queryRecord `json:"files" yaml:"files"`
+}
+
+// groups splits rows, already sorted by the group field, into runs
+// of equal value.
+func (q queryReport) groups(rows []query.Row) []queryGroup {
+	out := []queryGroup{}
+	recs := q.records(rows)
+	for i, r := range rows {
+		v := q.groupBy.Value(r.FrontMatter)
+		if n := len(out); n == 0 || query.Text(out[n-1].Group) != query.Text(v) {
+			out = append(out, queryGroup{Group: v})
+		}
+		out[len(out)-1].Files = append(out[len(out)-1].Files, recs[i])
+	}
+	return out
+}
+
+// MarshalJSON writes the record as an object in key order.
+func (r queryRecord) MarshalJSON() ([]byte, error) {
+	var buf bytes.Buffer
+	buf.WriteByte('{')
+	for i, k := range r.keys {
+		if i > 0 {
+			buf.WriteByte(',')
+		}
+		key, err := json.Marshal(k)
+		if err != nil {
+			return nil, err
+		}
+		val, err := json.Marshal(r.vals[i])
+		if err != nil {
+			return nil, err
+		}
+		buf.Write(key)
+		buf.WriteByte(':')
+		buf.Write(val)
+	}
+	buf.WriteByte('}')
+	return buf.Bytes(), nil
+}
+
+// MarshalYAML writes the record as a mapping in key order.
+func (r queryRecord) MarshalYAML() (any, error) {
+	m := &yaml.Node{Kind: yaml.MappingNode}
+	for i, k := range r.keys {
+		var val yaml.Node
+		if err := val.Encode(r.vals[i]); err != nil {
+			return nil, err
+		}
+		m.Content = append(m.Content, &yaml.Node{Kind: yaml.ScalarNode, Value: k}, &val)
+	}
+	return m, nil
+}
+
+func writeStructured(w io.Writer, format string, v any) error {
+	if format == "yaml" {
+		enc := yaml.NewEncoder(w)
+		enc.SetIndent(2)
+		if err := enc.Encode(v); err != nil {
+			return err
+		}
+		return enc.Close()
+	}
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(v)
+}
+
+// collectQueryRows returns the files whose front matter satisfies
+// matcher, with that front matter, in the order given. withDoc adds
+// the document facts for the matcher and the projection to read.
+func collectQueryRows(
+	matcher *query.Matcher, files []string, verbose bool, maxBytes int64, withDoc bool,
+) []query.Row {
+	var rows []query.Row
+	for _, f := range files {
+		fm, err := readQueryInput(f, maxBytes, withDoc)
+		switch {
+		case err != nil:
+			if verbose {
+				fmt.Fprintf(os.Stderr, "skip %s: %v\n", f, err)
+			}
+		case fm == nil:
+			if verbose {
+				fmt.Fprintf(os.Stderr, "skip %s: no front matter\n", f)
+			}
+		case matcher.Match(fm):
+			rows = append(rows, query.Row{Path: f, FrontMatter: fm})
+		case verbose:
+			fmt.Fprintf(os.Stderr, "skip %s: expression not satisfied\n", f)
+		}
+	}
+	return rows
+}
@@ -2,7 +2,6 @@ package main
 
 import (
 	"encoding/json"
-	"errors"
 	"fmt"
 	"io"
 	"os"
@@ -13,10 +12,14 @@ import (
 	flag "github.com/spf13/pflag"
 
 	"github.com/jeduden/mdsmith/internal/bytelimit"
+	"github.com/jeduden/mdsmith/internal/checker"
+	"github.com/jeduden/mdsmith/internal/config"
 	"github.com/jeduden/mdsmith/internal/index"
-	"github.com/jeduden/mdsmith/internal/mdtext"
+	"github.com/jeduden/mdsmith/internal/lint"
 	"github.com/jeduden/mdsmith/internal/oscompat"
 	"github.com/jeduden/mdsmith/internal/rename"
+	"github.com/jeduden/mdsmith/internal/rule"
+	"github.com/jeduden/mdsmith/internal/rules/headingnumbering"
 )
 
 // writeFileTempFn creates a named temp file; exposed as a variable so tests
@@ -62,6 +65,7 @@ type renameOptions struct {
 	maxInputSize string
 	heading      bool
 	linkRef      bool
+	numbering    bool
 	walk         walkCLI
 }
 
@@ -77,6 +81,7 @@ type renameSummary struct {
 // under is its workspace-relative path — the same string the CLI
 // writes back to disk.
 type cliRenameWorkspace struct {
+	cfg      *config.Config
 	idx      *index.Index
 	relToAbs map[string]string
 	rootDir  string
@@ -117,6 +122,8 @@ func parseRenameFlags(args []string) (renameOptions, []string, error) {
 	fs.StringVarP(&opts.format, "format", "f", "text", "Output format: text, json")
 	fs.BoolVar(&opts.heading, "heading", false, "Rename a heading and every workspace anchor that targets it")
 	fs.BoolVar(&opts.linkRef, "link-ref", false, "Rename a link-reference label: the def and every use in the file")
+	fs.BoolVar(&opts.numbering, "numbering", false,
+		"Renumber headings per heading-numbering and every workspace anchor that targets them")
 	fs.BoolVar(&noGitignore, "no-gitignore", false, "Disable .gitignore filtering when walking directories")
 	fs.BoolVar(&followSymlinks, "follow-symlinks", false,
 		"Follow symlinks; omitted defers to follow-symlinks config (default skip); "+
@@ -125,12 +132,14 @@ func parseRenameFlags(args []string) (renameOptions, []string, error) {
 		"Maximum file size to process (e.g. 2MB, 500KB, 0=unlimited)")
 
 	fs.Usage = func() {
-		fmt.Fprint(os.Stderr, "Usage: mdsmith rename [flags] <file> <old> <new>\n\n"+
-			"Rename a heading or a link-reference label, rewriting every\n"+
-			"dependent edit across the workspace in place. Exactly one of\n"+
-			"--heading or --link-ref is required.\n\n"+
+		fmt.Fprint(os.Stderr, "Usage: mdsmith rename [flags] <file> <old> <new>\n"+
+			"       mdsmith rename --numbering [flags] <file>\n\n"+
+			"Rename a heading or a link-reference label, or renumber headings,\n"+
+			"rewriting every dependent edit across the workspace in place.\n"+
+			"Exactly one of --heading, --link-ref, or --numbering is required.\n\n"+
 			"  mdsmith rename docs/a.md --heading \"Old Title\" \"New Title\"\n"+
-			"  mdsmith rename docs/a.md --link-ref oldlabel newlabel\n\n"+
+			"  mdsmith rename docs/a.md --link-ref oldlabel newlabel\n"+
+			"  mdsmith rename docs/spec.md --numbering\n\n"+
 			"Exit codes: 0 rewritten, 1 no match, 2 error or conflict\n\nFlags:\n")
 		fs.PrintDefaults()
 	}
@@ -154,11 +163,15 @@ func runRename(args []string) int {
 			return code
 		}
 	}
-	if opts.heading == opts.linkRef {
-		fmt.Fprint(os.Stderr, "mdsmith: rename requires exactly one of --heading or --link-ref\n")
+	if countTrue(opts.heading, opts.linkRef, opts.numbering) != 1 {
+		fmt.Fprint(os.Stderr, "mdsmith: rename requires exactly one of --heading, --link-ref, or --numbering\n")
 		return 2
 	}
-	if len(posArgs) != 3 {
+	switch {
+	case opts.numbering && len(posArgs) != 1:
+		fmt.Fprint(os.Stderr, "mdsmith: rename --numbering requires <file>\n")
+		return 2
+	case !opts.numbering && len(posArgs) != 3:
 		fmt.Fprint(os.Stderr, "mdsmith: rename requires <file> <old> <new>\n")
 		return 2
 	}
@@ -167,14 +180,18 @@ func runRename(args []string) int {
 		fmt.Fprintf(os.Stderr, "mdsmith: target %q must be workspace-relative\n", target)
 		return 2
 	}
-	oldName, newName := posArgs[1], posArgs[2]
 
 	ws, src, code := buildRenameWorkspace(opts, target)
 	if code >= 0 {
 		return code
 	}
 
-	changes, code := computeRenameChanges(ws, target, src, oldName, newName, opts.heading)
+	var changes map[string][]rename.Edit
+	if opts.numbering {
+		changes, code = computeNumberingChanges(ws, target, src)
+	} else {
+		changes, code = computeRenameChanges(ws, target, src, posArgs[1], posArgs[2], opts.heading)
+	}
 	if code >= 0 {
 		return code
 	}
@@ -211,7 +228,7 @@ func buildRenameWorkspace(opts renameOptions, target string) (cliRenameWorkspace
 	idx.BuildSerial(rels, func(rel string) ([]byte, error) {
 		return bytelimit.ReadFileLimited(relToAbs[rel], maxBytes)
 	})
-	ws := cliRenameWorkspace{idx: idx, relToAbs: relToAbs, rootDir: rootDir, maxBytes: maxBytes}
+	ws := cliRenameWorkspace{cfg: cfg, idx: idx, relToAbs: relToAbs, rootDir: rootDir, maxBytes: maxBytes}
 	_, src, ok := ws.Resolve(target)
 	if !ok {
 		fmt.Fprintf(os.Stderr, "mdsmith: cannot read %q\n", target)
@@ -256,6 +273,60 @@ func computeRenameChanges(
 	return map[string][]rename.Edit{target: edits}, -1
 }
 
+// computeNumberingChanges renumbers target's headings under its
+// effective heading-numbering settings and retargets every workspace
+// anchor whose slug moves with them. Exit contract as
+// computeRenameChanges: 1 when the numbers are already right, 2 when
+// the rule is off for the file or its config fails.
+func computeNumberingChanges(
+	ws cliRenameWorkspace, target string, src []byte,
+) (map[string][]rename.Edit, int) {
+	f, _ := lint.NewFileFromSource(target, src, frontMatterEnabled(ws.cfg)) // never errors today
+	effective, err := effectiveExportConfig(ws.cfg, target, f.FrontMatter, rule.All())
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "mdsmith: %v\n", err)
+		return nil, 2
+	}
+	rc, ok := effective["heading-numbering"]
+	if !ok || !rc.Enabled {
+		fmt.Fprintf(os.Stderr, "mdsmith: heading-numbering is not enabled for %s\n", target)
+		return nil, 2
+	}
+	configured, err := checker.ConfigureRule(rule.ByName("heading-numbering"), rc)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "mdsmith: %v\n", err)
+		return nil, 2
+	}
+	edits, bodyTexts := configured.(*headingnumbering.Rule).Renumber(f)
+	if len(edits) == 0 {
+		fmt.Fprintf(os.Stderr, "mdsmith: headings in %s are already numbered\n", target)
+		return nil, 1
+	}
+	// Renumber works on the body; shift to source lines.
+	texts := make(map[int]string, len(bodyTexts))
+	for line, text := range bodyTexts {
+		texts[line+f.LineOffset] = text
+	}
+	for i := range edits {
+		edits[i].Range.Start.Line += f.LineOffset
+		edits[i].Range.End.Line += f.LineOffset
+	}
+	changes := rename.RetargetAnchors(ws, target, src, texts)
+	changes[target] = append(edits, changes[target]...)
+	return changes, -1
+}
+
+// countTrue returns how many of flags are set.
+func countTrue(flags ...bool) int {
+	n := 0
+	for _, b := range flags {
+		if b {
+			n++
+		}
+	}
+	return n
+}
+
 // applyAndReport writes every change to disk and prints the per-file
 // summary. Returns 0 on success, 2 on a write or render failure.
 func applyAndReport(
@@ -269,7 +340,7 @@ func applyAndReport(
 			fmt.Fprintf(os.Stderr, "mdsmith: cannot read %q to apply edits\n", rel)
 			return 2
 		}
-		out, err := applyEdits(src, edits)
+		out, err := rename.Apply(src, edits)
 		if err != nil {
 			fmt.Fprintf(os.Stderr, "mdsmith: %s: %v\n", rel, err)
 			return 2
@@ -313,82 +384,6 @@ func emitRenameSummary(w io.Writer, summaries []renameSummary, format string) in
 	return 0
 }
 
-// applyEdits splices every edit into src and returns the rewritten
-// bytes. Each edit is single-line (heading text, label, or fragment).
-// Edits on the same line are applied right-to-left so a left edit's
-// byte offsets — computed against the original row — stay valid while
-// the bytes to its right are rewritten. A trailing `\r` is preserved
-// so CRLF files round-trip.
-func applyEdits(src []byte, edits []rename.Edit) ([]byte, error) {
-	segs := splitKeepCR(src)
-	byLine := map[int][]rename.Edit{}
-	for _, e := range edits {
-		if e.Range.Start.Line != e.Range.End.Line {
-			return nil, errors.New("multi-line edit is not supported")
-		}
-		byLine[e.Range.Start.Line] = append(byLine[e.Range.Start.Line], e)
-	}
-	for line, es := range byLine {
-		if line < 0 || line >= len(segs) {
-			return nil, fmt.Errorf("edit line %d out of range", line+1)
-		}
-		seg := segs[line]
-		cr := len(seg) > 0 && seg[len(seg)-1] == '\r'
-		row := seg
-		if cr {
-			row = seg[:len(seg)-1]
-		}
-		sort.SliceStable(es, func(i, j int) bool {
-			return es[i].Range.Start.Character > es[j].Range.Start.Character
-		})
-		buf := append([]byte(nil), row...)
-		for _, e := range es {
-			s := mdtext.UTF16ToByteOffset(row, e.Range.Start.Character)
-			en := mdtext.UTF16ToByteOffset(row, e.Range.End.Character)
-			if s < 0 || en < 0 || s > len(buf) || en > len(buf) || s > en {
-				return nil, fmt.Errorf("edit offset [%d,%d) out of range on line %d", s, en, line+1)
-			}
-			next := make([]byte, 0, len(buf)-(en-s)+len(e.NewText))
-			next = append(next, buf[:s]...)
-			next = append(next, e.NewText...)
-			next = append(next, buf[en:]...)
-			buf = next
-		}
-		if cr {
-			buf = append(buf, '\r')
-		}
-		segs[line] = buf
-	}
-	return joinLF(segs), nil
-}
-
-// splitKeepCR splits src on `\n`, keeping any trailing `\r` on each
-// segment so CRLF endings survive a round-trip.
-func splitKeepCR(src []byte) [][]byte {
-	var segs [][]byte
-	start := 0
-	for i := 0; i < len(src); i++ {
-		if src[i] == '\n' {
-			segs = append(segs, src[start:i])
-			start = i + 1
-		}
-	}
-	segs = append(segs, src[start:])
-	return segs
-}
-
-// joinLF rejoins segments with `\n`, the inverse of splitKeepCR.
-func joinLF(segs [][]byte) []byte {
-	var out []byte
-	for i, s := range segs {
-		if i > 0 {
-			out = append(out, '\n')
-		}
-		out = append(out, s...)
-	}
-	return out
-}
-
 // resolveWriteMode returns the permission bits to apply when creating a
 // replacement file at path. For a symlink it follows to the live target; for a
 // dangling symlink or any stat error it falls back to 0o644.
@@ -0,0 +1,216 @@
+package main
+
+import (
+	"fmt"
+	"html/template"
+	"os"
+
+	flag "github.com/spf13/pflag"
+
+	"github.com/jeduden/mdsmith/internal/bytelimit"
+	"github.com/jeduden/mdsmith/internal/config"
+	"github.com/jeduden/mdsmith/internal/convention"
+	"github.com/jeduden/mdsmith/internal/export"
+	"github.com/jeduden/mdsmith/internal/htmlrender"
+	"github.com/jeduden/mdsmith/internal/lint"
+	"github.com/jeduden/mdsmith/internal/rule"
+)
+
+type renderFlags struct {
+	exportFlags
+	flavor, template string
+	unsafe           bool
+}
+
+// runRender implements the "render" subcommand: export a file the
+// way `mdsmith export` does, then render the directive-free result
+// to HTML with the file's Markdown flavor. Heading IDs match the
+// anchors link checks resolve against, and relative `.md` links
+// point at `.html`. Output is a fragment unless --template wraps it
+// in a page.
+func runRender(args []string) int {
+	flags, posArgs, code := parseRenderFlags(args)
+	if code >= 0 {
+		return code
+	}
+	if flags.fixStale && flags.noCheck {
+		fmt.Fprintf(os.Stderr,
+			"mdsmith: render: --fix and --no-check are mutually exclusive\n")
+		return 2
+	}
+	switch len(posArgs) {
+	case 0:
+		fmt.Fprintf(os.Stderr, "mdsmith: render requires a file argument\n")
+		return 2
+	case 1:
+		return doRender(posArgs[0], flags)
+	default:
+		fmt.Fprintf(os.Stderr,
+			"mdsmith: render takes a single file argument (got %d)\n", len(posArgs))
+		return 2
+	}
+}
+
+// parseRenderFlags binds the flagset and parses args. Returns
+// (flags, positional, code) — when code is non-negative the caller
+// should return it directly.
+func parseRenderFlags(args []string) (renderFlags, []string, int) {
+	fs := flag.NewFlagSet("render", flag.ContinueOnError)
+	var flags renderFlags
+	fs.StringVarP(&flags.configPath, "config", "c", "", "Override config file path")
+	fs.StringVarP(&flags.output, "output", "o", "",
+		"Write output to <path> instead of stdout")
+	fs.StringVar(&flags.maxInputSize, "max-input-size", "",
+		"Maximum file size to process (e.g. 2MB, 500KB, 0=unlimited)")
+	fs.BoolVar(&flags.fixStale, "fix", false,
+		"Regenerate stale directive bodies in memory before rendering")
+	fs.BoolVar(&flags.noCheck, "no-check", false,
+		"Skip the staleness check; render on-disk bytes as-is")
+	fs.StringVar(&flags.flavor, "flavor", "",
+		"Markdown flavor to render (default: the file's markdown-flavor, else any)")
+	fs.StringVar(&flags.template, "template", "",
+		"Wrap the fragment in a page: \"minimal\" or an html/template file")
+	fs.BoolVar(&flags.unsafe, "unsafe", false,
+		"Pass raw HTML through instead of omitting it")
+
+	fs.Usage = func() {
+		fmt.Fprintf(os.Stderr,
+			"Usage: mdsmith render [flags] <file>\n\n"+
+				"Render a Markdown file to HTML as mdsmith parses it.\n"+
+				"Directives are exported first, as `mdsmith export` does.\n\n"+
+				"The source file is never modified.\n\nFlags:\n")
+		fs.PrintDefaults()
+	}
+
+	if err := fs.Parse(args); err != nil {
+		return flags, nil, reportFlagParseErr(err, os.Stderr, "mdsmith: render")
+	}
+	return flags, fs.Args(), -1
+}
+
+// doRender exports path in memory, renders the result, and writes it.
+func doRender(path string, flags renderFlags) int {
+	cfg, cfgPath, err := loadConfig(flags.configPath)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "mdsmith: %v\n", err)
+		return 2
+	}
+	maxBytes, err := resolveMaxInputBytes(cfg, flags.maxInputSize)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "mdsmith: %v\n", err)
+		return 2
+	}
+	tmpl, err := loadRenderTemplate(flags.template, maxBytes)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "mdsmith: %v\n", err)
+		return 2
+	}
+
+	data, err := bytelimit.ReadFileLimited(path, maxBytes)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "mdsmith: %v\n", err)
+		return 2
+	}
+	f, rules, err := prepareExportFile(path, data, cfg, cfgPath, maxBytes)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "mdsmith: %v\n", err)
+		return 2
+	}
+	fl, err := renderFlavor(flags.flavor, cfg, path, f.FrontMatter)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "mdsmith: %v\n", err)
+		return 2
+	}
+
+	exported, diags := export.Export(f, exportMode(flags.exportFlags), rules)
+	if len(diags) > 0 {
+		if code := formatDiagnostics(diags, "text", false); code != 0 {
+			return code
+		}
+		return 1
+	}
+	out, err := renderExported(path, exported, frontMatterEnabled(cfg), fl, flags.unsafe, tmpl)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "mdsmith: %v\n", err)
+		return 2
+	}
+	if err := writeExportOutput(flags.output, out); err != nil {
+		fmt.Fprintf(os.Stderr, "mdsmith: %v\n", err)
+		return 2
+	}
+	return 0
+}
+
+// loadRenderTemplate returns nil for no template, the built-in page
+// for "minimal", and otherwise parses the named file.
+func loadRenderTemplate(name string, maxBytes int64) (*template.Template, error) {
+	switch name {
+	case "":
+		return nil, nil
+	case "minimal":
+		return htmlrender.MinimalTemplate(), nil
+	}
+	src, err := bytelimit.ReadFileLimited(name, maxBytes)
+	if err != nil {
+		return nil, err
+	}
+	tmpl, err := htmlrender.ParseTemplate(name, src)
+	if err != nil {
+		return nil, fmt.Errorf("parsing template: %w", err)
+	}
+	return tmpl, nil
+}
+
+// renderFlavor resolves the flavor to render with: the --flavor
+// flag, else the markdown-flavor rule's `flavor` setting for the
+// file (kinds and conventions included), else any.
+func renderFlavor(
+	name string, cfg *config.Config, path string, fm []byte,
+) (convention.Flavor, error) {
+	if name == "" {
+		effective, err := effectiveExportConfig(cfg, path, fm, rule.All())
+		if err != nil {
+			return 0, err
+		}
+		name, _ = effective["markdown-flavor"].Settings["flavor"].(string)
+	}
+	if name == "" {
+		return convention.FlavorAny, nil
+	}
+	fl, ok := convention.ParseFlavor(name)
+	if !ok {
+		return 0, fmt.Errorf("unknown flavor %q", name)
+	}
+	return fl, nil
+}
+
+// renderExported re-parses the exported source and renders its
+// body, wrapped in tmpl when one is given.
+func renderExported(
+	path string, exported []byte, stripFM bool,
+	fl convention.Flavor, unsafe bool, tmpl *template.Template,
+) ([]byte, error) {
+	f, _ := lint.NewFileFromSource(path, exported, stripFM) // never errors today
+	body, err := htmlrender.Render(f, htmlrender.Options{Flavor: fl, Unsafe: unsafe})
+	if err != nil {
+		return nil, err
+	}
+	if tmpl == nil {
+		return body, nil
+	}
+	var fm map[string]any
+	if len(f.FrontMatter) > 0 {
+		if fm, err = lint.ParseFrontMatterFields(f.FrontMatter); err != nil {
+			return nil, fmt.Errorf("parsing front matter in %s: %w", path, err)
+		}
+	}
+	out, err := htmlrender.Page(tmpl, htmlrender.PageData{
+		Title:       htmlrender.Title(f, fm),
+		Body:        template.HTML(body), //nolint:gosec // goldmark output
+		FrontMatter: fm,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("executing template: %w", err)
+	}
+	return out, nil
+}
@@ -0,0 +1,327 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+
+	"github.com/jeduden/mdsmith/internal/bytelimit"
+	"github.com/jeduden/mdsmith/internal/config"
+	"github.com/jeduden/mdsmith/internal/engine"
+	"github.com/jeduden/mdsmith/internal/extract"
+	"github.com/jeduden/mdsmith/internal/lint"
+	"github.com/jeduden/mdsmith/internal/rule"
+	"github.com/jeduden/mdsmith/internal/schema"
+	"github.com/jeduden/mdsmith/internal/yamlutil"
+	"github.com/jeduden/mdsmith/pkg/mdsmith"
+	flag "github.com/spf13/pflag"
+)
+
+// scaffoldOptions holds the parsed scaffold command line.
+type scaffoldOptions struct {
+	kind   string
+	path   string
+	from   string
+	update bool
+	dryRun bool
+}
+
+// runScaffold implements the "scaffold" subcommand:
+//
+//	mdsmith scaffold <kind> --from <data> <file>
+//	mdsmith scaffold <kind> --from <data> --update <file>
+//
+// It is the inverse of extract. Without --update it renders a new
+// file from a record; with --update it patches an existing
+// conforming file so only changed values move. Either way the
+// result must pass MDS020 and extract back to the record before it
+// is written: exit 1 when it does not, 2 on a usage, input, or I/O
+// error.
+func runScaffold(args []string) int {
+	opts, stop := parseScaffoldArgs(args)
+	if stop >= 0 {
+		return stop
+	}
+	cfg, cfgPath, code := kindsConfig()
+	if code != 0 {
+		return code
+	}
+	if _, declared := cfg.Kinds[opts.kind]; !declared {
+		return extractErr(2, "unknown kind %q", opts.kind)
+	}
+	maxBytes, err := resolveMaxInputBytes(cfg, "")
+	if err != nil {
+		return extractErr(2, "%v", err)
+	}
+	data, err := readScaffoldData(opts.from, maxBytes)
+	if err != nil {
+		return extractErr(2, "%v", err)
+	}
+	sess := sessionForCLI(cfg, cfgPath)
+	defer sess.Dispose()
+	runner := newExtractRunner(cfg, cfgPath, maxBytes)
+	runner.Rules = structureRules()
+
+	var out []byte
+	if opts.update {
+		out, code = scaffoldUpdate(runner, sess, opts, data)
+	} else {
+		out, code = scaffoldNew(runner, sess, opts, data)
+	}
+	if code != 0 {
+		return code
+	}
+	if code := verifyScaffold(runner, opts, out, data); code != 0 {
+		return code
+	}
+	if opts.dryRun {
+		return emitRaw(out)
+	}
+	if err := os.MkdirAll(filepath.Dir(opts.path), 0o755); err != nil {
+		return extractErr(2, "%v", err)
+	}
+	if err := os.WriteFile(opts.path, out, 0o644); err != nil {
+		return extractErr(2, "writing %s: %v", opts.path, err)
+	}
+	return 0
+}
+
+// parseScaffoldArgs parses flags and the <kind> <file> positionals.
+// The final return is -1 to continue, or an exit code to stop on.
+func parseScaffoldArgs(args []string) (scaffoldOptions, int) {
+	fs := flag.NewFlagSet("scaffold", flag.ContinueOnError)
+	var opts scaffoldOptions
+	fs.StringVar(&opts.from, "from", "",
+		"Record to render: a JSON or YAML file, or - for stdin (required)")
+	fs.BoolVar(&opts.update, "update", false,
+		"Patch an existing file, changing only values that differ")
+	fs.BoolVar(&opts.dryRun, "dry-run", false,
+		"Print the document instead of writing the file")
+	fs.Usage = func() {
+		fmt.Fprintf(os.Stderr,
+			"Usage: mdsmith scaffold <kind> --from <data> [--update] <file>\n\n"+
+				"Render a record shaped like `mdsmith extract` output as a\n"+
+				"kind-conformant Markdown file, or patch an existing one.\n\nFlags:\n")
+		fs.PrintDefaults()
+	}
+	if err := fs.Parse(args); err != nil {
+		if code := reportFlagParseErr(err, os.Stderr, "mdsmith: scaffold"); code >= 0 {
+			return opts, code
+		}
+	}
+	switch {
+	case fs.NArg() != 2:
+		return opts, extractErr(2, "scaffold requires <kind> and <file>")
+	case opts.from == "":
+		return opts, extractErr(2, "scaffold requires --from")
+	}
+	opts.kind, opts.path = fs.Arg(0), fs.Arg(1)
+	return opts, -1
+}
+
+// readScaffoldData reads and decodes the record. YAML decoding
+// accepts JSON too, so one path serves both.
+func readScaffoldData(from string, maxBytes int64) (map[string]any, error) {
+	var raw []byte
+	var err error
+	if from == "-" {
+		raw, err = readStdinLimited(maxBytes)
+	} else {
+		raw, err = bytelimit.ReadFileLimited(from, maxBytes)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("reading %s: %w", from, err)
+	}
+	var v any
+	if err := yamlutil.UnmarshalSafe(raw, &v); err != nil {
+		return nil, fmt.Errorf("parsing %s: %w", from, err)
+	}
+	data, ok := v.(map[string]any)
+	if !ok {
+		return nil, fmt.Errorf("parsing %s: the record must be an object", from)
+	}
+	return data, nil
+}
+
+// structureRules returns just MDS020. Scaffold gates on conformance
+// to the kind, not on style: a record's prose may run long or skip
+// a blank line a style rule wants, and that is for `fix` to settle.
+func structureRules() []rule.Rule {
+	var out []rule.Rule
+	for _, r := range rule.All() {
+		if r.ID() == "MDS020" {
+			out = append(out, r)
+		}
+	}
+	return out
+}
+
+// scaffoldNew renders a new file. The kinds resolve from the path
+// and the record's front matter, as they will once it is written.
+func scaffoldNew(
+	runner *engine.Runner, sess *mdsmith.Session, opts scaffoldOptions, data map[string]any,
+) ([]byte, int) {
+	if _, err := os.Stat(opts.path); err == nil {
+		return nil, extractErr(2, "%s exists; pass --update to patch it", opts.path)
+	} else if !errors.Is(err, fs.ErrNotExist) {
+		return nil, extractErr(2, "%v", err)
+	}
+	cfg := runner.Config
+	head, err := frontMatterSource(data)
+	if err != nil {
+		return nil, extractErr(2, "%v", err)
+	}
+	res, err := resolveSourceKinds(cfg, sess, opts.path, head)
+	if err != nil {
+		return nil, extractErr(2, "%v", err)
+	}
+	if code := validateExtractKind(cfg, res, opts.kind, opts.path); code != 0 {
+		return nil, code
+	}
+	f, err := lint.NewFileFromSource(opts.path, head, frontMatterEnabled(cfg))
+	if err != nil {
+		return nil, extractErr(2, "%v", err)
+	}
+	if rd := rootDirFromConfig(runner.ConfigPath); rd != "" {
+		f.SetRootDir(rd)
+	}
+	sch, code := composedSchemaFor(f, res, opts.kind)
+	if code != 0 {
+		return nil, code
+	}
+	out, err := extract.Render(sch, data)
+	if err != nil {
+		return nil, extractErr(2, "%v", err)
+	}
+	return out, 0
+}
+
+// frontMatterSource returns the record's front matter as a
+// delimited block, or nil when it has none.
+func frontMatterSource(data map[string]any) ([]byte, error) {
+	fm, ok := data["frontmatter"].(map[string]any)
+	if !ok || len(fm) == 0 {
+		return nil, nil
+	}
+	y, err := yamlutil.Marshal(fm)
+	if err != nil {
+		return nil, fmt.Errorf("frontmatter: %w", err)
+	}
+	return append(append([]byte("---\n"), y...), "---\n"...), nil
+}
+
+// resolveSourceKinds is resolveFileWith for a document that exists
+// only in memory.
+func resolveSourceKinds(
+	cfg *config.Config, sess *mdsmith.Session, path string, source []byte,
+) (*config.FileResolution, error) {
+	if !frontMatterEnabled(cfg) {
+		return sess.ResolveFile(path, nil, nil), nil
+	}
+	prefix, _ := lint.StripFrontMatter(source)
+	kinds, err := lint.ParseFrontMatterKinds(prefix)
+	if err != nil {
+		return nil, err
+	}
+	if err := config.ValidateFrontMatterKinds(cfg, path, kinds); err != nil {
+		return nil, err
+	}
+	fields, err := lint.ParseFrontMatterFields(prefix)
+	if err != nil {
+		return nil, fmt.Errorf("parsing front matter: %w", err)
+	}
+	return sess.ResolveFile(path, kinds, fields), nil
+}
+
+// scaffoldUpdate patches an existing file. The file must conform
+// first: the patch is located through its match tree.
+func scaffoldUpdate(
+	runner *engine.Runner, sess *mdsmith.Session, opts scaffoldOptions, data map[string]any,
+) ([]byte, int) {
+	cfg, path := runner.Config, opts.path
+	res, err := resolveFileWith(cfg, sess, path, runner.MaxInputBytes)
+	if err != nil {
+		return nil, extractErr(2, "%v", err)
+	}
+	if code := validateExtractKind(cfg, res, opts.kind, path); code != 0 {
+		return nil, code
+	}
+	if code := gateResultCode(runner.Run([]string{path})); code != 0 {
+		return nil, code
+	}
+	f, source, code := loadExtractFile(cfg, runner.ConfigPath, path, runner.MaxInputBytes)
+	if code != 0 {
+		return nil, code
+	}
+	sch, code := composedSchemaFor(f, res, opts.kind)
+	if code != 0 {
+		return nil, code
+	}
+	docFM, code := decodeDocFrontMatter(cfg, source, path)
+	if code != 0 {
+		return nil, code
+	}
+	out, err := extract.Patch(f, sch, schema.BuildMatchTree(f, sch, docFM), data)
+	if err != nil {
+		return nil, extractErr(2, "%v", err)
+	}
+	return out, 0
+}
+
+// verifyScaffold gates the new source on MDS020 and checks that it
+// extracts back to the record, so nothing is written that `mdsmith
+// extract` would read differently.
+func verifyScaffold(
+	runner *engine.Runner, opts scaffoldOptions, out []byte, data map[string]any,
+) int {
+	if code := gateResultCode(runner.RunSource(opts.path, out)); code != 0 {
+		return code
+	}
+	cfg := runner.Config
+	f, err := lint.NewFileFromSource(opts.path, out, frontMatterEnabled(cfg))
+	if err != nil {
+		return extractErr(2, "%v", err)
+	}
+	if rd := rootDirFromConfig(runner.ConfigPath); rd != "" {
+		f.SetRootDir(rd)
+	}
+	sess := sessionForCLI(cfg, runner.ConfigPath)
+	defer sess.Dispose()
+	res, err := resolveSourceKinds(cfg, sess, opts.path, out)
+	if err != nil {
+		return extractErr(2, "%v", err)
+	}
+	sch, code := composedSchemaFor(f, res, opts.kind)
+	if code != 0 {
+		return code
+	}
+	docFM, code := decodeDocFrontMatter(cfg, out, opts.path)
+	if code != 0 {
+		return code
+	}
+	got, diags := extract.Extract(f, sch, schema.BuildMatchTree(f, sch, docFM))
+	if len(diags) > 0 {
+		formatDiagnostics(diags, "text", false)
+		return 1
+	}
+	if p := extract.Mismatch(data, got); p != "" {
+		return extractErr(1,
+			"%s: the value does not survive the round trip "+
+				"(the Markdown would extract differently)", p)
+	}
+	return 0
+}
+
+// emitRaw writes out to stdout.
+func emitRaw(out []byte) int {
+	w := extractStdout
+	if w == nil {
+		w = os.Stdout
+	}
+	if _, err := w.Write(out); err != nil {
+		return extractErr(2, "writing output: %v", err)
+	}
+	return 0
+}
@@ -3,13 +3,13 @@
 // and catalog templates. It imports no internal mdsmith package, so it
 // is usable on its own.
 //
-// [Compile] turns CUE source into a [Value]. [CompileJSON] lifts a
-// strict-JSON document (marshalled front matter, in mdsmith's case)
-// into the same type. [Value.Unify] merges two values, typically a
-// schema and its data. [Value.Validate] reports whether the merged
-// value is concrete and free of conflicts. [Errors] decomposes a
-// Validate error into one [PathError] per failing field, each tagged
-// with the path of the field that failed.
+// [Compile] turns CUE source into a [Value]; [Parse] only checks its
+// syntax. [CompileJSON] lifts a strict-JSON document (marshalled front
+// matter, in mdsmith's case) into the same type. [Value.Unify] merges
+// two values, typically a schema and its data. [Value.Validate]
+// reports whether the merged value is concrete and free of conflicts.
+// [Errors] decomposes a Validate error into one [PathError] per
+// failing field, each tagged with the path of the field that failed.
 //
 // [ParsePath] parses the string-label subset of CUE paths — dotted,
 // quoted, bracket, raw-string, and multiline-string labels — into a
@@ -153,16 +153,16 @@ func (s *scanner) skipTrivia() bool {
 	return newline
 }
 
-// isIdentStart reports whether c can start an identifier: a letter or
-// underscore. CUE also allows a `#`/`_#` definition prefix and a `$`; the
-// subset rejects definitions, so a leading `#` is handled by scanString's
-// raw-string path or scanPunct, not here.
+// isIdentStart reports whether c can start an identifier: a letter, an
+// underscore, or a `$`, as in ParsePath. CUE also allows a `#`/`_#`
+// definition prefix; the subset rejects definitions, so a leading `#` is
+// handled by scanString's raw-string path or scanPunct, not here.
 func isIdentStart(c byte) bool {
-	return c == '_' || (c >= 'a' && c <= 'z') || (c >= 'A' && c <= 'Z')
+	return c == '_' || c == '$' || (c >= 'a' && c <= 'z') || (c >= 'A' && c <= 'Z')
 }
 
 // isIdentPart reports whether c can continue an identifier: a letter, digit,
-// or underscore.
+// underscore, or `$`.
 func isIdentPart(c byte) bool {
 	return isIdentStart(c) || (c >= '0' && c <= '9')
 }
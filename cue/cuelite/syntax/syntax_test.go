@@ -44,6 +44,13 @@ func TestParseFile_basicShapes(t *testing.T) {
 	st := outer.Value.(*StructLit)
 	require.Len(t, st.Elts, 1)
 	assert.Equal(t, "status", st.Elts[0].(*Field).Label.(*Ident).Name)
+
+	// A `$` may start or continue an identifier, as in CUE.
+	f, err = parse(t, `$doc: a$b: 1`)
+	require.NoError(t, err)
+	outer = f.Decls[0].(*Field)
+	assert.Equal(t, "$doc", outer.Label.(*Ident).Name)
+	assert.Equal(t, "a$b", outer.Value.(*StructLit).Elts[0].(*Field).Label.(*Ident).Name)
 }
 
 // TestParseFile_operatorsAndPrecedence checks the binary precedence: `a & b | c`
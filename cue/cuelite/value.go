@@ -2,6 +2,8 @@ package cuelite
 
 import (
 	stderrors "errors"
+
+	"github.com/jeduden/mdsmith/cue/cuelite/syntax"
 )
 
 // Value is an immutable compiled value in the in-house CUE-subset engine.
@@ -63,6 +65,14 @@ func Compile(src string) (Value, error) {
 	return Value{v: ev}, nil
 }
 
+// Parse reports a syntax error in a CUE-subset source string without
+// evaluating it: a source Compile rejects only for a contradiction or
+// an unsupported operator parses cleanly.
+func Parse(src string) error {
+	_, err := syntax.ParseFile(src)
+	return err
+}
+
 // CompileJSON compiles a strict-JSON document into a [Value] using the
 // in-house JSON lifter. The input must be strict JSON: an unquoted key or a
 // CUE expression is rejected.
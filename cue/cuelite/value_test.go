@@ -31,6 +31,12 @@ func TestCompile(t *testing.T) {
 	})
 }
 
+func TestParse(t *testing.T) {
+	assert.NoError(t, Parse(`{x: int & string}`))
+	assert.NoError(t, Parse("a: 1\nb: a + 1\n"))
+	assert.ErrorContains(t, Parse("a: {\n b: 1\n"), "expected '}'")
+}
+
 // assertBottomError asserts that verr is a non-nil error decomposing to a
 // single path-free *PathError carrying wantMsg — the shape Validate returns
 // for every bottom Value, so the Errors invariant holds.
@@ -164,3 +164,15 @@ func EnsureTrailingNewline(s string) string {
 	}
 	return s + "\n"
 }
+
+// SplitList splits a newline-joined YAML list parameter into trimmed,
+// non-empty entries.
+func SplitList(v string) []string {
+	var out []string
+	for _, s := range strings.Split(v, "\n") {
+		if s = strings.TrimSpace(s); s != "" {
+			out = append(out, s)
+		}
+	}
+	return out
+}
@@ -250,6 +250,12 @@ func TestEngine_Check_NonStringValues(t *testing.T) {
 		"expected 'non-string value' message, got %q", diags[0].Message)
 }
 
+func TestValidateStringParams_BareKeyIsEmpty(t *testing.T) {
+	params, diags := ValidateStringParams("test.md", 1, map[string]any{"wrap": nil}, "MDS999", "mock")
+	require.Empty(t, diags)
+	assert.Equal(t, map[string]string{"wrap": ""}, params)
+}
+
 func TestEngine_Check_IntegerValueCoerced(t *testing.T) {
 	// Integer YAML params (e.g. min-level: 2) are coerced to string "2".
 	src := "<?mock\nkey: 42\n?>\n<?/mock?>\n"
@@ -478,6 +484,12 @@ func TestEnsureTrailingNewline_PreservesExisting(t *testing.T) {
 	assert.Equal(t, "hello\n", got, "expected %q, got %q", "hello\n", got)
 }
 
+func TestSplitList(t *testing.T) {
+	assert.Nil(t, SplitList(""))
+	assert.Nil(t, SplitList(" \n\n"))
+	assert.Equal(t, []string{"a", "b c"}, SplitList(" a\n\n b c \n"))
+}
+
 func TestSplitLines_Basic(t *testing.T) {
 	lines := SplitLines([]byte("a\nb\nc"))
 	require.Len(t, lines, 3, "expected 3 lines, got %d", len(lines))
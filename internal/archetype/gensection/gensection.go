@@ -39,3 +39,22 @@ type Directive interface {
 		params map[string]string,
 		columns map[string]ColumnConfig) (string, []lint.Diagnostic)
 }
+
+// DirectiveSet is implemented by a rule that serves several
+// directives whose names come from configuration rather than code
+// (e.g. user-directive). Callers that discover directives by name —
+// export, the merge driver, git-hook file discovery, generated-range
+// lookup — consult it alongside Directive.
+type DirectiveSet interface {
+	// Directives returns one Directive per configured name, sorted
+	// by name.
+	Directives() []Directive
+}
+
+// Expander is implemented by a Directive that renders by expanding to
+// another directive. GeneratedNames treats its body as generated when
+// the directive it expands to is.
+type Expander interface {
+	// Expands returns the name of the directive it expands to.
+	Expands() string
+}
@@ -251,7 +251,8 @@ func ExtractColumnsRaw(rawMap map[string]any) map[string]any {
 // allowing rules to accept list-valued parameters (e.g., multi-glob).
 // YAML integer and float scalars are converted to their decimal string
 // representation so rules with numeric parameters (e.g. min-level: 2)
-// do not require quoting in the directive body.
+// do not require quoting in the directive body. A key with no value,
+// such as a bare `wrap:`, is the empty string.
 func ValidateStringParams(
 	filePath string, line int, rawMap map[string]any, ruleID, ruleName string,
 ) (map[string]string, []lint.Diagnostic) {
@@ -261,6 +262,8 @@ func ValidateStringParams(
 		switch val := v.(type) {
 		case string:
 			params[k] = val
+		case nil:
+			params[k] = ""
 		case int:
 			params[k] = strconv.Itoa(val)
 		case float64:
@@ -4,34 +4,92 @@ import (
 	"bytes"
 
 	"github.com/jeduden/mdsmith/internal/lint"
+	"github.com/jeduden/mdsmith/internal/rule"
 )
 
-// generatedDirectiveNames are the directives whose generated bodies must
-// be excluded from host-file diagnostics and host-file metric counts.
-var generatedDirectiveNames = []string{"include", "catalog"}
+// generated lists the directives whose generated bodies must be
+// excluded from host-file diagnostics and host-file metric counts, in
+// registration order. Each directive's package adds itself from init
+// via RegisterGenerated.
+var generated []string
 
-// directiveMarkers are the byte prefixes used for the quick pre-check in
-// AuthoredSource. Kept in sync with generatedDirectiveNames.
-var directiveMarkers = [][]byte{[]byte("<?include"), []byte("<?catalog")}
+// generatedMarkers holds the "<?name" opener of each generated entry,
+// the byte prefixes HasGeneratedDirective scans for.
+var generatedMarkers [][]byte
 
-// HasGeneratedDirective reports whether source contains an include or
-// catalog directive opener — the directives whose generated bodies
-// FindAllGeneratedRanges excludes. It is a cheap byte scan with no parse,
-// so callers can decide on the raw bytes whether a file even has a
-// generated section. The engine's flat Layer-0 path (plan 2606142147) uses
-// it to keep files with generated sections on the AST path, where the
-// generated-range suppression is available.
-func HasGeneratedDirective(source []byte) bool {
-	for _, marker := range directiveMarkers {
+// RegisterGenerated marks name as a directive whose section body is
+// generated rather than authored. Call it from the init of the package
+// that defines the directive.
+func RegisterGenerated(name string) {
+	generated = append(generated, name)
+	generatedMarkers = append(generatedMarkers, []byte("<?"+name))
+}
+
+// IsGenerated reports whether name was registered with
+// RegisterGenerated.
+func IsGenerated(name string) bool {
+	for _, n := range generated {
+		if n == name {
+			return true
+		}
+	}
+	return false
+}
+
+// GeneratedNames returns the names of the configured directives whose
+// bodies are generated: those the DirectiveSet rules among rules serve
+// that expand to a registered generated directive. settings returns a
+// rule's effective settings, which decide the names a set serves; a
+// set whose settings do not apply contributes nothing. Callers store
+// the result in lint.File.GeneratedDirectives.
+func GeneratedNames(rules []rule.Rule, settings func(name string) map[string]any) []string {
+	var names []string
+	for _, r := range rules {
+		if _, ok := r.(DirectiveSet); !ok {
+			continue
+		}
+		if s := settings(r.Name()); s != nil {
+			r = rule.CloneRule(r)
+			if c, ok := r.(rule.Configurable); ok {
+				if err := c.ApplySettings(s); err != nil {
+					continue
+				}
+			}
+		}
+		for _, d := range r.(DirectiveSet).Directives() {
+			if e, ok := d.(Expander); ok && IsGenerated(e.Expands()) {
+				names = append(names, d.Name())
+			}
+		}
+	}
+	return names
+}
+
+// HasGeneratedDirective reports whether source contains the opener of a
+// registered generated directive or of one of the configured names —
+// the directives whose bodies FindAllGeneratedRanges excludes. It is a
+// cheap byte scan with no parse, so callers can decide on the raw bytes
+// whether a file even has a generated section. The engine's flat
+// Layer-0 path (plan 2606142147) uses it to keep files with generated
+// sections on the AST path, where the generated-range suppression is
+// available.
+func HasGeneratedDirective(source []byte, names ...string) bool {
+	for _, marker := range generatedMarkers {
 		if bytes.Contains(source, marker) {
 			return true
 		}
 	}
+	for _, name := range names {
+		if bytes.Contains(source, []byte("<?"+name)) {
+			return true
+		}
+	}
 	return false
 }
 
 // FindAllGeneratedRanges returns the content line ranges for all
-// include/catalog generated sections in f. Lines are 1-based and
+// registered generated sections in f, and for the sections of the
+// configured directives in f.GeneratedDirectives. Lines are 1-based and
 // relative to f.Source (i.e. post-front-matter when the file was
 // created with NewFileFromSource).
 //
@@ -39,31 +97,40 @@ func HasGeneratedDirective(source []byte) bool {
 // malformed markers), that directive's ranges are omitted entirely so the
 // engine never suppresses diagnostics based on an ambiguous range boundary.
 func FindAllGeneratedRanges(f *lint.File) []lint.LineRange {
-	// Fast path: a file with no `<?include`/`<?catalog` marker bytes can
-	// hold no generated section, so skip the two AST child-walks
+	// Fast path: a file with no generated-directive marker bytes can
+	// hold no generated section, so skip the AST child-walks
 	// FindMarkerPairs would otherwise run per directive name. The byte scan
 	// is the same one HasGeneratedDirective uses, so the result is identical
 	// to the walk (no markers ⟹ no ranges) — it just avoids the walk on the
 	// common directive-free file.
-	if !HasGeneratedDirective(f.Source) {
+	if !HasGeneratedDirective(f.Source, f.GeneratedDirectives...) {
 		return nil
 	}
 	var ranges []lint.LineRange
-	for _, name := range generatedDirectiveNames {
-		pairs, diags := FindMarkerPairs(f, name, "", "")
-		if len(diags) > 0 {
-			continue // malformed markers — skip to avoid filtering based on invalid spans
-		}
-		for _, mp := range pairs {
-			if mp.ContentFrom <= mp.ContentTo {
-				ranges = append(ranges, lint.LineRange{From: mp.ContentFrom, To: mp.ContentTo})
-			}
+	for _, name := range generated {
+		ranges = appendRanges(ranges, f, name)
+	}
+	for _, name := range f.GeneratedDirectives {
+		ranges = appendRanges(ranges, f, name)
+	}
+	return ranges
+}
+
+// appendRanges appends the body ranges of name's sections in f.
+func appendRanges(ranges []lint.LineRange, f *lint.File, name string) []lint.LineRange {
+	pairs, diags := FindMarkerPairs(f, name, "", "")
+	if len(diags) > 0 {
+		return ranges // malformed markers — skip to avoid filtering based on invalid spans
+	}
+	for _, mp := range pairs {
+		if mp.ContentFrom <= mp.ContentTo {
+			ranges = append(ranges, lint.LineRange{From: mp.ContentFrom, To: mp.ContentTo})
 		}
 	}
 	return ranges
 }
 
-// AuthoredSource returns source with the bodies of all include/catalog
+// AuthoredSource returns source with the bodies of all registered
 // generated sections removed (the opening and closing markers are kept).
 // This gives the "authored bytes" — what the file author wrote, excluding
 // fragments pulled in by directives. Used by the metrics pipeline so that
@@ -4,10 +4,19 @@ import (
 	"testing"
 
 	"github.com/jeduden/mdsmith/internal/lint"
+	"github.com/jeduden/mdsmith/internal/rule"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 )
 
+// The directive packages register themselves but import gensection,
+// so these tests register the names they exercise.
+func init() {
+	RegisterGenerated("include")
+	RegisterGenerated("catalog")
+	RegisterGenerated("data")
+}
+
 func mustNewFile(t *testing.T, path, src string) *lint.File {
 	t.Helper()
 	f, err := lint.NewFile(path, []byte(src))
@@ -58,6 +67,18 @@ func TestFindAllGeneratedRanges_CatalogSection(t *testing.T) {
 	assert.Equal(t, 6, ranges[0].To)
 }
 
+func TestFindAllGeneratedRanges_DataSection(t *testing.T) {
+	src := "# Data\n\n<?data\nfile: a.csv\n?>\n| a |\n| - |\n| 1 |\n<?/data?>\n"
+	f := mustNewFile(t, "data.md", src)
+
+	ranges := FindAllGeneratedRanges(f)
+	require.Len(t, ranges, 1)
+	assert.Equal(t, 6, ranges[0].From)
+	assert.Equal(t, 8, ranges[0].To)
+	assert.Equal(t, "# Data\n\n<?data\nfile: a.csv\n?>\n<?/data?>\n",
+		string(AuthoredSource([]byte(src))))
+}
+
 func TestFindAllGeneratedRanges_EmptyBody(t *testing.T) {
 	// No content between markers: ContentFrom > ContentTo → no range recorded.
 	src := "# Host\n\n<?include\nfile: frag.md\n?>\n<?/include?>\n"
@@ -148,3 +169,66 @@ func TestFindAllGeneratedRanges_NoDirectiveFastPath(t *testing.T) {
 	f := mustNewFile(t, "doc.md", "# Title\n\nProse with no directive.\n\n## Next\n\nMore.\n")
 	assert.Nil(t, FindAllGeneratedRanges(f), "directive-free file has no generated ranges")
 }
+
+// expandingDirective is a configured directive that expands to another.
+type expandingDirective struct {
+	mockDirective
+	name, expands string
+}
+
+func (d *expandingDirective) Name() string    { return d.name }
+func (d *expandingDirective) Expands() string { return d.expands }
+
+// setRule is a DirectiveSet whose `directives` setting maps each
+// directive name to the directive it expands to.
+type setRule struct {
+	defs map[string]any
+}
+
+func (r *setRule) ID() string                         { return "MDS998" }
+func (r *setRule) Name() string                       { return "set" }
+func (r *setRule) Category() string                   { return "directive" }
+func (r *setRule) Check(*lint.File) []lint.Diagnostic { return nil }
+func (r *setRule) DefaultSettings() map[string]any    { return map[string]any{} }
+
+func (r *setRule) ApplySettings(settings map[string]any) error {
+	if defs, ok := settings["directives"].(map[string]any); ok {
+		r.defs = defs
+	}
+	return nil
+}
+
+func (r *setRule) Directives() []Directive {
+	var out []Directive
+	for name, expands := range r.defs {
+		out = append(out, &expandingDirective{name: name, expands: expands.(string)})
+	}
+	return out
+}
+
+func TestGeneratedNames(t *testing.T) {
+	settings := map[string]any{"directives": map[string]any{"notes": "data"}}
+	rules := []rule.Rule{&setRule{}}
+	got := GeneratedNames(rules, func(name string) map[string]any {
+		if name == "set" {
+			return settings
+		}
+		return nil
+	})
+	assert.Equal(t, []string{"notes"}, got)
+
+	settings["directives"] = map[string]any{"notes": "toc"}
+	assert.Empty(t, GeneratedNames(rules, func(string) map[string]any { return settings }),
+		"a directive that expands to an unregistered name is authored")
+}
+
+func TestFindAllGeneratedRanges_ConfiguredDirective(t *testing.T) {
+	src := "# Host\n\n<?notes\n?>\nrow\n<?/notes?>\n"
+	f := mustNewFile(t, "host.md", src)
+	assert.Nil(t, FindAllGeneratedRanges(f), "an unconfigured name is authored text")
+
+	f.GeneratedDirectives = []string{"notes"}
+	assert.Equal(t, []lint.LineRange{{From: 5, To: 5}}, FindAllGeneratedRanges(f))
+	assert.True(t, HasGeneratedDirective([]byte(src), "notes"))
+	assert.False(t, HasGeneratedDirective([]byte(src)))
+}
@@ -36,6 +36,9 @@ type ExecConfig struct {
 	// recipe. Nil means the compiled default list; a non-nil list
 	// *replaces* the default (it does not append).
 	EnvPassThrough []string
+	// Sandbox confines each recipe on Linux; the zero value runs it
+	// unconfined.
+	Sandbox Sandbox
 }
 
 // defaultExecConfig returns the compiled defaults as an ExecConfig.
@@ -105,31 +108,19 @@ type runOpts struct {
 // Windows), waits up to gracePeriod, then force-kills the group, so a
 // recipe that spawns daemons cannot leave orphans behind.
 //
+// With o.exec.Sandbox enabled the recipe runs inside the build sandbox
+// (see startSandboxed). A sandbox setup failure is returned in place of
+// the exit error, and a non-zero exit carries a hint that the sandbox
+// may have denied a write or a connection.
+//
 // It returns the process exit code, whether the run timed out, and any
 // error. On success it returns (0, false, nil). On non-zero exit it
 // returns the exit code and a non-nil error. On timeout it returns the
 // exit code (or -1 if unavailable), timedOut=true, and a non-nil error.
 func runRecipe(ctx context.Context, o runOpts) (int, bool, error) {
-	// We manage the timeout and kill path ourselves (process group), so the
-	// command itself is not bound to a context-cancel kill — that would
-	// only kill the leader, not the group.
-	cmd := exec.Command(o.argv[0], o.argv[1:]...) //nolint:gosec // argv is explicit; user-declared recipe
-	cmd.Dir = o.dir
-	if o.stdout != nil {
-		cmd.Stdout = o.stdout
-	} else {
-		cmd.Stdout = os.Stderr
-	}
-	if o.stderr != nil {
-		cmd.Stderr = o.stderr
-	} else {
-		cmd.Stderr = os.Stderr
-	}
-	cmd.Env = buildEnv(o.exec, o.defExec)
-	configureProcessGroup(cmd)
-
-	if err := cmd.Start(); err != nil {
-		return -1, false, fmt.Errorf("starting recipe: %w", err)
+	cmd, sandboxStatus, err := startRecipe(o)
+	if err != nil {
+		return -1, false, err
 	}
 
 	jobCleanup := afterStartFn(cmd)
@@ -146,18 +137,29 @@ func runRecipe(ctx context.Context, o runOpts) (int, bool, error) {
 	// ctx.Done() we kill the whole group ourselves, then drain the Wait.
 	select {
 	case err := <-done:
-		if err == nil {
-			return 0, false, nil
-		}
 		exitCode := -1
 		var ee *exec.ExitError
 		if errors.As(err, &ee) {
 			exitCode = ee.ExitCode()
 		}
+		if sandboxStatus != nil {
+			if setupErr := sandboxStatus(); setupErr != nil {
+				return exitCode, false, setupErr
+			}
+			if err != nil {
+				return exitCode, false, fmt.Errorf("%w (%s)", err, sandboxViolationHint)
+			}
+		}
+		if err == nil {
+			return 0, false, nil
+		}
 		return exitCode, false, err
 	case <-ctx.Done():
 		killGroup(cmd)
 		waitErr := <-done
+		if sandboxStatus != nil {
+			_ = sandboxStatus() // close the status pipe; the timeout is the error
+		}
 		exitCode := -1
 		var ee *exec.ExitError
 		if errors.As(waitErr, &ee) {
@@ -170,6 +172,45 @@ func runRecipe(ctx context.Context, o runOpts) (int, bool, error) {
 	}
 }
 
+// recipeCommand returns the unstarted command for o: argv with no
+// shell, the staging dir as working directory, the hermetic
+// environment, and its own process group.
+func recipeCommand(o runOpts) *exec.Cmd {
+	// We manage the timeout and kill path ourselves (process group), so the
+	// command itself is not bound to a context-cancel kill — that would
+	// only kill the leader, not the group.
+	cmd := exec.Command(o.argv[0], o.argv[1:]...) //nolint:gosec // argv is explicit; user-declared recipe
+	cmd.Dir = o.dir
+	if o.stdout != nil {
+		cmd.Stdout = o.stdout
+	} else {
+		cmd.Stdout = os.Stderr
+	}
+	if o.stderr != nil {
+		cmd.Stderr = o.stderr
+	} else {
+		cmd.Stderr = os.Stderr
+	}
+	cmd.Env = buildEnv(o.exec, o.defExec)
+	configureProcessGroup(cmd)
+	return cmd
+}
+
+// startRecipe starts the recipe, inside the build sandbox when
+// o.exec.Sandbox is enabled. For a sandboxed start it also returns a
+// func that, once the child has exited, reports a sandbox setup
+// failure; it is nil otherwise.
+func startRecipe(o runOpts) (*exec.Cmd, func() error, error) {
+	if o.exec.Sandbox.Enabled {
+		return startSandboxed(o)
+	}
+	cmd := recipeCommand(o)
+	if err := cmd.Start(); err != nil {
+		return nil, nil, fmt.Errorf("starting recipe: %w", err)
+	}
+	return cmd, nil, nil
+}
+
 // gracePeriod is how long mdsmith waits after the first (polite)
 // termination signal before force-killing the process group. It is a var,
 // not a const, so a kill-path test can shorten it.
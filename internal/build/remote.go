@@ -0,0 +1,350 @@
+package build
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// RemoteTokenEnv names the environment variable whose value, when set,
+// is sent as a bearer token on every request to an HTTP build cache.
+// Like every parent variable it is withheld from recipes.
+const RemoteTokenEnv = "MDSMITH_BUILD_CACHE_TOKEN"
+
+// ErrRemoteMiss is returned by RemoteCache.Get when the key is absent.
+var ErrRemoteMiss = errors.New("not in the remote build cache")
+
+// RemoteCache is a shared, content-addressed store of built outputs.
+// Keys are slash-separated: "ac/<action-id>" holds the JSON manifest of
+// one build, "cas/<sha256 hex>" the bytes of one output. A value under
+// a key never changes meaning, so backends need no locking; a Put may
+// simply replace what is there.
+type RemoteCache interface {
+	// Get opens the object stored under key. A missing key returns
+	// ErrRemoteMiss.
+	Get(ctx context.Context, key string) (io.ReadCloser, error)
+	// Put stores size bytes from r under key.
+	Put(ctx context.Context, key string, r io.Reader, size int64) error
+}
+
+// remoteTimeout bounds one request to an HTTP build cache.
+const remoteTimeout = 60 * time.Second
+
+// maxManifestBytes caps the manifest read from a remote cache.
+const maxManifestBytes = 1 << 20
+
+// OpenRemoteCache returns the backend for a build.cache.url value. An
+// http:// or https:// URL selects the HTTP protocol; a file:// URL or a
+// plain path selects a shared directory. A relative path is resolved
+// against root.
+func OpenRemoteCache(rawURL, root string) (RemoteCache, error) {
+	switch {
+	case strings.HasPrefix(rawURL, "http://"), strings.HasPrefix(rawURL, "https://"):
+		if _, err := url.Parse(rawURL); err != nil {
+			return nil, fmt.Errorf("build cache url %q: %w", rawURL, err)
+		}
+		return &httpRemote{
+			base:   strings.TrimSuffix(rawURL, "/"),
+			client: &http.Client{Timeout: remoteTimeout},
+			token:  os.Getenv(RemoteTokenEnv),
+		}, nil
+	case strings.HasPrefix(rawURL, "file://"):
+		u, err := url.Parse(rawURL)
+		if err != nil {
+			return nil, fmt.Errorf("build cache url %q: %w", rawURL, err)
+		}
+		return dirRemote{dir: filepath.FromSlash(u.Path)}, nil
+	case strings.Contains(rawURL, "://"):
+		return nil, fmt.Errorf("build cache url %q: unsupported scheme", rawURL)
+	}
+	dir := rawURL
+	if !filepath.IsAbs(dir) {
+		dir = filepath.Join(root, dir)
+	}
+	return dirRemote{dir: dir}, nil
+}
+
+// dirRemote stores objects as files under a shared directory, one file
+// per key.
+type dirRemote struct {
+	dir string
+}
+
+// Get opens the file for key.
+func (d dirRemote) Get(_ context.Context, key string) (io.ReadCloser, error) {
+	f, err := os.Open(filepath.Join(d.dir, filepath.FromSlash(key)))
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, ErrRemoteMiss
+	}
+	return f, err
+}
+
+// Put writes key through a temp file and a rename, so a concurrent
+// reader sees either the old object or the whole new one.
+func (d dirRemote) Put(_ context.Context, key string, r io.Reader, _ int64) error {
+	final := filepath.Join(d.dir, filepath.FromSlash(key))
+	if err := os.MkdirAll(filepath.Dir(final), 0o755); err != nil {
+		return err
+	}
+	tmp, err := os.CreateTemp(filepath.Dir(final), ".tmp-*")
+	if err != nil {
+		return err
+	}
+	_, err = io.Copy(tmp, r)
+	if cerr := tmp.Close(); err == nil {
+		err = cerr
+	}
+	if err == nil {
+		err = os.Chmod(tmp.Name(), 0o644)
+	}
+	if err == nil {
+		err = os.Rename(tmp.Name(), final)
+	}
+	if err != nil {
+		_ = os.Remove(tmp.Name())
+	}
+	return err
+}
+
+// httpRemote speaks a minimal protocol: GET <base>/<key> returns the
+// object or 404, PUT <base>/<key> stores the request body.
+type httpRemote struct {
+	base   string
+	client *http.Client
+	token  string
+}
+
+// Get fetches key; 404 is a miss.
+func (h *httpRemote) Get(ctx context.Context, key string) (io.ReadCloser, error) {
+	resp, err := h.do(ctx, http.MethodGet, key, nil, 0)
+	if err != nil {
+		return nil, err
+	}
+	switch resp.StatusCode {
+	case http.StatusOK:
+		return resp.Body, nil
+	case http.StatusNotFound:
+		_ = resp.Body.Close()
+		return nil, ErrRemoteMiss
+	}
+	_ = resp.Body.Close()
+	return nil, fmt.Errorf("GET %s: %s", key, resp.Status)
+}
+
+// Put uploads r as key.
+func (h *httpRemote) Put(ctx context.Context, key string, r io.Reader, size int64) error {
+	resp, err := h.do(ctx, http.MethodPut, key, r, size)
+	if err != nil {
+		return err
+	}
+	_ = resp.Body.Close()
+	if resp.StatusCode/100 != 2 {
+		return fmt.Errorf("PUT %s: %s", key, resp.Status)
+	}
+	return nil
+}
+
+// do sends one request, with the bearer token when one is set.
+func (h *httpRemote) do(
+	ctx context.Context, method, key string, body io.Reader, size int64,
+) (*http.Response, error) {
+	req, err := http.NewRequestWithContext(ctx, method, h.base+"/"+key, body)
+	if err != nil {
+		return nil, err
+	}
+	if body != nil {
+		req.ContentLength = size
+	}
+	if h.token != "" {
+		req.Header.Set("Authorization", "Bearer "+h.token)
+	}
+	return h.client.Do(req)
+}
+
+// remoteManifest is the "ac/<action-id>" object: the outputs one build
+// produced, each with the content hash naming its "cas/" blob.
+type remoteManifest struct {
+	Version  int          `json:"version"`
+	ActionID string       `json:"action-id"`
+	Outputs  []OutputHash `json:"outputs"`
+}
+
+// contentHashRe matches a well-formed "sha256-<hex>" hash, so a hash
+// from a remote manifest can name a blob key and nothing else.
+var contentHashRe = regexp.MustCompile(`^sha256-[0-9a-f]{64}$`)
+
+// blobKey returns the "cas/" key of a "sha256-<hex>" content hash.
+func blobKey(hash string) string {
+	return "cas/" + strings.TrimPrefix(hash, "sha256-")
+}
+
+// UploadToRemote stores a freshly built entry in rc: each output's bytes
+// first, then the manifest, so a reader that finds the manifest finds
+// its blobs too.
+func UploadToRemote(ctx context.Context, rc RemoteCache, root string, entry CacheEntry) error {
+	for _, o := range entry.Outputs {
+		if err := uploadBlob(ctx, rc, root, o); err != nil {
+			return err
+		}
+	}
+	data, err := json.Marshal(remoteManifest{
+		Version: CacheVersion, ActionID: entry.ActionID, Outputs: entry.Outputs,
+	})
+	if err != nil {
+		return err
+	}
+	return rc.Put(ctx, "ac/"+entry.ActionID, strings.NewReader(string(data)), int64(len(data)))
+}
+
+// uploadBlob stores one output file under its content hash.
+func uploadBlob(ctx context.Context, rc RemoteCache, root string, o OutputHash) error {
+	f, err := os.Open(filepath.Join(root, filepath.FromSlash(o.Path)))
+	if err != nil {
+		return err
+	}
+	defer f.Close() //nolint:errcheck // read-only
+	info, err := f.Stat()
+	if err != nil {
+		return err
+	}
+	if err := rc.Put(ctx, blobKey(o.Hash), f, info.Size()); err != nil {
+		return fmt.Errorf("uploading %s: %w", o.Path, err)
+	}
+	return nil
+}
+
+// RestoreFromRemote looks actionID up in rc and, on a hit, writes the
+// stored outputs to the target's declared paths without running its
+// recipe. Every blob is downloaded into a staging dir and checked
+// against the manifest's sha256 before any output is committed, so a
+// corrupt or tampered cache cannot change the tree. It returns false
+// and a nil error on a miss.
+func RestoreFromRemote(ctx context.Context, rc RemoteCache, target Target, actionID string) (bool, error) {
+	outputs, err := resolveOutputsFn(StalenessInput{Target: target})
+	if err != nil {
+		return false, err
+	}
+	m, err := fetchManifest(ctx, rc, actionID)
+	if errors.Is(err, ErrRemoteMiss) {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+	hashes, err := manifestHashes(m, actionID, outputs)
+	if err != nil {
+		return false, err
+	}
+
+	stagingRoot, err := ensureStagingRoot(target.Root)
+	if err != nil {
+		return false, err
+	}
+	stageDir, err := mkdirTempFn(stagingRoot, "restore-")
+	if err != nil {
+		return false, fmt.Errorf("creating staging dir: %w", err)
+	}
+	defer os.RemoveAll(stageDir) //nolint:errcheck // best-effort cleanup
+
+	finals := make([]string, len(outputs))
+	stagePaths := make([]string, len(outputs))
+	for i, rel := range outputs {
+		stagePaths[i] = filepath.Join(stageDir, "out"+strconv.Itoa(i))
+		finals[i] = filepath.Join(target.Root, filepath.FromSlash(rel))
+		if err := downloadBlob(ctx, rc, hashes[rel], stagePaths[i]); err != nil {
+			return false, fmt.Errorf("restoring %s: %w", rel, err)
+		}
+	}
+	if err := commitOutputs(finals, outputs, stagePaths); err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+// fetchManifest reads and decodes the manifest stored for actionID.
+func fetchManifest(ctx context.Context, rc RemoteCache, actionID string) (remoteManifest, error) {
+	body, err := rc.Get(ctx, "ac/"+actionID)
+	if err != nil {
+		return remoteManifest{}, err
+	}
+	defer body.Close() //nolint:errcheck // read-only
+	data, err := io.ReadAll(io.LimitReader(body, maxManifestBytes+1))
+	if err != nil {
+		return remoteManifest{}, err
+	}
+	if len(data) > maxManifestBytes {
+		return remoteManifest{}, fmt.Errorf("manifest for %s exceeds %d bytes", actionID, maxManifestBytes)
+	}
+	var m remoteManifest
+	if err := json.Unmarshal(data, &m); err != nil {
+		return remoteManifest{}, fmt.Errorf("parsing manifest for %s: %w", actionID, err)
+	}
+	return m, nil
+}
+
+// manifestHashes checks that m describes exactly the declared outputs
+// under actionID and returns each output's content hash.
+func manifestHashes(m remoteManifest, actionID string, outputs []string) (map[string]string, error) {
+	if m.ActionID != actionID {
+		return nil, fmt.Errorf("manifest for %s names action %q", actionID, m.ActionID)
+	}
+	hashes := make(map[string]string, len(m.Outputs))
+	for _, o := range m.Outputs {
+		if !contentHashRe.MatchString(o.Hash) {
+			return nil, fmt.Errorf("manifest for %s has malformed hash %q for %s", actionID, o.Hash, o.Path)
+		}
+		hashes[o.Path] = o.Hash
+	}
+	if len(hashes) != len(outputs) {
+		return nil, fmt.Errorf("manifest for %s lists %d outputs, the directive declares %d",
+			actionID, len(hashes), len(outputs))
+	}
+	for _, rel := range outputs {
+		if _, ok := hashes[rel]; !ok {
+			return nil, fmt.Errorf("manifest for %s does not list output %s", actionID, rel)
+		}
+	}
+	return hashes, nil
+}
+
+// downloadBlob copies the blob for hash to dst, failing when the bytes
+// do not hash to it.
+func downloadBlob(ctx context.Context, rc RemoteCache, hash, dst string) error {
+	body, err := rc.Get(ctx, blobKey(hash))
+	if errors.Is(err, ErrRemoteMiss) {
+		return fmt.Errorf("blob %s is missing", hash)
+	}
+	if err != nil {
+		return err
+	}
+	defer body.Close() //nolint:errcheck // read-only
+
+	f, err := os.OpenFile(dst, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0o644) //nolint:gosec // dst is inside the staging dir
+	if err != nil {
+		return err
+	}
+	h := sha256.New()
+	_, err = io.Copy(io.MultiWriter(f, h), body)
+	if cerr := f.Close(); err == nil {
+		err = cerr
+	}
+	if err != nil {
+		return err
+	}
+	if got := "sha256-" + hex.EncodeToString(h.Sum(nil)); got != hash {
+		return fmt.Errorf("integrity check failed: want %s, got %s", hash, got)
+	}
+	return nil
+}
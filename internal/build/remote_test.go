@@ -0,0 +1,212 @@
+package build
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// standInServer serves the build-cache HTTP protocol from a directory:
+// GET returns the object or 404, PUT stores the body. It records the
+// Authorization header of the last request.
+func standInServer(t *testing.T, dir string) (*httptest.Server, *string) {
+	t.Helper()
+	store := dirRemote{dir: dir}
+	var auth string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		auth = r.Header.Get("Authorization")
+		key := strings.TrimPrefix(r.URL.Path, "/cache/")
+		switch r.Method {
+		case http.MethodGet:
+			body, err := store.Get(r.Context(), key)
+			if errors.Is(err, ErrRemoteMiss) {
+				http.NotFound(w, r)
+				return
+			}
+			require.NoError(t, err)
+			defer body.Close() //nolint:errcheck // test
+			_, _ = io.Copy(w, body)
+		case http.MethodPut:
+			require.NoError(t, store.Put(r.Context(), key, r.Body, r.ContentLength))
+			w.WriteHeader(http.StatusCreated)
+		default:
+			w.WriteHeader(http.StatusMethodNotAllowed)
+		}
+	}))
+	t.Cleanup(srv.Close)
+	return srv, &auth
+}
+
+// builtTarget writes out.txt under a fresh root and returns the target
+// with the cache entry a build would record for it.
+func builtTarget(t *testing.T, content string) (Target, CacheEntry) {
+	t.Helper()
+	root := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(root, "src.txt"), []byte("src"), 0o644))
+	require.NoError(t, os.WriteFile(filepath.Join(root, "out.txt"), []byte(content), 0o644))
+	tg := Target{Recipe: "gen", Root: root, Inputs: []string{"src.txt"}, Outputs: []string{"out.txt"}}
+	entry, err := RecordBuild(StalenessInput{Target: tg, Command: "gen {inputs} {outputs}"})
+	require.NoError(t, err)
+	return tg, entry
+}
+
+// freshClone returns tg rooted in a new dir holding only its input.
+func freshClone(t *testing.T, tg Target) Target {
+	t.Helper()
+	root := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(root, "src.txt"), []byte("src"), 0o644))
+	tg.Root = root
+	return tg
+}
+
+func TestRemote_RoundTrip(t *testing.T) {
+	srv, _ := standInServer(t, t.TempDir())
+	backends := map[string]func(t *testing.T) RemoteCache{
+		"dir": func(t *testing.T) RemoteCache {
+			rc, err := OpenRemoteCache("file://"+filepath.ToSlash(t.TempDir()), "")
+			require.NoError(t, err)
+			return rc
+		},
+		"http": func(t *testing.T) RemoteCache {
+			rc, err := OpenRemoteCache(srv.URL+"/cache/", "")
+			require.NoError(t, err)
+			return rc
+		},
+	}
+	for name, open := range backends {
+		t.Run(name, func(t *testing.T) {
+			rc := open(t)
+			tg, entry := builtTarget(t, "generated")
+			ctx := context.Background()
+
+			ok, err := RestoreFromRemote(ctx, rc, freshClone(t, tg), entry.ActionID)
+			require.NoError(t, err)
+			assert.False(t, ok, "an empty cache misses")
+
+			require.NoError(t, UploadToRemote(ctx, rc, tg.Root, entry))
+			clone := freshClone(t, tg)
+			ok, err = RestoreFromRemote(ctx, rc, clone, entry.ActionID)
+			require.NoError(t, err)
+			require.True(t, ok)
+			got, err := os.ReadFile(filepath.Join(clone.Root, "out.txt"))
+			require.NoError(t, err)
+			assert.Equal(t, "generated", string(got))
+
+			// The restored target is fresh against a cache recorded from it.
+			restored, err := RecordBuild(StalenessInput{Target: clone, Command: "gen {inputs} {outputs}"})
+			require.NoError(t, err)
+			assert.Equal(t, entry.ActionID, restored.ActionID)
+			assert.Equal(t, entry.Outputs, restored.Outputs)
+		})
+	}
+}
+
+func TestRemote_RelativeDirResolvesAgainstRoot(t *testing.T) {
+	root := t.TempDir()
+	rc, err := OpenRemoteCache(".shared-cache", root)
+	require.NoError(t, err)
+	assert.Equal(t, dirRemote{dir: filepath.Join(root, ".shared-cache")}, rc)
+
+	_, err = OpenRemoteCache("s3://bucket", root)
+	assert.EqualError(t, err, `build cache url "s3://bucket": unsupported scheme`)
+}
+
+func TestRemote_HTTPSendsBearerToken(t *testing.T) {
+	t.Setenv(RemoteTokenEnv, "s3cret")
+	srv, auth := standInServer(t, t.TempDir())
+	rc, err := OpenRemoteCache(srv.URL+"/cache", "")
+	require.NoError(t, err)
+	_, err = rc.Get(context.Background(), "ac/sha256-x")
+	assert.ErrorIs(t, err, ErrRemoteMiss)
+	assert.Equal(t, "Bearer s3cret", *auth)
+}
+
+func TestRemote_HTTPServerErrorIsReported(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(http.StatusForbidden)
+	}))
+	defer srv.Close()
+	rc, err := OpenRemoteCache(srv.URL, "")
+	require.NoError(t, err)
+	_, err = RestoreFromRemote(context.Background(), rc,
+		Target{Root: t.TempDir(), Outputs: []string{"out.txt"}}, "sha256-x")
+	assert.EqualError(t, err, "GET ac/sha256-x: 403 Forbidden")
+}
+
+func TestRemote_TamperedBlobFailsIntegrityCheck(t *testing.T) {
+	dir := t.TempDir()
+	rc := dirRemote{dir: dir}
+	tg, entry := builtTarget(t, "generated")
+	ctx := context.Background()
+	require.NoError(t, UploadToRemote(ctx, rc, tg.Root, entry))
+	blob := filepath.Join(dir, "cas", strings.TrimPrefix(entry.Outputs[0].Hash, "sha256-"))
+	require.NoError(t, os.WriteFile(blob, []byte("tampered"), 0o644))
+
+	clone := freshClone(t, tg)
+	ok, err := RestoreFromRemote(ctx, rc, clone, entry.ActionID)
+	assert.False(t, ok)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "restoring out.txt: integrity check failed: want "+entry.Outputs[0].Hash)
+	assert.NoFileExists(t, filepath.Join(clone.Root, "out.txt"))
+}
+
+func TestRemote_ManifestMustMatchDeclaredOutputs(t *testing.T) {
+	tg, entry := builtTarget(t, "generated")
+	hash := entry.Outputs[0].Hash
+	cases := map[string]struct {
+		manifest string
+		want     string
+	}{
+		"other action": {
+			manifest: `{"action-id":"sha256-other","outputs":[{"path":"out.txt","hash":"` + hash + `"}]}`,
+			want:     `names action "sha256-other"`,
+		},
+		"extra output": {
+			manifest: `{"action-id":"` + entry.ActionID + `","outputs":[{"path":"out.txt","hash":"` + hash +
+				`"},{"path":"../escape.txt","hash":"` + hash + `"}]}`,
+			want: "lists 2 outputs, the directive declares 1",
+		},
+		"other path": {
+			manifest: `{"action-id":"` + entry.ActionID + `","outputs":[{"path":"x.txt","hash":"` + hash + `"}]}`,
+			want:     "does not list output out.txt",
+		},
+		"malformed hash": {
+			manifest: `{"action-id":"` + entry.ActionID + `","outputs":[{"path":"out.txt","hash":"sha256-../../x"}]}`,
+			want:     `malformed hash "sha256-../../x"`,
+		},
+	}
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			rc := dirRemote{dir: t.TempDir()}
+			ctx := context.Background()
+			require.NoError(t, rc.Put(ctx, "ac/"+entry.ActionID, strings.NewReader(tc.manifest), 0))
+			clone := freshClone(t, tg)
+			ok, err := RestoreFromRemote(ctx, rc, clone, entry.ActionID)
+			assert.False(t, ok)
+			require.Error(t, err)
+			assert.Contains(t, err.Error(), tc.want)
+			assert.NoFileExists(t, filepath.Join(clone.Root, "out.txt"))
+		})
+	}
+}
+
+func TestRemote_MissingBlobFailsRestore(t *testing.T) {
+	rc := dirRemote{dir: t.TempDir()}
+	tg, entry := builtTarget(t, "generated")
+	ctx := context.Background()
+	require.NoError(t, UploadToRemote(ctx, rc, tg.Root, entry))
+	require.NoError(t, os.RemoveAll(filepath.Join(rc.dir, "cas")))
+
+	_, err := RestoreFromRemote(ctx, rc, freshClone(t, tg), entry.ActionID)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "blob "+entry.Outputs[0].Hash+" is missing")
+}
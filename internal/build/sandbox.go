@@ -0,0 +1,104 @@
+package build
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+)
+
+// Sandbox is the resolved build.exec.sandbox settings. The zero value
+// runs recipes unsandboxed, as before the sandbox existed.
+type Sandbox struct {
+	// Enabled confines every recipe: writes are allowed only under its
+	// staging dir, so declared inputs and the rest of the tree are
+	// read-only, and network access is denied unless AllowNetwork.
+	Enabled bool
+	// AllowNetwork keeps network access for a sandboxed recipe.
+	AllowNetwork bool
+}
+
+// sandboxSpecEnv names the environment variable carrying the JSON
+// sandboxSpec from mdsmith to the sandbox child. The child removes it
+// before it execs the recipe, so the recipe never sees it.
+const sandboxSpecEnv = "MDSMITH_SANDBOX_SPEC"
+
+// sandboxStatusFD is the descriptor the sandbox child reports a setup
+// failure on. It is close-on-exec in the child, so a successful exec of
+// the recipe closes it with nothing written.
+const sandboxStatusFD = 3
+
+// sandboxExitCode is the sandbox child's exit status when it could not
+// set up the sandbox. Like a shell's 126, it means the command never ran.
+const sandboxExitCode = 126
+
+// sandboxSpec is what the sandbox child needs to confine itself and
+// exec the recipe.
+type sandboxSpec struct {
+	Path         string   `json:"path"`          // resolved recipe program
+	Argv         []string `json:"argv"`          // recipe argv, argv[0] included
+	Writable     []string `json:"writable"`      // absolute dirs the recipe may write under
+	AllowNetwork bool     `json:"allow-network"` // skip network denial
+	NetNS        bool     `json:"netns"`         // the child already runs in an empty network namespace
+}
+
+// sandboxViolationHint is appended to a sandboxed recipe's failure so
+// the reader knows a denied write or connection may be the cause.
+const sandboxViolationHint = "the build sandbox allows writes only under the staging dir " +
+	"and denies network access unless build.exec.sandbox.allow-network is set"
+
+// IsSandboxChild reports whether this process was started by the build
+// pass as a sandbox child. The mdsmith binary checks it first thing in
+// main and hands control to SandboxChildMain.
+func IsSandboxChild() bool {
+	_, ok := os.LookupEnv(sandboxSpecEnv)
+	return ok
+}
+
+// SandboxChildMain confines the current process as the spec in the
+// environment asks and execs the recipe in its place. It returns only
+// by exiting: on a setup failure it writes the reason to the status
+// descriptor, where the parent picks it up as the build error, and
+// exits with sandboxExitCode.
+func SandboxChildMain() {
+	status := os.NewFile(sandboxStatusFD, "sandbox-status")
+	err := runSandboxChild(os.Getenv(sandboxSpecEnv))
+	if status != nil {
+		_, _ = io.WriteString(status, err.Error())
+	}
+	os.Exit(sandboxExitCode)
+}
+
+// decodeSandboxSpec parses the JSON spec handed to the sandbox child.
+func decodeSandboxSpec(raw string) (sandboxSpec, error) {
+	var spec sandboxSpec
+	if err := json.Unmarshal([]byte(raw), &spec); err != nil {
+		return spec, fmt.Errorf("decoding sandbox spec: %w", err)
+	}
+	if spec.Path == "" || len(spec.Argv) == 0 {
+		return spec, fmt.Errorf("sandbox spec names no program")
+	}
+	return spec, nil
+}
+
+// childEnv returns env without the sandbox spec variable.
+func childEnv(env []string) []string {
+	out := make([]string, 0, len(env))
+	for _, kv := range env {
+		if !strings.HasPrefix(kv, sandboxSpecEnv+"=") {
+			out = append(out, kv)
+		}
+	}
+	return out
+}
+
+// readSandboxStatus drains the status pipe after the child exits and
+// returns the setup failure it reported, or nil when it reported none.
+func readSandboxStatus(r io.Reader) error {
+	msg, _ := io.ReadAll(r)
+	if len(msg) == 0 {
+		return nil
+	}
+	return fmt.Errorf("build sandbox: %s", strings.TrimSpace(string(msg)))
+}
@@ -0,0 +1,307 @@
+//go:build linux
+
+package build
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"syscall"
+	"unsafe"
+
+	"golang.org/x/sys/unix"
+)
+
+// osExecutableFn is the os.Executable implementation; tests may replace
+// it to point the sandbox child at a different binary.
+var osExecutableFn = os.Executable
+
+// startSandboxChildFn is startSandboxChild; tests replace it to fake a
+// kernel that refuses the user namespace.
+var startSandboxChildFn = startSandboxChild
+
+// errNoNetworkDenial explains why a sandbox without its network
+// namespace refuses to run the recipe.
+const errNoNetworkDenial = "cannot deny network access: user namespaces are unavailable, " +
+	"and Landlock network rules cover only TCP, so UDP and DNS would stay open; " +
+	"set build.exec.sandbox.allow-network to run with network access"
+
+// startSandboxed starts the recipe under the Linux build sandbox.
+// mdsmith re-runs its own binary as the sandbox child, which applies a
+// Landlock ruleset to itself and then execs the recipe, so the recipe
+// and everything it spawns inherit the restrictions:
+//
+//   - the whole file system stays readable and executable;
+//   - writes are allowed only under the staging dir (and /dev/null), so
+//     declared inputs and the project tree are read-only;
+//   - unless o.exec.Sandbox.AllowNetwork, the child starts in a new,
+//     empty user and network namespace. When the kernel refuses the
+//     namespaces the recipe does not run: Landlock alone can deny
+//     only TCP, which is weaker than the sandbox promises. A seccomp
+//     filter also denies Unix sockets, whose socket files the
+//     namespace does not hide.
+//
+// TMPDIR points at a tmp dir inside the staging dir, the one place a
+// recipe can write scratch files.
+func startSandboxed(o runOpts) (*exec.Cmd, func() error, error) {
+	self, err := osExecutableFn()
+	if err != nil {
+		return nil, nil, fmt.Errorf("build sandbox: locating the mdsmith binary: %w", err)
+	}
+	tmp := filepath.Join(o.dir, "tmp")
+	if err := os.Mkdir(tmp, 0o700); err != nil && !errors.Is(err, fs.ErrExist) {
+		return nil, nil, fmt.Errorf("build sandbox: creating TMPDIR: %w", err)
+	}
+	spec := sandboxSpec{
+		Argv:         o.argv,
+		Writable:     []string{o.dir},
+		AllowNetwork: o.exec.Sandbox.AllowNetwork,
+		NetNS:        !o.exec.Sandbox.AllowNetwork,
+	}
+	cmd, status, err := startSandboxChildFn(self, tmp, spec, o)
+	if err != nil && spec.NetNS && namespaceRefused(err) {
+		return nil, nil, fmt.Errorf("build sandbox: %s (%w)", errNoNetworkDenial, err)
+	}
+	if err != nil {
+		return nil, nil, err
+	}
+	return cmd, status, nil
+}
+
+// startSandboxChild starts self as the sandbox child for spec. It
+// returns the started command and a func that reads the child's setup
+// status once it has exited.
+func startSandboxChild(
+	self, tmp string, spec sandboxSpec, o runOpts,
+) (*exec.Cmd, func() error, error) {
+	cmd := recipeCommand(o)
+	if cmd.Err != nil {
+		return nil, nil, fmt.Errorf("starting recipe: %w", cmd.Err)
+	}
+	spec.Path = cmd.Path
+	raw, err := json.Marshal(spec)
+	if err != nil {
+		return nil, nil, fmt.Errorf("build sandbox: %w", err)
+	}
+	r, w, err := os.Pipe()
+	if err != nil {
+		return nil, nil, fmt.Errorf("build sandbox: %w", err)
+	}
+	cmd.Path = self
+	cmd.Args = []string{self}
+	cmd.Env = append(cmd.Env, "TMPDIR="+tmp, sandboxSpecEnv+"="+string(raw))
+	cmd.ExtraFiles = []*os.File{w} // becomes sandboxStatusFD in the child
+	if spec.NetNS {
+		cmd.SysProcAttr.Cloneflags = syscall.CLONE_NEWUSER | syscall.CLONE_NEWNET
+		cmd.SysProcAttr.UidMappings = []syscall.SysProcIDMap{{ContainerID: os.Getuid(), HostID: os.Getuid(), Size: 1}}
+		cmd.SysProcAttr.GidMappings = []syscall.SysProcIDMap{{ContainerID: os.Getgid(), HostID: os.Getgid(), Size: 1}}
+	}
+	err = cmd.Start()
+	_ = w.Close()
+	if err != nil {
+		_ = r.Close()
+		return nil, nil, fmt.Errorf("starting recipe: %w", err)
+	}
+	return cmd, func() error {
+		defer r.Close() //nolint:errcheck // read end of a finished pipe
+		return readSandboxStatus(r)
+	}, nil
+}
+
+// namespaceRefused reports whether a start failed because the kernel
+// does not allow an unprivileged user namespace here.
+func namespaceRefused(err error) bool {
+	return errors.Is(err, syscall.EPERM) || errors.Is(err, syscall.EINVAL) ||
+		errors.Is(err, syscall.ENOSPC) || errors.Is(err, syscall.EACCES)
+}
+
+// runSandboxChild is the body of the sandbox child: it confines the
+// calling thread and execs the recipe from it. It returns only on
+// failure.
+func runSandboxChild(raw string) error {
+	// Landlock and no_new_privs apply to the calling thread; exec from
+	// that same thread carries them into the recipe.
+	runtime.LockOSThread()
+	syscall.CloseOnExec(sandboxStatusFD)
+	spec, err := decodeSandboxSpec(raw)
+	if err != nil {
+		return err
+	}
+	if err := landlockRestrict(spec); err != nil {
+		return err
+	}
+	err = unix.Exec(spec.Path, spec.Argv, childEnv(os.Environ()))
+	return fmt.Errorf("exec %s: %w", spec.Path, err)
+}
+
+// landlockABI returns the kernel's Landlock ABI version, or 0 when
+// Landlock is unsupported or disabled.
+func landlockABI() int {
+	v, _, errno := unix.Syscall(unix.SYS_LANDLOCK_CREATE_RULESET, 0, 0, unix.LANDLOCK_CREATE_RULESET_VERSION)
+	if errno != 0 {
+		return 0
+	}
+	return int(v)
+}
+
+// handledFSAccess returns every file-system right the given Landlock ABI
+// knows. A right the ruleset handles is denied unless a rule grants it.
+func handledFSAccess(abi int) uint64 {
+	access := uint64(unix.LANDLOCK_ACCESS_FS_EXECUTE | unix.LANDLOCK_ACCESS_FS_WRITE_FILE |
+		unix.LANDLOCK_ACCESS_FS_READ_FILE | unix.LANDLOCK_ACCESS_FS_READ_DIR |
+		unix.LANDLOCK_ACCESS_FS_REMOVE_DIR | unix.LANDLOCK_ACCESS_FS_REMOVE_FILE |
+		unix.LANDLOCK_ACCESS_FS_MAKE_CHAR | unix.LANDLOCK_ACCESS_FS_MAKE_DIR |
+		unix.LANDLOCK_ACCESS_FS_MAKE_REG | unix.LANDLOCK_ACCESS_FS_MAKE_SOCK |
+		unix.LANDLOCK_ACCESS_FS_MAKE_FIFO | unix.LANDLOCK_ACCESS_FS_MAKE_BLOCK |
+		unix.LANDLOCK_ACCESS_FS_MAKE_SYM)
+	if abi >= 2 {
+		access |= unix.LANDLOCK_ACCESS_FS_REFER
+	}
+	if abi >= 3 {
+		access |= unix.LANDLOCK_ACCESS_FS_TRUNCATE
+	}
+	if abi >= 5 {
+		access |= unix.LANDLOCK_ACCESS_FS_IOCTL_DEV
+	}
+	return access
+}
+
+// landlockRestrict confines the calling thread as spec asks. It fails
+// rather than run the recipe with a weaker sandbox than requested: the
+// network is denied by the empty network namespace, never by Landlock
+// network rules, which leave UDP open.
+func landlockRestrict(spec sandboxSpec) error {
+	abi := landlockABI()
+	if abi < 1 {
+		return errors.New("landlock is not available on this kernel; " +
+			"the sandbox needs Linux 5.13 or later with Landlock enabled")
+	}
+	if !spec.AllowNetwork && !spec.NetNS {
+		return errors.New(errNoNetworkDenial)
+	}
+	attr := unix.LandlockRulesetAttr{Access_fs: handledFSAccess(abi)}
+	ruleset, _, errno := unix.Syscall(unix.SYS_LANDLOCK_CREATE_RULESET,
+		uintptr(unsafe.Pointer(&attr)), unsafe.Sizeof(attr), 0)
+	if errno != 0 {
+		return fmt.Errorf("creating Landlock ruleset: %w", errno)
+	}
+	defer unix.Close(int(ruleset)) //nolint:errcheck // ruleset fd is not needed after restrict_self
+
+	read := uint64(unix.LANDLOCK_ACCESS_FS_READ_FILE | unix.LANDLOCK_ACCESS_FS_READ_DIR |
+		unix.LANDLOCK_ACCESS_FS_EXECUTE)
+	if err := allowBeneath(ruleset, "/", read); err != nil {
+		return err
+	}
+	for _, dir := range spec.Writable {
+		if err := allowBeneath(ruleset, dir, attr.Access_fs); err != nil {
+			return err
+		}
+	}
+	devNull := uint64(unix.LANDLOCK_ACCESS_FS_READ_FILE | unix.LANDLOCK_ACCESS_FS_WRITE_FILE)
+	if abi >= 3 {
+		devNull |= unix.LANDLOCK_ACCESS_FS_TRUNCATE
+	}
+	if err := allowBeneath(ruleset, "/dev/null", devNull); err != nil {
+		return err
+	}
+
+	if err := unix.Prctl(unix.PR_SET_NO_NEW_PRIVS, 1, 0, 0, 0); err != nil {
+		return fmt.Errorf("setting no_new_privs: %w", err)
+	}
+	if _, _, errno := unix.Syscall(unix.SYS_LANDLOCK_RESTRICT_SELF, ruleset, 0, 0); errno != 0 {
+		return fmt.Errorf("enforcing Landlock ruleset: %w", errno)
+	}
+	if !spec.AllowNetwork {
+		return denyUnixSockets()
+	}
+	return nil
+}
+
+// seccompArch returns the audit arch the Unix-socket filter checks
+// for, or 0 on an architecture it does not cover. The covered ones are
+// little-endian and create sockets only through socket(2), not the
+// socketcall(2) multiplexer, whose arguments seccomp cannot read.
+func seccompArch() uint32 {
+	switch runtime.GOARCH {
+	case "amd64":
+		return unix.AUDIT_ARCH_X86_64
+	case "arm64":
+		return unix.AUDIT_ARCH_AARCH64
+	case "riscv64":
+		return unix.AUDIT_ARCH_RISCV64
+	case "loong64":
+		return unix.AUDIT_ARCH_LOONGARCH64
+	}
+	return 0
+}
+
+// denyUnixSockets installs a seccomp filter that fails socket(AF_UNIX)
+// with EPERM. The network namespace hides abstract Unix sockets but not
+// socket files such as /var/run/docker.sock, and Landlock does not
+// guard connect(2) on them. seccomp cannot read connect's address, so
+// the filter refuses the socket itself. It also refuses io_uring, which
+// could create and connect a socket without a system call, and any
+// system call made through a foreign ABI such as x32 or 32-bit compat.
+// On an architecture seccompArch does not cover, it does nothing.
+func denyUnixSockets() error {
+	arch := seccompArch()
+	if arch == 0 {
+		return nil
+	}
+	const (
+		archOff = 4  // seccomp_data.arch
+		nrOff   = 0  // seccomp_data.nr
+		arg0Off = 16 // low word of seccomp_data.args[0] on little-endian
+		x32Bit  = 0x40000000
+		deny    = unix.SECCOMP_RET_ERRNO | uint32(unix.EPERM)&unix.SECCOMP_RET_DATA
+	)
+	load := func(off uint32) unix.SockFilter {
+		return unix.SockFilter{Code: unix.BPF_LD | unix.BPF_W | unix.BPF_ABS, K: off}
+	}
+	jump := func(op uint16, k uint32, jt, jf uint8) unix.SockFilter {
+		return unix.SockFilter{Code: unix.BPF_JMP | op | unix.BPF_K, K: k, Jt: jt, Jf: jf}
+	}
+	ret := func(k uint32) unix.SockFilter {
+		return unix.SockFilter{Code: unix.BPF_RET | unix.BPF_K, K: k}
+	}
+	filter := []unix.SockFilter{
+		load(archOff),
+		jump(unix.BPF_JEQ, arch, 1, 0),
+		ret(deny),
+		load(nrOff),
+		jump(unix.BPF_JSET, x32Bit, 5, 0),
+		jump(unix.BPF_JEQ, unix.SYS_IO_URING_SETUP, 4, 0),
+		jump(unix.BPF_JEQ, unix.SYS_SOCKET, 0, 2),
+		load(arg0Off),
+		jump(unix.BPF_JEQ, unix.AF_UNIX, 1, 0),
+		ret(unix.SECCOMP_RET_ALLOW),
+		ret(deny),
+	}
+	prog := unix.SockFprog{Len: uint16(len(filter)), Filter: &filter[0]}
+	if err := unix.Prctl(unix.PR_SET_SECCOMP, unix.SECCOMP_MODE_FILTER,
+		uintptr(unsafe.Pointer(&prog)), 0, 0); err != nil {
+		return fmt.Errorf("installing the Unix socket filter: %w", err)
+	}
+	return nil
+}
+
+// allowBeneath grants access to everything under path.
+func allowBeneath(ruleset uintptr, path string, access uint64) error {
+	fd, err := unix.Open(path, unix.O_PATH|unix.O_CLOEXEC, 0)
+	if err != nil {
+		return fmt.Errorf("opening %s: %w", path, err)
+	}
+	defer unix.Close(fd) //nolint:errcheck // O_PATH fd
+	rule := unix.LandlockPathBeneathAttr{Allowed_access: access, Parent_fd: int32(fd)}
+	_, _, errno := unix.Syscall6(unix.SYS_LANDLOCK_ADD_RULE, ruleset,
+		unix.LANDLOCK_RULE_PATH_BENEATH, uintptr(unsafe.Pointer(&rule)), 0, 0, 0)
+	if errno != 0 {
+		return fmt.Errorf("adding Landlock rule for %s: %w", path, errno)
+	}
+	return nil
+}
@@ -0,0 +1,213 @@
+//go:build linux
+
+package build
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"syscall"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestMain lets the test binary stand in for mdsmith as the sandbox
+// child, and doubles as a recipe that dials a TCP or Unix address so
+// the network tests need no external tool.
+func TestMain(m *testing.M) {
+	if IsSandboxChild() {
+		SandboxChildMain()
+	}
+	if len(os.Args) == 4 && os.Args[1] == "-sandbox-test-dial" {
+		conn, err := net.DialTimeout(os.Args[2], os.Args[3], 2*time.Second)
+		if err != nil {
+			_, _ = os.Stderr.WriteString(err.Error() + "\n")
+			os.Exit(1)
+		}
+		_ = conn.Close()
+		os.Exit(0)
+	}
+	os.Exit(m.Run())
+}
+
+// requireLandlock skips the test when the kernel has no Landlock.
+func requireLandlock(t *testing.T) {
+	t.Helper()
+	if landlockABI() < 1 {
+		t.Skip("landlock is not available on this kernel")
+	}
+}
+
+// sandboxRun runs argv sandboxed in a fresh staging dir and returns
+// the staging dir, the captured stderr, and the runRecipe error.
+func sandboxRun(t *testing.T, sb Sandbox, argv ...string) (string, string, error) {
+	t.Helper()
+	dir := t.TempDir()
+	var stderr strings.Builder
+	_, _, err := runRecipe(context.Background(), runOpts{
+		argv:    argv,
+		dir:     dir,
+		exec:    ExecConfig{Sandbox: sb},
+		defExec: defaultExecConfig(),
+		stdout:  &stderr,
+		stderr:  &stderr,
+	})
+	return dir, stderr.String(), err
+}
+
+func TestSandbox_WritesInsideStagingDir(t *testing.T) {
+	requireLandlock(t)
+	dir, out, err := sandboxRun(t, Sandbox{Enabled: true},
+		"/bin/sh", "-c", `printf ok > out0 && printf tmp > "$TMPDIR/scratch" && printf x > /dev/null`)
+	require.NoError(t, err, out)
+	got, err := os.ReadFile(filepath.Join(dir, "out0"))
+	require.NoError(t, err)
+	assert.Equal(t, "ok", string(got))
+	assert.FileExists(t, filepath.Join(dir, "tmp", "scratch"))
+}
+
+func TestSandbox_DeniesWriteOutsideStagingDir(t *testing.T) {
+	requireLandlock(t)
+	outside := filepath.Join(t.TempDir(), "input.txt")
+	require.NoError(t, os.WriteFile(outside, []byte("original"), 0o644))
+
+	_, out, err := sandboxRun(t, Sandbox{Enabled: true},
+		"/bin/sh", "-c", `cat "$1" && printf evil > "$1"`, "sh", outside)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "the build sandbox allows writes only under the staging dir")
+	assert.Contains(t, out, "original", "inputs stay readable")
+	got, rerr := os.ReadFile(outside)
+	require.NoError(t, rerr)
+	assert.Equal(t, "original", string(got), "inputs are read-only")
+}
+
+func TestSandbox_DeniesNetworkByDefault(t *testing.T) {
+	requireLandlock(t)
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	defer ln.Close() //nolint:errcheck // test listener
+	self, err := os.Executable()
+	require.NoError(t, err)
+
+	_, out, err := sandboxRun(t, Sandbox{Enabled: true}, self, "-sandbox-test-dial", "tcp", ln.Addr().String())
+	require.Error(t, err, "a sandboxed recipe must not reach the network")
+	assert.NotEmpty(t, out)
+
+	_, out, err = sandboxRun(t, Sandbox{Enabled: true, AllowNetwork: true},
+		self, "-sandbox-test-dial", "tcp", ln.Addr().String())
+	assert.NoError(t, err, out)
+}
+
+func TestSandbox_DeniesUnixSocketConnect(t *testing.T) {
+	requireLandlock(t)
+	if seccompArch() == 0 {
+		t.Skip("no seccomp filter for Unix sockets on " + runtime.GOARCH)
+	}
+	// A short dir keeps the socket path under the sun_path limit.
+	dir, err := os.MkdirTemp("", "sock")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir) //nolint:errcheck // test dir
+	sock := filepath.Join(dir, "s.sock")
+	ln, err := net.Listen("unix", sock)
+	require.NoError(t, err)
+	defer ln.Close() //nolint:errcheck // test listener
+	self, err := os.Executable()
+	require.NoError(t, err)
+
+	// The empty network namespace does not hide a socket file, so the
+	// sandbox must refuse the connection itself.
+	_, out, err := sandboxRun(t, Sandbox{Enabled: true}, self, "-sandbox-test-dial", "unix", sock)
+	require.Error(t, err, "a sandboxed recipe must not reach a Unix socket")
+	assert.Contains(t, out, "operation not permitted")
+
+	_, out, err = sandboxRun(t, Sandbox{Enabled: true, AllowNetwork: true},
+		self, "-sandbox-test-dial", "unix", sock)
+	assert.NoError(t, err, out)
+}
+
+func TestSandbox_RefusesWithoutNetworkNamespace(t *testing.T) {
+	requireLandlock(t)
+	self, err := os.Executable()
+	require.NoError(t, err)
+	dir := t.TempDir()
+	marker := filepath.Join(dir, "ran")
+
+	// Without its network namespace the child could deny only TCP, so
+	// it refuses before the recipe runs.
+	argv := []string{"/bin/sh", "-c", `printf x > "$1"`, "sh", marker}
+	cmd, status, err := startSandboxChild(self, dir,
+		sandboxSpec{Argv: argv, Writable: []string{dir}},
+		runOpts{argv: argv, dir: dir, defExec: defaultExecConfig(), stderr: io.Discard})
+	require.NoError(t, err)
+	require.Error(t, cmd.Wait())
+	assert.Equal(t, sandboxExitCode, cmd.ProcessState.ExitCode())
+	serr := status()
+	require.Error(t, serr)
+	assert.Contains(t, serr.Error(), "UDP and DNS would stay open")
+	assert.NoFileExists(t, marker)
+}
+
+func TestSandbox_NamespaceRefusedFailsClosed(t *testing.T) {
+	var specs []sandboxSpec
+	orig := startSandboxChildFn
+	startSandboxChildFn = func(_, _ string, spec sandboxSpec, _ runOpts) (*exec.Cmd, func() error, error) {
+		specs = append(specs, spec)
+		return nil, nil, fmt.Errorf("starting recipe: %w", syscall.EPERM)
+	}
+	defer func() { startSandboxChildFn = orig }()
+
+	_, _, err := sandboxRun(t, Sandbox{Enabled: true}, "/bin/true")
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "build sandbox: cannot deny network access")
+	assert.Contains(t, err.Error(), "allow-network")
+	require.Len(t, specs, 1, "no retry without the network namespace")
+	assert.True(t, specs[0].NetNS)
+}
+
+func TestSandbox_SetupFailureIsReported(t *testing.T) {
+	requireLandlock(t)
+	self, err := os.Executable()
+	require.NoError(t, err)
+	dir := t.TempDir()
+
+	cmd, status, err := startSandboxChild(self, dir,
+		sandboxSpec{Argv: []string{"/bin/true"}, AllowNetwork: true, Writable: []string{filepath.Join(dir, "missing")}},
+		runOpts{argv: []string{"/bin/true"}, dir: dir, defExec: defaultExecConfig()})
+	require.NoError(t, err)
+	require.Error(t, cmd.Wait())
+	assert.Equal(t, sandboxExitCode, cmd.ProcessState.ExitCode())
+	serr := status()
+	require.Error(t, serr)
+	assert.Contains(t, serr.Error(), "build sandbox: opening "+filepath.Join(dir, "missing"))
+}
+
+func TestSandbox_BuildWithResultFailsOnViolation(t *testing.T) {
+	requireLandlock(t)
+	root := t.TempDir()
+	sneaky := filepath.Join(root, "sneaky.txt")
+	b := NewCustomBuilderExec(map[string]RecipeSpec{
+		"gen": {Command: "/bin/sh -c {script} sh {outputs}"},
+	}, ExecConfig{Sandbox: Sandbox{Enabled: true}})
+
+	res := b.BuildWithResult(context.Background(), Target{
+		Recipe:  "gen",
+		Params:  map[string]string{"script": `printf ok > "$1"; printf evil > ` + sneaky},
+		Root:    root,
+		Outputs: []string{"out.txt"},
+	}, Options{})
+
+	require.Error(t, res.Err)
+	assert.Contains(t, res.Err.Error(), `recipe "gen" failed`)
+	assert.Contains(t, res.Err.Error(), "build sandbox")
+	assert.NoFileExists(t, sneaky)
+	assert.NoFileExists(t, filepath.Join(root, "out.txt"))
+}
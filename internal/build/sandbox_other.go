@@ -0,0 +1,26 @@
+//go:build !linux
+
+package build
+
+import (
+	"fmt"
+	"os/exec"
+	"runtime"
+)
+
+// errSandboxUnsupported fails a sandboxed build on a platform without a
+// sandbox implementation, rather than run the recipe unconfined.
+var errSandboxUnsupported = fmt.Errorf(
+	"build sandbox: build.exec.sandbox is only supported on Linux, not %s", runtime.GOOS,
+)
+
+// startSandboxed refuses to start the recipe; the sandbox is Linux only.
+func startSandboxed(runOpts) (*exec.Cmd, func() error, error) {
+	return nil, nil, errSandboxUnsupported
+}
+
+// runSandboxChild is never reached off Linux, since startSandboxed
+// starts no child.
+func runSandboxChild(string) error {
+	return errSandboxUnsupported
+}
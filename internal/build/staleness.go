@@ -52,10 +52,15 @@ func (v Verdict) String() string {
 // target: the resolved Target plus the recipe's command string and the
 // recipe's default-inputs already expanded to project-root-relative
 // paths (param tokens resolved to the relative path the param supplies).
+//
+// Upstream lists the ActionIDs of the targets whose outputs this target
+// reads, so a change to an upstream recipe or its inputs makes this
+// target stale too, even before the upstream output is rebuilt.
 type StalenessInput struct {
 	Target        Target
 	Command       string
 	DefaultInputs []string
+	Upstream      []string
 }
 
 // StalenessResult is the verdict plus the resolved relative input set,
@@ -204,6 +209,13 @@ func computeActionIDFromSums(in StalenessInput, inputs, outputs, sums []string)
 
 	frame(h, []byte(canonicalPaths(outputs)))
 
+	// Upstream ActionIDs are folded in only when present, so a target
+	// with no dependencies keeps the ActionID it had before the target
+	// graph existed and an upgrade does not rebuild it.
+	if len(in.Upstream) > 0 {
+		frame(h, []byte(canonicalPaths(in.Upstream)))
+	}
+
 	var verBuf [8]byte
 	binary.BigEndian.PutUint64(verBuf[:], uint64(CacheVersion))
 	frame(h, verBuf[:])
@@ -242,7 +254,7 @@ func ValidateInputs(in StalenessInput) error {
 
 // ComputeActionID computes the sha256 ActionID over the recipe command,
 // canonical params, sorted relative inputs, each input's content hash,
-// sorted relative outputs, and the cache version. Every field is framed
+// sorted relative outputs, the upstream ActionIDs, and the cache version. Every field is framed
 // with an outer 8-byte big-endian length; nested keys, values, and paths
 // are themselves framed. Returns "sha256-<64 lowercase hex>".
 func ComputeActionID(in StalenessInput) (string, error) {
@@ -266,13 +278,15 @@ type ExplainInput struct {
 
 // ActionExplanation is the full ActionID-input breakdown for one target: the
 // recipe command, the canonical params, the resolved inputs with content
-// shas, the resolved outputs, the cache version, and the resulting
-// ActionID. It answers "why is this fresh?" without diving into JSON.
+// shas, the resolved outputs, the upstream ActionIDs, the cache version,
+// and the resulting ActionID. It answers "why is this fresh?" without
+// diving into JSON.
 type ActionExplanation struct {
 	Command      string
 	Params       map[string]string
 	Inputs       []ExplainInput
 	Outputs      []string
+	Upstream     []string
 	CacheVersion int
 	ActionID     string
 }
@@ -303,6 +317,7 @@ func Explain(in StalenessInput) (ActionExplanation, error) {
 		Params:       in.Target.Params,
 		Inputs:       exInputs,
 		Outputs:      outputs,
+		Upstream:     in.Upstream,
 		CacheVersion: CacheVersion,
 		ActionID:     computeActionIDFromSums(in, inputs, outputs, sums),
 	}, nil
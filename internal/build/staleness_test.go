@@ -234,6 +234,45 @@ func TestActionID_LengthFramedNoCollision(t *testing.T) {
 	assert.NotEqual(t, id1, id2)
 }
 
+func TestStaleness_UpstreamActionIDCascades(t *testing.T) {
+	root := t.TempDir()
+	writeFile(t, root, "schema.json", "{}")
+	writeFile(t, root, "ref.md", "# Ref")
+	in := newPlan(t, root, "ref", "gen {inputs}", []string{"schema.json"}, []string{"ref.md"}, nil)
+	in.Upstream = []string{"sha256-one"}
+	cache := NewCache()
+	entry, err := RecordBuild(in)
+	require.NoError(t, err)
+	cache.Put(entry)
+
+	res, err := CheckStaleness(in, cache)
+	require.NoError(t, err)
+	require.Equal(t, Fresh, res.Verdict)
+
+	// The upstream target's ActionID changed: its recipe or inputs
+	// moved, so this target is stale before the schema is rebuilt.
+	in.Upstream = []string{"sha256-two"}
+	res, err = CheckStaleness(in, cache)
+	require.NoError(t, err)
+	assert.Equal(t, Stale, res.Verdict)
+}
+
+func TestActionID_NoUpstreamUnchanged(t *testing.T) {
+	root := t.TempDir()
+	writeFile(t, root, "src.txt", "x")
+	in := newPlan(t, root, "copy", "cp {inputs} {outputs}", []string{"src.txt"}, []string{"dst.txt"}, nil)
+	bare, err := ComputeActionID(in)
+	require.NoError(t, err)
+	in.Upstream = []string{}
+	empty, err := ComputeActionID(in)
+	require.NoError(t, err)
+	assert.Equal(t, bare, empty)
+	in.Upstream = []string{"sha256-up"}
+	up, err := ComputeActionID(in)
+	require.NoError(t, err)
+	assert.NotEqual(t, bare, up)
+}
+
 func TestDetectOutputOverlap_ExactCollision(t *testing.T) {
 	plans := []OverlapTarget{
 		{File: "a.md", Line: 1, Outputs: []string{"out.txt"}},
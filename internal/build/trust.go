@@ -6,6 +6,7 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
+	"strings"
 
 	"github.com/hexops/gotextdiff"
 	"github.com/hexops/gotextdiff/myers"
@@ -113,6 +114,25 @@ func CheckTrust(configPath string, envLookup func(name string) bool) TrustResult
 	return TrustResult{Trusted: true}
 }
 
+// EnvIsSet reports whether the named environment variable is set to a
+// truthy value. It is the production environment lookup passed to
+// CheckTrust, so an explicit MDSMITH_TRUST_BUILD=0 (or false/no/off)
+// does NOT grant trust — only an affirmative value does. This avoids the
+// footgun where a user who sets the variable to a disabling value still
+// has the build pass run.
+func EnvIsSet(name string) bool {
+	v := strings.TrimSpace(os.Getenv(name))
+	if v == "" {
+		return false
+	}
+	switch strings.ToLower(v) {
+	case "0", "false", "no", "off":
+		return false
+	default:
+		return true
+	}
+}
+
 // WriteTrustMarker copies the current config bytes into its trust marker,
 // recording the config at configPath as trusted. The marker is written
 // 0o600: trust is a per-user decision and should not be group- or
@@ -236,3 +236,20 @@ func TestWriteTrustMarker_AtomicWriteError(t *testing.T) {
 	require.Error(t, err)
 	assert.Contains(t, err.Error(), "installing trust marker")
 }
+
+func TestEnvIsSet_Truthiness(t *testing.T) {
+	const name = "MDSMITH_TEST_TRUST_FLAG"
+	truthy := []string{"1", "true", "yes", "on", "anything", " 1 ", "TRUE"}
+	falsy := []string{"", "0", "false", "no", "off", "FALSE", " 0 ", "  "}
+	for _, v := range truthy {
+		t.Setenv(name, v)
+		assert.True(t, EnvIsSet(name), "value %q should grant", v)
+	}
+	for _, v := range falsy {
+		t.Setenv(name, v)
+		assert.False(t, EnvIsSet(name), "value %q should not grant", v)
+	}
+	// Unset is falsy.
+	require.NoError(t, os.Unsetenv(name))
+	assert.False(t, EnvIsSet(name))
+}
@@ -0,0 +1,236 @@
+// Package buildgraph orders <?build?> targets by the files they share.
+// A target that reads another target's output depends on it: it must
+// run after it, and it goes stale when it does. The package is pure so
+// both the build pass and the MDS039 lint rule can use it.
+package buildgraph
+
+import (
+	"path"
+	"slices"
+	"strings"
+
+	"github.com/bmatcuk/doublestar/v4"
+)
+
+// Node is one target's declared paths, project-root-relative and
+// slash-separated. Inputs may be doublestar globs; outputs may not.
+type Node struct {
+	Inputs  []string
+	Outputs []string
+}
+
+// Graph is the dependency graph over a node list. Node indexes refer to
+// the slice passed to New.
+type Graph struct {
+	// Deps lists, per node, the nodes whose outputs it reads, in
+	// ascending index order.
+	Deps [][]int
+	// Dependents lists, per node, the nodes that read its outputs, in
+	// ascending index order.
+	Dependents [][]int
+}
+
+// New builds the graph: node i depends on node j when one of i's
+// inputs names one of j's outputs. A node that reads its own output
+// depends on itself, which Cycles reports.
+func New(nodes []Node) Graph {
+	g := Graph{
+		Deps:       make([][]int, len(nodes)),
+		Dependents: make([][]int, len(nodes)),
+	}
+	for i, n := range nodes {
+		for j, up := range nodes {
+			if readsAny(n.Inputs, up.Outputs) {
+				g.Deps[i] = append(g.Deps[i], j)
+				g.Dependents[j] = append(g.Dependents[j], i)
+			}
+		}
+	}
+	return g
+}
+
+// readsAny reports whether any input entry names any output.
+func readsAny(inputs, outputs []string) bool {
+	for _, in := range inputs {
+		for _, out := range outputs {
+			if Reads(in, out) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// Reads reports whether the input entry in names the output out: the
+// same path, a path under an output directory, or a glob that matches
+// the output or a file under it named like the glob's last segment.
+func Reads(in, out string) bool {
+	in, out = clean(in), clean(out)
+	if !strings.ContainsAny(in, "*?[{") {
+		return in == out || strings.HasPrefix(in, out+"/")
+	}
+	if ok, _ := doublestar.Match(in, out); ok {
+		return true
+	}
+	ok, _ := doublestar.Match(in, out+"/"+path.Base(in))
+	return ok
+}
+
+// clean normalizes a declared path for comparison.
+func clean(p string) string {
+	p = strings.TrimRight(p, "/")
+	if p == "" {
+		return "."
+	}
+	return path.Clean(p)
+}
+
+// Order returns the nodes in dependency order: every node after the
+// nodes it depends on, ties broken by index. Nodes on a cycle, and
+// nodes downstream of one, are left out.
+func (g Graph) Order() []int {
+	remaining := make([]int, len(g.Deps))
+	var ready []int
+	for i, deps := range g.Deps {
+		remaining[i] = len(deps)
+		if remaining[i] == 0 {
+			ready = append(ready, i)
+		}
+	}
+	order := make([]int, 0, len(g.Deps))
+	for len(ready) > 0 {
+		i := ready[0]
+		ready = ready[1:]
+		order = append(order, i)
+		for _, d := range g.Dependents[i] {
+			remaining[d]--
+			if remaining[d] == 0 {
+				ready = insertSorted(ready, d)
+			}
+		}
+	}
+	return order
+}
+
+// insertSorted inserts v into the ascending slice s.
+func insertSorted(s []int, v int) []int {
+	k, _ := slices.BinarySearch(s, v)
+	return slices.Insert(s, k, v)
+}
+
+// Cycles returns one cycle per group of mutually dependent nodes, as
+// the path data takes through it: each node reads the previous node's
+// output, and the last node's output feeds the first. A cycle starts
+// at its lowest index; cycles are ordered by that index.
+func (g Graph) Cycles() [][]int {
+	var cycles [][]int
+	for _, scc := range g.components() {
+		if len(scc) == 1 && !slices.Contains(g.Deps[scc[0]], scc[0]) {
+			continue
+		}
+		cycles = append(cycles, g.cyclePath(scc))
+	}
+	slices.SortFunc(cycles, func(a, b []int) int { return a[0] - b[0] })
+	return cycles
+}
+
+// cyclePath returns the shortest cycle through the lowest node of a
+// strongly connected component, following data from producer to
+// consumer.
+func (g Graph) cyclePath(scc []int) []int {
+	start := slices.Min(scc)
+	prev := map[int]int{}
+	queue := []int{start}
+	for len(queue) > 0 {
+		i := queue[0]
+		queue = queue[1:]
+		for _, d := range g.Dependents[i] {
+			if d == start {
+				path := []int{i}
+				for path[0] != start {
+					path = slices.Insert(path, 0, prev[path[0]])
+				}
+				return path
+			}
+			if _, seen := prev[d]; seen || !slices.Contains(scc, d) {
+				continue
+			}
+			prev[d] = i
+			queue = append(queue, d)
+		}
+	}
+	return []int{start}
+}
+
+// components returns the strongly connected components of the graph
+// (Tarjan's algorithm), each sorted ascending.
+func (g Graph) components() [][]int {
+	n := len(g.Deps)
+	index := make([]int, n)
+	low := make([]int, n)
+	onStack := make([]bool, n)
+	for i := range index {
+		index[i] = -1
+	}
+	var stack []int
+	var out [][]int
+	next := 0
+	var visit func(v int)
+	visit = func(v int) {
+		index[v], low[v] = next, next
+		next++
+		stack = append(stack, v)
+		onStack[v] = true
+		for _, w := range g.Dependents[v] {
+			switch {
+			case index[w] < 0:
+				visit(w)
+				low[v] = min(low[v], low[w])
+			case onStack[w]:
+				low[v] = min(low[v], index[w])
+			}
+		}
+		if low[v] != index[v] {
+			return
+		}
+		var scc []int
+		for {
+			w := stack[len(stack)-1]
+			stack = stack[:len(stack)-1]
+			onStack[w] = false
+			scc = append(scc, w)
+			if w == v {
+				break
+			}
+		}
+		slices.Sort(scc)
+		out = append(out, scc)
+	}
+	for v := range n {
+		if index[v] < 0 {
+			visit(v)
+		}
+	}
+	return out
+}
+
+// ResolveDefaultInputs maps each recipe default-inputs entry to a
+// relative path. A {param} token resolves to the param's value (the
+// relative path it supplies); a literal entry passes through unchanged.
+func ResolveDefaultInputs(entries []string, params map[string]string) []string {
+	if len(entries) == 0 {
+		return nil
+	}
+	out := make([]string, 0, len(entries))
+	for _, e := range entries {
+		if len(e) > 2 && e[0] == '{' && e[len(e)-1] == '}' {
+			name := e[1 : len(e)-1]
+			if v, ok := params[name]; ok {
+				out = append(out, v)
+				continue
+			}
+		}
+		out = append(out, e)
+	}
+	return out
+}
@@ -0,0 +1,63 @@
+package buildgraph
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestReads(t *testing.T) {
+	tests := []struct {
+		in, out string
+		want    bool
+	}{
+		{"gen/schema.json", "gen/schema.json", true},
+		{"./gen/schema.json", "gen/schema.json", true},
+		{"book/index.html", "book/", true},
+		{"book/index.html", "book", true},
+		{"bookmark.md", "book", false},
+		{"gen/*.json", "gen/schema.json", true},
+		{"gen/**/*.md", "gen/ref", true},
+		{"gen/*.yaml", "gen/schema.json", false},
+		{"docs/*.md", "gen/ref.md", false},
+		{"gen", "gen/schema.json", false},
+		{"site/*/index.html", "site/en", true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.in+" "+tt.out, func(t *testing.T) {
+			assert.Equal(t, tt.want, Reads(tt.in, tt.out))
+		})
+	}
+}
+
+func TestOrder_RunsProducersFirst(t *testing.T) {
+	g := New([]Node{
+		{Inputs: []string{"gen/schema.json"}, Outputs: []string{"docs/ref.md"}},
+		{Inputs: []string{"api.yaml"}, Outputs: []string{"gen/schema.json"}},
+		{Outputs: []string{"logo.svg"}},
+		{Inputs: []string{"docs/ref.md", "logo.svg"}, Outputs: []string{"site/"}},
+	})
+	assert.Equal(t, [][]int{{1}, nil, nil, {0, 2}}, g.Deps)
+	assert.Equal(t, []int{1, 0, 2, 3}, g.Order())
+	assert.Empty(t, g.Cycles())
+}
+
+func TestCycles(t *testing.T) {
+	g := New([]Node{
+		{Inputs: []string{"c.txt"}, Outputs: []string{"a.txt"}},
+		{Inputs: []string{"a.txt"}, Outputs: []string{"b.txt"}},
+		{Inputs: []string{"b.txt"}, Outputs: []string{"c.txt"}},
+		{Inputs: []string{"c.txt"}, Outputs: []string{"d.txt"}},
+		{Inputs: []string{"self/*.md"}, Outputs: []string{"self/out.md"}},
+	})
+	assert.Equal(t, [][]int{{0, 1, 2}, {4}}, g.Cycles())
+	assert.Empty(t, g.Order(), "cycle members and their dependents are not scheduled")
+}
+
+func TestResolveDefaultInputs(t *testing.T) {
+	assert.Equal(t, []string{"demo.tape"}, ResolveDefaultInputs([]string{"{tape}"}, map[string]string{"tape": "demo.tape"}))
+	assert.Equal(t, []string{"{unknown}"}, ResolveDefaultInputs([]string{"{unknown}"}, map[string]string{}))
+	assert.Equal(t, []string{"assets/logo.svg"}, ResolveDefaultInputs([]string{"assets/logo.svg"}, nil))
+	assert.Nil(t, ResolveDefaultInputs(nil, nil))
+	assert.Nil(t, ResolveDefaultInputs([]string{}, nil))
+}
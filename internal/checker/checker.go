@@ -9,6 +9,7 @@ import (
 	"runtime/debug"
 	"sync"
 
+	"github.com/jeduden/mdsmith/internal/archetype/gensection"
 	"github.com/jeduden/mdsmith/internal/config"
 	"github.com/jeduden/mdsmith/internal/lint"
 	"github.com/jeduden/mdsmith/internal/rule"
@@ -58,6 +59,16 @@ func ConfigureEnabledRules(
 	return configured, errs
 }
 
+// GeneratedDirectives returns the names of the directives the
+// DirectiveSet rules among rules serve under effective whose bodies
+// are generated, for lint.File.GeneratedDirectives. A disabled rule
+// still counts: its sections hold generated text either way.
+func GeneratedDirectives(rules []rule.Rule, effective map[string]config.RuleCfg) []string {
+	return gensection.GeneratedNames(rules, func(name string) map[string]any {
+		return effective[name].Settings
+	})
+}
+
 // ConfigureRule clones a rule and applies settings from cfg if the rule
 // implements Configurable and cfg has settings. Returns the configured
 // rule (or the original if no settings apply) and any error from
@@ -15,6 +15,18 @@ type BuildConfig struct {
 	// under (plan 2606101548). Both keys are optional; empty values mean
 	// the build executor's compiled defaults apply.
 	Exec ExecCfg `yaml:"exec,omitempty"`
+	// Cache names a shared build cache (plan 2610183400). Empty means
+	// only the local .mdsmith/build-cache.json is used.
+	Cache CacheCfg `yaml:"cache,omitempty"`
+}
+
+// CacheCfg is the build.cache: section. URL is an http:// or https://
+// base URL, a file:// URL, or a directory path (relative paths resolve
+// against the project root). ReadOnly restores from the cache but never
+// uploads to it.
+type CacheCfg struct {
+	URL      string `yaml:"url,omitempty"`
+	ReadOnly bool   `yaml:"read-only,omitempty"`
 }
 
 // HooksCfg holds the before/after hook lists for the build pass.
@@ -36,8 +48,17 @@ type HookCfg struct {
 // (PATH = /usr/bin:/bin on Unix; pass-through = [HOME, LANG, LC_ALL]).
 // EnvPassThrough *replaces* the default list rather than appending to it.
 type ExecCfg struct {
-	Path           string   `yaml:"path,omitempty"`
-	EnvPassThrough []string `yaml:"env-pass-through,omitempty"`
+	Path           string     `yaml:"path,omitempty"`
+	EnvPassThrough []string   `yaml:"env-pass-through,omitempty"`
+	Sandbox        SandboxCfg `yaml:"sandbox,omitempty"`
+}
+
+// SandboxCfg is the build.exec.sandbox: section. Enabled confines every
+// recipe on Linux: writes only under its staging dir, no network unless
+// AllowNetwork. The sandbox is opt-in; both keys default to false.
+type SandboxCfg struct {
+	Enabled      bool `yaml:"enabled,omitempty"`
+	AllowNetwork bool `yaml:"allow-network,omitempty"`
 }
 
 // RecipeCfg is a single user-defined recipe declaration.
@@ -104,7 +125,24 @@ func ValidateBuildConfig(cfg *Config) error {
 			return err
 		}
 	}
-	return validateExecConfig(cfg.Build.Exec)
+	if err := validateExecConfig(cfg.Build.Exec); err != nil {
+		return err
+	}
+	return validateCacheConfig(cfg.Build.Cache)
+}
+
+// validateCacheConfig rejects a build.cache.url with a scheme other than
+// http, https, or file.
+func validateCacheConfig(c CacheCfg) error {
+	scheme, _, ok := strings.Cut(c.URL, "://")
+	if !ok {
+		return nil
+	}
+	switch scheme {
+	case "http", "https", "file":
+		return nil
+	}
+	return fmt.Errorf("build.cache.url: unsupported scheme %q (want http, https, or file)", scheme)
 }
 
 // validateHook validates a single hook entry. listName is "before" or "after".
@@ -355,7 +393,8 @@ func validateCommandPlaceholders(recipeName, command string, allowed map[string]
 }
 
 // InjectBuildConfig copies cfg.Build.Recipes and cfg.Build.Hooks into the
-// recipe-safety and build rule settings. It is called after config loading in
+// recipe-safety and build rule settings, and cfgPath into history and
+// metric-regression. It is called after config loading in
 // main so rules receive their inputs through the normal ApplySettings path.
 // cfgPath is the path to the loaded .mdsmith.yml; it is set in the config-path
 // setting so MDS040 can report diagnostics against the right file.
@@ -379,6 +418,28 @@ func InjectBuildConfig(cfg *Config, cfgPath string) {
 		cfg.Rules["recipe-safety"] = rc
 	}
 
+	// Inject into history (MDS080): its git log runs under the
+	// build trust gate, which pins the loaded config. The path is
+	// always overwritten so a rule setting cannot point the gate at
+	// another file.
+	if rc, ok := cfg.Rules["history"]; ok && rc.Enabled {
+		if rc.Settings == nil {
+			rc.Settings = make(map[string]any)
+		}
+		rc.Settings["config-path"] = cfgPath
+		cfg.Rules["history"] = rc
+	}
+
+	// Inject into metric-regression (MDS075): its `ref` baseline
+	// runs git show under the same gate.
+	if rc, ok := cfg.Rules["metric-regression"]; ok && rc.Enabled {
+		if rc.Settings == nil {
+			rc.Settings = make(map[string]any)
+		}
+		rc.Settings["config-path"] = cfgPath
+		cfg.Rules["metric-regression"] = rc
+	}
+
 	// Inject into build directive (MDS039).
 	if rc, ok := cfg.Rules["build"]; ok && rc.Enabled {
 		if rc.Settings == nil {
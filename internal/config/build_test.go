@@ -73,6 +73,36 @@ func TestLoad_ExecConfigParses(t *testing.T) {
 	assert.Equal(t, []string{"HOME", "LANG", "SOURCE_DATE_EPOCH"}, cfg.Build.Exec.EnvPassThrough)
 }
 
+func TestLoad_ExecSandboxParsesAndMerges(t *testing.T) {
+	yml := []byte("build:\n  exec:\n    sandbox:\n      enabled: true\n      allow-network: true\n")
+	cfg, err := loadFromBytes(yml, "", false)
+	require.NoError(t, err)
+	assert.Equal(t, SandboxCfg{Enabled: true, AllowNetwork: true}, cfg.Build.Exec.Sandbox)
+
+	merged := Merge(Defaults(), cfg)
+	assert.Equal(t, SandboxCfg{Enabled: true, AllowNetwork: true}, merged.Build.Exec.Sandbox)
+}
+
+func TestLoad_CacheConfigParsesAndMerges(t *testing.T) {
+	yml := []byte("build:\n  cache:\n    url: https://cache.example.com/mdsmith\n    read-only: true\n")
+	cfg, err := loadFromBytes(yml, "", false)
+	require.NoError(t, err)
+	want := CacheCfg{URL: "https://cache.example.com/mdsmith", ReadOnly: true}
+	assert.Equal(t, want, cfg.Build.Cache)
+	assert.Equal(t, want, Merge(Defaults(), cfg).Build.Cache)
+}
+
+func TestValidateBuildConfig_CacheURLScheme(t *testing.T) {
+	for _, u := range []string{"", "/srv/cache", ".cache/shared", "file:///srv/cache", "https://c.example"} {
+		cfg := &Config{Build: BuildConfig{Cache: CacheCfg{URL: u}}}
+		assert.NoError(t, ValidateBuildConfig(cfg), u)
+	}
+	cfg := &Config{Build: BuildConfig{Cache: CacheCfg{URL: "s3://bucket/cache"}}}
+	err := ValidateBuildConfig(cfg)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), `build.cache.url: unsupported scheme "s3"`)
+}
+
 func TestValidateBuildConfig_ExecEmptyPassThroughName(t *testing.T) {
 	cfg := &Config{
 		Build: BuildConfig{
@@ -450,6 +480,31 @@ func TestInjectBuildConfig_EmptyRecipes_ClearsExistingSettings(t *testing.T) {
 	assert.Empty(t, recipes, "recipes must be cleared when build.recipes is empty")
 }
 
+func TestInjectBuildConfig_HistoryConfigPath(t *testing.T) {
+	cfg := &Config{
+		Rules: map[string]RuleCfg{
+			"history": {Enabled: true, Settings: map[string]any{"config-path": "elsewhere.yml"}},
+		},
+	}
+	InjectBuildConfig(cfg, "/w/.mdsmith.yml")
+	assert.Equal(t, "/w/.mdsmith.yml", cfg.Rules["history"].Settings["config-path"])
+
+	InjectBuildConfig(cfg, "")
+	assert.Equal(t, "", cfg.Rules["history"].Settings["config-path"],
+		"a defaults-only run must not keep a user-supplied config-path")
+}
+
+func TestInjectBuildConfig_MetricRegressionConfigPath(t *testing.T) {
+	cfg := &Config{
+		Rules: map[string]RuleCfg{
+			"metric-regression": {Enabled: true, Settings: map[string]any{"ref": "HEAD"}},
+		},
+	}
+	InjectBuildConfig(cfg, "/w/.mdsmith.yml")
+	assert.Equal(t, "/w/.mdsmith.yml", cfg.Rules["metric-regression"].Settings["config-path"])
+	assert.Equal(t, "HEAD", cfg.Rules["metric-regression"].Settings["ref"])
+}
+
 func TestInjectBuildConfig_NoRecipes(t *testing.T) {
 	cfg := &Config{
 		Rules: map[string]RuleCfg{
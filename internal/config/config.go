@@ -68,6 +68,27 @@ type UserWordlist struct {
 	SourcePath string `yaml:"-"`
 }
 
+// UserDirective is a user-defined generated-section directive declared
+// either inline under the top-level `directives:` block in .mdsmith.yml
+// or in a file under `.mdsmith/directives/<name>.yaml`. It takes the
+// parameters Params declares and expands to the built-in directive
+// Expand, with With as that directive's parameters; a {param}
+// placeholder in a With value is replaced by the directive's value.
+type UserDirective struct {
+	// Description says what the directive renders.
+	Description string `yaml:"description,omitempty"`
+	// Params names the parameters the directive accepts.
+	Params ParamCfg `yaml:"params,omitempty"`
+	// Expand names the built-in directive that renders the body.
+	Expand string `yaml:"expand"`
+	// With holds the built-in directive's parameters: each value is a
+	// string or a list of strings.
+	With map[string]any `yaml:"with,omitempty"`
+	// SourcePath is the workspace-absolute path of the file that
+	// defined this directive. Not serialized.
+	SourcePath string `yaml:"-"`
+}
+
 // Config is the top-level configuration.
 type Config struct {
 	Rules          map[string]RuleCfg    `yaml:"rules"`
@@ -113,6 +134,13 @@ type Config struct {
 	// `.mdsmith/wordlists/` file basename.
 	Wordlists map[string]UserWordlist `yaml:"wordlists,omitempty"`
 
+	// Directives holds user-defined directives declared under the
+	// top-level `directives:` key — the inline equivalent of a
+	// `.mdsmith/directives/<name>.yaml` file. The user-directive rule
+	// (MDS081) checks and fixes their sections. Names must not collide
+	// with a `.mdsmith/directives/` file basename or a rule name.
+	Directives map[string]UserDirective `yaml:"directives,omitempty"`
+
 	// Schemas holds named document-structure schemas declared inline
 	// under the top-level `schemas:` key — the inline equivalent of a
 	// `.mdsmith/schemas/<name>.yaml` file (plan 241). Each entry is a
@@ -0,0 +1,276 @@
+package config
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"sort"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+
+	"github.com/jeduden/mdsmith/internal/rule"
+	"github.com/jeduden/mdsmith/internal/wordlist"
+	"github.com/jeduden/mdsmith/internal/yamlutil"
+)
+
+// directiveFilesDir is the directory under the workspace root that
+// holds one YAML file per user-defined directive. The basename (minus
+// extension) is the directive name. Both `*.yaml` and `*.yml` are
+// scanned; subdirectories are rejected — one directive per file.
+const directiveFilesDir = ".mdsmith/directives"
+
+// directiveRule is the rule that runs user-defined directives.
+const directiveRule = "user-directive"
+
+// directiveNameRE is the name pattern a user-defined directive must
+// match, inline or from a file: the same lowercase identifier the
+// other `.mdsmith/` resources use, which is also a valid marker name.
+var directiveNameRE = regexp.MustCompile(`^[a-z][a-z0-9-]*$`)
+
+// reservedDirectiveNames are markerless processing instructions that
+// are not rules but still mean something to mdsmith.
+var reservedDirectiveNames = []string{"allow-empty-section", "require"}
+
+// discoveredDirective pairs a parsed UserDirective with the absolute
+// path of the file it came from.
+type discoveredDirective struct {
+	body       UserDirective
+	sourcePath string
+}
+
+// discoverDirectives walks `.mdsmith/directives/*.{yaml,yml}` at the
+// workspace root and returns one entry per discovered directive, keyed
+// by basename. Mirrors discoverWordlists: it rejects symlinks,
+// subdirectories, a bad basename, and a `.yaml`/`.yml` collision. A
+// missing directory returns an empty map and no error.
+func discoverDirectives(workspaceDir string) (map[string]discoveredDirective, error) {
+	root := filepath.Join(workspaceDir, directiveFilesDir)
+	entries, err := os.ReadDir(root)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("reading %s: %w", directiveFilesDir, err)
+	}
+
+	sort.Slice(entries, func(i, j int) bool {
+		return entries[i].Name() < entries[j].Name()
+	})
+
+	result := make(map[string]discoveredDirective, len(entries))
+	seenExt := make(map[string]string, len(entries))
+
+	for _, entry := range entries {
+		name := entry.Name()
+		if entry.Type()&os.ModeSymlink != 0 {
+			return nil, fmt.Errorf(
+				"%s: symlinks are not allowed (found %q)", directiveFilesDir, name)
+		}
+		if entry.IsDir() {
+			return nil, fmt.Errorf(
+				"%s: subdirectories are not allowed (found %q)", directiveFilesDir, name)
+		}
+		ext := filepath.Ext(name)
+		switch strings.ToLower(ext) {
+		case ".yaml", ".yml":
+		default:
+			continue
+		}
+		base := name[:len(name)-len(ext)]
+		if !directiveNameRE.MatchString(base) {
+			return nil, fmt.Errorf(
+				"%s/%s: basename %q must match %s",
+				directiveFilesDir, name, base, directiveNameRE.String())
+		}
+		if prior, ok := seenExt[base]; ok {
+			return nil, fmt.Errorf(
+				"%s: directive %q is declared by both %s and %s; keep one",
+				directiveFilesDir, base, prior, name)
+		}
+		seenExt[base] = name
+
+		path := filepath.Join(root, name)
+		body, err := parseDirectiveFile(path)
+		if err != nil {
+			return nil, err
+		}
+		body.SourcePath = path
+		result[base] = discoveredDirective{body: body, sourcePath: path}
+	}
+	return result, nil
+}
+
+// parseDirectiveFile reads one directive file and decodes it into a
+// UserDirective with strict (KnownFields) decoding, as parseKindFile
+// does, so a misspelt key is a config error.
+func parseDirectiveFile(path string) (UserDirective, error) {
+	data, err := readLimitedConfig(path)
+	if err != nil {
+		return UserDirective{}, fmt.Errorf("reading %s: %w", path, err)
+	}
+	if err := yamlutil.RejectYAMLAliases(data); err != nil {
+		return UserDirective{}, fmt.Errorf("parsing %s: %w", path, err)
+	}
+	var body UserDirective
+	dec := yaml.NewDecoder(bytes.NewReader(data))
+	dec.KnownFields(true)
+	if err := dec.Decode(&body); err != nil {
+		if errors.Is(err, io.EOF) {
+			return UserDirective{}, fmt.Errorf("%s: empty directive file", path)
+		}
+		return UserDirective{}, fmt.Errorf("parsing %s: %w", path, err)
+	}
+	return body, nil
+}
+
+// mergeDirectiveFiles tags every inline directive with cfgPath for
+// provenance, then discovers file-defined directives under the
+// workspace root and merges them into cfg.Directives. A name declared
+// both inline and in a file is a config error naming both sources.
+func mergeDirectiveFiles(cfg *Config, cfgPath string) error {
+	for name, ud := range cfg.Directives {
+		ud.SourcePath = cfgPath
+		cfg.Directives[name] = ud
+	}
+
+	discovered, err := discoverDirectives(filepath.Dir(cfgPath))
+	if err != nil {
+		return err
+	}
+	if len(discovered) == 0 {
+		return nil
+	}
+
+	if cfg.Directives == nil {
+		cfg.Directives = make(map[string]UserDirective, len(discovered))
+	}
+	names := make([]string, 0, len(discovered))
+	for name := range discovered {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		dd := discovered[name]
+		if existing, clash := cfg.Directives[name]; clash {
+			return fmt.Errorf(
+				"directive %q is declared both inline in %s and in %s; keep one source",
+				name, existing.SourcePath, dd.sourcePath)
+		}
+		cfg.Directives[name] = dd.body
+	}
+	return nil
+}
+
+// validateDirectives rejects a directive whose name is not a marker
+// identifier or is taken by a rule or a reserved instruction, then
+// hands the definitions to the user-directive rule, which checks
+// their shape: the expanded directive, the parameter lists, and the
+// `with` values. A binary without that rule skips the second step.
+func validateDirectives(cfg *Config) error {
+	names := make([]string, 0, len(cfg.Directives))
+	for name := range cfg.Directives {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		if !directiveNameRE.MatchString(name) {
+			return fmt.Errorf("directive %q: name must match %s", name, directiveNameRE.String())
+		}
+		if rule.ByName(name) != nil || slices.Contains(reservedDirectiveNames, name) {
+			return fmt.Errorf("directive %q: name is reserved for a built-in directive or rule", name)
+		}
+	}
+	if len(names) == 0 {
+		return nil
+	}
+	r := rule.ByName(directiveRule)
+	if _, ok := r.(rule.Configurable); !ok {
+		return nil
+	}
+	clone := rule.CloneRule(r).(rule.Configurable)
+	return clone.ApplySettings(map[string]any{"directives": serializeDirectives(cfg.Directives)})
+}
+
+// serializeDirectives converts the definitions to the settings shape
+// the user-directive rule reads.
+func serializeDirectives(m map[string]UserDirective) map[string]any {
+	out := make(map[string]any, len(m))
+	for name, ud := range m {
+		def := map[string]any{
+			"expand": ud.Expand,
+			"params": map[string]any{
+				"required": wordlist.ToAnySlice(ud.Params.Required),
+				"optional": wordlist.ToAnySlice(ud.Params.Optional),
+			},
+		}
+		if ud.Description != "" {
+			def["description"] = ud.Description
+		}
+		if len(ud.With) > 0 {
+			with := make(map[string]any, len(ud.With))
+			for k, v := range ud.With {
+				with[k] = v
+			}
+			def["with"] = with
+		}
+		out[name] = def
+	}
+	return out
+}
+
+// injectDirectives adds the config's user-defined directives to the
+// user-directive rule's `directives` setting. Definitions set on the
+// rule itself are kept unless a config definition has the same name.
+// It also hands the rule the file's effective settings for each
+// directive a definition expands to, so an expansion renders as the
+// built-in directive would in the same file.
+func injectDirectives(result map[string]RuleCfg, defs map[string]UserDirective) {
+	rc, ok := result[directiveRule]
+	if !ok {
+		return
+	}
+	merged := serializeDirectives(defs)
+	if existing, ok := rc.Settings["directives"].(map[string]any); ok {
+		for name, def := range existing {
+			if _, clash := merged[name]; !clash {
+				merged[name] = def
+			}
+		}
+	}
+	if len(merged) == 0 {
+		return
+	}
+	settings := make(map[string]any, len(rc.Settings)+2)
+	for k, v := range rc.Settings {
+		settings[k] = v
+	}
+	settings["directives"] = merged
+	settings["base-rules"] = baseRules(result, merged)
+	rc.Settings = settings
+	result[directiveRule] = rc
+}
+
+// baseRules returns, keyed by rule name, the enabled state and
+// settings of every configured rule the definitions expand to.
+func baseRules(result map[string]RuleCfg, defs map[string]any) map[string]any {
+	out := make(map[string]any)
+	for _, def := range defs {
+		m, _ := def.(map[string]any)
+		name, _ := m["expand"].(string)
+		if name == "" {
+			continue
+		}
+		rc, ok := result[name]
+		if !ok {
+			continue
+		}
+		out[name] = map[string]any{"enabled": rc.Enabled, "settings": rc.Settings}
+	}
+	return out
+}
@@ -0,0 +1,148 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	_ "github.com/jeduden/mdsmith/internal/rules/catalog"
+	_ "github.com/jeduden/mdsmith/internal/rules/userdirective"
+)
+
+// writeDirectiveFile creates .mdsmith/directives/<name>.yaml under dir.
+func writeDirectiveFile(t *testing.T, dir, name, body string) {
+	t.Helper()
+	dDir := filepath.Join(dir, ".mdsmith", "directives")
+	require.NoError(t, os.MkdirAll(dDir, 0o700))
+	require.NoError(t, os.WriteFile(filepath.Join(dDir, name+".yaml"), []byte(body), 0o600))
+}
+
+// loadDirectiveConfig writes cfgBody as .mdsmith.yml in dir and loads it.
+func loadDirectiveConfig(t *testing.T, dir, cfgBody string) (*Config, error) {
+	t.Helper()
+	path := filepath.Join(dir, ".mdsmith.yml")
+	require.NoError(t, os.WriteFile(path, []byte(cfgBody), 0o600))
+	return Load(path)
+}
+
+const rosterFile = `description: Team members.
+params:
+  required: [team]
+  optional: [role]
+expand: catalog
+with:
+  glob: "{team}/*.md"
+  where: 'role: "{role}"'
+  row: "- [{name}]({filename})"
+`
+
+func TestLoad_DirectiveFileAndInline(t *testing.T) {
+	dir := t.TempDir()
+	writeDirectiveFile(t, dir, "roster", rosterFile)
+	cfg, err := loadDirectiveConfig(t, dir,
+		"directives:\n  endpoints:\n    expand: catalog\n    with:\n      glob: [api/*.md, rpc/*.md]\n")
+	require.NoError(t, err)
+
+	require.Contains(t, cfg.Directives, "roster")
+	roster := cfg.Directives["roster"]
+	assert.Equal(t, "catalog", roster.Expand)
+	assert.Equal(t, []string{"team"}, roster.Params.Required)
+	assert.Equal(t, filepath.Join(dir, ".mdsmith", "directives", "roster.yaml"), roster.SourcePath)
+	assert.Equal(t, filepath.Join(dir, ".mdsmith.yml"), cfg.Directives["endpoints"].SourcePath)
+}
+
+func TestLoad_DirectiveErrors(t *testing.T) {
+	tests := []struct {
+		name string
+		file string // body of .mdsmith/directives/pages.yaml, if any
+		cfg  string
+		msg  string
+	}{
+		{"inline and file", "expand: tree\n", "directives:\n  pages:\n    expand: tree\n", "declared both inline"},
+		{"unknown file key", "expand: tree\ntemplate: x\n", "", "field template not found"},
+		{"empty file", "# nothing\n", "", "empty directive file"},
+		{"rule name", "", "directives:\n  catalog:\n    expand: tree\n", "reserved"},
+		{"markerless name", "", "directives:\n  require:\n    expand: tree\n", "reserved"},
+		{"bad inline name", "", "directives:\n  My_Pages:\n    expand: tree\n", "name must match"},
+		{"build", "expand: build\n", "", `expand "build" must be one of`},
+		{"overlap", "expand: tree\nparams:\n  required: [a]\n  optional: [a]\n", "", "both required and optional"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := t.TempDir()
+			if tt.file != "" {
+				writeDirectiveFile(t, dir, "pages", tt.file)
+			}
+			_, err := loadDirectiveConfig(t, dir, tt.cfg)
+			require.Error(t, err)
+			assert.Contains(t, err.Error(), tt.msg)
+		})
+	}
+}
+
+func TestDiscoverDirectives_RejectsBadBasenameAndSubdir(t *testing.T) {
+	dir := t.TempDir()
+	writeDirectiveFile(t, dir, "Team_Roster", "expand: tree\n")
+	_, err := discoverDirectives(dir)
+	assert.ErrorContains(t, err, "must match")
+
+	dir = t.TempDir()
+	require.NoError(t, os.MkdirAll(filepath.Join(dir, ".mdsmith", "directives", "nested"), 0o700))
+	_, err = discoverDirectives(dir)
+	assert.ErrorContains(t, err, "subdirectories are not allowed")
+}
+
+func TestEffective_InjectsDirectives(t *testing.T) {
+	dir := t.TempDir()
+	writeDirectiveFile(t, dir, "roster", rosterFile)
+	cfg, err := loadDirectiveConfig(t, dir, "rules:\n  user-directive:\n    directives:\n"+
+		"      legacy:\n        expand: tree\n      roster:\n        expand: tree\n")
+	require.NoError(t, err)
+
+	eff := Effective(cfg, "README.md", nil, nil)
+	defs, ok := eff["user-directive"].Settings["directives"].(map[string]any)
+	require.True(t, ok)
+	assert.Contains(t, defs, "legacy", "rule-level definitions are kept")
+	roster := defs["roster"].(map[string]any)
+	assert.Equal(t, "catalog", roster["expand"], "config definitions win")
+
+	// The config's own rule settings are untouched.
+	ruleDefs := cfg.Rules["user-directive"].Settings["directives"].(map[string]any)
+	assert.Equal(t, "tree", ruleDefs["roster"].(map[string]any)["expand"])
+}
+
+func TestEffective_InjectsBaseRules(t *testing.T) {
+	dir := t.TempDir()
+	writeDirectiveFile(t, dir, "roster", rosterFile)
+	cfg, err := loadDirectiveConfig(t, dir, "rules:\n  catalog:\n    columns: {}\n"+
+		"  tree: false\n  user-directive:\n    directives:\n"+
+		"      pages:\n        expand: tree\n      notes:\n        expand: data\n")
+	require.NoError(t, err)
+
+	eff := Effective(cfg, "README.md", nil, nil)
+	bases, ok := eff["user-directive"].Settings["base-rules"].(map[string]any)
+	require.True(t, ok)
+	assert.Equal(t, map[string]any{
+		"enabled":  true,
+		"settings": map[string]any{"columns": map[string]any{}},
+	}, bases["catalog"])
+	assert.Equal(t, false, bases["tree"].(map[string]any)["enabled"])
+	assert.NotContains(t, bases, "data", "an unconfigured base is left to the rule")
+}
+
+func TestCopyDirectives(t *testing.T) {
+	assert.Nil(t, copyDirectives(nil))
+	orig := map[string]UserDirective{"roster": {
+		Expand: "catalog",
+		Params: ParamCfg{Required: []string{"team"}},
+		With:   map[string]any{"glob": "{team}/*.md"},
+	}}
+	cp := copyDirectives(orig)
+	cp["roster"].With["glob"] = "x"
+	cp["roster"].Params.Required[0] = "y"
+	assert.Equal(t, "{team}/*.md", orig["roster"].With["glob"])
+	assert.Equal(t, "team", orig["roster"].Params.Required[0])
+}
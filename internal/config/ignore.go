@@ -1,6 +1,12 @@
 package config
 
-import "github.com/jeduden/mdsmith/internal/globpath"
+import (
+	"strings"
+
+	"github.com/bmatcuk/doublestar/v4"
+
+	"github.com/jeduden/mdsmith/internal/globpath"
+)
 
 // globMatchAny returns true if filePath matches any of the given glob
 // patterns. It checks the raw path, the cleaned path, and the base name
@@ -23,3 +29,38 @@ func globMatchAny(patterns []string, filePath string) bool {
 func IsIgnored(patterns []string, path string) bool {
 	return globMatchAny(patterns, path)
 }
+
+// ignoreRule is the rule that walks the workspace graph on its own
+// and so needs the ignore list lint file discovery applies.
+const ignoreRule = "reachability"
+
+// injectIgnore appends the config's ignore patterns to the
+// reachability rule's `ignore` setting, after any the rule sets
+// itself. Invalid patterns are dropped, as IsIgnored skips them.
+func injectIgnore(result map[string]RuleCfg, patterns []string) {
+	rc, ok := result[ignoreRule]
+	if !ok || len(patterns) == 0 {
+		return
+	}
+	settings := make(map[string]any, len(rc.Settings)+1)
+	for k, v := range rc.Settings {
+		settings[k] = v
+	}
+	var merged []any
+	switch existing := rc.Settings["ignore"].(type) {
+	case []any:
+		merged = append(merged, existing...)
+	case []string:
+		for _, p := range existing {
+			merged = append(merged, p)
+		}
+	}
+	for _, p := range patterns {
+		if doublestar.ValidatePattern(strings.TrimPrefix(p, "!")) {
+			merged = append(merged, p)
+		}
+	}
+	settings["ignore"] = merged
+	rc.Settings = settings
+	result[ignoreRule] = rc
+}
@@ -56,3 +56,20 @@ func TestIsIgnored_OnlyExclusionsMatchNothing(t *testing.T) {
 	assert.False(t, IsIgnored(patterns, "plan/96_kinds.md"),
 		"a list of only exclusions should not match anything")
 }
+
+func TestEffective_InjectsIgnoreIntoReachability(t *testing.T) {
+	cfg := &Config{
+		Ignore: []string{"build/**", "[invalid"},
+		Rules: map[string]RuleCfg{
+			"reachability": {Enabled: true, Settings: map[string]any{"ignore": []any{"tmp/**"}}},
+		},
+	}
+	eff := Effective(cfg, "README.md", nil, nil)
+	assert.Equal(t, []any{"tmp/**", "build/**"}, eff["reachability"].Settings["ignore"])
+	assert.Equal(t, []any{"tmp/**"}, cfg.Rules["reachability"].Settings["ignore"],
+		"the loaded config is not mutated")
+
+	cfg.Ignore = nil
+	eff = Effective(cfg, "README.md", nil, nil)
+	assert.Equal(t, []any{"tmp/**"}, eff["reachability"].Settings["ignore"])
+}
@@ -119,11 +119,16 @@ func loadFromBytes(data []byte, sourcePath string, mergeKinds bool) (*Config, er
 		return nil, fmt.Errorf("validating wordlists: %w", err)
 	}
 
+	if err := validateDirectives(&cfg); err != nil {
+		return nil, fmt.Errorf("validating directives: %w", err)
+	}
+
 	return &cfg, nil
 }
 
-// mergeFileResources merges file-defined kinds, conventions, and
-// word-lists from `.mdsmith/{kinds,conventions,wordlists}/`. Each merge
+// mergeFileResources merges file-defined kinds, conventions,
+// word-lists, and directives from
+// `.mdsmith/{kinds,conventions,wordlists,directives}/`. Each merge
 // tags inline entries with sourcePath for provenance and errors on name
 // collisions.
 func mergeFileResources(cfg *Config, sourcePath string) error {
@@ -136,6 +141,9 @@ func mergeFileResources(cfg *Config, sourcePath string) error {
 	if err := mergeWordlistFiles(cfg, sourcePath); err != nil {
 		return fmt.Errorf("loading wordlist files: %w", err)
 	}
+	if err := mergeDirectiveFiles(cfg, sourcePath); err != nil {
+		return fmt.Errorf("loading directive files: %w", err)
+	}
 	return nil
 }
 
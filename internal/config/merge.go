@@ -4,6 +4,7 @@ import (
 	"strconv"
 	"strings"
 
+	"github.com/jeduden/mdsmith/internal/lint"
 	"github.com/jeduden/mdsmith/internal/rule"
 )
 
@@ -58,6 +59,7 @@ func Merge(defaults, loaded *Config) *Config {
 		Conventions:            copyUserConventions(loaded.Conventions),
 		ConventionPreset:       copyConventionPreset(loaded.ConventionPreset),
 		Wordlists:              copyWordlists(loaded.Wordlists),
+		Directives:             copyDirectives(loaded.Directives),
 	}
 }
 
@@ -113,6 +115,7 @@ func copyConfig(cfg *Config) *Config {
 		Conventions:            copyUserConventions(cfg.Conventions),
 		ConventionPreset:       copyConventionPreset(cfg.ConventionPreset),
 		Wordlists:              copyWordlists(cfg.Wordlists),
+		Directives:             copyDirectives(cfg.Directives),
 	}
 }
 
@@ -133,6 +136,36 @@ func copyWordlists(m map[string]UserWordlist) map[string]UserWordlist {
 	return out
 }
 
+// copyDirectives returns a copy of a user-defined directives map with
+// its own Params slices and With map. Returns nil when the input is
+// nil.
+func copyDirectives(m map[string]UserDirective) map[string]UserDirective {
+	if m == nil {
+		return nil
+	}
+	out := make(map[string]UserDirective, len(m))
+	for k, v := range m {
+		var with map[string]any
+		if v.With != nil {
+			with = make(map[string]any, len(v.With))
+			for wk, wv := range v.With {
+				with[wk] = wv
+			}
+		}
+		out[k] = UserDirective{
+			Description: v.Description,
+			Params: ParamCfg{
+				Required: copyStrings(v.Params.Required),
+				Optional: copyStrings(v.Params.Optional),
+			},
+			Expand:     v.Expand,
+			With:       with,
+			SourcePath: v.SourcePath,
+		}
+	}
+	return out
+}
+
 // copyUserConventions returns a deep copy of a user-defined
 // conventions map. Returns nil when the input is nil.
 func copyUserConventions(m map[string]UserConvention) map[string]UserConvention {
@@ -157,13 +190,14 @@ func copyBuildConfig(b BuildConfig) BuildConfig {
 	exec := ExecCfg{
 		Path:           b.Exec.Path,
 		EnvPassThrough: copyStrings(b.Exec.EnvPassThrough),
+		Sandbox:        b.Exec.Sandbox,
 	}
 	hooks := HooksCfg{
 		Before: copyHooks(b.Hooks.Before),
 		After:  copyHooks(b.Hooks.After),
 	}
 	if len(b.Recipes) == 0 {
-		return BuildConfig{Hooks: hooks, Exec: exec}
+		return BuildConfig{Hooks: hooks, Exec: exec, Cache: b.Cache}
 	}
 	recipes := make(map[string]RecipeCfg, len(b.Recipes))
 	for name, r := range b.Recipes {
@@ -177,7 +211,7 @@ func copyBuildConfig(b BuildConfig) BuildConfig {
 			DefaultInputs: copyStrings(r.DefaultInputs),
 		}
 	}
-	return BuildConfig{Recipes: recipes, Hooks: hooks, Exec: exec}
+	return BuildConfig{Recipes: recipes, Hooks: hooks, Exec: exec, Cache: b.Cache}
 }
 
 // copyHooks returns a shallow copy of a hook list.
@@ -340,6 +374,25 @@ func EffectiveKinds(cfg *Config, filePath string, fmKinds []string, fmFields map
 	return resolveEffectiveKinds(cfg, filePath, fmKinds, fmFields)
 }
 
+// FileKinds returns the effective kinds of the file at filePath whose
+// front-matter block is fm, as EffectiveKinds resolves them. It backs
+// lint.File.KindsFunc, so rules that read other workspace files see
+// the kinds `mdsmith check` applies to them. Front matter that does
+// not parse contributes no kinds.
+func FileKinds(cfg *Config, filePath string, fm []byte) []string {
+	fmKinds, err := lint.ParseFrontMatterKinds(fm)
+	if err != nil {
+		fmKinds = nil
+	}
+	var fmFields map[string]any
+	if NeedsFieldsForFile(cfg, filePath) {
+		if fmFields, err = lint.ParseFrontMatterFields(fm); err != nil {
+			fmFields = nil
+		}
+	}
+	return EffectiveKinds(cfg, filePath, fmKinds, fmFields)
+}
+
 // resolveEffectiveKinds builds the ordered, deduplicated effective kind list
 // for a file. fmKinds are the kinds declared in the file's front matter;
 // they come first. kind-assignment matches are appended in config order.
@@ -513,6 +566,13 @@ func effectiveRules(cfg *Config, filePath string, kinds []string) map[string]Rul
 	// see it. Runs after the whole layer chain so every layer's
 	// `lists:` (append-merged) is included.
 	expandWordlists(result, cfg.Wordlists)
+	// Hand the config's user-defined directives to the rule that runs
+	// them, so every caller of Effective (CLI, LSP, merge driver)
+	// sees the same definitions.
+	injectDirectives(result, cfg.Directives)
+	// Rules that walk the workspace themselves leave out what lint
+	// file discovery leaves out.
+	injectIgnore(result, cfg.Ignore)
 	return result
 }
 
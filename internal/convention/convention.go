@@ -319,16 +319,19 @@ var conventions = map[string]Convention{
 			"emphasis-style":    {Enabled: true},
 			"list-marker-style": {Enabled: true},
 			"single-h1":         {Enabled: true},
-			// Disable the 25 mdsmith defaults gomarklint does not run by default.
+			// Disable the 30 mdsmith defaults gomarklint does not run by default.
 			"atx-heading-whitespace":         {Enabled: false},
+			"backlinks":                      {Enabled: false},
 			"blockquote-whitespace":          {Enabled: false},
 			"build":                          {Enabled: false},
 			"catalog":                        {Enabled: false},
 			"code-block-style":               {Enabled: false},
 			"commands-show-output":           {Enabled: false},
 			"cross-file-reference-integrity": {Enabled: false},
+			"data":                           {Enabled: false},
 			"empty-section-body":             {Enabled: false},
 			"first-line-heading":             {Enabled: false},
+			"history":                        {Enabled: false},
 			"include":                        {Enabled: false},
 			"line-length":                    {Enabled: false},
 			"list-indent":                    {Enabled: false},
@@ -344,7 +347,9 @@ var conventions = map[string]Convention{
 			"table-readability":              {Enabled: false},
 			"toc":                            {Enabled: false},
 			"token-budget":                   {Enabled: false},
+			"tree":                           {Enabled: false},
 			"unique-frontmatter":             {Enabled: false},
+			"user-directive":                 {Enabled: false},
 		},
 	},
 	"mado-parity": {
@@ -360,14 +365,17 @@ var conventions = map[string]Convention{
 			"no-space-in-link-text":  {Enabled: true},
 			"ordered-list-numbering": {Enabled: true},
 			"single-h1":              {Enabled: true},
-			// Disable the 24 mdsmith defaults mado does not run by default.
+			// Disable the 29 mdsmith defaults mado does not run by default.
+			"backlinks":                      {Enabled: false},
 			"blank-line-around-lists":        {Enabled: false},
 			"build":                          {Enabled: false},
 			"catalog":                        {Enabled: false},
 			"cross-file-reference-integrity": {Enabled: false},
+			"data":                           {Enabled: false},
 			"empty-section-body":             {Enabled: false},
 			"fenced-code-style":              {Enabled: false},
 			"heading-style":                  {Enabled: false},
+			"history":                        {Enabled: false},
 			"include":                        {Enabled: false},
 			"link-validity":                  {Enabled: false},
 			"list-indent":                    {Enabled: false},
@@ -383,8 +391,10 @@ var conventions = map[string]Convention{
 			"table-readability":              {Enabled: false},
 			"toc":                            {Enabled: false},
 			"token-budget":                   {Enabled: false},
+			"tree":                           {Enabled: false},
 			"unclosed-code-block":            {Enabled: false},
 			"unique-frontmatter":             {Enabled: false},
+			"user-directive":                 {Enabled: false},
 		},
 	},
 	"rumdl-parity": {
@@ -404,11 +414,14 @@ var conventions = map[string]Convention{
 			"ordered-list-numbering": {Enabled: true},
 			"proper-names":           {Enabled: true},
 			"single-h1":              {Enabled: true},
-			// Disable the 13 mdsmith defaults rumdl does not run by default.
+			// Disable the 18 mdsmith defaults rumdl does not run by default.
+			"backlinks":                      {Enabled: false},
 			"build":                          {Enabled: false},
 			"catalog":                        {Enabled: false},
 			"cross-file-reference-integrity": {Enabled: false},
+			"data":                           {Enabled: false},
 			"empty-section-body":             {Enabled: false},
+			"history":                        {Enabled: false},
 			"include":                        {Enabled: false},
 			"max-file-length":                {Enabled: false},
 			"paragraph-readability":          {Enabled: false},
@@ -416,8 +429,10 @@ var conventions = map[string]Convention{
 			"table-readability":              {Enabled: false},
 			"toc":                            {Enabled: false},
 			"token-budget":                   {Enabled: false},
+			"tree":                           {Enabled: false},
 			"unclosed-code-block":            {Enabled: false},
 			"unique-frontmatter":             {Enabled: false},
+			"user-directive":                 {Enabled: false},
 		},
 	},
 	"markdownlint-parity": {
@@ -437,11 +452,14 @@ var conventions = map[string]Convention{
 			"ordered-list-numbering": {Enabled: true},
 			"proper-names":           {Enabled: true},
 			"single-h1":              {Enabled: true},
-			// Disable the 13 mdsmith defaults markdownlint does not run by default.
+			// Disable the 18 mdsmith defaults markdownlint does not run by default.
+			"backlinks":                      {Enabled: false},
 			"build":                          {Enabled: false},
 			"catalog":                        {Enabled: false},
 			"cross-file-reference-integrity": {Enabled: false},
+			"data":                           {Enabled: false},
 			"empty-section-body":             {Enabled: false},
+			"history":                        {Enabled: false},
 			"include":                        {Enabled: false},
 			"max-file-length":                {Enabled: false},
 			"paragraph-readability":          {Enabled: false},
@@ -449,8 +467,10 @@ var conventions = map[string]Convention{
 			"table-readability":              {Enabled: false},
 			"toc":                            {Enabled: false},
 			"token-budget":                   {Enabled: false},
+			"tree":                           {Enabled: false},
 			"unclosed-code-block":            {Enabled: false},
 			"unique-frontmatter":             {Enabled: false},
+			"user-directive":                 {Enabled: false},
 		},
 	},
 }
@@ -316,7 +316,8 @@ var gomarklintParityDisabled = []string{
 	"empty-section-body", "toc", "build", "recipe-safety",
 	"no-unused-link-definitions", "no-undefined-reference-labels",
 	"blockquote-whitespace", "list-marker-space", "atx-heading-whitespace",
-	"code-block-style", "commands-show-output", "unique-frontmatter",
+	"code-block-style", "commands-show-output", "unique-frontmatter", "data", "backlinks", "tree", "history",
+	"user-directive",
 	// MDS027: gomarklint's link-fragments is a partial cover (same-file
 	// anchors only), so parity disables mdsmith's cross-file rule.
 	"cross-file-reference-integrity",
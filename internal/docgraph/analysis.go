@@ -0,0 +1,255 @@
+package docgraph
+
+import (
+	"cmp"
+	"slices"
+)
+
+// Hub is a node ranked by how many other files point at it.
+type Hub struct {
+	Path     string `json:"path"`
+	Incoming int    `json:"incoming"`
+}
+
+// Orphans returns the nodes no other file points at, sorted. Entries
+// are exempt: an entry point is where readers start, not a page that
+// needs a link.
+func (g *Graph) Orphans(entries []string) []string {
+	linked := make([]bool, len(g.Nodes))
+	for _, e := range g.Edges {
+		if e.From != e.To {
+			linked[g.index[e.To]] = true
+		}
+	}
+	var out []string
+	for i, n := range g.Nodes {
+		if !linked[i] && !slices.Contains(entries, n) {
+			out = append(out, n)
+		}
+	}
+	return out
+}
+
+// Unreachable returns the nodes no path of edges reaches from any of
+// entries, sorted. Entries that are not nodes are ignored.
+func (g *Graph) Unreachable(entries []string) []string {
+	seen := g.reach(entries)
+	var out []string
+	for i, n := range g.Nodes {
+		if !seen[i] {
+			out = append(out, n)
+		}
+	}
+	return out
+}
+
+// Reachable returns the set of nodes a path of edges reaches from any
+// of entries, the entries included.
+func (g *Graph) Reachable(entries []string) map[string]bool {
+	out := map[string]bool{}
+	for i, ok := range g.reach(entries) {
+		if ok {
+			out[g.Nodes[i]] = true
+		}
+	}
+	return out
+}
+
+// reach marks, per node, whether a breadth-first walk from entries
+// reaches it.
+func (g *Graph) reach(entries []string) []bool {
+	seen := make([]bool, len(g.Nodes))
+	var queue []int
+	for _, e := range entries {
+		if i, ok := g.index[e]; ok && !seen[i] {
+			seen[i] = true
+			queue = append(queue, i)
+		}
+	}
+	for len(queue) > 0 {
+		v := queue[0]
+		queue = queue[1:]
+		for _, ei := range g.out[v] {
+			w := g.index[g.Edges[ei].To]
+			if !seen[w] {
+				seen[w] = true
+				queue = append(queue, w)
+			}
+		}
+	}
+	return seen
+}
+
+// Sources returns the distinct other files with an edge to p, sorted.
+func (g *Graph) Sources(p string) []string {
+	var out []string
+	for _, e := range g.Edges {
+		if e.To == p && e.From != p {
+			out = append(out, e.From)
+		}
+	}
+	return slices.Compact(out)
+}
+
+// Hubs returns up to n nodes with the most distinct files pointing at
+// them, most-linked first, ties by path. Self-references do not count,
+// and nodes nothing points at are left out.
+func (g *Graph) Hubs(n int) []Hub {
+	sources := make([]map[string]struct{}, len(g.Nodes))
+	for _, e := range g.Edges {
+		if e.From == e.To {
+			continue
+		}
+		to := g.index[e.To]
+		if sources[to] == nil {
+			sources[to] = map[string]struct{}{}
+		}
+		sources[to][e.From] = struct{}{}
+	}
+	var hubs []Hub
+	for i, s := range sources {
+		if len(s) > 0 {
+			hubs = append(hubs, Hub{Path: g.Nodes[i], Incoming: len(s)})
+		}
+	}
+	slices.SortFunc(hubs, func(a, b Hub) int {
+		if c := cmp.Compare(b.Incoming, a.Incoming); c != 0 {
+			return c
+		}
+		return cmp.Compare(a.Path, b.Path)
+	})
+	if len(hubs) > n {
+		hubs = hubs[:n]
+	}
+	return hubs
+}
+
+// Components returns the strongly connected components with more than
+// one node: groups of files that all reach each other. Each component
+// is sorted; components are ordered by their first node.
+func (g *Graph) Components() [][]string {
+	var out [][]string
+	for _, scc := range g.components() {
+		if len(scc) > 1 {
+			out = append(out, g.names(scc))
+		}
+	}
+	return out
+}
+
+// IncludeCycles returns one cycle per group of files that include each
+// other, as the path the includes take: each file includes the next,
+// and the last includes the first. A cycle starts at its smallest path;
+// cycles are ordered by it. A file that includes itself is a cycle of
+// one.
+func (g *Graph) IncludeCycles() [][]string {
+	inc := g.Filter([]string{KindInclude})
+	var out [][]string
+	for _, scc := range inc.components() {
+		if len(scc) == 1 && !inc.hasEdge(scc[0], scc[0]) {
+			continue
+		}
+		out = append(out, inc.names(inc.cyclePath(scc)))
+	}
+	return out
+}
+
+func (g *Graph) names(idx []int) []string {
+	out := make([]string, len(idx))
+	for i, v := range idx {
+		out[i] = g.Nodes[v]
+	}
+	return out
+}
+
+func (g *Graph) hasEdge(from, to int) bool {
+	for _, ei := range g.out[from] {
+		if g.index[g.Edges[ei].To] == to {
+			return true
+		}
+	}
+	return false
+}
+
+// cyclePath returns the shortest cycle through the lowest node of a
+// strongly connected component.
+func (g *Graph) cyclePath(scc []int) []int {
+	start := slices.Min(scc)
+	prev := map[int]int{}
+	queue := []int{start}
+	for len(queue) > 0 {
+		v := queue[0]
+		queue = queue[1:]
+		for _, ei := range g.out[v] {
+			w := g.index[g.Edges[ei].To]
+			if w == start {
+				path := []int{v}
+				for path[0] != start {
+					path = slices.Insert(path, 0, prev[path[0]])
+				}
+				return path
+			}
+			if _, seen := prev[w]; seen || !slices.Contains(scc, w) {
+				continue
+			}
+			prev[w] = v
+			queue = append(queue, w)
+		}
+	}
+	return []int{start}
+}
+
+// components returns the strongly connected components of the graph
+// (Tarjan's algorithm), each sorted ascending, ordered by their lowest
+// node.
+func (g *Graph) components() [][]int {
+	n := len(g.Nodes)
+	index := make([]int, n)
+	low := make([]int, n)
+	onStack := make([]bool, n)
+	for i := range index {
+		index[i] = -1
+	}
+	var stack []int
+	var out [][]int
+	next := 0
+	var visit func(v int)
+	visit = func(v int) {
+		index[v], low[v] = next, next
+		next++
+		stack = append(stack, v)
+		onStack[v] = true
+		for _, ei := range g.out[v] {
+			w := g.index[g.Edges[ei].To]
+			switch {
+			case index[w] < 0:
+				visit(w)
+				low[v] = min(low[v], low[w])
+			case onStack[w]:
+				low[v] = min(low[v], index[w])
+			}
+		}
+		if low[v] != index[v] {
+			return
+		}
+		var scc []int
+		for {
+			w := stack[len(stack)-1]
+			stack = stack[:len(stack)-1]
+			onStack[w] = false
+			scc = append(scc, w)
+			if w == v {
+				break
+			}
+		}
+		slices.Sort(scc)
+		out = append(out, scc)
+	}
+	for v := range n {
+		if index[v] < 0 {
+			visit(v)
+		}
+	}
+	slices.SortFunc(out, func(a, b []int) int { return a[0] - b[0] })
+	return out
+}
@@ -0,0 +1,168 @@
+// Package docgraph builds the workspace document graph: one node per
+// Markdown file and one edge per file-to-file reference — links,
+// reference links, images, includes, catalogs, build inputs, and
+// wikilinks. It answers whole-graph questions (orphans, reachability,
+// cycles, hubs) and renders the graph for other tools. The package is
+// pure so both `mdsmith graph` and lint rules can use it.
+package docgraph
+
+import (
+	"slices"
+	"strings"
+
+	"github.com/jeduden/mdsmith/internal/linkgraph"
+	"github.com/jeduden/mdsmith/internal/lint"
+)
+
+// Edge kinds. The labels match the ones `mdsmith deps` prints, plus
+// image and wikilink, which the per-file index does not track.
+const (
+	KindFileLink = "file-link"
+	KindRefLink  = "ref-link"
+	KindImage    = "image"
+	KindInclude  = "include"
+	KindCatalog  = "catalog"
+	KindBuild    = "build"
+	KindWikilink = "wikilink"
+)
+
+// Edge is one reference from one workspace file to another. Paths are
+// workspace-relative and slash-separated.
+type Edge struct {
+	From string `json:"from"`
+	To   string `json:"to"`
+	Kind string `json:"kind"`
+}
+
+// FileEdges returns the edges leaving the file at rel, parsed as f.
+// Catalog globs and glob build inputs expand against files, relative
+// to the host file's directory; a catalog's source-dir is not
+// applied. Wikilinks resolve through wikilinks and are skipped when it
+// is nil. Same-file anchor links, absolute paths, and targets that
+// escape the workspace contribute no edge.
+func FileEdges(rel string, f *lint.File, files []string, wikilinks *linkgraph.WikilinkIndex) []Edge {
+	var out []Edge
+	add := func(target, kind string) {
+		if target != "" {
+			out = append(out, Edge{From: rel, To: target, Kind: kind})
+		}
+	}
+	addLinks := func(links []linkgraph.Link, kind string) {
+		for _, l := range links {
+			if !l.Target.LocalAnchor {
+				add(linkgraph.ResolveRelTarget(rel, l.Target.Path), kind)
+			}
+		}
+	}
+	addLinks(linkgraph.ExtractLinks(f), KindFileLink)
+	addLinks(linkgraph.ExtractRefLinkTargets(f), KindRefLink)
+	addLinks(linkgraph.ExtractImages(f), KindImage)
+
+	for _, d := range linkgraph.ExtractDirectives(f) {
+		kind := KindInclude
+		switch d.Kind {
+		case linkgraph.DirectiveCatalog:
+			kind = KindCatalog
+		case linkgraph.DirectiveBuild:
+			kind = KindBuild
+		}
+		if !d.IsUnresolved() {
+			add(linkgraph.ResolveRelTarget(rel, d.Path), kind)
+			continue
+		}
+		for _, match := range linkgraph.ExpandCatalog(hostGlobs(rel, d.Globs), files) {
+			add(match, kind)
+		}
+	}
+
+	for _, wl := range linkgraph.ExtractWikiLinks(f) {
+		if target, ok := wikilinks.Resolve(wl.Target); ok {
+			add(target, KindWikilink)
+		}
+	}
+	return out
+}
+
+// hostGlobs rewrites directive globs, written relative to the host
+// file's directory, into workspace-relative patterns. A pattern that
+// escapes the workspace is dropped.
+func hostGlobs(rel string, globs []string) []string {
+	out := make([]string, 0, len(globs))
+	for _, g := range globs {
+		neg := strings.HasPrefix(g, "!")
+		resolved := linkgraph.ResolveRelTarget(rel, strings.TrimPrefix(g, "!"))
+		if resolved == "" {
+			continue
+		}
+		if neg {
+			resolved = "!" + resolved
+		}
+		out = append(out, resolved)
+	}
+	return out
+}
+
+// Graph is the document graph over a fixed node set.
+type Graph struct {
+	// Nodes are the workspace files, sorted.
+	Nodes []string
+	// Edges are the distinct edges between nodes, sorted by from, to,
+	// then kind.
+	Edges []Edge
+
+	index map[string]int
+	out   [][]int // per node, the edge indexes leaving it
+}
+
+// New builds the graph over nodes. Edges whose endpoints are not both
+// nodes (an image, a missing page, an ignored file) are dropped, and
+// duplicate edges collapse to one.
+func New(nodes []string, edges []Edge) *Graph {
+	g := &Graph{Nodes: slices.Clone(nodes), index: make(map[string]int, len(nodes))}
+	slices.Sort(g.Nodes)
+	g.Nodes = slices.Compact(g.Nodes)
+	for i, n := range g.Nodes {
+		g.index[n] = i
+	}
+	for _, e := range edges {
+		if g.Has(e.From) && g.Has(e.To) {
+			g.Edges = append(g.Edges, e)
+		}
+	}
+	slices.SortFunc(g.Edges, compareEdges)
+	g.Edges = slices.Compact(g.Edges)
+	g.out = make([][]int, len(g.Nodes))
+	for i, e := range g.Edges {
+		from := g.index[e.From]
+		g.out[from] = append(g.out[from], i)
+	}
+	return g
+}
+
+func compareEdges(a, b Edge) int {
+	if c := strings.Compare(a.From, b.From); c != 0 {
+		return c
+	}
+	if c := strings.Compare(a.To, b.To); c != 0 {
+		return c
+	}
+	return strings.Compare(a.Kind, b.Kind)
+}
+
+// Has reports whether p is a node of the graph.
+func (g *Graph) Has(p string) bool {
+	_, ok := g.index[p]
+	return ok
+}
+
+// Filter returns the graph over the same nodes with only the edges
+// whose kind is in kinds.
+func (g *Graph) Filter(kinds []string) *Graph {
+	var keep []Edge
+	for _, e := range g.Edges {
+		if slices.Contains(kinds, e.Kind) {
+			keep = append(keep, e)
+		}
+	}
+	return New(g.Nodes, keep)
+}
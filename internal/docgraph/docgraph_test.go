@@ -0,0 +1,119 @@
+package docgraph
+
+import (
+	"strings"
+	"testing"
+	"testing/fstest"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/jeduden/mdsmith/internal/linkgraph"
+	"github.com/jeduden/mdsmith/internal/lint"
+)
+
+func TestFileEdges_EveryKind(t *testing.T) {
+	src := "# Index\n\n" +
+		"See [api](api.md#auth), [self](#top), and [ref][r].\n\n" +
+		"![diagram](img/d.png) and [[Notes]].\n\n" +
+		"[r]: guide/setup.md\n\n" +
+		"<?include\nfile: frag.md\n?>\n<?/include?>\n\n" +
+		"<?catalog\nglob:\n  - \"guide/*.md\"\n  - \"!guide/setup.md\"\n?>\n<?/catalog?>\n\n" +
+		"<?build\nrecipe: gen\ninputs:\n  - schema.json\n  - \"guide/*.md\"\noutputs:\n  - out.txt\n?>\n<?/build?>\n"
+	f, err := lint.NewFile("docs/index.md", []byte(src))
+	require.NoError(t, err)
+	files := []string{"docs/guide/a.md", "docs/guide/setup.md", "docs/api.md", "docs/notes.md"}
+	wl := linkgraph.NewWikilinkIndex(fstest.MapFS{"docs/notes.md": {}})
+
+	got := FileEdges("docs/index.md", f, files, wl)
+	want := []Edge{
+		{"docs/index.md", "docs/api.md", KindFileLink},
+		{"docs/index.md", "docs/guide/setup.md", KindRefLink},
+		{"docs/index.md", "docs/img/d.png", KindImage},
+		{"docs/index.md", "docs/frag.md", KindInclude},
+		{"docs/index.md", "docs/guide/a.md", KindCatalog},
+		{"docs/index.md", "docs/schema.json", KindBuild},
+		{"docs/index.md", "docs/guide/a.md", KindBuild},
+		{"docs/index.md", "docs/guide/setup.md", KindBuild},
+		{"docs/index.md", "docs/notes.md", KindWikilink},
+	}
+	assert.ElementsMatch(t, want, got)
+}
+
+func TestFileEdges_DropsEscapingTargets(t *testing.T) {
+	src := "[up](../x.md) [abs](/etc/x.md)\n\n<?catalog\nglob: \"../*.md\"\n?>\n<?/catalog?>\n"
+	f, err := lint.NewFile("a.md", []byte(src))
+	require.NoError(t, err)
+	assert.Empty(t, FileEdges("a.md", f, []string{"a.md"}, nil))
+}
+
+// testGraph is README -> index -> {a, b}; a and b include each other;
+// c includes itself; lonely has no incoming edge.
+func testGraph() *Graph {
+	return New(
+		[]string{"README.md", "index.md", "a.md", "b.md", "c.md", "lonely.md"},
+		[]Edge{
+			{"README.md", "index.md", KindFileLink},
+			{"index.md", "a.md", KindCatalog},
+			{"index.md", "b.md", KindCatalog},
+			{"index.md", "a.md", KindCatalog},
+			{"a.md", "b.md", KindInclude},
+			{"b.md", "a.md", KindInclude},
+			{"b.md", "index.md", KindFileLink},
+			{"c.md", "c.md", KindInclude},
+			{"lonely.md", "missing.md", KindFileLink},
+			{"lonely.md", "logo.png", KindImage},
+		},
+	)
+}
+
+func TestNew_DropsForeignAndDuplicateEdges(t *testing.T) {
+	g := testGraph()
+	assert.Equal(t, []string{"README.md", "a.md", "b.md", "c.md", "index.md", "lonely.md"}, g.Nodes)
+	assert.Len(t, g.Edges, 7)
+	assert.True(t, g.Has("a.md"))
+	assert.False(t, g.Has("missing.md"))
+}
+
+func TestAnalyses(t *testing.T) {
+	g := testGraph()
+	assert.Equal(t, []string{"c.md", "lonely.md"}, g.Orphans([]string{"README.md"}))
+	assert.Equal(t, []string{"README.md", "c.md", "lonely.md"}, g.Orphans(nil))
+	assert.Equal(t, []string{"c.md", "lonely.md"}, g.Unreachable([]string{"README.md", "nope.md"}))
+	assert.Equal(t, g.Nodes, g.Unreachable(nil))
+	assert.Equal(t, map[string]bool{"README.md": true, "index.md": true, "a.md": true, "b.md": true},
+		g.Reachable([]string{"README.md"}))
+	assert.Equal(t, []string{"b.md", "index.md"}, g.Sources("a.md"))
+	assert.Empty(t, g.Sources("c.md"))
+	assert.Equal(t, [][]string{{"a.md", "b.md", "index.md"}}, g.Components())
+	assert.Equal(t, [][]string{{"a.md", "b.md"}, {"c.md"}}, g.IncludeCycles())
+	assert.Equal(t, []Hub{{"a.md", 2}, {"b.md", 2}}, g.Hubs(2))
+	assert.Equal(t, []Hub{{"a.md", 2}, {"b.md", 2}, {"index.md", 2}}, g.Hubs(10))
+}
+
+func TestFilter_KeepsNodes(t *testing.T) {
+	g := testGraph().Filter([]string{KindInclude})
+	assert.Len(t, g.Nodes, 6)
+	assert.Len(t, g.Edges, 3)
+	assert.Equal(t, [][]string{{"a.md", "b.md"}}, g.Components())
+}
+
+func TestRender(t *testing.T) {
+	g := New([]string{"a.md", `q"b.md`}, []Edge{{"a.md", `q"b.md`, KindInclude}})
+
+	var dot strings.Builder
+	require.NoError(t, WriteDOT(&dot, g))
+	assert.Equal(t, "digraph mdsmith {\n  node [shape=box];\n  \"a.md\";\n  \"q\\\"b.md\";\n"+
+		"  \"a.md\" -> \"q\\\"b.md\" [label=\"include\"];\n}\n", dot.String())
+
+	var mm strings.Builder
+	require.NoError(t, WriteMermaid(&mm, g))
+	assert.Equal(t, "flowchart LR\n  n0[\"a.md\"]\n  n1[\"q#quot;b.md\"]\n  n0 -->|include| n1\n", mm.String())
+
+	var gml strings.Builder
+	require.NoError(t, WriteGraphML(&gml, g))
+	assert.Contains(t, gml.String(), `<node id="q&#34;b.md"/>`)
+	assert.Contains(t, gml.String(),
+		`<edge id="e0" source="a.md" target="q&#34;b.md"><data key="kind">include</data></edge>`)
+	assert.True(t, strings.HasPrefix(gml.String(), `<?xml version="1.0" encoding="UTF-8"?>`+"\n"))
+}
@@ -0,0 +1,80 @@
+package docgraph
+
+import (
+	"bufio"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// WriteDOT writes the graph in Graphviz DOT. Nodes are named by their
+// path; each edge carries its kind as the label.
+func WriteDOT(w io.Writer, g *Graph) error {
+	bw := bufio.NewWriter(w)
+	fmt.Fprintln(bw, "digraph mdsmith {")
+	fmt.Fprintln(bw, "  node [shape=box];")
+	for _, n := range g.Nodes {
+		fmt.Fprintf(bw, "  %s;\n", dotQuote(n))
+	}
+	for _, e := range g.Edges {
+		fmt.Fprintf(bw, "  %s -> %s [label=%s];\n", dotQuote(e.From), dotQuote(e.To), dotQuote(e.Kind))
+	}
+	fmt.Fprintln(bw, "}")
+	return bw.Flush()
+}
+
+func dotQuote(s string) string {
+	return `"` + strings.NewReplacer(`\`, `\\`, `"`, `\"`).Replace(s) + `"`
+}
+
+// WriteMermaid writes the graph as a Mermaid flowchart. Mermaid node
+// ids cannot hold paths, so nodes are numbered n0, n1, … in path order
+// and labelled with their path.
+func WriteMermaid(w io.Writer, g *Graph) error {
+	bw := bufio.NewWriter(w)
+	fmt.Fprintln(bw, "flowchart LR")
+	for i, n := range g.Nodes {
+		fmt.Fprintf(bw, "  n%d[\"%s\"]\n", i, mermaidEscape(n))
+	}
+	for _, e := range g.Edges {
+		fmt.Fprintf(bw, "  n%d -->|%s| n%d\n", g.index[e.From], e.Kind, g.index[e.To])
+	}
+	return bw.Flush()
+}
+
+// mermaidEscape replaces the characters that end a quoted Mermaid
+// label with their entity codes.
+func mermaidEscape(s string) string {
+	return strings.NewReplacer(`"`, "#quot;", "<", "#lt;", ">", "#gt;").Replace(s)
+}
+
+// WriteGraphML writes the graph as GraphML. Node ids are their paths;
+// the edge kind is the "kind" data key.
+func WriteGraphML(w io.Writer, g *Graph) error {
+	bw := bufio.NewWriter(w)
+	fmt.Fprintln(bw, xml.Header[:len(xml.Header)-1])
+	fmt.Fprintln(bw, `<graphml xmlns="http://graphml.graphdrawing.org/xmlns">`)
+	fmt.Fprintln(bw, `  <key id="kind" for="edge" attr.name="kind" attr.type="string"/>`)
+	fmt.Fprintln(bw, `  <graph id="mdsmith" edgedefault="directed">`)
+	for _, n := range g.Nodes {
+		fmt.Fprintf(bw, "    <node id=%s/>\n", xmlAttr(n))
+	}
+	for i, e := range g.Edges {
+		fmt.Fprintf(bw, "    <edge id=\"e%d\" source=%s target=%s><data key=\"kind\">%s</data></edge>\n",
+			i, xmlAttr(e.From), xmlAttr(e.To), xmlText(e.Kind))
+	}
+	fmt.Fprintln(bw, "  </graph>")
+	fmt.Fprintln(bw, "</graphml>")
+	return bw.Flush()
+}
+
+func xmlText(s string) string {
+	var b strings.Builder
+	_ = xml.EscapeText(&b, []byte(s)) // a strings.Builder never fails
+	return b.String()
+}
+
+func xmlAttr(s string) string {
+	return `"` + xmlText(s) + `"`
+}
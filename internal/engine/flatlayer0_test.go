@@ -105,20 +105,23 @@ func TestPooledFileConstructor_FlatPath(t *testing.T) {
 	active := &Runner{flatL0Active: true}
 
 	assert.Equal(t, ptr(lint.NewFileFlatPooled),
-		ptr(active.pooledFileConstructor([]byte("# H\n\nplain prose\n"))),
+		ptr(active.pooledFileConstructor([]byte("# H\n\nplain prose\n"), nil)),
 		"directive-free file on an active run uses the flat constructor")
 	assert.Equal(t, ptr(lint.NewFileFromSourcePooled),
-		ptr(active.pooledFileConstructor([]byte("<?include file: x.md ?>\nbody\n"))),
+		ptr(active.pooledFileConstructor([]byte("<?include file: x.md ?>\nbody\n"), nil)),
 		"a file with a generated directive stays on the AST path")
+	assert.Equal(t, ptr(lint.NewFileFromSourcePooled),
+		ptr(active.pooledFileConstructor([]byte("<?roster\n?>\nbody\n"), []string{"roster"})),
+		"so does a file with a configured generated directive")
 
 	inactive := &Runner{flatL0Active: false}
 	assert.Equal(t, ptr(lint.NewFileFromSourcePooled),
-		ptr(inactive.pooledFileConstructor([]byte("# H\n"))),
+		ptr(inactive.pooledFileConstructor([]byte("# H\n"), nil)),
 		"an inactive run always uses the AST constructor")
 
 	block := &Runner{BlockOnlyParse: true, flatL0Active: true}
 	assert.Equal(t, ptr(lint.NewFileBlockOnlyPooled),
-		ptr(block.pooledFileConstructor([]byte("# H\n"))),
+		ptr(block.pooledFileConstructor([]byte("# H\n"), nil)),
 		"block-only takes precedence over flat")
 }
 
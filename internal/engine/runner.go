@@ -453,6 +453,11 @@ func (r *Runner) lintFile(path string, intraFileCap int, cache *lint.RunCache, r
 	effective, sigKey := r.effectiveCached(path, fmKinds, fmFields, rr)
 	logRulesTo(flog, rr.mdRules, effective)
 
+	// Configure the enabled rules once per config signature (cached on the
+	// worker's confCache) and reuse the result across every file that shares
+	// that config, instead of re-cloning every Configurable rule per file.
+	conf := rr.configured(sigKey, effective)
+
 	// The pooled parse recycles AST slab memory across files. lintFile
 	// is the documented lifetime boundary: the File and everything
 	// aliasing its arena die before the deferred release — diagnostics
@@ -479,7 +484,7 @@ func (r *Runner) lintFile(path string, intraFileCap int, cache *lint.RunCache, r
 		// with code blocks (see layer0SkipEligible).
 		f, _ = lint.NewFileFlatPooled(path, source, r.StripFrontMatter)
 	} else {
-		f, releaseArena = r.pooledFileConstructor(source)(path, source, r.StripFrontMatter)
+		f, releaseArena = r.pooledFileConstructor(source, conf.generated)(path, source, r.StripFrontMatter)
 	}
 	release := func() {
 		releaseArena()
@@ -488,25 +493,22 @@ func (r *Runner) lintFile(path string, intraFileCap int, cache *lint.RunCache, r
 	}
 	defer release()
 	r.configureFile(f, path, cache)
+	f.GeneratedDirectives = conf.generated
 
 	// Generated-section ranges come from a PI walk over the AST. A
 	// parse-skipped File (AST nil) is, by gate construction, free of
 	// directives, so it has no generated sections — leave the ranges nil.
 	populateGeneratedRanges(f)
 
-	// Configure the enabled rules once per config signature (cached on the
-	// worker's confCache) and reuse the result across every file that shares
-	// that config, instead of re-cloning every Configurable rule per file.
-	configured, cfgErrs := rr.configured(sigKey, effective)
-	diags := r.checkWithForeignRegions(f, configured, path, intraFileCap)
+	diags := r.checkWithForeignRegions(f, conf.rules, path, intraFileCap)
 	if r.Explain {
 		explain.Attach(diags, r.Config, path, fmKinds, fmFields)
 	}
-	// cfgErrs is the cached configuration-error slice for this signature;
+	// conf.errs is the cached configuration-error slice for this signature;
 	// the aggregator copies elements out, never mutates it, so sharing it
 	// across files that hit the same cache entry is safe. It is nil on
 	// every well-formed config (the common path).
-	return fileOutcome{diags: diags, errs: cfgErrs}
+	return fileOutcome{diags: diags, errs: conf.errs}
 }
 
 // checkWithForeignRegions extends f.GeneratedRanges with the foreign-
@@ -556,6 +558,19 @@ func (r *Runner) configureFile(f *lint.File, path string, cache *lint.RunCache)
 	f.GitignoreFunc = func() *gitignore.Matcher {
 		return r.cachedGitignore(gd)
 	}
+	r.wireKinds(f)
+}
+
+// wireKinds lets rules that read other workspace files resolve their
+// kinds through the loaded config, kind-assignment globs included.
+func (r *Runner) wireKinds(f *lint.File) {
+	if r.Config == nil {
+		return
+	}
+	cfg := r.Config
+	f.KindsFunc = func(path string, fm []byte) []string {
+		return config.FileKinds(cfg, path, fm)
+	}
 }
 
 // pooledFileConstructor returns the per-file parse constructor for this
@@ -566,11 +581,13 @@ func (r *Runner) configureFile(f *lint.File, path string, cache *lint.RunCache)
 // directive stays on the full parse so its generated-section suppression
 // still works. All three special paths are default-off, so the shipped
 // constructor is lint.NewFileFromSourcePooled on every production run.
-func (r *Runner) pooledFileConstructor(source []byte) func(string, []byte, bool) (*lint.File, func()) {
+func (r *Runner) pooledFileConstructor(
+	source []byte, generated []string,
+) func(string, []byte, bool) (*lint.File, func()) {
 	if r.BlockOnlyParse {
 		return lint.NewFileBlockOnlyPooled
 	}
-	if r.flatL0Active && !gensection.HasGeneratedDirective(source) {
+	if r.flatL0Active && !gensection.HasGeneratedDirective(source, generated...) {
 		return lint.NewFileFlatPooled
 	}
 	return lint.NewFileFromSourcePooled
@@ -718,7 +735,7 @@ func (r *Runner) parseForSource(path string, source []byte, version int, usePars
 
 // populateFileFields sets the Runner-derived state on f that
 // downstream checks rely on: MaxInputBytes, RunCache, FS, RootDir,
-// the lazy gitignore hook, and the generated-section ranges.
+// the lazy gitignore hook, and the generated-section names and ranges.
 // Factored out of runSource so parseForSource can call it once
 // before the *File is published to the parse cache — see that
 // method's comment for the racing-readers argument.
@@ -758,6 +775,8 @@ func (r *Runner) populateFileFields(f *lint.File, path string) {
 			return r.cachedGitignore(gd)
 		}
 	}
+	r.wireKinds(f)
+	f.GeneratedDirectives = r.generatedDirectives(path, f.FrontMatter)
 	f.GeneratedRanges = gensection.FindAllGeneratedRanges(f)
 	// Extend the exclusion set with foreign-region spans before the *File
 	// is published to the parse cache, so the read-only diagnostic pass
@@ -890,6 +909,17 @@ func (r *Runner) parseFrontMatter(path string, fm []byte) ([]string, map[string]
 	return kinds, fields, nil
 }
 
+// generatedDirectives returns the configured directive names whose
+// bodies are generated in the file at path, or nil when its front
+// matter does not parse (runSource reports that error itself).
+func (r *Runner) generatedDirectives(path string, fm []byte) []string {
+	fmKinds, fmFields, err := r.parseFrontMatter(path, fm)
+	if err != nil {
+		return nil
+	}
+	return checker.GeneratedDirectives(r.markdownRules(), r.effectiveWithCategories(path, fmKinds, fmFields))
+}
+
 // effectiveWithCategories computes the effective rule config for a file
 // path, applying category-based enable/disable on top of per-rule settings.
 func (r *Runner) effectiveWithCategories(
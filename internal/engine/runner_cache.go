@@ -57,11 +57,13 @@ type runResolve struct {
 }
 
 // configuredRules is one cache entry: the enabled, configured rule list
-// for a config signature plus the settings-application errors that
-// configuring it produced (surfaced once, not re-derived per file).
+// for a config signature, the configured directives whose bodies are
+// generated, and the settings-application errors that configuring it
+// produced (surfaced once, not re-derived per file).
 type configuredRules struct {
-	rules []rule.Rule
-	errs  []error
+	rules     []rule.Rule
+	generated []string
+	errs      []error
 }
 
 // configured returns the configured enabled rule list for the effective
@@ -70,13 +72,18 @@ type configuredRules struct {
 // because the map header aliases the one instance the worker created.
 func (rr runResolve) configured(
 	key string, effective map[string]config.RuleCfg,
-) ([]rule.Rule, []error) {
+) configuredRules {
 	if c, ok := rr.confCache[key]; ok {
-		return c.rules, c.errs
+		return c
 	}
 	rules, errs := checker.ConfigureEnabledRules(rr.mdRules, effective)
-	rr.confCache[key] = configuredRules{rules: rules, errs: errs}
-	return rules, errs
+	c := configuredRules{
+		rules:     rules,
+		generated: checker.GeneratedDirectives(rr.mdRules, effective),
+		errs:      errs,
+	}
+	rr.confCache[key] = c
+	return c
 }
 
 // effectiveCached is the hot-path config resolver: it memoizes the
@@ -3,6 +3,7 @@ package engine
 import (
 	"os"
 	"path/filepath"
+	"strings"
 	"testing"
 
 	"github.com/jeduden/mdsmith/internal/checker"
@@ -13,6 +14,7 @@ import (
 	"github.com/stretchr/testify/require"
 
 	// Import rules so init() registers them.
+	_ "github.com/jeduden/mdsmith/internal/rules/linelength"
 	_ "github.com/jeduden/mdsmith/internal/rules/notrailingspaces"
 )
 
@@ -113,6 +115,80 @@ func TestLintOnce_CatalogHost(t *testing.T) {
 	}
 }
 
+// assertBodyNotLinted lints a host whose <?name?> section holds params
+// and a body line longer than the line limit, and fails on any MDS001
+// finding: the body is generated, so fix cannot shorten it.
+func assertBodyNotLinted(t *testing.T, name, params string) {
+	t.Helper()
+	assertBodyNotLintedWith(t, &config.Config{Rules: map[string]config.RuleCfg{
+		"line-length": {Enabled: true},
+	}}, name, params)
+}
+
+// assertBodyNotLintedWith is assertBodyNotLinted under cfg.
+func assertBodyNotLintedWith(t *testing.T, cfg *config.Config, name, params string) {
+	t.Helper()
+	dir := t.TempDir()
+	host := "# Host\n\n" +
+		"<?" + name + "\n" + params + "?>\n" +
+		"- " + strings.Repeat("word ", 30) + "\n" +
+		"<?/" + name + "?>\n"
+	hostPath := filepath.Join(dir, "host.md")
+	require.NoError(t, os.WriteFile(hostPath, []byte(host), 0o644))
+
+	runner := &Runner{
+		Config:  cfg,
+		Rules:   rule.All(),
+		RootDir: dir,
+	}
+	result := runner.Run([]string{hostPath})
+	require.Empty(t, result.Errors, "unexpected errors: %v", result.Errors)
+	for _, d := range result.Diagnostics {
+		assert.NotEqual(t, "MDS001", d.RuleID,
+			"%s host must not surface line-length from generated body: line %d", name, d.Line)
+	}
+}
+
+// TestLintOnce_DataHost verifies that a <?data?> row longer than the
+// line limit is not reported on the host.
+func TestLintOnce_DataHost(t *testing.T) {
+	assertBodyNotLinted(t, "data", "file: rows.csv\nrow: \"- {name}: {note}\"\n")
+}
+
+// TestLintOnce_BacklinksHost verifies that a long <?backlinks?> entry
+// is not reported on the host.
+func TestLintOnce_BacklinksHost(t *testing.T) {
+	assertBodyNotLinted(t, "backlinks", "")
+}
+
+// TestLintOnce_TreeHost verifies that a long <?tree?> entry is not
+// reported on the host.
+func TestLintOnce_TreeHost(t *testing.T) {
+	assertBodyNotLinted(t, "tree", "")
+}
+
+// TestLintOnce_HistoryHost verifies that a <?history?> entry with a
+// long commit subject is not reported on the host.
+func TestLintOnce_HistoryHost(t *testing.T) {
+	assertBodyNotLinted(t, "history", "")
+}
+
+// TestLintOnce_UserDirectiveHost verifies that the body of a directive
+// declared in config, here one that expands to data, is not reported
+// on the host.
+func TestLintOnce_UserDirectiveHost(t *testing.T) {
+	cfg := &config.Config{
+		Rules: map[string]config.RuleCfg{
+			"line-length":    {Enabled: true},
+			"user-directive": {Enabled: true},
+		},
+		Directives: map[string]config.UserDirective{
+			"notes": {Expand: "data", With: map[string]any{"file": "rows.csv"}},
+		},
+	}
+	assertBodyNotLintedWith(t, cfg, "notes", "")
+}
+
 // TestLintOnce_HostOwnedDiagnosticsPreserved verifies that diagnostics in
 // host-authored content (outside generated sections) are not suppressed.
 func TestLintOnce_HostOwnedDiagnosticsPreserved(t *testing.T) {
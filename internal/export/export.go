@@ -45,7 +45,8 @@ const (
 // Marker stripping is independent of `rules`: every directive
 // registered in the global rule registry has its start/end markers
 // stripped from the output, so a disabled directive's markers still
-// disappear even though its body is untouched. Callers that want
+// disappear even though its body is untouched. The one exception is
+// user-defined directives, whose names only `rules` carries. Callers that want
 // stripping but no staleness behavior can pass a nil rules slice.
 //
 // Generated section markers are removed, generated bodies stay as
@@ -60,7 +61,7 @@ const (
 //     exit non-zero
 func Export(f *lint.File, mode Mode, rules []rule.Rule) ([]byte, []lint.Diagnostic) {
 	active := selectDirectives(rules)
-	stripDirs := allDirectiveNames()
+	stripDirs := allDirectiveNames(rules)
 
 	working := f
 	switch mode {
@@ -88,7 +89,9 @@ type directiveRule struct {
 
 // selectDirectives picks the rules that implement gensection.Directive
 // AND rule.FixableRule, and orders them by directive name so behavior
-// is deterministic across calls. Returns nil for a nil/empty input.
+// is deterministic across calls. A gensection.DirectiveSet rule sorts
+// by its first directive's name and is skipped when it serves none.
+// Returns nil for a nil/empty input.
 func selectDirectives(rules []rule.Rule) []directiveRule {
 	var out []directiveRule
 	for _, r := range rules {
@@ -98,7 +101,15 @@ func selectDirectives(rules []rule.Rule) []directiveRule {
 		}
 		d, dok := r.(gensection.Directive)
 		if !dok {
-			continue
+			ds, ok := r.(gensection.DirectiveSet)
+			if !ok {
+				continue
+			}
+			set := ds.Directives()
+			if len(set) == 0 {
+				continue
+			}
+			d = set[0]
 		}
 		out = append(out, directiveRule{rule: fr, directive: d})
 	}
@@ -122,21 +133,30 @@ type directiveStrip struct {
 // directive registered at package-init time. Stripping is independent
 // of the file's effective config — a disabled rule's markers should
 // still vanish — so this list comes straight from `rule.All()` without
-// any kind/override merge.
-func allDirectiveNames() []directiveStrip {
-	all := rule.All()
+// any kind/override merge. User-defined directives only exist in
+// config, so their names come from the gensection.DirectiveSet rules
+// in rules.
+func allDirectiveNames(rules []rule.Rule) []directiveStrip {
 	var out []directiveStrip
-	for _, r := range all {
-		d, ok := r.(gensection.Directive)
-		if !ok {
-			continue
-		}
+	add := func(d gensection.Directive) {
 		out = append(out, directiveStrip{
 			name:     d.Name(),
 			ruleID:   d.RuleID(),
 			ruleName: d.RuleName(),
 		})
 	}
+	for _, r := range rule.All() {
+		if d, ok := r.(gensection.Directive); ok {
+			add(d)
+		}
+	}
+	for _, r := range rules {
+		if ds, ok := r.(gensection.DirectiveSet); ok {
+			for _, d := range ds.Directives() {
+				add(d)
+			}
+		}
+	}
 	sort.Slice(out, func(i, j int) bool { return out[i].name < out[j].name })
 	return out
 }
@@ -179,14 +199,17 @@ func regenerate(orig *lint.File, directives []directiveRule) *lint.File {
 }
 
 // hydrate copies the per-file context the directive engines rely on
-// (FS, RootFS/RootDir, MaxInputBytes, gitignore factory) from orig
-// onto parsed so a freshly parsed buffer behaves like the original.
+// (FS, RootFS/RootDir, MaxInputBytes, gitignore and kinds resolvers,
+// generated directive names) from orig onto parsed so a freshly parsed buffer behaves like the
+// original.
 func hydrate(parsed, orig *lint.File) {
 	parsed.FS = orig.FS
 	parsed.RootFS = orig.RootFS
 	parsed.RootDir = orig.RootDir
 	parsed.MaxInputBytes = orig.MaxInputBytes
 	parsed.GitignoreFunc = orig.GitignoreFunc
+	parsed.KindsFunc = orig.KindsFunc
+	parsed.GeneratedDirectives = orig.GeneratedDirectives
 	parsed.GeneratedRanges = gensection.FindAllGeneratedRanges(parsed)
 }
 
@@ -31,7 +31,7 @@ func TestSelectDirectives(t *testing.T) {
 }
 
 func TestAllDirectiveNames(t *testing.T) {
-	got := allDirectiveNames()
+	got := allDirectiveNames(nil)
 	require.NotEmpty(t, got)
 
 	for i := 1; i < len(got); i++ {
@@ -113,7 +113,7 @@ func TestStripDirectives(t *testing.T) {
 		src := "# Title\n\nbody\n"
 		f, err := lint.NewFile("doc.md", []byte(src))
 		require.NoError(t, err)
-		got := stripDirectives(f, allDirectiveNames())
+		got := stripDirectives(f, allDirectiveNames(nil))
 		assert.Equal(t, src, string(got))
 	})
 
@@ -121,7 +121,7 @@ func TestStripDirectives(t *testing.T) {
 		src := "# Title\n\n<?toc?>\n\n- [Section](#section)\n\n<?/toc?>\n\n## Section\n\nbody\n"
 		f, err := lint.NewFile("doc.md", []byte(src))
 		require.NoError(t, err)
-		got := stripDirectives(f, allDirectiveNames())
+		got := stripDirectives(f, allDirectiveNames(nil))
 		s := string(got)
 		assert.NotContains(t, s, "<?toc")
 		assert.Contains(t, s, "- [Section](#section)")
@@ -131,7 +131,7 @@ func TestStripDirectives(t *testing.T) {
 		src := "# Title\n\n<?allow-empty-section?>\n\nbody\n"
 		f, err := lint.NewFile("doc.md", []byte(src))
 		require.NoError(t, err)
-		got := stripDirectives(f, allDirectiveNames())
+		got := stripDirectives(f, allDirectiveNames(nil))
 		assert.NotContains(t, string(got), "<?allow-empty-section?>")
 		assert.Contains(t, string(got), "body")
 	})
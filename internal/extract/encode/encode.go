@@ -1,6 +1,6 @@
 // Package encode serialises an extracted data tree into one of the
-// supported wire formats. JSON, YAML, and msgpack are equivalent
-// projections of the same tree; a Lua encoder is deferred (plan
+// supported wire formats. JSON, JSON Lines, YAML, and msgpack are
+// equivalent projections of the same tree; a Lua encoder is deferred (plan
 // 166) but would slot in behind the same Format enum.
 package encode
 
@@ -19,6 +19,7 @@ type Format string
 // Supported formats.
 const (
 	JSON    Format = "json"
+	JSONL   Format = "jsonl"
 	YAML    Format = "yaml"
 	Msgpack Format = "msgpack"
 )
@@ -28,23 +29,25 @@ const (
 // message.
 func ParseFormat(s string) (Format, error) {
 	switch Format(s) {
-	case JSON, YAML, Msgpack:
+	case JSON, JSONL, YAML, Msgpack:
 		return Format(s), nil
 	}
 	return "", fmt.Errorf(
-		"unknown format %q (want json, yaml, or msgpack)", s)
+		"unknown format %q (want json, jsonl, yaml, or msgpack)", s)
 }
 
 // Encode serialises v in the requested format. JSON is indented
 // two spaces and newline-terminated for human-readable CLI output;
-// YAML uses a two-space indent; msgpack is the canonical binary
-// form.
+// JSON Lines is one compact, newline-terminated line; YAML uses a
+// two-space indent; msgpack is the canonical binary form.
 func Encode(f Format, v any) ([]byte, error) {
 	switch f {
-	case JSON:
+	case JSON, JSONL:
 		var buf bytes.Buffer
 		enc := json.NewEncoder(&buf)
-		enc.SetIndent("", "  ")
+		if f == JSON {
+			enc.SetIndent("", "  ")
+		}
 		enc.SetEscapeHTML(false)
 		if err := enc.Encode(v); err != nil {
 			return nil, err
@@ -11,7 +11,7 @@ import (
 )
 
 func TestParseFormat(t *testing.T) {
-	for _, s := range []string{"json", "yaml", "msgpack"} {
+	for _, s := range []string{"json", "jsonl", "yaml", "msgpack"} {
 		f, err := ParseFormat(s)
 		require.NoError(t, err)
 		assert.Equal(t, Format(s), f)
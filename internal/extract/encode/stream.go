@@ -0,0 +1,148 @@
+package encode
+
+import (
+	"bytes"
+	"io"
+	"os"
+
+	"github.com/vmihailenco/msgpack/v5"
+)
+
+// Stream writes a sequence of values as one document in a Format,
+// one value at a time, so a caller projecting many files never holds
+// more than one tree:
+//
+//   - JSON Lines: one line per value.
+//   - JSON: an indented array.
+//   - YAML: a block sequence.
+//   - msgpack: an array. Its header carries the length, so values
+//     are spooled to a temporary file and copied out on Close.
+//
+// An empty stream is an empty array (`[]`) in JSON and YAML and no
+// output in JSON Lines.
+type Stream struct {
+	w     io.Writer
+	f     Format
+	n     int
+	spool *os.File
+}
+
+// NewStream returns a Stream writing f to w. Close must be called to
+// finish the document; a caller that gives up early calls Abort.
+func NewStream(w io.Writer, f Format) *Stream {
+	return &Stream{w: w, f: f}
+}
+
+// Write appends v to the stream.
+func (s *Stream) Write(v any) error {
+	switch s.f {
+	case JSON:
+		return s.writeJSON(v)
+	case YAML:
+		// A one-item sequence per value concatenates into one
+		// sequence; a fresh encoder per value avoids the `---`
+		// document separators a shared one would emit.
+		out, err := Encode(YAML, []any{v})
+		if err != nil {
+			return err
+		}
+		return s.put(out)
+	case Msgpack:
+		return s.spoolMsgpack(v)
+	}
+	out, err := Encode(s.f, v)
+	if err != nil {
+		return err
+	}
+	return s.put(out)
+}
+
+// Close finishes the document and releases the msgpack spool.
+func (s *Stream) Close() error {
+	switch s.f {
+	case JSON, YAML:
+		if s.n == 0 {
+			_, err := io.WriteString(s.w, "[]\n")
+			return err
+		}
+		if s.f == JSON {
+			_, err := io.WriteString(s.w, "\n]\n")
+			return err
+		}
+	case Msgpack:
+		return s.flushMsgpack()
+	}
+	return nil
+}
+
+// Abort releases the msgpack spool without finishing the document.
+// It does nothing after Close, so a caller may defer it to cover an
+// early return on a Write error.
+func (s *Stream) Abort() {
+	s.dropSpool()
+}
+
+func (s *Stream) put(out []byte) error {
+	if _, err := s.w.Write(out); err != nil {
+		return err
+	}
+	s.n++
+	return nil
+}
+
+// writeJSON writes v as the next element of an indented array, so
+// the whole stream reads like Encode(JSON, values).
+func (s *Stream) writeJSON(v any) error {
+	out, err := Encode(JSON, v)
+	if err != nil {
+		return err
+	}
+	sep := ",\n  "
+	if s.n == 0 {
+		sep = "[\n  "
+	}
+	out = bytes.ReplaceAll(bytes.TrimSuffix(out, []byte("\n")), []byte("\n"), []byte("\n  "))
+	return s.put(append([]byte(sep), out...))
+}
+
+func (s *Stream) spoolMsgpack(v any) error {
+	out, err := Encode(Msgpack, v)
+	if err != nil {
+		return err
+	}
+	if s.spool == nil {
+		if s.spool, err = os.CreateTemp("", "mdsmith-stream-*.msgpack"); err != nil {
+			return err
+		}
+	}
+	if _, err := s.spool.Write(out); err != nil {
+		return err
+	}
+	s.n++
+	return nil
+}
+
+func (s *Stream) flushMsgpack() error {
+	if err := msgpack.NewEncoder(s.w).EncodeArrayLen(s.n); err != nil {
+		s.dropSpool()
+		return err
+	}
+	if s.spool == nil {
+		return nil
+	}
+	defer s.dropSpool()
+	if _, err := s.spool.Seek(0, io.SeekStart); err != nil {
+		return err
+	}
+	_, err := io.Copy(s.w, s.spool)
+	return err
+}
+
+func (s *Stream) dropSpool() {
+	if s.spool == nil {
+		return
+	}
+	_ = s.spool.Close()
+	_ = os.Remove(s.spool.Name())
+	s.spool = nil
+}
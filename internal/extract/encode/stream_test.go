@@ -0,0 +1,88 @@
+package encode
+
+import (
+	"bytes"
+	"encoding/json"
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/vmihailenco/msgpack/v5"
+	"gopkg.in/yaml.v3"
+)
+
+func streamAll(t *testing.T, f Format, vals ...any) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	s := NewStream(&buf, f)
+	for _, v := range vals {
+		require.NoError(t, s.Write(v))
+	}
+	require.NoError(t, s.Close())
+	return buf.Bytes()
+}
+
+func TestStream_MatchesEncodeOfTheList(t *testing.T) {
+	vals := []any{
+		map[string]any{"path": "a.md", "data": map[string]any{"n": 1}},
+		map[string]any{"path": "b.md", "data": []any{"x", "y"}},
+	}
+	for _, f := range []Format{JSON, YAML} {
+		want, err := Encode(f, vals)
+		require.NoError(t, err)
+		assert.Equal(t, string(want), string(streamAll(t, f, vals...)), f)
+	}
+
+	var mv []any
+	require.NoError(t, msgpack.Unmarshal(streamAll(t, Msgpack, vals...), &mv))
+	var jv []any
+	require.NoError(t, json.Unmarshal(streamAll(t, JSON, vals...), &jv))
+	assert.Len(t, mv, 2)
+	assert.Equal(t, "b.md", mv[1].(map[string]any)["path"])
+	assert.Equal(t, "b.md", jv[1].(map[string]any)["path"])
+
+	assert.Equal(t, "{\"path\":\"a.md\"}\n{\"path\":\"b.md\"}\n",
+		string(streamAll(t, JSONL, map[string]any{"path": "a.md"}, map[string]any{"path": "b.md"})))
+}
+
+func TestStream_Empty(t *testing.T) {
+	assert.Equal(t, "[]\n", string(streamAll(t, JSON)))
+	assert.Equal(t, "[]\n", string(streamAll(t, YAML)))
+	assert.Empty(t, streamAll(t, JSONL))
+
+	var yv []any
+	require.NoError(t, yaml.Unmarshal(streamAll(t, YAML), &yv))
+	var mv []any
+	require.NoError(t, msgpack.Unmarshal(streamAll(t, Msgpack), &mv))
+	assert.Empty(t, mv)
+}
+
+func TestStream_EncodeError(t *testing.T) {
+	bad := map[string]any{"f": func() {}}
+	for _, f := range []Format{JSON, JSONL, Msgpack} {
+		var buf bytes.Buffer
+		s := NewStream(&buf, f)
+		assert.Error(t, s.Write(bad), f)
+		require.NoError(t, s.Close())
+	}
+}
+
+func TestStream_AbortRemovesSpool(t *testing.T) {
+	var buf bytes.Buffer
+	s := NewStream(&buf, Msgpack)
+	require.NoError(t, s.Write(map[string]any{"path": "a.md"}))
+	require.NotNil(t, s.spool)
+	name := s.spool.Name()
+	assert.Error(t, s.Write(map[string]any{"f": func() {}}))
+
+	s.Abort()
+	_, err := os.Stat(name)
+	assert.True(t, os.IsNotExist(err), "spool %s must be removed", name)
+	assert.Empty(t, buf.Bytes())
+
+	// Abort after Close is a no-op.
+	s = NewStream(&buf, Msgpack)
+	require.NoError(t, s.Close())
+	s.Abort()
+}
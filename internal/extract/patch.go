@@ -0,0 +1,643 @@
+package extract
+
+import (
+	"bytes"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/jeduden/mdsmith/internal/lint"
+	"github.com/jeduden/mdsmith/internal/schema"
+	"github.com/jeduden/mdsmith/internal/yamlutil"
+	"github.com/jeduden/mdsmith/pkg/goldmark/ast"
+	"gopkg.in/yaml.v3"
+)
+
+// Patch rewrites a conforming document so its projection becomes
+// data, touching only what changed. f must be built with its front
+// matter stripped (lint.NewFileFromSource) and mt must be its match
+// tree, so Patch knows which lines each projected value came from.
+//
+// Edits are line-based and local:
+//
+//   - front matter keys are set, added, or removed on the YAML node
+//     tree, so key order and comments survive;
+//   - a changed heading capture rewrites that heading line;
+//   - a changed content value re-renders only its block, and prose
+//     between blocks is never touched;
+//   - an optional section or content entry absent from data is
+//     deleted, and one present only in data is rendered (as Render
+//     would) and inserted in schema order;
+//   - repeating sections patch element by element, appending or
+//     deleting the tail.
+//
+// A required section absent from data is left as it is. Sections a
+// schema-level `projection: blocks` projects without a declaring
+// scope cannot be patched and are an error when they change. Like
+// Render, Patch does not prove conformance; callers gate the result
+// and compare a fresh Extract with Mismatch.
+func Patch(
+	f *lint.File, sch *schema.Schema, mt *schema.MatchTree, data map[string]any,
+) ([]byte, error) {
+	pt := &patcher{
+		f:     f,
+		sch:   sch,
+		p:     &projector{f: f, sch: sch},
+		r:     &renderer{sch: sch, fm: mt.Frontmatter},
+		heads: schema.ExtractDocHeadings(f),
+		lines: sourceLines(f.Source),
+	}
+	root := newDataObj(data, "")
+	fmOut := f.FrontMatter
+	if v, ok := root.take("frontmatter"); ok {
+		fm, isMap := v.(map[string]any)
+		if !isMap && v != nil {
+			return nil, fmt.Errorf("frontmatter: want an object, got %T", v)
+		}
+		pt.r.fm = fm
+		var err error
+		if fmOut, err = patchFrontMatter(f.FrontMatter, fm); err != nil {
+			return nil, err
+		}
+	}
+	anchor := 0
+	if sch.EffectiveRootLevel() == 2 {
+		var err error
+		if anchor, err = pt.title(root); err != nil {
+			return nil, err
+		}
+	}
+	err := pt.scopes(sch.Sections, sch.EffectiveRootLevel(), root,
+		mt.Root, anchor, len(pt.lines)+1)
+	if err != nil {
+		return nil, err
+	}
+	if err := pt.unlisted(root, mt.Root); err != nil {
+		return nil, err
+	}
+	if err := root.leftovers(); err != nil {
+		return nil, err
+	}
+	return append(append([]byte{}, fmOut...), pt.apply()...), nil
+}
+
+type patcher struct {
+	f     *lint.File
+	sch   *schema.Schema
+	p     *projector
+	r     *renderer
+	heads []schema.DocHeading
+	lines []string
+	edits []lineEdit
+}
+
+// lineEdit replaces the 1-based line range [start, end) with lines;
+// start == end inserts before start.
+type lineEdit struct {
+	start, end int
+	lines      []string
+}
+
+func sourceLines(src []byte) []string {
+	s := strings.TrimSuffix(string(src), "\n")
+	if s == "" {
+		return nil
+	}
+	return strings.Split(s, "\n")
+}
+
+func (pt *patcher) replace(start, end int, text string) {
+	pt.edits = append(pt.edits, lineEdit{start, end, strings.Split(text, "\n")})
+}
+
+func (pt *patcher) remove(start, end int) {
+	pt.edits = append(pt.edits, lineEdit{start: start, end: end})
+}
+
+// insertAfter adds a block after line, separated by a blank line.
+// Line 0 is the top of the body.
+func (pt *patcher) insertAfter(line int, text string) {
+	if line == 0 {
+		pt.insertBefore(1, text)
+		return
+	}
+	pt.edits = append(pt.edits, lineEdit{line + 1, line + 1,
+		append([]string{""}, strings.Split(text, "\n")...)})
+}
+
+// insertBefore adds a block before line — a heading, or the end of
+// the document when line is past the last one.
+func (pt *patcher) insertBefore(line int, text string) {
+	if line > len(pt.lines) {
+		pt.insertAfter(len(pt.lines), text)
+		return
+	}
+	pt.edits = append(pt.edits, lineEdit{line, line,
+		append(strings.Split(text, "\n"), "")})
+}
+
+// apply runs the edits bottom-up so earlier line numbers stay valid.
+// Edits at the same line apply in reverse order of creation, which
+// keeps two insertions at one spot in the order they were made.
+func (pt *patcher) apply() []byte {
+	sort.SliceStable(pt.edits, func(i, j int) bool {
+		return pt.edits[i].start < pt.edits[j].start
+	})
+	lines := pt.lines
+	for i := len(pt.edits) - 1; i >= 0; i-- {
+		e := pt.edits[i]
+		tail := append(append([]string{}, e.lines...), lines[e.end-1:]...)
+		lines = append(lines[:e.start-1:e.start-1], tail...)
+	}
+	for len(lines) > 0 && strings.TrimSpace(lines[len(lines)-1]) == "" {
+		lines = lines[:len(lines)-1]
+	}
+	if len(lines) == 0 {
+		return nil
+	}
+	return []byte(strings.Join(lines, "\n") + "\n")
+}
+
+func (pt *patcher) blank(line int) bool {
+	return line >= 1 && line <= len(pt.lines) && strings.TrimSpace(pt.lines[line-1]) == ""
+}
+
+// nextNonBlank returns the first line at or after line that is not
+// blank, or one past the end.
+func (pt *patcher) nextNonBlank(line int) int {
+	for pt.blank(line) {
+		line++
+	}
+	return line
+}
+
+// title rewrites or inserts the document H1 and returns its line
+// (0 when there is none), the anchor for root preamble content.
+func (pt *patcher) title(root *dataObj) (int, error) {
+	line := 0
+	for _, dh := range pt.heads {
+		if dh.Level == 1 {
+			line = dh.Line
+			break
+		}
+	}
+	v, ok := root.take("title")
+	if !ok {
+		return line, nil
+	}
+	title, err := scalarText("title", v)
+	if err != nil {
+		return 0, err
+	}
+	if line == 0 {
+		pt.insertBefore(pt.nextNonBlank(1), "# "+title)
+		return 0, nil
+	}
+	if title != pt.p.firstH1PlainText() {
+		if err := pt.heading(line, 1, title); err != nil {
+			return 0, err
+		}
+	}
+	return line, nil
+}
+
+// heading rewrites an ATX heading line. A setext heading spans two
+// lines and is left to the author.
+func (pt *patcher) heading(line, level int, text string) error {
+	if !strings.HasPrefix(strings.TrimLeft(pt.lines[line-1], " "), "#") {
+		return fmt.Errorf("line %d: cannot rewrite a setext heading; use `#` headings", line)
+	}
+	pt.replace(line, line+1, strings.Repeat("#", level)+" "+text)
+	return nil
+}
+
+// sectionEnd returns the line after sm's section: the next heading
+// at the same or a shallower level, or one past the end.
+func (pt *patcher) sectionEnd(sm *schema.ScopeMatch) int {
+	for _, dh := range pt.heads {
+		if dh.Line > sm.Heading.Line && dh.Level <= sm.Heading.Level {
+			return dh.Line
+		}
+	}
+	return len(pt.lines) + 1
+}
+
+// removeSection deletes sm's section. At the end of the document
+// it also takes the blank lines before the heading, so a section
+// appended in the same patch keeps a single separating blank.
+func (pt *patcher) removeSection(sm *schema.ScopeMatch) {
+	start, end := sm.Heading.Line, pt.sectionEnd(sm)
+	if end > len(pt.lines) {
+		for pt.blank(start - 1) {
+			start--
+		}
+	}
+	pt.remove(start, end)
+}
+
+// matchesFor returns parent's child matches for sc, in document
+// order.
+func matchesFor(parent *schema.ScopeMatch, sc *schema.Scope) []*schema.ScopeMatch {
+	var out []*schema.ScopeMatch
+	for _, sm := range parent.Children {
+		if sm.Scope == sc {
+			out = append(out, sm)
+		}
+	}
+	return out
+}
+
+// insertPoint returns where a new occurrence of scopes[i] goes: after
+// its last match, else before the first later sibling that matched,
+// else at the end of the parent.
+func (pt *patcher) insertPoint(
+	scopes []schema.Scope, i int, parent *schema.ScopeMatch, end int,
+) int {
+	if ms := matchesFor(parent, &scopes[i]); len(ms) > 0 {
+		return pt.sectionEnd(ms[len(ms)-1])
+	}
+	for j := i + 1; j < len(scopes); j++ {
+		if scopes[j].Preamble {
+			continue
+		}
+		if ms := matchesFor(parent, &scopes[j]); len(ms) > 0 {
+			return ms[0].Heading.Line
+		}
+	}
+	return end
+}
+
+// scopes patches the scope list under parent (heading at anchor,
+// section ending before end) against obj.
+func (pt *patcher) scopes(
+	scopes []schema.Scope, level int, obj *dataObj,
+	parent *schema.ScopeMatch, anchor, end int,
+) error {
+	for i := range scopes {
+		sc := &scopes[i]
+		if isBroadScope(sc) {
+			continue
+		}
+		ms := matchesFor(parent, sc)
+		if sc.Preamble {
+			var sm *schema.ScopeMatch
+			if len(ms) > 0 {
+				sm = ms[0]
+			}
+			if err := pt.content(sc, obj, sm, anchor); err != nil {
+				return err
+			}
+			continue
+		}
+		at := pt.insertPoint(scopes, i, parent, end)
+		if sc.Bind != nil && *sc.Bind == "" {
+			if len(ms) == 0 {
+				if err := pt.insertSection(at, sc, level, obj, 1); err != nil {
+					return err
+				}
+				continue
+			}
+			if err := pt.section(sc, obj, ms[0], 1); err != nil {
+				return err
+			}
+			continue
+		}
+		key := keyFor(sc)
+		v, ok := obj.take(key)
+		path := obj.keyPath(key)
+		if isRepeating(sc) {
+			var arr []any
+			if ok {
+				if arr, ok = v.([]any); !ok {
+					return fmt.Errorf("%s: want an array, got %T", path, v)
+				}
+			}
+			for idx, el := range arr {
+				ep := fmt.Sprintf("%s[%d]", path, idx)
+				if idx >= len(ms) {
+					if err := pt.insertElement(at, sc, level, ep, el, idx+1); err != nil {
+						return err
+					}
+					continue
+				}
+				if err := pt.element(sc, ep, el, ms[idx], idx+1); err != nil {
+					return err
+				}
+			}
+			for _, sm := range ms[min(len(arr), len(ms)):] {
+				pt.removeSection(sm)
+			}
+			continue
+		}
+		switch {
+		case !ok:
+			if len(ms) > 0 && !sc.Required() {
+				pt.removeSection(ms[0])
+			}
+		case len(ms) == 0:
+			if err := pt.insertElement(at, sc, level, path, v, 1); err != nil {
+				return err
+			}
+		default:
+			if err := pt.element(sc, path, v, ms[0], 1); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// element patches one matched occurrence from its own object.
+func (pt *patcher) element(
+	sc *schema.Scope, path string, v any, sm *schema.ScopeMatch, ordinal int,
+) error {
+	child, err := objectAt(path, v)
+	if err != nil {
+		return err
+	}
+	if err := pt.section(sc, child, sm, ordinal); err != nil {
+		return err
+	}
+	return child.leftovers()
+}
+
+// insertElement renders a new occurrence and inserts it before line.
+func (pt *patcher) insertElement(
+	line int, sc *schema.Scope, level int, path string, v any, ordinal int,
+) error {
+	r := &renderer{sch: pt.sch, fm: pt.r.fm}
+	if err := r.element(sc, level, path, v, ordinal); err != nil {
+		return err
+	}
+	pt.insertBefore(line, strings.TrimSuffix(string(r.body()), "\n"))
+	return nil
+}
+
+// insertSection is insertElement for a hoisted scope, whose keys live
+// in the parent object.
+func (pt *patcher) insertSection(line int, sc *schema.Scope, level int, o *dataObj, ordinal int) error {
+	r := &renderer{sch: pt.sch, fm: pt.r.fm}
+	if err := r.section(sc, level, o, ordinal); err != nil {
+		return err
+	}
+	pt.insertBefore(line, strings.TrimSuffix(string(r.body()), "\n"))
+	return nil
+}
+
+// section patches a matched scope: its heading captures, then its
+// body the way Render would write it.
+func (pt *patcher) section(sc *schema.Scope, o *dataObj, sm *schema.ScopeMatch, ordinal int) error {
+	vals := pt.r.headingVals(sc, o, ordinal)
+	changed := false
+	for k, v := range vals {
+		if sm.Captures[k] != v {
+			changed = true
+		}
+	}
+	if changed {
+		text, ok := schema.HeadingText(sc, vals)
+		if !ok {
+			return fmt.Errorf("%s: cannot render a heading for %q from the data",
+				pathOrRoot(o.path), sc.Heading)
+		}
+		if err := pt.heading(sm.Heading.Line, sm.Heading.Level, text); err != nil {
+			return err
+		}
+	}
+	end := pt.sectionEnd(sm)
+	if pt.r.projectsBlocks(sc) {
+		if v, ok := o.take("blocks"); ok && len(sc.Content) == 0 && len(sc.Sections) == 0 {
+			cur := pt.p.blocksFromNodes(sm.Body, pt.p.inlineBlockParagraphs(sc))
+			if sameValue(v, cur) {
+				return nil
+			}
+			chunks, err := renderBlocks(o.keyPath("blocks"), v)
+			if err != nil {
+				return err
+			}
+			body := []string{""}
+			if len(chunks) > 0 {
+				body = append(body, strings.Split(strings.Join(chunks, "\n\n"), "\n")...)
+				body = append(body, "")
+			}
+			pt.edits = append(pt.edits, lineEdit{sm.Heading.Line + 1, end, body})
+			return nil
+		}
+	}
+	if err := pt.content(sc, o, sm, sm.Heading.Line); err != nil {
+		return err
+	}
+	return pt.scopes(sc.Sections, sm.Heading.Level+1, o, sm, sm.Heading.Line, end)
+}
+
+// content patches a scope's declared content entries. sm is nil for
+// an unmatched preamble; anchor is the line new entries follow when
+// no earlier entry matched.
+func (pt *patcher) content(sc *schema.Scope, o *dataObj, sm *schema.ScopeMatch, anchor int) error {
+	cur := map[string]any{}
+	curKey := map[*schema.ContentEntry]string{}
+	nodes := map[*schema.ContentEntry]schema.ContentMatch{}
+	if sm != nil {
+		pt.p.projectContent(sm.Content, cur)
+		// Matched entries are keyed as projectContent keys them:
+		// every match advances its base's counter.
+		counts := contentKeys{}
+		for _, cm := range sm.Content {
+			nodes[cm.Entry] = cm
+			base := contentBaseKey(cm.Entry)
+			curKey[cm.Entry] = counts.peek(base)
+			counts[base]++
+		}
+	}
+	keys := newContentKeys()
+	last := anchor
+	for i := range sc.Content {
+		e := &sc.Content[i]
+		if e.Kind == schema.ContentKindUnlisted {
+			continue
+		}
+		key := keys.claim(e, o)
+		want, has := entryValue(e, o.m, key)
+		cm, matched := nodes[e]
+		switch {
+		case matched && has:
+			start, end := cm.Line, pt.nodeEnd(cm)
+			got, _ := entryValue(e, cur, curKey[e])
+			chunk, _, err := renderEntry(e, o, key)
+			if err != nil {
+				return err
+			}
+			if !sameValue(want, got) {
+				pt.replace(start, end, chunk)
+			}
+			last = end - 1
+		case matched:
+			if e.Required {
+				return fmt.Errorf("%s: missing required %s content", o.keyPath(key), e.Kind)
+			}
+			pt.remove(cm.Line, pt.nextNonBlank(pt.nodeEnd(cm)))
+		case has:
+			chunk, _, err := renderEntry(e, o, key)
+			if err != nil {
+				return err
+			}
+			pt.insertAfter(last, chunk)
+		case e.Required:
+			return fmt.Errorf("%s: missing required %s content", o.keyPath(key), e.Kind)
+		}
+	}
+	return nil
+}
+
+// entryValue returns an entry's value from a projected object: the
+// `columns`/`rows` pair for a `rows` table, else m[key].
+func entryValue(e *schema.ContentEntry, m map[string]any, key string) (any, bool) {
+	if isRowsTable(e) {
+		cols, hasCols := m["columns"]
+		rows, hasRows := m["rows"]
+		return []any{cols, rows}, hasCols || hasRows
+	}
+	v, ok := m[key]
+	return v, ok
+}
+
+// nodeEnd returns the line after a content node. The top-level
+// Layer 0 span that starts on the node's line bounds it; the node's
+// own last line covers a loose list the scan splits at a blank.
+func (pt *patcher) nodeEnd(cm schema.ContentMatch) int {
+	last := cm.Line
+	for _, span := range lint.Layer0(pt.f).BlockSpans {
+		if span.Depth == 0 && span.Start == cm.Line {
+			last = span.End
+			break
+		}
+	}
+	_ = ast.Walk(cm.Node, func(n ast.Node, entering bool) (ast.WalkStatus, error) {
+		if !entering || n.Type() != ast.TypeBlock {
+			return ast.WalkContinue, nil
+		}
+		if segs := n.Lines(); segs.Len() > 0 {
+			last = max(last, pt.f.LineOfOffset(segs.At(segs.Len()-1).Start))
+		}
+		return ast.WalkContinue, nil
+	})
+	return last + 1
+}
+
+// unlisted checks the sections a schema-level `projection: blocks`
+// projects without a declaring scope. They have no schema position
+// to patch into, so any change is an error.
+func (pt *patcher) unlisted(root *dataObj, parent *schema.ScopeMatch) error {
+	if pt.sch.Projection != schema.ProjectionBlocks {
+		return nil
+	}
+	var run []*schema.ScopeMatch
+	for _, sm := range parent.Children {
+		if sm.Unlisted {
+			run = append(run, sm)
+		}
+	}
+	cur := map[string]any{}
+	pt.p.projectUnlisted(run, cur)
+	keys := make([]string, 0, len(cur))
+	for k := range cur {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	for _, k := range keys {
+		if v, ok := root.take(k); !ok || !sameValue(v, cur[k]) {
+			return fmt.Errorf("%s: sections no schema scope declares cannot be patched; "+
+				"edit them in the Markdown", k)
+		}
+	}
+	return nil
+}
+
+// patchFrontMatter applies want to the front matter block raw
+// (delimiters included) on its YAML node tree: changed values are
+// replaced in place, removed keys dropped, and new keys appended in
+// sorted order. Comments on untouched keys survive. raw is returned
+// unchanged when it already decodes to want; a file without front
+// matter gains a new block. raw is decoded afresh rather than taken
+// from the match tree, whose map the extracted record shares.
+func patchFrontMatter(raw []byte, want map[string]any) ([]byte, error) {
+	if len(raw) == 0 {
+		if len(want) == 0 {
+			return nil, nil
+		}
+		y, err := marshalFrontMatter(want)
+		if err != nil {
+			return nil, err
+		}
+		return append(append([]byte("---\n"), y...), "---\n"...), nil
+	}
+	cur, err := lint.ParseFrontMatterFields(raw)
+	if err != nil {
+		return nil, fmt.Errorf("frontmatter: %w", err)
+	}
+	if cur == nil {
+		cur = map[string]any{}
+	}
+	if sameValue(cur, want) {
+		return raw, nil
+	}
+	delim := []byte("---\n")
+	body := bytes.TrimSuffix(bytes.TrimPrefix(raw, delim), delim)
+	doc, err := yamlutil.UnmarshalNodeSafe(body)
+	if err != nil {
+		return nil, fmt.Errorf("frontmatter: %w", err)
+	}
+	if len(doc.Content) == 0 {
+		doc = yaml.Node{Kind: yaml.DocumentNode,
+			Content: []*yaml.Node{{Kind: yaml.MappingNode, Tag: "!!map"}}}
+	}
+	m := doc.Content[0]
+	if m.Kind != yaml.MappingNode {
+		return nil, fmt.Errorf("frontmatter: want a mapping")
+	}
+	seen := map[string]bool{}
+	kept := m.Content[:0]
+	for i := 0; i+1 < len(m.Content); i += 2 {
+		k, v := m.Content[i], m.Content[i+1]
+		nv, ok := want[k.Value]
+		if !ok {
+			continue
+		}
+		seen[k.Value] = true
+		if !sameValue(cur[k.Value], nv) {
+			var n yaml.Node
+			if err := n.Encode(nv); err != nil {
+				return nil, fmt.Errorf("frontmatter.%s: %w", k.Value, err)
+			}
+			n.LineComment = v.LineComment
+			v = &n
+		}
+		kept = append(kept, k, v)
+	}
+	m.Content = kept
+	added := make([]string, 0, len(want))
+	for k := range want {
+		if !seen[k] {
+			added = append(added, k)
+		}
+	}
+	sort.Strings(added)
+	for _, k := range added {
+		var n yaml.Node
+		if err := n.Encode(want[k]); err != nil {
+			return nil, fmt.Errorf("frontmatter.%s: %w", k, err)
+		}
+		m.Content = append(m.Content,
+			&yaml.Node{Kind: yaml.ScalarNode, Tag: "!!str", Value: k}, &n)
+	}
+	var buf bytes.Buffer
+	enc := yaml.NewEncoder(&buf)
+	enc.SetIndent(2)
+	if err := enc.Encode(&doc); err != nil {
+		return nil, fmt.Errorf("frontmatter: %w", err)
+	}
+	if err := enc.Close(); err != nil {
+		return nil, fmt.Errorf("frontmatter: %w", err)
+	}
+	return append(append([]byte("---\n"), buf.Bytes()...), delim...), nil
+}
@@ -0,0 +1,274 @@
+package extract
+
+import (
+	"testing"
+
+	"github.com/jeduden/mdsmith/internal/lint"
+	"github.com/jeduden/mdsmith/internal/schema"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// patchDoc extracts src, lets edit change the record, patches src
+// with it, and checks the result round-trips.
+func patchDoc(
+	t *testing.T, sch *schema.Schema, src string, edit func(root map[string]any),
+) string {
+	t.Helper()
+	f, err := lint.NewFileFromSource("doc.md", []byte(src), true)
+	require.NoError(t, err)
+	fm, err := lint.ParseFrontMatterFields(f.FrontMatter)
+	require.NoError(t, err)
+	mt := schema.BuildMatchTree(f, sch, fm)
+	cur, diags := Extract(f, sch, mt)
+	require.Empty(t, diags)
+	data := cur.(map[string]any)
+	edit(data)
+	out, err := Patch(f, sch, mt, data)
+	require.NoError(t, err)
+	assert.Equal(t, "", Mismatch(data, reextract(t, sch, out)))
+	return string(out)
+}
+
+const recipeDoc = `---
+id: r1 # stable
+tags: [quick]
+---
+
+# Pancakes
+
+## Ingredients
+
+Serves 2, more if hungry.
+
+- flour
+- milk
+
+A note the schema does not declare.
+
+## Step 1
+
+` + "```sh\nmix\n```" + `
+
+## Step 2
+
+` + "```sh\nfry\n```" + `
+`
+
+func TestPatch_NoChangeIsIdentity(t *testing.T) {
+	out := patchDoc(t, recipeSchema(), recipeDoc, func(map[string]any) {})
+	assert.Equal(t, recipeDoc, out)
+}
+
+func TestPatch_ChangesOnlyEditedValues(t *testing.T) {
+	out := patchDoc(t, recipeSchema(), recipeDoc, func(root map[string]any) {
+		root["frontmatter"].(map[string]any)["id"] = "r2"
+		root["title"] = "Crepes"
+		ing := root["ingredients"].(map[string]any)
+		ing["items"] = []any{"flour", "milk", "eggs"}
+	})
+	assert.Equal(t, `---
+id: r2 # stable
+tags: [quick]
+---
+
+# Crepes
+
+## Ingredients
+
+Serves 2, more if hungry.
+
+- flour
+- milk
+- eggs
+
+A note the schema does not declare.
+
+## Step 1
+
+`+"```sh\nmix\n```"+`
+
+## Step 2
+
+`+"```sh\nfry\n```"+`
+`, out)
+}
+
+func TestPatch_RepeatingAndOptional(t *testing.T) {
+	// Drop step 2, add a third-party step, and add the optional
+	// Nutrition section with its table.
+	out := patchDoc(t, recipeSchema(), recipeDoc, func(root map[string]any) {
+		root["step"] = []any{
+			map[string]any{"n": "1", "code": "whisk"},
+		}
+		root["nutrition"] = []any{map[string]any{
+			"rows": []any{map[string]any{"kcal": "300"}},
+		}}
+		delete(root["ingredients"].(map[string]any), "text")
+	})
+	assert.Equal(t, `---
+id: r1 # stable
+tags: [quick]
+---
+
+# Pancakes
+
+## Ingredients
+
+- flour
+- milk
+
+A note the schema does not declare.
+
+## Step 1
+
+`+"```sh\nwhisk\n```"+`
+
+## Nutrition
+
+| kcal |
+| --- |
+| 300 |
+`, out)
+
+	out = patchDoc(t, recipeSchema(), recipeDoc, func(root map[string]any) {
+		steps := root["step"].([]any)
+		root["step"] = append(steps, map[string]any{"code": "serve"})
+		root["ingredients"].(map[string]any)["text"] = "Serves 4."
+	})
+	assert.Contains(t, out, "## Ingredients\n\nServes 4.\n\n- flour")
+	assert.Contains(t, out, "```sh\nfry\n```\n\n## Step 3\n\n```sh\nserve\n```\n")
+}
+
+func TestPatch_InsertsMissingOptionalContent(t *testing.T) {
+	src := "## Ingredients\n\n- flour\n\n## Step 1\n\n```sh\nmix\n```\n"
+	out := patchDoc(t, recipeSchema(), src, func(root map[string]any) {
+		root["ingredients"].(map[string]any)["text"] = "Serves 2."
+		root["frontmatter"] = map[string]any{"id": "new"}
+	})
+	assert.Equal(t, "---\nid: new\n---\n## Ingredients\n\nServes 2.\n\n- flour\n\n"+
+		"## Step 1\n\n```sh\nmix\n```\n", out)
+}
+
+func TestPatch_Captures(t *testing.T) {
+	sch := &schema.Schema{RootLevel: 2, Sections: []schema.Scope{{
+		Heading: "{id}",
+		Matcher: &schema.Matcher{Regex: `\#(fmvar(id)) notes`},
+		Content: []schema.ContentEntry{{Kind: schema.ContentKindParagraph, Required: true}},
+	}}}
+	src := "---\nid: A-1\n---\n\n## A-1 notes\n\nBody.\n"
+	out := patchDoc(t, sch, src, func(root map[string]any) {
+		root["frontmatter"] = map[string]any{"id": "B-2"}
+		notes := root["notes"].(map[string]any)
+		notes["id"] = "B-2"
+	})
+	assert.Equal(t, "---\nid: B-2\n---\n\n## B-2 notes\n\nBody.\n", out)
+}
+
+func TestPatch_BlocksBody(t *testing.T) {
+	src := "## Notes\n\nOld.\n\n## Extra\n\nKeep.\n"
+	sch := schemaBlocksDefault("Notes")
+	out := patchDoc(t, sch, src, func(root map[string]any) {
+		root["notes"].(map[string]any)["blocks"] = []any{
+			map[string]any{"block": "paragraph", "text": "New."},
+			map[string]any{"block": "break"},
+		}
+	})
+	assert.Equal(t, "## Notes\n\nNew.\n\n---\n\n## Extra\n\nKeep.\n", out)
+}
+
+func TestPatch_Errors(t *testing.T) {
+	f, err := lint.NewFileFromSource("doc.md", []byte(recipeDoc), true)
+	require.NoError(t, err)
+	fm, err := lint.ParseFrontMatterFields(f.FrontMatter)
+	require.NoError(t, err)
+	sch := recipeSchema()
+	mt := schema.BuildMatchTree(f, sch, fm)
+
+	_, err = Patch(f, sch, mt, map[string]any{
+		"ingredients": map[string]any{"items": []any{"a"}, "bogus": true},
+	})
+	assert.ErrorContains(t, err, "ingredients.bogus: no schema section")
+
+	_, err = Patch(f, sch, mt, map[string]any{"ingredients": map[string]any{}})
+	assert.ErrorContains(t, err, "ingredients.items: missing required list content")
+
+	_, err = Patch(f, sch, mt, map[string]any{"frontmatter": []any{}})
+	assert.ErrorContains(t, err, "frontmatter: want an object")
+
+	blocks := schemaBlocksDefault("Notes")
+	bf, err := lint.NewFileFromSource("doc.md", []byte("## Notes\n\nx\n\n## Extra\n\ny\n"), true)
+	require.NoError(t, err)
+	_, err = Patch(bf, blocks, schema.BuildMatchTree(bf, blocks, nil), map[string]any{
+		"notes": map[string]any{"blocks": []any{}},
+		"extra": map[string]any{"heading": "Extra", "blocks": []any{}},
+	})
+	assert.ErrorContains(t, err, "extra: sections no schema scope declares cannot be patched")
+}
+
+func TestPatch_HoistedAndRowsTable(t *testing.T) {
+	sch := &schema.Schema{RootLevel: 2, Sections: []schema.Scope{
+		litScope("Goal"),
+		{
+			Heading: "Meta",
+			Matcher: &schema.Matcher{
+				Regex:  "Meta",
+				Repeat: schema.Repeat{Set: true, Min: 0, Max: 1},
+			},
+			Bind: strPtr(""),
+			Content: []schema.ContentEntry{
+				{Kind: schema.ContentKindTable, Required: true, Projection: schema.ProjectionRows},
+			},
+		},
+	}}
+	src := "## Goal\n\nx\n"
+	out := patchDoc(t, sch, src, func(root map[string]any) {
+		root["columns"] = []any{"K"}
+		root["rows"] = []any{[]any{"v"}}
+	})
+	assert.Equal(t, "## Goal\n\nx\n\n## Meta\n\n| K |\n| --- |\n| v |\n", out)
+
+	out = patchDoc(t, sch, out, func(root map[string]any) {
+		root["rows"] = []any{[]any{"w"}}
+	})
+	assert.Equal(t, "## Goal\n\nx\n\n## Meta\n\n| K |\n| --- |\n| w |\n", out)
+}
+
+func TestPatch_FrontMatterKeysAddedAndRemoved(t *testing.T) {
+	raw := []byte("---\n# lead comment\nb: 1\na: 2\n---\n")
+	out, err := patchFrontMatter(raw, map[string]any{"b": 1, "c": "new"})
+	require.NoError(t, err)
+	assert.Equal(t, "---\n# lead comment\nb: 1\nc: new\n---\n", string(out))
+
+	out, err = patchFrontMatter(raw, map[string]any{"b": 1, "a": 2})
+	require.NoError(t, err)
+	assert.Equal(t, string(raw), string(out))
+
+	out, err = patchFrontMatter(nil, nil)
+	require.NoError(t, err)
+	assert.Nil(t, out)
+
+	out, err = patchFrontMatter([]byte("---\n---\n"), map[string]any{"a": 1})
+	require.NoError(t, err)
+	assert.Equal(t, "---\na: 1\n---\n", string(out))
+
+	_, err = patchFrontMatter([]byte("---\n- x\n---\n"), map[string]any{"a": 1})
+	assert.ErrorContains(t, err, "frontmatter:")
+}
+
+func TestPatch_TitleInsertedAndSetextRefused(t *testing.T) {
+	out := patchDoc(t, recipeSchema(), "## Ingredients\n\n- a\n\n## Step 1\n\n```sh\nx\n```\n",
+		func(root map[string]any) { root["title"] = "New" })
+	assert.Equal(t, "# New\n\n## Ingredients\n\n- a\n\n## Step 1\n\n```sh\nx\n```\n", out)
+
+	src := "Old\n===\n\n## Ingredients\n\n- a\n\n## Step 1\n\n```sh\nx\n```\n"
+	f, err := lint.NewFileFromSource("doc.md", []byte(src), true)
+	require.NoError(t, err)
+	sch := recipeSchema()
+	_, err = Patch(f, sch, schema.BuildMatchTree(f, sch, nil), map[string]any{
+		"title":       "New",
+		"ingredients": map[string]any{"items": []any{"a"}},
+		"step":        []any{map[string]any{"code": "x"}},
+	})
+	assert.ErrorContains(t, err, "line 1: cannot rewrite a setext heading")
+}
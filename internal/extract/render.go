@@ -0,0 +1,831 @@
+package extract
+
+import (
+	"bytes"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/jeduden/mdsmith/internal/fieldinterp"
+	"github.com/jeduden/mdsmith/internal/schema"
+	"gopkg.in/yaml.v3"
+)
+
+// Render is the inverse of Extract: it builds a Markdown document
+// whose projection against sch is data. Keys resolve through the
+// same seams Extract uses (keyFor, contentBaseKey), so a record
+// emitted by `mdsmith extract` renders back to a conforming file.
+//
+// Headings come from each scope's matcher via schema.HeadingText: a
+// literal heading renders as-is, a `digits` heading takes the
+// element's `n` (or its 1-based position), and an fmvar heading
+// takes the field from the element or the front matter. Text values
+// are written verbatim — extract keeps backslash escapes and other
+// inline syntax in its plain text, so writing them back unescaped is
+// what round-trips. A required section absent from data renders as
+// its bare heading; a key no scope or content entry binds is an
+// error naming its path.
+//
+// Render does not guarantee conformance on its own (a list can fall
+// short of min-items, a text value can start with a list marker).
+// Callers gate the output on MDS020 and compare a fresh Extract
+// against data with Mismatch.
+func Render(sch *schema.Schema, data map[string]any) ([]byte, error) {
+	r := &renderer{sch: sch}
+	root := newDataObj(data, "")
+	var head []byte
+	if v, ok := root.take("frontmatter"); ok && v != nil {
+		fm, isMap := v.(map[string]any)
+		if !isMap {
+			return nil, fmt.Errorf("frontmatter: want an object, got %T", v)
+		}
+		r.fm = fm
+		if len(fm) > 0 {
+			y, err := marshalFrontMatter(fm)
+			if err != nil {
+				return nil, err
+			}
+			head = append([]byte("---\n"), y...)
+			head = append(head, "---\n"...)
+		}
+	}
+	if sch.EffectiveRootLevel() == 2 {
+		if v, ok := root.take("title"); ok {
+			title, err := scalarText(root.keyPath("title"), v)
+			if err != nil {
+				return nil, err
+			}
+			r.emit("# " + title)
+		}
+	}
+	if err := r.scopes(sch.Sections, sch.EffectiveRootLevel(), root); err != nil {
+		return nil, err
+	}
+	if err := r.unlisted(root); err != nil {
+		return nil, err
+	}
+	if err := root.leftovers(); err != nil {
+		return nil, err
+	}
+	return append(head, r.body()...), nil
+}
+
+// renderer accumulates a document as blank-line-separated chunks.
+// fm is the record's front matter, the fallback source for fmvar
+// heading placeholders an element does not carry itself.
+type renderer struct {
+	sch    *schema.Schema
+	fm     map[string]any
+	chunks []string
+}
+
+func (r *renderer) emit(chunk string) {
+	r.chunks = append(r.chunks, chunk)
+}
+
+// body joins the chunks into the document text, one blank line
+// between blocks and a single trailing newline.
+func (r *renderer) body() []byte {
+	if len(r.chunks) == 0 {
+		return nil
+	}
+	return []byte(strings.Join(r.chunks, "\n\n") + "\n")
+}
+
+// dataObj is one object of the input record with the set of keys a
+// scope, capture, or content entry has consumed, so leftovers can
+// report keys nothing in the schema binds.
+type dataObj struct {
+	m    map[string]any
+	used map[string]bool
+	path string
+}
+
+func newDataObj(m map[string]any, path string) *dataObj {
+	return &dataObj{m: m, used: map[string]bool{}, path: path}
+}
+
+// take returns the value under key and marks it consumed.
+func (o *dataObj) take(key string) (any, bool) {
+	v, ok := o.m[key]
+	if ok {
+		o.used[key] = true
+	}
+	return v, ok
+}
+
+// has reports whether key is present without consuming it.
+func (o *dataObj) has(key string) bool {
+	_, ok := o.m[key]
+	return ok
+}
+
+func (o *dataObj) keyPath(key string) string {
+	if o.path == "" {
+		return key
+	}
+	return o.path + "." + key
+}
+
+// leftovers reports the first (sorted) key nothing consumed.
+func (o *dataObj) leftovers() error {
+	keys := make([]string, 0, len(o.m))
+	for k := range o.m {
+		if !o.used[k] {
+			keys = append(keys, k)
+		}
+	}
+	if len(keys) == 0 {
+		return nil
+	}
+	sort.Strings(keys)
+	return fmt.Errorf("%s: no schema section or content entry binds this key",
+		o.keyPath(keys[0]))
+}
+
+// objectAt wraps v, found at path, as the object for a nested scope.
+func objectAt(path string, v any) (*dataObj, error) {
+	m, ok := v.(map[string]any)
+	if !ok {
+		return nil, fmt.Errorf("%s: want an object, got %T", path, v)
+	}
+	return newDataObj(m, path), nil
+}
+
+// isBroadScope reports whether sc claims any heading (`.+`). Like
+// buildScopeMatches, rendering skips those scopes: there is no
+// heading text to write and extract never projects them.
+func isBroadScope(sc *schema.Scope) bool {
+	return sc.Matcher != nil && sc.Matcher.Regex == ".+"
+}
+
+// scopes renders the scope list at heading level into obj's
+// document region, in schema order.
+func (r *renderer) scopes(scopes []schema.Scope, level int, obj *dataObj) error {
+	for i := range scopes {
+		sc := &scopes[i]
+		if isBroadScope(sc) {
+			continue
+		}
+		if sc.Preamble {
+			if err := r.content(sc, obj); err != nil {
+				return err
+			}
+			continue
+		}
+		if sc.Bind != nil && *sc.Bind == "" {
+			if err := r.section(sc, level, obj, 1); err != nil {
+				return err
+			}
+			continue
+		}
+		key := keyFor(sc)
+		v, ok := obj.take(key)
+		if isRepeating(sc) {
+			if !ok {
+				continue
+			}
+			arr, isArr := v.([]any)
+			if !isArr {
+				return fmt.Errorf("%s: want an array, got %T", obj.keyPath(key), v)
+			}
+			for idx, el := range arr {
+				path := fmt.Sprintf("%s[%d]", obj.keyPath(key), idx)
+				if err := r.element(sc, level, path, el, idx+1); err != nil {
+					return err
+				}
+			}
+			continue
+		}
+		if !ok {
+			if !sc.Required() {
+				continue
+			}
+			v = map[string]any{}
+		}
+		if err := r.element(sc, level, obj.keyPath(key), v, 1); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// element renders one scope occurrence from its own object.
+func (r *renderer) element(sc *schema.Scope, level int, path string, v any, ordinal int) error {
+	child, err := objectAt(path, v)
+	if err != nil {
+		return err
+	}
+	if err := r.section(sc, level, child, ordinal); err != nil {
+		return err
+	}
+	return child.leftovers()
+}
+
+// section renders a scope's heading, then its body: the declared
+// content and child scopes, or — for a `projection: blocks` scope
+// that declares neither — the `blocks` list. When a blocks scope
+// also declares entries, the entries win and `blocks` (a second
+// view of the same body) is consumed unread.
+func (r *renderer) section(sc *schema.Scope, level int, o *dataObj, ordinal int) error {
+	text, ok := schema.HeadingText(sc, r.headingVals(sc, o, ordinal))
+	if !ok {
+		return fmt.Errorf("%s: cannot render a heading for %q from the data",
+			pathOrRoot(o.path), sc.Heading)
+	}
+	r.emit(strings.Repeat("#", level) + " " + text)
+	if r.projectsBlocks(sc) {
+		if v, ok := o.take("blocks"); ok && len(sc.Content) == 0 && len(sc.Sections) == 0 {
+			chunks, err := renderBlocks(o.keyPath("blocks"), v)
+			if err != nil {
+				return err
+			}
+			r.chunks = append(r.chunks, chunks...)
+			return nil
+		}
+	}
+	if err := r.content(sc, o); err != nil {
+		return err
+	}
+	return r.scopes(sc.Sections, level+1, o)
+}
+
+func pathOrRoot(path string) string {
+	if path == "" {
+		return "<root>"
+	}
+	return path
+}
+
+// projectsBlocks mirrors BuildMatchTree: a scope's own `projection:
+// blocks` or the schema default when the scope leaves it unset.
+func (r *renderer) projectsBlocks(sc *schema.Scope) bool {
+	return sc.Projection == schema.ProjectionBlocks ||
+		(r.sch.Projection == schema.ProjectionBlocks && sc.Projection == "")
+}
+
+// headingVals gathers the placeholder values a scope's heading
+// needs, consuming the capture keys from o. A missing `n` falls
+// back to the element's 1-based position; a missing fmvar field
+// falls back to the record's front matter.
+func (r *renderer) headingVals(sc *schema.Scope, o *dataObj, ordinal int) map[string]string {
+	_, fmvars, hasDigits := schema.HeadingStem(sc)
+	vals := map[string]string{}
+	if hasDigits {
+		vals["n"] = strconv.Itoa(ordinal)
+		if v, ok := o.take("n"); ok {
+			vals["n"] = fmt.Sprint(v)
+		}
+	}
+	for _, name := range fmvars {
+		if v, ok := o.take(name); ok {
+			vals[name] = fmt.Sprint(v)
+			continue
+		}
+		if v, err := fieldinterp.ResolvePath(r.fm, fieldinterp.ParseCUEPath(name)); err == nil {
+			vals[name] = v
+		}
+	}
+	return vals
+}
+
+// content renders a scope's declared content entries in order.
+func (r *renderer) content(sc *schema.Scope, o *dataObj) error {
+	keys := newContentKeys()
+	for i := range sc.Content {
+		e := &sc.Content[i]
+		if e.Kind == schema.ContentKindUnlisted {
+			continue
+		}
+		chunk, ok, err := renderEntry(e, o, keys.claim(e, o))
+		if err != nil {
+			return err
+		}
+		if !ok {
+			if e.Required {
+				return fmt.Errorf("%s: missing required %s content",
+					o.keyPath(keys.peek(contentBaseKey(e))), e.Kind)
+			}
+			continue
+		}
+		r.emit(chunk)
+	}
+	return nil
+}
+
+// contentKeys numbers repeated content keys the way projectContent
+// does (code, code-2, …). Extract only counts entries that matched a
+// node, so a key is claimed only when the data carries it — an absent
+// optional entry does not shift the numbering of the next one.
+type contentKeys map[string]int
+
+func newContentKeys() contentKeys { return contentKeys{} }
+
+func (c contentKeys) peek(base string) string {
+	if c[base] == 0 {
+		return base
+	}
+	return fmt.Sprintf("%s-%d", base, c[base]+1)
+}
+
+// claim returns e's key in o and advances its counter when o holds
+// a value for it. The `rows` table projection writes the fixed
+// `columns`/`rows` pair and takes no counter.
+func (c contentKeys) claim(e *schema.ContentEntry, o *dataObj) string {
+	if isRowsTable(e) {
+		return "rows"
+	}
+	base := contentBaseKey(e)
+	key := c.peek(base)
+	if o.has(key) {
+		c[base]++
+	}
+	return key
+}
+
+func isRowsTable(e *schema.ContentEntry) bool {
+	return e.Kind == schema.ContentKindTable && e.Projection == schema.ProjectionRows
+}
+
+// renderEntry renders one content entry from o[key]. It reports
+// false when o carries no value for the entry.
+func renderEntry(e *schema.ContentEntry, o *dataObj, key string) (string, bool, error) {
+	if isRowsTable(e) {
+		if !o.has("columns") && !o.has("rows") {
+			return "", false, nil
+		}
+		cols, _ := o.take("columns")
+		rows, _ := o.take("rows")
+		s, err := renderRowsTable(o.keyPath("rows"), cols, rows)
+		return s, true, err
+	}
+	v, ok := o.take(key)
+	if !ok {
+		return "", false, nil
+	}
+	path := o.keyPath(key)
+	var s string
+	var err error
+	switch e.Kind {
+	case schema.ContentKindCodeBlock:
+		var body string
+		if body, err = scalarText(path, v); err == nil {
+			s = renderFence(e.Lang, body)
+		}
+	case schema.ContentKindList:
+		ordered := e.OrderedSet && e.Ordered
+		if e.Projection == schema.ProjectionTree {
+			s, err = renderTreeList(path, v, ordered)
+		} else {
+			s, err = renderFlatList(path, v, ordered)
+		}
+	case schema.ContentKindTable:
+		s, err = renderRecordsTable(path, v, e.Columns)
+	case schema.ContentKindParagraph:
+		if e.Projection == schema.ProjectionInline {
+			s, err = renderSpans(path, v)
+		} else {
+			s, err = scalarText(path, v)
+		}
+	}
+	return s, true, err
+}
+
+// unlisted renders the root `{heading, blocks}` objects a
+// schema-level `projection: blocks` extracts for sections no scope
+// declares. Map order is lost in the data, so they render in key
+// order after the declared sections.
+func (r *renderer) unlisted(root *dataObj) error {
+	if r.sch.Projection != schema.ProjectionBlocks {
+		return nil
+	}
+	keys := make([]string, 0, len(root.m))
+	for k := range root.m {
+		if !root.used[k] {
+			keys = append(keys, k)
+		}
+	}
+	sort.Strings(keys)
+	level := r.sch.EffectiveRootLevel()
+	for _, k := range keys {
+		items := []any{root.m[k]}
+		if arr, ok := root.m[k].([]any); ok {
+			items = arr
+		}
+		for _, it := range items {
+			m, ok := it.(map[string]any)
+			if !ok || m["heading"] == nil || m["blocks"] == nil {
+				// Not an unlisted section; leftovers reports it.
+				break
+			}
+			root.used[k] = true
+			heading, err := scalarText(root.keyPath(k)+".heading", m["heading"])
+			if err != nil {
+				return err
+			}
+			r.emit(strings.Repeat("#", level) + " " + heading)
+			chunks, err := renderBlocks(root.keyPath(k)+".blocks", m["blocks"])
+			if err != nil {
+				return err
+			}
+			r.chunks = append(r.chunks, chunks...)
+		}
+	}
+	return nil
+}
+
+// marshalFrontMatter encodes fm as block YAML, keys sorted, two-space
+// indent.
+func marshalFrontMatter(fm map[string]any) ([]byte, error) {
+	var buf bytes.Buffer
+	enc := yaml.NewEncoder(&buf)
+	enc.SetIndent(2)
+	if err := enc.Encode(fm); err != nil {
+		return nil, fmt.Errorf("frontmatter: %w", err)
+	}
+	if err := enc.Close(); err != nil {
+		return nil, fmt.Errorf("frontmatter: %w", err)
+	}
+	return buf.Bytes(), nil
+}
+
+// scalarText renders a scalar value as text. Numbers and booleans
+// print as Go formats them; objects and arrays are an error.
+func scalarText(path string, v any) (string, error) {
+	switch s := v.(type) {
+	case string:
+		return s, nil
+	case nil:
+		return "", nil
+	case map[string]any, []any:
+		return "", fmt.Errorf("%s: want a string, got %T", path, v)
+	}
+	return fmt.Sprint(v), nil
+}
+
+// renderFence wraps body in a backtick fence one tick longer than
+// any backtick run that opens a body line, so the body cannot close
+// it early.
+func renderFence(lang, body string) string {
+	n := 3
+	for _, line := range strings.Split(body, "\n") {
+		t := strings.TrimLeft(line, " ")
+		run := len(t) - len(strings.TrimLeft(t, "`"))
+		if run >= n {
+			n = run + 1
+		}
+	}
+	fence := strings.Repeat("`", n)
+	return fence + lang + "\n" + body + "\n" + fence
+}
+
+func listMarker(ordered bool, i int) string {
+	if ordered {
+		return strconv.Itoa(i+1) + ". "
+	}
+	return "- "
+}
+
+func renderFlatList(path string, v any, ordered bool) (string, error) {
+	items, ok := v.([]any)
+	if !ok {
+		return "", fmt.Errorf("%s: want an array, got %T", path, v)
+	}
+	if len(items) == 0 {
+		return "", fmt.Errorf("%s: a list needs at least one item", path)
+	}
+	lines := make([]string, 0, len(items))
+	for i, it := range items {
+		text, err := scalarText(fmt.Sprintf("%s[%d]", path, i), it)
+		if err != nil {
+			return "", err
+		}
+		lines = append(lines, listMarker(ordered, i)+text)
+	}
+	return strings.Join(lines, "\n"), nil
+}
+
+// renderTreeList renders the `projection: tree` item objects: a
+// `checked` bool becomes a task marker and `children` nest under the
+// item, indented to its content column.
+func renderTreeList(path string, v any, ordered bool) (string, error) {
+	items, ok := v.([]any)
+	if !ok {
+		return "", fmt.Errorf("%s: want an array, got %T", path, v)
+	}
+	if len(items) == 0 {
+		return "", fmt.Errorf("%s: a list needs at least one item", path)
+	}
+	var b strings.Builder
+	if err := writeTreeItems(&b, path, items, "", ordered); err != nil {
+		return "", err
+	}
+	return strings.TrimSuffix(b.String(), "\n"), nil
+}
+
+func writeTreeItems(b *strings.Builder, path string, items []any, indent string, ordered bool) error {
+	for i, it := range items {
+		ip := fmt.Sprintf("%s[%d]", path, i)
+		m, ok := it.(map[string]any)
+		if !ok {
+			return fmt.Errorf("%s: want an object, got %T", ip, it)
+		}
+		text, err := scalarText(ip+".text", m["text"])
+		if err != nil {
+			return err
+		}
+		marker := listMarker(ordered, i)
+		b.WriteString(indent + marker)
+		if checked, ok := m["checked"].(bool); ok {
+			if checked {
+				b.WriteString("[x] ")
+			} else {
+				b.WriteString("[ ] ")
+			}
+		}
+		b.WriteString(text + "\n")
+		if kids, ok := m["children"].([]any); ok && len(kids) > 0 {
+			sub := indent + strings.Repeat(" ", len(marker))
+			if err := writeTreeItems(b, ip+".children", kids, sub, false); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// renderRecordsTable renders the default table projection: an array
+// of row objects keyed by column header. The header comes from the
+// entry's declared columns, else the sorted union of row keys.
+func renderRecordsTable(path string, v any, columns []string) (string, error) {
+	rows, ok := v.([]any)
+	if !ok && v != nil {
+		return "", fmt.Errorf("%s: want an array, got %T", path, v)
+	}
+	cols := columns
+	if len(cols) == 0 {
+		seen := map[string]bool{}
+		for _, row := range rows {
+			if m, ok := row.(map[string]any); ok {
+				for k := range m {
+					if !seen[k] {
+						seen[k] = true
+						cols = append(cols, k)
+					}
+				}
+			}
+		}
+		sort.Strings(cols)
+	}
+	if len(cols) == 0 {
+		return "", fmt.Errorf("%s: a table needs columns or at least one row", path)
+	}
+	cells := make([][]string, 0, len(rows))
+	for i, row := range rows {
+		rp := fmt.Sprintf("%s[%d]", path, i)
+		m, ok := row.(map[string]any)
+		if !ok {
+			return "", fmt.Errorf("%s: want an object, got %T", rp, row)
+		}
+		line := make([]string, len(cols))
+		for j, c := range cols {
+			s, err := scalarText(rp+"."+c, m[c])
+			if err != nil {
+				return "", err
+			}
+			line[j] = s
+		}
+		cells = append(cells, line)
+	}
+	return renderTable(cols, cells), nil
+}
+
+// renderRowsTable renders the positional `rows` projection.
+func renderRowsTable(path string, colsV, rowsV any) (string, error) {
+	cols, err := stringList(path+".columns", colsV)
+	if err != nil {
+		return "", err
+	}
+	if len(cols) == 0 {
+		return "", fmt.Errorf("%s: a table needs at least one column", path)
+	}
+	rowList, ok := rowsV.([]any)
+	if !ok && rowsV != nil {
+		return "", fmt.Errorf("%s: want an array, got %T", path, rowsV)
+	}
+	cells := make([][]string, 0, len(rowList))
+	for i, row := range rowList {
+		line, err := stringList(fmt.Sprintf("%s[%d]", path, i), row)
+		if err != nil {
+			return "", err
+		}
+		cells = append(cells, line)
+	}
+	return renderTable(cols, cells), nil
+}
+
+func stringList(path string, v any) ([]string, error) {
+	arr, ok := v.([]any)
+	if !ok && v != nil {
+		return nil, fmt.Errorf("%s: want an array, got %T", path, v)
+	}
+	out := make([]string, 0, len(arr))
+	for i, it := range arr {
+		s, err := scalarText(fmt.Sprintf("%s[%d]", path, i), it)
+		if err != nil {
+			return nil, err
+		}
+		out = append(out, s)
+	}
+	return out, nil
+}
+
+// renderTable writes a GFM pipe table. Rows shorter than the header
+// are padded; longer ones are cut, as the parser would cut them.
+func renderTable(cols []string, rows [][]string) string {
+	row := func(cells []string) string {
+		out := make([]string, len(cols))
+		copy(out, cells)
+		return "| " + strings.Join(out, " | ") + " |"
+	}
+	lines := []string{row(cols)}
+	sep := make([]string, len(cols))
+	for i := range sep {
+		sep[i] = "---"
+	}
+	lines = append(lines, row(sep))
+	for _, r := range rows {
+		lines = append(lines, row(r))
+	}
+	return strings.Join(lines, "\n")
+}
+
+// renderSpans writes a typed inline-span list back as Markdown
+// inline syntax: the inverse of inlineSpans.
+func renderSpans(path string, v any) (string, error) {
+	spans, ok := v.([]any)
+	if !ok {
+		return "", fmt.Errorf("%s: want an array, got %T", path, v)
+	}
+	var b strings.Builder
+	for i, sv := range spans {
+		sp := fmt.Sprintf("%s[%d]", path, i)
+		s, ok := sv.(map[string]any)
+		if !ok {
+			return "", fmt.Errorf("%s: want an object, got %T", sp, sv)
+		}
+		value, _ := s["value"].(string)
+		kind, _ := s["span"].(string)
+		switch kind {
+		case "text":
+			b.WriteString(value)
+		case "break":
+			if hard, _ := s["hard"].(bool); hard {
+				b.WriteString("\\")
+			}
+			b.WriteString("\n")
+		case "code":
+			b.WriteString(renderCodeSpan(value))
+		case "autolink":
+			b.WriteString("<" + value + ">")
+		case "emphasis", "strong":
+			kids, err := renderSpans(sp+".children", s["children"])
+			if err != nil {
+				return "", err
+			}
+			mark := "*"
+			if kind == "strong" {
+				mark = "**"
+			}
+			b.WriteString(mark + kids + mark)
+		case "link", "image":
+			kids, err := renderSpans(sp+".children", s["children"])
+			if err != nil {
+				return "", err
+			}
+			if kind == "image" {
+				b.WriteString("!")
+			}
+			url, _ := s["url"].(string)
+			b.WriteString("[" + kids + "](" + url)
+			if title, ok := s["title"].(string); ok {
+				b.WriteString(" " + strconv.Quote(title))
+			}
+			b.WriteString(")")
+		default:
+			return "", fmt.Errorf("%s: unknown span %q", sp, kind)
+		}
+	}
+	return b.String(), nil
+}
+
+// renderCodeSpan wraps value in a backtick run longer than any run
+// inside it, padding with a space when the value starts or ends
+// with a backtick so the delimiters stay distinct.
+func renderCodeSpan(value string) string {
+	ticks := "`"
+	for strings.Contains(value, ticks) {
+		ticks += "`"
+	}
+	pad := ""
+	if strings.HasPrefix(value, "`") || strings.HasSuffix(value, "`") ||
+		(strings.HasPrefix(value, " ") && strings.HasSuffix(value, " ")) {
+		pad = " "
+	}
+	return ticks + pad + value + pad + ticks
+}
+
+// renderBlocks writes a typed `blocks` list (plan 246's grammar)
+// back as Markdown chunks, one per block; a `section` block adds its
+// heading chunk followed by its nested blocks.
+func renderBlocks(path string, v any) ([]string, error) {
+	blocks, ok := v.([]any)
+	if !ok {
+		return nil, fmt.Errorf("%s: want an array, got %T", path, v)
+	}
+	var chunks []string
+	for i, bv := range blocks {
+		bp := fmt.Sprintf("%s[%d]", path, i)
+		b, ok := bv.(map[string]any)
+		if !ok {
+			return nil, fmt.Errorf("%s: want an object, got %T", bp, bv)
+		}
+		more, err := renderBlock(bp, b)
+		if err != nil {
+			return nil, err
+		}
+		chunks = append(chunks, more...)
+	}
+	return chunks, nil
+}
+
+func renderBlock(path string, b map[string]any) ([]string, error) {
+	kind, _ := b["block"].(string)
+	switch kind {
+	case "paragraph":
+		if spans, ok := b["inline"]; ok {
+			s, err := renderSpans(path+".inline", spans)
+			return []string{s}, err
+		}
+		s, err := scalarText(path+".text", b["text"])
+		return []string{s}, err
+	case "code":
+		value, err := scalarText(path+".value", b["value"])
+		if err != nil {
+			return nil, err
+		}
+		lang, _ := b["lang"].(string)
+		return []string{renderFence(lang, strings.TrimSuffix(value, "\n"))}, nil
+	case "list":
+		s, err := renderTreeList(path+".items", b["items"], false)
+		return []string{s}, err
+	case "table":
+		s, err := renderRowsTable(path, b["columns"], b["rows"])
+		return []string{s}, err
+	case "quote":
+		inner, err := renderBlocks(path+".blocks", b["blocks"])
+		if err != nil {
+			return nil, err
+		}
+		lines := strings.Split(strings.Join(inner, "\n\n"), "\n")
+		for i, l := range lines {
+			lines[i] = strings.TrimRight("> "+l, " ")
+		}
+		return []string{strings.Join(lines, "\n")}, nil
+	case "break":
+		return []string{"---"}, nil
+	case "html":
+		s, err := scalarText(path+".value", b["value"])
+		return []string{s}, err
+	case "section":
+		level, ok := intValue(b["level"])
+		if !ok || level < 1 || level > 6 {
+			return nil, fmt.Errorf("%s.level: want a heading level 1-6", path)
+		}
+		heading, err := scalarText(path+".heading", b["heading"])
+		if err != nil {
+			return nil, err
+		}
+		inner, err := renderBlocks(path+".blocks", b["blocks"])
+		if err != nil {
+			return nil, err
+		}
+		return append([]string{strings.Repeat("#", level) + " " + heading}, inner...), nil
+	}
+	return nil, fmt.Errorf("%s: unknown block %q", path, kind)
+}
+
+// intValue accepts the integer forms a decoded record can carry:
+// YAML ints and JSON float64s.
+func intValue(v any) (int, bool) {
+	switch n := v.(type) {
+	case int:
+		return n, true
+	case int64:
+		return int(n), true
+	case float64:
+		return int(n), n == float64(int(n))
+	}
+	return 0, false
+}
@@ -0,0 +1,346 @@
+package extract
+
+import (
+	"testing"
+
+	"github.com/jeduden/mdsmith/internal/lint"
+	"github.com/jeduden/mdsmith/internal/schema"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// reextract parses src the way the CLI does (front matter stripped
+// and decoded) and projects it against sch.
+func reextract(t *testing.T, sch *schema.Schema, src []byte) any {
+	t.Helper()
+	f, err := lint.NewFileFromSource("doc.md", src, true)
+	require.NoError(t, err)
+	fm, err := lint.ParseFrontMatterFields(f.FrontMatter)
+	require.NoError(t, err)
+	got, diags := Extract(f, sch, schema.BuildMatchTree(f, sch, fm))
+	require.Empty(t, diags)
+	return got
+}
+
+func recipeSchema() *schema.Schema {
+	return &schema.Schema{RootLevel: 2, Sections: []schema.Scope{
+		{
+			Heading: "Ingredients",
+			Matcher: &schema.Matcher{Regex: "Ingredients"},
+			Content: []schema.ContentEntry{
+				{Kind: schema.ContentKindParagraph, Required: false},
+				{Kind: schema.ContentKindList, Required: true},
+			},
+		},
+		{
+			Heading: "Step {n}",
+			Matcher: &schema.Matcher{
+				Regex:  `Step \#(digits)`,
+				Repeat: schema.Repeat{Set: true, Min: 1},
+			},
+			Content: []schema.ContentEntry{
+				{Kind: schema.ContentKindCodeBlock, Required: true, Lang: "sh"},
+			},
+		},
+		{
+			Heading: "Nutrition",
+			Matcher: &schema.Matcher{
+				Regex:  "Nutrition",
+				Repeat: schema.Repeat{Set: true, Min: 0, Max: 1},
+			},
+			Content: []schema.ContentEntry{
+				{Kind: schema.ContentKindTable, Required: true},
+			},
+		},
+	}}
+}
+
+func TestRender_RoundTrip(t *testing.T) {
+	sch := recipeSchema()
+	data := map[string]any{
+		"frontmatter": map[string]any{"id": "r1", "tags": []any{"quick"}},
+		"title":       "Pancakes",
+		"ingredients": map[string]any{
+			"text":  "Serves 2.",
+			"items": []any{"flour", "milk"},
+		},
+		"step": []any{
+			map[string]any{"code": "mix"},
+			map[string]any{"n": "2", "code": "echo '```'\nfry"},
+		},
+	}
+	out, err := Render(sch, data)
+	require.NoError(t, err)
+	assert.Equal(t, "---\nid: r1\ntags:\n  - quick\n---\n"+
+		"# Pancakes\n\n## Ingredients\n\nServes 2.\n\n- flour\n- milk\n\n"+
+		"## Step 1\n\n```sh\nmix\n```\n\n"+
+		"## Step 2\n\n```sh\necho '```'\nfry\n```\n", string(out))
+	assert.Equal(t, "", Mismatch(data, reextract(t, sch, out)))
+
+	// Plain text is written verbatim, so inline syntax in a `text`
+	// value re-parses as markup and the round trip names the loss.
+	data["ingredients"].(map[string]any)["text"] = "Serves *two*."
+	out, err = Render(sch, data)
+	require.NoError(t, err)
+	assert.Equal(t, "ingredients.text", Mismatch(data, reextract(t, sch, out)))
+}
+
+func TestRender_RecordsTableAndOrderedList(t *testing.T) {
+	sch := &schema.Schema{RootLevel: 2, Sections: []schema.Scope{{
+		Heading: "Facts",
+		Matcher: &schema.Matcher{Regex: "Facts"},
+		Content: []schema.ContentEntry{
+			{Kind: schema.ContentKindTable, Required: true, Columns: []string{"B", "A"}},
+			{Kind: schema.ContentKindList, Required: true, Ordered: true, OrderedSet: true},
+		},
+	}}}
+	data := map[string]any{"facts": map[string]any{
+		"rows":  []any{map[string]any{"A": "1", "B": "2"}},
+		"items": []any{"x", "y"},
+	}}
+	out, err := Render(sch, data)
+	require.NoError(t, err)
+	assert.Equal(t, "## Facts\n\n| B | A |\n| --- | --- |\n| 2 | 1 |\n\n1. x\n2. y\n",
+		string(out))
+	assert.Equal(t, "", Mismatch(data, reextract(t, sch, out)))
+}
+
+func TestRender_FmvarHeadingFromFrontMatter(t *testing.T) {
+	sch := &schema.Schema{RootLevel: 2, Sections: []schema.Scope{{
+		Heading: "{id}",
+		Matcher: &schema.Matcher{Regex: `\#(fmvar(id)): Summary`},
+	}}}
+	out, err := Render(sch, map[string]any{
+		"frontmatter": map[string]any{"id": "RFC-7"},
+	})
+	require.NoError(t, err)
+	assert.Contains(t, string(out), "## RFC-7: Summary\n")
+}
+
+func TestRender_RequiredSectionRendersBareHeading(t *testing.T) {
+	sch := &schema.Schema{RootLevel: 2, Sections: []schema.Scope{litScope("Goal")}}
+	out, err := Render(sch, map[string]any{})
+	require.NoError(t, err)
+	assert.Equal(t, "## Goal\n", string(out))
+}
+
+func TestRender_Errors(t *testing.T) {
+	sch := recipeSchema()
+	cases := map[string]struct {
+		data map[string]any
+		want string
+	}{
+		"unknown key": {
+			map[string]any{"ingredients": map[string]any{"items": []any{"a"}, "bogus": 1}},
+			"ingredients.bogus: no schema section or content entry binds this key",
+		},
+		"missing content": {
+			map[string]any{"ingredients": map[string]any{}},
+			"ingredients.items: missing required list content",
+		},
+		"wrong shape": {
+			map[string]any{
+				"ingredients": map[string]any{"items": []any{"a"}},
+				"step":        map[string]any{},
+			},
+			"step: want an array",
+		},
+		"frontmatter shape": {
+			map[string]any{"frontmatter": "x"},
+			"frontmatter: want an object",
+		},
+	}
+	for name, c := range cases {
+		_, err := Render(sch, c.data)
+		require.Error(t, err, name)
+		assert.Contains(t, err.Error(), c.want, name)
+	}
+
+	broad := &schema.Schema{RootLevel: 2, Sections: []schema.Scope{{
+		Heading: "any", Matcher: &schema.Matcher{Regex: "(A|B)"},
+	}}}
+	_, err := Render(broad, map[string]any{})
+	assert.ErrorContains(t, err, `cannot render a heading for "any"`)
+}
+
+func TestRender_TreeListAndInlineSpans(t *testing.T) {
+	sch := &schema.Schema{RootLevel: 2, Sections: []schema.Scope{{
+		Heading: "Todo",
+		Matcher: &schema.Matcher{Regex: "Todo"},
+		Content: []schema.ContentEntry{
+			{Kind: schema.ContentKindParagraph, Required: true, Projection: schema.ProjectionInline},
+			{Kind: schema.ContentKindList, Required: true, Projection: schema.ProjectionTree},
+		},
+	}}}
+	data := map[string]any{"todo": map[string]any{
+		"inline": []any{
+			map[string]any{"span": "text", "value": "See "},
+			map[string]any{"span": "link", "url": "a.md", "children": []any{
+				map[string]any{"span": "strong", "level": 2, "children": []any{
+					map[string]any{"span": "text", "value": "docs"},
+				}},
+			}},
+			map[string]any{"span": "text", "value": " and "},
+			map[string]any{"span": "code", "value": "a`b"},
+		},
+		"items": []any{
+			map[string]any{"text": "parent", "checked": true, "children": []any{
+				map[string]any{"text": "child"},
+			}},
+		},
+	}}
+	out, err := Render(sch, data)
+	require.NoError(t, err)
+	assert.Equal(t, "## Todo\n\nSee [**docs**](a.md) and ``a`b``\n\n"+
+		"- [x] parent\n  - child\n", string(out))
+	assert.Equal(t, "", Mismatch(data, reextract(t, sch, out)))
+}
+
+func TestRender_BlocksProjection(t *testing.T) {
+	sch := schemaBlocksDefault("Notes")
+	data := map[string]any{
+		"notes": map[string]any{"blocks": []any{
+			map[string]any{"block": "paragraph", "text": "Lead."},
+			map[string]any{"block": "quote", "blocks": []any{
+				map[string]any{"block": "paragraph", "text": "Quoted."},
+			}},
+			map[string]any{"block": "section", "level": 3, "heading": "Deep", "blocks": []any{
+				map[string]any{"block": "code", "lang": "go", "value": "x\n"},
+				map[string]any{"block": "break"},
+			}},
+		}},
+		"extra": map[string]any{"heading": "Extra", "blocks": []any{
+			map[string]any{"block": "table", "columns": []any{"A"}, "rows": []any{[]any{"1"}}},
+		}},
+	}
+	out, err := Render(sch, data)
+	require.NoError(t, err)
+	assert.Equal(t, "## Notes\n\nLead.\n\n> Quoted.\n\n### Deep\n\n```go\nx\n```\n\n---\n\n"+
+		"## Extra\n\n| A |\n| --- |\n| 1 |\n", string(out))
+	assert.Equal(t, "", Mismatch(data, reextract(t, sch, out)))
+}
+
+func TestMismatch(t *testing.T) {
+	want := map[string]any{"a": []any{1, "x"}, "b": map[string]any{"c": true}}
+	assert.Equal(t, "", Mismatch(want, map[string]any{
+		"a": []any{"1", "x"}, "b": map[string]any{"c": true, "extra": 1},
+	}))
+	assert.Equal(t, "a[1]", Mismatch(want, map[string]any{
+		"a": []any{1, "y"}, "b": map[string]any{"c": true},
+	}))
+	assert.Equal(t, "b.c", Mismatch(want, map[string]any{
+		"a": []any{1, "x"}, "b": map[string]any{},
+	}))
+	assert.Equal(t, "a", Mismatch(want, map[string]any{"a": []any{1}}))
+	assert.Equal(t, "<root>", Mismatch(want, "x"))
+	assert.Equal(t, "<root>", Mismatch(map[string]any{"f": func() {}}, nil))
+	assert.True(t, sameValue([]any{"a"}, []any{"a"}))
+	assert.False(t, sameValue(map[string]any{}, map[string]any{"k": 1}))
+}
+
+func TestRender_HoistPreambleAndRowsTable(t *testing.T) {
+	hoisted := schema.Scope{
+		Heading: "Meta",
+		Matcher: &schema.Matcher{Regex: "Meta"},
+		Bind:    strPtr(""),
+		Content: []schema.ContentEntry{
+			{Kind: schema.ContentKindTable, Required: true, Projection: schema.ProjectionRows},
+		},
+	}
+	sch := &schema.Schema{RootLevel: 2, Sections: []schema.Scope{
+		{Preamble: true, Content: []schema.ContentEntry{
+			{Kind: schema.ContentKindParagraph, Required: true},
+		}},
+		hoisted,
+	}}
+	data := map[string]any{
+		"title":   "Doc",
+		"text":    "Lead.",
+		"columns": []any{"K", "K"},
+		"rows":    []any{[]any{"a"}, []any{"b", "c"}},
+	}
+	out, err := Render(sch, data)
+	require.NoError(t, err)
+	assert.Equal(t, "# Doc\n\nLead.\n\n## Meta\n\n| K | K |\n| --- | --- |\n| a |  |\n| b | c |\n",
+		string(out))
+	got := reextract(t, sch, out).(map[string]any)
+	assert.Equal(t, []any{[]any{"a", ""}, []any{"b", "c"}}, got["rows"])
+}
+
+func TestRender_InlineAndBlockVariants(t *testing.T) {
+	spans := []any{
+		map[string]any{"span": "emphasis", "level": 1, "children": []any{
+			map[string]any{"span": "text", "value": "em"},
+		}},
+		map[string]any{"span": "text", "value": " "},
+		map[string]any{"span": "autolink", "value": "https://x.dev", "url": "https://x.dev"},
+		map[string]any{"span": "break", "hard": true},
+		map[string]any{"span": "image", "url": "i.png", "title": "T", "children": []any{
+			map[string]any{"span": "text", "value": "alt"},
+		}},
+		map[string]any{"span": "break", "hard": false},
+		map[string]any{"span": "code", "value": "`x"},
+	}
+	s, err := renderSpans("p", spans)
+	require.NoError(t, err)
+	assert.Equal(t, "*em* <https://x.dev>\\\n![alt](i.png \"T\")\n`` `x ``", s)
+
+	chunks, err := renderBlocks("b", []any{
+		map[string]any{"block": "paragraph", "inline": []any{
+			map[string]any{"span": "text", "value": "hi"},
+		}},
+		map[string]any{"block": "list", "items": []any{map[string]any{"text": "one"}}},
+		map[string]any{"block": "html", "value": "<div>x</div>"},
+		map[string]any{"block": "code", "value": "plain\n"},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"hi", "- one", "<div>x</div>", "```\nplain\n```"}, chunks)
+}
+
+func TestRender_ShapeErrors(t *testing.T) {
+	cases := map[string]struct {
+		err  error
+		want string
+	}{}
+	add := func(name string, err error, want string) {
+		cases[name] = struct {
+			err  error
+			want string
+		}{err, want}
+	}
+	_, err := renderSpans("p", "x")
+	add("spans not array", err, "p: want an array")
+	_, err = renderSpans("p", []any{"x"})
+	add("span not object", err, "p[0]: want an object")
+	_, err = renderSpans("p", []any{map[string]any{"span": "video"}})
+	add("unknown span", err, `p[0]: unknown span "video"`)
+	_, err = renderBlocks("b", []any{map[string]any{"block": "aside"}})
+	add("unknown block", err, `b[0]: unknown block "aside"`)
+	_, err = renderBlocks("b", []any{map[string]any{"block": "section", "level": 9}})
+	add("bad level", err, "b[0].level: want a heading level 1-6")
+	_, err = renderBlocks("b", "x")
+	add("blocks not array", err, "b: want an array")
+	_, err = renderFlatList("l", []any{}, false)
+	add("empty list", err, "l: a list needs at least one item")
+	_, err = renderFlatList("l", []any{map[string]any{}}, false)
+	add("list item object", err, "l[0]: want a string")
+	_, err = renderTreeList("l", []any{"x"}, false)
+	add("tree item scalar", err, "l[0]: want an object")
+	_, err = renderRecordsTable("t", []any{}, nil)
+	add("no columns", err, "t: a table needs columns or at least one row")
+	_, err = renderRecordsTable("t", []any{"x"}, []string{"A"})
+	add("row not object", err, "t[0]: want an object")
+	_, err = renderRowsTable("t", []any{}, nil)
+	add("rows no columns", err, "t: a table needs at least one column")
+	_, err = renderRowsTable("t", []any{"A"}, []any{"x"})
+	add("row not array", err, "t[0]: want an array")
+	for name, c := range cases {
+		assert.ErrorContains(t, c.err, c.want, name)
+	}
+
+	n, ok := intValue(2.5)
+	assert.False(t, ok, n)
+	n, ok = intValue(int64(3))
+	assert.True(t, ok)
+	assert.Equal(t, 3, n)
+}
@@ -0,0 +1,99 @@
+package extract
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+)
+
+// Mismatch compares a record against a fresh Extract of the document
+// rendered or patched from it, and returns the path of the first
+// value that did not survive the round trip ("" when every value
+// did). want is a subset check: keys got carries that want omits —
+// an empty `frontmatter`, a positional `n`, a `blocks` view — are
+// ignored, while arrays must match element for element. Scalars
+// compare by their printed form, so a JSON number 3 matches the
+// string "3" a heading capture projects.
+func Mismatch(want, got any) string {
+	w, err := normalize(want)
+	if err != nil {
+		return "<root>"
+	}
+	g, err := normalize(got)
+	if err != nil {
+		return "<root>"
+	}
+	if p, ok := mismatchAt("", w, g); ok {
+		return pathOrRoot(p)
+	}
+	return ""
+}
+
+// normalize maps v to the generic JSON shape (map[string]any,
+// []any, float64, string, bool, nil) so YAML-decoded and projected
+// values compare alike.
+func normalize(v any) (any, error) {
+	b, err := json.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+	var out any
+	if err := json.Unmarshal(b, &out); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func mismatchAt(path string, want, got any) (string, bool) {
+	switch w := want.(type) {
+	case map[string]any:
+		g, ok := got.(map[string]any)
+		if !ok {
+			return path, true
+		}
+		keys := make([]string, 0, len(w))
+		for k := range w {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+		for _, k := range keys {
+			kp := k
+			if path != "" {
+				kp = path + "." + k
+			}
+			gv, ok := g[k]
+			if !ok {
+				return kp, true
+			}
+			if p, bad := mismatchAt(kp, w[k], gv); bad {
+				return p, true
+			}
+		}
+		return "", false
+	case []any:
+		g, ok := got.([]any)
+		if !ok || len(g) != len(w) {
+			return path, true
+		}
+		for i := range w {
+			if p, bad := mismatchAt(fmt.Sprintf("%s[%d]", path, i), w[i], g[i]); bad {
+				return p, true
+			}
+		}
+		return "", false
+	}
+	switch got.(type) {
+	case map[string]any, []any:
+		return path, true
+	}
+	if fmt.Sprint(want) != fmt.Sprint(got) {
+		return path, true
+	}
+	return "", false
+}
+
+// sameValue reports whether a and b hold the same data under
+// Mismatch's comparison, in both directions.
+func sameValue(a, b any) bool {
+	return Mismatch(a, b) == "" && Mismatch(b, a) == ""
+}
@@ -72,6 +72,14 @@ func ContainsField(text string) bool {
 	return len(Fields(text)) > 0
 }
 
+// FieldSpans returns the byte range [start, end) of each {field}
+// placeholder in text. Escaped braces are not placeholders.
+func FieldSpans(text string) [][]int {
+	s := strings.ReplaceAll(text, "{{", "\x00\x00")
+	s = strings.ReplaceAll(s, "}}", "\x00\x00")
+	return fieldPattern.FindAllStringIndex(s, -1)
+}
+
 // SplitOnFields splits text on {field} placeholders and returns the literal
 // parts between them. Escaped braces are treated as literals.
 // For "{id}: {name}" it returns ["", ": ", ""].
@@ -184,3 +184,10 @@ func TestParseCUEPath_MixedQuotedAndIdent(t *testing.T) {
 	segments := ParseCUEPath(`params."my-key"`)
 	assert.Equal(t, []string{"params", "my-key"}, segments)
 }
+
+// --- FieldSpans tests ---
+
+func TestFieldSpans_SkipsEscapedBraces(t *testing.T) {
+	text := "{{lit}} {a.b} x {c}"
+	assert.Equal(t, [][]int{{8, 13}, {16, 19}}, FieldSpans(text))
+}
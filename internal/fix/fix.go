@@ -102,9 +102,10 @@ type Fixer struct {
 // FixableRule subset sorted by ID, and any settings-application errors
 // produced while configuring it.
 type fixerConfigured struct {
-	all     []rule.Rule
-	fixable []rule.FixableRule
-	errs    []error
+	all       []rule.Rule
+	fixable   []rule.FixableRule
+	generated []string
+	errs      []error
 }
 
 // effectiveCachedForFix is fixFile's hot-path config resolver: it
@@ -161,7 +162,12 @@ func (f *Fixer) configuredFor(key string, effective map[string]config.RuleCfg) f
 		}
 	}
 	sort.Slice(fixable, func(i, j int) bool { return fixable[i].ID() < fixable[j].ID() })
-	fc := fixerConfigured{all: all, fixable: fixable, errs: errs}
+	fc := fixerConfigured{
+		all:       all,
+		fixable:   fixable,
+		generated: checker.GeneratedDirectives(f.Rules, effective),
+		errs:      errs,
+	}
 	if f.confCache == nil {
 		f.confCache = make(map[string]fixerConfigured)
 	}
@@ -409,6 +415,7 @@ func (f *Fixer) fixFile(path string) (
 	f.logRules(effective)
 
 	fc := f.configuredFor(key, effective)
+	lf.GeneratedDirectives = fc.generated
 	lf.GeneratedRanges = gensection.FindAllGeneratedRanges(lf)
 	foreignDiags := foreignregion.Apply(lf, f.Config, path)
 	beforeDiags := checker.CheckConfiguredRules(lf, fc.all, false, 1)
@@ -599,7 +606,8 @@ func countByRule(diags []lint.Diagnostic) map[string]int {
 // time and resolution context that the engine.Runner sets per-file
 // (see runner.go ~line 90-108): FS, RootFS/RootDir, FrontMatter,
 // LineOffset, StripFrontMatter, MaxInputBytes, DryRun, GitignoreFunc,
-// and GeneratedRanges (recomputed for the parsed bytes). Used by both
+// KindsFunc, GeneratedDirectives, and GeneratedRanges (recomputed for the
+// parsed bytes). Used by both
 // the post-fix CheckRules call and the parsedFile inside each
 // applyFixPasses iteration so rules see the same File regardless of
 // which Fixer phase invokes them. Without this, fixable rules like
@@ -618,6 +626,8 @@ func hydrateLintFile(parsed *lint.File, lf *lint.File, dirFS fs.FS, cfg *config.
 	parsed.MaxInputBytes = lf.MaxInputBytes
 	parsed.DryRun = lf.DryRun
 	parsed.GitignoreFunc = lf.GitignoreFunc
+	parsed.KindsFunc = lf.KindsFunc
+	parsed.GeneratedDirectives = lf.GeneratedDirectives
 	parsed.GeneratedRanges = gensection.FindAllGeneratedRanges(parsed)
 	// Extend the exclusion set with foreign-region spans so fixable
 	// rules skip a marker pair another generator owns, exactly as the
@@ -779,6 +789,11 @@ func (f *Fixer) prepareFile(path string, source []byte) (*lint.File, fs.FS, []st
 	lf.GitignoreFunc = func() *gitignore.Matcher {
 		return f.cachedGitignore(gd)
 	}
+	if cfg := f.Config; cfg != nil {
+		lf.KindsFunc = func(path string, fm []byte) []string {
+			return config.FileKinds(cfg, path, fm)
+		}
+	}
 	kinds, err := lint.ParseFrontMatterKinds(lf.FrontMatter)
 	if err != nil {
 		return nil, nil, nil, nil, fmt.Errorf("parsing front-matter kinds in %q: %w", path, err)
@@ -7,6 +7,7 @@ import (
 	"math"
 
 	"github.com/jeduden/mdsmith/internal/archetype/gensection"
+	"github.com/jeduden/mdsmith/internal/checker"
 	"github.com/jeduden/mdsmith/internal/config"
 	"github.com/jeduden/mdsmith/internal/rule"
 	"github.com/jeduden/mdsmith/internal/setutil"
@@ -116,6 +117,7 @@ func fixSourceImpl(opts SourceOptions, only []string) ([]byte, error) {
 		}
 		fixable = filtered
 	}
+	lf.GeneratedDirectives = checker.GeneratedDirectives(f.Rules, effective)
 	lf.GeneratedRanges = gensection.FindAllGeneratedRanges(lf)
 	// applyFixPasses' error sink is unreachable today: the only
 	// path that appends is `lint.NewFile`'s error return, and
@@ -68,6 +68,40 @@ func ResolveHooksDir(repoRoot string) string {
 	return filepath.Join(repoRoot, ".git", "hooks")
 }
 
+// DirectiveNames returns the name of every directive whose section
+// mdsmith fix regenerates: each registered gensection.Directive rule,
+// plus the names each gensection.DirectiveSet rule serves once cfg's
+// settings are applied to it (user-directive's definitions). A nil
+// cfg, or settings a rule rejects, contribute no configured names.
+func DirectiveNames(cfg *config.Config) []string {
+	var effective map[string]config.RuleCfg
+	if cfg != nil {
+		effective = config.Effective(cfg, "", nil, nil)
+	}
+	var names []string
+	for _, r := range rule.All() {
+		if d, ok := r.(gensection.Directive); ok {
+			names = append(names, d.Name())
+			continue
+		}
+		if _, ok := r.(gensection.DirectiveSet); !ok {
+			continue
+		}
+		clone := rule.CloneRule(r)
+		if c, ok := clone.(rule.Configurable); ok {
+			if settings := effective[r.Name()].Settings; settings != nil {
+				if err := c.ApplySettings(settings); err != nil {
+					continue
+				}
+			}
+		}
+		for _, d := range clone.(gensection.DirectiveSet).Directives() {
+			names = append(names, d.Name())
+		}
+	}
+	return names
+}
+
 // DiscoverFiles scans repoRoot for Markdown files containing a
 // generated-section directive (catalog, include, toc, …). Returned
 // paths are relative to repoRoot and use forward-slash separators on
@@ -79,24 +113,20 @@ func ResolveHooksDir(repoRoot string) string {
 // whether to apply a fallback (the install commands do; the
 // git-hook-sync rule does not).
 func DiscoverFiles(repoRoot string, maxBytes int64) []string {
-	allRules := rule.All()
-	directiveNames := make([]string, 0, len(allRules))
-	for _, r := range allRules {
-		if d, ok := r.(gensection.Directive); ok {
-			directiveNames = append(directiveNames, d.Name())
-		}
-	}
-
 	// Load the project's ignore patterns so discovery does not list
 	// files that mdsmith would skip during `mdsmith fix`. Without this
 	// the merge driver and pre-merge-commit hook would fire on paths
 	// (e.g. fixture files under `internal/rules/*/{good,bad,fixed}/**`)
 	// where mdsmith fix is a no-op, leaving real conflicts unresolved.
-	// A missing or unparseable config simply means no ignore filtering.
+	// The same config names the user-defined directives. A missing or
+	// unparseable config simply means no ignore filtering.
 	var ignorePatterns []string
-	if cfg, err := config.Load(filepath.Join(repoRoot, configFileName)); err == nil {
-		ignorePatterns = cfg.Ignore
+	var cfg *config.Config
+	if loaded, err := config.Load(filepath.Join(repoRoot, configFileName)); err == nil {
+		ignorePatterns = loaded.Ignore
+		cfg = config.Merge(config.Defaults(), loaded)
 	}
+	directiveNames := DirectiveNames(cfg)
 
 	seen := make(map[string]struct{})
 	var files []string
@@ -20,6 +20,7 @@ import (
 	_ "github.com/jeduden/mdsmith/internal/rules/catalog"
 	_ "github.com/jeduden/mdsmith/internal/rules/include"
 	_ "github.com/jeduden/mdsmith/internal/rules/toc"
+	_ "github.com/jeduden/mdsmith/internal/rules/userdirective"
 )
 
 func TestFilesMatch(t *testing.T) {
@@ -170,6 +171,44 @@ func TestDiscoverFiles_FindsDirectives(t *testing.T) {
 	assert.NotContains(t, got, ".hidden/secret.md")
 }
 
+func TestDiscoverFiles_FindsUserDirectives(t *testing.T) {
+	// User-defined directive names come from the user-directive rule
+	// once configured, so a definition set on the rule itself is
+	// found as well as one under the top-level `directives:` key.
+	dir := t.TempDir()
+	files := map[string]string{
+		".mdsmith.yml": "directives:\n" +
+			"  roster:\n" +
+			"    expand: catalog\n" +
+			"    with:\n" +
+			"      glob: \"team/*.md\"\n" +
+			"rules:\n" +
+			"  user-directive:\n" +
+			"    directives:\n" +
+			"      owners:\n" +
+			"        expand: catalog\n" +
+			"        with:\n" +
+			"          glob: \"team/*.md\"\n",
+		"roster.md": "# Roster\n\n<?roster?>\n<?/roster?>\n",
+		"owners.md": "# Owners\n\n<?owners?>\n<?/owners?>\n",
+		"other.md":  "# Other\n\n<?unknown?>\n<?/unknown?>\n",
+	}
+	for name, content := range files {
+		path := filepath.Join(dir, name)
+		require.NoError(t, os.MkdirAll(filepath.Dir(path), 0o755))
+		require.NoError(t, os.WriteFile(path, []byte(content), 0o644))
+	}
+
+	got := DiscoverFiles(dir, 1024*1024)
+	assert.Equal(t, []string{"owners.md", "roster.md"}, got)
+}
+
+func TestDirectiveNames_NilConfig(t *testing.T) {
+	names := DirectiveNames(nil)
+	assert.Contains(t, names, "catalog")
+	assert.NotContains(t, names, "roster")
+}
+
 func TestDiscoverFiles_IgnoresDirectivesInsideFencedCode(t *testing.T) {
 	dir := t.TempDir()
 	// docs file shows a directive only inside a fenced code block,
@@ -0,0 +1,148 @@
+// Package htmlrender renders a parsed Markdown file to HTML with the
+// vendored goldmark renderer, behind `mdsmith render`. The parse uses
+// the selected flavor's extensions; heading IDs are the anchors
+// linkgraph computes, so every link MDS027 accepts lands on its
+// heading; and relative `.md` links point at the sibling `.html`.
+//
+// The package operates on an in-memory *lint.File, normally the
+// re-parsed output of internal/export. Reads, writes, and the export
+// step are the CLI layer's responsibility.
+package htmlrender
+
+import (
+	"bytes"
+	"strings"
+
+	"github.com/jeduden/mdsmith/pkg/goldmark/ast"
+	"github.com/jeduden/mdsmith/pkg/goldmark/renderer"
+	"github.com/jeduden/mdsmith/pkg/goldmark/renderer/html"
+	"github.com/jeduden/mdsmith/pkg/goldmark/text"
+	"github.com/jeduden/mdsmith/pkg/goldmark/util"
+	"github.com/jeduden/mdsmith/pkg/markdown/flavor"
+
+	"github.com/jeduden/mdsmith/internal/linkgraph"
+	"github.com/jeduden/mdsmith/internal/lint"
+	"github.com/jeduden/mdsmith/internal/mdtext"
+)
+
+// Options controls Render.
+type Options struct {
+	// Flavor selects the parser extensions. FlavorCommonMark renders
+	// plain CommonMark; FlavorAny enables every extension.
+	Flavor flavor.Flavor
+	// Unsafe passes raw HTML through. By default it is replaced with
+	// an HTML comment, as goldmark does.
+	Unsafe bool
+}
+
+// Render returns f's body as an HTML fragment. Front matter is not
+// rendered; see Title and Page for the standalone document.
+func Render(f *lint.File, opts Options) ([]byte, error) {
+	md := flavor.NewMarkdown(flavor.Extensions(opts.Flavor)...)
+	doc := md.Parser().Parse(text.NewReader(f.Source))
+
+	md.Renderer().AddOptions(renderer.WithNodeRenderers(
+		util.Prioritized(newExtraRenderer(doc), 500),
+	))
+	if opts.Unsafe {
+		md.Renderer().AddOptions(html.WithUnsafe())
+	}
+	setHeadingIDs(doc, headingAnchors(f))
+	rewriteLinks(doc)
+
+	var buf bytes.Buffer
+	if err := md.Renderer().Render(&buf, f.Source, doc); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// headingAnchors maps the start offset of each heading's text to the
+// anchor linkgraph.CollectAnchors gives it. Both walks visit headings
+// in document order and skip the ones with an empty slug, so the
+// n-th kept heading owns the n-th anchor. Keying by offset rather
+// than by position lets the flavor parse, which may see a different
+// set of blocks, find its headings by where they sit in the source.
+func headingAnchors(f *lint.File) map[int]string {
+	out := make(map[int]string)
+	if f.AST == nil {
+		return out
+	}
+	items := mdtext.CollectTOCItems(f.AST, f.Source)
+	i := 0
+	_ = ast.Walk(f.AST, func(n ast.Node, entering bool) (ast.WalkStatus, error) {
+		h, ok := n.(*ast.Heading)
+		if !entering || !ok || i >= len(items) {
+			return ast.WalkContinue, nil
+		}
+		if mdtext.Slugify(mdtext.ExtractPlainText(h, f.Source)) == "" {
+			return ast.WalkContinue, nil
+		}
+		if h.Lines().Len() > 0 {
+			out[h.Lines().At(0).Start] = items[i].Anchor
+		}
+		i++
+		return ast.WalkContinue, nil
+	})
+	return out
+}
+
+// setHeadingIDs sets each heading's id attribute from anchors. A
+// `{#id}` attribute block the flavor parse read is replaced: the id
+// links resolve against is the slug, not the attribute.
+func setHeadingIDs(doc ast.Node, anchors map[int]string) {
+	_ = ast.Walk(doc, func(n ast.Node, entering bool) (ast.WalkStatus, error) {
+		h, ok := n.(*ast.Heading)
+		if !entering || !ok || h.Lines().Len() == 0 {
+			return ast.WalkContinue, nil
+		}
+		if id, ok := anchors[h.Lines().At(0).Start]; ok {
+			h.SetAttributeString("id", []byte(id))
+		}
+		return ast.WalkSkipChildren, nil
+	})
+}
+
+// rewriteLinks points relative `.md` links at the rendered `.html`
+// sibling and normalizes fragments to the slug form heading IDs use.
+// External links and images are left alone.
+func rewriteLinks(doc ast.Node) {
+	_ = ast.Walk(doc, func(n ast.Node, entering bool) (ast.WalkStatus, error) {
+		if l, ok := n.(*ast.Link); ok && entering {
+			l.Destination = []byte(rewriteDestination(string(l.Destination)))
+		}
+		return ast.WalkContinue, nil
+	})
+}
+
+// rewriteDestination returns dest with a trailing `.md` on a
+// relative path replaced by `.html`, and its fragment slugified the
+// way linkgraph.NormalizeAnchor does. Any other destination is
+// returned unchanged.
+func rewriteDestination(dest string) string {
+	t, ok := linkgraph.ParseTarget(dest)
+	if !ok {
+		return dest
+	}
+	if !t.LocalAnchor && !strings.HasSuffix(t.Path, ".md") {
+		return dest
+	}
+	head, frag, hasFrag := strings.Cut(strings.TrimSpace(dest), "#")
+	if !t.LocalAnchor {
+		path, query, hasQuery := strings.Cut(head, "?")
+		if !strings.HasSuffix(path, ".md") {
+			return dest
+		}
+		head = strings.TrimSuffix(path, ".md") + ".html"
+		if hasQuery {
+			head += "?" + query
+		}
+	}
+	if hasFrag && t.Anchor != "" {
+		frag = linkgraph.NormalizeAnchor(t.Anchor)
+	}
+	if !hasFrag {
+		return head
+	}
+	return head + "#" + frag
+}
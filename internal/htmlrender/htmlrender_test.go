@@ -0,0 +1,103 @@
+package htmlrender
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/jeduden/mdsmith/internal/linkgraph"
+	"github.com/jeduden/mdsmith/internal/lint"
+	"github.com/jeduden/mdsmith/pkg/markdown/flavor"
+)
+
+func render(t *testing.T, src string, opts Options) string {
+	t.Helper()
+	f, err := lint.NewFileFromSource("doc.md", []byte(src), true)
+	require.NoError(t, err)
+	out, err := Render(f, opts)
+	require.NoError(t, err)
+	return string(out)
+}
+
+func TestRender_HeadingIDsMatchLinkgraph(t *testing.T) {
+	src := "# Intro\n\n## Intro\n\n## Intro-1\n\n## Setup & Use\n"
+	f, err := lint.NewFileFromSource("doc.md", []byte(src), true)
+	require.NoError(t, err)
+	out, err := Render(f, Options{Flavor: flavor.FlavorAny})
+	require.NoError(t, err)
+	for anchor := range linkgraph.CollectAnchors(f) {
+		assert.Contains(t, string(out), `id="`+anchor+`"`)
+	}
+	assert.Contains(t, string(out), `<h2 id="intro-1-1">Intro-1</h2>`)
+	assert.Contains(t, string(out), `<h2 id="setup-use">`)
+}
+
+func TestRender_HeadingAttributeYieldsToSlug(t *testing.T) {
+	out := render(t, "## Install {#custom}\n", Options{Flavor: flavor.FlavorAny})
+	// linkgraph reads the heading as CommonMark, attribute text and
+	// all, so that slug is the id.
+	assert.Contains(t, out, `id="install-custom"`)
+	assert.NotContains(t, out, `id="custom"`)
+}
+
+func TestRender_FlavorSelectsExtensions(t *testing.T) {
+	src := "| a |\n| - |\n| ~~b~~ |\n\nH~2~O and x^2^\n"
+	cm := render(t, src, Options{Flavor: flavor.FlavorCommonMark})
+	assert.NotContains(t, cm, "<table>")
+	assert.NotContains(t, cm, "<del>")
+
+	gfm := render(t, src, Options{Flavor: flavor.FlavorGFM})
+	assert.Contains(t, gfm, "<table>")
+	assert.Contains(t, gfm, "<del>b</del>")
+	assert.NotContains(t, gfm, "<sub>")
+
+	pandoc := render(t, src, Options{Flavor: flavor.FlavorPandoc})
+	assert.Contains(t, pandoc, "H<sub>2</sub>O")
+	assert.Contains(t, pandoc, "x<sup>2</sup>")
+}
+
+func TestRender_ExtraNodes(t *testing.T) {
+	src := "The HTML spec and $a<b$.\n\n$$\nx^2\n$$\n\n" +
+		"*[HTML]: Hyper Text Markup Language\n\n<?allow-empty-section?>\n"
+	out := render(t, src, Options{Flavor: flavor.FlavorAny})
+	assert.Contains(t, out, `<abbr title="Hyper Text Markup Language">HTML</abbr>`)
+	assert.Contains(t, out, `<span class="math inline">\(a&lt;b\)</span>`)
+	assert.Contains(t, out, `<div class="math display">\[x^2\]</div>`)
+	assert.NotContains(t, out, "*[HTML]")
+	assert.NotContains(t, out, "allow-empty-section")
+}
+
+func TestRender_RawHTML(t *testing.T) {
+	src := "<div>hi</div>\n"
+	assert.NotContains(t, render(t, src, Options{}), "<div>hi</div>")
+	assert.Contains(t, render(t, src, Options{Unsafe: true}), "<div>hi</div>")
+}
+
+func TestRender_LinksRewritten(t *testing.T) {
+	src := "[a](guide.md) [b](../ref/cli.md#Some%20Heading) [c](#Local-Part)\n" +
+		"[d](https://example.com/x.md) [e](notes.txt) [f][r]\n\n[r]: sub/page.md?x=1\n"
+	out := render(t, src, Options{})
+	assert.Contains(t, out, `href="guide.html"`)
+	assert.Contains(t, out, `href="../ref/cli.html#some-heading"`)
+	assert.Contains(t, out, `href="#local-part"`)
+	assert.Contains(t, out, `href="https://example.com/x.md"`)
+	assert.Contains(t, out, `href="notes.txt"`)
+	assert.Contains(t, out, `href="sub/page.html?x=1"`)
+}
+
+func TestRewriteDestination(t *testing.T) {
+	cases := map[string]string{
+		"a.md":           "a.html",
+		"a.md#":          "a.html#",
+		"dir/a.md#Intro": "dir/a.html#intro",
+		"#Intro":         "#intro",
+		"a.markdown":     "a.markdown",
+		"mailto:x@y.md":  "mailto:x@y.md",
+		"":               "",
+		"/abs/a.md":      "/abs/a.html",
+	}
+	for in, want := range cases {
+		assert.Equal(t, want, rewriteDestination(in), "dest=%q", in)
+	}
+}
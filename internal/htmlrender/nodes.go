@@ -0,0 +1,113 @@
+package htmlrender
+
+import (
+	"bytes"
+
+	"github.com/jeduden/mdsmith/pkg/goldmark/ast"
+	"github.com/jeduden/mdsmith/pkg/goldmark/renderer"
+	"github.com/jeduden/mdsmith/pkg/goldmark/renderer/html"
+	"github.com/jeduden/mdsmith/pkg/goldmark/util"
+	"github.com/jeduden/mdsmith/pkg/markdown"
+	"github.com/jeduden/mdsmith/pkg/markdown/flavor/ext"
+)
+
+// extraRenderer renders the nodes goldmark has no HTML for: the
+// detection-only MDS034 extensions and mdsmith's processing
+// instructions. Math is written in Pandoc's form (`\(…\)` in a
+// `math inline` span, `\[…\]` in a `math display` div), which
+// MathJax and KaTeX pick up as is.
+type extraRenderer struct {
+	// abbrs maps each defined abbreviation to its expansion. The
+	// parse keeps the table in its context only, so it is rebuilt
+	// from the definition nodes.
+	abbrs map[string][]byte
+}
+
+func newExtraRenderer(doc ast.Node) *extraRenderer {
+	r := &extraRenderer{abbrs: make(map[string][]byte)}
+	_ = ast.Walk(doc, func(n ast.Node, entering bool) (ast.WalkStatus, error) {
+		if d, ok := n.(*ext.AbbreviationDefinition); ok && entering {
+			r.abbrs[string(d.Term)] = d.Expansion
+		}
+		return ast.WalkContinue, nil
+	})
+	return r
+}
+
+// RegisterFuncs implements renderer.NodeRenderer.
+func (r *extraRenderer) RegisterFuncs(reg renderer.NodeRendererFuncRegisterer) {
+	reg.Register(markdown.KindProcessingInstruction, skipNode)
+	reg.Register(ext.KindAbbreviationDefinition, skipNode)
+	reg.Register(ext.KindAbbreviationReference, r.renderAbbreviation)
+	reg.Register(ext.KindSuperscript, wrapNode("sup"))
+	reg.Register(ext.KindSubscript, wrapNode("sub"))
+	reg.Register(ext.KindMathInline, renderMathInline)
+	reg.Register(ext.KindMathBlock, renderMathBlock)
+}
+
+// skipNode renders nothing for the node or its children.
+func skipNode(util.BufWriter, []byte, ast.Node, bool) (ast.WalkStatus, error) {
+	return ast.WalkSkipChildren, nil
+}
+
+// wrapNode renders the node's children inside a <tag> element.
+func wrapNode(tag string) renderer.NodeRendererFunc {
+	return func(w util.BufWriter, _ []byte, _ ast.Node, entering bool) (ast.WalkStatus, error) {
+		if entering {
+			_, _ = w.WriteString("<" + tag + ">")
+		} else {
+			_, _ = w.WriteString("</" + tag + ">")
+		}
+		return ast.WalkContinue, nil
+	}
+}
+
+func (r *extraRenderer) renderAbbreviation(
+	w util.BufWriter, _ []byte, node ast.Node, entering bool,
+) (ast.WalkStatus, error) {
+	if !entering {
+		_, _ = w.WriteString("</abbr>")
+		return ast.WalkContinue, nil
+	}
+	n := node.(*ext.AbbreviationReference)
+	_, _ = w.WriteString(`<abbr title="`)
+	html.DefaultWriter.RawWrite(w, r.abbrs[string(n.Term)])
+	_, _ = w.WriteString(`">`)
+	return ast.WalkContinue, nil
+}
+
+func renderMathInline(
+	w util.BufWriter, source []byte, n ast.Node, entering bool,
+) (ast.WalkStatus, error) {
+	if !entering {
+		return ast.WalkContinue, nil
+	}
+	_, _ = w.WriteString(`<span class="math inline">\(`)
+	for c := n.FirstChild(); c != nil; c = c.NextSibling() {
+		if t, ok := c.(*ast.Text); ok {
+			html.DefaultWriter.RawWrite(w, t.Segment.Value(source))
+		}
+	}
+	_, _ = w.WriteString(`\)</span>`)
+	return ast.WalkSkipChildren, nil
+}
+
+func renderMathBlock(
+	w util.BufWriter, source []byte, n ast.Node, entering bool,
+) (ast.WalkStatus, error) {
+	if !entering {
+		return ast.WalkContinue, nil
+	}
+	var body []byte
+	for i := range n.Lines().Len() {
+		seg := n.Lines().At(i)
+		body = append(body, seg.Value(source)...)
+	}
+	body = bytes.TrimSpace(body)
+	body = bytes.TrimPrefix(body, []byte("$$"))
+	body = bytes.TrimSuffix(body, []byte("$$"))
+	_, _ = w.WriteString(`<div class="math display">\[`)
+	html.DefaultWriter.RawWrite(w, bytes.TrimSpace(body))
+	_, _ = w.WriteString("\\]</div>\n")
+	return ast.WalkSkipChildren, nil
+}
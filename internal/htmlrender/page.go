@@ -0,0 +1,92 @@
+package htmlrender
+
+import (
+	"bytes"
+	"html/template"
+	"path/filepath"
+	"strings"
+
+	"github.com/jeduden/mdsmith/pkg/goldmark/ast"
+
+	"github.com/jeduden/mdsmith/internal/lint"
+	"github.com/jeduden/mdsmith/internal/mdtext"
+)
+
+// PageData is what a page template is executed with.
+type PageData struct {
+	// Title is the front matter `title`, else the first H1's text,
+	// else the file name without its extension.
+	Title string
+	// Body is the rendered fragment.
+	Body template.HTML
+	// FrontMatter is the decoded front matter; nil when there is none.
+	FrontMatter map[string]any
+}
+
+// minimalTemplate is the built-in `--template minimal` page: a
+// readable column with no external assets, enough to preview a
+// document in a browser.
+const minimalTemplate = `<!DOCTYPE html>
+<html lang="en">
+<head>
+<meta charset="utf-8">
+<meta name="viewport" content="width=device-width, initial-scale=1">
+<title>{{.Title}}</title>
+<style>
+body { max-width: 46rem; margin: 2rem auto; padding: 0 1rem;
+  font: 16px/1.6 system-ui, sans-serif; }
+pre, code { font-family: ui-monospace, monospace; }
+pre { overflow-x: auto; padding: .75rem; background: #f6f8fa; }
+table { border-collapse: collapse; }
+th, td { border: 1px solid #d0d7de; padding: .25rem .5rem; }
+</style>
+</head>
+<body>
+{{.Body}}
+</body>
+</html>
+`
+
+// MinimalTemplate returns the built-in page template.
+func MinimalTemplate() *template.Template {
+	return template.Must(template.New("minimal").Parse(minimalTemplate))
+}
+
+// ParseTemplate parses a user page template. It is an html/template,
+// so values other than Body are escaped for their context.
+func ParseTemplate(name string, src []byte) (*template.Template, error) {
+	return template.New(name).Parse(string(src))
+}
+
+// Page executes tmpl with data and returns the document.
+func Page(tmpl *template.Template, data PageData) ([]byte, error) {
+	var buf bytes.Buffer
+	if err := tmpl.Execute(&buf, data); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// Title picks a page title for f: fm["title"] when it is a non-empty
+// string, else the plain text of the first H1, else the base name of
+// f.Path without its extension.
+func Title(f *lint.File, fm map[string]any) string {
+	if s, ok := fm["title"].(string); ok && strings.TrimSpace(s) != "" {
+		return s
+	}
+	var title string
+	if f.AST != nil {
+		_ = ast.Walk(f.AST, func(n ast.Node, entering bool) (ast.WalkStatus, error) {
+			if h, ok := n.(*ast.Heading); ok && entering && h.Level == 1 {
+				title = mdtext.ExtractPlainText(h, f.Source)
+				return ast.WalkStop, nil
+			}
+			return ast.WalkContinue, nil
+		})
+	}
+	if title != "" {
+		return title
+	}
+	base := filepath.Base(f.Path)
+	return strings.TrimSuffix(base, filepath.Ext(base))
+}
@@ -0,0 +1,53 @@
+package htmlrender
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/jeduden/mdsmith/internal/lint"
+)
+
+func TestTitle(t *testing.T) {
+	f, err := lint.NewFileFromSource("docs/setup.md", []byte("Intro.\n\n# Set *up*\n"), true)
+	require.NoError(t, err)
+	assert.Equal(t, "Front", Title(f, map[string]any{"title": "Front"}))
+	assert.Equal(t, "Set up", Title(f, map[string]any{"title": 3}))
+	assert.Equal(t, "Set up", Title(f, nil))
+
+	bare, err := lint.NewFileFromSource("docs/setup.md", []byte("## Only H2\n"), true)
+	require.NoError(t, err)
+	assert.Equal(t, "setup", Title(bare, nil))
+}
+
+func TestPage_Minimal(t *testing.T) {
+	out, err := Page(MinimalTemplate(), PageData{
+		Title: "A <b> title",
+		Body:  "<p>body</p>",
+	})
+	require.NoError(t, err)
+	assert.Contains(t, string(out), "<title>A &lt;b&gt; title</title>")
+	assert.Contains(t, string(out), "<body>\n<p>body</p>\n</body>")
+}
+
+func TestPage_UserTemplate(t *testing.T) {
+	tmpl, err := ParseTemplate("page.html",
+		[]byte(`<h1>{{.Title}}</h1><i>{{.FrontMatter.author}}</i>{{.Body}}`))
+	require.NoError(t, err)
+	out, err := Page(tmpl, PageData{
+		Title:       "T",
+		Body:        "<p>b</p>",
+		FrontMatter: map[string]any{"author": "<Ann>"},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, "<h1>T</h1><i>&lt;Ann&gt;</i><p>b</p>", string(out))
+
+	_, err = ParseTemplate("bad.html", []byte("{{.Title"))
+	assert.Error(t, err)
+
+	tmpl, err = ParseTemplate("exec.html", []byte("{{.Missing.Field}}"))
+	require.NoError(t, err)
+	_, err = Page(tmpl, PageData{})
+	assert.Error(t, err)
+}
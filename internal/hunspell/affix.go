@@ -0,0 +1,328 @@
+package hunspell
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+)
+
+// flag is one affix or attribute flag. The .aff FLAG directive picks
+// how flags are written; every form is decoded to this one integer.
+type flag uint32
+
+// flagMode is the FLAG encoding: one character per flag (the
+// default, read as runes), two characters per flag ("long"), or
+// comma-separated decimal numbers ("num").
+type flagMode uint8
+
+const (
+	flagChar flagMode = iota
+	flagLong
+	flagNum
+)
+
+// parseFlags decodes a flag string in mode m.
+func parseFlags(s string, m flagMode) ([]flag, error) {
+	if s == "" {
+		return nil, nil
+	}
+	switch m {
+	case flagLong:
+		rs := []rune(s)
+		if len(rs)%2 != 0 {
+			return nil, fmt.Errorf("long flags %q have an odd length", s)
+		}
+		out := make([]flag, 0, len(rs)/2)
+		for i := 0; i < len(rs); i += 2 {
+			out = append(out, flag(rs[i])<<16|flag(rs[i+1]))
+		}
+		return out, nil
+	case flagNum:
+		parts := strings.Split(s, ",")
+		out := make([]flag, 0, len(parts))
+		for _, p := range parts {
+			n, err := strconv.ParseUint(strings.TrimSpace(p), 10, 32)
+			if err != nil {
+				return nil, fmt.Errorf("numeric flag %q: %w", p, err)
+			}
+			out = append(out, flag(n))
+		}
+		return out, nil
+	}
+	out := make([]flag, 0, utf8.RuneCountInString(s))
+	for _, r := range s {
+		out = append(out, flag(r))
+	}
+	return out, nil
+}
+
+// hasFlag reports whether fs contains f.
+func hasFlag(fs []flag, f flag) bool {
+	for _, x := range fs {
+		if x == f {
+			return true
+		}
+	}
+	return false
+}
+
+// charClass is one position of an affix condition: a single rune, a
+// bracket set ("[aeiou]", "[^ey]"), or "." for any rune.
+type charClass struct {
+	set    string
+	negate bool
+	any    bool
+}
+
+func (c charClass) match(r rune) bool {
+	if c.any {
+		return true
+	}
+	return strings.ContainsRune(c.set, r) != c.negate
+}
+
+// condition is an affix condition, matched against the end of the
+// stem for a suffix and against its start for a prefix.
+type condition []charClass
+
+// parseCondition decodes the condition field of an affix rule.
+func parseCondition(s string) (condition, error) {
+	if s == "." {
+		return nil, nil
+	}
+	var out condition
+	rs := []rune(s)
+	for i := 0; i < len(rs); i++ {
+		switch rs[i] {
+		case '.':
+			out = append(out, charClass{any: true})
+		case '[':
+			end := i + 1
+			for end < len(rs) && rs[end] != ']' {
+				end++
+			}
+			if end == len(rs) {
+				return nil, fmt.Errorf("condition %q has an unclosed bracket", s)
+			}
+			set := rs[i+1 : end]
+			c := charClass{}
+			if len(set) > 0 && set[0] == '^' {
+				c.negate = true
+				set = set[1:]
+			}
+			c.set = string(set)
+			out = append(out, c)
+			i = end
+		default:
+			out = append(out, charClass{set: string(rs[i])})
+		}
+	}
+	return out, nil
+}
+
+// matchEnd reports whether the last len(c) runes of stem match c.
+func (c condition) matchEnd(stem string) bool {
+	i := len(c) - 1
+	for j := len(stem); i >= 0; i-- {
+		if j == 0 {
+			return false
+		}
+		r, size := utf8.DecodeLastRuneInString(stem[:j])
+		if !c[i].match(r) {
+			return false
+		}
+		j -= size
+	}
+	return true
+}
+
+// matchStart reports whether the first len(c) runes of stem match c.
+func (c condition) matchStart(stem string) bool {
+	j := 0
+	for _, cc := range c {
+		if j == len(stem) {
+			return false
+		}
+		r, size := utf8.DecodeRuneInString(stem[j:])
+		if !cc.match(r) {
+			return false
+		}
+		j += size
+	}
+	return true
+}
+
+// affix is one PFX or SFX rule line: strip `strip` from the stem,
+// then add `add`, when the stem matches cond. cross marks a rule
+// that combines with an affix of the other kind.
+type affix struct {
+	strip string
+	add   string
+	cond  condition
+	flag  flag
+	cross bool
+}
+
+// affixHeader is the first line of a PFX or SFX block, which names
+// the flag, the cross-product mark, and how many rule lines follow.
+type affixHeader struct {
+	flag  flag
+	cross bool
+	left  int
+}
+
+// affixFile is the parsed subset of an .aff file the checker and
+// suggester use. Directives outside this subset (compounding,
+// morphology, ...) are ignored, as Hunspell ignores unknown ones.
+type affixFile struct {
+	mode      flagMode
+	try       string
+	rep       [][2]string
+	aliases   [][]flag
+	prefixes  map[string][]affix // keyed by add
+	suffixes  map[string][]affix // keyed by add
+	maxPrefix int
+	maxSuffix int
+
+	aliasCount bool
+	forbidden  flag
+	needAffix  flag
+	noSuggest  flag
+	keepCase   flag
+}
+
+// parseAffixes decodes a UTF-8 .aff body.
+func parseAffixes(data string) (*affixFile, error) {
+	a := &affixFile{
+		prefixes: map[string][]affix{},
+		suffixes: map[string][]affix{},
+	}
+	open := map[string]*affixHeader{}
+	for n, line := range strings.Split(data, "\n") {
+		fields := strings.Fields(line)
+		if len(fields) == 0 || strings.HasPrefix(fields[0], "#") {
+			continue
+		}
+		if err := a.directive(fields, open); err != nil {
+			return nil, fmt.Errorf("line %d: %w", n+1, err)
+		}
+	}
+	return a, nil
+}
+
+// directive applies one .aff line. open tracks the PFX/SFX blocks
+// whose header has been read, keyed by kind and flag text.
+func (a *affixFile) directive(fields []string, open map[string]*affixHeader) error {
+	arg := func(i int) string {
+		if i < len(fields) {
+			return fields[i]
+		}
+		return ""
+	}
+	switch fields[0] {
+	case "FLAG":
+		switch arg(1) {
+		case "long":
+			a.mode = flagLong
+		case "num":
+			a.mode = flagNum
+		case "UTF-8", "char":
+			a.mode = flagChar
+		default:
+			return fmt.Errorf("unsupported FLAG %q", arg(1))
+		}
+	case "TRY":
+		a.try = arg(1)
+	case "REP":
+		if len(fields) == 3 {
+			a.rep = append(a.rep, [2]string{
+				strings.ReplaceAll(fields[1], "_", " "),
+				strings.ReplaceAll(fields[2], "_", " "),
+			})
+		}
+	case "AF":
+		if len(fields) == 2 {
+			if !a.aliasCount {
+				a.aliasCount = true // the first AF line is the count
+				return nil
+			}
+			fs, err := parseFlags(fields[1], a.mode)
+			if err != nil {
+				return err
+			}
+			a.aliases = append(a.aliases, fs)
+		}
+	case "FORBIDDENWORD", "NEEDAFFIX", "NOSUGGEST", "KEEPCASE":
+		fs, err := parseFlags(arg(1), a.mode)
+		if err != nil || len(fs) != 1 {
+			return fmt.Errorf("%s needs one flag", fields[0])
+		}
+		a.setAttribute(fields[0], fs[0])
+	case "PFX", "SFX":
+		return a.affixLine(fields, open)
+	}
+	return nil
+}
+
+func (a *affixFile) setAttribute(name string, f flag) {
+	switch name {
+	case "FORBIDDENWORD":
+		a.forbidden = f
+	case "NEEDAFFIX":
+		a.needAffix = f
+	case "NOSUGGEST":
+		a.noSuggest = f
+	case "KEEPCASE":
+		a.keepCase = f
+	}
+}
+
+// affixLine reads a PFX/SFX header ("SFX S Y 4") or one of the rule
+// lines that follow it ("SFX S y ies [^aeiou]y").
+func (a *affixFile) affixLine(fields []string, open map[string]*affixHeader) error {
+	if len(fields) < 4 {
+		return fmt.Errorf("%s needs at least 3 fields", fields[0])
+	}
+	key := fields[0] + " " + fields[1]
+	h := open[key]
+	if h == nil || h.left == 0 {
+		fs, err := parseFlags(fields[1], a.mode)
+		if err != nil || len(fs) != 1 {
+			return fmt.Errorf("%s %s: bad flag", fields[0], fields[1])
+		}
+		count, err := strconv.Atoi(fields[3])
+		if err != nil {
+			return fmt.Errorf("%s %s: bad rule count %q", fields[0], fields[1], fields[3])
+		}
+		open[key] = &affixHeader{flag: fs[0], cross: fields[2] == "Y", left: count}
+		return nil
+	}
+	h.left--
+	cond := "."
+	if len(fields) > 4 {
+		cond = fields[4]
+	}
+	c, err := parseCondition(cond)
+	if err != nil {
+		return err
+	}
+	add, _, _ := strings.Cut(fields[3], "/") // continuation flags are not supported
+	e := affix{strip: zero(fields[2]), add: zero(add), cond: c, flag: h.flag, cross: h.cross}
+	if fields[0] == "PFX" {
+		a.prefixes[e.add] = append(a.prefixes[e.add], e)
+		a.maxPrefix = max(a.maxPrefix, len(e.add))
+	} else {
+		a.suffixes[e.add] = append(a.suffixes[e.add], e)
+		a.maxSuffix = max(a.maxSuffix, len(e.add))
+	}
+	return nil
+}
+
+// zero maps the .aff placeholder "0" to the empty string.
+func zero(s string) string {
+	if s == "0" {
+		return ""
+	}
+	return s
+}
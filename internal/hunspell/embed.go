@@ -0,0 +1,23 @@
+//go:build !(js && wasm)
+
+package hunspell
+
+import "embed"
+
+// embeddedFS holds the built-in en_US dictionary (see data/LICENSE).
+// It is left out of the js/wasm build to keep the download small.
+//
+//go:embed data/en_US.aff data/en_US.dic.gz
+var embeddedFS embed.FS
+
+// embeddedNames lists the dictionaries built into the binary.
+var embeddedNames = []string{"en_US"}
+
+func readEmbedded(name string) (aff, dicGz []byte, err error) {
+	aff, err = embeddedFS.ReadFile("data/" + name + ".aff")
+	if err != nil {
+		return nil, nil, err
+	}
+	dicGz, err = embeddedFS.ReadFile("data/" + name + ".dic.gz")
+	return aff, dicGz, err
+}
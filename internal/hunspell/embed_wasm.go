@@ -0,0 +1,12 @@
+//go:build js && wasm
+
+package hunspell
+
+import "errors"
+
+// The js/wasm build embeds no dictionary; Embedded reports ok=false.
+var embeddedNames []string
+
+func readEmbedded(string) (aff, dicGz []byte, err error) {
+	return nil, nil, errors.New("no embedded dictionaries in this build")
+}
@@ -0,0 +1,62 @@
+package hunspell
+
+import (
+	"bytes"
+	"compress/gzip"
+	"fmt"
+	"io"
+	"slices"
+	"sync"
+)
+
+// embeddedCache holds each embedded dictionary once parsed, so every
+// rule instance and every file shares one copy.
+var embeddedCache sync.Map // string -> *embeddedEntry
+
+type embeddedEntry struct {
+	once sync.Once
+	dict *Dictionary
+	err  error
+}
+
+// Embedded returns the built-in dictionary called name (e.g.
+// "en_US"), parsing it on first use. ok is false when this build has
+// no such dictionary; the js/wasm build embeds none.
+func Embedded(name string) (d *Dictionary, ok bool, err error) {
+	if !slices.Contains(embeddedNames, name) {
+		return nil, false, nil
+	}
+	v, found := embeddedCache.Load(name)
+	if !found {
+		v, _ = embeddedCache.LoadOrStore(name, &embeddedEntry{})
+	}
+	e := v.(*embeddedEntry)
+	e.once.Do(func() {
+		aff, dicGz, err := readEmbedded(name)
+		if err == nil {
+			e.dict, err = parseCompressed(aff, dicGz)
+		}
+		if err != nil {
+			e.err = fmt.Errorf("hunspell: embedded %s: %w", name, err)
+		}
+	})
+	return e.dict, true, e.err
+}
+
+// EmbeddedNames lists the dictionaries Embedded can load in this
+// build.
+func EmbeddedNames() []string {
+	return slices.Clone(embeddedNames)
+}
+
+func parseCompressed(aff, dicGz []byte) (*Dictionary, error) {
+	zr, err := gzip.NewReader(bytes.NewReader(dicGz))
+	if err != nil {
+		return nil, err
+	}
+	dic, err := io.ReadAll(zr)
+	if err != nil {
+		return nil, err
+	}
+	return Parse(aff, dic)
+}
@@ -0,0 +1,305 @@
+// Package hunspell reads Hunspell dictionaries — an affix file (.aff)
+// and a word list (.dic) — and checks and suggests spellings with
+// them. It covers the subset of the format that word-level checking
+// needs: single-character, long, and numeric flags with AF aliases,
+// PFX/SFX rules with conditions and cross products, REP and TRY for
+// suggestions, and the FORBIDDENWORD, NEEDAFFIX, NOSUGGEST, and
+// KEEPCASE attributes. Compounding, continuation classes (two-level
+// affixes), and morphology are not supported; a dictionary that
+// relies on them accepts fewer words than Hunspell would.
+package hunspell
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+	"unsafe"
+)
+
+// Dictionary is a parsed Hunspell dictionary. It is read-only after
+// Parse and safe for concurrent use.
+type Dictionary struct {
+	words map[string][]flag
+	aff   *affixFile
+}
+
+// Parse builds a Dictionary from the bytes of an .aff and a .dic file.
+// Both must be UTF-8 or, when the .aff declares SET ISO8859-1, Latin-1.
+func Parse(aff, dic []byte) (*Dictionary, error) {
+	latin1, err := latin1Encoded(aff)
+	if err != nil {
+		return nil, err
+	}
+	affText, dicText := string(aff), string(dic)
+	if latin1 {
+		affText, dicText = fromLatin1(aff), fromLatin1(dic)
+	}
+	a, err := parseAffixes(affText)
+	if err != nil {
+		return nil, fmt.Errorf("affix file: %w", err)
+	}
+	d := &Dictionary{aff: a, words: map[string][]flag{}}
+	if err := d.parseWords(dicText); err != nil {
+		return nil, fmt.Errorf("dictionary file: %w", err)
+	}
+	return d, nil
+}
+
+// latin1Encoded reads the SET directive. UTF-8 (or no SET) returns
+// false; ISO8859-1 returns true; any other encoding is an error.
+func latin1Encoded(aff []byte) (bool, error) {
+	for _, line := range strings.Split(string(aff), "\n") {
+		fields := strings.Fields(line)
+		if len(fields) < 2 || fields[0] != "SET" {
+			continue
+		}
+		switch strings.ToUpper(fields[1]) {
+		case "UTF-8":
+			return false, nil
+		case "ISO8859-1", "ISO-8859-1":
+			return true, nil
+		}
+		return false, fmt.Errorf("affix file: unsupported encoding %q (use UTF-8 or ISO8859-1)", fields[1])
+	}
+	return false, nil
+}
+
+// fromLatin1 decodes ISO 8859-1 bytes, whose code points equal their
+// byte values.
+func fromLatin1(b []byte) string {
+	var sb strings.Builder
+	sb.Grow(len(b))
+	for _, c := range b {
+		sb.WriteRune(rune(c))
+	}
+	return sb.String()
+}
+
+// parseWords reads the .dic body: an optional leading entry count,
+// then one "word/FLAGS" entry per line. Anything after the first
+// space or tab (morphological fields) is ignored, "\/" is a literal
+// slash, and a repeated word merges its flags.
+func (d *Dictionary) parseWords(text string) error {
+	for n, line := range strings.Split(text, "\n") {
+		line = strings.TrimRight(line, "\r")
+		if n == 0 {
+			if _, err := strconv.Atoi(strings.TrimSpace(line)); err == nil {
+				continue
+			}
+		}
+		if i := strings.IndexAny(line, " \t"); i >= 0 {
+			line = line[:i]
+		}
+		if line == "" {
+			continue
+		}
+		word, flagText := splitEntry(line)
+		fs, err := d.entryFlags(flagText)
+		if err != nil {
+			return fmt.Errorf("line %d: %w", n+1, err)
+		}
+		d.words[word] = append(d.words[word], fs...)
+	}
+	return nil
+}
+
+// splitEntry splits "word/FLAGS" at the first unescaped slash.
+func splitEntry(entry string) (word, flags string) {
+	for i := 0; i < len(entry); i++ {
+		switch entry[i] {
+		case '\\':
+			i++
+		case '/':
+			return strings.ReplaceAll(entry[:i], `\/`, "/"), entry[i+1:]
+		}
+	}
+	return strings.ReplaceAll(entry, `\/`, "/"), ""
+}
+
+// entryFlags decodes a .dic flag field, resolving an AF alias number.
+func (d *Dictionary) entryFlags(s string) ([]flag, error) {
+	if s == "" {
+		return nil, nil
+	}
+	if len(d.aff.aliases) > 0 {
+		n, err := strconv.Atoi(s)
+		if err != nil || n < 1 || n > len(d.aff.aliases) {
+			return nil, fmt.Errorf("unknown flag alias %q", s)
+		}
+		return d.aff.aliases[n-1], nil
+	}
+	return parseFlags(s, d.aff.mode)
+}
+
+// Check reports whether word is spelled correctly: it is a
+// dictionary entry or an entry with one of its affixes. Case follows
+// Hunspell: a lowercase entry also accepts the capitalized and
+// all-caps forms unless it carries KEEPCASE, and an entry with
+// capitals accepts the all-caps form.
+func (d *Dictionary) Check(word string) bool {
+	if word == "" {
+		return true
+	}
+	if ok, known := d.checkForm(word, false); known {
+		return ok
+	}
+	var buf [64]byte
+	switch caseOf(word) {
+	case caseInitial:
+		ok, _ := d.checkForm(toLower(&buf, word), true)
+		return ok
+	case caseUpper:
+		lower := toLower(&buf, word)
+		if ok, _ := d.checkForm(lower, true); ok {
+			return true
+		}
+		ok, _ := d.checkForm(capitalize(lower), true)
+		return ok
+	}
+	return false
+}
+
+// toLower lowercases word. An ASCII word that fits buf is written
+// there and returned as a view of it, so checking a capitalized word
+// does not allocate; the view must not outlive the caller's buf.
+func toLower(buf *[64]byte, word string) string {
+	if len(word) > len(buf) {
+		return strings.ToLower(word)
+	}
+	for i := 0; i < len(word); i++ {
+		c := word[i]
+		if c >= utf8.RuneSelf {
+			return strings.ToLower(word)
+		}
+		if 'A' <= c && c <= 'Z' {
+			c += 'a' - 'A'
+		}
+		buf[i] = c
+	}
+	return unsafe.String(&buf[0], len(word))
+}
+
+// checkForm checks one case form. known reports a definite verdict
+// (an accepted form, or a FORBIDDENWORD entry) that later case
+// forms must not override. recased rejects KEEPCASE stems, which
+// only match their own spelling.
+func (d *Dictionary) checkForm(w string, recased bool) (ok, known bool) {
+	if fs, found := d.words[w]; found {
+		if d.aff.forbidden != 0 && hasFlag(fs, d.aff.forbidden) {
+			return false, true
+		}
+		if d.stemUsable(fs, recased) && (d.aff.needAffix == 0 || !hasFlag(fs, d.aff.needAffix)) {
+			return true, true
+		}
+	}
+	if d.checkSuffixed(w, 0, recased) || d.checkPrefixed(w, recased) {
+		return true, true
+	}
+	return false, false
+}
+
+// stemUsable reports whether a stem with flags fs may stand in for a
+// word whose case was changed to reach it.
+func (d *Dictionary) stemUsable(fs []flag, recased bool) bool {
+	return !recased || d.aff.keepCase == 0 || !hasFlag(fs, d.aff.keepCase)
+}
+
+// checkSuffixed reports whether w is a stem plus one suffix rule.
+// A non-zero pfx restricts the match to cross-product suffixes on a
+// stem that also carries the prefix flag pfx.
+func (d *Dictionary) checkSuffixed(w string, pfx flag, recased bool) bool {
+	for n := 0; n <= d.aff.maxSuffix && n <= len(w); n++ {
+		cut := len(w) - n
+		if cut < len(w) && !utf8.RuneStart(w[cut]) {
+			continue
+		}
+		for _, e := range d.aff.suffixes[w[cut:]] {
+			if pfx != 0 && !e.cross {
+				continue
+			}
+			stem := w[:cut] + e.strip
+			if stem == "" || !e.cond.matchEnd(stem) {
+				continue
+			}
+			fs, found := d.words[stem]
+			if !found || !hasFlag(fs, e.flag) || !d.stemUsable(fs, recased) {
+				continue
+			}
+			if pfx != 0 && !hasFlag(fs, pfx) {
+				continue
+			}
+			if d.aff.forbidden == 0 || !hasFlag(fs, d.aff.forbidden) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// checkPrefixed reports whether w is a stem plus one prefix rule,
+// optionally combined with a cross-product suffix.
+func (d *Dictionary) checkPrefixed(w string, recased bool) bool {
+	for n := 0; n <= d.aff.maxPrefix && n <= len(w); n++ {
+		if n < len(w) && !utf8.RuneStart(w[n]) {
+			continue
+		}
+		for _, e := range d.aff.prefixes[w[:n]] {
+			stem := e.strip + w[n:]
+			if stem == "" || !e.cond.matchStart(stem) {
+				continue
+			}
+			if fs, found := d.words[stem]; found && hasFlag(fs, e.flag) && d.stemUsable(fs, recased) &&
+				(d.aff.forbidden == 0 || !hasFlag(fs, d.aff.forbidden)) {
+				return true
+			}
+			if e.cross && d.checkSuffixed(stem, e.flag, recased) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// wordCase classifies the capitalization of a word.
+type wordCase uint8
+
+const (
+	caseLower   wordCase = iota // no capitals
+	caseInitial                 // one leading capital
+	caseUpper                   // all letters capital
+	caseMixed                   // any other mix, e.g. "GitHub"
+)
+
+func caseOf(w string) wordCase {
+	upper, letters := 0, 0
+	firstUpper := false
+	for i, r := range w {
+		if !unicode.IsLetter(r) {
+			continue
+		}
+		letters++
+		if unicode.IsUpper(r) {
+			upper++
+			if i == 0 {
+				firstUpper = true
+			}
+		}
+	}
+	switch {
+	case upper == 0:
+		return caseLower
+	case upper == letters && letters > 1:
+		return caseUpper
+	case upper == 1 && firstUpper:
+		return caseInitial
+	}
+	return caseMixed
+}
+
+// capitalize upper-cases the first rune of w.
+func capitalize(w string) string {
+	r, size := utf8.DecodeRuneInString(w)
+	return string(unicode.ToUpper(r)) + w[size:]
+}
@@ -0,0 +1,182 @@
+package hunspell
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const testAff = `SET UTF-8
+TRY esianrtolcdugmphbyfvkwz
+REP 1
+REP f ph
+FORBIDDENWORD !
+NEEDAFFIX ?
+KEEPCASE K
+NOSUGGEST N
+
+PFX U Y 1
+PFX U 0 un .
+
+SFX S Y 2
+SFX S y ies [^aeiou]y
+SFX S 0 s [^y]
+
+SFX D N 2
+SFX D 0 d e
+SFX D 0 ed [^e]
+`
+
+const testDic = `9
+cat/S
+fly/S
+do/U
+tie/SUD
+walk/D
+photo/S
+sing/?S
+catss/!
+\/etc
+`
+
+func mustParse(t *testing.T, aff, dic string) *Dictionary {
+	t.Helper()
+	d, err := Parse([]byte(aff), []byte(dic))
+	require.NoError(t, err)
+	return d
+}
+
+func TestCheck_AffixRules(t *testing.T) {
+	d := mustParse(t, testAff, testDic)
+	for word, want := range map[string]bool{
+		"cat":      true,
+		"cats":     true,
+		"flies":    true,
+		"flys":     false, // [^y] condition fails
+		"undo":     true,
+		"ties":     true,
+		"untie":    true,
+		"unties":   true, // cross-product prefix + suffix
+		"tied":     true,
+		"untied":   false, // D is not a cross-product class
+		"walked":   true,
+		"walks":    false, // no S flag
+		"sing":     false, // NEEDAFFIX: only with an affix
+		"sings":    true,
+		"catss":    false, // FORBIDDENWORD
+		"/etc":     true,  // escaped slash
+		"dog":      false,
+		"Cat":      true,
+		"CATS":     true,
+		"cAt":      false,
+		"Photos":   true,
+		"UNDO":     true,
+		"unphotos": false,
+	} {
+		assert.Equal(t, want, d.Check(word), word)
+	}
+}
+
+func TestCheck_KeepCase(t *testing.T) {
+	d := mustParse(t, testAff, "2\nmdsmith/K\nGo\n")
+	assert.True(t, d.Check("mdsmith"))
+	assert.False(t, d.Check("Mdsmith"))
+	assert.False(t, d.Check("MDSMITH"))
+	assert.True(t, d.Check("Go"))
+	assert.True(t, d.Check("GO"))
+	assert.False(t, d.Check("go"))
+}
+
+func TestParse_FlagModes(t *testing.T) {
+	long := "FLAG long\nSFX Aa Y 1\nSFX Aa 0 s .\n"
+	d := mustParse(t, long, "1\ncat/Aa\n")
+	assert.True(t, d.Check("cats"))
+
+	num := "FLAG num\nSFX 101 Y 1\nSFX 101 0 s .\nSFX 7 Y 1\nSFX 7 0 ed .\n"
+	d = mustParse(t, num, "1\nwalk/7,101\n")
+	assert.True(t, d.Check("walks"))
+	assert.True(t, d.Check("walked"))
+
+	alias := "AF 2\nAF S\nAF SD\nSFX S Y 1\nSFX S 0 s .\nSFX D Y 1\nSFX D 0 ed .\n"
+	d = mustParse(t, alias, "2\ncat/1\nwalk/2\n")
+	assert.True(t, d.Check("cats"))
+	assert.False(t, d.Check("cated"))
+	assert.True(t, d.Check("walked"))
+}
+
+func TestParse_Latin1(t *testing.T) {
+	aff := []byte("SET ISO8859-1\nSFX S Y 1\nSFX S 0 s .\n")
+	dic := []byte("1\ncaf\xe9/S\n")
+	d, err := Parse(aff, dic)
+	require.NoError(t, err)
+	assert.True(t, d.Check("cafés"))
+}
+
+func TestParse_Errors(t *testing.T) {
+	for name, tc := range map[string]struct{ aff, dic, want string }{
+		"encoding":  {"SET KOI8-R\n", "", `unsupported encoding "KOI8-R"`},
+		"flag mode": {"FLAG wide\n", "", `line 1: unsupported FLAG "wide"`},
+		"condition": {"SFX S Y 1\nSFX S 0 s [ab\n", "", "has an unclosed bracket"},
+		"count":     {"SFX S Y many\n", "", `bad rule count "many"`},
+		"alias":     {"AF 1\nAF S\n", "1\ncat/2\n", `line 2: unknown flag alias "2"`},
+		"long":      {"FLAG long\n", "1\ncat/A\n", "odd length"},
+	} {
+		t.Run(name, func(t *testing.T) {
+			_, err := Parse([]byte(tc.aff), []byte(tc.dic))
+			require.Error(t, err)
+			assert.Contains(t, err.Error(), tc.want)
+		})
+	}
+}
+
+func TestSuggest_EditFamilies(t *testing.T) {
+	d := mustParse(t, testAff, testDic+"a\nlot\nI\n")
+	assert.Equal(t, []string{"photo"}, d.Suggest("foto", 1), "REP")
+	assert.Equal(t, "cat", d.Suggest("act", 3)[0], "swap")
+	assert.Contains(t, d.Suggest("catt", 5), "cat", "extra character")
+	assert.Contains(t, d.Suggest("ct", 5), "cat", "missing character")
+	assert.Contains(t, d.Suggest("cot", 5), "cat", "wrong character")
+	assert.Contains(t, d.Suggest("alot", 5), "a lot", "two words")
+	assert.Equal(t, []string{"I"}, d.Suggest("i", 1), "capitalized")
+	assert.Equal(t, []string{"Cats"}, d.Suggest("Cast", 1), "keeps case")
+	assert.NotContains(t, d.Suggest("catsss", 5), "catss", "forbidden")
+	assert.Nil(t, d.Suggest("cat", 0))
+}
+
+func TestSuggest_NoSuggest(t *testing.T) {
+	d := mustParse(t, testAff, "2\ndamn/N\ndarn\n")
+	assert.Equal(t, []string{"darn"}, d.Suggest("dasn", 5))
+}
+
+func TestSuggest_SimilarFallback(t *testing.T) {
+	d := mustParse(t, testAff, "2\nconfiguration\nconfirmation\n")
+	assert.Equal(t, []string{"configuration"}, d.Suggest("confguratoin", 1))
+}
+
+func TestEmbedded_EnUS(t *testing.T) {
+	d, ok, err := Embedded("en_US")
+	require.NoError(t, err)
+	require.True(t, ok)
+	for _, w := range []string{"the", "receive", "walked", "creation", "Markdown", "I'm", "configs"} {
+		assert.True(t, d.Check(w), w)
+	}
+	for _, w := range []string{"recieve", "teh", "definately", "i"} {
+		assert.False(t, d.Check(w), w)
+	}
+	assert.Equal(t, "receive", d.Suggest("recieve", 1)[0])
+	assert.Equal(t, []string{"en_US"}, EmbeddedNames())
+
+	again, _, _ := Embedded("en_US")
+	assert.Same(t, d, again, "parsed once")
+
+	_, ok, err = Embedded("xx_XX")
+	assert.False(t, ok)
+	assert.NoError(t, err)
+}
+
+func TestSuggest_SkipsCaseVariants(t *testing.T) {
+	aff := "TRY iI\n"
+	d := mustParse(t, aff, "2\nink\nInk\n")
+	assert.Equal(t, []string{"ink"}, d.Suggest("lnk", 5))
+}
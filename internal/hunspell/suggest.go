@@ -0,0 +1,224 @@
+package hunspell
+
+import (
+	"sort"
+	"strings"
+	"unicode/utf8"
+)
+
+// Suggest returns up to limit correctly spelled alternatives for
+// word, best first. It tries Hunspell's edit families in its order —
+// a capitalized form, REP replacements, swapped neighbours, an extra character, a
+// missing TRY character, a wrong TRY character, and a split into two
+// words — and falls back to the dictionary entries sharing the most
+// letter pairs with word when no single edit yields a word.
+// Suggestions keep the capitalization of word, and entries marked
+// NOSUGGEST or FORBIDDENWORD are never offered.
+func (d *Dictionary) Suggest(word string, limit int) []string {
+	if limit <= 0 || word == "" {
+		return nil
+	}
+	s := suggester{d: d, limit: limit, seen: map[string]bool{word: true}, wcase: caseOf(word)}
+	base := word
+	if s.wcase == caseInitial || s.wcase == caseUpper {
+		base = strings.ToLower(word)
+	}
+	if s.wcase == caseLower {
+		s.try(capitalize(word))
+		s.try(strings.ToUpper(word))
+	}
+	s.replacements(base)
+	rs := []rune(base)
+	s.swaps(rs)
+	s.deletions(rs)
+	s.insertions(rs)
+	s.substitutions(rs)
+	s.splits(rs)
+	if len(s.out) == 0 {
+		s.similar(base)
+	}
+	return s.out
+}
+
+// suggester collects candidates in order until limit is reached.
+type suggester struct {
+	d     *Dictionary
+	seen  map[string]bool
+	out   []string
+	limit int
+	wcase wordCase
+}
+
+func (s *suggester) full() bool { return len(s.out) >= s.limit }
+
+// try recases cand to the misspelling's case and keeps it when it is
+// a word the dictionary would suggest.
+func (s *suggester) try(cand string) {
+	if s.full() || cand == "" {
+		return
+	}
+	switch s.wcase {
+	case caseInitial:
+		cand = capitalize(cand)
+	case caseUpper:
+		cand = strings.ToUpper(cand)
+	}
+	if s.seen[cand] {
+		return
+	}
+	s.seen[cand] = true
+	for _, o := range s.out {
+		if strings.EqualFold(o, cand) {
+			return // "ink" already offered; "Ink" adds nothing
+		}
+	}
+	for _, part := range strings.Split(cand, " ") {
+		if !s.d.Check(part) || s.d.noSuggest(part) {
+			return
+		}
+	}
+	s.out = append(s.out, cand)
+}
+
+// noSuggest reports whether w is an entry marked NOSUGGEST.
+func (d *Dictionary) noSuggest(w string) bool {
+	fs, ok := d.words[strings.ToLower(w)]
+	if !ok {
+		fs, ok = d.words[w]
+	}
+	return ok && d.aff.noSuggest != 0 && hasFlag(fs, d.aff.noSuggest)
+}
+
+// replacements applies each REP pair at every position it matches.
+func (s *suggester) replacements(w string) {
+	for _, rep := range s.d.aff.rep {
+		for i := 0; !s.full(); {
+			j := strings.Index(w[i:], rep[0])
+			if j < 0 {
+				break
+			}
+			at := i + j
+			s.try(w[:at] + rep[1] + w[at+len(rep[0]):])
+			i = at + 1
+		}
+	}
+}
+
+// swaps exchanges each pair of neighbouring characters.
+func (s *suggester) swaps(rs []rune) {
+	for i := 0; i+1 < len(rs) && !s.full(); i++ {
+		if rs[i] == rs[i+1] {
+			continue
+		}
+		rs[i], rs[i+1] = rs[i+1], rs[i]
+		s.try(string(rs))
+		rs[i], rs[i+1] = rs[i+1], rs[i]
+	}
+}
+
+// deletions drops one character.
+func (s *suggester) deletions(rs []rune) {
+	for i := range rs {
+		if s.full() {
+			return
+		}
+		s.try(string(rs[:i]) + string(rs[i+1:]))
+	}
+}
+
+// insertions adds one TRY character at each position.
+func (s *suggester) insertions(rs []rune) {
+	for _, c := range s.d.aff.try {
+		for i := 0; i <= len(rs) && !s.full(); i++ {
+			s.try(string(rs[:i]) + string(c) + string(rs[i:]))
+		}
+	}
+}
+
+// substitutions replaces one character with a TRY character.
+func (s *suggester) substitutions(rs []rune) {
+	for _, c := range s.d.aff.try {
+		for i := 0; i < len(rs) && !s.full(); i++ {
+			if rs[i] == c {
+				continue
+			}
+			old := rs[i]
+			rs[i] = c
+			s.try(string(rs))
+			rs[i] = old
+		}
+	}
+}
+
+// splits inserts a space so both halves are words ("alot" → "a lot").
+func (s *suggester) splits(rs []rune) {
+	for i := 1; i < len(rs) && !s.full(); i++ {
+		s.try(string(rs[:i]) + " " + string(rs[i:]))
+	}
+}
+
+// minSimilarity is the letter-pair overlap (Dice coefficient) a
+// dictionary entry needs to be offered by the fallback search.
+const minSimilarity = 0.5
+
+// similar offers the dictionary entries whose letter pairs overlap
+// most with w, for misspellings more than one edit away.
+func (s *suggester) similar(w string) {
+	pairs := letterPairs(w)
+	n := utf8.RuneCountInString(w)
+	type scored struct {
+		word  string
+		score float64
+	}
+	var found []scored
+	for entry, fs := range s.d.words {
+		if s.d.aff.needAffix != 0 && hasFlag(fs, s.d.aff.needAffix) {
+			continue
+		}
+		m := utf8.RuneCountInString(entry)
+		if m < n-2 || m > n+2 {
+			continue
+		}
+		if score := dice(pairs, letterPairs(strings.ToLower(entry))); score >= minSimilarity {
+			found = append(found, scored{entry, score})
+		}
+	}
+	sort.Slice(found, func(i, j int) bool {
+		if found[i].score != found[j].score {
+			return found[i].score > found[j].score
+		}
+		return found[i].word < found[j].word
+	})
+	for _, f := range found {
+		s.try(f.word)
+	}
+}
+
+// letterPairs returns the overlapping two-rune substrings of w.
+func letterPairs(w string) []string {
+	rs := []rune(w)
+	if len(rs) < 2 {
+		return []string{w}
+	}
+	out := make([]string, 0, len(rs)-1)
+	for i := 0; i+1 < len(rs); i++ {
+		out = append(out, string(rs[i:i+2]))
+	}
+	return out
+}
+
+// dice is the Dice coefficient of two letter-pair multisets.
+func dice(a, b []string) float64 {
+	left := make(map[string]int, len(a))
+	for _, p := range a {
+		left[p]++
+	}
+	shared := 0
+	for _, p := range b {
+		if left[p] > 0 {
+			left[p]--
+			shared++
+		}
+	}
+	return 2 * float64(shared) / float64(len(a)+len(b))
+}
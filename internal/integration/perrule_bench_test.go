@@ -61,13 +61,14 @@ import (
 // legitimately scales with the number of diagnostics. Notably it uses
 // inline links only (no reference definitions), so neither the
 // default reference-label rules (MDS053/MDS054) nor the opt-in
-// no-reference-style rule (MDS043) fire.
+// no-reference-style rule (MDS043) fire. Its sections carry numbers,
+// so the opt-in heading-numbering rule (MDS082) stays quiet too.
 func perRuleBenchDoc() string {
 	const sections = 12
 	parts := make([]string, 0, sections)
 	for s := 0; s < sections; s++ {
 		var b strings.Builder
-		fmt.Fprintf(&b, "## Section %d\n\n", s)
+		fmt.Fprintf(&b, "## %d Section %d\n\n", s+1, s)
 		b.WriteString("A short prose paragraph for the readability and structural\n")
 		b.WriteString("rules to scan here. It stays one paragraph in length.\n\n")
 		b.WriteString("See [the other doc](other.md) for the related details here.\n\n")
@@ -316,7 +317,18 @@ var perRuleAllocCeiling = map[string]float64{
 	"MDS071": 4,   // required-frontmatter: 0 allocs (inert without fields)
 	// MDS072 (external-link-check) is network-bound and excluded from
 	// this gate via isNetworkBound; it has no alloc ceiling here.
-	"MDS073": 4, // slide-structure: 0 allocs (inert without slide markers)
+	"MDS073": 4,  // slide-structure: 0 allocs (inert without slide markers)
+	"MDS075": 4,  // metric-regression: 0 allocs (inert without baseline or ref)
+	"MDS076": 16, // spelling: ~13 allocs (diagnostics for the fixture's unknown words)
+	"MDS077": 4,  // data: 0 allocs (inert without a <?data?> directive)
+	"MDS078": 4,  // backlinks: 0 allocs (inert without a <?backlinks?> directive)
+	"MDS079": 4,  // tree: 0 allocs (inert without a <?tree?> directive)
+	"MDS080": 4,  // history: 0 allocs (inert without a config path)
+	"MDS081": 4,  // user-directive: 0 allocs (inert without definitions)
+	"MDS082": 24, // heading-numbering: ~18 allocs (plain text per heading)
+	"MDS083": 4,  // code-block-syntax: ~1 alloc (cached verdict per go fence)
+	"MDS084": 4,  // mermaid-syntax: 0 allocs (no mermaid fences)
+	"MDS085": 4,  // reachability: 0 allocs (no README.md entry point to walk from)
 }
 
 // init pins MDS043's allocs ceiling from the build-tagged
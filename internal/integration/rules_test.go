@@ -14,6 +14,7 @@ import (
 
 	_ "github.com/jeduden/mdsmith/internal/rules/ambiguousemphasis"
 	_ "github.com/jeduden/mdsmith/internal/rules/atxheadingwhitespace"
+	_ "github.com/jeduden/mdsmith/internal/rules/backlinks"
 	_ "github.com/jeduden/mdsmith/internal/rules/blanklinearoundfencedcode"
 	_ "github.com/jeduden/mdsmith/internal/rules/blanklinearoundheadings"
 	_ "github.com/jeduden/mdsmith/internal/rules/blanklinearoundlists"
@@ -22,9 +23,11 @@ import (
 	_ "github.com/jeduden/mdsmith/internal/rules/callouttype"
 	_ "github.com/jeduden/mdsmith/internal/rules/catalog"
 	_ "github.com/jeduden/mdsmith/internal/rules/codeblockstyle"
+	_ "github.com/jeduden/mdsmith/internal/rules/codeblocksyntax"
 	_ "github.com/jeduden/mdsmith/internal/rules/commandsshowoutput"
 	_ "github.com/jeduden/mdsmith/internal/rules/concisenessscoring"
 	_ "github.com/jeduden/mdsmith/internal/rules/crossfilereferenceintegrity"
+	_ "github.com/jeduden/mdsmith/internal/rules/data"
 	_ "github.com/jeduden/mdsmith/internal/rules/descriptivelinktext"
 	"github.com/jeduden/mdsmith/internal/rules/directorystructure"
 	_ "github.com/jeduden/mdsmith/internal/rules/duplicatedcontent"
@@ -38,7 +41,9 @@ import (
 	_ "github.com/jeduden/mdsmith/internal/rules/forbiddentext"
 	_ "github.com/jeduden/mdsmith/internal/rules/githooksync"
 	_ "github.com/jeduden/mdsmith/internal/rules/headingincrement"
+	_ "github.com/jeduden/mdsmith/internal/rules/headingnumbering"
 	_ "github.com/jeduden/mdsmith/internal/rules/headingstyle"
+	_ "github.com/jeduden/mdsmith/internal/rules/history"
 	_ "github.com/jeduden/mdsmith/internal/rules/horizontalrulestyle"
 	_ "github.com/jeduden/mdsmith/internal/rules/include"
 	_ "github.com/jeduden/mdsmith/internal/rules/linelength"
@@ -50,6 +55,8 @@ import (
 	_ "github.com/jeduden/mdsmith/internal/rules/markdownflavor"
 	_ "github.com/jeduden/mdsmith/internal/rules/maxfilelength"
 	_ "github.com/jeduden/mdsmith/internal/rules/maxsectionlength"
+	_ "github.com/jeduden/mdsmith/internal/rules/mermaidsyntax"
+	_ "github.com/jeduden/mdsmith/internal/rules/metricregression"
 	_ "github.com/jeduden/mdsmith/internal/rules/nobareurls"
 	_ "github.com/jeduden/mdsmith/internal/rules/noduplicateheadings"
 	_ "github.com/jeduden/mdsmith/internal/rules/noemphasisasheading"
@@ -68,6 +75,7 @@ import (
 	_ "github.com/jeduden/mdsmith/internal/rules/paragraphreadability"
 	_ "github.com/jeduden/mdsmith/internal/rules/paragraphstructure"
 	_ "github.com/jeduden/mdsmith/internal/rules/propernames"
+	_ "github.com/jeduden/mdsmith/internal/rules/reachability"
 	_ "github.com/jeduden/mdsmith/internal/rules/recipesafety"
 	_ "github.com/jeduden/mdsmith/internal/rules/requiredfrontmatter"
 	_ "github.com/jeduden/mdsmith/internal/rules/requiredmentions"
@@ -77,13 +85,16 @@ import (
 	_ "github.com/jeduden/mdsmith/internal/rules/singleh1"
 	_ "github.com/jeduden/mdsmith/internal/rules/singletrailingnewline"
 	_ "github.com/jeduden/mdsmith/internal/rules/slidevstructure"
+	_ "github.com/jeduden/mdsmith/internal/rules/spelling"
 	_ "github.com/jeduden/mdsmith/internal/rules/tableformat"
 	_ "github.com/jeduden/mdsmith/internal/rules/tablereadability"
 	_ "github.com/jeduden/mdsmith/internal/rules/toc"
 	_ "github.com/jeduden/mdsmith/internal/rules/tocdirective"
 	_ "github.com/jeduden/mdsmith/internal/rules/tokenbudget"
+	_ "github.com/jeduden/mdsmith/internal/rules/tree"
 	_ "github.com/jeduden/mdsmith/internal/rules/unclosedcodeblock"
 	_ "github.com/jeduden/mdsmith/internal/rules/uniquefrontmatter"
+	_ "github.com/jeduden/mdsmith/internal/rules/userdirective"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
@@ -353,9 +364,11 @@ func attachFixtureFS(f *lint.File, dir string, r rule.Rule) {
 
 // fixtureFilePath returns the value to use as f.Path when running a
 // fixture. For rules whose Check inspects git state (currently
-// MDS048), it returns a path inside a fresh non-repo tempdir so the
-// fixture cannot fail based on the contributor's local git config or
-// installed hooks. For rules that resolve paths against the project
+// MDS048 and MDS080), it returns a path inside a fresh non-repo
+// tempdir so the fixture cannot fail based on the contributor's local
+// git config, installed hooks, or commit history. MDS080 only runs
+// git for a trusted build, so its fixtures also set
+// MDSMITH_TRUST_BUILD. For rules that resolve paths against the project
 // root (currently MDS019), it returns the fixture's absolute path so
 // projectRelFileDir-style logic computes the same root-relative
 // directory it would for a real `mdsmith check <abs-path>` invocation.
@@ -366,6 +379,10 @@ func fixtureFilePath(t *testing.T, r rule.Rule, filePath string) string {
 	if r != nil && r.ID() == "MDS048" {
 		return filepath.Join(t.TempDir(), filepath.Base(filePath))
 	}
+	if r != nil && r.ID() == "MDS080" {
+		t.Setenv("MDSMITH_TRUST_BUILD", "1")
+		return filepath.Join(t.TempDir(), filepath.Base(filePath))
+	}
 	if r != nil && r.ID() == "MDS019" {
 		abs, err := filepath.Abs(filePath)
 		require.NoError(t, err)
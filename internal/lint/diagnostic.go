@@ -64,9 +64,22 @@ type Diagnostic struct {
 	// surface (see rules.DocURL), so there is no per-diagnostic field.
 	RelatedLocations []RelatedLocation
 
+	// Suggestions lists replacement texts for the span the diagnostic
+	// covers, best first — for MDS076, the dictionary's spellings of an
+	// unknown word. The LSP offers each as a quick fix that replaces
+	// the span; the CLI prints them in the message. Nil when the rule
+	// has no replacement to offer.
+	Suggestions []string
+
 	Line   int
 	Column int
 
+	// EndColumn is the 1-based column just past the flagged span on
+	// Line, or 0 when the diagnostic marks a position rather than a
+	// span. The LSP uses it to end the diagnostic range (otherwise the
+	// range runs to the end of the line).
+	EndColumn int
+
 	// SourceStartLine is the 1-based line number of the first entry in
 	// SourceLines.
 	SourceStartLine int
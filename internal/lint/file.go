@@ -43,12 +43,24 @@ type File struct {
 	GitignoreFunc func() *gitignore.Matcher
 	gitignoreVal  *gitignore.Matcher
 
+	// KindsFunc resolves the effective kinds of another workspace
+	// file from its project-relative path and front-matter block,
+	// kind-assignment globs included. The engine wires it from the
+	// loaded config; nil falls back to the front matter's `kinds:`.
+	KindsFunc func(path string, fm []byte) []string
+
 	// GeneratedRanges records the content line ranges of generated
 	// sections (<?include?> / <?catalog?> bodies). Diagnostics whose
 	// line falls within these ranges are suppressed when linting the
 	// host file — the source file is responsible for those bytes.
 	GeneratedRanges []LineRange
 
+	// GeneratedDirectives names the configured directives, beyond the
+	// built-in ones, whose section bodies are generated (such as
+	// user-defined directives). Callers set it from the file's
+	// effective config before computing GeneratedRanges.
+	GeneratedDirectives []string
+
 	// newlineOffsets caches the byte offset of every '\n' in Source,
 	// built once on first LineOfOffset call. Without it LineOfOffset
 	// rescans Source from byte 0 on every call, which made it ~24%
@@ -442,6 +454,17 @@ func (f *File) GetGitignore() *gitignore.Matcher {
 	return f.gitignoreVal
 }
 
+// Kinds returns the effective kinds of the workspace file at path
+// (project-relative, slash-separated) with front-matter block fm.
+// Without a KindsFunc only the front matter's own `kinds:` count.
+func (f *File) Kinds(path string, fm []byte) []string {
+	if f.KindsFunc != nil {
+		return f.KindsFunc(path, fm)
+	}
+	kinds, _ := ParseFrontMatterKinds(fm)
+	return kinds
+}
+
 // NewParser returns mdsmith's canonical goldmark parser, forwarded
 // from pkg/markdown. Rules that need to re-inspect a document (for
 // example, to consult the link reference definition map) should use
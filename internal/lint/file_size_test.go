@@ -24,8 +24,12 @@ import (
 // scalars per the same ordering rule. 656 crosses a Go allocator
 // size-class boundary from 640's class (641-704 all round up to 704,
 // measured) — see headingTextCache's own comment in file.go for why
-// the two new fields were kept anyway.
-const fileSizeBudget = 656
+// the two new fields were kept anyway. 664 adds KindsFunc, a func
+// pointer beside GitignoreFunc; it stays inside the 704-byte class,
+// so the heap cost per File is unchanged. 688 adds GeneratedDirectives,
+// a slice header beside GeneratedRanges, still inside the 704-byte
+// class.
+const fileSizeBudget = 688
 
 func TestFile_SizeBudget(t *testing.T) {
 	got := unsafe.Sizeof(File{})
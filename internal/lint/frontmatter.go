@@ -74,6 +74,28 @@ func ParseFrontMatterKinds(fm []byte) ([]string, error) {
 	return parsed.Kinds, nil
 }
 
+// ParseFrontMatterLang returns the string value of the top-level
+// lang: key in a YAML front-matter block (including its ---
+// delimiters), or "" when the block is empty, has no lang key, holds
+// a non-string lang, or does not parse. Prose rules read it to pick
+// a language; an unusable value just leaves the configured one.
+func ParseFrontMatterLang(fm []byte) string {
+	if len(fm) == 0 || !bytes.Contains(fm, []byte("lang:")) {
+		return ""
+	}
+	delim := []byte("---\n")
+	body := bytes.TrimPrefix(fm, delim)
+	body = bytes.TrimSuffix(body, delim)
+	var parsed struct {
+		Lang any `yaml:"lang"`
+	}
+	if err := yamlutil.UnmarshalSafe(body, &parsed); err != nil {
+		return ""
+	}
+	lang, _ := parsed.Lang.(string)
+	return lang
+}
+
 // ParseFrontMatterFields decodes a YAML front-matter block (including its
 // --- delimiters) into a map of top-level keys to raw values. Returns
 // (nil, nil) when fm is empty, whitespace-only, or decodes to YAML null.
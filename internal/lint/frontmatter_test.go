@@ -193,3 +193,23 @@ func TestParseFrontMatterFields(t *testing.T) {
 		}
 	})
 }
+
+func TestParseFrontMatterLang(t *testing.T) {
+	tests := []struct {
+		name string
+		fm   string
+		want string
+	}{
+		{"empty", "", ""},
+		{"no lang key", "---\ntitle: x\n---\n", ""},
+		{"lang", "---\ntitle: x\nlang: de-AT\n---\n", "de-AT"},
+		{"non-string lang", "---\nlang: [de]\n---\n", ""},
+		{"nested lang only", "---\nmeta:\n  lang: de\n---\n", ""},
+		{"invalid yaml", "---\nlang: [de\n---\n", ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, ParseFrontMatterLang([]byte(tt.fm)))
+		})
+	}
+}
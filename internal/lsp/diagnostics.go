@@ -52,6 +52,9 @@ func toLSPWithRoot(d lint.Diagnostic, lines [][]byte, root, resolvedRoot string)
 	line := currentLineBytes(lines, d.Line)
 	startCol := mdtext.UTF16FromByteOffset(line, d.Column-1)
 	endCol := utf16Length(line)
+	if d.EndColumn > d.Column && d.EndColumn-1 <= len(line) {
+		endCol = mdtext.UTF16FromByteOffset(line, d.EndColumn-1)
+	}
 	out := Diagnostic{
 		Range: Range{
 			Start: Position{Line: startLine, Character: startCol},
@@ -62,9 +65,10 @@ func toLSPWithRoot(d lint.Diagnostic, lines [][]byte, root, resolvedRoot string)
 		Source:   "mdsmith",
 		Message:  d.Message,
 		Data: &diagnosticData{
-			RuleName:   d.RuleName,
-			Deprecated: d.Deprecated,
-			ReplacedBy: d.ReplacedBy,
+			RuleName:    d.RuleName,
+			Deprecated:  d.Deprecated,
+			ReplacedBy:  d.ReplacedBy,
+			Suggestions: d.Suggestions,
 		},
 	}
 	if ri := relatedInformation(d.RelatedLocations, root, resolvedRoot); len(ri) > 0 {
@@ -21,8 +21,12 @@ import (
 // Only directives with a dedicated section in the mapped guide are listed here;
 // toc (MDS038) and ignore have no guide page yet and are omitted.
 var directiveToDocFile = map[string]string{
+	"backlinks":           "generating-content.md",
 	"catalog":             "generating-content.md",
+	"data":                "generating-content.md",
+	"history":             "generating-content.md",
 	"include":             "generating-content.md",
+	"tree":                "generating-content.md",
 	"build":               "build.md",
 	"allow-empty-section": "enforcing-structure.md",
 	"require":             "enforcing-structure.md",
@@ -230,10 +230,15 @@ type codeDescription struct {
 // adds for schema field deprecations: clients that route warnings
 // (e.g. surface a "migrate to <new>" quick-fix hint) read these
 // without scanning the human-facing message body.
+//
+// Suggestions carries lint.Diagnostic's replacement texts (MDS076
+// spellings); appendQuickFixActions turns each into a quick fix that
+// replaces the diagnostic's range.
 type diagnosticData struct {
-	RuleName   string `json:"rule"`
-	Deprecated bool   `json:"deprecated,omitempty"`
-	ReplacedBy string `json:"replaced_by,omitempty"`
+	RuleName    string   `json:"rule"`
+	Deprecated  bool     `json:"deprecated,omitempty"`
+	ReplacedBy  string   `json:"replaced_by,omitempty"`
+	Suggestions []string `json:"suggestions,omitempty"`
 }
 
 // publishDiagnosticsParams is LSP §3.18.6 PublishDiagnosticsParams.
@@ -3,6 +3,7 @@ package lsp
 import (
 	"bytes"
 	"encoding/json"
+	"strconv"
 	"strings"
 
 	"github.com/hexops/gotextdiff"
@@ -157,6 +158,7 @@ func (s *Server) appendQuickFixActions(
 		if d.Data == nil || d.Data.RuleName == "" {
 			continue
 		}
+		actions = appendSuggestionActions(actions, p.TextDocument.URI, d)
 		rule := d.Data.RuleName
 		edit, seen := ruleEdits[rule]
 		if !seen {
@@ -179,6 +181,24 @@ func (s *Server) appendQuickFixActions(
 	return actions
 }
 
+// appendSuggestionActions appends one quick fix per replacement text
+// the diagnostic carries (MDS076 spellings), best first. Unlike the
+// whole-file rule fixes, each edit replaces only the diagnostic's own
+// range, so picking a spelling touches nothing else in the document.
+func appendSuggestionActions(actions []codeAction, uri string, d Diagnostic) []codeAction {
+	for _, sug := range d.Data.Suggestions {
+		actions = append(actions, codeAction{
+			Title:       "Change to " + strconv.Quote(sug),
+			Kind:        kindQuickFix,
+			Diagnostics: []Diagnostic{d},
+			Edit: &workspaceEdit{
+				Changes: map[string][]textEdit{uri: {{Range: d.Range, NewText: sug}}},
+			},
+		})
+	}
+	return actions
+}
+
 // appendFixAllAction computes the source.fixAll.mdsmith action and
 // appends it to actions when the fix produces a change.
 func (s *Server) appendFixAllAction(
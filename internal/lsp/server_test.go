@@ -979,6 +979,54 @@ func TestComputeCodeActionsCachesNilEdits(t *testing.T) {
 	assert.Empty(t, actions)
 }
 
+// TestComputeCodeActionsOffersSuggestions pins the spelling quick
+// fixes: each suggestion becomes its own action whose edit replaces
+// only the diagnostic's range, in suggestion order.
+func TestComputeCodeActionsOffersSuggestions(t *testing.T) {
+	t.Parallel()
+	s := New(Options{Reader: nil, Writer: io.Discard, Rules: rule.All()})
+	cfg := config.Merge(config.Defaults(), nil)
+	doc := &document{path: "x.md", text: []byte("# Hi\n\nteh cat\n")}
+	rng := Range{Start: Position{Line: 2, Character: 0}, End: Position{Line: 2, Character: 3}}
+	d := Diagnostic{
+		Range: rng, Code: "MDS076",
+		Data: &diagnosticData{RuleName: "spelling", Suggestions: []string{"the", "tea"}},
+	}
+	p := codeActionParams{
+		TextDocument: textDocumentIdentifier{URI: "file:///x.md"},
+		Context:      codeActionContext{Diagnostics: []Diagnostic{d}, Only: []string{kindQuickFix}},
+	}
+	actions := s.computeCodeActions(p, doc, cfg, "")
+	require.Len(t, actions, 2, "spelling has no whole-file fix; one action per suggestion")
+	for i, want := range []string{"the", "tea"} {
+		a := actions[i]
+		assert.Equal(t, `Change to "`+want+`"`, a.Title)
+		assert.Equal(t, kindQuickFix, a.Kind)
+		require.NotNil(t, a.Edit)
+		assert.Equal(t, []textEdit{{Range: rng, NewText: want}}, a.Edit.Changes["file:///x.md"])
+	}
+}
+
+// TestToLSP_EndColumnAndSuggestions confirms a span diagnostic ends its
+// range at EndColumn (in UTF-16 units) and carries its suggestions in
+// data for the code-action round trip.
+func TestToLSP_EndColumnAndSuggestions(t *testing.T) {
+	t.Parallel()
+	d := lint.Diagnostic{
+		Line: 1, Column: 7, EndColumn: 10, RuleID: "MDS076", RuleName: "spelling",
+		Suggestions: []string{"the"},
+	}
+	got := toLSP(d, [][]byte{[]byte("café teh end")}, "")
+	assert.Equal(t, 5, got.Range.Start.Character, "byte column 7 after the 2-byte é")
+	assert.Equal(t, 8, got.Range.End.Character)
+	require.NotNil(t, got.Data)
+	assert.Equal(t, []string{"the"}, got.Data.Suggestions)
+
+	d.EndColumn = 0
+	got = toLSP(d, [][]byte{[]byte("café teh end")}, "")
+	assert.Equal(t, 12, got.Range.End.Character, "no EndColumn: range runs to the end of the line")
+}
+
 func TestToLSPClampsZeroLine(t *testing.T) {
 	t.Parallel()
 	got := toLSP(lint.Diagnostic{Line: 0, Column: 1, RuleID: "MDS001", Severity: lint.Error},
@@ -0,0 +1,144 @@
+package mdtext
+
+import (
+	"strings"
+	"unicode"
+)
+
+// cjkStart is the first rune of the CJK Radicals Supplement block.
+// Every rune classified as CJK below sits at or above it, so the
+// word and sentence scans pay one comparison for accented Latin and
+// other non-ASCII text before reaching the table lookups.
+const cjkStart = 0x2E80
+
+// Word-scan states shared by the word counters. A word is a maximal
+// run of non-space runes, except in Chinese and Japanese script,
+// which does not put spaces between words. There each Han ideograph
+// and each hiragana is a word of its own, a katakana run is one word,
+// and CJK punctuation separates words without being one. These are
+// the ideographic rules of UAX #29 word segmentation (WB13 joins
+// katakana, nothing joins ideographs or hiragana), applied without
+// its finer Latin rules, so space-delimited text counts exactly as
+// strings.Fields does.
+const (
+	wordGap uint8 = iota
+	wordIn
+	wordKatakana
+)
+
+// cjkClass is how the word counters treat a rune at or above cjkStart.
+type cjkClass uint8
+
+const (
+	cjkNone     cjkClass = iota // not CJK: part of an ordinary word
+	cjkSingle                   // Han or hiragana: a word by itself
+	cjkKatakana                 // katakana: runs join into one word
+	cjkPunct                    // CJK punctuation: a word break
+)
+
+func classifyCJK(r rune) cjkClass {
+	switch {
+	case r == 'ー' || unicode.Is(unicode.Katakana, r):
+		return cjkKatakana
+	case r == '〇' || unicode.Is(unicode.Han, r) || unicode.Is(unicode.Hiragana, r):
+		return cjkSingle
+	case (0x3000 <= r && r <= 0x303F) || (0xFF00 <= r && r <= 0xFF65):
+		// CJK Symbols and Punctuation, and the fullwidth ASCII
+		// variants. Fullwidth letters and digits stay word runes.
+		if unicode.IsLetter(r) || unicode.IsDigit(r) {
+			return cjkNone
+		}
+		return cjkPunct
+	}
+	return cjkNone
+}
+
+// IsCJK reports whether r is a Chinese or Japanese character or CJK
+// punctuation: a rune written without spaces around it. Hangul is
+// not included; Korean separates words with spaces.
+func IsCJK(r rune) bool {
+	return r >= cjkStart && classifyCJK(r) != cjkNone
+}
+
+// nextWordState advances the word-scan state over one non-ASCII,
+// non-space rune and reports whether the rune starts a new word.
+func nextWordState(state uint8, r rune) (uint8, bool) {
+	if r >= cjkStart {
+		switch classifyCJK(r) {
+		case cjkSingle:
+			return wordGap, true
+		case cjkKatakana:
+			return wordKatakana, state != wordKatakana
+		case cjkPunct:
+			return wordGap, false
+		}
+	}
+	return wordIn, state != wordIn
+}
+
+// isCJKTerminal reports whether r ends a sentence in Chinese or
+// Japanese text: the ideographic full stop and the fullwidth
+// exclamation and question marks. Unlike ASCII terminals they need
+// no following space.
+func isCJKTerminal(r rune) bool {
+	switch r {
+	case '。', '！', '？', '｡':
+		return true
+	}
+	return false
+}
+
+// isSentenceCloser reports whether r belongs to the sentence a
+// terminal just ended: a closing bracket or quote, or another
+// terminal ("？！").
+func isSentenceCloser(r rune) bool {
+	switch r {
+	case '」', '』', '）', '】', '〕', '〉', '》', '〟', '’', '”', ')', ']', '"', '\'':
+		return true
+	}
+	return isCJKTerminal(r)
+}
+
+// hasCJKTerminal reports whether text contains a CJK sentence
+// terminal. All four are three-byte runes starting 0xE3 or 0xEF, so
+// text without either byte skips the rune scan.
+func hasCJKTerminal(text string) bool {
+	if strings.IndexByte(text, 0xE3) < 0 && strings.IndexByte(text, 0xEF) < 0 {
+		return false
+	}
+	return strings.ContainsAny(text, "。！？｡")
+}
+
+// splitCJKSentences splits text after each CJK terminal (and the
+// closers that follow it), the same boundaries CountSentences
+// counts. Each piece without an ASCII terminal is one sentence;
+// a piece mixing in Latin sentences goes through split, the Punkt
+// segmenter, which does not break on "！" or "？" and would detach
+// a closing bracket from its "。".
+func splitCJKSentences(dst []string, text string, split func(dst []string, text string) []string) []string {
+	start := 0
+	pending := false
+	flush := func(end int) {
+		piece := strings.TrimSpace(text[start:end])
+		start = end
+		if piece == "" {
+			return
+		}
+		if strings.ContainsAny(piece, ".!?") {
+			dst = split(dst, piece)
+			return
+		}
+		dst = append(dst, piece)
+	}
+	for i, r := range text {
+		if pending && !isSentenceCloser(r) && !IsSpace(r) {
+			flush(i)
+			pending = false
+		}
+		if isCJKTerminal(r) {
+			pending = true
+		}
+	}
+	flush(len(text))
+	return dst
+}
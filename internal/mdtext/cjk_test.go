@@ -0,0 +1,104 @@
+package mdtext_test
+
+import (
+	"testing"
+	"unicode"
+
+	"github.com/clipperhouse/uax29/v2/words"
+	"github.com/jeduden/mdsmith/internal/lint"
+	"github.com/jeduden/mdsmith/internal/mdtext"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var cjkWordCases = []struct {
+	text string
+	want int
+}{
+	{"日本語", 3},
+	{"日本語のテキストです。", 7},
+	{"カタカナ", 1},
+	{"らーめん", 4},
+	{"中文句子，很长。", 6},
+	{"日本語 と English", 5},
+	{"READMEを読む", 4},
+	{"「引用」", 2},
+	{"ＡＢＣ１２３", 1},
+	{"한국어 문장", 2},
+}
+
+func TestCountWords_CJK(t *testing.T) {
+	for _, c := range cjkWordCases {
+		assert.Equalf(t, c.want, mdtext.CountWords(c.text), "CountWords(%q)", c.text)
+		assert.Equalf(t, c.want, mdtext.CountWordsBytes([]byte(c.text)), "CountWordsBytes(%q)", c.text)
+	}
+}
+
+// TestCountWords_CJKMatchesUAX29 pins the CJK word rules to UAX #29
+// word segmentation: for text without Latin punctuation, a word is a
+// segment holding a letter or digit.
+func TestCountWords_CJKMatchesUAX29(t *testing.T) {
+	for _, c := range cjkWordCases {
+		n := 0
+		seg := words.FromString(c.text)
+		for seg.Next() {
+			for _, r := range seg.Value() {
+				if unicode.IsLetter(r) || unicode.IsDigit(r) {
+					n++
+					break
+				}
+			}
+		}
+		assert.Equalf(t, n, mdtext.CountWords(c.text), "uax29 segments of %q", c.text)
+	}
+}
+
+func TestCountWordsInNode_CJK(t *testing.T) {
+	src := "日本語の**テキスト**です。\n次の`コード`行。\n"
+	f, err := lint.NewFile("t.md", []byte(src))
+	require.NoError(t, err)
+	para := f.AST.FirstChild()
+	text := mdtext.ExtractPlainText(para, f.Source)
+	assert.Equal(t, mdtext.CountWords(text), mdtext.CountWordsInNode(para, f.Source))
+}
+
+func TestIsCJK(t *testing.T) {
+	for _, r := range "日あカー。「！" {
+		assert.Truef(t, mdtext.IsCJK(r), "IsCJK(%q)", r)
+	}
+	for _, r := range "aé한1 " {
+		assert.Falsef(t, mdtext.IsCJK(r), "IsCJK(%q)", r)
+	}
+}
+
+func TestCountSentences_CJK(t *testing.T) {
+	cases := []struct {
+		text string
+		want int
+	}{
+		{"日本語の文です。次の文です。", 2},
+		{"本当ですか？はい、そうです！わかりました。", 3},
+		{"「そうだ。」と彼は言った。", 2},
+		{"終わり。", 1},
+		{"中文句子。English sentence. 又一句。", 3},
+		{"日本語の文です。 次の文です。", 2},
+	}
+	for _, c := range cases {
+		assert.Equalf(t, c.want, mdtext.CountSentences(c.text), "CountSentences(%q)", c.text)
+	}
+}
+
+func TestSplitSentences_CJK(t *testing.T) {
+	assert.Equal(t,
+		[]string{"本当ですか？", "はい、そうです！", "わかりました。"},
+		mdtext.SplitSentences("本当ですか？はい、そうです！わかりました。"))
+	assert.Equal(t,
+		[]string{"「そうだ。」", "と彼は言った。"},
+		mdtext.SplitSentences("「そうだ。」と彼は言った。"))
+	assert.Equal(t,
+		[]string{"中文句子。", "English sentence.", "Dr. Smith agrees.", "又一句。"},
+		mdtext.SplitSentences("中文句子。English sentence. Dr. Smith agrees. 又一句。"))
+	assert.Equal(t,
+		[]string{"Sie kam, vgl. oben.", "次。"},
+		mdtext.SplitSentencesLangInto(nil, "de", "Sie kam, vgl. oben. 次。"))
+}
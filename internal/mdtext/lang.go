@@ -0,0 +1,107 @@
+package mdtext
+
+import (
+	"strings"
+	"sync"
+
+	"github.com/jeduden/mdsmith/internal/punkt"
+)
+
+// DefaultLang is the language prose is segmented and scored as when
+// nothing selects another one.
+const DefaultLang = "en"
+
+// langNames maps lowercase English and native language names to
+// their ISO 639-1 code, so front matter like `lang: Deutsch` or
+// `lang: spanish` resolves the same as `lang: de` / `lang: es`.
+var langNames = map[string]string{
+	"english":    "en",
+	"german":     "de",
+	"deutsch":    "de",
+	"spanish":    "es",
+	"español":    "es",
+	"espanol":    "es",
+	"french":     "fr",
+	"français":   "fr",
+	"francais":   "fr",
+	"italian":    "it",
+	"italiano":   "it",
+	"dutch":      "nl",
+	"nederlands": "nl",
+}
+
+// Langs lists the language codes NormalizeLang accepts, in the order
+// docs and error messages show them.
+var Langs = []string{"en", "de", "es", "fr", "it", "nl"}
+
+// NormalizeLang maps a user-supplied language tag to one of Langs.
+// It accepts a BCP 47 tag ("de", "de-AT", "es_MX"; the primary
+// subtag decides) or a language name ("German", "español"). ok is
+// false for an empty or unknown tag.
+func NormalizeLang(raw string) (lang string, ok bool) {
+	s := strings.ToLower(strings.TrimSpace(raw))
+	if s == "" {
+		return "", false
+	}
+	if code, ok := langNames[s]; ok {
+		return code, true
+	}
+	if i := strings.IndexAny(s, "-_"); i >= 0 {
+		s = s[:i]
+	}
+	for _, code := range Langs {
+		if s == code {
+			return code, true
+		}
+	}
+	return "", false
+}
+
+// langTokenizers caches one *punkt.Tokenizer per non-English
+// language, built on first use. A nil entry records a language this
+// build has no model for (the js/wasm build), so the lookup is not
+// retried.
+var langTokenizers sync.Map // lang -> *punkt.Tokenizer
+
+func langTokenizer(lang string) *punkt.Tokenizer {
+	if v, ok := langTokenizers.Load(lang); ok {
+		return v.(*punkt.Tokenizer)
+	}
+	// The models are embedded and covered by punkt's tests; a load
+	// error is treated like a missing model and falls back to English.
+	tok, ok, err := punkt.NewLanguage(lang)
+	if !ok || err != nil {
+		tok = nil
+	}
+	v, _ := langTokenizers.LoadOrStore(lang, tok)
+	return v.(*punkt.Tokenizer)
+}
+
+// SplitSentencesLangInto is SplitSentencesInto with the trained
+// Punkt model for lang (a code from NormalizeLang). English, an
+// empty lang, and a language without a model in this build take the
+// English path, so the result matches SplitSentencesInto exactly.
+func SplitSentencesLangInto(dst []string, lang, text string) []string {
+	if lang == "" || lang == DefaultLang {
+		return SplitSentencesInto(dst, text)
+	}
+	tok := langTokenizer(lang)
+	if tok == nil {
+		return SplitSentencesInto(dst, text)
+	}
+	if strings.TrimSpace(text) == "" {
+		return dst
+	}
+	split := func(dst []string, text string) []string {
+		for _, s := range tok.Tokenize(text) {
+			if t := strings.TrimSpace(s.Text); t != "" {
+				dst = append(dst, t)
+			}
+		}
+		return dst
+	}
+	if hasCJKTerminal(text) {
+		return splitCJKSentences(dst, text, split)
+	}
+	return split(dst, text)
+}
@@ -0,0 +1,40 @@
+package mdtext
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNormalizeLang(t *testing.T) {
+	tests := map[string]string{
+		"de": "de", "DE": "de", "de-AT": "de", "es_MX": "es",
+		"German": "de", "Deutsch": "de", "español": "es", "english": "en",
+		"nl": "nl", " fr ": "fr",
+	}
+	for in, want := range tests {
+		got, ok := NormalizeLang(in)
+		assert.True(t, ok, in)
+		assert.Equal(t, want, got, in)
+	}
+	for _, in := range []string{"", "ja", "xx-de", "klingon"} {
+		_, ok := NormalizeLang(in)
+		assert.False(t, ok, in)
+	}
+}
+
+func TestSplitSentencesLangInto_EnglishMatchesDefault(t *testing.T) {
+	text := "Dr. Smith met Mr. Jones. They talked."
+	assert.Equal(t, SplitSentences(text), SplitSentencesLangInto(nil, "en", text))
+	assert.Equal(t, SplitSentences(text), SplitSentencesLangInto(nil, "", text))
+	assert.Equal(t, SplitSentences(text), SplitSentencesLangInto(nil, "xx", text))
+}
+
+func TestSplitSentencesLangInto_German(t *testing.T) {
+	text := "Die Werte steigen, vgl. Tabelle drei. Das gilt bzw. Fall zwei auch."
+	assert.Equal(t, []string{
+		"Die Werte steigen, vgl. Tabelle drei.",
+		"Das gilt bzw. Fall zwei auch.",
+	}, SplitSentencesLangInto(nil, "de", text))
+	assert.Empty(t, SplitSentencesLangInto(nil, "de", "   "))
+}
@@ -151,6 +151,24 @@ func ExtractPlainText(node ast.Node, source []byte) string {
 	return buf.String()
 }
 
+// LeadingH1 returns the plain text of the document's first heading
+// when it is a level-1 heading, or "" otherwise. Rules that title a
+// file by its H1 (backlinks, tree) share this lookup.
+func LeadingH1(root ast.Node, source []byte) string {
+	var first *ast.Heading
+	_ = ast.Walk(root, func(n ast.Node, entering bool) (ast.WalkStatus, error) {
+		if h, ok := n.(*ast.Heading); ok && entering {
+			first = h
+			return ast.WalkStop, nil
+		}
+		return ast.WalkContinue, nil
+	})
+	if first == nil || first.Level != 1 {
+		return ""
+	}
+	return strings.TrimSpace(ExtractPlainText(first, source))
+}
+
 func extractText(buf *bytes.Buffer, node ast.Node, source []byte) {
 	// For text nodes, write the content.
 	if t, ok := node.(*ast.Text); ok {
@@ -218,10 +236,13 @@ var asciiSpace = [utf8.RuneSelf]bool{
 	'\t': true, '\n': true, '\v': true, '\f': true, '\r': true, ' ': true,
 }
 
-// CountWords counts whitespace-delimited words in text. It is exactly
-// len(strings.Fields(text)) — a word is a maximal run of non-space
-// runes, space being [IsSpace] (exactly unicode.IsSpace) — but counts
-// in a single rune scan instead of allocating the []string. CountWords
+// CountWords counts whitespace-delimited words in text. Outside
+// Chinese and Japanese script it is exactly len(strings.Fields(text))
+// — a word is a maximal run of non-space runes, space being [IsSpace]
+// (exactly unicode.IsSpace) — but counts in a single rune scan
+// instead of allocating the []string. Text in those scripts has no
+// spaces between words; each ideograph or hiragana counts as a word,
+// and a katakana run as one (see wordGap in cjk.go). CountWords
 // is called per sentence, per paragraph, per file; the slice
 // strings.Fields built only to be discarded was ~0.48 GB over the
 // 600-file check gate (plan 175 profiling).
@@ -234,15 +255,15 @@ func CountWords(text string) int {
 // into a string first.
 func CountWordsBytes(text []byte) int {
 	n := 0
-	inWord := false
+	state := wordGap
 	for i := 0; i < len(text); {
 		c := text[i]
 		if c < utf8.RuneSelf {
 			i++
 			if asciiSpace[c] {
-				inWord = false
-			} else if !inWord {
-				inWord = true
+				state = wordGap
+			} else if state != wordIn {
+				state = wordIn
 				n++
 			}
 			continue
@@ -250,9 +271,11 @@ func CountWordsBytes(text []byte) int {
 		r, size := utf8.DecodeRune(text[i:])
 		i += size
 		if unicode.IsSpace(r) {
-			inWord = false
-		} else if !inWord {
-			inWord = true
+			state = wordGap
+			continue
+		}
+		var starts bool
+		if state, starts = nextWordState(state, r); starts {
 			n++
 		}
 	}
@@ -263,15 +286,15 @@ func CountWordsBytes(text []byte) int {
 // loops stay byte-identical in shape so they cannot drift.
 func countWordsString(text string) int {
 	n := 0
-	inWord := false
+	state := wordGap
 	for i := 0; i < len(text); {
 		c := text[i]
 		if c < utf8.RuneSelf {
 			i++
 			if asciiSpace[c] {
-				inWord = false
-			} else if !inWord {
-				inWord = true
+				state = wordGap
+			} else if state != wordIn {
+				state = wordIn
 				n++
 			}
 			continue
@@ -279,9 +302,11 @@ func countWordsString(text string) int {
 		r, size := utf8.DecodeRuneInString(text[i:])
 		i += size
 		if unicode.IsSpace(r) {
-			inWord = false
-		} else if !inWord {
-			inWord = true
+			state = wordGap
+			continue
+		}
+		var starts bool
+		if state, starts = nextWordState(state, r); starts {
 			n++
 		}
 	}
@@ -315,8 +340,8 @@ func CountWordsInNode(node ast.Node, source []byte) int {
 // the way [ExtractPlainText] would have concatenated those segments
 // before [CountWords] tallied the joined string.
 type wordCounter struct {
-	n      int
-	inWord bool
+	n     int
+	state uint8 // wordGap, wordIn, or wordKatakana
 }
 
 // writeBytes folds b into the running count. Equivalent to feeding b
@@ -332,9 +357,9 @@ func (wc *wordCounter) writeBytes(b []byte) {
 		if c < utf8.RuneSelf {
 			b = b[1:]
 			if asciiSpace[c] {
-				wc.inWord = false
-			} else if !wc.inWord {
-				wc.inWord = true
+				wc.state = wordGap
+			} else if wc.state != wordIn {
+				wc.state = wordIn
 				wc.n++
 			}
 			continue
@@ -342,9 +367,11 @@ func (wc *wordCounter) writeBytes(b []byte) {
 		r, size := utf8.DecodeRune(b)
 		b = b[size:]
 		if IsSpace(r) {
-			wc.inWord = false
-		} else if !wc.inWord {
-			wc.inWord = true
+			wc.state = wordGap
+			continue
+		}
+		var starts bool
+		if wc.state, starts = nextWordState(wc.state, r); starts {
 			wc.n++
 		}
 	}
@@ -353,7 +380,7 @@ func (wc *wordCounter) writeBytes(b []byte) {
 // writeSpace marks a word boundary without writing any rune —
 // matches ExtractPlainText's `buf.WriteByte(' ')` for Text nodes with
 // SoftLineBreak / HardLineBreak set.
-func (wc *wordCounter) writeSpace() { wc.inWord = false }
+func (wc *wordCounter) writeSpace() { wc.state = wordGap }
 
 // countWordsInNode mirrors [extractText]'s dispatch shape so the two
 // stay equivalent under [CountWords]. Any change to the cases below
@@ -391,8 +418,10 @@ func countWordsInNode(wc *wordCounter, node ast.Node, source []byte) {
 }
 
 // CountSentences counts sentences by splitting on sentence-ending
-// punctuation (., !, ?) followed by whitespace or end of text.
-// Returns at least 1 for non-empty text.
+// punctuation (., !, ?) followed by whitespace or end of text, and
+// after the CJK terminals 。！？, which need no following space. A
+// closing bracket or quote after a CJK terminal stays in the sentence
+// it ends. Returns at least 1 for non-empty text.
 func CountSentences(text string) int {
 	if strings.TrimSpace(text) == "" {
 		return 0
@@ -402,13 +431,22 @@ func CountSentences(text string) int {
 	// materialising []rune(text) for lookahead — the rune slice was
 	// one whole-text allocation per call on the check hot path.
 	prevTerminal := false
+	cjkPending := false
 	for _, r := range text {
+		if cjkPending {
+			if isSentenceCloser(r) || IsSpace(r) {
+				continue
+			}
+			count++
+			cjkPending = false
+		}
 		if prevTerminal && IsSpace(r) {
 			count++
 		}
 		prevTerminal = r == '.' || r == '!' || r == '?'
+		cjkPending = r >= cjkStart && isCJKTerminal(r)
 	}
-	if prevTerminal {
+	if prevTerminal || cjkPending {
 		count++
 	}
 	if count == 0 {
@@ -447,6 +485,9 @@ func SplitSentences(text string) []string {
 		return nil
 	}
 	initTokenizerOnce()
+	if hasCJKTerminal(text) {
+		return splitCJKSentences(nil, text, splitSentencesInto)
+	}
 	return splitSentencesInto(nil, text)
 }
 
@@ -466,6 +507,9 @@ func SplitSentencesInto(dst []string, text string) []string {
 		return dst
 	}
 	initTokenizerOnce()
+	if hasCJKTerminal(text) {
+		return splitCJKSentences(dst, text, splitSentencesInto)
+	}
 	return splitSentencesInto(dst, text)
 }
 
@@ -42,6 +42,17 @@ func parseParagraph(t *testing.T, src string) (ast.Node, []byte) {
 	return para, source
 }
 
+func TestLeadingH1(t *testing.T) {
+	doc, src := parseDoc(t, "# The *Title*\n\nBody.\n")
+	assert.Equal(t, "The Title", mdtext.LeadingH1(doc, src))
+
+	doc, src = parseDoc(t, "## Section\n\n# Late H1\n")
+	assert.Equal(t, "", mdtext.LeadingH1(doc, src), "first heading is not an H1")
+
+	doc, src = parseDoc(t, "No headings.\n")
+	assert.Equal(t, "", mdtext.LeadingH1(doc, src))
+}
+
 func TestExtractPlainText_PlainParagraph(t *testing.T) {
 	para, src := parseParagraph(t, "Hello world.\n")
 	assert.Equal(t, "Hello world.", mdtext.ExtractPlainText(para, src))
@@ -202,9 +213,10 @@ func TestCountWords_MultipleSpaces(t *testing.T) {
 // TestCountWords_EquivalentToStringsFields pins the allocation-free
 // rewrite to its original definition (len(strings.Fields(s))) across
 // the whitespace shapes that matter: tabs, newlines, CRLF, leading and
-// trailing runs, Unicode spaces (NBSP, ideographic space), and CJK
-// runs with no ASCII space. If these ever diverge, the rewrite changed
-// behaviour and the rule output would shift.
+// trailing runs, and Unicode spaces (NBSP, ideographic space). If
+// these ever diverge, the rewrite changed behaviour and the rule
+// output would shift. CJK text is the one intended divergence; see
+// TestCountWords_CJK.
 func TestCountWords_EquivalentToStringsFields(t *testing.T) {
 	cases := []string{
 		"",
@@ -218,7 +230,7 @@ func TestCountWords_EquivalentToStringsFields(t *testing.T) {
 		"emoji 🚀 done",
 		"non breaking space",
 		"ideographic　space　here",
-		"日本語 と English",
+		"café naïve Ünïcödé",
 		"trailing ",
 		" leading",
 	}
@@ -0,0 +1,202 @@
+package mermaid
+
+import (
+	"regexp"
+	"slices"
+	"strings"
+)
+
+// classRelation matches a class relation: an optional end, a solid or
+// dotted line, and an optional end, such as "<|--", "*--", or "..>".
+var classRelation = regexp.MustCompile(`^(<\||\*|o|<|\(\))?(--|\.\.)(\|>|\*|o|>|\(\))?$`)
+
+// classDiagram is the state of one class diagram parse.
+type classDiagram struct {
+	*checker
+	open []block // class bodies and namespaces
+}
+
+func parseClass(c *checker, rest line, body []line) {
+	c.noRest(rest, "classDiagram")
+	cd := &classDiagram{checker: c}
+	c.each(body, cd.statement)
+	if !c.failed() {
+		c.closeAll(cd.open, "}")
+	}
+}
+
+// statement parses one class diagram statement.
+func (cd *classDiagram) statement(l line) {
+	if l.text == "}" {
+		if len(cd.open) == 0 {
+			cd.failf(l, 0, "\"}\" without an open class or namespace")
+			return
+		}
+		cd.open = cd.open[:len(cd.open)-1]
+		return
+	}
+	if len(cd.open) > 0 && cd.open[len(cd.open)-1].kind == "class" {
+		return // a member line
+	}
+	word, rest := cut(l)
+	switch word.text {
+	case "class":
+		cd.class(l, rest)
+	case "namespace":
+		name, brace := cut(rest)
+		if name.text == "" || brace.text != "{" {
+			cd.failf(l, 0, "expected \"namespace Name {\"")
+			return
+		}
+		cd.open = append(cd.open, block{kind: "namespace", at: l})
+	case "note":
+		if target, ok := keyword(rest, "for"); ok {
+			name, _ := cut(target)
+			cd.use(name, "class", name.text)
+		}
+	case "direction":
+		if !slices.Contains(directions, rest.text) {
+			cd.failf(rest, 0, "unknown direction %q", rest.text)
+		}
+	case "classDef":
+	case "style", "click", "callback", "link":
+		name, _ := cut(rest)
+		if name.text == "" {
+			cd.failf(l, 0, "%s needs a class name", word.text)
+			return
+		}
+		cd.use(name, "class", name.text)
+	case "cssClass":
+		names, _ := cut(rest)
+		for _, n := range split(line{num: names.num, col: names.col + 1, text: strings.Trim(names.text, `"`)}, ',') {
+			cd.use(n, "class", n.text)
+		}
+	default:
+		if strings.HasPrefix(l.text, "<<") {
+			cd.annotation(l)
+			return
+		}
+		cd.relation(l)
+	}
+}
+
+// class parses "class Name", with optional generics, label, :::style,
+// and an opening "{" for a member block.
+func (cd *classDiagram) class(l, rest line) {
+	t := rest.text
+	j := scanClassName(t, 0)
+	if j == 0 {
+		cd.failf(l, 0, "class needs a name")
+		return
+	}
+	cd.define(className(t[:j]))
+	if strings.HasPrefix(t[j:], "[") {
+		e := strings.IndexByte(t[j:], ']')
+		if e < 0 {
+			cd.failf(rest, j, "class label has no closing \"]\"")
+			return
+		}
+		j += e + 1
+	}
+	if strings.HasPrefix(t[j:], ":::") {
+		j = scanID(t, j+3)
+	}
+	switch tail := strings.TrimSpace(t[j:]); tail {
+	case "":
+	case "{":
+		cd.open = append(cd.open, block{kind: "class", at: l})
+	case "{}", "{ }":
+	default:
+		cd.failf(rest, j, "unexpected %q after the class name", tail)
+	}
+}
+
+// annotation parses "<<interface>> Name".
+func (cd *classDiagram) annotation(l line) {
+	e := strings.Index(l.text, ">>")
+	if e < 0 {
+		cd.failf(l, 0, "annotation has no closing \">>\"")
+		return
+	}
+	name := l.at(e + 2)
+	if name.text == "" {
+		cd.failf(l, 0, "annotation needs a class name")
+		return
+	}
+	cd.define(className(name.text))
+}
+
+// relation parses a member line, "Name : +field", or a relation,
+// `A "1" <|-- "*" B : label`.
+func (cd *classDiagram) relation(l line) {
+	t := l.text
+	j := scanClassName(t, 0)
+	if j == 0 {
+		cd.failf(l, 0, "unexpected %q", t)
+		return
+	}
+	from := className(t[:j])
+	j = skipSpace(t, j)
+	if j < len(t) && t[j] == ':' {
+		cd.define(from)
+		return
+	}
+	j = skipCardinality(t, j)
+	k := j
+	for k < len(t) && t[k] != ' ' && t[k] != '\t' && t[k] != '"' {
+		k++
+	}
+	if j == len(t) || !classRelation.MatchString(t[j:k]) {
+		cd.failf(l, j, "expected a relation such as \"<|--\" or \"..>\", found %q", t[j:])
+		return
+	}
+	m := skipCardinality(t, skipSpace(t, k))
+	n := scanClassName(t, m)
+	if n == m {
+		cd.failf(l, j, "relation has no target class")
+		return
+	}
+	if tail := strings.TrimSpace(t[n:]); tail != "" && tail[0] != ':' {
+		cd.failf(l, n, "unexpected %q after the relation", tail)
+		return
+	}
+	cd.define(from)
+	cd.define(className(t[m:n]))
+}
+
+// skipCardinality skips a quoted cardinality such as "1..*" and the
+// blanks after it.
+func skipCardinality(t string, i int) int {
+	if i < len(t) && t[i] == '"' {
+		if e := strings.IndexByte(t[i+1:], '"'); e >= 0 {
+			return skipSpace(t, i+e+2)
+		}
+	}
+	return i
+}
+
+// scanClassName returns the end of the class name at byte i: a name
+// with optional ~generic~ parameters, or a `backquoted` name.
+func scanClassName(t string, i int) int {
+	if i < len(t) && t[i] == '`' {
+		if e := strings.IndexByte(t[i+1:], '`'); e >= 0 {
+			return i + e + 2
+		}
+		return i
+	}
+	j := scanID(t, i)
+	if j > i && j < len(t) && t[j] == '~' {
+		if e := strings.IndexByte(t[j+1:], '~'); e >= 0 {
+			j += e + 2
+		}
+	}
+	return j
+}
+
+// className drops the generics and backquotes from a scanned name.
+func className(s string) string {
+	if i := strings.IndexByte(s, '~'); i > 0 {
+		s = s[:i]
+	}
+	return strings.Trim(s, "`")
+}
@@ -0,0 +1,127 @@
+package mermaid
+
+import (
+	"regexp"
+	"slices"
+	"strings"
+)
+
+// erRelation matches an entity relationship in the crow's foot
+// notation: a cardinality, a solid or dotted line, and a cardinality,
+// such as "||--o{".
+var erRelation = regexp.MustCompile(`^(\|o|o\||\}o|o\{|\}\||\|\{|\|\||u)(--|\.\.|\.-|-\.)(\|o|o\||\}o|o\{|\}\||\|\{|\|\||u)$`)
+
+// erDiagram is the state of one entity-relationship diagram parse.
+type erDiagram struct {
+	*checker
+	entity *line // the open attribute block
+}
+
+func parseER(c *checker, rest line, body []line) {
+	c.noRest(rest, "erDiagram")
+	ed := &erDiagram{checker: c}
+	c.each(body, ed.statement)
+	if !c.failed() && ed.entity != nil {
+		c.failf(*ed.entity, 0, "entity has no closing \"}\"")
+	}
+}
+
+// statement parses one entity-relationship statement.
+func (ed *erDiagram) statement(l line) {
+	if ed.entity != nil {
+		ed.attribute(l)
+		return
+	}
+	word, rest := cut(l)
+	switch word.text {
+	case "direction":
+		if !slices.Contains(directions, rest.text) {
+			ed.failf(rest, 0, "unknown direction %q", rest.text)
+		}
+		return
+	case "classDef":
+		return
+	case "class", "style":
+		ids, _ := cut(rest)
+		for _, id := range split(ids, ',') {
+			ed.use(id, "entity", id.text)
+		}
+		return
+	}
+	t := l.text
+	j := scanEntity(t, 0)
+	if j == 0 {
+		ed.failf(l, 0, "unexpected %q", t)
+		return
+	}
+	name := strings.Trim(t[:j], `"`)
+	if strings.HasPrefix(t[j:], "[") {
+		e := strings.IndexByte(t[j:], ']')
+		if e < 0 {
+			ed.failf(l, j, "entity alias has no closing \"]\"")
+			return
+		}
+		j += e + 1
+	}
+	switch tail := strings.TrimSpace(t[j:]); {
+	case tail == "":
+		ed.define(name)
+	case tail == "{":
+		ed.define(name)
+		ed.entity = &l
+	case tail == "{}" || tail == "{ }":
+		ed.define(name)
+	default:
+		ed.relationship(l, name, j)
+	}
+}
+
+// relationship parses the part of `A ||--o{ B : label` after the
+// first entity name, which ends at byte i.
+func (ed *erDiagram) relationship(l line, from string, i int) {
+	t := l.text
+	head, label, found := strings.Cut(t, ":")
+	words := strings.Fields(head[i:])
+	switch {
+	case len(words) >= 2 && erRelation.MatchString(words[0]):
+		if len(words) > 2 {
+			ed.failf(l, strings.Index(t[i:], words[2])+i, "unexpected %q after the relationship", words[2])
+			return
+		}
+	case slices.Contains(words, "to") && len(words) >= 3:
+		// The word notation, such as "only one to zero or more".
+	default:
+		ed.failf(l, skipSpace(t, i), "expected a relationship such as \"||--o{\", found %q", strings.TrimSpace(t[i:]))
+		return
+	}
+	if !found || strings.TrimSpace(label) == "" {
+		ed.failf(l, len(t), "relationship has no label; add \": label\"")
+		return
+	}
+	ed.define(from)
+	ed.define(strings.Trim(words[len(words)-1], `"`))
+}
+
+// attribute parses one line of an entity's attribute block: a type, a
+// name, optional keys, and an optional quoted comment.
+func (ed *erDiagram) attribute(l line) {
+	if l.text == "}" {
+		ed.entity = nil
+		return
+	}
+	if len(strings.Fields(l.text)) < 2 {
+		ed.failf(l, 0, "attribute needs a type and a name, found %q", l.text)
+	}
+}
+
+// scanEntity returns the end of the entity name at byte i: a name or
+// a quoted name.
+func scanEntity(t string, i int) int {
+	if i < len(t) && t[i] == '"' {
+		if e := strings.IndexByte(t[i+1:], '"'); e >= 0 {
+			return i + e + 2
+		}
+		return i
+	}
+	return scanID(t, i)
+}
@@ -0,0 +1,395 @@
+package mermaid
+
+import (
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// directions lists the flow directions of a flowchart or subgraph.
+var directions = []string{"TB", "TD", "BT", "RL", "LR", ">", "<", "^", "v"}
+
+// shapes pairs each node shape opener with its closers. Longer openers
+// come first, so "((" wins over "(".
+var shapes = []struct {
+	open  string
+	close []string
+}{
+	{"(((", []string{")))"}},
+	{"((", []string{"))"}},
+	{"([", []string{"])"}},
+	{"(", []string{")"}},
+	{"[[", []string{"]]"}},
+	{"[(", []string{")]"}},
+	{"[/", []string{"/]", `\]`}},
+	{`[\`, []string{`\]`, "/]"}},
+	{"[", []string{"]"}},
+	{"{{", []string{"}}"}},
+	{"{", []string{"}"}},
+	{">", []string{"]"}},
+}
+
+// shapeBreakers are the bytes that end unquoted node text early in
+// Mermaid's lexer; text holding them must be quoted.
+const shapeBreakers = `[](){}"`
+
+// flowchart is the state of one flowchart parse.
+type flowchart struct {
+	*checker
+	open       []block // subgraphs
+	links      int
+	linkStyles []linkStyle
+}
+
+// linkStyle is one index a linkStyle statement styles.
+type linkStyle struct {
+	at    line
+	index int
+}
+
+func parseFlowchart(c *checker, rest line, body []line) {
+	fc := &flowchart{checker: c}
+	head := split(rest, ';')
+	if d := head[0]; d.text != "" && !slices.Contains(directions, d.text) {
+		c.failf(d, 0, "unknown direction %q", d.text)
+		return
+	}
+	for _, s := range head[1:] {
+		fc.statement(s)
+	}
+	c.each(body, func(l line) {
+		for _, s := range split(l, ';') {
+			if !c.failed() {
+				fc.statement(s)
+			}
+		}
+	})
+	if c.failed() {
+		return
+	}
+	c.closeAll(fc.open, "end")
+	for _, ls := range fc.linkStyles {
+		if ls.index >= fc.links {
+			c.problem(ls.at, "linkStyle index %d is out of range; the diagram has %d links", ls.index, fc.links)
+		}
+	}
+}
+
+// statement parses one flowchart statement.
+func (fc *flowchart) statement(s line) {
+	if s.text == "" {
+		return
+	}
+	if rest, ok := keyword(s, "end"); ok {
+		if len(fc.open) == 0 {
+			fc.failf(s, 0, "\"end\" without a subgraph")
+			return
+		}
+		fc.open = fc.open[:len(fc.open)-1]
+		fc.noRest(rest, "end")
+		return
+	}
+	if rest, ok := keyword(s, "subgraph"); ok {
+		fc.subgraph(s, rest)
+		return
+	}
+	if rest, ok := keyword(s, "direction"); ok {
+		if !slices.Contains(directions, rest.text) {
+			fc.failf(rest, 0, "unknown direction %q", rest.text)
+		}
+		return
+	}
+	if rest, ok := keyword(s, "classDef"); ok {
+		if rest.text == "" {
+			fc.failf(s, 0, "classDef needs a class name")
+		}
+		return
+	}
+	if rest, ok := keyword(s, "class"); ok {
+		ids, cls := cut(rest)
+		if cls.text == "" {
+			fc.failf(s, 0, "class needs node ids and a class name")
+			return
+		}
+		for _, id := range split(ids, ',') {
+			fc.use(id, "node", id.text)
+		}
+		return
+	}
+	for _, kw := range []string{"style", "click"} {
+		if rest, ok := keyword(s, kw); ok {
+			id, _ := cut(rest)
+			if id.text == "" {
+				fc.failf(s, 0, "%s needs a node id", kw)
+				return
+			}
+			fc.use(id, "node", id.text)
+			return
+		}
+	}
+	if rest, ok := keyword(s, "linkStyle"); ok {
+		fc.linkStyle(s, rest)
+		return
+	}
+	fc.chain(s)
+}
+
+// subgraph opens a subgraph. Its id, when it has one, names a node
+// that style and class statements may refer to.
+func (fc *flowchart) subgraph(s, rest line) {
+	if rest.text == "" {
+		fc.failf(s, 0, "subgraph needs an id or a title")
+		return
+	}
+	if j := scanID(rest.text, 0); j > 0 {
+		fc.define(rest.text[:j])
+	}
+	fc.open = append(fc.open, block{kind: "subgraph", at: s})
+}
+
+// linkStyle records the link indexes a linkStyle statement styles.
+func (fc *flowchart) linkStyle(s, rest line) {
+	idx, _ := cut(rest)
+	if idx.text == "" {
+		fc.failf(s, 0, "linkStyle needs a link index")
+		return
+	}
+	if idx.text == "default" {
+		return
+	}
+	for _, part := range split(idx, ',') {
+		n, err := strconv.Atoi(part.text)
+		if err != nil || n < 0 {
+			fc.failf(part, 0, "linkStyle index %q is not a number", part.text)
+			return
+		}
+		fc.linkStyles = append(fc.linkStyles, linkStyle{at: part, index: n})
+	}
+}
+
+// chain parses a statement of nodes joined by links: groups of nodes
+// joined by "&", with a link between each group and the next.
+func (fc *flowchart) chain(s line) {
+	t := s.text
+	i := 0
+	for {
+		i = fc.vertex(s, i)
+		if fc.failed() {
+			return
+		}
+		i = skipSpace(t, i)
+		if i == len(t) {
+			return
+		}
+		if t[i] == '&' {
+			i = skipSpace(t, i+1)
+			if i == len(t) {
+				fc.failf(s, i, "\"&\" has no node after it")
+				return
+			}
+			continue
+		}
+		start := i
+		i = fc.link(s, i)
+		if fc.failed() {
+			return
+		}
+		fc.links++
+		i = skipSpace(t, i)
+		if i == len(t) {
+			fc.failf(s, start, "link has no target node")
+			return
+		}
+	}
+}
+
+// vertex parses a node: its id, an optional shape with text or an
+// @{...} metadata block, and an optional :::class. It returns the
+// index after the node.
+func (fc *flowchart) vertex(s line, i int) int {
+	t := s.text
+	j := scanID(t, i)
+	if j == i {
+		fc.failf(s, i, "expected a node id, found %q", t[i:])
+		return j
+	}
+	id := t[i:j]
+	if id == "end" {
+		fc.failf(s, i, "\"end\" cannot be a node id; rename the node")
+		return j
+	}
+	fc.define(id)
+	if strings.HasPrefix(t[j:], "@{") {
+		k := strings.IndexByte(t[j:], '}')
+		if k < 0 {
+			fc.failf(s, j, "node metadata has no closing \"}\"")
+			return len(t)
+		}
+		j += k + 1
+	} else if k := skipSpace(t, j); k < len(t) && strings.IndexByte("[({", t[k]) >= 0 || k == j && k < len(t) && t[k] == '>' {
+		j = fc.shape(s, k)
+	}
+	if strings.HasPrefix(t[j:], ":::") {
+		k := scanID(t, j+3)
+		if k == j+3 {
+			fc.failf(s, j, "expected a class name after \":::\"")
+			return k
+		}
+		j = k
+	}
+	return j
+}
+
+// shape parses the node shape opening at byte i and returns the index
+// after its closer.
+func (fc *flowchart) shape(s line, i int) int {
+	t := s.text
+	for _, sh := range shapes {
+		if !strings.HasPrefix(t[i:], sh.open) {
+			continue
+		}
+		k := i + len(sh.open)
+		if k < len(t) && t[k] == '"' {
+			q := strings.IndexByte(t[k+1:], '"')
+			if q < 0 {
+				fc.failf(s, k, "node text has no closing quote")
+				return len(t)
+			}
+			k += q + 2
+			for _, cl := range sh.close {
+				if strings.HasPrefix(t[k:], cl) {
+					return k + len(cl)
+				}
+			}
+			fc.failf(s, k, "expected %q to close the node shape", sh.close[0])
+			return len(t)
+		}
+		for m := k; m < len(t); m++ {
+			for _, cl := range sh.close {
+				if strings.HasPrefix(t[m:], cl) {
+					return m + len(cl)
+				}
+			}
+			if strings.IndexByte(shapeBreakers, t[m]) >= 0 {
+				fc.failf(s, m, "unquoted %q in node text; put the text in quotes", t[m])
+				return len(t)
+			}
+		}
+		fc.failf(s, i, "node shape %q has no closing %q", sh.open, sh.close[0])
+		return len(t)
+	}
+	return i
+}
+
+// link parses a link at byte i, with an optional edge id before it and
+// an optional |label| after it, and returns the index after it.
+func (fc *flowchart) link(s line, i int) int {
+	t := s.text
+	if k := scanID(t, i); k > i && k+1 < len(t) && t[k] == '@' && strings.IndexByte("-=.<~ox", t[k+1]) >= 0 {
+		i = k + 1
+	}
+	start := i
+	if i+1 < len(t) && strings.IndexByte("<ox", t[i]) >= 0 && strings.IndexByte("-=.", t[i+1]) >= 0 {
+		i++
+	}
+	switch {
+	case strings.HasPrefix(t[i:], "~~~"):
+		for i < len(t) && t[i] == '~' {
+			i++
+		}
+	case strings.HasPrefix(t[i:], "-."):
+		i = fc.dottedLink(s, start, i+1)
+	case t[i] == '-' || t[i] == '=':
+		i = fc.solidLink(s, start, i)
+	default:
+		fc.failf(s, start, "expected a link or \"&\", found %q", t[start:])
+		return len(t)
+	}
+	if fc.failed() {
+		return len(t)
+	}
+	if j := skipSpace(t, i); j < len(t) && t[j] == '|' {
+		e := strings.IndexByte(t[j+1:], '|')
+		if e < 0 {
+			fc.failf(s, j, "link label has no closing \"|\"")
+			return len(t)
+		}
+		i = j + e + 2
+	}
+	return i
+}
+
+// solidLink parses a "--" or "==" link whose body starts at byte i:
+// an arrow such as "-->", "---", or "==x", or a text link such as
+// "-- text -->". start is where the link began, for errors.
+func (fc *flowchart) solidLink(s line, start, i int) int {
+	t := s.text
+	ch := t[i]
+	k := i
+	for k < len(t) && t[k] == ch {
+		k++
+	}
+	switch {
+	case k-i < 2:
+		fc.failf(s, start, "incomplete link %q", t[start:k])
+	case k < len(t) && strings.IndexByte("xo>", t[k]) >= 0:
+		return k + 1
+	case k-i >= 3:
+		return k
+	default:
+		if end := closeTextLink(t, k, ch); end >= 0 {
+			return end
+		}
+		fc.failf(s, start, "link text has no closing %q", string([]byte{ch, ch, '>'}))
+	}
+	return len(t)
+}
+
+// closeTextLink finds the arrow that ends a text link opened with two
+// ch bytes, searching from byte i, and returns the index after it.
+func closeTextLink(t string, i int, ch byte) int {
+	for i < len(t) {
+		m := strings.IndexByte(t[i:], ch)
+		if m < 0 {
+			return -1
+		}
+		k := i + m
+		n := k
+		for n < len(t) && t[n] == ch {
+			n++
+		}
+		switch {
+		case n-k < 2:
+		case n < len(t) && strings.IndexByte("xo>", t[n]) >= 0:
+			return n + 1
+		case n-k >= 3:
+			return n
+		}
+		i = n
+	}
+	return -1
+}
+
+// dottedLink parses a dotted link whose dots start at byte i: an arrow
+// such as "-.->" or "-.-", or a text link such as "-. text .->".
+func (fc *flowchart) dottedLink(s line, start, i int) int {
+	t := s.text
+	k := i
+	for k < len(t) && t[k] == '.' {
+		k++
+	}
+	if k < len(t) && t[k] == '-' {
+		k++
+	} else {
+		e := strings.Index(t[k:], ".-")
+		if e < 0 {
+			fc.failf(s, start, "dotted link text has no closing \".->\"")
+			return len(t)
+		}
+		k += e + 2
+	}
+	if k < len(t) && strings.IndexByte("xo>", t[k]) >= 0 {
+		k++
+	}
+	return k
+}
@@ -0,0 +1,91 @@
+package mermaid
+
+import (
+	"slices"
+	"strings"
+)
+
+// ganttKeywords lists the gantt statements that are not tasks.
+var ganttKeywords = []string{
+	"axisFormat", "dateFormat", "displayMode", "excludes", "inclusiveEndDates",
+	"includes", "section", "tickInterval", "title", "todayMarker", "topAxis",
+	"weekday", "weekend",
+}
+
+// ganttTags lists the tags that may open a task's metadata.
+var ganttTags = []string{"active", "crit", "done", "milestone"}
+
+// gantt is the state of one gantt chart parse.
+type gantt struct {
+	*checker
+}
+
+func parseGantt(c *checker, rest line, body []line) {
+	c.noRest(rest, "gantt")
+	g := &gantt{checker: c}
+	c.each(body, g.statement)
+}
+
+// statement parses one gantt statement.
+func (g *gantt) statement(l line) {
+	word, rest := cut(l)
+	if word.text == "click" {
+		id, _ := cut(rest)
+		if id.text == "" {
+			g.failf(l, 0, "click needs a task id")
+			return
+		}
+		g.use(id, "task", id.text)
+		return
+	}
+	for _, kw := range ganttKeywords {
+		if word.text == kw || strings.HasPrefix(l.text, kw+":") {
+			return
+		}
+	}
+	g.task(l)
+}
+
+// task parses "Name : tags, id, start, end". The metadata holds one to
+// three dates after the tags: the end; the start and the end; or an
+// id, the start, and the end. A start of "after a b" and an end of
+// "until c" refer to other tasks by id.
+func (g *gantt) task(l line) {
+	i := strings.IndexByte(l.text, ':')
+	if i < 0 {
+		g.failf(l, 0, "unexpected %q; expected a task such as \"Name : 2024-01-01, 3d\"", l.text)
+		return
+	}
+	if strings.TrimSpace(l.text[:i]) == "" {
+		g.failf(l, 0, "task has no name")
+		return
+	}
+	parts := split(l.at(i+1), ',')
+	for len(parts) > 0 && slices.Contains(ganttTags, parts[0].text) {
+		parts = parts[1:]
+	}
+	if len(parts) == 0 || len(parts) > 3 || slices.ContainsFunc(parts, func(p line) bool { return p.text == "" }) {
+		g.failf(l, i, "task needs one to three comma-separated dates after its tags")
+		return
+	}
+	if len(parts) == 3 {
+		g.define(parts[0].text)
+	}
+	if len(parts) >= 2 {
+		if after, ok := keyword(parts[len(parts)-2], "after"); ok {
+			g.useTasks(after)
+		}
+	}
+	if until, ok := keyword(parts[len(parts)-1], "until"); ok {
+		g.useTasks(until)
+	}
+}
+
+// useTasks records each blank-separated task id in l as a reference.
+func (g *gantt) useTasks(l line) {
+	for l.text != "" {
+		id, rest := cut(l)
+		g.use(id, "task", id.text)
+		l = rest
+	}
+}
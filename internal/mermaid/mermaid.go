@@ -0,0 +1,331 @@
+// Package mermaid checks Mermaid diagram source without rendering it.
+// It parses the six common diagram types — flowchart, sequence,
+// class, state, entity-relationship, and gantt — one statement at a
+// time. It reports the first syntax error and, when the syntax is
+// sound, every reference to a node, state, class, or task the diagram
+// never defines. The other diagram types are recognised and skipped.
+package mermaid
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+)
+
+// Error is one problem in a diagram. Line and Column are 1-based and
+// count bytes of the diagram source.
+type Error struct {
+	Line   int
+	Column int
+	Msg    string
+}
+
+// Error implements error.
+func (e Error) Error() string { return fmt.Sprintf("%d:%d: %s", e.Line, e.Column, e.Msg) }
+
+// Types lists the diagram types Check parses.
+var Types = []string{"class", "er", "flowchart", "gantt", "sequence", "state"}
+
+// headers maps each header keyword to its diagram type. An empty type
+// marks a diagram Mermaid renders but Check does not parse.
+var headers = map[string]string{
+	"flowchart":       "flowchart",
+	"flowchart-elk":   "flowchart",
+	"graph":           "flowchart",
+	"sequenceDiagram": "sequence",
+	"classDiagram":    "class",
+	"classDiagram-v2": "class",
+	"stateDiagram":    "state",
+	"stateDiagram-v2": "state",
+	"erDiagram":       "er",
+	"gantt":           "gantt",
+
+	"architecture-beta":  "",
+	"block-beta":         "",
+	"C4Component":        "",
+	"C4Container":        "",
+	"C4Context":          "",
+	"C4Deployment":       "",
+	"C4Dynamic":          "",
+	"gitGraph":           "",
+	"info":               "",
+	"journey":            "",
+	"kanban":             "",
+	"mindmap":            "",
+	"packet-beta":        "",
+	"pie":                "",
+	"quadrantChart":      "",
+	"radar-beta":         "",
+	"requirementDiagram": "",
+	"sankey-beta":        "",
+	"timeline":           "",
+	"treemap-beta":       "",
+	"xychart-beta":       "",
+	"zenuml":             "",
+}
+
+// parsers maps each parsed diagram type to its statement parser. rest
+// is what follows the header keyword on its line.
+var parsers = map[string]func(c *checker, rest line, body []line){
+	"flowchart": parseFlowchart,
+	"sequence":  parseSequence,
+	"class":     parseClass,
+	"state":     parseState,
+	"er":        parseER,
+	"gantt":     parseGantt,
+}
+
+// Check parses src. It returns the diagram type, or "" when src is
+// empty or its type is one Check does not parse. The errors hold the
+// first syntax error or, when there is none, every undefined
+// reference, in source order.
+func Check(src string) (string, []Error) {
+	lines := statementLines(src)
+	if len(lines) == 0 {
+		return "", nil
+	}
+	head := lines[0]
+	word, rest := cut(head)
+	typ, ok := headers[word.text]
+	if !ok {
+		return "", []Error{{Line: head.num, Column: head.col, Msg: fmt.Sprintf("unknown diagram type %q", word.text)}}
+	}
+	if typ == "" {
+		return "", nil
+	}
+	c := &checker{defined: map[string]bool{}}
+	parsers[typ](c, rest, lines[1:])
+	return typ, c.errors()
+}
+
+// line is one source line: its 1-based number, its text without the
+// surrounding blanks, and the 1-based column where that text starts.
+type line struct {
+	num  int
+	col  int
+	text string
+}
+
+// at returns the part of l from byte i on, its blanks trimmed.
+func (l line) at(i int) line {
+	s := l.text[i:]
+	t := strings.TrimLeft(s, " \t")
+	return line{num: l.num, col: l.col + i + len(s) - len(t), text: strings.TrimRight(t, " \t")}
+}
+
+// cut splits off the first blank-separated word of l.
+func cut(l line) (word, rest line) {
+	i := strings.IndexAny(l.text, " \t")
+	if i < 0 {
+		return l, line{num: l.num, col: l.col + len(l.text)}
+	}
+	return line{num: l.num, col: l.col, text: l.text[:i]}, l.at(i)
+}
+
+// statementLines returns the non-blank lines of src, without the front
+// matter block and the %% comment and directive lines.
+func statementLines(src string) []line {
+	raw := strings.Split(strings.ReplaceAll(src, "\r\n", "\n"), "\n")
+	start := 0
+	if len(raw) > 0 && strings.TrimSpace(raw[0]) == "---" {
+		for i := 1; i < len(raw); i++ {
+			if strings.TrimSpace(raw[i]) == "---" {
+				start = i + 1
+				break
+			}
+		}
+	}
+	var out []line
+	for i := start; i < len(raw); i++ {
+		l := line{num: i + 1, col: 1, text: raw[i]}.at(0)
+		if l.text == "" || strings.HasPrefix(l.text, "%%") {
+			continue
+		}
+		out = append(out, l)
+	}
+	return out
+}
+
+// keyword reports whether l opens with the word kw and returns the
+// rest of the line.
+func keyword(l line, kw string) (line, bool) {
+	if !strings.HasPrefix(l.text, kw) {
+		return line{}, false
+	}
+	if len(l.text) > len(kw) && l.text[len(kw)] != ' ' && l.text[len(kw)] != '\t' {
+		return line{}, false
+	}
+	return l.at(len(kw)), true
+}
+
+// checker collects what a diagram parser finds.
+type checker struct {
+	err     *Error          // first syntax error
+	defined map[string]bool // names the diagram defines
+	refs    []ref           // names the diagram uses
+	other   []Error         // reference problems found while parsing
+}
+
+// ref is one use of a name that must be defined somewhere.
+type ref struct {
+	num, col int
+	kind     string
+	name     string
+}
+
+// failf records a syntax error at byte i of l; only the first counts.
+func (c *checker) failf(l line, i int, format string, args ...any) {
+	if c.err == nil {
+		c.err = &Error{Line: l.num, Column: l.col + i, Msg: fmt.Sprintf(format, args...)}
+	}
+}
+
+// failed reports whether a syntax error has been recorded.
+func (c *checker) failed() bool { return c.err != nil }
+
+func (c *checker) define(name string) { c.defined[name] = true }
+
+// use records a reference to name, a kind, at the start of l.
+func (c *checker) use(l line, kind, name string) {
+	c.refs = append(c.refs, ref{num: l.num, col: l.col, kind: kind, name: name})
+}
+
+// problem records a reference problem at the start of l.
+func (c *checker) problem(l line, format string, args ...any) {
+	c.other = append(c.other, Error{Line: l.num, Column: l.col, Msg: fmt.Sprintf(format, args...)})
+}
+
+// errors returns the syntax error or the reference problems.
+func (c *checker) errors() []Error {
+	if c.err != nil {
+		return []Error{*c.err}
+	}
+	out := c.other
+	for _, r := range c.refs {
+		if !c.defined[r.name] {
+			out = append(out, Error{Line: r.num, Column: r.col, Msg: fmt.Sprintf("undefined %s %q", r.kind, r.name)})
+		}
+	}
+	slices.SortStableFunc(out, func(a, b Error) int {
+		if a.Line != b.Line {
+			return a.Line - b.Line
+		}
+		return a.Column - b.Column
+	})
+	return out
+}
+
+// each calls fn for every statement in body until a syntax error. It
+// handles the accessibility statements every diagram type accepts:
+// accTitle, and accDescr on one line or in a braced block.
+func (c *checker) each(body []line, fn func(l line)) {
+	for i := 0; i < len(body) && !c.failed(); i++ {
+		l := body[i]
+		after, acc := accStatement(l.text)
+		switch {
+		case !acc:
+			fn(l)
+		case strings.HasPrefix(after, ":"):
+		case strings.HasPrefix(after, "{") && strings.HasPrefix(l.text, "accDescr"):
+			if strings.Contains(after, "}") {
+				continue
+			}
+			j := i + 1
+			for j < len(body) && !strings.Contains(body[j].text, "}") {
+				j++
+			}
+			if j == len(body) {
+				c.failf(l, 0, "accDescr has no closing \"}\"")
+				return
+			}
+			i = j
+		default:
+			fn(l)
+		}
+	}
+}
+
+// accStatement reports whether s opens with accTitle or accDescr and
+// returns what follows the keyword.
+func accStatement(s string) (string, bool) {
+	for _, kw := range []string{"accTitle", "accDescr"} {
+		if strings.HasPrefix(s, kw) {
+			return strings.TrimLeft(s[len(kw):], " \t"), true
+		}
+	}
+	return "", false
+}
+
+// noRest records an error when a statement has text after its last
+// part.
+func (c *checker) noRest(rest line, after string) {
+	if rest.text != "" {
+		c.failf(rest, 0, "unexpected %q after %s", rest.text, after)
+	}
+}
+
+// block is an open block statement such as a subgraph or a loop.
+type block struct {
+	kind string
+	at   line
+}
+
+// closeAll records an error for the innermost block left open at the
+// end of the diagram.
+func (c *checker) closeAll(open []block, closer string) {
+	if len(open) > 0 {
+		b := open[len(open)-1]
+		c.failf(b.at, 0, "%s has no closing %q", b.kind, closer)
+	}
+}
+
+// split cuts l at every sep outside double quotes and returns the
+// trimmed parts, empty ones included.
+func split(l line, sep byte) []line {
+	var out []line
+	quoted, start := false, 0
+	for i := 0; i < len(l.text); i++ {
+		switch l.text[i] {
+		case '"':
+			quoted = !quoted
+		case sep:
+			if !quoted {
+				out = append(out, line{num: l.num, col: l.col, text: l.text[:i]}.at(start))
+				start = i + 1
+			}
+		}
+	}
+	return append(out, l.at(start))
+}
+
+// isIDByte reports whether c may appear in a node, state, or entity
+// name. Bytes of multi-byte UTF-8 sequences count as name bytes.
+func isIDByte(c byte) bool {
+	return c == '_' || c >= '0' && c <= '9' || c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z' || c >= 0x80
+}
+
+// scanID returns the end of the name starting at byte i of s. A '-' or
+// '.' joins two name parts, so "web-server" is one name while the "-"
+// of "a-->b" starts a link.
+func scanID(s string, i int) int {
+	for i < len(s) {
+		switch {
+		case isIDByte(s[i]):
+			i++
+		case (s[i] == '-' || s[i] == '.') && i+1 < len(s) && isIDByte(s[i+1]) && i > 0 && isIDByte(s[i-1]):
+			i++
+		default:
+			return i
+		}
+	}
+	return i
+}
+
+// skipSpace returns the index of the first non-blank byte of s at or
+// after i.
+func skipSpace(s string, i int) int {
+	for i < len(s) && (s[i] == ' ' || s[i] == '\t') {
+		i++
+	}
+	return i
+}
@@ -0,0 +1,268 @@
+package mermaid
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCheck_ValidDiagrams(t *testing.T) {
+	tests := []struct {
+		name, typ, src string
+	}{
+		{"flowchart", "flowchart", `---
+title: Build
+---
+%%{init: {"theme": "dark"}}%%
+flowchart LR
+    A[Hard edge] -->|Link text| B(Round edge)
+    B --> C{Decision}
+    C -->|One| D[Result one]
+    C -->|Two| E[Result two]
+    F(("circle")) -.-> G>flag] ==> H{{hex}}
+    I[/lean/] --- J[\alt\] ~~~ K[(db)] --o L([stadium]) --x M[[sub]]
+    N -- text --> O -. dotted .-> P == thick ==> Q --plain--> R ---|label| S
+    R & S --> T & U
+    V <--> W
+    X e1@--> Y
+    Z@{ shape: rect, label: "Box" }
+    A:::hot --> Z
+    classDef hot fill:#f96
+    class A,B hot
+    style C fill:#f9f
+    click D "https://example.com" "Tooltip"
+    linkStyle 0 stroke:#ff3
+    linkStyle default stroke:#333
+    subgraph one [First]
+        direction TB
+        a1-->a2
+    end
+    subgraph two
+        b1-->b2
+    end
+    one --> two
+    style one fill:#eee
+    web-server --> db.primary
+    accTitle: Build flow
+    accDescr {
+        The build flow.
+    }
+`},
+		{"graph header", "flowchart", "graph TD;\n  A-->B;\n  B-->C;"},
+		{"statements on header line", "flowchart", "graph LR; A-->B"},
+		{"sequence", "sequence", `sequenceDiagram
+    title: Login
+    autonumber
+    participant A as Alice
+    actor J as John
+    box Aqua Group
+        participant C
+    end
+    A->>+J: Hello John, how are you?
+    J-->>-A: Great!
+    A-)J: async
+    A-xJ: lost
+    A--xJ: lost
+    A<<->>J: both ways
+    loop Every minute
+        J->>A: ping
+    end
+    alt is sick
+        A->>J: Not so good
+    else is well
+        A->>J: Feeling fresh
+    end
+    par Alice to Bob
+        A->>J: hi
+    and Alice to Carl
+        A->>C: hi
+    end
+    critical Connect
+        A->>C: connect
+    option Timeout
+        A->>A: log
+    end
+    rect rgb(191, 223, 255)
+        A->>J: inside
+    end
+    Note right of J: thinks
+    Note over A,J: A typical interaction
+    activate C
+    C->>A: done
+    deactivate C
+    create participant D
+    A->>D: hi
+    destroy D
+    D->>A: bye
+    link A: Dashboard @ https://example.com
+`},
+		{"class", "class", `classDiagram
+    direction RL
+    note "From Duck till Zebra"
+    Animal <|-- Duck
+    note for Duck "can fly"
+    Animal <|-- Fish
+    Animal "1" *-- "many" Leg : has
+    Animal : +int age
+    Animal : +isMammal() bool
+    class Duck{
+        +String beakColor
+        +swim()
+    }
+    class Shape~T~ {
+        <<interface>>
+        draw()
+    }
+    class Zebra["Striped horse"]
+    <<abstract>> Fish
+    classA ..|> classB : Realization
+    classC .. classD
+    classE --> classF
+    classG o-- classH
+    namespace Shapes {
+        class Triangle
+        class Square
+    }
+    style Duck fill:#f9f
+    cssClass "Duck,Fish" highlight
+    click Zebra href "https://example.com"
+`},
+		{"state", "state", `stateDiagram-v2
+    direction LR
+    [*] --> Still
+    Still --> [*]
+    Still --> Moving : push
+    Moving --> Crash
+    Crash --> [*]
+    state "Waiting for input" as Wait
+    Wait : idle
+    state Fork <<fork>>
+    state Busy {
+        [*] --> Working
+        Working --> [*]
+        --
+        [*] --> Logging
+    }
+    note right of Busy : composite
+    note left of Wait
+        multi-line note
+    end note
+    classDef bad fill:#f00
+    class Crash bad
+    Moving:::bad
+    style Wait fill:#eee
+`},
+		{"er", "er", `erDiagram
+    CUSTOMER ||--o{ ORDER : places
+    ORDER ||--|{ LINE-ITEM : contains
+    CUSTOMER }|..|{ DELIVERY-ADDRESS : uses
+    PRODUCT
+    CUSTOMER {
+        string name PK
+        string email UK "unique"
+    }
+    ORDER["Order"] {
+        int id
+    }
+    PERSON only one to zero or more CAR : drives
+`},
+		{"gantt", "gantt", `gantt
+    title A Gantt Diagram
+    dateFormat YYYY-MM-DD
+    excludes weekends
+    section Section
+        A task          :a1, 2014-01-01, 30d
+        Another task    :after a1, 20d
+    section Another
+        Task in Another :done, crit, b1, 2014-01-12, 12d
+        another task    :24d
+        Deploy          :milestone, until b1
+        Joined          :j1, after a1 b1, 1d
+    click a1 href "https://example.com"
+`},
+		{"unparsed type", "", "pie title Pets\n  \"Dogs\" : 386\n"},
+		{"empty", "", "\n%% just a comment\n"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			typ, errs := Check(tt.src)
+			assert.Equal(t, tt.typ, typ)
+			assert.Empty(t, errs)
+		})
+	}
+}
+
+func TestCheck_Errors(t *testing.T) {
+	tests := []struct {
+		name      string
+		src       string
+		line, col int
+		msg       string
+	}{
+		{"unknown type", "flowchat LR\n", 1, 1, `unknown diagram type "flowchat"`},
+		{"bad direction", "graph XY\n", 1, 7, `unknown direction "XY"`},
+		{"unquoted paren", "graph TD\n  A[Call (twice)] --> B\n", 2, 10,
+			`unquoted '(' in node text; put the text in quotes`},
+		{"unclosed shape", "graph TD\n  A[Start --> B\n", 2, 4, `node shape "[" has no closing "]"`},
+		{"dangling link", "graph TD\n  A -->\n", 2, 5, "link has no target node"},
+		{"unclosed link text", "graph TD\n  A--B\n", 2, 4, `link text has no closing "-->"`},
+		{"incomplete link", "graph TD\n  A - B\n", 2, 5, `incomplete link "-"`},
+		{"garbage after node", "graph TD\n  A B\n", 2, 5, `expected a link or "&", found "B"`},
+		{"end node", "graph TD\n  A --> end\n", 2, 9, `"end" cannot be a node id; rename the node`},
+		{"stray end", "graph TD\n  A --> B\n  end\n", 3, 3, `"end" without a subgraph`},
+		{"open subgraph", "graph TD\n  subgraph one\n  A --> B\n", 2, 3, `subgraph has no closing "end"`},
+		{"unclosed label", "graph TD\n  A -->|yes B\n", 2, 8, `link label has no closing "|"`},
+		{"sequence no text", "sequenceDiagram\n  A->>B\n", 2, 8, `message has no text; add ": text"`},
+		{"sequence stray else", "sequenceDiagram\n  loop x\n  else y\n  end\n", 3, 3, `"else" outside the block it belongs to`},
+		{"sequence open loop", "sequenceDiagram\n  loop forever\n  A->>B: x\n", 2, 3, `loop has no closing "end"`},
+		{"sequence note", "sequenceDiagram\n  Note above A: x\n", 2, 8,
+			`expected left of, right of, or over after note, found "above A: x"`},
+		{"class relation", "classDiagram\n  A <|-> B\n", 2, 5, `expected a relation such as "<|--" or "..>", found "<|-> B"`},
+		{"class open body", "classDiagram\n  class A {\n  +x\n", 2, 3, `class has no closing "}"`},
+		{"state arrow", "stateDiagram-v2\n  A -> B\n", 2, 5, `expected "-->" or ":" after the state name, found "-> B"`},
+		{"state note", "stateDiagram-v2\n  A --> B\n  note right of B\n  text\n", 3, 3, `note has no closing "end note"`},
+		{"er label", "erDiagram\n  A ||--o{ B\n", 2, 13, `relationship has no label; add ": label"`},
+		{"er cardinality", "erDiagram\n  A |--o{ B : x\n", 2, 5, `expected a relationship such as "||--o{", found "|--o{ B : x"`},
+		{"gantt no colon", "gantt\n  Write docs 3d\n", 2, 3, `unexpected "Write docs 3d"; expected a task such as "Name : 2024-01-01, 3d"`},
+		{"gantt too many", "gantt\n  A : a, b, c, d\n", 2, 5, "task needs one to three comma-separated dates after its tags"},
+		{"open accDescr", "graph TD\n  accDescr {\n  text\n", 2, 3, `accDescr has no closing "}"`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, errs := Check(tt.src)
+			require.Len(t, errs, 1, "%v", errs)
+			assert.Equal(t, Error{Line: tt.line, Column: tt.col, Msg: tt.msg}, errs[0])
+		})
+	}
+}
+
+func TestCheck_UndefinedReferences(t *testing.T) {
+	tests := []struct {
+		name string
+		src  string
+		want []Error
+	}{
+		{"flowchart", "graph TD\n  A --> B\n  style Bx fill:#f9f\n  class A,C hot\n  click D call()\n  linkStyle 0,3 stroke:red\n",
+			[]Error{
+				{3, 9, `undefined node "Bx"`},
+				{4, 11, `undefined node "C"`},
+				{5, 9, `undefined node "D"`},
+				{6, 15, "linkStyle index 3 is out of range; the diagram has 1 links"},
+			}},
+		{"sequence deactivate", "sequenceDiagram\n  A->>B: x\n  deactivate B\n  B-->>-A: y\n",
+			[]Error{
+				{3, 3, `"B" is deactivated but not active`},
+				{4, 3, `"B" is deactivated but not active`},
+			}},
+		{"class", "classDiagram\n  A <|-- B\n  note for C \"x\"\n", []Error{{3, 12, `undefined class "C"`}}},
+		{"state", "stateDiagram-v2\n  A --> B\n  note left of Z : x\n", []Error{{3, 16, `undefined state "Z"`}}},
+		{"gantt", "gantt\n  A : a1, 2024-01-01, 1d\n  B : after a2, 1d\n", []Error{{3, 13, `undefined task "a2"`}}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, errs := Check(tt.src)
+			assert.Equal(t, tt.want, errs)
+		})
+	}
+}
@@ -0,0 +1,198 @@
+package mermaid
+
+import (
+	"slices"
+	"strings"
+)
+
+// arrows lists the sequence message arrows. Longer arrows come first,
+// so "-->>" wins over "-->" and "->>".
+var arrows = []string{"<<-->>", "<<->>", "-->>", "->>", "--x", "--)", "-->", "-x", "-)", "->"}
+
+// sequenceBlocks maps each block keyword to the keywords that may
+// split it, such as the else branches of an alt.
+var sequenceBlocks = map[string][]string{
+	"loop":     nil,
+	"alt":      {"else"},
+	"opt":      nil,
+	"par":      {"and"},
+	"par_over": {"and"},
+	"critical": {"option"},
+	"break":    nil,
+	"rect":     nil,
+	"box":      nil,
+}
+
+// sequence is the state of one sequence diagram parse.
+type sequence struct {
+	*checker
+	open   []block
+	active map[string]int // activation depth per participant
+}
+
+func parseSequence(c *checker, rest line, body []line) {
+	c.noRest(rest, "sequenceDiagram")
+	sq := &sequence{checker: c, active: map[string]int{}}
+	c.each(body, sq.statement)
+	if !c.failed() {
+		c.closeAll(sq.open, "end")
+	}
+}
+
+// statement parses one sequence diagram statement.
+func (sq *sequence) statement(l line) {
+	word, rest := cut(l)
+	switch kw := word.text; {
+	case kw == "participant" || kw == "actor":
+		sq.participant(l, rest)
+	case kw == "create":
+		what, name := cut(rest)
+		if what.text != "participant" && what.text != "actor" {
+			sq.failf(rest, 0, "expected participant or actor after create, found %q", rest.text)
+			return
+		}
+		sq.participant(l, name)
+	case kw == "destroy":
+		if rest.text == "" {
+			sq.failf(l, 0, "destroy needs a participant")
+		}
+	case kw == "end":
+		if len(sq.open) == 0 {
+			sq.failf(l, 0, "\"end\" without a block")
+			return
+		}
+		sq.open = sq.open[:len(sq.open)-1]
+		sq.noRest(rest, "end")
+	case kw == "else" || kw == "and" || kw == "option":
+		if len(sq.open) == 0 || !slices.Contains(sequenceBlocks[sq.open[len(sq.open)-1].kind], kw) {
+			sq.failf(l, 0, "%q outside the block it belongs to", kw)
+		}
+	case isSequenceBlock(kw):
+		if kw == "box" && slices.ContainsFunc(sq.open, func(b block) bool { return b.kind == "box" }) {
+			sq.failf(l, 0, "a box cannot hold another box")
+			return
+		}
+		sq.open = append(sq.open, block{kind: kw, at: l})
+	case kw == "autonumber" || kw == "title" || strings.HasPrefix(kw, "title:"):
+	case kw == "activate":
+		if rest.text == "" {
+			sq.failf(l, 0, "activate needs a participant")
+			return
+		}
+		sq.active[rest.text]++
+	case kw == "deactivate":
+		if rest.text == "" {
+			sq.failf(l, 0, "deactivate needs a participant")
+			return
+		}
+		sq.deactivate(l, rest.text)
+	case strings.EqualFold(kw, "note"):
+		sq.note(l, rest)
+	case kw == "link" || kw == "links" || kw == "properties" || kw == "details":
+		if !strings.Contains(rest.text, ":") {
+			sq.failf(l, 0, "%s needs \"participant: value\"", kw)
+		}
+	default:
+		sq.message(l)
+	}
+}
+
+// isSequenceBlock reports whether kw opens a block closed by "end".
+func isSequenceBlock(kw string) bool {
+	_, ok := sequenceBlocks[kw]
+	return ok
+}
+
+// participant parses the name and optional alias of a declaration.
+func (sq *sequence) participant(l, rest line) {
+	if rest.text == "" || strings.HasPrefix(rest.text, "as ") {
+		sq.failf(l, 0, "participant needs a name")
+	}
+}
+
+// deactivate ends one activation of name. Mermaid refuses to render a
+// diagram that deactivates a participant that is not active.
+func (sq *sequence) deactivate(l line, name string) {
+	if sq.active[name] == 0 {
+		sq.problem(l, "%q is deactivated but not active", name)
+		return
+	}
+	sq.active[name]--
+}
+
+// note parses "Note left of A: text", "Note right of A: text", or
+// "Note over A,B: text".
+func (sq *sequence) note(l, rest line) {
+	var where line
+	ok := false
+	for _, pos := range []string{"left of", "right of", "over"} {
+		if r, found := keyword(rest, pos); found {
+			where, ok = r, true
+			break
+		}
+	}
+	if !ok {
+		sq.failf(rest, 0, "expected left of, right of, or over after note, found %q", rest.text)
+		return
+	}
+	names, _, found := strings.Cut(where.text, ":")
+	if !found {
+		sq.failf(l, len(l.text), "note has no text; add \": text\"")
+		return
+	}
+	for _, n := range split(line{num: where.num, col: where.col, text: names}, ',') {
+		if n.text == "" {
+			sq.failf(where, 0, "note needs a participant")
+			return
+		}
+	}
+}
+
+// message parses "A->>B: text", with an optional + or - after the
+// arrow that activates the receiver or deactivates the sender.
+func (sq *sequence) message(l line) {
+	t := l.text
+	head := t
+	if i := strings.IndexByte(t, ':'); i >= 0 {
+		head = t[:i]
+	}
+	at, arrow := -1, ""
+	for i := 0; i < len(head) && at < 0; i++ {
+		for _, a := range arrows {
+			if strings.HasPrefix(head[i:], a) {
+				at, arrow = i, a
+				break
+			}
+		}
+	}
+	if at < 0 {
+		sq.failf(l, 0, "unexpected %q; expected a message such as \"A->>B: text\"", t)
+		return
+	}
+	from := strings.TrimSpace(head[:at])
+	if from == "" {
+		sq.failf(l, at, "message has no sender")
+		return
+	}
+	k := at + len(arrow)
+	var act byte
+	if k < len(head) && (head[k] == '+' || head[k] == '-') {
+		act = head[k]
+		k++
+	}
+	to := strings.TrimSpace(head[k:])
+	if to == "" {
+		sq.failf(l, k, "message has no receiver")
+		return
+	}
+	if len(head) == len(t) {
+		sq.failf(l, len(t), "message has no text; add \": text\"")
+		return
+	}
+	switch act {
+	case '+':
+		sq.active[to]++
+	case '-':
+		sq.deactivate(l, from)
+	}
+}
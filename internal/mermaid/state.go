@@ -0,0 +1,208 @@
+package mermaid
+
+import (
+	"slices"
+	"strings"
+)
+
+// stateKinds lists the pseudo-state stereotypes a state may take.
+var stateKinds = []string{"<<fork>>", "<<join>>", "<<choice>>"}
+
+// stateDiagram is the state of one state diagram parse.
+type stateDiagram struct {
+	*checker
+	open []block // composite states
+	note *line   // the open multi-line note
+}
+
+func parseState(c *checker, rest line, body []line) {
+	c.noRest(rest, "stateDiagram")
+	sd := &stateDiagram{checker: c}
+	c.each(body, sd.statement)
+	if c.failed() {
+		return
+	}
+	if sd.note != nil {
+		c.failf(*sd.note, 0, "note has no closing \"end note\"")
+		return
+	}
+	c.closeAll(sd.open, "}")
+}
+
+// statement parses one state diagram statement.
+func (sd *stateDiagram) statement(l line) {
+	if sd.note != nil {
+		if l.text == "end note" {
+			sd.note = nil
+		}
+		return
+	}
+	switch l.text {
+	case "}":
+		if len(sd.open) == 0 {
+			sd.failf(l, 0, "\"}\" without an open state")
+			return
+		}
+		sd.open = sd.open[:len(sd.open)-1]
+		return
+	case "--":
+		if len(sd.open) == 0 {
+			sd.failf(l, 0, "\"--\" outside a composite state")
+		}
+		return
+	}
+	word, rest := cut(l)
+	switch word.text {
+	case "state":
+		sd.state(l, rest)
+	case "note":
+		sd.noteStatement(l, rest)
+	case "direction":
+		if !slices.Contains(directions, rest.text) {
+			sd.failf(rest, 0, "unknown direction %q", rest.text)
+		}
+	case "classDef", "hide", "scale":
+	case "class":
+		ids, cls := cut(rest)
+		if cls.text == "" {
+			sd.failf(l, 0, "class needs state ids and a class name")
+			return
+		}
+		for _, id := range split(ids, ',') {
+			sd.use(id, "state", id.text)
+		}
+	case "style":
+		id, _ := cut(rest)
+		if id.text == "" {
+			sd.failf(l, 0, "style needs a state id")
+			return
+		}
+		sd.use(id, "state", id.text)
+	default:
+		sd.transition(l)
+	}
+}
+
+// state parses the forms of a state declaration: "state Name",
+// `state "Description" as Name`, "state Name : Description",
+// "state Name <<choice>>", and "state Name {" opening a composite.
+func (sd *stateDiagram) state(l, rest line) {
+	if strings.HasPrefix(rest.text, `"`) {
+		e := strings.IndexByte(rest.text[1:], '"')
+		if e < 0 {
+			sd.failf(rest, 0, "state description has no closing quote")
+			return
+		}
+		as, ok := keyword(rest.at(e+2), "as")
+		if !ok {
+			sd.failf(rest, e+2, "expected \"as Name\" after the state description")
+			return
+		}
+		rest = as
+	}
+	t := rest.text
+	j := scanID(t, 0)
+	if j == 0 {
+		sd.failf(l, 0, "state needs a name")
+		return
+	}
+	sd.define(t[:j])
+	if strings.HasPrefix(t[j:], ":::") {
+		j = scanID(t, j+3)
+	}
+	tail := strings.TrimSpace(t[j:])
+	switch {
+	case tail == "":
+	case tail == "{":
+		sd.open = append(sd.open, block{kind: "state " + t[:scanID(t, 0)], at: l})
+	case strings.HasPrefix(tail, ":"):
+	case slices.Contains(stateKinds, tail):
+	default:
+		sd.failf(rest, j, "unexpected %q after the state name", tail)
+	}
+}
+
+// noteStatement parses "note left of A : text", or "note right of A"
+// opening a note closed by "end note".
+func (sd *stateDiagram) noteStatement(l, rest line) {
+	if strings.HasPrefix(rest.text, `"`) {
+		return // a floating note: note "text" as N
+	}
+	var target line
+	ok := false
+	for _, pos := range []string{"left of", "right of"} {
+		if r, found := keyword(rest, pos); found {
+			target, ok = r, true
+			break
+		}
+	}
+	if !ok {
+		sd.failf(rest, 0, "expected left of or right of after note, found %q", rest.text)
+		return
+	}
+	name := target.text
+	multi := true
+	if i := strings.IndexByte(name, ':'); i >= 0 {
+		name, multi = strings.TrimSpace(name[:i]), false
+	}
+	if name == "" {
+		sd.failf(l, 0, "note needs a state")
+		return
+	}
+	sd.use(target, "state", name)
+	if multi {
+		sd.note = &l
+	}
+}
+
+// transition parses "A --> B : label", "A : description", a bare
+// state name, or "A:::class". [*] is the start or end state.
+func (sd *stateDiagram) transition(l line) {
+	t := l.text
+	j := scanState(t, 0)
+	if j == 0 {
+		sd.failf(l, 0, "unexpected %q", t)
+		return
+	}
+	from := t[:j]
+	k := skipSpace(t, j)
+	switch {
+	case k == len(t), strings.HasPrefix(t[k:], ":::"), t[k] == ':':
+		sd.defineState(from)
+	case strings.HasPrefix(t[k:], "-->"):
+		m := skipSpace(t, k+3)
+		n := scanState(t, m)
+		if n == m {
+			sd.failf(l, k, "transition has no target state")
+			return
+		}
+		if strings.HasPrefix(t[n:], ":::") {
+			n = scanID(t, n+3)
+		}
+		if tail := strings.TrimSpace(t[n:]); tail != "" && tail[0] != ':' {
+			sd.failf(l, n, "unexpected %q after the transition", tail)
+			return
+		}
+		sd.defineState(from)
+		sd.defineState(t[m:n])
+	default:
+		sd.failf(l, k, "expected \"-->\" or \":\" after the state name, found %q", t[k:])
+	}
+}
+
+// defineState defines a state name, ignoring the [*] pseudo-state and
+// a trailing :::class.
+func (sd *stateDiagram) defineState(name string) {
+	name, _, _ = strings.Cut(name, ":::")
+	if name != "[*]" {
+		sd.define(name)
+	}
+}
+
+// scanState returns the end of the state name at byte i, [*] included.
+func scanState(t string, i int) int {
+	if strings.HasPrefix(t[i:], "[*]") {
+		return i + 3
+	}
+	return scanID(t, i)
+}
@@ -0,0 +1,219 @@
+package metrics
+
+import (
+	"fmt"
+	"math"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// Stat names an aggregate statistic over a metric's per-file values.
+type Stat string
+
+const (
+	// StatSum is the total over all files with a value.
+	StatSum Stat = "sum"
+	// StatMean is the arithmetic mean.
+	StatMean Stat = "mean"
+	// StatP50 is the median (nearest-rank).
+	StatP50 Stat = "p50"
+	// StatP90 is the 90th percentile (nearest-rank).
+	StatP90 Stat = "p90"
+	// StatMax is the largest value.
+	StatMax Stat = "max"
+)
+
+// Stats lists every aggregate statistic in output order.
+var Stats = []Stat{StatSum, StatMean, StatP50, StatP90, StatMax}
+
+// ParseStat parses a user-provided statistic name.
+func ParseStat(raw string) (Stat, error) {
+	name := Stat(strings.ToLower(strings.TrimSpace(raw)))
+	if name == "" {
+		return StatSum, nil
+	}
+	for _, s := range Stats {
+		if s == name {
+			return s, nil
+		}
+	}
+	return "", fmt.Errorf("unknown stat %q (supported: sum, mean, p50, p90, max)", raw)
+}
+
+// Summary holds the aggregate statistics of one metric over a group
+// of files. Count is the number of files with an available value;
+// unavailable values are left out of every statistic.
+type Summary struct {
+	Count int
+	Sum   float64
+	Mean  float64
+	P50   float64
+	P90   float64
+	Max   float64
+}
+
+// Value returns the selected statistic. It is unavailable when no
+// file in the group had an available value.
+func (s Summary) Value(stat Stat) Value {
+	if s.Count == 0 {
+		return UnavailableValue()
+	}
+	switch stat {
+	case StatSum:
+		return AvailableValue(s.Sum)
+	case StatMean:
+		return AvailableValue(s.Mean)
+	case StatP50:
+		return AvailableValue(s.P50)
+	case StatP90:
+		return AvailableValue(s.P90)
+	case StatMax:
+		return AvailableValue(s.Max)
+	default:
+		return UnavailableValue()
+	}
+}
+
+// Group holds aggregated metric summaries for one directory (scope
+// dir) or for the whole selection (scope workspace, path ".").
+type Group struct {
+	Path    string
+	Files   int
+	Metrics map[string]Summary
+}
+
+// Aggregate groups rows by scope and summarizes every metric in
+// defs. ScopeDir keys each row by its slash-separated parent
+// directory; ScopeWorkspace puts every row in one group. Groups are
+// returned sorted by path. ScopeFile is not an aggregation and
+// yields one group per row.
+func Aggregate(rows []Row, defs []Definition, scope Scope) []Group {
+	order := make([]string, 0)
+	members := make(map[string][]Row)
+	for _, row := range rows {
+		key := groupKey(row.Path, scope)
+		if _, ok := members[key]; !ok {
+			order = append(order, key)
+		}
+		members[key] = append(members[key], row)
+	}
+	sort.Strings(order)
+
+	groups := make([]Group, 0, len(order))
+	for _, key := range order {
+		group := Group{
+			Path:    key,
+			Files:   len(members[key]),
+			Metrics: make(map[string]Summary, len(defs)),
+		}
+		for _, def := range defs {
+			group.Metrics[def.Name] = summarize(members[key], def.Name)
+		}
+		groups = append(groups, group)
+	}
+	return groups
+}
+
+func groupKey(path string, scope Scope) string {
+	switch scope {
+	case ScopeDir:
+		return filepath.ToSlash(filepath.Dir(path))
+	case ScopeWorkspace:
+		return "."
+	default:
+		return path
+	}
+}
+
+func summarize(rows []Row, name string) Summary {
+	values := make([]float64, 0, len(rows))
+	for _, row := range rows {
+		if v := row.Metrics[name]; v.Available {
+			values = append(values, v.Number)
+		}
+	}
+	if len(values) == 0 {
+		return Summary{}
+	}
+	sort.Float64s(values)
+
+	sum := 0.0
+	for _, v := range values {
+		sum += v
+	}
+	return Summary{
+		Count: len(values),
+		Sum:   sum,
+		Mean:  sum / float64(len(values)),
+		P50:   percentile(values, 50),
+		P90:   percentile(values, 90),
+		Max:   values[len(values)-1],
+	}
+}
+
+// percentile returns the nearest-rank percentile of sorted values,
+// so the result is always one of the observed values.
+func percentile(sorted []float64, p float64) float64 {
+	rank := int(math.Ceil(p / 100 * float64(len(sorted))))
+	if rank < 1 {
+		rank = 1
+	}
+	return sorted[rank-1]
+}
+
+// GroupRows flattens groups into rows carrying one statistic per
+// metric, so aggregated output can reuse the row sort, limit, and
+// diff helpers.
+func GroupRows(groups []Group, stat Stat) []Row {
+	rows := make([]Row, 0, len(groups))
+	for _, g := range groups {
+		values := make(map[string]Value, len(g.Metrics))
+		for name, s := range g.Metrics {
+			values[name] = s.Value(stat)
+		}
+		rows = append(rows, Row{Path: g.Path, Metrics: values})
+	}
+	return rows
+}
+
+// StatDefinition returns def adjusted for rendering stat: a mean of
+// an integer metric is fractional, so it renders as a one-decimal
+// float. Every other statistic keeps the metric's own kind.
+func StatDefinition(def Definition, stat Stat) Definition {
+	if stat == StatMean && def.Kind == KindInteger {
+		def.Kind = KindFloat
+		def.Precision = 1
+	}
+	return def
+}
+
+// SortGroups sorts groups by one statistic of a metric, using the
+// same ordering and path tie-break as SortRows.
+func SortGroups(groups []Group, by Definition, stat Stat, order Order) {
+	sort.SliceStable(groups, func(i, j int) bool {
+		a := groups[i].Metrics[by.Name].Value(stat)
+		b := groups[j].Metrics[by.Name].Value(stat)
+		if a.Available != b.Available {
+			return a.Available
+		}
+		if a.Available && b.Available {
+			diff := a.Number - b.Number
+			if math.Abs(diff) > 1e-9 {
+				if order == OrderAsc {
+					return diff < 0
+				}
+				return diff > 0
+			}
+		}
+		return groups[i].Path < groups[j].Path
+	})
+}
+
+// LimitGroups returns at most top groups (if top > 0).
+func LimitGroups(groups []Group, top int) []Group {
+	if top <= 0 || top >= len(groups) {
+		return groups
+	}
+	return groups[:top]
+}
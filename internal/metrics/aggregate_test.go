@@ -0,0 +1,141 @@
+package metrics
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func bytesRows(values map[string]float64) []Row {
+	rows := make([]Row, 0, len(values))
+	for path, v := range values {
+		rows = append(rows, Row{Path: path, Metrics: map[string]Value{"bytes": AvailableValue(v)}})
+	}
+	return rows
+}
+
+func TestParseAggregateScope(t *testing.T) {
+	for raw, want := range map[string]Scope{
+		"": ScopeFile, "file": ScopeFile, "DIR": ScopeDir, " workspace ": ScopeWorkspace,
+	} {
+		got, err := ParseAggregateScope(raw)
+		require.NoError(t, err, raw)
+		assert.Equal(t, want, got, raw)
+	}
+	_, err := ParseAggregateScope("repo")
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "file, dir, workspace")
+}
+
+func TestParseScope_StillRejectsAggregateScopes(t *testing.T) {
+	_, err := ParseScope("dir")
+	require.Error(t, err)
+}
+
+func TestParseStat(t *testing.T) {
+	got, err := ParseStat("")
+	require.NoError(t, err)
+	assert.Equal(t, StatSum, got)
+
+	got, err = ParseStat("P90")
+	require.NoError(t, err)
+	assert.Equal(t, StatP90, got)
+
+	_, err = ParseStat("median")
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "unknown stat")
+}
+
+func TestAggregate_DirScopeGroupsByParent(t *testing.T) {
+	def, ok := LookupScope(ScopeFile, "bytes")
+	require.True(t, ok)
+	rows := bytesRows(map[string]float64{
+		"docs/a.md": 10, "docs/b.md": 30, "docs/c.md": 20, "README.md": 5,
+	})
+
+	groups := Aggregate(rows, []Definition{def}, ScopeDir)
+
+	require.Len(t, groups, 2)
+	assert.Equal(t, ".", groups[0].Path)
+	assert.Equal(t, 1, groups[0].Files)
+	assert.Equal(t, "docs", groups[1].Path)
+	assert.Equal(t, 3, groups[1].Files)
+	assert.Equal(t, Summary{Count: 3, Sum: 60, Mean: 20, P50: 20, P90: 30, Max: 30}, groups[1].Metrics["bytes"])
+}
+
+func TestAggregate_WorkspaceScopeIsOneGroup(t *testing.T) {
+	def, ok := LookupScope(ScopeFile, "bytes")
+	require.True(t, ok)
+	rows := bytesRows(map[string]float64{"a/x.md": 1, "b/y.md": 2})
+
+	groups := Aggregate(rows, []Definition{def}, ScopeWorkspace)
+
+	require.Len(t, groups, 1)
+	assert.Equal(t, ".", groups[0].Path)
+	assert.Equal(t, 2, groups[0].Files)
+	assert.InDelta(t, 3, groups[0].Metrics["bytes"].Sum, 1e-9)
+}
+
+func TestAggregate_SkipsUnavailableValues(t *testing.T) {
+	def, ok := LookupScope(ScopeFile, "readability")
+	require.True(t, ok)
+	rows := []Row{
+		{Path: "a.md", Metrics: map[string]Value{"readability": UnavailableValue()}},
+		{Path: "b.md", Metrics: map[string]Value{"readability": AvailableValue(8)}},
+	}
+
+	s := Aggregate(rows, []Definition{def}, ScopeWorkspace)[0].Metrics["readability"]
+
+	assert.Equal(t, 1, s.Count)
+	assert.InDelta(t, 8, s.Mean, 1e-9)
+}
+
+func TestSummaryValue_EmptyIsUnavailable(t *testing.T) {
+	for _, stat := range Stats {
+		assert.False(t, Summary{}.Value(stat).Available, stat)
+	}
+}
+
+func TestPercentile_NearestRank(t *testing.T) {
+	values := []float64{1, 2, 3, 4, 5, 6, 7, 8, 9, 10}
+	assert.InDelta(t, 5, percentile(values, 50), 1e-9)
+	assert.InDelta(t, 9, percentile(values, 90), 1e-9)
+	assert.InDelta(t, 1, percentile(values, 0), 1e-9)
+}
+
+func TestStatDefinition_MeanOfIntegerIsFloat(t *testing.T) {
+	def, ok := LookupScope(ScopeFile, "bytes")
+	require.True(t, ok)
+
+	mean := StatDefinition(def, StatMean)
+	assert.Equal(t, KindFloat, mean.Kind)
+	assert.Equal(t, 1, mean.Precision)
+	assert.Equal(t, KindInteger, StatDefinition(def, StatSum).Kind)
+}
+
+func TestSortGroupsAndLimit(t *testing.T) {
+	def, ok := LookupScope(ScopeFile, "bytes")
+	require.True(t, ok)
+	rows := bytesRows(map[string]float64{"a/x.md": 1, "b/y.md": 9, "c/z.md": 5})
+	groups := Aggregate(rows, []Definition{def}, ScopeDir)
+
+	SortGroups(groups, def, StatMax, OrderDesc)
+	groups = LimitGroups(groups, 2)
+
+	require.Len(t, groups, 2)
+	assert.Equal(t, "b", groups[0].Path)
+	assert.Equal(t, "c", groups[1].Path)
+}
+
+func TestGroupRows_CarriesSelectedStat(t *testing.T) {
+	def, ok := LookupScope(ScopeFile, "bytes")
+	require.True(t, ok)
+	groups := Aggregate(bytesRows(map[string]float64{"d/a.md": 2, "d/b.md": 4}), []Definition{def}, ScopeDir)
+
+	rows := GroupRows(groups, StatMean)
+
+	require.Len(t, rows, 1)
+	assert.Equal(t, "d", rows[0].Path)
+	assert.InDelta(t, 3, rows[0].Metrics["bytes"].Number, 1e-9)
+}
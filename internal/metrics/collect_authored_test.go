@@ -8,6 +8,9 @@ import (
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
+
+	// Registers <?include?> as a generated directive.
+	_ "github.com/jeduden/mdsmith/internal/rules/include"
 )
 
 // TestCollect_AuthoredMetrics verifies that a host file with an <?include?>
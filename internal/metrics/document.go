@@ -34,6 +34,9 @@ type Document struct {
 	sentenceCount      int
 	sentenceCountReady bool
 	sentenceCountErr   error
+
+	lang      string
+	langReady bool
 }
 
 // countSentencesFn is a package variable so tests can substitute a
@@ -168,3 +171,19 @@ func (d *Document) HeadingCount() (int, error) {
 	d.headingCountReady = true
 	return d.headingCount, nil
 }
+
+// Lang returns the document's language code from its front-matter
+// `lang:` key (see mdtext.NormalizeLang), or mdtext.DefaultLang when
+// the key is absent or names an unsupported language.
+func (d *Document) Lang() string {
+	if d.langReady {
+		return d.lang
+	}
+	d.lang = mdtext.DefaultLang
+	fm, _ := lint.StripFrontMatter(d.Source)
+	if lang, ok := mdtext.NormalizeLang(lint.ParseFrontMatterLang(fm)); ok {
+		d.lang = lang
+	}
+	d.langReady = true
+	return d.lang
+}
@@ -6,7 +6,7 @@ import (
 	"sort"
 	"strings"
 
-	"github.com/jeduden/mdsmith/internal/mdtext"
+	"github.com/jeduden/mdsmith/internal/readability"
 )
 
 var registry = []Definition{
@@ -112,7 +112,7 @@ var registry = []Definition{
 	{
 		ID:           "MET008",
 		Name:         "readability",
-		Description:  "Automated Readability Index (ARI) over the file's plain text. Higher means harder to read.",
+		Description:  "Grade-level readability of the file's plain text: ARI, or the Wiener Sachtextformel for German. Higher means harder to read.",
 		Scope:        ScopeFile,
 		Kind:         KindFloat,
 		Precision:    1,
@@ -130,7 +130,9 @@ var registry = []Definition{
 			if err != nil {
 				return UnavailableValue(), err
 			}
-			return AvailableValue(mdtext.ARI(text)), nil
+			lang := doc.Lang()
+			f := readability.Grade(lang)
+			return FormulaValue(f.Score(text, lang), f.ID), nil
 		},
 	},
 	{
@@ -174,6 +176,32 @@ var registry = []Definition{
 			return AvailableValue(float64(words) / float64(sentences)), nil
 		},
 	},
+	{
+		ID:           "MET011",
+		Name:         "reading-ease",
+		Description:  "Flesch-family reading ease (0-100) of the file's plain text, with the formula calibrated for its language. Higher means easier to read.",
+		Scope:        ScopeFile,
+		Kind:         KindFloat,
+		Precision:    1,
+		Default:      false,
+		DefaultOrder: OrderAsc,
+		Compute: func(doc *Document) (Value, error) {
+			words, err := doc.WordCount()
+			if err != nil {
+				return UnavailableValue(), err
+			}
+			lang := doc.Lang()
+			f, ok := readability.Ease(lang)
+			if words == 0 || !ok {
+				return UnavailableValue(), nil
+			}
+			text, err := doc.PlainText()
+			if err != nil {
+				return UnavailableValue(), err
+			}
+			return FormulaValue(f.Score(text, lang), f.ID), nil
+		},
+	},
 }
 
 // All returns all metrics sorted by ID.
@@ -121,3 +121,44 @@ func TestConciseness_DenseBeatsVerbose(t *testing.T) {
 		)
 	}
 }
+
+func TestReadabilityFormulas_FollowFrontMatterLang(t *testing.T) {
+	readability, ok := Lookup("MET008")
+	require.True(t, ok)
+	ease, ok := Lookup("MET011")
+	require.True(t, ok)
+
+	tests := []struct {
+		src      string
+		grade    string
+		easeID   string
+		wantLang string
+	}{
+		{"The cat sat on the mat.\n", "ari", "flesch", "en"},
+		{"---\nlang: de\n---\nDer Hund bellt laut.\n", "wiener-sachtextformel", "flesch-amstad", "de"},
+		{"---\nlang: es-MX\n---\nEl perro ladra fuerte.\n", "ari", "fernandez-huerta", "es"},
+		{"---\nlang: ja\n---\nThe cat sat.\n", "ari", "flesch", "en"},
+	}
+	for _, tt := range tests {
+		doc := NewDocument("test.md", []byte(tt.src))
+		require.Equal(t, tt.wantLang, doc.Lang(), tt.src)
+
+		v, err := readability.Compute(doc)
+		require.NoError(t, err)
+		require.True(t, v.Available)
+		require.Equal(t, tt.grade, v.Formula, tt.src)
+
+		v, err = ease.Compute(doc)
+		require.NoError(t, err)
+		require.True(t, v.Available)
+		require.Equal(t, tt.easeID, v.Formula, tt.src)
+	}
+}
+
+func TestReadingEase_UnavailableWithoutWords(t *testing.T) {
+	def, ok := Lookup("MET011")
+	require.True(t, ok)
+	v, err := def.Compute(NewDocument("empty.md", nil))
+	require.NoError(t, err)
+	require.False(t, v.Available)
+}
@@ -0,0 +1,225 @@
+package metrics
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"sort"
+	"time"
+)
+
+// SnapshotVersion is the record format written by NewSnapshot.
+// ParseSnapshot rejects any other version so a newer layout never
+// diffs silently against an older one.
+const SnapshotVersion = 1
+
+// SnapshotDir is the workspace-relative directory that
+// `mdsmith metrics snapshot` writes timestamped records into.
+const SnapshotDir = ".mdsmith/metrics"
+
+// Snapshot is a timestamped record of per-file metric values. It
+// is the unit `metrics diff` compares, whether read from disk or
+// from a git revision.
+type Snapshot struct {
+	Version int            `json:"version"`
+	Created time.Time      `json:"created"`
+	Metrics []string       `json:"metrics"`
+	Files   []SnapshotFile `json:"files"`
+}
+
+// SnapshotFile is one file's values inside a Snapshot. A nil value
+// records an unavailable metric.
+type SnapshotFile struct {
+	Path    string              `json:"path"`
+	Metrics map[string]*float64 `json:"metrics"`
+}
+
+// NewSnapshot records rows for defs at created (stored in UTC).
+// Values are rounded the same way JSONValue rounds them, so a
+// snapshot diffs against the numbers `metrics rank` prints.
+func NewSnapshot(rows []Row, defs []Definition, created time.Time) Snapshot {
+	names := make([]string, 0, len(defs))
+	for _, def := range defs {
+		names = append(names, def.Name)
+	}
+
+	files := make([]SnapshotFile, 0, len(rows))
+	for _, row := range rows {
+		values := make(map[string]*float64, len(defs))
+		for _, def := range defs {
+			values[def.Name] = snapshotValue(def, row.Metrics[def.Name])
+		}
+		files = append(files, SnapshotFile{Path: row.Path, Metrics: values})
+	}
+	sort.Slice(files, func(i, j int) bool { return files[i].Path < files[j].Path })
+
+	return Snapshot{
+		Version: SnapshotVersion,
+		Created: created.UTC().Truncate(time.Second),
+		Metrics: names,
+		Files:   files,
+	}
+}
+
+func snapshotValue(def Definition, v Value) *float64 {
+	switch n := JSONValue(def, v).(type) {
+	case int64:
+		f := float64(n)
+		return &f
+	case float64:
+		return &n
+	default:
+		return nil
+	}
+}
+
+// SnapshotFileName returns the timestamped base name a snapshot
+// created at t is written under, e.g. 20261018T093000Z.json. The
+// layout sorts lexically in creation order.
+func SnapshotFileName(t time.Time) string {
+	return t.UTC().Format("20060102T150405Z") + ".json"
+}
+
+// ParseSnapshot decodes a snapshot record and checks its version.
+func ParseSnapshot(data []byte) (Snapshot, error) {
+	var s Snapshot
+	if err := json.Unmarshal(data, &s); err != nil {
+		return Snapshot{}, fmt.Errorf("parsing snapshot: %w", err)
+	}
+	if s.Version != SnapshotVersion {
+		return Snapshot{}, fmt.Errorf(
+			"unsupported snapshot version %d (supported: %d)", s.Version, SnapshotVersion)
+	}
+	return s, nil
+}
+
+// Rows converts the snapshot back into metric rows so it can be
+// aggregated and compared like freshly collected values.
+func (s Snapshot) Rows() []Row {
+	rows := make([]Row, 0, len(s.Files))
+	for _, f := range s.Files {
+		values := make(map[string]Value, len(f.Metrics))
+		for name, v := range f.Metrics {
+			if v == nil {
+				values[name] = UnavailableValue()
+				continue
+			}
+			values[name] = AvailableValue(*v)
+		}
+		rows = append(rows, Row{Path: f.Path, Metrics: values})
+	}
+	return rows
+}
+
+// Definitions resolves the snapshot's recorded metric names against
+// the registry. Names the running binary does not know (recorded by
+// a newer mdsmith) are skipped.
+func (s Snapshot) Definitions() []Definition {
+	defs := make([]Definition, 0, len(s.Metrics))
+	for _, name := range s.Metrics {
+		if def, ok := LookupScope(ScopeFile, name); ok {
+			defs = append(defs, def)
+		}
+	}
+	return defs
+}
+
+// Worsened reports whether moving from old to cur makes the metric
+// worse. The direction comes from DefaultOrder: rank lists the
+// worst files first, so a desc metric (bytes, readability) worsens
+// as it grows and an asc metric (conciseness) worsens as it falls.
+func (d Definition) Worsened(old, cur float64) bool {
+	if d.DefaultOrder == OrderAsc {
+		return cur < old
+	}
+	return cur > old
+}
+
+// ExceedsThreshold reports whether the move from old to cur is a
+// regression larger than threshold percent of old. A worsening from
+// zero counts as an unbounded change and always exceeds it.
+func (d Definition) ExceedsThreshold(old, cur, threshold float64) bool {
+	if !d.Worsened(old, cur) {
+		return false
+	}
+	if old == 0 {
+		return true
+	}
+	return math.Abs(cur-old)/math.Abs(old)*100 > threshold
+}
+
+// Change is one metric's movement for one path between two sets
+// of rows.
+type Change struct {
+	Path       string
+	Metric     Definition
+	Old        Value
+	New        Value
+	Regression bool
+}
+
+// Delta returns New minus Old, or an unavailable value when either
+// side is missing.
+func (c Change) Delta() Value {
+	if !c.Old.Available || !c.New.Available {
+		return UnavailableValue()
+	}
+	return AvailableValue(c.New.Number - c.Old.Number)
+}
+
+// Compare lists every metric value that differs between old and
+// cur, keyed by row path, sorted by path then metric ID. A path
+// present on one side only yields changes with an unavailable
+// other side; those are never regressions. A change is a
+// regression when the metric worsens by more than threshold
+// percent.
+func Compare(old, cur []Row, defs []Definition, threshold float64) []Change {
+	oldByPath := rowsByPath(old)
+	curByPath := rowsByPath(cur)
+
+	paths := make([]string, 0, len(oldByPath)+len(curByPath))
+	for p := range oldByPath {
+		paths = append(paths, p)
+	}
+	for p := range curByPath {
+		if _, ok := oldByPath[p]; !ok {
+			paths = append(paths, p)
+		}
+	}
+	sort.Strings(paths)
+
+	var changes []Change
+	for _, path := range paths {
+		for _, def := range defs {
+			a := oldByPath[path].Metrics[def.Name]
+			b := curByPath[path].Metrics[def.Name]
+			if sameValue(a, b) {
+				continue
+			}
+			changes = append(changes, Change{
+				Path:   path,
+				Metric: def,
+				Old:    a,
+				New:    b,
+				Regression: a.Available && b.Available &&
+					def.ExceedsThreshold(a.Number, b.Number, threshold),
+			})
+		}
+	}
+	return changes
+}
+
+func rowsByPath(rows []Row) map[string]Row {
+	m := make(map[string]Row, len(rows))
+	for _, r := range rows {
+		m[r.Path] = r
+	}
+	return m
+}
+
+func sameValue(a, b Value) bool {
+	if a.Available != b.Available {
+		return false
+	}
+	return !a.Available || math.Abs(a.Number-b.Number) <= 1e-9
+}
@@ -0,0 +1,113 @@
+package metrics
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewSnapshot_RoundTrips(t *testing.T) {
+	defs, err := Resolve(ScopeFile, []string{"bytes", "readability"})
+	require.NoError(t, err)
+	rows := []Row{
+		{Path: "b.md", Metrics: map[string]Value{"bytes": AvailableValue(12), "readability": AvailableValue(7.26)}},
+		{Path: "a.md", Metrics: map[string]Value{"bytes": AvailableValue(3), "readability": UnavailableValue()}},
+	}
+	created := time.Date(2026, 10, 18, 9, 30, 0, 500, time.FixedZone("CET", 3600))
+
+	snap := NewSnapshot(rows, defs, created)
+	data, err := json.Marshal(snap)
+	require.NoError(t, err)
+	parsed, err := ParseSnapshot(data)
+	require.NoError(t, err)
+
+	assert.Equal(t, time.Date(2026, 10, 18, 8, 30, 0, 0, time.UTC), parsed.Created)
+	assert.Equal(t, []string{"bytes", "readability"}, parsed.Metrics)
+	require.Len(t, parsed.Files, 2)
+	assert.Equal(t, "a.md", parsed.Files[0].Path)
+	assert.Nil(t, parsed.Files[0].Metrics["readability"])
+
+	back := parsed.Rows()
+	assert.InDelta(t, 7.3, back[1].Metrics["readability"].Number, 1e-9, "rounded like JSONValue")
+	assert.False(t, back[0].Metrics["readability"].Available)
+	assert.Len(t, parsed.Definitions(), 2)
+}
+
+func TestSnapshotFileName(t *testing.T) {
+	got := SnapshotFileName(time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC))
+	assert.Equal(t, "20260102T030405Z.json", got)
+}
+
+func TestParseSnapshot_Errors(t *testing.T) {
+	_, err := ParseSnapshot([]byte("{"))
+	require.Error(t, err)
+
+	_, err = ParseSnapshot([]byte(`{"version": 99}`))
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "unsupported snapshot version 99")
+}
+
+func TestSnapshotDefinitions_SkipsUnknownNames(t *testing.T) {
+	snap := Snapshot{Metrics: []string{"bytes", "from-the-future"}}
+	defs := snap.Definitions()
+	require.Len(t, defs, 1)
+	assert.Equal(t, "bytes", defs[0].Name)
+}
+
+func TestDefinitionWorsened_FollowsDefaultOrder(t *testing.T) {
+	bytesDef, _ := LookupScope(ScopeFile, "bytes")
+	conciseness, _ := LookupScope(ScopeFile, "conciseness")
+
+	assert.True(t, bytesDef.Worsened(10, 11))
+	assert.False(t, bytesDef.Worsened(11, 10))
+	assert.True(t, conciseness.Worsened(80, 70))
+	assert.False(t, conciseness.Worsened(70, 80))
+}
+
+func TestDefinitionExceedsThreshold(t *testing.T) {
+	bytesDef, _ := LookupScope(ScopeFile, "bytes")
+
+	assert.False(t, bytesDef.ExceedsThreshold(100, 105, 5), "exactly at threshold")
+	assert.True(t, bytesDef.ExceedsThreshold(100, 106, 5))
+	assert.False(t, bytesDef.ExceedsThreshold(100, 50, 5), "improvement")
+	assert.True(t, bytesDef.ExceedsThreshold(0, 1, 1000), "from zero")
+}
+
+func TestCompare(t *testing.T) {
+	bytesDef, _ := LookupScope(ScopeFile, "bytes")
+	defs := []Definition{bytesDef}
+	old := []Row{
+		{Path: "same.md", Metrics: map[string]Value{"bytes": AvailableValue(10)}},
+		{Path: "grew.md", Metrics: map[string]Value{"bytes": AvailableValue(100)}},
+		{Path: "gone.md", Metrics: map[string]Value{"bytes": AvailableValue(1)}},
+	}
+	cur := []Row{
+		{Path: "same.md", Metrics: map[string]Value{"bytes": AvailableValue(10)}},
+		{Path: "grew.md", Metrics: map[string]Value{"bytes": AvailableValue(120)}},
+		{Path: "new.md", Metrics: map[string]Value{"bytes": AvailableValue(7)}},
+	}
+
+	changes := Compare(old, cur, defs, 5)
+
+	require.Len(t, changes, 3)
+	assert.Equal(t, "gone.md", changes[0].Path)
+	assert.False(t, changes[0].New.Available)
+	assert.False(t, changes[0].Regression)
+	assert.False(t, changes[0].Delta().Available)
+
+	assert.Equal(t, "grew.md", changes[1].Path)
+	assert.True(t, changes[1].Regression)
+	assert.InDelta(t, 20, changes[1].Delta().Number, 1e-9)
+
+	assert.Equal(t, "new.md", changes[2].Path)
+	assert.False(t, changes[2].Regression)
+}
+
+func TestCompare_NoChangesIsNil(t *testing.T) {
+	bytesDef, _ := LookupScope(ScopeFile, "bytes")
+	rows := []Row{{Path: "a.md", Metrics: map[string]Value{"bytes": AvailableValue(1)}}}
+	assert.Nil(t, Compare(rows, rows, []Definition{bytesDef}, 0))
+}
@@ -11,6 +11,11 @@ type Scope string
 const (
 	// ScopeFile indicates a file-level metric.
 	ScopeFile Scope = "file"
+	// ScopeDir aggregates file-level values per parent directory.
+	ScopeDir Scope = "dir"
+	// ScopeWorkspace aggregates file-level values over every
+	// selected file.
+	ScopeWorkspace Scope = "workspace"
 )
 
 // ParseScope parses a user-provided scope value.
@@ -23,6 +28,23 @@ func ParseScope(raw string) (Scope, error) {
 	}
 }
 
+// ParseAggregateScope parses the scope of a report built from
+// file-level values: file (one row per file), dir, or workspace.
+// Metric definitions stay file-scoped; dir and workspace only
+// change how their values are grouped.
+func ParseAggregateScope(raw string) (Scope, error) {
+	switch strings.ToLower(strings.TrimSpace(raw)) {
+	case "", string(ScopeFile):
+		return ScopeFile, nil
+	case string(ScopeDir):
+		return ScopeDir, nil
+	case string(ScopeWorkspace):
+		return ScopeWorkspace, nil
+	default:
+		return "", fmt.Errorf("unknown scope %q (supported: file, dir, workspace)", raw)
+	}
+}
+
 // Order defines metric sort order.
 type Order string
 
@@ -59,6 +81,9 @@ const (
 type Value struct {
 	Number    float64
 	Available bool
+	// Formula names the formula that produced Number when a metric
+	// picks one per file (MET008, MET011); "" otherwise.
+	Formula string
 }
 
 // AvailableValue constructs an available metric value.
@@ -69,6 +94,16 @@ func AvailableValue(n float64) Value {
 	}
 }
 
+// FormulaValue constructs an available metric value computed by the
+// named formula.
+func FormulaValue(n float64, formula string) Value {
+	return Value{
+		Number:    n,
+		Available: true,
+		Formula:   formula,
+	}
+}
+
 // UnavailableValue constructs an unavailable metric value.
 func UnavailableValue() Value {
 	return Value{}
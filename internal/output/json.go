@@ -35,9 +35,13 @@ type jsonDiagnostic struct {
 	// unchanged on the wire. The rule-doc URL is not emitted here — it
 	// is derivable from the `rule` field and is an editor (LSP) concern.
 	RelatedLocations []jsonRelatedLocation `json:"related_locations,omitempty"`
+	// Suggestions mirrors lint.Diagnostic's replacement texts (MDS076
+	// spellings). omitempty for the same wire-stability reason.
+	Suggestions []string `json:"suggestions,omitempty"`
 
 	Line            int  `json:"line"`
 	Column          int  `json:"column"`
+	EndColumn       int  `json:"end_column,omitempty"`
 	SourceStartLine int  `json:"source_start_line,omitempty"`
 	Deprecated      bool `json:"deprecated,omitempty"`
 }
@@ -81,6 +85,8 @@ func (f *JSONFormatter) Format(w io.Writer, diagnostics []lint.Diagnostic) error
 			Deprecated:       d.Deprecated,
 			ReplacedBy:       d.ReplacedBy,
 			RelatedLocations: relatedToJSON(d.RelatedLocations),
+			Suggestions:      d.Suggestions,
+			EndColumn:        d.EndColumn,
 		})
 	}
 	enc := json.NewEncoder(w)
@@ -118,6 +118,24 @@ func MaskBodyTokens(text string, tokens []string) string {
 	return text
 }
 
+// BodyTokenSpans returns the byte range [start, end) of each substring
+// token (var-token, apm-input-token) in text, in no particular order.
+// Whole-text tokens and cue-frontmatter have no spans.
+func BodyTokenSpans(text string, tokens []string) [][]int {
+	var spans [][]int
+	for _, tok := range tokens {
+		switch tok {
+		case VarToken:
+			spans = append(spans, fieldinterp.FieldSpans(text)...)
+		case APMInputToken:
+			if strings.Contains(text, apmInputPrefix) {
+				spans = append(spans, apmInputRe.FindAllStringIndex(text, -1)...)
+			}
+		}
+	}
+	return spans
+}
+
 // IsAllBodyTokens reports whether text (trimmed) consists only of
 // placeholder token patterns, with no other content. Unlike MaskBodyTokens,
 // this strips placeholder patterns to empty rather than replacing with
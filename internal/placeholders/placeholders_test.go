@@ -352,3 +352,16 @@ func TestAPMInputToken_IsAllBodyTokens(t *testing.T) {
 	assert.True(t, placeholders.IsAllBodyTokens("${input:a} ${input:b}", tok))
 	assert.False(t, placeholders.IsAllBodyTokens("${input:a} extra", tok))
 }
+
+func TestBodyTokenSpans(t *testing.T) {
+	text := `{"id": {id}, "q": "${input:q}", "lit": {{x}}}`
+	spans := placeholders.BodyTokenSpans(text, []string{
+		placeholders.VarToken, placeholders.APMInputToken, placeholders.HeadingQuestion,
+	})
+	var got []string
+	for _, s := range spans {
+		got = append(got, text[s[0]:s[1]])
+	}
+	assert.ElementsMatch(t, []string{"{id}", "${input:q}"}, got)
+	assert.Empty(t, placeholders.BodyTokenSpans(text, nil))
+}
@@ -3,9 +3,13 @@
 // (https://github.com/neurosnap/sentences). It vendors only what
 // MDS024 needs: Storage, Token, WordTokenizer, TokenGrouper,
 // OrthoContext, DefaultSentenceTokenizer, and the English
-// supervised abbreviations. The non-English language data and
-// IsNonPunct (no call site in upstream's English pipeline, per
-// plan 187) are not vendored.
+// supervised abbreviations. IsNonPunct (no call site in upstream's
+// English pipeline, per plan 187) is not vendored.
+//
+// The trained German, Spanish, French, Italian, and Dutch models
+// from upstream's data/ directory are vendored gzipped under data/
+// and loaded by NewLanguage. They run through the same annotators
+// as English. The js/wasm build leaves them out (lang_wasm.go).
 //
 // CJK terminal punctuation is supported at the same level upstream's
 // English pipeline supports it: full-width `。 ； ！ ？` are word
@@ -56,10 +60,8 @@
 // has no extension so mdsmith's content rules do not lint the
 // verbatim license text.)
 //
-// Multilingual loaders and IsNonPunct are not vendored. The English
-// pipeline never calls IsNonPunct (plan 187 records the negative)
-// and the non-English language data is unrelated to mdsmith's
-// English Markdown corpus. CJK terminal punctuation IS supported
+// IsNonPunct is not vendored: the English pipeline never calls it
+// (plan 187 records the negative). CJK terminal punctuation IS supported
 // in the word tokenizer so non-ASCII paragraphs flowing through
 // MDS024 segment the same way upstream does — exercised by the
 // equivalence harness's CJK paragraphs.
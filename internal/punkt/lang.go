@@ -0,0 +1,67 @@
+package punkt
+
+import (
+	"bytes"
+	"compress/gzip"
+	"fmt"
+	"io"
+)
+
+// languageData maps an ISO 639-1 code to the base name of its
+// trained model under data/. English is not listed: it ships inside
+// github.com/neurosnap/sentences/data and loads through NewEnglish.
+var languageData = map[string]string{
+	"de": "german",
+	"es": "spanish",
+	"fr": "french",
+	"it": "italian",
+	"nl": "dutch",
+}
+
+// Languages lists the ISO 639-1 codes this build can segment with a
+// trained model, English first. The js/wasm build lists English
+// only: the non-English models are not embedded there.
+func Languages() []string {
+	if !languageDataEmbedded {
+		return []string{"en"}
+	}
+	return []string{"en", "de", "es", "fr", "it", "nl"}
+}
+
+// NewLanguage constructs a Tokenizer over the trained model for the
+// ISO 639-1 code lang. ok is false when this build has no model for
+// lang; callers fall back to English. The non-English models run
+// through the same three annotators as English — upstream applies
+// no language-specific supervised abbreviations to them either.
+func NewLanguage(lang string) (t *Tokenizer, ok bool, err error) {
+	if lang == "en" {
+		return NewEnglish(), true, nil
+	}
+	name, ok := languageData[lang]
+	if !ok {
+		return nil, false, nil
+	}
+	raw, ok := readLanguageData(name)
+	if !ok {
+		return nil, false, nil
+	}
+	storage, err := loadCompressedTraining(raw)
+	if err != nil {
+		return nil, false, fmt.Errorf("punkt: loading %s training data: %w", name, err)
+	}
+	return New(storage), true, nil
+}
+
+// loadCompressedTraining gunzips raw and parses it as upstream's
+// training JSON.
+func loadCompressedTraining(raw []byte) (*Storage, error) {
+	zr, err := gzip.NewReader(bytes.NewReader(raw))
+	if err != nil {
+		return nil, err
+	}
+	data, err := io.ReadAll(zr)
+	if err != nil {
+		return nil, err
+	}
+	return LoadTraining(data)
+}
@@ -0,0 +1,20 @@
+//go:build !(js && wasm)
+
+package punkt
+
+import "embed"
+
+// languageFS holds the gzipped non-English training models, copied
+// unchanged from github.com/neurosnap/sentences@v1.1.2 data/ (see
+// UPSTREAM_LICENSE). They are left out of the js/wasm build, where
+// they would add about 1 MB to the gzip transfer size.
+//
+//go:embed data/*.json.gz
+var languageFS embed.FS
+
+const languageDataEmbedded = true
+
+func readLanguageData(name string) ([]byte, bool) {
+	raw, err := languageFS.ReadFile("data/" + name + ".json.gz")
+	return raw, err == nil
+}
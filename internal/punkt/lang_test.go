@@ -0,0 +1,72 @@
+package punkt
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func sentenceTexts(sents []Sentence) []string {
+	out := make([]string, 0, len(sents))
+	for _, s := range sents {
+		out = append(out, s.Text)
+	}
+	return out
+}
+
+func TestNewLanguage_GermanAbbreviations(t *testing.T) {
+	de, ok, err := NewLanguage("de")
+	require.NoError(t, err)
+	require.True(t, ok)
+
+	text := "Die Werte steigen, vgl. Tabelle drei. Das gilt bzw. Fall zwei auch."
+	assert.Equal(t, []string{
+		"Die Werte steigen, vgl. Tabelle drei.",
+		" Das gilt bzw. Fall zwei auch.",
+	}, sentenceTexts(de.Tokenize(text)))
+
+	// The English model does not know "vgl" and "bzw" and breaks
+	// after each.
+	assert.Greater(t, len(NewEnglish().Tokenize(text)), 2)
+}
+
+func TestNewLanguage_SpanishAbbreviations(t *testing.T) {
+	es, ok, err := NewLanguage("es")
+	require.NoError(t, err)
+	require.True(t, ok)
+
+	text := "Vive en la Avda. Central de Madrid. Trabaja con el Sr. Gómez."
+	assert.Equal(t, []string{
+		"Vive en la Avda. Central de Madrid.",
+		" Trabaja con el Sr. Gómez.",
+	}, sentenceTexts(es.Tokenize(text)))
+}
+
+func TestNewLanguage_English(t *testing.T) {
+	en, ok, err := NewLanguage("en")
+	require.NoError(t, err)
+	require.True(t, ok)
+	assert.Len(t, en.Tokenize("Dr. Smith came. He left."), 2)
+}
+
+func TestNewLanguage_Unknown(t *testing.T) {
+	tok, ok, err := NewLanguage("xx")
+	require.NoError(t, err)
+	assert.False(t, ok)
+	assert.Nil(t, tok)
+}
+
+func TestNewLanguage_EveryListedLanguageLoads(t *testing.T) {
+	for _, lang := range Languages() {
+		tok, ok, err := NewLanguage(lang)
+		require.NoError(t, err, lang)
+		require.True(t, ok, lang)
+		assert.NotEmpty(t, tok.Storage.OrthoContext, lang)
+	}
+}
+
+func TestLoadCompressedTraining_Malformed(t *testing.T) {
+	_, err := loadCompressedTraining([]byte("not gzip"))
+	assert.Error(t, err)
+}
@@ -0,0 +1,9 @@
+//go:build js && wasm
+
+package punkt
+
+// The js/wasm build does not embed the non-English models; every
+// language segments with the English model there.
+const languageDataEmbedded = false
+
+func readLanguageData(string) ([]byte, bool) { return nil, false }
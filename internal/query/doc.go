@@ -0,0 +1,162 @@
+package query
+
+import (
+	"regexp"
+	"strings"
+
+	"github.com/jeduden/mdsmith/internal/archetype/gensection"
+	"github.com/jeduden/mdsmith/internal/lint"
+	"github.com/jeduden/mdsmith/internal/mdtext"
+	"github.com/jeduden/mdsmith/internal/metrics"
+	"github.com/jeduden/mdsmith/pkg/goldmark/ast"
+)
+
+// DocKey is the virtual front-matter key that carries a file's
+// document facts. An expression that names it, such as
+// `$doc: metrics: words: >2000`, matches on content as well as on
+// front matter.
+const DocKey = "$doc"
+
+// taskMarkerRE matches a leading GFM task-list marker, as the goldmark
+// task-list extension does. The lint parser has no task-list inline
+// parser, so markers reach the AST as literal text.
+var taskMarkerRE = regexp.MustCompile(`^\[([\sxX])\]\s*`)
+
+// WithDoc returns a copy of fm with the document facts of source under
+// DocKey. A nil fm, a file without front matter, yields a map holding
+// only the facts. A front-matter key named DocKey is shadowed.
+func WithDoc(fm map[string]any, path string, source []byte) map[string]any {
+	out := make(map[string]any, len(fm)+1)
+	for k, v := range fm {
+		out[k] = v
+	}
+	out[DocKey] = Doc(path, source)
+	return out
+}
+
+// Doc returns the document facts of the Markdown source at path:
+//
+//   - headings: every heading's text, in document order
+//   - sections: per heading text, its level and the words under it,
+//     subsections included; the first heading with a text wins
+//   - links: per link destination as written, how many links use it
+//   - languages: per fenced code block language, how many blocks use it
+//   - tasks: total and done task-list items, and their ratio when
+//     there is at least one
+//   - metrics: every file metric `mdsmith metrics` reports, by name;
+//     unavailable metrics are left out
+//
+// Structure covers the whole body, generated sections included, as a
+// reader sees it. Metrics skip generated sections, as `mdsmith
+// metrics` does.
+func Doc(path string, source []byte) map[string]any {
+	f, err := lint.NewFileFromSource(path, source, true)
+	doc := map[string]any{
+		"headings":  []any{},
+		"sections":  map[string]any{},
+		"links":     map[string]any{},
+		"languages": map[string]any{},
+		"tasks":     map[string]any{"total": 0, "done": 0},
+		"metrics":   docMetrics(path, source),
+	}
+	if err != nil || f.AST == nil {
+		return doc
+	}
+	doc["headings"], doc["sections"] = docSections(f)
+	doc["links"], doc["languages"], doc["tasks"] = docContent(f)
+	return doc
+}
+
+// docSections collects heading texts and per-section word counts. A
+// section runs from its heading to the next top-level heading of the
+// same or a higher level.
+func docSections(f *lint.File) ([]any, map[string]any) {
+	headings := []any{}
+	sections := map[string]any{}
+	type open struct {
+		text  string
+		level int
+		words int
+	}
+	var stack []*open
+	closeTo := func(level int) {
+		for len(stack) > 0 && stack[len(stack)-1].level >= level {
+			s := stack[len(stack)-1]
+			stack = stack[:len(stack)-1]
+			if _, seen := sections[s.text]; !seen {
+				sections[s.text] = map[string]any{"level": s.level, "words": s.words}
+			}
+		}
+	}
+	for n := f.AST.FirstChild(); n != nil; n = n.NextSibling() {
+		if h, ok := n.(*ast.Heading); ok {
+			text := mdtext.ExtractPlainText(h, f.Source)
+			headings = append(headings, text)
+			closeTo(h.Level)
+			stack = append(stack, &open{text: text, level: h.Level})
+			continue
+		}
+		words := mdtext.CountWordsInNode(n, f.Source)
+		for _, s := range stack {
+			s.words += words
+		}
+	}
+	closeTo(0)
+	return headings, sections
+}
+
+// docContent counts links per destination, code blocks per language,
+// and task-list items in one walk.
+func docContent(f *lint.File) (links, languages, tasks map[string]any) {
+	links, languages = map[string]any{}, map[string]any{}
+	count := func(m map[string]any, key string) {
+		n, _ := m[key].(int)
+		m[key] = n + 1
+	}
+	total, done := 0, 0
+	_ = ast.Walk(f.AST, func(n ast.Node, entering bool) (ast.WalkStatus, error) {
+		if !entering {
+			return ast.WalkContinue, nil
+		}
+		switch n := n.(type) {
+		case *ast.Link:
+			count(links, string(n.Destination))
+		case *ast.AutoLink:
+			count(links, string(n.URL(f.Source)))
+		case *ast.FencedCodeBlock:
+			if lang := string(n.Language(f.Source)); lang != "" {
+				count(languages, lang)
+			}
+		case *ast.ListItem:
+			if first := n.FirstChild(); first != nil {
+				if m := taskMarkerRE.FindStringSubmatch(mdtext.ExtractPlainText(first, f.Source)); m != nil {
+					total++
+					if strings.TrimSpace(m[1]) != "" {
+						done++
+					}
+				}
+			}
+		}
+		return ast.WalkContinue, nil
+	})
+	tasks = map[string]any{"total": total, "done": done}
+	if total > 0 {
+		tasks["ratio"] = float64(done) / float64(total)
+	}
+	return links, languages, tasks
+}
+
+func docMetrics(path string, source []byte) map[string]any {
+	out := map[string]any{}
+	doc := metrics.NewDocument(path, gensection.AuthoredSource(source))
+	for _, def := range metrics.ForScope(metrics.ScopeFile) {
+		v, err := def.Compute(doc)
+		if err != nil {
+			continue
+		}
+		if jv := metrics.JSONValue(def, v); jv != nil {
+			out[def.Name] = jv
+		}
+	}
+	return out
+}
@@ -0,0 +1,78 @@
+package query
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const docSource = "---\nstatus: draft\n---\n# Guide\n\nIntro words here.\n\n" +
+	"## Usage\n\nSee [a](a.md), [again](a.md), and <https://example.com>.\n\n" +
+	"- [x] write\n- [ ] review\n- plain item\n\n" +
+	"```go\nx := 1\n```\n\n### Details\n\nfour more words here\n\n" +
+	"## Usage\n\nA second usage section.\n"
+
+func TestDoc(t *testing.T) {
+	doc := Doc("guide.md", []byte(docSource))
+	assert.Equal(t, []any{"Guide", "Usage", "Details", "Usage"}, doc["headings"])
+	sections := doc["sections"].(map[string]any)
+	assert.Equal(t, map[string]any{"level": 3, "words": 4}, sections["Details"])
+	// The first Usage wins; it holds its subsection's words.
+	assert.Equal(t, 2, sections["Usage"].(map[string]any)["level"])
+	assert.Greater(t, sections["Usage"].(map[string]any)["words"], 4)
+	assert.Equal(t, map[string]any{"a.md": 2, "https://example.com": 1}, doc["links"])
+	assert.Equal(t, map[string]any{"go": 1}, doc["languages"])
+	assert.Equal(t, map[string]any{"total": 2, "done": 1, "ratio": 0.5}, doc["tasks"])
+	assert.Contains(t, doc["metrics"], "words")
+}
+
+func TestDoc_NoTasksHasNoRatio(t *testing.T) {
+	doc := Doc("a.md", []byte("# A\n\nText.\n"))
+	assert.Equal(t, map[string]any{"total": 0, "done": 0}, doc["tasks"])
+}
+
+func TestMatch_Doc(t *testing.T) {
+	fm := WithDoc(map[string]any{"status": "draft"}, "guide.md", []byte(docSource))
+	cases := map[string]bool{
+		`status: "draft", $doc: metrics: words: >10`:   true,
+		`status: "draft", $doc: metrics: words: >2000`: false,
+		`$doc: sections: Details: _`:                   true,
+		`$doc: sections: Missing: _`:                   false,
+		`$doc: headings: [...!="Changelog"]`:           true,
+		`$doc: headings: [...!="Usage"]`:               false,
+		`$doc: languages: go: >0`:                      true,
+		`$doc: links: "a.md": >=2`:                     true,
+		`$doc: tasks: ratio: <1`:                       true,
+	}
+	for expr, want := range cases {
+		m, err := Compile(expr)
+		require.NoError(t, err, expr)
+		assert.True(t, m.UsesDoc(), expr)
+		assert.Equal(t, want, m.Match(fm), expr)
+	}
+
+	m, err := Compile(`status: "draft"`)
+	require.NoError(t, err)
+	assert.False(t, m.UsesDoc())
+}
+
+// TestCompile_DocSyntax pins the CUE form of a $doc query. The
+// `&&` and dotted-path form is not CUE and stays a parse error.
+func TestCompile_DocSyntax(t *testing.T) {
+	_, err := Compile(`status: "draft", $doc: metrics: words: >2000`)
+	require.NoError(t, err)
+	for _, expr := range []string{
+		`status: "draft" && $doc.metrics.words > 2000`,
+		`$doc.metrics.words: >2000`,
+	} {
+		_, err := Compile(expr)
+		assert.ErrorContains(t, err, "invalid CUE expression", expr)
+	}
+}
+
+func TestWithDoc_NoFrontMatter(t *testing.T) {
+	fm := WithDoc(nil, "a.md", []byte("# A\n"))
+	assert.Len(t, fm, 1)
+	assert.Contains(t, fm, DocKey)
+}
@@ -0,0 +1,176 @@
+package query
+
+import (
+	"cmp"
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/jeduden/mdsmith/internal/fieldinterp"
+)
+
+// Field is a front-matter field named by a CUE path, such as `title`,
+// `owner.name`, or `"review-date"`.
+type Field struct {
+	// Name labels the column: the path's unquoted segments joined by
+	// dots, so `"review-date"` is labelled review-date.
+	Name string
+	path []string
+}
+
+// ParseField parses a CUE path naming a front-matter field.
+func ParseField(expr string) (Field, error) {
+	path := fieldinterp.ParseCUEPath(expr)
+	if path == nil {
+		return Field{}, fmt.Errorf("invalid field path %q", expr)
+	}
+	return Field{Name: strings.Join(path, "."), path: path}, nil
+}
+
+// UsesDoc reports whether the field reads the document facts under
+// DocKey.
+func (f Field) UsesDoc() bool { return f.path[0] == DocKey }
+
+// Value returns the field's value in fm, or nil when any segment of
+// the path is missing. Lists and maps come back as-is.
+func (f Field) Value(fm map[string]any) any {
+	var cur any = fm
+	for _, seg := range f.path {
+		m, ok := cur.(map[string]any)
+		if !ok {
+			return nil
+		}
+		cur = m[seg]
+	}
+	return cur
+}
+
+// SortKey orders rows by one field, descending when Desc is set.
+type SortKey struct {
+	Field
+	Desc bool
+}
+
+// ParseSortKey parses a field path with an optional "-" prefix for
+// descending order.
+func ParseSortKey(expr string) (SortKey, error) {
+	desc := strings.HasPrefix(expr, "-")
+	f, err := ParseField(strings.TrimPrefix(expr, "-"))
+	if err != nil {
+		return SortKey{}, err
+	}
+	return SortKey{Field: f, Desc: desc}, nil
+}
+
+// Row is one matched file and its front matter.
+type Row struct {
+	Path        string
+	FrontMatter map[string]any
+}
+
+// SortRows orders rows by keys in turn, then by path. A file missing
+// a key sorts after every file that has it, in either direction.
+func SortRows(rows []Row, keys []SortKey) {
+	slices.SortStableFunc(rows, func(a, b Row) int {
+		for _, k := range keys {
+			av, bv := k.Value(a.FrontMatter), k.Value(b.FrontMatter)
+			switch {
+			case av == nil && bv == nil:
+				continue
+			case av == nil:
+				return 1
+			case bv == nil:
+				return -1
+			}
+			c := CompareValues(av, bv)
+			if k.Desc {
+				c = -c
+			}
+			if c != 0 {
+				return c
+			}
+		}
+		return strings.Compare(a.Path, b.Path)
+	})
+}
+
+// CompareValues orders two front-matter values: numbers numerically,
+// everything else by its text form.
+func CompareValues(a, b any) int {
+	if x, ok := number(a); ok {
+		if y, ok := number(b); ok {
+			return cmp.Compare(x, y)
+		}
+	}
+	return strings.Compare(Text(a), Text(b))
+}
+
+func number(v any) (float64, bool) {
+	switch x := v.(type) {
+	case int:
+		return float64(x), true
+	case int64:
+		return float64(x), true
+	case uint64:
+		return float64(x), true
+	case float64:
+		return x, true
+	}
+	return 0, false
+}
+
+// Text renders a value for a table cell. Scalars use their YAML text;
+// a list joins its scalar items with ", "; a map or nested list has no
+// text form and renders empty, as fieldinterp does.
+func Text(v any) string {
+	list, ok := v.([]any)
+	if !ok {
+		return fieldinterp.Stringify(v)
+	}
+	parts := make([]string, 0, len(list))
+	for _, item := range list {
+		parts = append(parts, fieldinterp.Stringify(item))
+	}
+	return strings.Join(parts, ", ")
+}
+
+// Count is the number of rows sharing one value of a field.
+type Count struct {
+	Value string `json:"value" yaml:"value"`
+	Count int    `json:"count" yaml:"count"`
+}
+
+// CountBy tallies rows by the text of f. A list value counts once per
+// distinct item, so counting `kinds` tallies each kind; a missing value
+// counts under "". Counts are ordered most frequent first, ties by
+// value.
+func CountBy(rows []Row, f Field) []Count {
+	tally := map[string]int{}
+	for _, r := range rows {
+		v := f.Value(r.FrontMatter)
+		list, ok := v.([]any)
+		if !ok {
+			tally[Text(v)]++
+			continue
+		}
+		seen := map[string]bool{}
+		for _, item := range list {
+			s := fieldinterp.Stringify(item)
+			if !seen[s] {
+				seen[s] = true
+				tally[s]++
+			}
+		}
+	}
+	out := make([]Count, 0, len(tally))
+	for v, n := range tally {
+		out = append(out, Count{Value: v, Count: n})
+	}
+	slices.SortFunc(out, func(a, b Count) int {
+		if c := cmp.Compare(b.Count, a.Count); c != 0 {
+			return c
+		}
+		return strings.Compare(a.Value, b.Value)
+	})
+	return out
+}
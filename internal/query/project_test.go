@@ -0,0 +1,70 @@
+package query
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseField(t *testing.T) {
+	f, err := ParseField(`owner."full-name"`)
+	require.NoError(t, err)
+	assert.Equal(t, "owner.full-name", f.Name)
+	assert.Equal(t, "Ada", f.Value(map[string]any{"owner": map[string]any{"full-name": "Ada"}}))
+	assert.Nil(t, f.Value(map[string]any{"owner": "Ada"}))
+	assert.Nil(t, f.Value(nil))
+
+	_, err = ParseField("full-name")
+	assert.EqualError(t, err, `invalid field path "full-name"`)
+}
+
+func TestSortRows(t *testing.T) {
+	rows := []Row{
+		{Path: "c.md", FrontMatter: map[string]any{"id": 10, "status": "done"}},
+		{Path: "a.md", FrontMatter: map[string]any{"status": "todo"}},
+		{Path: "b.md", FrontMatter: map[string]any{"id": 9, "status": "done"}},
+		{Path: "d.md", FrontMatter: map[string]any{"id": 10, "status": "todo"}},
+	}
+	paths := func() []string {
+		var out []string
+		for _, r := range rows {
+			out = append(out, r.Path)
+		}
+		return out
+	}
+	id, err := ParseSortKey("id")
+	require.NoError(t, err)
+	SortRows(rows, []SortKey{id})
+	// Numeric, not lexical; ties by path; missing last.
+	assert.Equal(t, []string{"b.md", "c.md", "d.md", "a.md"}, paths())
+
+	desc, err := ParseSortKey("-id")
+	require.NoError(t, err)
+	status, err := ParseSortKey("status")
+	require.NoError(t, err)
+	SortRows(rows, []SortKey{status, desc})
+	assert.Equal(t, []string{"c.md", "b.md", "d.md", "a.md"}, paths())
+}
+
+func TestText(t *testing.T) {
+	assert.Equal(t, "", Text(nil))
+	assert.Equal(t, "3", Text(3))
+	assert.Equal(t, "a, true", Text([]any{"a", true}))
+	assert.Equal(t, "", Text(map[string]any{"a": 1}))
+}
+
+func TestCountBy(t *testing.T) {
+	rows := []Row{
+		{Path: "a.md", FrontMatter: map[string]any{"status": "done", "kinds": []any{"plan", "adr"}}},
+		{Path: "b.md", FrontMatter: map[string]any{"status": "todo", "kinds": []any{"plan", "plan"}}},
+		{Path: "c.md", FrontMatter: map[string]any{"status": "done"}},
+	}
+	status, err := ParseField("status")
+	require.NoError(t, err)
+	assert.Equal(t, []Count{{"done", 2}, {"todo", 1}}, CountBy(rows, status))
+
+	kinds, err := ParseField("kinds")
+	require.NoError(t, err)
+	assert.Equal(t, []Count{{"plan", 2}, {"", 1}, {"adr", 1}}, CountBy(rows, kinds))
+}
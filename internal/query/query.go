@@ -11,6 +11,7 @@ import (
 type Matcher struct {
 	schema cuelite.Value
 	paths  []cuelite.Path // leaf field paths required by the expression
+	doc    bool           // the expression names DocKey
 }
 
 // Compile parses a CUE struct literal body and returns a
@@ -22,9 +23,19 @@ func Compile(expr string) (*Matcher, error) {
 		return nil, fmt.Errorf("invalid CUE expression: %w", err)
 	}
 	paths := collectPaths(val, nil)
-	return &Matcher{schema: val, paths: paths}, nil
+	m := &Matcher{schema: val, paths: paths}
+	for _, p := range paths {
+		if p.Segments()[0] == DocKey {
+			m.doc = true
+		}
+	}
+	return m, nil
 }
 
+// UsesDoc reports whether the expression constrains DocKey, so the
+// caller must add the document facts with WithDoc before Match.
+func (m *Matcher) UsesDoc() bool { return m.doc }
+
 // collectPaths recursively collects all leaf field paths from a CUE
 // value, so Match can verify they exist in front matter data before
 // unification. This handles nested struct expressions like
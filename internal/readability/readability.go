@@ -0,0 +1,208 @@
+// Package readability scores plain text with language-appropriate
+// readability formulas. It backs MDS023 paragraph-readability and
+// the readability and reading-ease metrics.
+//
+// Formulas come in two directions. A grade formula (ARI, Wiener
+// Sachtextformel) estimates a school grade: higher is harder. An
+// ease formula (the Flesch family) scores 0–100: higher is easier.
+// Callers compare against a maximum or a minimum accordingly.
+package readability
+
+import (
+	"strings"
+
+	"github.com/jeduden/mdsmith/internal/mdtext"
+)
+
+// Formula is one readability formula.
+type Formula struct {
+	// ID is the kebab-case name used in settings and output.
+	ID string
+	// Name is the human-readable name.
+	Name string
+	// Ease is true for a reading-ease score (higher is easier) and
+	// false for a grade level (higher is harder).
+	Ease bool
+	// Lang is the language the formula was calibrated for, or ""
+	// for a language-independent formula.
+	Lang string
+
+	score func(text, lang string) float64
+}
+
+// Score computes the formula over text. lang selects the syllable
+// rules for the text (see Measure). Text without words scores 0.
+func (f Formula) Score(text, lang string) float64 {
+	return f.score(text, lang)
+}
+
+// stats adapts a Stats-based formula to Score's signature.
+func stats(fn func(s Stats, asl, asw float64) float64) func(text, lang string) float64 {
+	return func(text, lang string) float64 {
+		s := Measure(text, lang)
+		if s.Words == 0 {
+			return 0
+		}
+		sentences := s.Sentences
+		if sentences == 0 {
+			sentences = 1
+		}
+		asl := float64(s.Words) / float64(sentences)
+		asw := float64(s.Syllables) / float64(s.Words)
+		return fn(s, asl, asw)
+	}
+}
+
+func percent(n, words int) float64 {
+	return 100 * float64(n) / float64(words)
+}
+
+// formulas lists every formula in the order docs show them.
+var formulas = []Formula{
+	{
+		ID:   "ari",
+		Name: "Automated Readability Index",
+		score: func(text, _ string) float64 {
+			return mdtext.ARI(text)
+		},
+	},
+	{
+		ID:   "wiener-sachtextformel",
+		Name: "Wiener Sachtextformel",
+		Lang: "de",
+		score: stats(func(s Stats, asl, _ float64) float64 {
+			return 0.1935*percent(s.Polysyllables, s.Words) + 0.1672*asl +
+				0.1297*percent(s.LongWords, s.Words) -
+				0.0327*percent(s.Monosyllables, s.Words) - 0.875
+		}),
+	},
+	{
+		ID:   "flesch",
+		Name: "Flesch Reading Ease",
+		Ease: true,
+		Lang: "en",
+		score: stats(func(_ Stats, asl, asw float64) float64 {
+			return 206.835 - 1.015*asl - 84.6*asw
+		}),
+	},
+	{
+		ID:   "flesch-amstad",
+		Name: "Flesch-Amstad",
+		Ease: true,
+		Lang: "de",
+		score: stats(func(_ Stats, asl, asw float64) float64 {
+			return 180 - asl - 58.5*asw
+		}),
+	},
+	{
+		// Fernández-Huerta with the sentence term as mean sentence
+		// length, as in Flesch's original; the often-copied
+		// "sentences per 100 words" variant inverts that term.
+		ID:   "fernandez-huerta",
+		Name: "Fernández-Huerta",
+		Ease: true,
+		Lang: "es",
+		score: stats(func(_ Stats, asl, asw float64) float64 {
+			return 206.84 - 60*asw - 1.02*asl
+		}),
+	},
+	{
+		ID:   "kandel-moles",
+		Name: "Kandel-Moles",
+		Ease: true,
+		Lang: "fr",
+		score: stats(func(_ Stats, asl, asw float64) float64 {
+			return 207 - 1.015*asl - 73.6*asw
+		}),
+	},
+	{
+		ID:   "flesch-vacca",
+		Name: "Flesch-Vacca",
+		Ease: true,
+		Lang: "it",
+		score: stats(func(_ Stats, asl, asw float64) float64 {
+			return 206 - asl - 65*asw
+		}),
+	},
+	{
+		ID:   "flesch-douma",
+		Name: "Flesch-Douma",
+		Ease: true,
+		Lang: "nl",
+		score: stats(func(_ Stats, asl, asw float64) float64 {
+			return 206.835 - 0.93*asl - 77*asw
+		}),
+	},
+}
+
+// Formulas returns every formula.
+func Formulas() []Formula {
+	out := make([]Formula, len(formulas))
+	copy(out, formulas)
+	return out
+}
+
+// IDs returns the formula IDs joined for an error message.
+func IDs() string {
+	ids := make([]string, 0, len(formulas))
+	for _, f := range formulas {
+		ids = append(ids, f.ID)
+	}
+	return strings.Join(ids, ", ")
+}
+
+// Lookup finds a formula by ID.
+func Lookup(id string) (Formula, bool) {
+	for _, f := range formulas {
+		if f.ID == id {
+			return f, true
+		}
+	}
+	return Formula{}, false
+}
+
+func mustLookup(id string) Formula {
+	f, ok := Lookup(id)
+	if !ok {
+		panic("readability: unknown formula " + id)
+	}
+	return f
+}
+
+// Grade returns the grade formula for lang: the Wiener
+// Sachtextformel for German, ARI otherwise.
+func Grade(lang string) Formula {
+	if lang == "de" {
+		return mustLookup("wiener-sachtextformel")
+	}
+	return mustLookup("ari")
+}
+
+// Ease returns the reading-ease formula calibrated for lang. ok is
+// false for a language without one.
+func Ease(lang string) (Formula, bool) {
+	if lang == "" {
+		lang = mdtext.DefaultLang
+	}
+	for _, f := range formulas {
+		if f.Ease && f.Lang == lang {
+			return f, true
+		}
+	}
+	return Formula{}, false
+}
+
+// Default returns the formula MDS023 applies to lang when none is
+// configured: ARI for English (its long-standing default), the
+// Wiener Sachtextformel for German, and the language's ease formula
+// elsewhere. A language without one falls back to ARI.
+func Default(lang string) Formula {
+	switch lang {
+	case "", mdtext.DefaultLang, "de":
+		return Grade(lang)
+	}
+	if f, ok := Ease(lang); ok {
+		return f
+	}
+	return Grade(lang)
+}
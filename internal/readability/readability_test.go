@@ -0,0 +1,142 @@
+package readability
+
+import (
+	"testing"
+
+	"github.com/jeduden/mdsmith/internal/mdtext"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func syllables(word, lang string) int {
+	return Measure(word, lang).Syllables
+}
+
+func TestSyllables_English(t *testing.T) {
+	for word, want := range map[string]int{
+		"cat": 1, "make": 1, "table": 2, "reading": 2,
+		"the": 1, "free": 1, "beautiful": 3, "42": 1,
+	} {
+		assert.Equal(t, want, syllables(word, "en"), word)
+	}
+}
+
+func TestSyllables_German(t *testing.T) {
+	for word, want := range map[string]int{
+		"Haus": 1, "Bäume": 2, "Einstellung": 3, "Verkehrsmittel": 4, "schön": 1,
+	} {
+		assert.Equal(t, want, syllables(word, "de"), word)
+	}
+}
+
+func TestSyllables_SpanishHiatus(t *testing.T) {
+	for word, want := range map[string]int{
+		"leer": 2, "caída": 3, "ciudad": 2, "aire": 2, "poeta": 3, "hoy": 1,
+	} {
+		assert.Equal(t, want, syllables(word, "es"), word)
+	}
+}
+
+func TestSyllables_FrenchSilentE(t *testing.T) {
+	assert.Equal(t, 1, syllables("grande", "fr"))
+	assert.Equal(t, 2, syllables("grande", "it"))
+}
+
+func TestMeasure_Counts(t *testing.T) {
+	s := Measure("Das Haus ist schön. Die Verkehrsmittel fahren.", "de")
+	assert.Equal(t, 7, s.Words)
+	assert.Equal(t, 2, s.Sentences)
+	assert.Equal(t, mdtext.CountCharacters("Das Haus ist schön. Die Verkehrsmittel fahren."), s.Characters)
+	assert.Equal(t, 1, s.LongWords)     // Verkehrsmittel
+	assert.Equal(t, 1, s.Polysyllables) // Verkehrsmittel
+	assert.Equal(t, 5, s.Monosyllables)
+}
+
+func TestMeasure_Empty(t *testing.T) {
+	assert.Equal(t, Stats{}, Measure("  ", "en"))
+	for _, f := range Formulas() {
+		assert.Zero(t, f.Score("", "en"), f.ID)
+	}
+}
+
+func TestMeasure_DoesNotAllocate(t *testing.T) {
+	text := "Die Einstellung der Verkehrsmittel ist schön. Sie fahren pünktlich."
+	allocs := testing.AllocsPerRun(100, func() { _ = Measure(text, "de") })
+	assert.Zero(t, allocs)
+}
+
+func TestARIMatchesMdtext(t *testing.T) {
+	text := "The cat sat on the mat. The implementation requires sophisticated understanding."
+	f, ok := Lookup("ari")
+	require.True(t, ok)
+	assert.InDelta(t, mdtext.ARI(text), f.Score(text, "en"), 1e-9)
+}
+
+func TestFormulas_KnownValues(t *testing.T) {
+	// 2 sentences, 8 words, 10 syllables: ASL 4, ASW 1.25.
+	text := "Der Hund bellt laut. Die Katze schläft ruhig."
+	s := Measure(text, "de")
+	require.Equal(t, 8, s.Words)
+	require.Equal(t, 2, s.Sentences)
+	require.Equal(t, 10, s.Syllables)
+
+	amstad, _ := Lookup("flesch-amstad")
+	assert.InDelta(t, 180-4-58.5*1.25, amstad.Score(text, "de"), 1e-9)
+
+	wstf, _ := Lookup("wiener-sachtextformel")
+	// 0 polysyllables, 1/8 long words (schläft), 6/8 monosyllables.
+	assert.InDelta(t, 0.1672*4+0.1297*12.5-0.0327*75-0.875, wstf.Score(text, "de"), 1e-9)
+
+	fh, _ := Lookup("fernandez-huerta")
+	es := "El perro ladra. El gato duerme tranquilo."
+	m := Measure(es, "es")
+	want := 206.84 - 60*float64(m.Syllables)/float64(m.Words) - 1.02*float64(m.Words)/float64(m.Sentences)
+	assert.InDelta(t, want, fh.Score(es, "es"), 1e-9)
+}
+
+func TestEaseFormulasRankHarderTextLower(t *testing.T) {
+	easy := "Der Hund bellt. Die Katze schläft. Das Kind spielt."
+	hard := "Die Implementierung verteilter Datenverarbeitungssysteme erfordert " +
+		"umfangreiche Kenntnisse grundlegender Synchronisationsmechanismen."
+	for _, id := range []string{"flesch-amstad", "wiener-sachtextformel"} {
+		f, _ := Lookup(id)
+		if f.Ease {
+			assert.Greater(t, f.Score(easy, "de"), f.Score(hard, "de"), id)
+		} else {
+			assert.Less(t, f.Score(easy, "de"), f.Score(hard, "de"), id)
+		}
+	}
+}
+
+func TestDefaults(t *testing.T) {
+	assert.Equal(t, "ari", Default("").ID)
+	assert.Equal(t, "ari", Default("en").ID)
+	assert.Equal(t, "wiener-sachtextformel", Default("de").ID)
+	assert.Equal(t, "fernandez-huerta", Default("es").ID)
+	assert.Equal(t, "kandel-moles", Default("fr").ID)
+	assert.Equal(t, "flesch-vacca", Default("it").ID)
+	assert.Equal(t, "flesch-douma", Default("nl").ID)
+	assert.Equal(t, "ari", Default("xx").ID)
+
+	assert.Equal(t, "ari", Grade("es").ID)
+	f, ok := Ease("")
+	require.True(t, ok)
+	assert.Equal(t, "flesch", f.ID)
+	_, ok = Ease("xx")
+	assert.False(t, ok)
+}
+
+func TestLookup(t *testing.T) {
+	_, ok := Lookup("nope")
+	assert.False(t, ok)
+	assert.Contains(t, IDs(), "fernandez-huerta")
+	assert.Panics(t, func() { mustLookup("nope") })
+}
+
+func TestMeasure_CJKLettersAreOneSyllableWords(t *testing.T) {
+	s := Measure("日本語です。", "en")
+	assert.Equal(t, Stats{
+		Words: 5, Sentences: 1, Characters: 5, Syllables: 5, Monosyllables: 5,
+	}, s)
+	assert.Equal(t, mdtext.CountWords("日本語です。"), s.Words)
+}
@@ -0,0 +1,182 @@
+package readability
+
+import (
+	"unicode"
+
+	"github.com/jeduden/mdsmith/internal/mdtext"
+)
+
+// Stats are the text counts the readability formulas combine.
+// Words, Sentences, and Characters match mdtext.CountWords,
+// mdtext.CountSentences, and mdtext.CountCharacters, so a formula
+// built on them agrees with ARI's inputs. The one exception is a
+// katakana run, which CountWords counts as one word and Measure
+// counts per character: every CJK letter is a one-syllable word.
+type Stats struct {
+	Words         int
+	Sentences     int
+	Characters    int // letters and digits
+	Syllables     int
+	Monosyllables int // words of exactly one syllable
+	Polysyllables int // words of three or more syllables
+	LongWords     int // words of more than six letters
+}
+
+// Measure counts text in one rune scan without allocating. Syllables
+// follow lang's vowel rules (see syllableCounter); a word without
+// letters, such as a number, counts as one syllable.
+func Measure(text, lang string) Stats {
+	s := Stats{Sentences: mdtext.CountSentences(text)}
+	var w syllableCounter
+	w.reset(lang)
+	inWord := false
+	for _, r := range text {
+		if mdtext.IsSpace(r) {
+			if inWord {
+				s.addWord(w.finish())
+				w.reset(lang)
+				inWord = false
+			}
+			continue
+		}
+		if mdtext.IsCJK(r) {
+			if inWord {
+				s.addWord(w.finish())
+				w.reset(lang)
+				inWord = false
+			}
+			if unicode.IsLetter(r) {
+				s.Characters++
+				s.addWord(1, 1)
+			}
+			continue
+		}
+		inWord = true
+		if unicode.IsLetter(r) || unicode.IsDigit(r) {
+			s.Characters++
+		}
+		w.add(r)
+	}
+	if inWord {
+		s.addWord(w.finish())
+	}
+	return s
+}
+
+func (s *Stats) addWord(letters, syllables int) {
+	s.Words++
+	s.Syllables += syllables
+	switch {
+	case syllables == 1:
+		s.Monosyllables++
+	case syllables >= 3:
+		s.Polysyllables++
+	}
+	if letters > 6 {
+		s.LongWords++
+	}
+}
+
+// syllableCounter estimates a word's syllables from its vowel
+// groups: each maximal run of vowels is one syllable. Two language
+// rules refine the count. Spanish splits a run at every pair of
+// strong vowels (a, e, o, and accented í, ú), which form a hiatus
+// instead of a diphthong ("le-er", "ca-í-da"). English and French
+// drop a silent final "e" from a word with another vowel group
+// ("make", "grande"), except English "-le" ("table").
+type syllableCounter struct {
+	lang       string
+	letters    int
+	groups     int
+	inVowel    bool
+	prevStrong bool // previous rune was a Spanish strong vowel
+	last       rune // last letter, lowercased
+	prevLast   rune // letter before last
+	lastVowel  bool // last letter was a vowel
+}
+
+func (c *syllableCounter) reset(lang string) {
+	*c = syllableCounter{lang: lang}
+}
+
+func (c *syllableCounter) add(r rune) {
+	if !unicode.IsLetter(r) {
+		c.inVowel = false
+		c.prevStrong = false
+		return
+	}
+	r = unicode.ToLower(r)
+	c.letters++
+	c.prevLast, c.last = c.last, r
+	vowel := isVowel(r, c.lang)
+	c.lastVowel = vowel
+	if !vowel {
+		c.inVowel = false
+		c.prevStrong = false
+		return
+	}
+	strong := c.lang == "es" && isSpanishStrong(r)
+	if !c.inVowel || (strong && c.prevStrong) {
+		c.groups++
+	}
+	c.inVowel = true
+	c.prevStrong = strong
+}
+
+// finish returns the word's letter and syllable counts.
+func (c *syllableCounter) finish() (letters, syllables int) {
+	if c.letters == 0 {
+		return 0, 1
+	}
+	n := c.groups
+	if c.silentFinalE() {
+		n--
+	}
+	if n < 1 {
+		n = 1
+	}
+	return c.letters, n
+}
+
+func (c *syllableCounter) silentFinalE() bool {
+	if c.last != 'e' || c.groups < 2 || !c.lastVowel {
+		return false
+	}
+	switch c.lang {
+	case "en":
+		return c.prevLast != 'l' && !isVowel(c.prevLast, c.lang)
+	case "fr":
+		return !isVowel(c.prevLast, c.lang)
+	default:
+		return false
+	}
+}
+
+func isVowel(r rune, lang string) bool {
+	switch r {
+	case 'a', 'e', 'i', 'o', 'u':
+		return true
+	case 'y':
+		// Spanish "y" is a consonant except word-finally ("hoy"),
+		// which the group count cannot see; treating it as a
+		// consonant undercounts only those diphthongs.
+		return lang != "es"
+	}
+	if r < 0x80 {
+		return false
+	}
+	switch r {
+	case 'á', 'à', 'â', 'ä', 'é', 'è', 'ê', 'ë', 'í', 'ì', 'î', 'ï',
+		'ó', 'ò', 'ô', 'ö', 'ú', 'ù', 'û', 'ü', 'ÿ', 'æ', 'œ':
+		return true
+	}
+	return false
+}
+
+func isSpanishStrong(r rune) bool {
+	switch r {
+	case 'a', 'e', 'o', 'á', 'é', 'ó', 'í', 'ú':
+		return true
+	}
+	return false
+}
@@ -119,6 +119,40 @@ func Heading(
 	return changes, nil
 }
 
+// RetargetAnchors rewrites every workspace anchor link and ref-def
+// destination whose heading changes slug when the headings on the
+// given lines take new visible texts. texts maps a 1-based source
+// line of a heading in file to its new text. Unlike Heading it emits
+// no heading-line edit — the caller owns those, since a renumbering
+// rewrites only part of the heading line — and runs no collision
+// check: disambiguators shift the same way a fresh parse would.
+//
+// Slugs are diffed once over the whole file, so several headings can
+// change together; a swap of two slugs rewrites each link against
+// the original bytes and never chains.
+func RetargetAnchors(ws Workspace, file string, source []byte, texts map[int]string) map[string][]Edit {
+	changes := map[string][]Edit{}
+	if len(texts) == 0 {
+		return changes
+	}
+	body, fmOffset := bodyAndFMOffset(source)
+	root := lint.NewParser().Parse(text.NewReader(body), parser.WithContext(parser.NewContext()))
+	headings := walkAllHeadings(root, body)
+	oldTexts := slicesOfText(headings)
+	newTexts := slicesOfText(headings)
+	for i, h := range headings {
+		if t, ok := texts[h.bodyLine+fmOffset]; ok {
+			newTexts[i] = t
+		}
+	}
+	for old, neu := range slugRemapPairs(assignSlugs(oldTexts), assignSlugs(newTexts)) {
+		appendAnchorEditsForHeading(changes, ws, file, old, neu)
+		appendRefDefDestEditsForHeading(changes, ws, file, old, neu)
+	}
+	stableSortEdits(changes)
+	return changes
+}
+
 // FindHeadingLine returns the 1-based source line of the first
 // heading whose visible text equals headingText, or ok=false when no
 // heading matches. The CLI uses it to turn `--heading "Old"` into the
@@ -628,3 +628,27 @@ func TestAnchorFragmentBytes_ImageWithFragInLink(t *testing.T) {
 	require.True(t, ok)
 	assert.Equal(t, "setup", string(row[s:e]))
 }
+
+func TestRetargetAnchors_SwapsSlugsAcrossFiles(t *testing.T) {
+	ws := newMemWorkspace(map[string]string{
+		"spec.md": "## 1 Notes\n\n## 2 Notes\n\nSee [first](#1-notes).\n",
+		"b.md":    "Read [second](spec.md#2-notes).\n\n[n]: spec.md#1-notes\n",
+	})
+	changes := RetargetAnchors(ws, "spec.md", ws.files["spec.md"], map[int]string{
+		1: "2 Notes",
+		3: "1 Notes",
+	})
+	got := map[string][]string{}
+	for key, edits := range changes {
+		for _, e := range edits {
+			got[key] = append(got[key], e.NewText)
+		}
+	}
+	// Edits come in reverse document order; each rewrites against
+	// the original bytes, so the swap does not chain.
+	assert.Equal(t, map[string][]string{
+		"spec.md": {"2-notes"},
+		"b.md":    {"2-notes", "1-notes"},
+	}, got)
+	assert.Empty(t, RetargetAnchors(ws, "spec.md", ws.files["spec.md"], nil))
+}
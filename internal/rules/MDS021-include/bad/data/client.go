@@ -0,0 +1,10 @@
+package client
+
+import "net/http"
+
+// region setup
+func newClient() *http.Client {
+	return &http.Client{}
+}
+
+// endregion
@@ -18,6 +18,7 @@ package all
 import (
 	_ "github.com/jeduden/mdsmith/internal/rules/ambiguousemphasis"           // registers rule
 	_ "github.com/jeduden/mdsmith/internal/rules/atxheadingwhitespace"        // registers rule
+	_ "github.com/jeduden/mdsmith/internal/rules/backlinks"                   // registers rule
 	_ "github.com/jeduden/mdsmith/internal/rules/blanklinearoundfencedcode"   // registers rule
 	_ "github.com/jeduden/mdsmith/internal/rules/blanklinearoundheadings"     // registers rule
 	_ "github.com/jeduden/mdsmith/internal/rules/blanklinearoundlists"        // registers rule
@@ -26,9 +27,11 @@ import (
 	_ "github.com/jeduden/mdsmith/internal/rules/callouttype"                 // registers rule
 	_ "github.com/jeduden/mdsmith/internal/rules/catalog"                     // registers rule
 	_ "github.com/jeduden/mdsmith/internal/rules/codeblockstyle"              // registers rule
+	_ "github.com/jeduden/mdsmith/internal/rules/codeblocksyntax"             // registers rule
 	_ "github.com/jeduden/mdsmith/internal/rules/commandsshowoutput"          // registers rule
 	_ "github.com/jeduden/mdsmith/internal/rules/concisenessscoring"          // registers rule
 	_ "github.com/jeduden/mdsmith/internal/rules/crossfilereferenceintegrity" // registers rule
+	_ "github.com/jeduden/mdsmith/internal/rules/data"                        // registers rule
 	_ "github.com/jeduden/mdsmith/internal/rules/descriptivelinktext"         // registers rule
 	_ "github.com/jeduden/mdsmith/internal/rules/directorystructure"          // registers rule
 	_ "github.com/jeduden/mdsmith/internal/rules/duplicatedcontent"           // registers rule
@@ -42,7 +45,9 @@ import (
 	_ "github.com/jeduden/mdsmith/internal/rules/forbiddentext"               // registers rule
 	_ "github.com/jeduden/mdsmith/internal/rules/githooksync"                 // registers rule
 	_ "github.com/jeduden/mdsmith/internal/rules/headingincrement"            // registers rule
+	_ "github.com/jeduden/mdsmith/internal/rules/headingnumbering"            // registers rule
 	_ "github.com/jeduden/mdsmith/internal/rules/headingstyle"                // registers rule
+	_ "github.com/jeduden/mdsmith/internal/rules/history"                     // registers rule
 	_ "github.com/jeduden/mdsmith/internal/rules/horizontalrulestyle"         // registers rule
 	_ "github.com/jeduden/mdsmith/internal/rules/include"                     // registers rule
 	_ "github.com/jeduden/mdsmith/internal/rules/linelength"                  // registers rule
@@ -54,6 +59,8 @@ import (
 	_ "github.com/jeduden/mdsmith/internal/rules/markdownflavor"              // registers rule
 	_ "github.com/jeduden/mdsmith/internal/rules/maxfilelength"               // registers rule
 	_ "github.com/jeduden/mdsmith/internal/rules/maxsectionlength"            // registers rule
+	_ "github.com/jeduden/mdsmith/internal/rules/mermaidsyntax"               // registers rule
+	_ "github.com/jeduden/mdsmith/internal/rules/metricregression"            // registers rule
 	_ "github.com/jeduden/mdsmith/internal/rules/nobareurls"                  // registers rule
 	_ "github.com/jeduden/mdsmith/internal/rules/noduplicateheadings"         // registers rule
 	_ "github.com/jeduden/mdsmith/internal/rules/noemphasisasheading"         // registers rule
@@ -73,6 +80,7 @@ import (
 	_ "github.com/jeduden/mdsmith/internal/rules/paragraphreadability"        // registers rule
 	_ "github.com/jeduden/mdsmith/internal/rules/paragraphstructure"          // registers rule
 	_ "github.com/jeduden/mdsmith/internal/rules/propernames"                 // registers rule
+	_ "github.com/jeduden/mdsmith/internal/rules/reachability"                // registers rule
 	_ "github.com/jeduden/mdsmith/internal/rules/recipesafety"                // registers rule
 	_ "github.com/jeduden/mdsmith/internal/rules/requiredfrontmatter"         // registers rule
 	_ "github.com/jeduden/mdsmith/internal/rules/requiredmentions"            // registers rule
@@ -82,11 +90,14 @@ import (
 	_ "github.com/jeduden/mdsmith/internal/rules/singleh1"                    // registers rule
 	_ "github.com/jeduden/mdsmith/internal/rules/singletrailingnewline"       // registers rule
 	_ "github.com/jeduden/mdsmith/internal/rules/slidevstructure"             // registers rule
+	_ "github.com/jeduden/mdsmith/internal/rules/spelling"                    // registers rule
 	_ "github.com/jeduden/mdsmith/internal/rules/tableformat"                 // registers rule
 	_ "github.com/jeduden/mdsmith/internal/rules/tablereadability"            // registers rule
 	_ "github.com/jeduden/mdsmith/internal/rules/toc"                         // registers rule
 	_ "github.com/jeduden/mdsmith/internal/rules/tocdirective"                // registers rule
 	_ "github.com/jeduden/mdsmith/internal/rules/tokenbudget"                 // registers rule
+	_ "github.com/jeduden/mdsmith/internal/rules/tree"                        // registers rule
 	_ "github.com/jeduden/mdsmith/internal/rules/unclosedcodeblock"           // registers rule
 	_ "github.com/jeduden/mdsmith/internal/rules/uniquefrontmatter"           // registers rule
+	_ "github.com/jeduden/mdsmith/internal/rules/userdirective"               // registers rule
 )
@@ -0,0 +1,35 @@
+package astutil
+
+import (
+	"bytes"
+
+	"github.com/jeduden/mdsmith/internal/lint"
+	"github.com/jeduden/mdsmith/internal/mdtext"
+)
+
+// Lang resolves the language a prose rule reads f in. A front-matter
+// `lang:` naming a supported language wins, since it is the file's
+// own declaration; otherwise configured (the rule's `lang` setting,
+// which kinds and overrides can set) applies; otherwise English.
+//
+// Files without a `lang:` key skip the front-matter decode and the
+// memo entry entirely, keeping the common path allocation-free.
+func Lang(f *lint.File, configured string) string {
+	if len(f.FrontMatter) > 0 && bytes.Contains(f.FrontMatter, []byte("lang:")) {
+		if lang := f.MemoFile("astutil.frontMatterLang", buildFrontMatterLang).(string); lang != "" {
+			return lang
+		}
+	}
+	if lang, ok := mdtext.NormalizeLang(configured); ok {
+		return lang
+	}
+	return mdtext.DefaultLang
+}
+
+// buildFrontMatterLang is the MemoFile builder for Lang: the
+// normalized front-matter language, or "" when it is missing or
+// unsupported.
+func buildFrontMatterLang(f *lint.File) any {
+	lang, _ := mdtext.NormalizeLang(lint.ParseFrontMatterLang(f.FrontMatter))
+	return lang
+}
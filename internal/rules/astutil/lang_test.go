@@ -0,0 +1,41 @@
+package astutil
+
+import (
+	"testing"
+
+	"github.com/jeduden/mdsmith/internal/lint"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLang(t *testing.T) {
+	tests := []struct {
+		name       string
+		fm         string
+		configured string
+		want       string
+	}{
+		{"default", "", "", "en"},
+		{"configured", "", "de", "de"},
+		{"configured name", "", "Spanish", "es"},
+		{"configured unknown", "", "xx", "en"},
+		{"front matter wins", "---\nlang: fr-CA\n---\n", "de", "fr"},
+		{"unsupported front matter", "---\nlang: ja\n---\n", "de", "de"},
+		{"front matter without lang", "---\ntitle: x\n---\n", "it", "it"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			f, err := lint.NewFile("doc.md", []byte("# Doc\n"))
+			require.NoError(t, err)
+			f.FrontMatter = []byte(tt.fm)
+			assert.Equal(t, tt.want, Lang(f, tt.configured))
+		})
+	}
+}
+
+func TestLang_NoFrontMatterDoesNotAllocate(t *testing.T) {
+	f, err := lint.NewFile("doc.md", []byte("# Doc\n"))
+	require.NoError(t, err)
+	allocs := testing.AllocsPerRun(100, func() { _ = Lang(f, "de") })
+	assert.Zero(t, allocs)
+}
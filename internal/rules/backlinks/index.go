@@ -0,0 +1,232 @@
+package backlinks
+
+import (
+	"bytes"
+	"io/fs"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/jeduden/mdsmith/pkg/goldmark/ast"
+
+	"github.com/jeduden/mdsmith/internal/archetype/gensection"
+	"github.com/jeduden/mdsmith/internal/bytelimit"
+	"github.com/jeduden/mdsmith/internal/linkgraph"
+	"github.com/jeduden/mdsmith/internal/lint"
+	"github.com/jeduden/mdsmith/internal/mdpath"
+	"github.com/jeduden/mdsmith/internal/mdtext"
+)
+
+// inbound is one link from a workspace file to another. Paths are
+// relative to the corpus root, slash-separated.
+type inbound struct {
+	source  string
+	title   string
+	text    string
+	heading string
+	anchor  string
+	kinds   []string
+	line    int
+}
+
+// linkIndex maps each link target to the links that reach it, in
+// source-path then line order. Built once per run and read-only
+// afterwards, so concurrent Check goroutines share it without locks.
+type linkIndex struct {
+	byTarget map[string][]inbound
+}
+
+// sectionHeading is one heading with the line it starts on and the
+// anchor MDS027 and <?toc?> give it.
+type sectionHeading struct {
+	text   string
+	anchor string
+	line   int
+}
+
+// corpusIndex returns the link index for corpus, built at most once
+// per run via the RunCache when the corpus has a stable root. Any
+// content edit drops the RunCache slot, so the LSP rebuilds it on
+// the next Check. The per-File memo fallback serves fixtures and
+// in-memory callers.
+func corpusIndex(f *lint.File, corpus fs.FS, rootDir string) *linkIndex {
+	build := func() any { return buildIndex(f, corpus, rootDir) }
+	var v any
+	if f.RunCache != nil && rootDir != "" {
+		v = f.RunCache.CorpusIndex("MDS078\x00"+rootDir, build)
+	} else {
+		v = f.Memo("backlinks.index", build)
+	}
+	return v.(*linkIndex)
+}
+
+// buildIndex walks every Markdown file in corpus and records its
+// outgoing links by resolved target. Unreadable files are skipped:
+// one broken sibling must not fail every backlinks section.
+func buildIndex(f *lint.File, corpus fs.FS, rootDir string) *linkIndex {
+	idx := &linkIndex{byTarget: map[string][]inbound{}}
+	var wikilinks *linkgraph.WikilinkIndex
+	if rootDir != "" {
+		wikilinks = linkgraph.WikilinkIndexFor(f.RunCache, rootDir, corpus)
+	} else {
+		wikilinks = linkgraph.NewWikilinkIndex(corpus)
+	}
+	_ = fs.WalkDir(corpus, ".", func(p string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return nil
+		}
+		if d.IsDir() {
+			switch path.Base(p) {
+			case ".git", "node_modules":
+				return fs.SkipDir
+			}
+			return nil
+		}
+		if !mdpath.IsMarkdownPath(p) {
+			return nil
+		}
+		data, err := bytelimit.ReadFSFileLimited(corpus, p, f.MaxInputBytes)
+		if err != nil {
+			return nil
+		}
+		src, _ := lint.NewFileFromSource(p, data, f.StripFrontMatter) //nolint:errcheck
+		indexSource(idx, src, p, f.Kinds(p, src.FrontMatter), wikilinks)
+		return nil
+	})
+	for target, refs := range idx.byTarget {
+		sort.SliceStable(refs, func(i, j int) bool {
+			if refs[i].source != refs[j].source {
+				return refs[i].source < refs[j].source
+			}
+			return refs[i].line < refs[j].line
+		})
+		idx.byTarget[target] = refs
+	}
+	return idx
+}
+
+// indexSource adds the links src makes to other files, tagged with
+// src's effective kinds. Links inside a <?backlinks?> body are
+// skipped: they are generated from the index itself, and counting
+// them would make two pages that list each other's backlinks keep
+// each other alive.
+func indexSource(
+	idx *linkIndex, src *lint.File, p string, kinds []string, wikilinks *linkgraph.WikilinkIndex,
+) {
+	skip := backlinkBodies(src)
+	headings := collectHeadings(src)
+	var base inbound
+	base.source = p
+	base.title = fileTitle(src, p)
+	base.kinds = kinds
+	add := func(target, text string, line int) {
+		if target == "" || target == p || inRanges(skip, line) {
+			return
+		}
+		ref := base
+		ref.text = text
+		// Heading lookup uses body lines; {line} is the file line
+		// `mdsmith list backlinks` reports.
+		ref.line = line + src.LineOffset
+		if h, ok := headingAt(headings, line); ok {
+			ref.heading, ref.anchor = h.text, h.anchor
+		}
+		idx.byTarget[target] = append(idx.byTarget[target], ref)
+	}
+	for _, l := range linkgraph.ExtractLinks(src) {
+		if l.Target.LocalAnchor {
+			continue
+		}
+		add(linkgraph.ResolveRelTarget(p, l.Target.Path), l.Text, l.Line)
+	}
+	for _, wl := range linkgraph.ExtractWikiLinks(src) {
+		target, ok := wikilinks.Resolve(wl.Target)
+		if !ok {
+			continue
+		}
+		text := wl.Alias
+		if text == "" {
+			text = wl.Target
+		}
+		add(target, text, wl.Line)
+	}
+}
+
+// backlinkBodies returns the body line ranges of src's backlinks
+// sections. Malformed markers yield no ranges.
+func backlinkBodies(src *lint.File) []lint.LineRange {
+	if !bytes.Contains(src.Source, []byte("<?backlinks")) {
+		return nil
+	}
+	pairs, diags := gensection.FindMarkerPairs(src, "backlinks", "", "")
+	if len(diags) > 0 {
+		return nil
+	}
+	ranges := make([]lint.LineRange, 0, len(pairs))
+	for _, mp := range pairs {
+		ranges = append(ranges, lint.LineRange{From: mp.ContentFrom, To: mp.ContentTo})
+	}
+	return ranges
+}
+
+func inRanges(ranges []lint.LineRange, line int) bool {
+	for _, r := range ranges {
+		if line >= r.From && line <= r.To {
+			return true
+		}
+	}
+	return false
+}
+
+// collectHeadings returns src's headings in document order. Anchors
+// come from mdtext.CollectTOCItems, which skips headings with an
+// empty slug; this walk skips the same ones so the two line up.
+func collectHeadings(src *lint.File) []sectionHeading {
+	items := mdtext.CollectTOCItems(src.AST, src.Source)
+	if len(items) == 0 {
+		return nil
+	}
+	out := make([]sectionHeading, 0, len(items))
+	_ = ast.Walk(src.AST, func(n ast.Node, entering bool) (ast.WalkStatus, error) {
+		h, ok := n.(*ast.Heading)
+		if !entering || !ok || len(out) == len(items) {
+			return ast.WalkContinue, nil
+		}
+		if mdtext.Slugify(mdtext.ExtractPlainText(h, src.Source)) == "" {
+			return ast.WalkContinue, nil
+		}
+		line := 0
+		if lines := h.Lines(); lines.Len() > 0 {
+			line = src.LineOfOffset(lines.At(0).Start)
+		}
+		item := items[len(out)]
+		out = append(out, sectionHeading{text: item.Text, anchor: item.Anchor, line: line})
+		return ast.WalkSkipChildren, nil
+	})
+	return out
+}
+
+// headingAt returns the last heading at or above line.
+func headingAt(headings []sectionHeading, line int) (sectionHeading, bool) {
+	i := sort.Search(len(headings), func(i int) bool { return headings[i].line > line })
+	if i == 0 {
+		return sectionHeading{}, false
+	}
+	return headings[i-1], true
+}
+
+// fileTitle returns the front-matter title, else the first level-1
+// heading, else the file name without its extension.
+func fileTitle(src *lint.File, p string) string {
+	if fields, err := lint.ParseFrontMatterFields(src.FrontMatter); err == nil {
+		if t, ok := fields["title"].(string); ok && strings.TrimSpace(t) != "" {
+			return strings.TrimSpace(t)
+		}
+	}
+	if t := mdtext.LeadingH1(src.AST, src.Source); t != "" {
+		return t
+	}
+	base := path.Base(p)
+	return strings.TrimSuffix(base, filepath.Ext(base))
+}
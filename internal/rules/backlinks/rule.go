@@ -0,0 +1,267 @@
+// Package backlinks implements MDS078, the <?backlinks?> generated-
+// section directive that lists the workspace files linking to the
+// host file.
+package backlinks
+
+import (
+	"fmt"
+	"io/fs"
+	"path"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/bmatcuk/doublestar/v4"
+
+	"github.com/jeduden/mdsmith/internal/archetype/gensection"
+	"github.com/jeduden/mdsmith/internal/fieldinterp"
+	"github.com/jeduden/mdsmith/internal/globpath"
+	"github.com/jeduden/mdsmith/internal/lint"
+	"github.com/jeduden/mdsmith/internal/rule"
+)
+
+// defaultRow renders one bullet per linking file.
+const defaultRow = "- [{title}]({source})"
+
+func init() {
+	rule.Register(&Rule{})
+	gensection.RegisterGenerated("backlinks")
+}
+
+// Rule checks and fixes <?backlinks?>...<?/backlinks?> generated
+// sections.
+//
+// engineOnce serialises lazy engine init; the rule is a registered
+// singleton and the LSP server may call Check from concurrent
+// goroutines.
+type Rule struct {
+	engineOnce sync.Once
+	engine     *gensection.Engine
+}
+
+// ID implements rule.Rule.
+func (r *Rule) ID() string { return "MDS078" }
+
+// Name implements rule.Rule.
+func (r *Rule) Name() string { return "backlinks" }
+
+// Category implements rule.Rule.
+func (r *Rule) Category() string { return "directive" }
+
+// RuleID implements gensection.Directive.
+func (r *Rule) RuleID() string { return "MDS078" }
+
+// RuleName implements gensection.Directive.
+func (r *Rule) RuleName() string { return "backlinks" }
+
+func (r *Rule) getEngine() *gensection.Engine {
+	r.engineOnce.Do(func() {
+		r.engine = gensection.NewEngine(r)
+	})
+	return r.engine
+}
+
+// Check implements rule.Rule. Stdin and in-memory sources have no
+// workspace to scan, so the rule skips them, as MDS037 does.
+func (r *Rule) Check(f *lint.File) []lint.Diagnostic {
+	if f.FS == nil {
+		return nil
+	}
+	return r.getEngine().Check(f)
+}
+
+// Fix implements rule.FixableRule.
+func (r *Rule) Fix(f *lint.File) []byte {
+	if f.FS == nil {
+		return f.Source
+	}
+	return r.getEngine().Fix(f)
+}
+
+// Validate implements gensection.Directive.
+func (r *Rule) Validate(filePath string, line int,
+	params map[string]string, _ map[string]gensection.ColumnConfig,
+) []lint.Diagnostic {
+	return validateBacklinksDirective(filePath, line, params)
+}
+
+// Generate implements gensection.Directive.
+func (r *Rule) Generate(f *lint.File, filePath string, line int,
+	params map[string]string, _ map[string]gensection.ColumnConfig,
+) (string, []lint.Diagnostic) {
+	corpus, self, rootDir := resolveCorpus(f)
+	idx := corpusIndex(f, corpus, rootDir)
+
+	globs := sourceGlobs(params)
+	kinds := gensection.SplitList(params["kind"])
+	var refs []inbound
+	for _, ref := range idx.byTarget[self] {
+		if globs != nil && !globpath.MatchAny(globs, ref.source) {
+			continue
+		}
+		if len(kinds) > 0 && !slices.ContainsFunc(kinds, func(k string) bool {
+			return slices.Contains(ref.kinds, k)
+		}) {
+			continue
+		}
+		refs = append(refs, ref)
+	}
+
+	if len(refs) == 0 {
+		if empty := params["empty"]; empty != "" {
+			return gensection.EnsureTrailingNewline(empty), nil
+		}
+		return "", nil
+	}
+	return renderRows(params, refs, path.Dir(self)), nil
+}
+
+// renderRows renders header, one row per link, and footer. Rows that
+// render identically are emitted once, so the default row lists a
+// file once however often it links to the host. A missing header or
+// footer becomes a blank line so the list satisfies MDS014, as in
+// <?toc?>.
+func renderRows(params map[string]string, refs []inbound, hostDir string) string {
+	row := params["row"]
+	if row == "" {
+		row = defaultRow
+	}
+	var b strings.Builder
+	if header := params["header"]; header != "" {
+		b.WriteString(gensection.EnsureTrailingNewline(header))
+	} else {
+		b.WriteByte('\n')
+	}
+	seen := map[string]struct{}{}
+	for _, ref := range refs {
+		line := gensection.EnsureTrailingNewline(
+			fieldinterp.Interpolate(row, rowFields(ref, hostDir)))
+		if _, dup := seen[line]; dup {
+			continue
+		}
+		seen[line] = struct{}{}
+		b.WriteString(line)
+	}
+	if footer := params["footer"]; footer != "" {
+		b.WriteString(gensection.EnsureTrailingNewline(footer))
+	} else {
+		b.WriteByte('\n')
+	}
+	return b.String()
+}
+
+// rowFields returns the placeholder values for one link. source and
+// link are relative to the host file, so they work as link targets.
+func rowFields(ref inbound, hostDir string) map[string]any {
+	source := ref.source
+	if rel, err := filepath.Rel(hostDir, ref.source); err == nil {
+		source = filepath.ToSlash(rel)
+	}
+	link := source
+	if ref.anchor != "" {
+		link += "#" + ref.anchor
+	}
+	return map[string]any{
+		"source":  source,
+		"path":    ref.source,
+		"title":   ref.title,
+		"text":    ref.text,
+		"heading": ref.heading,
+		"anchor":  ref.anchor,
+		"link":    link,
+		"line":    strconv.Itoa(ref.line),
+	}
+}
+
+// sourceGlobs returns the `glob:` patterns, or nil when every source
+// is kept. A list of only `!` exclusions keeps everything else.
+func sourceGlobs(params map[string]string) []string {
+	globs := gensection.SplitList(params["glob"])
+	if len(globs) == 0 {
+		return nil
+	}
+	include, _ := globpath.SplitIncludeExclude(globs)
+	if len(include) == 0 {
+		globs = append([]string{"**"}, globs...)
+	}
+	return globs
+}
+
+// resolveCorpus returns the FS to scan for links, the host file's
+// path within it, and the FS's absolute root ("" when it has none).
+// The project root is preferred; without one only the host's own
+// directory is scanned.
+func resolveCorpus(f *lint.File) (corpus fs.FS, self string, rootDir string) {
+	if f.RootFS != nil && f.RootDir != "" {
+		if rel, ok := rootRelative(f.RootDir, f.Path); ok {
+			return f.RootFS, rel, f.RootDir
+		}
+	}
+	return f.FS, filepath.Base(f.Path), ""
+}
+
+// rootRelative returns p relative to rootDir with forward slashes,
+// or ok=false when p lies outside rootDir. A relative p is taken as
+// CWD-relative, the form the engine passes.
+func rootRelative(rootDir, p string) (string, bool) {
+	abs, err := filepath.Abs(p)
+	if err != nil {
+		return "", false
+	}
+	rel, err := filepath.Rel(rootDir, abs)
+	if err != nil {
+		return "", false
+	}
+	rel = filepath.ToSlash(rel)
+	if rel == ".." || strings.HasPrefix(rel, "../") {
+		return "", false
+	}
+	return rel, true
+}
+
+// validateBacklinksDirective validates the directive's parameters
+// without scanning the workspace.
+func validateBacklinksDirective(
+	filePath string, line int, params map[string]string,
+) []lint.Diagnostic {
+	for _, g := range gensection.SplitList(params["glob"]) {
+		if !doublestar.ValidatePattern(strings.TrimPrefix(g, "!")) {
+			return []lint.Diagnostic{makeDiag(filePath, line,
+				fmt.Sprintf("backlinks directive has invalid glob %q", g))}
+		}
+	}
+	if v, ok := params["kind"]; ok && len(gensection.SplitList(v)) == 0 {
+		return []lint.Diagnostic{makeDiag(filePath, line,
+			`backlinks directive has empty "kind" value`)}
+	}
+	if row, ok := params["row"]; ok {
+		if strings.TrimSpace(row) == "" {
+			return []lint.Diagnostic{makeDiag(filePath, line,
+				`backlinks directive has empty "row" value`)}
+		}
+		if err := fieldinterp.Validate(row); err != nil {
+			return []lint.Diagnostic{makeDiag(filePath, line,
+				fmt.Sprintf("backlinks directive has invalid row template: %v", err))}
+		}
+	}
+	return nil
+}
+
+func makeDiag(file string, line int, msg string) lint.Diagnostic {
+	return lint.Diagnostic{
+		File:     file,
+		Line:     line,
+		Column:   1,
+		RuleID:   "MDS078",
+		RuleName: "backlinks",
+		Severity: lint.Error,
+		Message:  msg,
+	}
+}
+
+var _ rule.FixableRule = (*Rule)(nil)
+
+// FixTitle implements rule.QuickFixTitler.
+func (r *Rule) FixTitle() string { return "Regenerate backlinks" }
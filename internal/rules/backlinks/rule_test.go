@@ -0,0 +1,173 @@
+package backlinks
+
+import (
+	"strings"
+	"testing"
+	"testing/fstest"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/jeduden/mdsmith/internal/archetype/gensection"
+	"github.com/jeduden/mdsmith/internal/config"
+	"github.com/jeduden/mdsmith/internal/lint"
+)
+
+// wikiFS is a small workspace where three files link to target.md.
+var wikiFS = fstest.MapFS{
+	"target.md": {Data: []byte("# Target\n")},
+	"alpha.md": {Data: []byte("---\ntitle: Alpha Page\nkinds: [guide]\n---\n# Alpha\n\n" +
+		"See [the target](target.md).\n\n## Usage\n\nAgain: [target](target.md#top).\n")},
+	"notes/beta.md": {Data: []byte("# Beta\n\n## Links\n\n[[target]] is related.\n")},
+	"gamma.md":      {Data: []byte("Intro [t](./target.md) with no heading.\n")},
+	"other.md":      {Data: []byte("# Other\n\nNo links here.\n")},
+}
+
+// fixBacklinks runs Fix on target.md with a backlinks directive using
+// the given parameter lines and returns the body between the markers.
+func fixBacklinks(t *testing.T, params string) string {
+	t.Helper()
+	return fixBacklinksWith(t, params, nil)
+}
+
+// fixBacklinksWith is fixBacklinks with kinds resolved through cfg, as
+// the engine wires them; nil leaves only front-matter kinds.
+func fixBacklinksWith(t *testing.T, params string, cfg *config.Config) string {
+	t.Helper()
+	src := "# Target\n\n<?backlinks\n" + params + "?>\n<?/backlinks?>\n"
+	f, err := lint.NewFile("target.md", []byte(src))
+	require.NoError(t, err)
+	f.FS = wikiFS
+	f.StripFrontMatter = true
+	if cfg != nil {
+		f.KindsFunc = func(p string, fm []byte) []string { return config.FileKinds(cfg, p, fm) }
+	}
+	out := string((&Rule{}).Fix(f))
+	start := strings.Index(out, "?>\n") + len("?>\n")
+	end := strings.LastIndex(out, "<?/backlinks?>")
+	return out[start:end]
+}
+
+func TestGenerate_DefaultListsEachSourceOnce(t *testing.T) {
+	got := fixBacklinks(t, "")
+	want := "\n- [Alpha Page](alpha.md)\n- [gamma](gamma.md)\n- [Beta](notes/beta.md)\n\n"
+	assert.Equal(t, want, got)
+}
+
+func TestGenerate_HeadingContext(t *testing.T) {
+	got := fixBacklinks(t, "row: \"- [{title}]({link}): {heading}\"\n")
+	want := "\n- [Alpha Page](alpha.md#alpha): Alpha\n" +
+		"- [Alpha Page](alpha.md#usage): Usage\n" +
+		"- [gamma](gamma.md): \n" +
+		"- [Beta](notes/beta.md#links): Links\n\n"
+	assert.Equal(t, want, got)
+}
+
+func TestGenerate_FilterByGlob(t *testing.T) {
+	got := fixBacklinks(t, "glob: \"notes/**\"\n")
+	assert.Equal(t, "\n- [Beta](notes/beta.md)\n\n", got)
+
+	got = fixBacklinks(t, "glob: \"!notes/**\"\n")
+	assert.Equal(t, "\n- [Alpha Page](alpha.md)\n- [gamma](gamma.md)\n\n", got)
+}
+
+func TestGenerate_FilterByKind(t *testing.T) {
+	got := fixBacklinks(t, "kind: guide\n")
+	assert.Equal(t, "\n- [Alpha Page](alpha.md)\n\n", got)
+}
+
+func TestGenerate_FilterByAssignedKind(t *testing.T) {
+	cfg := &config.Config{KindAssignment: []config.KindAssignmentEntry{
+		{Glob: []string{"notes/**"}, Kinds: []string{"note"}},
+	}}
+	got := fixBacklinksWith(t, "kind: note\n", cfg)
+	assert.Equal(t, "\n- [Beta](notes/beta.md)\n\n", got)
+
+	// Front-matter kinds still count alongside assigned ones.
+	got = fixBacklinksWith(t, "kind: [guide, note]\n", cfg)
+	assert.Equal(t, "\n- [Alpha Page](alpha.md)\n- [Beta](notes/beta.md)\n\n", got)
+}
+
+func TestGenerate_EmptyFallback(t *testing.T) {
+	got := fixBacklinks(t, "kind: missing\nempty: Nothing links here yet.\n")
+	assert.Equal(t, "Nothing links here yet.\n", got)
+}
+
+func TestGenerate_HeaderFooterAndLinkText(t *testing.T) {
+	got := fixBacklinks(t, "header: \"Referenced by:\\n\"\nrow: \"- {text} ({path}:{line})\"\n"+
+		"footer: \"End.\"\nglob: alpha.md\n")
+	want := "Referenced by:\n- the target (alpha.md:7)\n- target (alpha.md:11)\nEnd.\n"
+	assert.Equal(t, want, got)
+}
+
+func TestGenerate_IgnoresLinksInsideBacklinksSections(t *testing.T) {
+	fsys := fstest.MapFS{
+		"a.md": {Data: []byte("# A\n\n<?backlinks\n?>\n\n- [B](b.md)\n\n<?/backlinks?>\n")},
+		"b.md": {Data: []byte("# B\n\nSee [A](a.md).\n\n<?backlinks\n?>\n<?/backlinks?>\n")},
+	}
+	f, err := lint.NewFile("b.md", fsys["b.md"].Data)
+	require.NoError(t, err)
+	f.FS = fsys
+	out := string((&Rule{}).Fix(f))
+	assert.Equal(t, string(fsys["b.md"].Data), out,
+		"a.md's generated link to b.md must not count as a backlink")
+
+	f, err = lint.NewFile("a.md", fsys["a.md"].Data)
+	require.NoError(t, err)
+	f.FS = fsys
+	assert.Empty(t, (&Rule{}).Check(f), "a.md lists b.md, which links to it")
+}
+
+func TestCheck_StaleSectionReported(t *testing.T) {
+	src := "# Target\n\n<?backlinks\n?>\n- [Other](other.md)\n<?/backlinks?>\n"
+	f, err := lint.NewFile("target.md", []byte(src))
+	require.NoError(t, err)
+	f.FS = wikiFS
+	f.StripFrontMatter = true
+	diags := (&Rule{}).Check(f)
+	require.Len(t, diags, 1)
+	assert.Equal(t, 3, diags[0].Line)
+	assert.Contains(t, diags[0].Message, "out of date")
+}
+
+func TestCheck_NoFSSkips(t *testing.T) {
+	f, err := lint.NewFile("target.md", []byte("<?backlinks\n?>\nstale\n<?/backlinks?>\n"))
+	require.NoError(t, err)
+	assert.Empty(t, (&Rule{}).Check(f))
+}
+
+func TestValidate_Errors(t *testing.T) {
+	tests := []struct {
+		name   string
+		params map[string]string
+		msg    string
+	}{
+		{"bad glob", map[string]string{"glob": "["}, `invalid glob "["`},
+		{"empty kind", map[string]string{"kind": " "}, `empty "kind"`},
+		{"empty row", map[string]string{"row": " "}, `empty "row"`},
+		{"bad row", map[string]string{"row": "{title"}, "invalid row template"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			diags := (&Rule{}).Validate("target.md", 1, tt.params, nil)
+			require.Len(t, diags, 1)
+			assert.Contains(t, diags[0].Message, tt.msg)
+			assert.Equal(t, "MDS078", diags[0].RuleID)
+		})
+	}
+}
+
+func TestRootRelative(t *testing.T) {
+	rel, ok := rootRelative("/work", "/work/docs/a.md")
+	assert.True(t, ok)
+	assert.Equal(t, "docs/a.md", rel)
+
+	_, ok = rootRelative("/work", "/elsewhere/a.md")
+	assert.False(t, ok)
+}
+
+func TestAuthoredSource_SkipsBacklinksBody(t *testing.T) {
+	src := "# Target\n\n<?backlinks?>\n- [Alpha](alpha.md)\n<?/backlinks?>\n"
+	assert.Equal(t, "# Target\n\n<?backlinks?>\n<?/backlinks?>\n",
+		string(gensection.AuthoredSource([]byte(src))))
+}
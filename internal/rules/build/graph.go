@@ -0,0 +1,171 @@
+package build
+
+import (
+	"bytes"
+	"io/fs"
+	"path"
+	"path/filepath"
+	"strings"
+
+	"github.com/jeduden/mdsmith/internal/archetype/gensection"
+	"github.com/jeduden/mdsmith/internal/buildgraph"
+	"github.com/jeduden/mdsmith/internal/bytelimit"
+	"github.com/jeduden/mdsmith/internal/lint"
+	"github.com/jeduden/mdsmith/internal/mdpath"
+)
+
+// target is one well-formed <?build?> directive as the target graph
+// sees it. Default inputs are resolved later, against the recipes the
+// rule is configured with.
+type target struct {
+	file    string
+	line    int
+	recipe  string
+	params  map[string]string
+	inputs  []string
+	outputs []string
+}
+
+// targetsIn returns the well-formed build directives of f, the same set
+// the build pass dispatches.
+func targetsIn(f *lint.File, file string) []target {
+	pairs, _ := gensection.FindMarkerPairs(f, "build", "", "")
+	var out []target
+	for _, mp := range pairs {
+		dir, diags := gensection.ParseDirective(f.Path, mp, "", "")
+		if dir == nil || len(diags) > 0 {
+			continue
+		}
+		t := target{
+			file:    file,
+			line:    mp.StartLine,
+			recipe:  strings.TrimSpace(dir.Params["recipe"]),
+			params:  dir.Params,
+			inputs:  splitList(dir.Params["inputs"]),
+			outputs: splitList(dir.Params["outputs"]),
+		}
+		if t.recipe == "" || len(t.outputs) == 0 {
+			continue
+		}
+		out = append(out, t)
+	}
+	return out
+}
+
+// corpusTargets returns the build directives of every Markdown file in
+// corpus, built at most once per run via the RunCache when the corpus
+// has a stable root, else memoized on f.
+func corpusTargets(f *lint.File, corpus fs.FS, rootDir string) []target {
+	build := func() any { return scanTargets(f, corpus) }
+	var v any
+	if f.RunCache != nil && rootDir != "" {
+		v = f.RunCache.CorpusIndex("MDS039\x00"+rootDir, build)
+	} else {
+		v = f.Memo("build.targets", build)
+	}
+	return v.([]target)
+}
+
+// scanTargets walks corpus and collects its build directives.
+// Unreadable files are skipped.
+func scanTargets(f *lint.File, corpus fs.FS) []target {
+	var out []target
+	_ = fs.WalkDir(corpus, ".", func(p string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return nil
+		}
+		if d.IsDir() {
+			switch path.Base(p) {
+			case ".git", "node_modules":
+				return fs.SkipDir
+			}
+			return nil
+		}
+		if !mdpath.IsMarkdownPath(p) {
+			return nil
+		}
+		data, err := bytelimit.ReadFSFileLimited(corpus, p, f.MaxInputBytes)
+		if err != nil || !bytes.Contains(data, []byte("<?build")) {
+			return nil
+		}
+		src, _ := lint.NewFileFromSource(p, data, f.StripFrontMatter) //nolint:errcheck
+		out = append(out, targetsIn(src, p)...)
+		return nil
+	})
+	return out
+}
+
+// cycleDiags reports each directive of f that sits on a dependency
+// cycle: its inputs are, directly or through other directives anywhere
+// in the workspace, built from its own outputs. f's directives come
+// from f itself so an unsaved edit is seen.
+func (r *Rule) cycleDiags(f *lint.File) []lint.Diagnostic {
+	corpus, self, rootDir := resolveCorpus(f)
+	own := targetsIn(f, self)
+	if len(own) == 0 {
+		return nil
+	}
+	targets := own
+	if corpus != nil {
+		for _, t := range corpusTargets(f, corpus, rootDir) {
+			if t.file != self {
+				targets = append(targets, t)
+			}
+		}
+	}
+	nodes := make([]buildgraph.Node, len(targets))
+	for i, t := range targets {
+		inputs := t.inputs
+		if schema, ok := r.resolveRecipe(t.recipe); ok && len(schema.DefaultInputs) > 0 {
+			inputs = append(append([]string(nil), inputs...),
+				buildgraph.ResolveDefaultInputs(schema.DefaultInputs, t.params)...)
+		}
+		nodes[i] = buildgraph.Node{Inputs: inputs, Outputs: t.outputs}
+	}
+	var diags []lint.Diagnostic
+	for _, c := range buildgraph.New(nodes).Cycles() {
+		names := make([]string, 0, len(c)+1)
+		for _, i := range c {
+			names = append(names, targets[i].outputs[0])
+		}
+		names = append(names, names[0])
+		msg := "build directive is part of a dependency cycle: " + strings.Join(names, " -> ")
+		for _, i := range c {
+			if i < len(own) {
+				diags = append(diags, gensection.MakeDiag(r.RuleID(), r.RuleName(), f.Path, own[i].line, msg))
+			}
+		}
+	}
+	return diags
+}
+
+// resolveCorpus returns the FS to scan for directives, the host file's
+// path within it, and the FS's absolute root ("" when it has none).
+// The project root is preferred, since directive paths are relative to
+// it; without one only the host's own directory is scanned.
+func resolveCorpus(f *lint.File) (corpus fs.FS, self string, rootDir string) {
+	if f.RootFS != nil && f.RootDir != "" {
+		if rel, ok := rootRelative(f.RootDir, f.Path); ok {
+			return f.RootFS, rel, f.RootDir
+		}
+	}
+	return f.FS, filepath.Base(f.Path), ""
+}
+
+// rootRelative returns p relative to rootDir with forward slashes, or
+// ok=false when p lies outside rootDir.
+func rootRelative(rootDir, p string) (string, bool) {
+	abs, err := filepath.Abs(p)
+	if err != nil {
+		return "", false
+	}
+	rel, err := filepath.Rel(rootDir, abs)
+	if err != nil {
+		return "", false
+	}
+	rel = filepath.ToSlash(rel)
+	if rel == ".." || strings.HasPrefix(rel, "../") {
+		return "", false
+	}
+	return rel, true
+}
@@ -0,0 +1,75 @@
+package build
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/jeduden/mdsmith/internal/lint"
+)
+
+// buildDirective returns a <?build?> directive with a stale-free body
+// for the "gen" recipe.
+func buildDirective(input, output string) string {
+	return "<?build\nrecipe: gen\ninputs:\n  - " + input + "\noutputs:\n  - " + output +
+		"\n?>\n[" + output + "](" + output + ")\n<?/build?>\n"
+}
+
+// ruleWithGen returns a Rule with a "gen" recipe whose default inputs
+// are given.
+func ruleWithGen(defaults ...string) *Rule {
+	return &Rule{recipes: map[string]recipeSchema{"gen": {DefaultInputs: defaults}}}
+}
+
+func TestCheck_CycleInOneFile(t *testing.T) {
+	src := "# Doc\n\n" + buildDirective("b.txt", "a.txt") + "\n" + buildDirective("a.txt", "b.txt")
+	diags := ruleWithGen().Check(newFile(t, src))
+	require.Len(t, diags, 2)
+	msg := "build directive is part of a dependency cycle: a.txt -> b.txt -> a.txt"
+	assert.Equal(t, 3, diags[0].Line)
+	assert.Equal(t, msg, diags[0].Message)
+	assert.Equal(t, lint.Error, diags[0].Severity)
+	assert.Equal(t, 13, diags[1].Line)
+	assert.Equal(t, msg, diags[1].Message)
+}
+
+func TestCheck_ChainIsNotACycle(t *testing.T) {
+	src := "# Doc\n\n" + buildDirective("api.yaml", "schema.json") + "\n" + buildDirective("schema.json", "ref.txt")
+	assert.Empty(t, ruleWithGen().Check(newFile(t, src)))
+}
+
+func TestCheck_CycleThroughDefaultInputs(t *testing.T) {
+	src := "# Doc\n\n" + buildDirective("src.txt", "out.txt")
+	diags := ruleWithGen("out.txt").Check(newFile(t, src))
+	require.Len(t, diags, 1)
+	assert.Equal(t, "build directive is part of a dependency cycle: out.txt -> out.txt", diags[0].Message)
+}
+
+func TestCheck_CycleAcrossWorkspace(t *testing.T) {
+	root := t.TempDir()
+	other := "# Other\n\n" + buildDirective("docs/ref.txt", "gen/schema.json")
+	require.NoError(t, os.WriteFile(filepath.Join(root, "other.md"), []byte(other), 0o644))
+	self := "# Doc\n\n" + buildDirective("gen/schema.json", "docs/ref.txt")
+	path := filepath.Join(root, "doc.md")
+	require.NoError(t, os.WriteFile(path, []byte(self), 0o644))
+
+	f, err := lint.NewFile(path, []byte(self))
+	require.NoError(t, err)
+	f.SetRootDir(root)
+	diags := ruleWithGen().Check(f)
+	require.Len(t, diags, 1)
+	assert.Equal(t, 3, diags[0].Line)
+	assert.Equal(t,
+		"build directive is part of a dependency cycle: docs/ref.txt -> gen/schema.json -> docs/ref.txt",
+		diags[0].Message)
+
+	// The unsaved content of the host file wins over the copy on disk.
+	fixed := "# Doc\n\n" + buildDirective("api.yaml", "docs/ref.txt")
+	f, err = lint.NewFile(path, []byte(fixed))
+	require.NoError(t, err)
+	f.SetRootDir(root)
+	assert.Empty(t, ruleWithGen().Check(f))
+}
@@ -20,11 +20,13 @@ func init() {
 	rule.Register(&Rule{})
 }
 
-// recipeSchema holds the param schema and body template for a recipe.
+// recipeSchema holds the param schema, body template, and default
+// inputs for a recipe.
 type recipeSchema struct {
-	Required     []string
-	Optional     []string
-	BodyTemplate string
+	Required      []string
+	Optional      []string
+	BodyTemplate  string
+	DefaultInputs []string
 }
 
 // defaultBodyTemplate is the fallback body-template for recipes that omit body-template.
@@ -90,12 +92,16 @@ func (r *Rule) getEngine() *gensection.Engine {
 // when the rendered body differs from the expected body-template output.
 // Unknown params are reported as warnings, which the gensection engine
 // cannot emit alongside a stale-body check, so Check is implemented
-// manually.
+// manually. A directive on a dependency cycle with other directives in
+// the workspace is reported too, since the build pass refuses to run it.
 func (r *Rule) Check(f *lint.File) []lint.Diagnostic {
 	pairs, diags := gensection.FindMarkerPairs(f, r.Name(), r.RuleID(), r.RuleName())
 	for _, mp := range pairs {
 		diags = append(diags, r.checkPair(f, mp)...)
 	}
+	if len(pairs) > 0 {
+		diags = append(diags, r.cycleDiags(f)...)
+	}
 	return diags
 }
 
@@ -434,6 +440,13 @@ func parseRecipesSettings(v any) (map[string]recipeSchema, error) {
 			}
 			schema.BodyTemplate = s
 		}
+		if di, ok := rm["default-inputs"]; ok {
+			s, err := toStringSlice(di)
+			if err != nil {
+				return nil, fmt.Errorf("recipe %q: default-inputs: %w", name, err)
+			}
+			schema.DefaultInputs = s
+		}
 		if rawParams, hasParams := rm["params"]; hasParams {
 			paramsMap, ok := rawParams.(map[string]any)
 			if !ok {
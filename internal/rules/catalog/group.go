@@ -0,0 +1,290 @@
+package catalog
+
+import (
+	"cmp"
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/jeduden/mdsmith/internal/fieldinterp"
+	"github.com/jeduden/mdsmith/internal/lint"
+)
+
+// maxGroupDepth caps `group-by:` at a field and one nested field.
+const maxGroupDepth = 2
+
+// defaultGroupLevel is the heading level of the outermost group when
+// the directive sets no `group-level:`.
+const defaultGroupLevel = 2
+
+// defaultGroupMissing labels the group of entries that have no value
+// for the group field.
+const defaultGroupMissing = "Other"
+
+// groupKey is one parsed `group-by:` entry: the front-matter path to
+// group on and whether its groups are ordered descending (a leading
+// `-`, as in `sort:`).
+type groupKey struct {
+	path       []string
+	descending bool
+}
+
+// entryGroup is one distinct value of a group field with the entries
+// that carry it, in the catalog's sort order.
+type entryGroup struct {
+	value   string
+	missing bool
+	entries []fileEntry
+}
+
+// parseGroupKeys parses the newline-joined `group-by:` list. It
+// returns nil when the directive does not group.
+func parseGroupKeys(params map[string]string) []groupKey {
+	v := strings.TrimSpace(params["group-by"])
+	if v == "" {
+		return nil
+	}
+	var keys []groupKey
+	for _, field := range strings.Split(v, "\n") {
+		field = strings.TrimSpace(field)
+		k := groupKey{}
+		if strings.HasPrefix(field, "-") {
+			k.descending = true
+			field = field[1:]
+		}
+		k.path = fieldinterp.ParseCUEPath(field)
+		keys = append(keys, k)
+	}
+	return keys
+}
+
+// validateGrouping checks group-by, group-level, group-heading, limit,
+// and offset. It does not read any matched file.
+func validateGrouping(filePath string, line int, params map[string]string) []lint.Diagnostic {
+	if v, ok := params["group-by"]; ok {
+		fields := strings.Split(strings.TrimSpace(v), "\n")
+		if len(fields) > maxGroupDepth {
+			return []lint.Diagnostic{makeDiag(filePath, line,
+				fmt.Sprintf(`generated section directive "group-by" takes at most %d fields`, maxGroupDepth))}
+		}
+		for _, field := range fields {
+			field = strings.TrimPrefix(strings.TrimSpace(field), "-")
+			if fieldinterp.ParseCUEPath(field) == nil {
+				return []lint.Diagnostic{makeDiag(filePath, line,
+					fmt.Sprintf(`generated section directive has invalid "group-by" field %q`, field))}
+			}
+		}
+		level, err := groupLevel(params)
+		if err != nil || level+len(fields)-1 > 6 {
+			return []lint.Diagnostic{makeDiag(filePath, line,
+				`generated section directive has invalid "group-level" value; `+
+					`must be an integer from 1 to 6 that leaves room for nested groups`)}
+		}
+		if err := fieldinterp.Validate(params["group-heading"]); err != nil {
+			return []lint.Diagnostic{makeDiag(filePath, line,
+				fmt.Sprintf(`generated section directive has invalid "group-heading" template: %v`, err))}
+		}
+	} else {
+		for _, k := range []string{"group-level", "group-heading", "group-missing"} {
+			if _, has := params[k]; has {
+				return []lint.Diagnostic{makeDiag(filePath, line,
+					fmt.Sprintf(`generated section directive "%s" requires "group-by"`, k))}
+			}
+		}
+	}
+	if _, _, err := pageBounds(params); err != nil {
+		return []lint.Diagnostic{makeDiag(filePath, line,
+			fmt.Sprintf("generated section directive has invalid %v", err))}
+	}
+	return nil
+}
+
+// groupLevel returns the heading level of the outermost group.
+func groupLevel(params map[string]string) (int, error) {
+	v, ok := params["group-level"]
+	if !ok {
+		return defaultGroupLevel, nil
+	}
+	n, err := strconv.Atoi(strings.TrimSpace(v))
+	if err != nil || n < 1 || n > 6 {
+		return 0, fmt.Errorf("must be an integer from 1 to 6")
+	}
+	return n, nil
+}
+
+// pageBounds parses `offset:` and `limit:`. limit is 0 when unset.
+// The error names the offending parameter.
+func pageBounds(params map[string]string) (offset, limit int, err error) {
+	if v, ok := params["offset"]; ok {
+		offset, err = strconv.Atoi(strings.TrimSpace(v))
+		if err != nil || offset < 0 {
+			return 0, 0, fmt.Errorf(`"offset" value; must be a non-negative integer`)
+		}
+	}
+	if v, ok := params["limit"]; ok {
+		limit, err = strconv.Atoi(strings.TrimSpace(v))
+		if err != nil || limit < 1 {
+			return 0, 0, fmt.Errorf(`"limit" value; must be a positive integer`)
+		}
+	}
+	return offset, limit, nil
+}
+
+// paginate applies `offset:` and `limit:` to the sorted, filtered
+// entries. The result is a subslice, so the memoised entries shared
+// with the other Check passes are never modified.
+func paginate(params map[string]string, entries []fileEntry) []fileEntry {
+	offset, limit, err := pageBounds(params)
+	if err != nil {
+		return entries // Validate already reports the bad value
+	}
+	if offset >= len(entries) {
+		return nil
+	}
+	entries = entries[offset:]
+	if limit > 0 && limit < len(entries) {
+		entries = entries[:limit]
+	}
+	return entries
+}
+
+// groupEntries splits entries by the value of key. An entry whose
+// value is a list joins the group of every element, so `group-by:
+// tags` lists a post under each of its tags. Groups are ordered by
+// value, numerically when every value is an integer; the group of
+// entries with no value comes last. Entries keep their sort order
+// inside each group.
+func groupEntries(entries []fileEntry, key groupKey) []entryGroup {
+	index := map[string]int{}
+	var groups []entryGroup
+	var missing []fileEntry
+	for _, e := range entries {
+		values := groupValues(e.fields, key.path)
+		if len(values) == 0 {
+			missing = append(missing, e)
+			continue
+		}
+		for _, v := range values {
+			i, ok := index[v]
+			if !ok {
+				i = len(groups)
+				index[v] = i
+				groups = append(groups, entryGroup{value: v})
+			}
+			groups[i].entries = append(groups[i].entries, e)
+		}
+	}
+	sortGroups(groups, key.descending)
+	if len(missing) > 0 {
+		groups = append(groups, entryGroup{missing: true, entries: missing})
+	}
+	return groups
+}
+
+// sortGroups orders groups by value, numerically when every value
+// parses as an integer and case-insensitively otherwise.
+func sortGroups(groups []entryGroup, descending bool) {
+	numeric := true
+	for _, g := range groups {
+		if _, err := strconv.ParseInt(g.value, 10, 64); err != nil {
+			numeric = false
+			break
+		}
+	}
+	slices.SortStableFunc(groups, func(a, b entryGroup) int {
+		var c int
+		if numeric {
+			x, _ := strconv.ParseInt(a.value, 10, 64)
+			y, _ := strconv.ParseInt(b.value, 10, 64)
+			c = cmp.Compare(x, y)
+		} else {
+			c = cmp.Compare(strings.ToLower(a.value), strings.ToLower(b.value))
+		}
+		if descending {
+			c = -c
+		}
+		return c
+	})
+}
+
+// groupValues returns the distinct non-empty values the entry has at
+// path: one for a scalar, one per element for a list.
+func groupValues(fields map[string]any, path []string) []string {
+	var cur any = fields
+	for _, seg := range path {
+		m, ok := cur.(map[string]any)
+		if !ok {
+			return nil
+		}
+		cur = m[seg]
+	}
+	list, isList := cur.([]any)
+	if !isList {
+		list = []any{cur}
+	}
+	var out []string
+	for _, item := range list {
+		s := strings.TrimSpace(fieldinterp.Stringify(item))
+		if s != "" && !slices.Contains(out, s) {
+			out = append(out, s)
+		}
+	}
+	return out
+}
+
+// renderGrouped renders entries as one headed block per group,
+// nesting a second `group-by:` field one heading level deeper. Each
+// block holds what the ungrouped catalog would render for the
+// group's entries. Headings and blocks are separated by blank lines,
+// and the whole body is wrapped in them.
+func renderGrouped(
+	params map[string]string, entries []fileEntry, keys []groupKey,
+	cols map[string]columnConfig, hasRow bool,
+) (string, error) {
+	level, _ := groupLevel(params)
+	var b strings.Builder
+	b.WriteByte('\n')
+	if err := writeGroups(&b, params, entries, keys, level, cols, hasRow); err != nil {
+		return "", err
+	}
+	return b.String(), nil
+}
+
+func writeGroups(
+	b *strings.Builder, params map[string]string, entries []fileEntry,
+	keys []groupKey, level int, cols map[string]columnConfig, hasRow bool,
+) error {
+	heading := params["group-heading"]
+	if heading == "" {
+		heading = "{value}"
+	}
+	missingLabel, ok := params["group-missing"]
+	if !ok {
+		missingLabel = defaultGroupMissing
+	}
+	for _, g := range groupEntries(entries, keys[0]) {
+		value := g.value
+		if g.missing {
+			value = missingLabel
+		}
+		title := fieldinterp.Interpolate(heading, map[string]any{
+			"value": value,
+			"count": len(g.entries),
+		})
+		b.WriteString(strings.Repeat("#", level) + " " + strings.TrimSpace(title) + "\n\n")
+		if len(keys) > 1 {
+			if err := writeGroups(b, params, g.entries, keys[1:], level+1, cols, hasRow); err != nil {
+				return err
+			}
+			continue
+		}
+		body, err := renderCatalogContent(params, g.entries, cols, hasRow)
+		if err != nil {
+			return err
+		}
+		b.WriteString(body)
+		b.WriteByte('\n')
+	}
+	return nil
+}
@@ -0,0 +1,110 @@
+package catalog
+
+import (
+	"strings"
+	"testing"
+	"testing/fstest"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// groupFS holds four posts with year, status, and tags front matter.
+// d.md has no status, so it lands in the missing-value group.
+var groupFS = fstest.MapFS{
+	"posts/a.md": {Data: []byte("---\ntitle: Alpha\nyear: 2024\nstatus: draft\ntags: [go, cli]\n---\n# A\n")},
+	"posts/b.md": {Data: []byte("---\ntitle: Beta\nyear: 2025\nstatus: done\ntags: [go]\n---\n# B\n")},
+	"posts/c.md": {Data: []byte("---\ntitle: Gamma\nyear: 9\nstatus: done\n---\n# C\n")},
+	"posts/d.md": {Data: []byte("---\ntitle: Delta\nyear: 2025\n---\n# D\n")},
+}
+
+// fixCatalog runs Fix on a catalog directive with the given parameter
+// lines and returns the regenerated body between the markers.
+func fixCatalog(t *testing.T, params string) string {
+	t.Helper()
+	src := "<?catalog\nglob: \"posts/*.md\"\n" + params + "?>\n<?/catalog?>\n"
+	f := newTestFile(t, "index.md", src, groupFS)
+	out := string(newDefaultRule().Fix(f))
+	start := strings.Index(out, "?>\n") + len("?>\n")
+	end := strings.LastIndex(out, "<?/catalog?>")
+	return out[start:end]
+}
+
+func TestGroupBy_ListRowsUnderHeadings(t *testing.T) {
+	got := fixCatalog(t, "row: \"- {title}\"\ngroup-by: status\n")
+	want := "\n## done\n\n- Beta\n- Gamma\n\n## draft\n\n- Alpha\n\n## Other\n\n- Delta\n\n"
+	assert.Equal(t, want, got)
+}
+
+func TestGroupBy_NumericDescendingWithCount(t *testing.T) {
+	got := fixCatalog(t, "row: \"- {title}\"\ngroup-by: -year\n"+
+		"group-level: 3\ngroup-heading: \"{value} ({count})\"\n")
+	want := "\n### 2025 (2)\n\n- Beta\n- Delta\n\n### 2024 (1)\n\n- Alpha\n\n### 9 (1)\n\n- Gamma\n\n"
+	assert.Equal(t, want, got)
+}
+
+func TestGroupBy_ListValueJoinsEveryGroup(t *testing.T) {
+	got := fixCatalog(t, "row: \"- {title}\"\ngroup-by: tags\ngroup-missing: Untagged\n")
+	want := "\n## cli\n\n- Alpha\n\n## go\n\n- Alpha\n- Beta\n\n## Untagged\n\n- Gamma\n- Delta\n\n"
+	assert.Equal(t, want, got)
+}
+
+func TestGroupBy_NestedFieldOneLevelDeeper(t *testing.T) {
+	got := fixCatalog(t, "row: \"- {title}\"\ngroup-by: [-year, status]\nwhere: 'year: >2000'\n")
+	want := "\n## 2025\n\n### done\n\n- Beta\n\n### Other\n\n- Delta\n\n" +
+		"## 2024\n\n### draft\n\n- Alpha\n\n"
+	assert.Equal(t, want, got)
+}
+
+func TestGroupBy_TableRepeatsHeaderPerGroup(t *testing.T) {
+	got := fixCatalog(t, "header: |\n  | Title |\n  |-------|\n"+
+		"row: \"| {title} |\"\ngroup-by: status\nwhere: 'status: \"done\"'\n")
+	want := "\n## done\n\n| Title |\n| ----- |\n| Beta  |\n| Gamma |\n\n"
+	assert.Equal(t, want, got)
+}
+
+func TestLimitOffset_PageOfSortedEntries(t *testing.T) {
+	got := fixCatalog(t, "row: \"- {title}\"\nsort: title\noffset: 1\nlimit: 2\n")
+	assert.Equal(t, "- Beta\n- Delta\n", got)
+}
+
+func TestLimitOffset_AppliesBeforeGrouping(t *testing.T) {
+	got := fixCatalog(t, "row: \"- {title}\"\nsort: title\nlimit: 2\ngroup-by: status\n")
+	want := "\n## done\n\n- Beta\n\n## draft\n\n- Alpha\n\n"
+	assert.Equal(t, want, got)
+}
+
+func TestLimitOffset_OffsetPastEndRendersEmpty(t *testing.T) {
+	got := fixCatalog(t, "row: \"- {title}\"\noffset: 10\nempty: No posts.\n")
+	assert.Equal(t, "No posts.\n", got)
+}
+
+func TestValidateGrouping_Errors(t *testing.T) {
+	tests := []struct {
+		name   string
+		params map[string]string
+		msg    string
+	}{
+		{"too many fields", map[string]string{"group-by": "a\nb\nc"}, `"group-by" takes at most 2 fields`},
+		{"bad level", map[string]string{"group-by": "a", "group-level": "7"}, `invalid "group-level"`},
+		{"nested past h6", map[string]string{"group-by": "a\nb", "group-level": "6"}, `invalid "group-level"`},
+		{"bad heading", map[string]string{"group-by": "a", "group-heading": "{value"}, `invalid "group-heading"`},
+		{"level without group-by", map[string]string{"group-level": "3"}, `"group-level" requires "group-by"`},
+		{"zero limit", map[string]string{"limit": "0"}, `invalid "limit" value`},
+		{"negative offset", map[string]string{"offset": "-1"}, `invalid "offset" value`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			diags := validateGrouping("index.md", 1, tt.params)
+			expectDiags(t, diags, 1)
+			expectDiagMsg(t, diags, tt.msg)
+		})
+	}
+}
+
+func TestValidateGrouping_Valid(t *testing.T) {
+	diags := validateGrouping("index.md", 1, map[string]string{
+		"group-by": "-year\nstatus", "group-level": "5",
+		"group-heading": "{value} ({count})", "limit": "10", "offset": "0",
+	})
+	expectDiags(t, diags, 0)
+}
@@ -6,6 +6,7 @@ import (
 	"errors"
 	"fmt"
 	"io/fs"
+	"maps"
 	"path"
 	"path/filepath"
 	"slices"
@@ -47,6 +48,7 @@ var errCatalogCapExceeded = errors.New("catalog match cap exceeded")
 
 func init() {
 	rule.Register(&Rule{Pad: 1, SeparatorStyle: tablefmt.SeparatorSpaced})
+	gensection.RegisterGenerated("catalog")
 }
 
 // Rule checks that generated sections match their directive output.
@@ -146,7 +148,7 @@ func (r *Rule) Generate(f *lint.File, filePath string, line int,
 	}
 
 	hasRow := hasRowTemplate(params)
-	content, err := renderCatalogContent(params, entries, cols, hasRow)
+	content, err := renderPage(params, paginate(params, entries), cols, hasRow)
 	if err != nil {
 		return "", []lint.Diagnostic{makeDiag(filePath, line,
 			fmt.Sprintf("generated section template execution failed: %v", err))}
@@ -266,6 +268,7 @@ func validateCatalogDirective(
 		diags = append(diags, validateSort(filePath, line, sortVal)...)
 	}
 	diags = append(diags, validateRowExpressions(filePath, line, params)...)
+	diags = append(diags, validateGrouping(filePath, line, params)...)
 	if whereExpr := strings.TrimSpace(params["where"]); whereExpr != "" {
 		if _, err := query.Compile(whereExpr); err != nil {
 			diags = append(diags, makeDiag(filePath, line,
@@ -798,7 +801,8 @@ func buildCatalogEntries(
 	sortKey, descending, numeric := parseSort(params)
 	hasRow := hasRowTemplate(params)
 	whereExpr := strings.TrimSpace(params["where"])
-	needFM := hasRow || whereExpr != "" || (sortKey != "path" && sortKey != "filename")
+	needFM := hasRow || whereExpr != "" || params["group-by"] != "" ||
+		(sortKey != "path" && sortKey != "filename")
 
 	var matcher *query.Matcher
 	if whereExpr != "" {
@@ -831,7 +835,20 @@ func buildCatalogEntries(
 				fields[k] = v
 			}
 		}
-		if matcher != nil && !matcher.Match(fm) {
+		matchFM := fm
+		if matcher != nil && matcher.UsesDoc() {
+			absPath, _ := absMatchedPath(res, p)
+			doc, err := cachedDocFacts(f, res.fs, p, absPath, f.MaxInputBytes)
+			if err != nil {
+				diags = append(diags, makeDiag(filePath, line,
+					fmt.Sprintf("cannot read %q: %v", displayPath, err)))
+				continue
+			}
+			matchFM = make(map[string]any, len(fm)+1)
+			maps.Copy(matchFM, fm)
+			matchFM[query.DocKey] = doc
+		}
+		if matcher != nil && !matcher.Match(matchFM) {
 			continue
 		}
 		entries = append(entries, fileEntry{fields: fields, matchPath: p})
@@ -1035,6 +1052,19 @@ func (r *Rule) checkInjection(f *lint.File) []lint.Diagnostic {
 	return diags
 }
 
+// renderPage renders one page of catalog entries, split into headed
+// groups when the directive sets `group-by:`.
+func renderPage(
+	params map[string]string, entries []fileEntry,
+	cols map[string]columnConfig, hasRow bool,
+) (string, error) {
+	keys := parseGroupKeys(params)
+	if len(keys) == 0 || len(entries) == 0 {
+		return renderCatalogContent(params, entries, cols, hasRow)
+	}
+	return renderGrouped(params, entries, keys, cols, hasRow)
+}
+
 // renderCatalogContent renders catalog entries into the final content string.
 func renderCatalogContent(
 	params map[string]string, entries []fileEntry,
@@ -1217,6 +1247,35 @@ func cachedFrontMatter(
 	return r.fm, r.err
 }
 
+// docFactsResult holds one query.Doc result for the caches.
+type docFactsResult struct {
+	doc map[string]any
+	err error
+}
+
+// cachedDocFacts returns the document facts a where: expression naming
+// $doc reads, cached like cachedFrontMatter. The run-wide slot is a
+// corpus slot, so any content edit drops it.
+func cachedDocFacts(
+	f *lint.File, fsys fs.FS, path, absPath string, maxBytes int64,
+) (map[string]any, error) {
+	build := func() any {
+		data, err := bytelimit.ReadFSFileLimited(fsys, path, maxBytes)
+		if err != nil {
+			return docFactsResult{err: err}
+		}
+		return docFactsResult{doc: query.Doc(path, data)}
+	}
+	var v any
+	if f.RunCache != nil && absPath != "" {
+		v = f.RunCache.CorpusIndex("catalog.doc\x00"+absPath, build)
+	} else {
+		v = f.Memo("catalog.doc:"+path, build)
+	}
+	r := v.(docFactsResult)
+	return r.doc, r.err
+}
+
 // absMatchedPath returns the absolute filesystem path of a doublestar
 // match m, using the absolute base resolveGlobFS already computed for
 // gitignore anchoring. Returns ("", false) when no base is available
@@ -4468,6 +4468,28 @@ row: "- [{title}]({filename})"
 	expectDiags(t, diags, 0)
 }
 
+func TestWhere_DocFacts(t *testing.T) {
+	// where: reads $doc, so files are selected by content: here, by
+	// the words under a heading, one file without front matter at all.
+	src := `<?catalog
+glob: "docs/*.md"
+where: '$doc: sections: Usage: words: >2'
+row: "- {filename}"
+?>
+- docs/bare.md
+- docs/long.md
+<?/catalog?>
+`
+	mapFS := fstest.MapFS{
+		"docs/bare.md":  {Data: []byte("# Bare\n\n## Usage\n\nRun the tool twice.\n")},
+		"docs/long.md":  {Data: []byte("---\ntitle: Long\n---\n# Long\n\n## Usage\n\nCall it with flags.\n")},
+		"docs/short.md": {Data: []byte("---\ntitle: Short\n---\n# Short\n\n## Usage\n\nRun.\n")},
+		"docs/none.md":  {Data: []byte("---\ntitle: None\n---\n# None\n")},
+	}
+	f := newTestFile(t, "index.md", src, mapFS)
+	expectDiags(t, (&Rule{}).Check(f), 0)
+}
+
 func TestWhere_SelectsNothing(t *testing.T) {
 	// where: selects no files; with no `empty:` the body is blank.
 	src := `<?catalog
@@ -0,0 +1,54 @@
+package codeblocksyntax
+
+import "sync"
+
+// maxCachedBlocks bounds the verdict cache; a full cache starts over.
+const maxCachedBlocks = 1024
+
+// verdictCache remembers the parse verdict of each block content, so
+// a block that recurs — in an LSP session re-checking the document on
+// every edit, or through includes — is parsed once. It lives on the
+// rule instance behind a pointer: clones share it, and ApplySettings
+// swaps in a fresh one because the masks depend on the settings.
+type verdictCache struct {
+	mu sync.RWMutex
+	m  map[string]map[string]*syntaxError // lang → content → nil when it parses
+	n  int
+}
+
+func newVerdictCache() *verdictCache {
+	return &verdictCache{m: map[string]map[string]*syntaxError{}}
+}
+
+// get returns the cached verdict for src in lang.
+func (c *verdictCache) get(lang string, src []byte) (*syntaxError, bool) {
+	if c == nil {
+		return nil, false
+	}
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	e, ok := c.m[lang][string(src)]
+	return e, ok
+}
+
+// put records the verdict for src in lang.
+func (c *verdictCache) put(lang string, src []byte, e *syntaxError) {
+	if c == nil {
+		return
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.n >= maxCachedBlocks {
+		clear(c.m)
+		c.n = 0
+	}
+	byLang := c.m[lang]
+	if byLang == nil {
+		byLang = map[string]*syntaxError{}
+		c.m[lang] = byLang
+	}
+	if _, ok := byLang[string(src)]; !ok {
+		c.n++
+	}
+	byLang[string(src)] = e
+}
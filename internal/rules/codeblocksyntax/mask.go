@@ -0,0 +1,137 @@
+package codeblocksyntax
+
+import (
+	"bytes"
+	"regexp"
+
+	"github.com/jeduden/mdsmith/internal/placeholders"
+)
+
+// Every mask below overwrites bytes in place and keeps newlines, so a
+// parser's line and column on the masked copy hold for the original.
+
+// elisionRE matches a `...` or `…` elision with the commas around it.
+var elisionRE = regexp.MustCompile(`(,[ \t]*)?(?:\.\.\.|…)([ \t]*,)?`)
+
+// elides reports whether `...` is an elision in lang rather than syntax:
+// YAML reads it as a document end or a plain scalar, and CUE as an
+// open list or struct.
+func elides(lang string) bool {
+	return lang != "yaml" && lang != "cue"
+}
+
+// mask returns src with tolerated elisions and placeholders neutralised
+// and whether it masked anything; src itself comes back unmasked. A
+// masked value becomes "0" padded with spaces; an elision between list
+// items keeps one comma.
+func (r *Rule) mask(lang string, src []byte) ([]byte, bool) {
+	var out []byte
+	ensure := func() {
+		if out == nil {
+			out = append([]byte(nil), src...)
+		}
+	}
+	if r.Elisions && elides(lang) && (bytes.Contains(src, []byte("...")) || bytes.Contains(src, []byte("…"))) {
+		for _, m := range elisionRE.FindAllSubmatchIndex(src, -1) {
+			ensure()
+			blank(out, m[0], m[1])
+			switch {
+			case m[2] >= 0 && m[4] >= 0:
+				out[m[0]] = ','
+			case m[2] < 0 && valuePosition(src, m[0]):
+				out[m[0]] = '0'
+			}
+		}
+	}
+	if len(r.Placeholders) > 0 {
+		for _, s := range placeholders.BodyTokenSpans(string(src), r.Placeholders) {
+			ensure()
+			blank(out, s[0], s[1])
+			out[s[0]] = '0'
+		}
+	}
+	if out == nil {
+		return src, false
+	}
+	return out, true
+}
+
+// valuePosition reports whether the text before off ends in `:` or `=`,
+// so a value belongs at off.
+func valuePosition(src []byte, off int) bool {
+	i := off - 1
+	for i >= 0 && (src[i] == ' ' || src[i] == '\t') {
+		i--
+	}
+	return i >= 0 && (src[i] == ':' || src[i] == '=')
+}
+
+// blank overwrites src[start:end] with spaces, keeping newlines.
+func blank(src []byte, start, end int) {
+	for i := start; i < end; i++ {
+		if src[i] != '\n' {
+			src[i] = ' '
+		}
+	}
+}
+
+// blankJSONC returns a copy of src with // and /* */ comments and
+// trailing commas blanked, outside string literals. Comments go
+// first, so a comma before a trailing comment is still seen as
+// trailing.
+func blankJSONC(src []byte) []byte {
+	out := append([]byte(nil), src...)
+	for i := 0; i < len(out); {
+		switch {
+		case out[i] == '"':
+			i = skipString(out, i)
+		case bytes.HasPrefix(out[i:], []byte("//")):
+			end := i + bytes.IndexByte(out[i:], '\n')
+			if end < i {
+				end = len(out)
+			}
+			blank(out, i, end)
+			i = end
+		case bytes.HasPrefix(out[i:], []byte("/*")):
+			end := bytes.Index(out[i+2:], []byte("*/"))
+			if end < 0 {
+				end = len(out)
+			} else {
+				end += i + 4
+			}
+			blank(out, i, end)
+			i = end
+		default:
+			i++
+		}
+	}
+	for i := 0; i < len(out); {
+		switch out[i] {
+		case '"':
+			i = skipString(out, i)
+		case ',':
+			rest := bytes.TrimLeft(out[i+1:], " \t\r\n")
+			if len(rest) > 0 && (rest[0] == '}' || rest[0] == ']') {
+				out[i] = ' '
+			}
+			i++
+		default:
+			i++
+		}
+	}
+	return out
+}
+
+// skipString returns the index just past the string literal opening
+// at src[i], honouring backslash escapes.
+func skipString(src []byte, i int) int {
+	for i++; i < len(src); i++ {
+		switch src[i] {
+		case '\\':
+			i++
+		case '"':
+			return i + 1
+		}
+	}
+	return len(src)
+}
@@ -0,0 +1,143 @@
+package codeblocksyntax
+
+import (
+	"bytes"
+	"encoding/json"
+	"encoding/xml"
+	"errors"
+	"io"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+
+	"github.com/jeduden/mdsmith/cue/cuelite"
+)
+
+// syntaxError is a parse failure inside a code block. line and col are
+// 1-based within the block's content; col is 0 when the parser reports
+// only a line.
+type syntaxError struct {
+	line int
+	col  int
+	msg  string
+}
+
+// parsers maps each supported language to its syntax check.
+var parsers = map[string]func([]byte) *syntaxError{
+	"json":  parseJSON,
+	"jsonc": parseJSONC,
+	"yaml":  parseYAML,
+	"toml":  parseTOML,
+	"go":    parseGo,
+	"xml":   parseXML,
+	"cue":   parseCUE,
+}
+
+// aliases maps other info-string spellings to a supported language.
+var aliases = map[string]string{
+	"yml":    "yaml",
+	"golang": "go",
+}
+
+// languages lists the supported languages in a stable order.
+var languages = []string{"cue", "go", "json", "jsonc", "toml", "xml", "yaml"}
+
+func parseJSON(src []byte) *syntaxError {
+	if json.Valid(src) {
+		return nil
+	}
+	var v any
+	err := json.Unmarshal(src, &v)
+	var se *json.SyntaxError
+	if !errors.As(err, &se) {
+		return &syntaxError{line: 1, msg: errMsg(err)}
+	}
+	// Offset counts the bytes read, including the offending one.
+	off := int(se.Offset) - 1
+	if off < 0 {
+		off = 0
+	}
+	line, col := lineCol(src, off)
+	return &syntaxError{line: line, col: col, msg: se.Error()}
+}
+
+// parseJSONC blanks comments and trailing commas, which keeps every
+// offset in place, then checks the rest as JSON.
+func parseJSONC(src []byte) *syntaxError {
+	return parseJSON(blankJSONC(src))
+}
+
+// parseYAML decodes each document into a node tree, which neither
+// expands aliases nor binds types, so only syntax is checked. yaml.v3
+// reports a line, never a column.
+func parseYAML(src []byte) *syntaxError {
+	dec := yaml.NewDecoder(bytes.NewReader(src))
+	for {
+		var doc yaml.Node
+		err := dec.Decode(&doc)
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			msg := strings.TrimPrefix(err.Error(), "yaml: ")
+			if m := yamlLineRE.FindStringSubmatch(msg); m != nil {
+				line, _ := strconv.Atoi(m[1])
+				return &syntaxError{line: line, msg: m[2]}
+			}
+			return &syntaxError{line: 1, msg: msg}
+		}
+	}
+}
+
+var yamlLineRE = regexp.MustCompile(`^line (\d+): (.*)$`)
+
+// parseXML reads every token. Its errors carry a line only.
+func parseXML(src []byte) *syntaxError {
+	dec := xml.NewDecoder(bytes.NewReader(src))
+	// The declared encoding does not change the syntax.
+	dec.CharsetReader = func(_ string, r io.Reader) (io.Reader, error) { return r, nil }
+	for {
+		_, err := dec.Token()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			var se *xml.SyntaxError
+			if errors.As(err, &se) {
+				return &syntaxError{line: se.Line, msg: se.Msg}
+			}
+			return &syntaxError{line: 1, msg: errMsg(err)}
+		}
+	}
+}
+
+// parseCUE checks the CUE subset mdsmith itself reads: schemas,
+// query filters, and catalog rows. The parser reports no position.
+func parseCUE(src []byte) *syntaxError {
+	err := cuelite.Parse(string(src))
+	if err == nil {
+		return nil
+	}
+	return &syntaxError{line: 1, msg: errMsg(err)}
+}
+
+// errMsg drops the package prefix a parser puts on its errors.
+func errMsg(err error) string {
+	msg := err.Error()
+	for _, p := range []string{"cuelite: ", "json: ", "xml: "} {
+		msg = strings.TrimPrefix(msg, p)
+	}
+	return msg
+}
+
+// lineCol converts a byte offset in src to a 1-based line and column.
+func lineCol(src []byte, off int) (int, int) {
+	if off > len(src) {
+		off = len(src)
+	}
+	line := 1 + bytes.Count(src[:off], []byte{'\n'})
+	start := bytes.LastIndexByte(src[:off], '\n') + 1
+	return line, off - start + 1
+}
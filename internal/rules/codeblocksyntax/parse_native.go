@@ -0,0 +1,114 @@
+//go:build !(js && wasm)
+
+package codeblocksyntax
+
+import (
+	"bytes"
+	"errors"
+	"go/format"
+	"go/parser"
+	"go/scanner"
+	"go/token"
+	"regexp"
+	"strconv"
+	"strings"
+
+	toml "github.com/pelletier/go-toml"
+)
+
+func parseTOML(src []byte) *syntaxError {
+	_, err := toml.LoadBytes(src)
+	if err == nil {
+		return nil
+	}
+	msg := err.Error()
+	if m := tomlPosRE.FindStringSubmatch(msg); m != nil {
+		line, _ := strconv.Atoi(m[1])
+		col, _ := strconv.Atoi(m[2])
+		return &syntaxError{line: line, col: col, msg: m[3]}
+	}
+	return &syntaxError{line: 1, msg: msg}
+}
+
+var tomlPosRE = regexp.MustCompile(`^\((\d+), (\d+)\): (.*)$`)
+
+// Prefixes that turn a Go fragment into a file. Both stay on the first
+// line, so only first-line columns shift.
+const (
+	goDeclPrefix = "package p;"
+	goStmtPrefix = "package p; func _() {"
+)
+
+// parseGo accepts a whole file, a declaration list, or a statement
+// list, the fragments go/format accepts. A source without a package
+// clause is tried as declarations, then as statements.
+func parseGo(src []byte) *syntaxError {
+	const mode = parser.SkipObjectResolution
+	fset := token.NewFileSet()
+	if hasPackageClause(src) {
+		_, err := parser.ParseFile(fset, "", src, mode)
+		return goError(err, 0, src)
+	}
+	_, err := parser.ParseFile(fset, "", append([]byte(goDeclPrefix), src...), mode)
+	if err == nil || !strings.Contains(err.Error(), "expected declaration") {
+		return goError(err, len(goDeclPrefix), src)
+	}
+	stmts := append(append([]byte(goStmtPrefix), src...), '\n', '}')
+	_, err = parser.ParseFile(fset, "", stmts, mode)
+	return goError(err, len(goStmtPrefix), src)
+}
+
+// hasPackageClause reports whether the first token of src, past
+// comments, is the package keyword.
+func hasPackageClause(src []byte) bool {
+	for {
+		src = bytes.TrimLeft(src, " \t\r\n")
+		switch {
+		case bytes.HasPrefix(src, []byte("//")):
+			i := bytes.IndexByte(src, '\n')
+			if i < 0 {
+				return false
+			}
+			src = src[i:]
+		case bytes.HasPrefix(src, []byte("/*")):
+			i := bytes.Index(src[2:], []byte("*/"))
+			if i < 0 {
+				return false
+			}
+			src = src[i+4:]
+		default:
+			rest, ok := bytes.CutPrefix(src, []byte("package"))
+			return ok && len(rest) > 0 && (rest[0] == ' ' || rest[0] == '\t')
+		}
+	}
+}
+
+// goError maps the first parse error, if any, back onto src, undoing
+// the prefix on the first line. An error past the end of src, such as
+// a missing closing brace, sits at src's end.
+func goError(err error, prefix int, src []byte) *syntaxError {
+	if err == nil {
+		return nil
+	}
+	var list scanner.ErrorList
+	if !errors.As(err, &list) || len(list) == 0 {
+		return &syntaxError{line: 1, msg: errMsg(err)}
+	}
+	pos := list[0].Pos
+	line, col := pos.Line, pos.Column
+	if line == 1 {
+		col -= prefix
+	}
+	if last, end := lineCol(src, len(src)); line > last || (line == last && col > end) {
+		line, col = last, end
+	}
+	if col < 1 {
+		col = 1
+	}
+	return &syntaxError{line: line, col: col, msg: list[0].Msg}
+}
+
+// formatGo returns src the way gofmt writes it.
+func formatGo(src []byte) ([]byte, error) {
+	return format.Source(src)
+}
@@ -0,0 +1,17 @@
+//go:build js && wasm
+
+package codeblocksyntax
+
+import "errors"
+
+// The WebAssembly build leaves out the Go and TOML parsers to keep the
+// artifact small; blocks in those languages pass unchecked there.
+
+func parseTOML(_ []byte) *syntaxError { return nil }
+
+func parseGo(_ []byte) *syntaxError { return nil }
+
+// formatGo reports that Go formatting is unavailable.
+func formatGo(_ []byte) ([]byte, error) {
+	return nil, errors.New("go formatting is not available in the WebAssembly build")
+}
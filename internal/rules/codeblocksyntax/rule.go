@@ -0,0 +1,363 @@
+// Package codeblocksyntax implements MDS083, which parses fenced code
+// blocks tagged json, jsonc, yaml, toml, go, xml, or cue and reports
+// each parse error at its line and column in the Markdown file. Fix
+// reformats valid Go and JSON blocks when the format setting asks.
+package codeblocksyntax
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/jeduden/mdsmith/internal/lint"
+	"github.com/jeduden/mdsmith/internal/placeholders"
+	"github.com/jeduden/mdsmith/internal/rule"
+	"github.com/jeduden/mdsmith/internal/rules/settings"
+	"github.com/jeduden/mdsmith/pkg/goldmark/ast"
+	"github.com/jeduden/mdsmith/pkg/goldmark/text"
+)
+
+func init() {
+	rule.Register(&Rule{Languages: languages, Elisions: true, cache: newVerdictCache()})
+}
+
+// Rule reports syntax errors in fenced code blocks.
+type Rule struct {
+	Languages    []string // languages to check
+	Placeholders []string // placeholder tokens tolerated in code
+	Elisions     bool     // tolerate `...` and `…` elisions
+	Format       []string // languages Fix reformats: go, json
+
+	cache *verdictCache
+}
+
+// ID implements rule.Rule.
+func (r *Rule) ID() string { return "MDS083" }
+
+// Name implements rule.Rule.
+func (r *Rule) Name() string { return "code-block-syntax" }
+
+// Category implements rule.Rule.
+func (r *Rule) Category() string { return "code" }
+
+// EnabledByDefault implements rule.Defaultable. Many documents show
+// partial snippets on purpose, so the rule is opt-in.
+func (r *Rule) EnabledByDefault() bool { return false }
+
+// Check implements rule.Rule.
+func (r *Rule) Check(f *lint.File) []lint.Diagnostic {
+	if f == nil || f.AST == nil {
+		return nil
+	}
+	return rule.WalkNodes(r, f)
+}
+
+// CheckNode implements rule.NodeChecker.
+func (r *Rule) CheckNode(n ast.Node, entering bool, f *lint.File) []lint.Diagnostic {
+	if !entering {
+		return nil
+	}
+	fcb, ok := n.(*ast.FencedCodeBlock)
+	if !ok {
+		return nil
+	}
+	lang, src := r.block(f, fcb)
+	if lang == "" {
+		return nil
+	}
+	e, ok := r.cache.get(lang, src)
+	if !ok {
+		masked, _ := r.mask(lang, src)
+		e = parsers[lang](masked)
+		r.cache.put(lang, src, e)
+	}
+	if e == nil {
+		return nil
+	}
+	line, col := position(f, fcb.Lines(), e)
+	return []lint.Diagnostic{{
+		File:     f.Path,
+		Line:     line,
+		Column:   col,
+		RuleID:   r.ID(),
+		RuleName: r.Name(),
+		Severity: lint.Warning,
+		Message:  fmt.Sprintf("%s syntax error: %s", lang, e.msg),
+	}}
+}
+
+// block returns the checked language of fcb and its content, or ""
+// when the rule skips the block.
+func (r *Rule) block(f *lint.File, fcb *ast.FencedCodeBlock) (string, []byte) {
+	segs := fcb.Lines()
+	if fcb.Info == nil || segs.Len() == 0 {
+		return "", nil
+	}
+	lang := infoLanguage(fcb.Info.Segment.Value(f.Source))
+	if !slices.Contains(r.Languages, lang) || inGeneratedRange(f, f.LineOfOffset(segs.At(0).Start)) {
+		return "", nil
+	}
+	src := content(f.Source, segs)
+	if len(bytes.TrimSpace(src)) == 0 {
+		return "", nil
+	}
+	return lang, src
+}
+
+// infoLanguage returns the supported language an info string's first
+// word names, or "". It reads the segment rather than
+// FencedCodeBlock.Language, which caches onto the shared AST.
+func infoLanguage(info []byte) string {
+	if i := bytes.IndexAny(info, " \t"); i >= 0 {
+		info = info[:i]
+	}
+	for _, l := range languages {
+		if strings.EqualFold(string(info), l) {
+			return l
+		}
+	}
+	for alias, l := range aliases {
+		if strings.EqualFold(string(info), alias) {
+			return l
+		}
+	}
+	return ""
+}
+
+// content returns a block's text. A block whose lines sit back to back
+// in the source, with no container prefix, is a slice of it.
+func content(src []byte, segs *text.Segments) []byte {
+	first := segs.At(0)
+	end := first.Stop
+	for i := 1; i < segs.Len(); i++ {
+		seg := segs.At(i)
+		if seg.Start != end || seg.Padding > 0 {
+			return segs.Value(src)
+		}
+		end = seg.Stop
+	}
+	if first.Padding > 0 {
+		return segs.Value(src)
+	}
+	return src[first.Start:end]
+}
+
+// position maps a content position onto the Markdown file. A position
+// past the last line sits at the end of that line; a parser that
+// reports no column points at the line's first character.
+func position(f *lint.File, segs *text.Segments, e *syntaxError) (int, int) {
+	line, col := max(e.line, 1), e.col
+	if line > segs.Len() {
+		line = segs.Len()
+		last := segs.At(line - 1)
+		col = len(bytes.TrimRight(last.Value(f.Source), "\r\n")) + 1
+	}
+	seg := segs.At(line - 1)
+	return f.LineOfOffset(seg.Start), seg.Start - lineStartOf(f.Source, seg.Start) + max(col, 1)
+}
+
+// Fix implements rule.FixableRule. It reformats the valid blocks of
+// each language in Format: Go with go/format, JSON with two-space
+// indentation. A block that needs a mask to parse is left alone, so
+// placeholders and elisions survive.
+func (r *Rule) Fix(f *lint.File) []byte {
+	if f == nil {
+		return nil
+	}
+	if f.AST == nil || len(r.Format) == 0 {
+		return f.Source
+	}
+	type rewrite struct {
+		start, end int // byte range of the content lines in f.Source
+		prefix     []byte
+		text       []byte
+	}
+	var rewrites []rewrite
+	_ = ast.Walk(f.AST, func(n ast.Node, entering bool) (ast.WalkStatus, error) {
+		fcb, ok := n.(*ast.FencedCodeBlock)
+		if !entering || !ok {
+			return ast.WalkContinue, nil
+		}
+		lang, src := r.block(f, fcb)
+		if !slices.Contains(r.Format, lang) {
+			return ast.WalkContinue, nil
+		}
+		if _, masked := r.mask(lang, src); masked {
+			return ast.WalkContinue, nil
+		}
+		out, ok := reformat(lang, src)
+		if !ok || bytes.Equal(out, src) {
+			return ast.WalkContinue, nil
+		}
+		segs := fcb.Lines()
+		first, last := segs.At(0), segs.At(segs.Len()-1)
+		start := lineStartOf(f.Source, first.Start)
+		prefix := f.Source[start:first.Start]
+		for i := 0; i < segs.Len(); i++ {
+			seg := segs.At(i)
+			if seg.Padding > 0 || !bytes.HasPrefix(f.Source[lineStartOf(f.Source, seg.Start):], prefix) {
+				// Tabs or a ragged container prefix: leave the block.
+				return ast.WalkContinue, nil
+			}
+		}
+		rewrites = append(rewrites, rewrite{start: start, end: last.Stop, prefix: prefix, text: out})
+		return ast.WalkContinue, nil
+	})
+	if len(rewrites) == 0 {
+		return f.Source
+	}
+	var buf bytes.Buffer
+	buf.Grow(len(f.Source))
+	prev := 0
+	for _, rw := range rewrites {
+		buf.Write(f.Source[prev:rw.start])
+		for _, l := range bytes.SplitAfter(rw.text, []byte("\n")) {
+			if len(l) == 0 {
+				continue
+			}
+			if len(bytes.TrimSpace(l)) > 0 {
+				buf.Write(rw.prefix)
+			} else {
+				buf.Write(bytes.TrimRight(rw.prefix, " \t"))
+			}
+			buf.Write(l)
+		}
+		prev = rw.end
+	}
+	buf.Write(f.Source[prev:])
+	return buf.Bytes()
+}
+
+// reformat returns the canonical form of a block, ending in a newline,
+// or false when the block does not parse.
+func reformat(lang string, src []byte) ([]byte, bool) {
+	var out []byte
+	switch lang {
+	case "go":
+		b, err := formatGo(src)
+		if err != nil {
+			return nil, false
+		}
+		out = b
+	case "json":
+		var buf bytes.Buffer
+		if err := json.Indent(&buf, src, "", "  "); err != nil {
+			return nil, false
+		}
+		out = buf.Bytes()
+	default:
+		return nil, false
+	}
+	return append(bytes.TrimRight(out, " \t\r\n"), '\n'), true
+}
+
+// lineStartOf returns the offset of the first byte on off's line.
+func lineStartOf(src []byte, off int) int {
+	for off > 0 && src[off-1] != '\n' {
+		off--
+	}
+	return off
+}
+
+// FixTitle implements rule.QuickFixTitler.
+func (r *Rule) FixTitle() string { return "Reformat code block" }
+
+func inGeneratedRange(f *lint.File, line int) bool {
+	for _, gr := range f.GeneratedRanges {
+		if gr.Contains(line) {
+			return true
+		}
+	}
+	return false
+}
+
+// ApplySettings implements rule.Configurable.
+func (r *Rule) ApplySettings(s map[string]any) error {
+	for k, v := range s {
+		switch k {
+		case "languages", "format":
+			list, ok := settings.ToStringSlice(v)
+			if !ok {
+				return fmt.Errorf("code-block-syntax: %s must be a list of strings, got %T", k, v)
+			}
+			allowed := languages
+			if k == "format" {
+				allowed = []string{"go", "json"}
+			}
+			norm := make([]string, 0, len(list))
+			for _, l := range list {
+				l = strings.ToLower(l)
+				if a, ok := aliases[l]; ok {
+					l = a
+				}
+				if !slices.Contains(allowed, l) {
+					return fmt.Errorf("code-block-syntax: %s: unsupported language %q (supported: %s)",
+						k, l, strings.Join(allowed, ", "))
+				}
+				norm = append(norm, l)
+			}
+			if k == "languages" {
+				r.Languages = norm
+			} else {
+				r.Format = norm
+			}
+		case "placeholders":
+			toks, ok := settings.ToStringSlice(v)
+			if !ok {
+				return fmt.Errorf("code-block-syntax: placeholders must be a list of strings, got %T", v)
+			}
+			if err := placeholders.Validate(toks); err != nil {
+				return fmt.Errorf("code-block-syntax: %w", err)
+			}
+			r.Placeholders = toks
+		case "elisions":
+			b, ok := v.(bool)
+			if !ok {
+				return fmt.Errorf("code-block-syntax: elisions must be a boolean, got %T", v)
+			}
+			r.Elisions = b
+		default:
+			return fmt.Errorf("code-block-syntax: unknown setting %q", k)
+		}
+	}
+	r.cache = newVerdictCache()
+	return nil
+}
+
+// DefaultSettings implements rule.Configurable.
+func (r *Rule) DefaultSettings() map[string]any {
+	return map[string]any{
+		"languages":    slices.Clone(languages),
+		"placeholders": []string{},
+		"elisions":     true,
+		"format":       []string{},
+	}
+}
+
+// SettingMergeMode implements rule.ListMerger.
+func (r *Rule) SettingMergeMode(key string) rule.MergeMode {
+	if key == "placeholders" {
+		return rule.MergeAppend
+	}
+	return rule.MergeReplace
+}
+
+// enteringKinds is the static node-kind interest CheckNode declares
+// via rule.KindScopedChecker; package-level so EnteringKinds returns
+// it without allocating.
+var enteringKinds = []ast.NodeKind{ast.KindFencedCodeBlock}
+
+// EnteringKinds implements rule.KindScopedChecker.
+func (r *Rule) EnteringKinds() []ast.NodeKind { return enteringKinds }
+
+var (
+	_ rule.Configurable      = (*Rule)(nil)
+	_ rule.FixableRule       = (*Rule)(nil)
+	_ rule.Defaultable       = (*Rule)(nil)
+	_ rule.NodeChecker       = (*Rule)(nil)
+	_ rule.KindScopedChecker = (*Rule)(nil)
+	_ rule.ListMerger        = (*Rule)(nil)
+	_ rule.QuickFixTitler    = (*Rule)(nil)
+)
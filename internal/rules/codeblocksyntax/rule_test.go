@@ -0,0 +1,150 @@
+package codeblocksyntax
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/jeduden/mdsmith/internal/lint"
+)
+
+func newRule(t *testing.T, s map[string]any) *Rule {
+	t.Helper()
+	r := &Rule{}
+	require.NoError(t, r.ApplySettings(r.DefaultSettings()))
+	require.NoError(t, r.ApplySettings(s))
+	return r
+}
+
+func newFile(t *testing.T, src string) *lint.File {
+	t.Helper()
+	f, err := lint.NewFile("doc.md", []byte(src))
+	require.NoError(t, err)
+	return f
+}
+
+func TestCheck_ReportsPositionInMarkdown(t *testing.T) {
+	tests := []struct {
+		name      string
+		src       string
+		line, col int
+		msg       string
+	}{
+		{"json", "# T\n\n```json\n{\n  \"a\": 1,\n}\n```\n", 6, 1,
+			"json syntax error: invalid character '}' looking for beginning of object key string"},
+		{"yaml line only", "# T\n\n```yaml\na: 1\n b: 2\n```\n", 5, 1,
+			"yaml syntax error: mapping values are not allowed in this context"},
+		{"toml", "# T\n\n```toml\na = 1\nb =\n```\n", 5, 4, "toml syntax error: expecting a value"},
+		{"go statements", "# T\n\n```go\nx := 1\nif x {\n```\n", 5, 7, "go syntax error: expected '}', found 'EOF'"},
+		{"go declaration", "# T\n\n```golang\nfunc f( {}\n```\n", 4, 9, "go syntax error: expected ')', found '{'"},
+		{"xml", "# T\n\n```xml\n<a>\n  <b>\n</a>\n```\n", 6, 1, "xml syntax error: element <b> closed by </a>"},
+		{"cue", "# T\n\n```cue\na: {\n```\n", 4, 1, "cue syntax error: expected '}' to close struct"},
+		{"list item", "# T\n\n- item\n\n  ```json\n  [1 2]\n  ```\n", 6, 6,
+			"json syntax error: invalid character '2' after array element"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			diags := newRule(t, nil).Check(newFile(t, tt.src))
+			require.Len(t, diags, 1)
+			assert.Equal(t, tt.line, diags[0].Line)
+			assert.Equal(t, tt.col, diags[0].Column)
+			assert.Equal(t, tt.msg, diags[0].Message)
+		})
+	}
+}
+
+func TestCheck_ValidAndSkippedBlocks(t *testing.T) {
+	src := "# T\n\n" +
+		"```json\n{\"a\": [1, 2]}\n```\n\n" +
+		"```jsonc\n{\n  // note\n  \"a\": 1, /* x */\n}\n```\n\n" +
+		"```yml\na: [1, 2]\n...\n```\n\n" +
+		"```go\npackage main\n\nfunc main() {}\n```\n\n" +
+		"```xml\n<?xml version=\"1.0\" encoding=\"ISO-8859-1\"?>\n<a/>\n```\n\n" +
+		"```cue\nname: string\nage: >=0 & <150\n```\n\n" +
+		"```python\ndef (\n```\n\n" +
+		"```json\n\n```\n"
+	assert.Empty(t, newRule(t, nil).Check(newFile(t, src)))
+}
+
+func TestCheck_LanguagesSetting(t *testing.T) {
+	src := "# T\n\n```json\n{\n```\n\n```yaml\na: [\n```\n"
+	diags := newRule(t, map[string]any{"languages": []any{"YML"}}).Check(newFile(t, src))
+	require.Len(t, diags, 1)
+	assert.Equal(t, 8, diags[0].Line)
+}
+
+func TestCheck_Elisions(t *testing.T) {
+	src := "# T\n\n```json\n{\n  \"items\": [1, ..., 3],\n  \"more\": ...,\n  ...\n}\n```\n\n" +
+		"```go\nfunc f() {\n\t...\n}\n```\n"
+	assert.Empty(t, newRule(t, nil).Check(newFile(t, src)))
+	diags := newRule(t, map[string]any{"elisions": false}).Check(newFile(t, src))
+	assert.Len(t, diags, 2)
+}
+
+func TestCheck_Placeholders(t *testing.T) {
+	src := "# T\n\n```json\n{\"id\": {id}, \"q\": ${input:q}}\n```\n"
+	require.Len(t, newRule(t, nil).Check(newFile(t, src)), 1)
+	r := newRule(t, map[string]any{"placeholders": []any{"var-token", "apm-input-token"}})
+	assert.Empty(t, r.Check(newFile(t, src)))
+}
+
+func TestFix_ReformatsGoAndJSON(t *testing.T) {
+	r := newRule(t, map[string]any{"format": []any{"go", "json"}})
+	src := "# T\n\n```go\nfunc f(){return}\n```\n\n" +
+		"> ```json\n> {\"a\":[1,2],\n> \"b\":{}}\n> ```\n\n" +
+		"```json\n{\"a\": ...}\n```\n\n" +
+		"```json\n{\n```\n"
+	got := string(r.Fix(newFile(t, src)))
+	assert.Equal(t, "# T\n\n```go\nfunc f() { return }\n```\n\n"+
+		"> ```json\n> {\n>   \"a\": [\n>     1,\n>     2\n>   ],\n>   \"b\": {}\n> }\n> ```\n\n"+
+		"```json\n{\"a\": ...}\n```\n\n"+
+		"```json\n{\n```\n", got)
+}
+
+func TestFix_OffByDefault(t *testing.T) {
+	src := "# T\n\n```json\n{\"a\":1}\n```\n"
+	assert.Equal(t, src, string(newRule(t, nil).Fix(newFile(t, src))))
+}
+
+func TestBlankJSONC(t *testing.T) {
+	src := "{\"u\": \"http://x\", // c\n \"a\": [1, /* y */],\n}"
+	got := string(blankJSONC([]byte(src)))
+	assert.Equal(t, len(src), len(got))
+	assert.Equal(t, "{\"u\": \"http://x\",     \n \"a\": [1         ] \n}", got)
+}
+
+func TestApplySettings_Errors(t *testing.T) {
+	tests := []struct {
+		name string
+		s    map[string]any
+		msg  string
+	}{
+		{"languages type", map[string]any{"languages": "json"}, "must be a list of strings"},
+		{"unknown language", map[string]any{"languages": []any{"python"}}, `unsupported language "python"`},
+		{"format language", map[string]any{"format": []any{"yaml"}}, `unsupported language "yaml"`},
+		{"placeholder token", map[string]any{"placeholders": []any{"nope"}}, "unknown placeholder token"},
+		{"elisions type", map[string]any{"elisions": "yes"}, "must be a boolean"},
+		{"unknown", map[string]any{"strict": true}, `unknown setting "strict"`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := &Rule{}
+			assert.ErrorContains(t, r.ApplySettings(tt.s), tt.msg)
+		})
+	}
+}
+
+func TestCheck_CachesVerdicts(t *testing.T) {
+	r := newRule(t, nil)
+	src := "# T\n\n```json\n{\n```\n"
+	first := r.Check(newFile(t, src))
+	require.Len(t, first, 1)
+	assert.Equal(t, first, r.Check(newFile(t, src)))
+
+	// New settings start a new cache: the elision now fails.
+	src = "# T\n\n```json\n[1, ...]\n```\n"
+	assert.Empty(t, r.Check(newFile(t, src)))
+	require.NoError(t, r.ApplySettings(map[string]any{"elisions": false}))
+	assert.Len(t, r.Check(newFile(t, src)), 1)
+}
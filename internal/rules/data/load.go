@@ -0,0 +1,185 @@
+package data
+
+import (
+	"bytes"
+	"encoding/csv"
+	"fmt"
+	"io/fs"
+	"path"
+	"strings"
+
+	"github.com/jeduden/mdsmith/internal/globpath"
+	"github.com/jeduden/mdsmith/internal/lint"
+	"github.com/jeduden/mdsmith/internal/yamlutil"
+	"gopkg.in/yaml.v3"
+)
+
+// formatExtensions maps a data file extension to the format it implies
+// when the directive has no `format:`.
+var formatExtensions = map[string]string{
+	".csv":  "csv",
+	".tsv":  "tsv",
+	".json": "json",
+	".yaml": "yaml",
+	".yml":  "yaml",
+}
+
+// dataFormat returns the directive's explicit format, or the one the
+// file extension implies. It returns "" when neither names a format.
+func dataFormat(params map[string]string) string {
+	if v, ok := params["format"]; ok {
+		v = strings.ToLower(strings.TrimSpace(v))
+		for _, known := range formatExtensions {
+			if v == known {
+				return v
+			}
+		}
+		return ""
+	}
+	return formatExtensions[strings.ToLower(path.Ext(params["file"]))]
+}
+
+// table is a data file read into records. fields lists every column in
+// the order it first appears; each record maps a field to its value.
+// CSV and TSV values are always strings; JSON and YAML values keep
+// their decoded type so `where:` can compare numbers and booleans.
+type table struct {
+	fields  []string
+	records []map[string]any
+}
+
+// hasField reports whether name is a column of t.
+func (t *table) hasField(name string) bool {
+	for _, f := range t.fields {
+		if f == name {
+			return true
+		}
+	}
+	return false
+}
+
+// resolveDataPath resolves the data file relative to the host file and
+// returns the filesystem and path to read it from. With a project root
+// the path may climb out of the host's directory but not out of the
+// root; without one, ".." is refused outright, as for include.
+func resolveDataPath(f *lint.File, filePath, file string, line int) (fs.FS, string, []lint.Diagnostic) {
+	if f.RootFS == nil {
+		if globpath.ContainsDotDotSegment(file) {
+			return nil, "", []lint.Diagnostic{makeDiag(filePath, line,
+				`data file path contains ".." but project root is not configured`)}
+		}
+		return f.FS, path.Clean(file), nil
+	}
+	resolved := path.Clean(path.Join(path.Dir(filePath), file))
+	if strings.HasPrefix(resolved, "..") {
+		return nil, "", []lint.Diagnostic{makeDiag(filePath, line,
+			`data file path escapes project root`)}
+	}
+	return f.RootFS, resolved, nil
+}
+
+// parseTable decodes raw as format.
+func parseTable(raw []byte, format string) (*table, error) {
+	switch format {
+	case "csv":
+		r := csv.NewReader(bytes.NewReader(bytes.TrimPrefix(raw, []byte("\ufeff"))))
+		rows, err := r.ReadAll()
+		if err != nil {
+			return nil, err
+		}
+		return fromRows(rows)
+	case "tsv":
+		rows, err := splitTSV(bytes.TrimPrefix(raw, []byte("\ufeff")))
+		if err != nil {
+			return nil, err
+		}
+		return fromRows(rows)
+	default:
+		return parseRecords(raw)
+	}
+}
+
+// splitTSV splits tab-separated text into rows of cells. TSV has no
+// quoting, so a quote is an ordinary character; blank lines are
+// skipped. Every row must have as many cells as the first.
+func splitTSV(raw []byte) ([][]string, error) {
+	var rows [][]string
+	for i, line := range strings.Split(string(raw), "\n") {
+		line = strings.TrimSuffix(line, "\r")
+		if line == "" {
+			continue
+		}
+		cells := strings.Split(line, "\t")
+		if len(rows) > 0 && len(cells) != len(rows[0]) {
+			return nil, fmt.Errorf("line %d: wrong number of fields", i+1)
+		}
+		rows = append(rows, cells)
+	}
+	return rows, nil
+}
+
+// fromRows builds a table from delimited rows. The first row names the
+// columns; every later row is one record.
+func fromRows(rows [][]string) (*table, error) {
+	if len(rows) == 0 {
+		return nil, fmt.Errorf("no header row")
+	}
+	header := rows[0]
+	seen := make(map[string]struct{}, len(header))
+	for i, name := range header {
+		header[i] = strings.TrimSpace(name)
+		if header[i] == "" {
+			return nil, fmt.Errorf("column %d has an empty header", i+1)
+		}
+		if _, dup := seen[header[i]]; dup {
+			return nil, fmt.Errorf("column %q appears twice in the header", header[i])
+		}
+		seen[header[i]] = struct{}{}
+	}
+	t := &table{fields: header, records: make([]map[string]any, 0, len(rows)-1)}
+	for _, row := range rows[1:] {
+		rec := make(map[string]any, len(header))
+		for i, v := range row {
+			rec[header[i]] = v
+		}
+		t.records = append(t.records, rec)
+	}
+	return t, nil
+}
+
+// parseRecords reads a JSON or YAML list of objects. JSON is a subset
+// of YAML 1.2, so one decoder serves both, and decoding through
+// yaml.Node keeps the key order the columns default to.
+func parseRecords(raw []byte) (*table, error) {
+	doc, err := yamlutil.UnmarshalNodeSafe(raw)
+	if err != nil {
+		return nil, err
+	}
+	if len(doc.Content) == 0 {
+		return &table{}, nil
+	}
+	list := doc.Content[0]
+	if list.Kind != yaml.SequenceNode {
+		return nil, fmt.Errorf("top level must be a list of objects")
+	}
+	t := &table{records: make([]map[string]any, 0, len(list.Content))}
+	seen := map[string]struct{}{}
+	for i, item := range list.Content {
+		if item.Kind != yaml.MappingNode {
+			return nil, fmt.Errorf("item %d is not an object", i+1)
+		}
+		for k := 0; k+1 < len(item.Content); k += 2 {
+			key := item.Content[k].Value
+			if _, ok := seen[key]; !ok {
+				seen[key] = struct{}{}
+				t.fields = append(t.fields, key)
+			}
+		}
+		var rec map[string]any
+		if err := item.Decode(&rec); err != nil {
+			return nil, fmt.Errorf("item %d: %w", i+1, err)
+		}
+		t.records = append(t.records, rec)
+	}
+	return t, nil
+}
@@ -0,0 +1,86 @@
+package data
+
+import (
+	"strings"
+
+	"github.com/jeduden/mdsmith/internal/archetype/gensection"
+	"github.com/jeduden/mdsmith/internal/fieldinterp"
+)
+
+// cellReplacer makes a value safe inside one table cell: a pipe would
+// end the cell and a line break would end the row.
+var cellReplacer = strings.NewReplacer("|", `\|`, "\r\n", " ", "\n", " ", "\r", " ")
+
+// escapeCell returns v as a single-line cell value.
+func escapeCell(v any) string {
+	return cellReplacer.Replace(fieldinterp.Stringify(v))
+}
+
+// escapeRecord returns a copy of rec with every string, at any depth,
+// escaped for a table cell, so a `row:` placeholder cannot break the
+// row it lands in. Numbers and booleans pass through.
+func escapeRecord(rec map[string]any) map[string]any {
+	out := make(map[string]any, len(rec))
+	for k, v := range rec {
+		out[k] = escapeValue(v)
+	}
+	return out
+}
+
+func escapeValue(v any) any {
+	switch x := v.(type) {
+	case string:
+		return cellReplacer.Replace(x)
+	case map[string]any:
+		return escapeRecord(x)
+	case []any:
+		out := make([]any, len(x))
+		for i, e := range x {
+			out[i] = escapeValue(e)
+		}
+		return out
+	default:
+		return v
+	}
+}
+
+// renderColumns renders records as a table with one column per field,
+// headed by the field names.
+func renderColumns(fields []string, records []map[string]any) string {
+	var b strings.Builder
+	writeRow(&b, len(fields), func(i int) string { return cellReplacer.Replace(fields[i]) })
+	writeRow(&b, len(fields), func(int) string { return "---" })
+	for _, rec := range records {
+		writeRow(&b, len(fields), func(i int) string { return escapeCell(rec[fields[i]]) })
+	}
+	return b.String()
+}
+
+// writeRow writes one "| a | b |" line of n cells.
+func writeRow(b *strings.Builder, n int, cell func(int) string) {
+	b.WriteByte('|')
+	for i := 0; i < n; i++ {
+		b.WriteByte(' ')
+		b.WriteString(cell(i))
+		b.WriteString(" |")
+	}
+	b.WriteByte('\n')
+}
+
+// renderTemplate renders header, one `row:` line per record, and
+// footer, the same shape catalog's row templates produce.
+func renderTemplate(params map[string]string, records []map[string]any) string {
+	var b strings.Builder
+	if header := params["header"]; header != "" {
+		b.WriteString(gensection.EnsureTrailingNewline(header))
+	}
+	row := params["row"]
+	for _, rec := range records {
+		b.WriteString(gensection.EnsureTrailingNewline(
+			fieldinterp.Interpolate(row, escapeRecord(rec))))
+	}
+	if footer := params["footer"]; footer != "" {
+		b.WriteString(gensection.EnsureTrailingNewline(footer))
+	}
+	return b.String()
+}
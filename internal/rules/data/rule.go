@@ -0,0 +1,331 @@
+// Package data implements MDS077, the <?data?> generated-section
+// directive that renders a CSV, TSV, JSON, or YAML file as a
+// Markdown table.
+package data
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+	"sync"
+
+	"github.com/jeduden/mdsmith/internal/archetype/gensection"
+	"github.com/jeduden/mdsmith/internal/bytelimit"
+	"github.com/jeduden/mdsmith/internal/fieldinterp"
+	"github.com/jeduden/mdsmith/internal/lint"
+	"github.com/jeduden/mdsmith/internal/query"
+	"github.com/jeduden/mdsmith/internal/rule"
+	"github.com/jeduden/mdsmith/internal/rules/settings"
+	"github.com/jeduden/mdsmith/internal/rules/tablefmt"
+)
+
+func init() {
+	rule.Register(&Rule{Pad: 1, SeparatorStyle: tablefmt.SeparatorSpaced})
+	gensection.RegisterGenerated("data")
+}
+
+// Rule checks and fixes <?data?>...<?/data?> generated sections.
+//
+// engineOnce serialises lazy engine init; the rule is a registered
+// singleton and the LSP server may call Check from concurrent
+// goroutines.
+//
+// Pad and SeparatorStyle govern the tables this rule emits, for the
+// same reason catalog carries its own copies of MDS025's knobs: the
+// engine applies settings per file in parallel, so reading MDS025's
+// configured state would race.
+type Rule struct {
+	engineOnce     sync.Once
+	engine         *gensection.Engine
+	Pad            int
+	SeparatorStyle tablefmt.SeparatorStyle
+}
+
+// ID implements rule.Rule.
+func (r *Rule) ID() string { return "MDS077" }
+
+// Name implements rule.Rule.
+func (r *Rule) Name() string { return "data" }
+
+// Category implements rule.Rule.
+func (r *Rule) Category() string { return "directive" }
+
+// RuleID implements gensection.Directive.
+func (r *Rule) RuleID() string { return "MDS077" }
+
+// RuleName implements gensection.Directive.
+func (r *Rule) RuleName() string { return "data" }
+
+func (r *Rule) getEngine() *gensection.Engine {
+	r.engineOnce.Do(func() {
+		r.engine = gensection.NewEngine(r)
+	})
+	return r.engine
+}
+
+// Check implements rule.Rule.
+func (r *Rule) Check(f *lint.File) []lint.Diagnostic {
+	if f.FS == nil {
+		return nil
+	}
+	return r.getEngine().Check(f)
+}
+
+// Fix implements rule.FixableRule.
+func (r *Rule) Fix(f *lint.File) []byte {
+	if f.FS == nil {
+		return f.Source
+	}
+	return r.getEngine().Fix(f)
+}
+
+// Validate implements gensection.Directive.
+func (r *Rule) Validate(filePath string, line int,
+	params map[string]string, _ map[string]gensection.ColumnConfig,
+) []lint.Diagnostic {
+	return validateDataDirective(filePath, line, params)
+}
+
+// Generate implements gensection.Directive.
+func (r *Rule) Generate(f *lint.File, filePath string, line int,
+	params map[string]string, _ map[string]gensection.ColumnConfig,
+) (string, []lint.Diagnostic) {
+	filePath = filepath.ToSlash(filePath)
+	file := params["file"]
+	readFS, readPath, diags := resolveDataPath(f, filePath, file, line)
+	if len(diags) > 0 {
+		return "", diags
+	}
+	raw, err := bytelimit.ReadFSFileLimited(readFS, readPath, f.MaxInputBytes)
+	if err != nil {
+		return "", []lint.Diagnostic{makeDiag(filePath, line,
+			fmt.Sprintf("cannot read data file %q: %v", file, err))}
+	}
+	t, err := parseTable(raw, dataFormat(params))
+	if err != nil {
+		return "", []lint.Diagnostic{makeDiag(filePath, line,
+			fmt.Sprintf("cannot parse data file %q: %v", file, err))}
+	}
+
+	fields := t.fields
+	if v, ok := params["fields"]; ok {
+		fields = splitFields(v)
+		if len(t.records) > 0 {
+			for _, name := range fields {
+				if !t.hasField(name) {
+					return "", []lint.Diagnostic{makeDiag(filePath, line,
+						fmt.Sprintf("data file %q has no field %q", file, name))}
+				}
+			}
+		}
+	}
+
+	records := filterRecords(t.records, params["where"])
+	var content string
+	switch {
+	case len(records) == 0 && params["empty"] != "":
+		content = gensection.EnsureTrailingNewline(params["empty"])
+	case params["row"] != "":
+		content = renderTemplate(params, records)
+	case len(fields) > 0:
+		content = renderColumns(fields, records)
+	}
+	if content == "" {
+		return "", nil
+	}
+	content = tablefmt.FormatStringWithConfig(content, tablefmt.Config{
+		Pad:            r.Pad,
+		SeparatorStyle: r.SeparatorStyle,
+	})
+	// Wrap with blank lines so the table satisfies MDS025's
+	// blank-line-around-table check.
+	return "\n" + content + "\n", nil
+}
+
+// filterRecords keeps the records the `where:` expression matches.
+// An empty or invalid expression keeps every record; Validate already
+// reports the invalid one.
+func filterRecords(records []map[string]any, where string) []map[string]any {
+	where = strings.TrimSpace(where)
+	if where == "" {
+		return records
+	}
+	m, err := query.Compile(where)
+	if err != nil {
+		return records
+	}
+	out := make([]map[string]any, 0, len(records))
+	for _, rec := range records {
+		if m.Match(rec) {
+			out = append(out, rec)
+		}
+	}
+	return out
+}
+
+// splitFields splits the newline-joined `fields:` list into trimmed
+// names.
+func splitFields(v string) []string {
+	names := strings.Split(v, "\n")
+	for i := range names {
+		names[i] = strings.TrimSpace(names[i])
+	}
+	return names
+}
+
+// validateDataDirective validates the directive's parameters without
+// reading the data file.
+func validateDataDirective(
+	filePath string, line int, params map[string]string,
+) []lint.Diagnostic {
+	if diags := validateFile(filePath, line, params); len(diags) > 0 {
+		return diags
+	}
+	if diags := validateFields(filePath, line, params); len(diags) > 0 {
+		return diags
+	}
+	if diags := validateRow(filePath, line, params); len(diags) > 0 {
+		return diags
+	}
+	if where := strings.TrimSpace(params["where"]); where != "" {
+		if _, err := query.Compile(where); err != nil {
+			return []lint.Diagnostic{makeDiag(filePath, line,
+				fmt.Sprintf(`data directive has invalid "where" expression: %v`, err))}
+		}
+	}
+	return nil
+}
+
+// validateFile checks the file and format parameters.
+func validateFile(
+	filePath string, line int, params map[string]string,
+) []lint.Diagnostic {
+	file, hasFile := params["file"]
+	switch {
+	case !hasFile || strings.TrimSpace(file) == "":
+		return []lint.Diagnostic{makeDiag(filePath, line,
+			`data directive missing required "file" parameter`)}
+	case filepath.IsAbs(file):
+		return []lint.Diagnostic{makeDiag(filePath, line,
+			"data directive has absolute file path")}
+	case strings.Contains(file, "://"):
+		return []lint.Diagnostic{makeDiag(filePath, line,
+			"data directive has URL scheme in file path")}
+	}
+	if dataFormat(params) != "" {
+		return nil
+	}
+	if _, ok := params["format"]; ok {
+		return []lint.Diagnostic{makeDiag(filePath, line,
+			`data directive "format" must be "csv", "tsv", "json", or "yaml"`)}
+	}
+	return []lint.Diagnostic{makeDiag(filePath, line,
+		fmt.Sprintf(`data directive cannot infer the format of %q; set "format"`, file))}
+}
+
+// validateFields checks that `fields:` names each column once and is
+// not combined with a `row:` template, which picks its own fields.
+func validateFields(
+	filePath string, line int, params map[string]string,
+) []lint.Diagnostic {
+	v, ok := params["fields"]
+	if !ok {
+		return nil
+	}
+	if _, hasRow := params["row"]; hasRow {
+		return []lint.Diagnostic{makeDiag(filePath, line,
+			`data directive "fields" cannot be combined with "row"`)}
+	}
+	seen := map[string]struct{}{}
+	for _, name := range splitFields(v) {
+		if name == "" {
+			return []lint.Diagnostic{makeDiag(filePath, line,
+				`data directive "fields" has an empty entry`)}
+		}
+		if _, dup := seen[name]; dup {
+			return []lint.Diagnostic{makeDiag(filePath, line,
+				fmt.Sprintf(`data directive "fields" lists %q twice`, name))}
+		}
+		seen[name] = struct{}{}
+	}
+	return nil
+}
+
+// validateRow checks the row template and the header and footer that
+// only make sense with one.
+func validateRow(
+	filePath string, line int, params map[string]string,
+) []lint.Diagnostic {
+	row, hasRow := params["row"]
+	_, hasHeader := params["header"]
+	_, hasFooter := params["footer"]
+	if !hasRow {
+		if hasHeader || hasFooter {
+			return []lint.Diagnostic{makeDiag(filePath, line,
+				`data directive "header" and "footer" require "row"`)}
+		}
+		return nil
+	}
+	if strings.TrimSpace(row) == "" {
+		return []lint.Diagnostic{makeDiag(filePath, line,
+			`data directive has empty "row" value`)}
+	}
+	if err := fieldinterp.Validate(row); err != nil {
+		return []lint.Diagnostic{makeDiag(filePath, line,
+			fmt.Sprintf("data directive has invalid row template: %v", err))}
+	}
+	return nil
+}
+
+func makeDiag(file string, line int, msg string) lint.Diagnostic {
+	return lint.Diagnostic{
+		File:     file,
+		Line:     line,
+		Column:   1,
+		RuleID:   "MDS077",
+		RuleName: "data",
+		Severity: lint.Error,
+		Message:  msg,
+	}
+}
+
+// ApplySettings implements rule.Configurable.
+func (r *Rule) ApplySettings(s map[string]any) error {
+	for k, v := range s {
+		switch k {
+		case "pad":
+			n, ok := settings.ToInt(v)
+			if !ok {
+				return fmt.Errorf("data: pad must be an integer, got %T", v)
+			}
+			if n < 0 {
+				return fmt.Errorf("data: pad must be non-negative, got %d", n)
+			}
+			r.Pad = n
+		case "separator-style":
+			style, err := tablefmt.ParseSeparatorStyle(v, "data")
+			if err != nil {
+				return err
+			}
+			r.SeparatorStyle = style
+		default:
+			return fmt.Errorf("data: unknown setting %q", k)
+		}
+	}
+	return nil
+}
+
+// DefaultSettings implements rule.Configurable.
+func (r *Rule) DefaultSettings() map[string]any {
+	return map[string]any{
+		"pad":             1,
+		"separator-style": "spaced",
+	}
+}
+
+var _ rule.FixableRule = (*Rule)(nil)
+
+var _ rule.Configurable = (*Rule)(nil)
+
+// FixTitle implements rule.QuickFixTitler.
+func (r *Rule) FixTitle() string { return "Regenerate data table" }
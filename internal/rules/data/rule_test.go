@@ -0,0 +1,207 @@
+package data
+
+import (
+	"testing"
+	"testing/fstest"
+
+	"github.com/jeduden/mdsmith/internal/lint"
+	"github.com/jeduden/mdsmith/internal/rules/tablefmt"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const matrixCSV = "os,arch,status\n" +
+	"linux,amd64,stable\n" +
+	"darwin,arm64,stable\n" +
+	"windows,amd64,\"beta | preview\"\n"
+
+const matrixYAML = `- name: go
+  version: 1.24
+  tier: 1
+- name: node
+  version: "22"
+  tier: 2
+  notes: LTS
+`
+
+func newRule() *Rule {
+	return &Rule{Pad: 1, SeparatorStyle: tablefmt.SeparatorSpaced}
+}
+
+func newTestFile(t *testing.T, source string, fsys fstest.MapFS) *lint.File {
+	t.Helper()
+	f, err := lint.NewFile("docs/doc.md", []byte(source))
+	require.NoError(t, err)
+	f.FS = fsys
+	f.RootFS = fsys
+	return f
+}
+
+// fixData runs Fix on a single directive with body and returns the
+// generated section body.
+func fixData(t *testing.T, body string, fsys fstest.MapFS) string {
+	t.Helper()
+	src := "# Doc\n\n<?data\n" + body + "?>\nold\n<?/data?>\n"
+	got := string(newRule().Fix(newTestFile(t, src, fsys)))
+	prefix := "# Doc\n\n<?data\n" + body + "?>\n"
+	require.Contains(t, got, prefix)
+	body = got[len(prefix) : len(got)-len("<?/data?>\n")]
+	if body == "" {
+		return body
+	}
+	require.True(t, len(body) >= 2 && body[0] == '\n' && body[len(body)-2:] == "\n\n",
+		"generated body is wrapped in blank lines: %q", body)
+	return body[1 : len(body)-1]
+}
+
+func TestRule_Metadata(t *testing.T) {
+	r := newRule()
+	assert.Equal(t, "MDS077", r.ID())
+	assert.Equal(t, "data", r.Name())
+	assert.Equal(t, "directive", r.Category())
+	assert.NotEmpty(t, r.FixTitle())
+}
+
+func TestFix_CSVAllColumns(t *testing.T) {
+	fsys := fstest.MapFS{"docs/matrix.csv": {Data: []byte(matrixCSV)}}
+	got := fixData(t, "file: matrix.csv\n", fsys)
+	assert.Equal(t, "| os      | arch  | status          |\n"+
+		"| ------- | ----- | --------------- |\n"+
+		"| linux   | amd64 | stable          |\n"+
+		"| darwin  | arm64 | stable          |\n"+
+		"| windows | amd64 | beta \\| preview |\n", got)
+}
+
+func TestFix_FieldsAndWhere(t *testing.T) {
+	fsys := fstest.MapFS{"docs/matrix.csv": {Data: []byte(matrixCSV)}}
+	got := fixData(t, "file: matrix.csv\nfields: [status, os]\nwhere: 'status: \"stable\"'\n", fsys)
+	assert.Equal(t, "| status | os     |\n"+
+		"| ------ | ------ |\n"+
+		"| stable | linux  |\n"+
+		"| stable | darwin |\n", got)
+}
+
+func TestFix_TSV(t *testing.T) {
+	fsys := fstest.MapFS{"docs/m.tsv": {Data: []byte("a\tb\n1\t\"x\n")}}
+	got := fixData(t, "file: m.tsv\n", fsys)
+	assert.Equal(t, "| a   | b   |\n| --- | --- |\n| 1   | \"x  |\n", got)
+}
+
+func TestFix_YAMLKeyOrderAndNumericWhere(t *testing.T) {
+	fsys := fstest.MapFS{"docs/langs.yml": {Data: []byte(matrixYAML)}}
+	got := fixData(t, "file: langs.yml\nwhere: 'tier: <=2'\n", fsys)
+	assert.Equal(t, "| name | version | tier | notes |\n"+
+		"| ---- | ------- | ---- | ----- |\n"+
+		"| go   | 1.24    | 1    |       |\n"+
+		"| node | 22      | 2    | LTS   |\n", got)
+
+	got = fixData(t, "file: langs.yml\nwhere: 'tier: 1'\n", fsys)
+	assert.Contains(t, got, "| go ")
+	assert.NotContains(t, got, "node")
+}
+
+func TestFix_JSONWithRowTemplate(t *testing.T) {
+	fsys := fstest.MapFS{"docs/tools.json": {Data: []byte(
+		`[{"name": "mdsmith", "url": "https://x.test/m", "ok": true},` +
+			`{"name": "a|b", "url": "https://x.test/a", "ok": false}]`)}}
+	body := "file: tools.json\n" +
+		"header: |\n  | Tool | OK |\n  |---|---|\n" +
+		"row: \"| [{name}]({url}) | {ok} |\"\n"
+	got := fixData(t, body, fsys)
+	assert.Equal(t, "| Tool                        | OK    |\n"+
+		"| --------------------------- | ----- |\n"+
+		"| [mdsmith](https://x.test/m) | true  |\n"+
+		"| [a\\|b](https://x.test/a)    | false |\n", got)
+}
+
+func TestFix_EmptyFallback(t *testing.T) {
+	fsys := fstest.MapFS{"docs/matrix.csv": {Data: []byte(matrixCSV)}}
+	got := fixData(t, "file: matrix.csv\nwhere: 'os: \"plan9\"'\nempty: No platforms.\n", fsys)
+	assert.Equal(t, "No platforms.\n", got)
+
+	got = fixData(t, "file: matrix.csv\nwhere: 'os: \"plan9\"'\n", fsys)
+	assert.Equal(t, "| os  | arch | status |\n| --- | ---- | ------ |\n", got,
+		"without empty: the header row still renders")
+}
+
+func TestFix_ParentPathUnderRoot(t *testing.T) {
+	fsys := fstest.MapFS{"data/m.csv": {Data: []byte("a\n1\n")}}
+	got := fixData(t, "file: ../data/m.csv\n", fsys)
+	assert.Equal(t, "| a   |\n| --- |\n| 1   |\n", got)
+}
+
+func TestCheck_UpToDate(t *testing.T) {
+	fsys := fstest.MapFS{"docs/m.csv": {Data: []byte("a\n1\n")}}
+	src := "# Doc\n\n<?data\nfile: m.csv\n?>\n\n| a   |\n| --- |\n| 1   |\n\n<?/data?>\n"
+	assert.Empty(t, newRule().Check(newTestFile(t, src, fsys)))
+
+	stale := "# Doc\n\n<?data\nfile: m.csv\n?>\n| a |\n| - |\n| 2 |\n<?/data?>\n"
+	diags := newRule().Check(newTestFile(t, stale, fsys))
+	require.Len(t, diags, 1)
+	assert.Contains(t, diags[0].Message, "out of date")
+}
+
+func TestCheck_Errors(t *testing.T) {
+	fsys := fstest.MapFS{
+		"docs/m.csv":      {Data: []byte(matrixCSV)},
+		"docs/ragged.csv": {Data: []byte("a,b\n1\n")},
+		"docs/dup.csv":    {Data: []byte("a,a\n1,2\n")},
+		"docs/obj.json":   {Data: []byte(`{"a": 1}`)},
+		"docs/m.txt":      {Data: []byte("a\n")},
+	}
+	for name, tc := range map[string]struct{ body, want string }{
+		"no file":        {"fields: [a]\n", `data directive missing required "file" parameter`},
+		"absolute":       {"file: /m.csv\n", "data directive has absolute file path"},
+		"url":            {"file: https://x.test/m.csv\n", "data directive has URL scheme in file path"},
+		"bad format":     {"file: m.csv\nformat: xml\n", `data directive "format" must be "csv", "tsv", "json", or "yaml"`},
+		"no format":      {"file: m.txt\n", `data directive cannot infer the format of "m.txt"; set "format"`},
+		"fields and row": {"file: m.csv\nfields: [os]\nrow: \"| {os} |\"\n", `data directive "fields" cannot be combined with "row"`},
+		"dup field":      {"file: m.csv\nfields: [os, os]\n", `data directive "fields" lists "os" twice`},
+		"header no row":  {"file: m.csv\nheader: x\n", `data directive "header" and "footer" require "row"`},
+		"empty row":      {"file: m.csv\nrow: \" \"\n", `data directive has empty "row" value`},
+		"bad where":      {"file: m.csv\nwhere: 'os: ('\n", `data directive has invalid "where" expression`},
+		"missing file":   {"file: gone.csv\n", `cannot read data file "gone.csv"`},
+		"escapes root":   {"file: ../../m.csv\n", "data file path escapes project root"},
+		"unknown field":  {"file: m.csv\nfields: [os, cpu]\n", `data file "m.csv" has no field "cpu"`},
+		"ragged":         {"file: ragged.csv\n", `cannot parse data file "ragged.csv": record on line 2: wrong number of fields`},
+		"dup header":     {"file: dup.csv\n", `cannot parse data file "dup.csv": column "a" appears twice in the header`},
+		"not a list":     {"file: obj.json\n", `cannot parse data file "obj.json": top level must be a list of objects`},
+		"txt as csv":     {"file: m.txt\nformat: csv\n", ""},
+	} {
+		t.Run(name, func(t *testing.T) {
+			src := "# Doc\n\n<?data\n" + tc.body + "?>\n<?/data?>\n"
+			diags := newRule().Check(newTestFile(t, src, fsys))
+			if tc.want == "" {
+				require.Len(t, diags, 1)
+				assert.Contains(t, diags[0].Message, "out of date")
+				return
+			}
+			require.Len(t, diags, 1, "%v", diags)
+			assert.Contains(t, diags[0].Message, tc.want)
+			assert.Equal(t, "MDS077", diags[0].RuleID)
+		})
+	}
+}
+
+func TestCheck_NoRootRejectsParentPath(t *testing.T) {
+	f, err := lint.NewFile("doc.md", []byte("# Doc\n\n<?data\nfile: ../m.csv\n?>\n<?/data?>\n"))
+	require.NoError(t, err)
+	f.FS = fstest.MapFS{}
+	diags := newRule().Check(f)
+	require.Len(t, diags, 1)
+	assert.Equal(t, `data file path contains ".." but project root is not configured`, diags[0].Message)
+}
+
+func TestApplySettings(t *testing.T) {
+	r := newRule()
+	require.NoError(t, r.ApplySettings(map[string]any{"pad": 0, "separator-style": "compact"}))
+	fsys := fstest.MapFS{"docs/m.csv": {Data: []byte("a,b\n1,2\n")}}
+	src := "# Doc\n\n<?data\nfile: m.csv\n?>\n<?/data?>\n"
+	assert.Contains(t, string(r.Fix(newTestFile(t, src, fsys))), "|a  |b  |\n|---|---|\n|1  |2  |\n")
+
+	assert.EqualError(t, newRule().ApplySettings(map[string]any{"pad": -1}),
+		"data: pad must be non-negative, got -1")
+	assert.EqualError(t, newRule().ApplySettings(map[string]any{"rows": 1}),
+		`data: unknown setting "rows"`)
+	assert.Equal(t, map[string]any{"pad": 1, "separator-style": "spaced"}, newRule().DefaultSettings())
+}
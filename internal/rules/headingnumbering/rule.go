@@ -0,0 +1,404 @@
+// Package headingnumbering implements MDS082, which keeps "1.2.3"
+// section numbers on headings in step with the document outline. Fix
+// renumbers the headings and retargets the same-file anchor links and
+// ref-def destinations whose slugs the new numbers change; `mdsmith
+// rename --numbering` does the same across the workspace.
+package headingnumbering
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"unicode"
+
+	"github.com/jeduden/mdsmith/internal/index"
+	"github.com/jeduden/mdsmith/internal/lint"
+	"github.com/jeduden/mdsmith/internal/mdtext"
+	"github.com/jeduden/mdsmith/internal/rename"
+	"github.com/jeduden/mdsmith/internal/rule"
+	"github.com/jeduden/mdsmith/internal/rules/settings"
+	"github.com/jeduden/mdsmith/pkg/goldmark/ast"
+)
+
+func init() {
+	r := &Rule{StartLevel: 2, MaxLevel: 6, Separator: "."}
+	r.compile()
+	rule.Register(r)
+}
+
+// Rule checks that headings in the numbered range carry the section
+// number their place in the outline gives them.
+type Rule struct {
+	StartLevel   int    // shallowest numbered heading level
+	MaxLevel     int    // deepest numbered heading level
+	Separator    string // joins the number's components
+	Suffix       string // follows the last component
+	AppendixFrom string // title of the first lettered top section
+
+	numberRE *regexp.Regexp
+}
+
+// ID implements rule.Rule.
+func (r *Rule) ID() string { return "MDS082" }
+
+// Name implements rule.Rule.
+func (r *Rule) Name() string { return "heading-numbering" }
+
+// Category implements rule.Rule.
+func (r *Rule) Category() string { return "heading" }
+
+// EnabledByDefault implements rule.Defaultable. Most documents are
+// not numbered, so the rule is opt-in, usually through a kind.
+func (r *Rule) EnabledByDefault() bool { return false }
+
+// section is one heading in the numbered range.
+type section struct {
+	line  int    // 1-based line in f.Source
+	got   string // current number, "" when the heading has none
+	want  string // number the outline gives it
+	title string // visible text after the number
+}
+
+// Check implements rule.Rule.
+func (r *Rule) Check(f *lint.File) []lint.Diagnostic {
+	var diags []lint.Diagnostic
+	for _, s := range r.sections(f) {
+		if s.got == s.want {
+			continue
+		}
+		msg := fmt.Sprintf("heading numbered %q; expected %q", s.got, s.want)
+		if s.got == "" {
+			msg = fmt.Sprintf("heading is not numbered; expected %q", s.want)
+		}
+		diags = append(diags, lint.Diagnostic{
+			File:     f.Path,
+			Line:     s.line,
+			Column:   1,
+			RuleID:   r.ID(),
+			RuleName: r.Name(),
+			Severity: lint.Warning,
+			Message:  msg,
+		})
+	}
+	return diags
+}
+
+// Fix implements rule.FixableRule. It renumbers the headings and
+// rewrites every same-file link whose target slug moves with them.
+func (r *Rule) Fix(f *lint.File) []byte {
+	edits, texts := r.Renumber(f)
+	if len(edits) == 0 {
+		return append([]byte(nil), f.Source...)
+	}
+	path := index.NormalizePath(f.Path)
+	idx := index.New("")
+	idx.Update(path, f.Source)
+	ws := fileWorkspace{idx: idx, path: path, source: f.Source}
+	edits = append(edits, rename.RetargetAnchors(ws, path, f.Source, texts)[path]...)
+	out, err := rename.Apply(f.Source, edits)
+	if err != nil {
+		return append([]byte(nil), f.Source...)
+	}
+	return out
+}
+
+// FixTitle implements rule.QuickFixTitler.
+func (r *Rule) FixTitle() string { return "Renumber headings" }
+
+// Renumber returns the heading-line edits that renumber f and the new
+// visible text of each renumbered heading, keyed by its 1-based line
+// in f.Source. A heading whose number sits inside inline markup is
+// reported by Check but left alone here.
+func (r *Rule) Renumber(f *lint.File) ([]rename.Edit, map[int]string) {
+	var edits []rename.Edit
+	texts := map[int]string{}
+	for _, s := range r.sections(f) {
+		if s.got == s.want || s.line > len(f.Lines) {
+			continue
+		}
+		row := f.Lines[s.line-1]
+		start := headingTextStart(row)
+		end, text := start, s.want+" "
+		if s.got != "" {
+			if !strings.HasPrefix(string(row[start:]), s.got) {
+				continue
+			}
+			end, text = start+len(s.got), s.want
+		}
+		edits = append(edits, rename.Edit{
+			Range: rename.Range{
+				Start: rename.Position{Line: s.line - 1, Character: start},
+				End:   rename.Position{Line: s.line - 1, Character: end},
+			},
+			NewText: text,
+		})
+		texts[s.line] = strings.TrimSpace(s.want + " " + s.title)
+	}
+	return edits, texts
+}
+
+// sections walks the document's top-level headings and numbers the
+// ones between StartLevel and MaxLevel. Depth follows the outline,
+// not raw levels, as the toc directive nests: an H4 right under an
+// H2 is its first subsection. A heading above StartLevel restarts the
+// numbering.
+func (r *Rule) sections(f *lint.File) []section {
+	if f == nil || f.AST == nil {
+		return nil
+	}
+	var (
+		out      []section
+		levels   []int // open heading levels, outermost first
+		counts   []int // section counter per depth
+		appendix bool
+	)
+	for n := f.AST.FirstChild(); n != nil; n = n.NextSibling() {
+		h, ok := n.(*ast.Heading)
+		if !ok || h.Lines().Len() == 0 || h.Level > r.MaxLevel {
+			continue
+		}
+		if h.Level < r.StartLevel {
+			levels, counts = levels[:0], counts[:0]
+			continue
+		}
+		for len(levels) > 0 && levels[len(levels)-1] >= h.Level {
+			levels = levels[:len(levels)-1]
+		}
+		levels = append(levels, h.Level)
+		depth := len(levels)
+
+		text := mdtext.ExtractPlainText(h, f.Source)
+		got, title := r.splitNumber(text)
+		if depth == 1 && !appendix && r.AppendixFrom != "" &&
+			(title == r.AppendixFrom || text == r.AppendixFrom) {
+			appendix = true
+			counts = counts[:0]
+		}
+
+		for len(counts) < depth {
+			counts = append(counts, 0)
+		}
+		counts = counts[:depth]
+		counts[depth-1]++
+
+		if r.isBareLetter(got) && !(appendix && depth == 1) {
+			// A bare capital, as in "A Quick Start", is a word
+			// outside the appendices, not a number.
+			got, title = "", text
+		}
+		if got != "" && !r.isSectionNumber(got, depth, counts[depth-1]) {
+			got, title = "", text
+		}
+		out = append(out, section{
+			line:  f.LineOfOffset(h.Lines().At(0).Start),
+			got:   got,
+			want:  r.format(counts, appendix),
+			title: title,
+		})
+	}
+	return out
+}
+
+// splitNumber splits a heading's visible text into its leading number
+// and the rest. Numbers written with another separator or suffix than
+// the configured ones match too; isSectionNumber decides whether the
+// match is a number or a word.
+func (r *Rule) splitNumber(text string) (string, string) {
+	m := r.numberRE.FindString(text)
+	if m == "" {
+		return "", text
+	}
+	return strings.TrimRight(m, " \t"), text[len(m):]
+}
+
+// isSectionNumber reports whether number, split from the heading at
+// depth and position pos among its siblings, is the heading's section
+// number rather than the start of its title. A number written with the
+// configured separator or suffix always is, so a style change rewrites
+// it. Otherwise it needs one component per level of depth, and a lone
+// top-level number must be at most one past pos: "## 2024 Roadmap"
+// and "## 10 Tips" keep their words, while "## 3 Usage" left behind by
+// a deleted section is renumbered.
+func (r *Rule) isSectionNumber(number string, depth, pos int) bool {
+	if r.Suffix != "" && strings.HasSuffix(number, r.Suffix) {
+		return true
+	}
+	body := strings.TrimRight(number, ".)")
+	if strings.Contains(body, r.Separator) {
+		return true
+	}
+	if strings.Count(body, ".")+strings.Count(body, "-")+1 != depth {
+		return false
+	}
+	if depth > 1 {
+		return true
+	}
+	n, err := strconv.Atoi(body)
+	return err != nil || n <= pos+1
+}
+
+// isBareLetter reports whether number is a lone letter component,
+// such as "A" or "B.", with no digits after it.
+func (r *Rule) isBareLetter(number string) bool {
+	number = strings.TrimRight(strings.TrimSuffix(number, r.Suffix), ".)")
+	if number == "" {
+		return false
+	}
+	for _, c := range number {
+		if c < 'A' || c > 'Z' {
+			return false
+		}
+	}
+	return true
+}
+
+// format renders the counters as a section number. In the appendices
+// the first component is a letter: A, B, … Z, AA, AB, ….
+func (r *Rule) format(counts []int, appendix bool) string {
+	parts := make([]string, len(counts))
+	for i, c := range counts {
+		parts[i] = strconv.Itoa(c)
+	}
+	if appendix {
+		parts[0] = letters(counts[0])
+	}
+	return strings.Join(parts, r.Separator) + r.Suffix
+}
+
+// letters renders n ≥ 1 in bijective base 26.
+func letters(n int) string {
+	var b []byte
+	for n > 0 {
+		n--
+		b = append([]byte{byte('A' + n%26)}, b...)
+		n /= 26
+	}
+	return string(b)
+}
+
+// headingTextStart returns the byte offset where the heading text
+// begins on an ATX heading line, or after the indentation of a setext
+// heading's text line.
+func headingTextStart(row []byte) int {
+	i := 0
+	for i < len(row) && i < 3 && row[i] == ' ' {
+		i++
+	}
+	if i >= len(row) || row[i] != '#' {
+		return i
+	}
+	for i < len(row) && row[i] == '#' {
+		i++
+	}
+	for i < len(row) && (row[i] == ' ' || row[i] == '\t') {
+		i++
+	}
+	return i
+}
+
+// compile builds the pattern that recognises a leading section
+// number: digits or one or two capitals, more digit components after
+// the separator or `.`/`-`, an optional suffix or `.`/`)`, then
+// whitespace or the end of the text.
+func (r *Rule) compile() {
+	sep := `[.-]`
+	if r.Separator != "" {
+		sep = `(?:[.-]|` + regexp.QuoteMeta(r.Separator) + `)`
+	}
+	suffix := `[.)]`
+	if r.Suffix != "" {
+		suffix = `(?:[.)]|` + regexp.QuoteMeta(r.Suffix) + `)`
+	}
+	r.numberRE = regexp.MustCompile(`^(?:\d+|[A-Z]{1,2})(?:` + sep + `\d+)*` + suffix + `?(?:[ \t]+|$)`)
+}
+
+// fileWorkspace is the one-file rename.Workspace Fix hands the rename
+// engine: the file's own links are the only ones a lint fix may
+// rewrite.
+type fileWorkspace struct {
+	idx    *index.Index
+	path   string
+	source []byte
+}
+
+func (w fileWorkspace) IncomingAnchorEdges(file, slug string) []index.Edge {
+	return w.idx.IncomingEdges(file, slug)
+}
+
+func (w fileWorkspace) Files() []string { return []string{w.path} }
+
+func (w fileWorkspace) Resolve(file string) (string, []byte, bool) {
+	if index.NormalizePath(file) != w.path {
+		return "", nil, false
+	}
+	return w.path, w.source, true
+}
+
+// ApplySettings implements rule.Configurable.
+func (r *Rule) ApplySettings(s map[string]any) error {
+	for k, v := range s {
+		switch k {
+		case "start-level", "max-level":
+			n, ok := settings.ToInt(v)
+			if !ok || n < 1 || n > 6 {
+				return fmt.Errorf("heading-numbering: %s must be an integer from 1 to 6, got %v", k, v)
+			}
+			if k == "start-level" {
+				r.StartLevel = n
+			} else {
+				r.MaxLevel = n
+			}
+		case "separator", "suffix":
+			str, ok := v.(string)
+			if !ok {
+				return fmt.Errorf("heading-numbering: %s must be a string, got %T", k, v)
+			}
+			if strings.ContainsFunc(str, func(c rune) bool {
+				return unicode.IsSpace(c) || unicode.IsDigit(c) || unicode.IsLetter(c)
+			}) {
+				return fmt.Errorf("heading-numbering: %s %q must not contain spaces, digits, or letters", k, str)
+			}
+			if k == "separator" {
+				if str == "" {
+					return fmt.Errorf("heading-numbering: separator must not be empty")
+				}
+				r.Separator = str
+			} else {
+				r.Suffix = str
+			}
+		case "appendix-from":
+			str, ok := v.(string)
+			if !ok {
+				return fmt.Errorf("heading-numbering: appendix-from must be a string, got %T", v)
+			}
+			r.AppendixFrom = strings.TrimSpace(str)
+		default:
+			return fmt.Errorf("heading-numbering: unknown setting %q", k)
+		}
+	}
+	if r.MaxLevel < r.StartLevel {
+		return fmt.Errorf("heading-numbering: max-level (%d) must not be below start-level (%d)",
+			r.MaxLevel, r.StartLevel)
+	}
+	r.compile()
+	return nil
+}
+
+// DefaultSettings implements rule.Configurable.
+func (r *Rule) DefaultSettings() map[string]any {
+	return map[string]any{
+		"start-level":   2,
+		"max-level":     6,
+		"separator":     ".",
+		"suffix":        "",
+		"appendix-from": "",
+	}
+}
+
+var (
+	_ rule.Configurable   = (*Rule)(nil)
+	_ rule.FixableRule    = (*Rule)(nil)
+	_ rule.Defaultable    = (*Rule)(nil)
+	_ rule.QuickFixTitler = (*Rule)(nil)
+)
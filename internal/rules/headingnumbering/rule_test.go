@@ -0,0 +1,149 @@
+package headingnumbering
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/jeduden/mdsmith/internal/lint"
+)
+
+func newRule(t *testing.T, s map[string]any) *Rule {
+	t.Helper()
+	r := &Rule{}
+	require.NoError(t, r.ApplySettings(r.DefaultSettings()))
+	require.NoError(t, r.ApplySettings(s))
+	return r
+}
+
+func newFile(t *testing.T, src string) *lint.File {
+	t.Helper()
+	f, err := lint.NewFile("spec.md", []byte(src))
+	require.NoError(t, err)
+	return f
+}
+
+func messages(diags []lint.Diagnostic) []string {
+	out := make([]string, len(diags))
+	for i, d := range diags {
+		out[i] = d.Message
+	}
+	return out
+}
+
+func TestCheck_NumberedOutlinePasses(t *testing.T) {
+	src := "# Spec\n\n## 1 Intro\n\n### 1.1 Scope\n\n#### 1.1.1 Terms\n\n## 2 Usage\n\n### 2.1 Setup\n"
+	assert.Empty(t, newRule(t, nil).Check(newFile(t, src)))
+}
+
+func TestCheck_WrongAndMissingNumbers(t *testing.T) {
+	src := "# Spec\n\n## 1 Intro\n\n### 1.2 Scope\n\n## Usage\n"
+	diags := newRule(t, nil).Check(newFile(t, src))
+	require.Len(t, diags, 2)
+	assert.Equal(t, 5, diags[0].Line)
+	assert.Equal(t, []string{
+		`heading numbered "1.2"; expected "1.1"`,
+		`heading is not numbered; expected "2"`,
+	}, messages(diags))
+	assert.Equal(t, "MDS082", diags[0].RuleID)
+}
+
+func TestCheck_DepthFollowsOutline(t *testing.T) {
+	// An H4 right under an H2 is its first subsection; the H1 in the
+	// middle restarts the numbering.
+	src := "## 1 Intro\n\n#### 1.1 Detail\n\n# Part two\n\n## 1 Again\n"
+	assert.Empty(t, newRule(t, nil).Check(newFile(t, src)))
+}
+
+func TestCheck_LevelRange(t *testing.T) {
+	r := newRule(t, map[string]any{"start-level": 1, "max-level": 2})
+	src := "# 1 Spec\n\n## 1.1 Intro\n\n### Not numbered\n"
+	assert.Empty(t, r.Check(newFile(t, src)))
+}
+
+func TestFix_RenumbersAndRetargetsAnchors(t *testing.T) {
+	src := "# Spec\n\n" +
+		"See [usage](#3-usage) and [scope][s].\n\n" +
+		"## 2 Intro\n\n### Scope\n\n## 3 Usage\n\n" +
+		"[s]: #scope\n"
+	got := string(newRule(t, nil).Fix(newFile(t, src)))
+	assert.Equal(t, "# Spec\n\n"+
+		"See [usage](#2-usage) and [scope][s].\n\n"+
+		"## 1 Intro\n\n### 1.1 Scope\n\n## 2 Usage\n\n"+
+		"[s]: #11-scope\n", got)
+}
+
+func TestFix_AppendicesAreLettered(t *testing.T) {
+	r := newRule(t, map[string]any{"appendix-from": "Glossary"})
+	src := "## 1 Intro\n\n## Glossary\n\n### Terms\n\n## References\n"
+	got := string(r.Fix(newFile(t, src)))
+	assert.Equal(t, "## 1 Intro\n\n## A Glossary\n\n### A.1 Terms\n\n## B References\n", got)
+	assert.Empty(t, r.Check(newFile(t, got)))
+}
+
+func TestFix_BareCapitalIsAWord(t *testing.T) {
+	got := string(newRule(t, nil).Fix(newFile(t, "## A Quick Start\n")))
+	assert.Equal(t, "## 1 A Quick Start\n", got)
+}
+
+func TestFix_LeadingNumberWordsAreKept(t *testing.T) {
+	r := newRule(t, nil)
+	src := "## Intro\n\n## 2024 Roadmap\n\n## 10 Tips for Writers\n\n### 3 Drafts\n"
+	got := string(r.Fix(newFile(t, src)))
+	assert.Equal(t, "## 1 Intro\n\n## 2 2024 Roadmap\n\n## 3 10 Tips for Writers\n\n### 3.1 3 Drafts\n", got)
+	assert.Empty(t, r.Check(newFile(t, got)))
+}
+
+func TestCheck_StaleNumberAfterDeletedSection(t *testing.T) {
+	src := "## 1 Intro\n\n## 3 Usage\n\n## 4 FAQ\n"
+	assert.Equal(t, []string{
+		`heading numbered "3"; expected "2"`,
+		`heading numbered "4"; expected "3"`,
+	}, messages(newRule(t, nil).Check(newFile(t, src))))
+}
+
+func TestFix_StyleChangeRewritesNumbers(t *testing.T) {
+	r := newRule(t, map[string]any{"separator": "-", "suffix": "."})
+	src := "## 1 Intro\n\n### 1.1 Scope\n\nIntro\n-----\n"
+	got := string(r.Fix(newFile(t, src)))
+	assert.Equal(t, "## 1. Intro\n\n### 1-1. Scope\n\n2. Intro\n-----\n", got)
+}
+
+func TestFix_NumberInMarkupIsReportedOnly(t *testing.T) {
+	r := newRule(t, nil)
+	src := "## **2** Intro\n"
+	require.Len(t, r.Check(newFile(t, src)), 1)
+	assert.Equal(t, src, string(r.Fix(newFile(t, src))))
+}
+
+func TestLetters(t *testing.T) {
+	assert.Equal(t, "A", letters(1))
+	assert.Equal(t, "Z", letters(26))
+	assert.Equal(t, "AA", letters(27))
+	assert.Equal(t, "AZ", letters(52))
+}
+
+func TestApplySettings_Errors(t *testing.T) {
+	tests := []struct {
+		name string
+		s    map[string]any
+		msg  string
+	}{
+		{"level range", map[string]any{"start-level": 7}, "from 1 to 6"},
+		{"level type", map[string]any{"max-level": "3"}, "from 1 to 6"},
+		{"max below start", map[string]any{"start-level": 3, "max-level": 2}, "must not be below"},
+		{"empty separator", map[string]any{"separator": ""}, "must not be empty"},
+		{"digit suffix", map[string]any{"suffix": "1"}, "must not contain"},
+		{"spaced separator", map[string]any{"separator": " "}, "must not contain"},
+		{"appendix type", map[string]any{"appendix-from": 1}, "must be a string"},
+		{"unknown", map[string]any{"style": "x"}, `unknown setting "style"`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := &Rule{}
+			require.NoError(t, r.ApplySettings(r.DefaultSettings()))
+			assert.ErrorContains(t, r.ApplySettings(tt.s), tt.msg)
+		})
+	}
+}
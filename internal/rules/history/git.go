@@ -0,0 +1,47 @@
+//go:build !(js && wasm)
+
+package history
+
+import (
+	"bytes"
+	"errors"
+	"os/exec"
+	"strconv"
+	"strings"
+
+	"github.com/jeduden/mdsmith/internal/build"
+)
+
+// trustConfig applies the build trust gate to the config at path.
+func trustConfig(path string) (bool, string) {
+	res := build.CheckTrust(path, build.EnvIsSet)
+	return res.Trusted, res.Reason
+}
+
+// gitLog runs git log for q. A directory outside any repository has
+// no history, so it yields no commits rather than an error.
+func gitLog(q logQuery) ([]commit, error) {
+	args := []string{
+		"-C", q.dir, "log", "--no-merges", "--no-show-signature",
+		"-n", strconv.Itoa(q.limit), "--format=" + logFormat, "--",
+	}
+	args = append(args, q.pathspecs...)
+	var stderr bytes.Buffer
+	cmd := exec.Command("git", args...)
+	cmd.Stderr = &stderr
+	out, err := cmd.Output()
+	if err == nil {
+		return parseLog(string(out)), nil
+	}
+	msg := strings.TrimSpace(stderr.String())
+	if strings.Contains(msg, "not a git repository") {
+		return nil, nil
+	}
+	if i := strings.IndexByte(msg, '\n'); i >= 0 {
+		msg = msg[:i]
+	}
+	if msg == "" {
+		return nil, err
+	}
+	return nil, errors.New(strings.TrimPrefix(msg, "fatal: "))
+}
@@ -0,0 +1,13 @@
+//go:build js && wasm
+
+package history
+
+// trustConfig never trusts: the WebAssembly build cannot run git.
+func trustConfig(_ string) (bool, string) {
+	return false, "git is not available in the WebAssembly build"
+}
+
+// gitLog is unreachable behind trustConfig; it reports no commits.
+func gitLog(_ logQuery) ([]commit, error) {
+	return nil, nil
+}
@@ -0,0 +1,266 @@
+package history
+
+import (
+	"path/filepath"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/jeduden/mdsmith/internal/archetype/gensection"
+	"github.com/jeduden/mdsmith/internal/fieldinterp"
+)
+
+// commit is one git log entry. type, scope, and description are set
+// when the subject follows Conventional Commits.
+type commit struct {
+	hash        string
+	short       string
+	date        string // author date, YYYY-MM-DD
+	author      string
+	subject     string
+	typ         string
+	scope       string
+	description string
+}
+
+// logQuery is one git log invocation: pathspecs are relative to dir.
+type logQuery struct {
+	dir        string
+	pathspecs  []string
+	limit      int
+	typeFilter bool
+}
+
+// key identifies the query within one file's memo.
+func (q logQuery) key() string {
+	return q.dir + "\x00" + strconv.Itoa(q.limit) + "\x00" + strings.Join(q.pathspecs, "\x00")
+}
+
+// hostDir returns the directory git runs in. The engine passes
+// CWD-relative paths, so the file's own directory works as is.
+func hostDir(p string) string {
+	dir := filepath.Dir(p)
+	if dir == "" {
+		return "."
+	}
+	return dir
+}
+
+// pathspecs turns `path:` entries into git pathspecs relative to the
+// host's directory. Entries with wildcards use glob magic, so `**`
+// crosses directories; the rest match literally. The log defaults to
+// the host's directory, the stamp to the host itself. The log always
+// leaves out the host file: every regeneration is a commit to it, so
+// listing its own commits would never settle.
+func pathspecs(hostPath string, paths []string, excludeSelf bool) []string {
+	self := filepath.Base(hostPath)
+	if len(paths) == 0 {
+		if excludeSelf {
+			paths = []string{"."}
+		} else {
+			paths = []string{self}
+		}
+	}
+	out := make([]string, 0, len(paths)+1)
+	for _, p := range paths {
+		if strings.ContainsAny(p, "*?[") {
+			out = append(out, ":(glob)"+p)
+		} else {
+			out = append(out, ":(literal)"+p)
+		}
+	}
+	if excludeSelf {
+		out = append(out, ":(exclude,literal)"+self)
+	}
+	return out
+}
+
+// logFormat asks git for one record per commit, fields split by the
+// unit separator and records by the record separator.
+const logFormat = "%H%x1f%h%x1f%aI%x1f%an%x1f%s%x1e"
+
+// parseLog parses git log output in logFormat.
+func parseLog(out string) []commit {
+	var commits []commit
+	for _, rec := range strings.Split(out, "\x1e") {
+		fields := strings.Split(strings.TrimLeft(rec, "\r\n"), "\x1f")
+		if len(fields) != 5 {
+			continue
+		}
+		c := commit{
+			hash:    fields[0],
+			short:   fields[1],
+			date:    fields[2],
+			author:  fields[3],
+			subject: fields[4],
+		}
+		if len(c.date) >= len("2006-01-02") {
+			c.date = c.date[:len("2006-01-02")]
+		}
+		c.typ, c.scope, c.description = parseConventional(c.subject)
+		commits = append(commits, c)
+	}
+	return commits
+}
+
+// conventionalRe matches a Conventional Commits subject such as
+// "feat(parser)!: add tables".
+var conventionalRe = regexp.MustCompile(`^([A-Za-z]+)(?:\(([^)]*)\))?!?: (.+)$`)
+
+// parseConventional splits a Conventional Commits subject into its
+// lowercased type, scope, and description. Other subjects have no
+// type and keep the whole subject as the description.
+func parseConventional(subject string) (typ, scope, description string) {
+	m := conventionalRe.FindStringSubmatch(subject)
+	if m == nil {
+		return "", "", subject
+	}
+	return strings.ToLower(m[1]), m[2], m[3]
+}
+
+// filterTypes keeps commits whose type is one of types. "other"
+// matches subjects that are not Conventional Commits.
+func filterTypes(commits []commit, types []string) []commit {
+	var out []commit
+	for _, c := range commits {
+		t := c.typ
+		if t == "" {
+			t = "other"
+		}
+		if slices.ContainsFunc(types, func(want string) bool { return strings.EqualFold(want, t) }) {
+			out = append(out, c)
+		}
+	}
+	return out
+}
+
+// typeGroups lists the group-by headings in output order. Types not
+// listed fall under otherGroup.
+var typeGroups = []struct{ typ, heading string }{
+	{"feat", "Features"},
+	{"fix", "Bug Fixes"},
+	{"perf", "Performance"},
+	{"refactor", "Refactoring"},
+	{"docs", "Documentation"},
+	{"test", "Tests"},
+	{"build", "Build"},
+	{"ci", "CI"},
+	{"chore", "Chores"},
+	{"style", "Style"},
+	{"revert", "Reverts"},
+}
+
+const otherGroup = "Other"
+
+// groupHeading returns the group-by heading for a commit type.
+func groupHeading(typ string) string {
+	for _, g := range typeGroups {
+		if g.typ == typ {
+			return g.heading
+		}
+	}
+	return otherGroup
+}
+
+// renderLog renders header, rows, and footer. With group-by, rows
+// sit under one heading per commit type, in typeGroups order. A
+// missing header or footer becomes a blank line so the list satisfies
+// MDS014, as in <?toc?>.
+func renderLog(params map[string]string, commits []commit) string {
+	row := params["row"]
+	if row == "" {
+		row = defaultLogRow
+	}
+	var b strings.Builder
+	if header := params["header"]; header != "" {
+		b.WriteString(gensection.EnsureTrailingNewline(header))
+	} else {
+		b.WriteByte('\n')
+	}
+	if params["group-by"] == "type" {
+		writeGroups(&b, params, row, commits)
+	} else {
+		writeRows(&b, row, commits)
+	}
+	if footer := params["footer"]; footer != "" {
+		b.WriteString(gensection.EnsureTrailingNewline(footer))
+	} else {
+		b.WriteByte('\n')
+	}
+	return b.String()
+}
+
+func writeGroups(b *strings.Builder, params map[string]string, row string, commits []commit) {
+	level := defaultGroupLevel
+	if v, ok := params["group-level"]; ok {
+		level, _ = strconv.Atoi(v)
+	}
+	byHeading := map[string][]commit{}
+	for _, c := range commits {
+		h := groupHeading(c.typ)
+		byHeading[h] = append(byHeading[h], c)
+	}
+	headings := make([]string, 0, len(typeGroups)+1)
+	for _, g := range typeGroups {
+		headings = append(headings, g.heading)
+	}
+	headings = append(headings, otherGroup)
+	first := true
+	for _, h := range headings {
+		group := byHeading[h]
+		if len(group) == 0 {
+			continue
+		}
+		if !first {
+			b.WriteByte('\n')
+		}
+		first = false
+		b.WriteString(strings.Repeat("#", level) + " " + h + "\n\n")
+		writeRows(b, row, group)
+	}
+}
+
+func writeRows(b *strings.Builder, row string, commits []commit) {
+	for _, c := range commits {
+		b.WriteString(gensection.EnsureTrailingNewline(fieldinterp.Interpolate(row, rowFields(c))))
+	}
+}
+
+// renderStamp renders the last-updated line for the newest commit.
+func renderStamp(params map[string]string, c commit) string {
+	row := params["row"]
+	if row == "" {
+		row = defaultStampRow
+	}
+	var b strings.Builder
+	if header := params["header"]; header != "" {
+		b.WriteString(gensection.EnsureTrailingNewline(header))
+	}
+	b.WriteString(gensection.EnsureTrailingNewline(fieldinterp.Interpolate(row, rowFields(c))))
+	if footer := params["footer"]; footer != "" {
+		b.WriteString(gensection.EnsureTrailingNewline(footer))
+	}
+	return b.String()
+}
+
+// textEscaper escapes the characters in commit text that would start
+// emphasis, a link, a table cell break, or inline HTML in the row.
+var textEscaper = strings.NewReplacer(
+	`\`, `\\`, `*`, `\*`, `_`, `\_`, `[`, `\[`, `]`, `\]`, `|`, `\|`, `<`, `\<`)
+
+// rowFields returns the placeholder values for one commit. Author
+// names and message text are escaped, since anyone who can commit
+// writes them.
+func rowFields(c commit) map[string]any {
+	return map[string]any{
+		"hash":        c.hash,
+		"short":       c.short,
+		"date":        c.date,
+		"author":      textEscaper.Replace(c.author),
+		"subject":     textEscaper.Replace(c.subject),
+		"type":        textEscaper.Replace(c.typ),
+		"scope":       textEscaper.Replace(c.scope),
+		"description": textEscaper.Replace(c.description),
+	}
+}
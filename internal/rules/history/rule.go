@@ -0,0 +1,283 @@
+// Package history implements MDS080, the <?history?> generated-section
+// directive that renders a file's git log as a changelog or a
+// "last updated" stamp.
+package history
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/jeduden/mdsmith/internal/archetype/gensection"
+	"github.com/jeduden/mdsmith/internal/fieldinterp"
+	"github.com/jeduden/mdsmith/internal/lint"
+	"github.com/jeduden/mdsmith/internal/rule"
+)
+
+const (
+	// defaultLimit is how many commits a log renders when `limit:` is
+	// not set.
+	defaultLimit = 20
+	// maxLimit caps `limit:` so one directive cannot dump a whole
+	// repository's history into a page.
+	maxLimit = 1000
+	// defaultLogRow renders one bullet per commit.
+	defaultLogRow = "- {date} {subject} ({short})"
+	// defaultStampRow renders the last-updated line.
+	defaultStampRow = "Last updated: {date}"
+	// defaultGroupLevel is the heading level of group-by sections.
+	defaultGroupLevel = 3
+)
+
+func init() {
+	rule.Register(&Rule{})
+	gensection.RegisterGenerated("history")
+}
+
+// Rule checks and fixes <?history?>...<?/history?> generated sections.
+//
+// Running git reads the repository's config, which can name programs
+// git then executes, so the rule only runs under the same trust gate
+// as build recipes. ConfigPath is the loaded .mdsmith.yml, injected by
+// config.InjectBuildConfig; without it (the LSP, WebAssembly, and
+// in-memory sources) the rule is inert.
+//
+// engineOnce and trustOnce serialise lazy init; the rule is a
+// registered singleton and the LSP server may call Check from
+// concurrent goroutines.
+type Rule struct {
+	ConfigPath string
+
+	engineOnce sync.Once
+	engine     *gensection.Engine
+
+	trustOnce sync.Once
+	trusted   bool
+	reason    string
+}
+
+// ID implements rule.Rule.
+func (r *Rule) ID() string { return "MDS080" }
+
+// Name implements rule.Rule.
+func (r *Rule) Name() string { return "history" }
+
+// Category implements rule.Rule.
+func (r *Rule) Category() string { return "directive" }
+
+// RuleID implements gensection.Directive.
+func (r *Rule) RuleID() string { return "MDS080" }
+
+// RuleName implements gensection.Directive.
+func (r *Rule) RuleName() string { return "history" }
+
+// DefaultSettings implements rule.Configurable.
+func (r *Rule) DefaultSettings() map[string]any {
+	return map[string]any{}
+}
+
+// ApplySettings implements rule.Configurable.
+func (r *Rule) ApplySettings(settings map[string]any) error {
+	for k, v := range settings {
+		switch k {
+		case "config-path":
+			s, ok := v.(string)
+			if !ok {
+				return fmt.Errorf("history: config-path must be a string, got %T", v)
+			}
+			r.ConfigPath = s
+		default:
+			return fmt.Errorf("history: unknown setting %q", k)
+		}
+	}
+	return nil
+}
+
+func (r *Rule) getEngine() *gensection.Engine {
+	r.engineOnce.Do(func() {
+		r.engine = gensection.NewEngine(r)
+	})
+	return r.engine
+}
+
+// checkTrust runs the build trust gate once per rule instance.
+func (r *Rule) checkTrust() (bool, string) {
+	r.trustOnce.Do(func() {
+		r.trusted, r.reason = trustConfig(r.ConfigPath)
+	})
+	return r.trusted, r.reason
+}
+
+// Check implements rule.Rule. Without a config path, or for stdin and
+// in-memory sources, there is no checkout to read, so the rule skips.
+func (r *Rule) Check(f *lint.File) []lint.Diagnostic {
+	if f.FS == nil || r.ConfigPath == "" {
+		return nil
+	}
+	return r.getEngine().Check(f)
+}
+
+// Fix implements rule.FixableRule.
+func (r *Rule) Fix(f *lint.File) []byte {
+	if f.FS == nil || r.ConfigPath == "" {
+		return f.Source
+	}
+	return r.getEngine().Fix(f)
+}
+
+// Validate implements gensection.Directive.
+func (r *Rule) Validate(filePath string, line int,
+	params map[string]string, _ map[string]gensection.ColumnConfig,
+) []lint.Diagnostic {
+	return validateHistoryDirective(filePath, line, params)
+}
+
+// logResult is the memoised output of one git log call.
+type logResult struct {
+	commits []commit
+	err     error
+}
+
+// Generate implements gensection.Directive. An untrusted checkout
+// yields a warning and leaves the section as it is.
+func (r *Rule) Generate(f *lint.File, filePath string, line int,
+	params map[string]string, _ map[string]gensection.ColumnConfig,
+) (string, []lint.Diagnostic) {
+	if ok, reason := r.checkTrust(); !ok {
+		d := makeDiag(filePath, line, "history directive not run: "+reason)
+		d.Severity = lint.Warning
+		return "", []lint.Diagnostic{d}
+	}
+
+	stamp := params["mode"] == "last-updated"
+	limit := 1
+	if !stamp {
+		limit = defaultLimit
+		if v, ok := params["limit"]; ok {
+			limit, _ = strconv.Atoi(v)
+		}
+	}
+	q := logQuery{
+		dir:        hostDir(f.Path),
+		pathspecs:  pathspecs(f.Path, gensection.SplitList(params["path"]), !stamp),
+		limit:      limit,
+		typeFilter: !stamp && params["types"] != "",
+	}
+	if q.typeFilter {
+		// Filtering happens after the log is read, so read the
+		// maximum and cut to the limit afterwards.
+		q.limit = maxLimit
+	}
+	res := f.Memo("history\x00"+q.key(), func() any {
+		commits, err := gitLog(q)
+		return logResult{commits: commits, err: err}
+	}).(logResult)
+	if res.err != nil {
+		return "", []lint.Diagnostic{makeDiag(filePath, line,
+			"history directive cannot read git log: "+res.err.Error())}
+	}
+
+	commits := res.commits
+	if q.typeFilter {
+		commits = filterTypes(commits, gensection.SplitList(params["types"]))
+		if len(commits) > limit {
+			commits = commits[:limit]
+		}
+	}
+	if len(commits) == 0 {
+		if empty := params["empty"]; empty != "" {
+			return gensection.EnsureTrailingNewline(empty), nil
+		}
+		return "", nil
+	}
+	if stamp {
+		return renderStamp(params, commits[0]), nil
+	}
+	return renderLog(params, commits), nil
+}
+
+// validateHistoryDirective validates the directive's parameters
+// without running git.
+func validateHistoryDirective(filePath string, line int, params map[string]string) []lint.Diagnostic {
+	fail := func(msg string) []lint.Diagnostic {
+		return []lint.Diagnostic{makeDiag(filePath, line, msg)}
+	}
+	mode, hasMode := params["mode"]
+	if hasMode && mode != "log" && mode != "last-updated" {
+		return fail(`history directive has invalid "mode" value; must be "log" or "last-updated"`)
+	}
+	if v, ok := params["path"]; ok {
+		paths := gensection.SplitList(v)
+		if len(paths) == 0 {
+			return fail(`history directive has empty "path" value`)
+		}
+		for _, p := range paths {
+			if strings.HasPrefix(p, ":") || strings.HasPrefix(p, "/") {
+				return fail(fmt.Sprintf("history directive path %q must be relative to the file", p))
+			}
+		}
+	}
+	if v, ok := params["limit"]; ok {
+		if n, err := strconv.Atoi(v); err != nil || n < 1 || n > maxLimit {
+			return fail(fmt.Sprintf(
+				`history directive has invalid "limit" value; must be an integer from 1 to %d`, maxLimit))
+		}
+	}
+	if v, ok := params["types"]; ok && len(gensection.SplitList(v)) == 0 {
+		return fail(`history directive has empty "types" value`)
+	}
+	if v, ok := params["group-by"]; ok && v != "type" {
+		return fail(`history directive has invalid "group-by" value; must be "type"`)
+	}
+	if v, ok := params["group-level"]; ok {
+		if n, err := strconv.Atoi(v); err != nil || n < 1 || n > 6 {
+			return fail(`history directive has invalid "group-level" value; must be 1 to 6`)
+		}
+	}
+	if mode == "last-updated" {
+		for _, k := range []string{"limit", "types", "group-by", "group-level"} {
+			if _, ok := params[k]; ok {
+				return fail(fmt.Sprintf(`history directive %q does not apply to mode "last-updated"`, k))
+			}
+		}
+	}
+	if row, ok := params["row"]; ok {
+		if strings.TrimSpace(row) == "" {
+			return fail(`history directive has empty "row" value`)
+		}
+		if err := fieldinterp.Validate(row); err != nil {
+			return fail(fmt.Sprintf("history directive has invalid row template: %v", err))
+		}
+		if mode == "last-updated" {
+			for _, field := range fieldinterp.Fields(row) {
+				if field == "hash" || field == "short" {
+					return fail(fmt.Sprintf(
+						`history directive row field {%s} does not apply to mode "last-updated"; `+
+							"the commit that writes the stamp changes it", field))
+				}
+			}
+		}
+	}
+	return nil
+}
+
+func makeDiag(file string, line int, msg string) lint.Diagnostic {
+	return lint.Diagnostic{
+		File:     file,
+		Line:     line,
+		Column:   1,
+		RuleID:   "MDS080",
+		RuleName: "history",
+		Severity: lint.Error,
+		Message:  msg,
+	}
+}
+
+var (
+	_ rule.FixableRule  = (*Rule)(nil)
+	_ rule.Configurable = (*Rule)(nil)
+)
+
+// FixTitle implements rule.QuickFixTitler.
+func (r *Rule) FixTitle() string { return "Regenerate history" }
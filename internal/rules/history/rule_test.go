@@ -0,0 +1,216 @@
+package history
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/jeduden/mdsmith/internal/archetype/gensection"
+	"github.com/jeduden/mdsmith/internal/lint"
+)
+
+// gitRepo creates a repository with one commit per subject, each
+// touching the file named alongside it, dated a day apart from
+// 2026-01-01.
+func gitRepo(t *testing.T, commits [][2]string) string {
+	t.Helper()
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git not installed")
+	}
+	dir := t.TempDir()
+	git := func(env []string, args ...string) {
+		cmd := exec.Command("git", append([]string{"-C", dir,
+			"-c", "user.name=Ada", "-c", "user.email=ada@example.com",
+			"-c", "commit.gpgsign=false"}, args...)...)
+		cmd.Env = append(os.Environ(), env...)
+		out, err := cmd.CombinedOutput()
+		require.NoError(t, err, string(out))
+	}
+	git(nil, "init", "-q")
+	for i, c := range commits {
+		p := filepath.Join(dir, filepath.FromSlash(c[0]))
+		require.NoError(t, os.MkdirAll(filepath.Dir(p), 0o755))
+		f, err := os.OpenFile(p, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0o644)
+		require.NoError(t, err)
+		_, err = f.WriteString(c[1] + "\n")
+		require.NoError(t, err)
+		require.NoError(t, f.Close())
+		date := "2026-01-0" + string(rune('1'+i)) + "T12:00:00Z"
+		git([]string{"GIT_AUTHOR_DATE=" + date, "GIT_COMMITTER_DATE=" + date}, "add", "-A")
+		git([]string{"GIT_AUTHOR_DATE=" + date, "GIT_COMMITTER_DATE=" + date}, "commit", "-q", "-m", c[1])
+	}
+	return dir
+}
+
+// trusted returns a rule whose config is trusted through the
+// environment.
+func trusted(t *testing.T) *Rule {
+	t.Helper()
+	t.Setenv("MDSMITH_TRUST_BUILD", "1")
+	return &Rule{ConfigPath: filepath.Join(t.TempDir(), ".mdsmith.yml")}
+}
+
+// fixHistory runs Fix on a history directive in dir/host with the
+// given parameter lines and returns the body between the markers.
+func fixHistory(t *testing.T, r *Rule, dir, host, params string) string {
+	t.Helper()
+	src := "# Changes\n\n<?history\n" + params + "?>\n<?/history?>\n"
+	f, err := lint.NewFile(filepath.Join(dir, host), []byte(src))
+	require.NoError(t, err)
+	f.FS = os.DirFS(filepath.Join(dir, filepath.Dir(host)))
+	out := string(r.Fix(f))
+	start := strings.Index(out, "?>\n") + len("?>\n")
+	end := strings.LastIndex(out, "<?/history?>")
+	return out[start:end]
+}
+
+var changelogCommits = [][2]string{
+	{"docs/guide.md", "feat(guide): add setup steps"},
+	{"docs/CHANGES.md", "docs: start changelog"},
+	{"docs/guide.md", "fix: correct a typo"},
+	{"src/main.go", "chore: bump deps"},
+	{"docs/api.md", "Write API notes"},
+}
+
+func TestGenerate_LogExcludesHostAndOtherDirs(t *testing.T) {
+	dir := gitRepo(t, changelogCommits)
+	got := fixHistory(t, trusted(t), dir, "docs/CHANGES.md", "row: \"- {date} {subject}\"\n")
+	want := "\n" +
+		"- 2026-01-05 Write API notes\n" +
+		"- 2026-01-03 fix: correct a typo\n" +
+		"- 2026-01-01 feat(guide): add setup steps\n" +
+		"\n"
+	assert.Equal(t, want, got)
+}
+
+func TestGenerate_GroupByTypeAndFilter(t *testing.T) {
+	dir := gitRepo(t, changelogCommits)
+	r := trusted(t)
+	got := fixHistory(t, r, dir, "docs/CHANGES.md",
+		"path: [\".\", \"../src\"]\ngroup-by: type\nrow: \"- {description} ({scope})\"\n")
+	want := "\n" +
+		"### Features\n\n- add setup steps (guide)\n\n" +
+		"### Bug Fixes\n\n- correct a typo ()\n\n" +
+		"### Chores\n\n- bump deps ()\n\n" +
+		"### Other\n\n- Write API notes ()\n" +
+		"\n"
+	assert.Equal(t, want, got)
+
+	got = fixHistory(t, r, dir, "docs/CHANGES.md", "types: [feat, fix]\nlimit: 1\nrow: \"- {subject}\"\n")
+	assert.Equal(t, "\n- fix: correct a typo\n\n", got)
+}
+
+func TestGenerate_EscapesCommitText(t *testing.T) {
+	dir := gitRepo(t, [][2]string{{"guide.md", "fix: a|b *c* [d](e) <f> g_h"}})
+	got := fixHistory(t, trusted(t), dir, "CHANGES.md", "row: \"| {subject} | {author} |\"\n")
+	assert.Equal(t, "\n| fix: a\\|b \\*c\\* \\[d\\](e) \\<f> g\\_h | Ada |\n\n", got)
+}
+
+func TestGenerate_GlobPath(t *testing.T) {
+	dir := gitRepo(t, changelogCommits)
+	got := fixHistory(t, trusted(t), dir, "CHANGES.md", "path: \"**/*.go\"\nrow: \"- {subject} by {author}\"\n")
+	assert.Equal(t, "\n- chore: bump deps by Ada\n\n", got)
+}
+
+func TestGenerate_LastUpdated(t *testing.T) {
+	dir := gitRepo(t, changelogCommits)
+	r := trusted(t)
+	got := fixHistory(t, r, dir, "docs/guide.md", "mode: last-updated\n")
+	assert.Equal(t, "Last updated: 2026-01-03\n", got)
+
+	got = fixHistory(t, r, dir, "docs/new.md", "mode: last-updated\nempty: Not committed yet.\n")
+	assert.Equal(t, "Not committed yet.\n", got)
+}
+
+func TestGenerate_OutsideRepositoryIsEmpty(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git not installed")
+	}
+	got := fixHistory(t, trusted(t), t.TempDir(), "CHANGES.md", "empty: No history.\n")
+	assert.Equal(t, "No history.\n", got)
+}
+
+func TestCheck_UntrustedWarnsAndFixLeavesSection(t *testing.T) {
+	t.Setenv("MDSMITH_TRUST_BUILD", "")
+	cfg := filepath.Join(t.TempDir(), ".mdsmith.yml")
+	require.NoError(t, os.WriteFile(cfg, []byte("rules: {}\n"), 0o644))
+	src := "<?history\n?>\nstale\n<?/history?>\n"
+	f, err := lint.NewFile("CHANGES.md", []byte(src))
+	require.NoError(t, err)
+	f.FS = os.DirFS(t.TempDir())
+
+	diags := (&Rule{ConfigPath: cfg}).Check(f)
+	require.Len(t, diags, 1)
+	assert.Equal(t, lint.Warning, diags[0].Severity)
+	assert.Contains(t, diags[0].Message, "build not trusted")
+	assert.Equal(t, src, string((&Rule{ConfigPath: cfg}).Fix(f)))
+}
+
+func TestCheck_NoConfigPathSkips(t *testing.T) {
+	f, err := lint.NewFile("CHANGES.md", []byte("<?history\n?>\nstale\n<?/history?>\n"))
+	require.NoError(t, err)
+	f.FS = os.DirFS(t.TempDir())
+	assert.Empty(t, (&Rule{}).Check(f))
+}
+
+func TestParseConventional(t *testing.T) {
+	tests := []struct{ subject, typ, scope, desc string }{
+		{"feat(api)!: drop v1", "feat", "api", "drop v1"},
+		{"Fix: handle nil", "fix", "", "handle nil"},
+		{"Merge branch 'main'", "", "", "Merge branch 'main'"},
+		{"fix:missing space", "", "", "fix:missing space"},
+	}
+	for _, tt := range tests {
+		typ, scope, desc := parseConventional(tt.subject)
+		assert.Equal(t, []string{tt.typ, tt.scope, tt.desc}, []string{typ, scope, desc}, tt.subject)
+	}
+}
+
+func TestApplySettings(t *testing.T) {
+	r := &Rule{}
+	require.NoError(t, r.ApplySettings(map[string]any{"config-path": "/w/.mdsmith.yml"}))
+	assert.Equal(t, "/w/.mdsmith.yml", r.ConfigPath)
+	assert.Error(t, r.ApplySettings(map[string]any{"config-path": 1}))
+	assert.Error(t, r.ApplySettings(map[string]any{"trusted": true}))
+}
+
+func TestValidate_Errors(t *testing.T) {
+	tests := []struct {
+		name   string
+		params map[string]string
+		msg    string
+	}{
+		{"bad mode", map[string]string{"mode": "blame"}, `invalid "mode"`},
+		{"empty path", map[string]string{"path": " "}, `empty "path"`},
+		{"absolute path", map[string]string{"path": "/etc"}, "must be relative"},
+		{"pathspec magic", map[string]string{"path": ":(top)x"}, "must be relative"},
+		{"zero limit", map[string]string{"limit": "0"}, `invalid "limit"`},
+		{"huge limit", map[string]string{"limit": "5000"}, `invalid "limit"`},
+		{"empty types", map[string]string{"types": ""}, `empty "types"`},
+		{"bad group-by", map[string]string{"group-by": "author"}, `invalid "group-by"`},
+		{"bad group-level", map[string]string{"group-level": "7"}, `invalid "group-level"`},
+		{"stamp with limit", map[string]string{"mode": "last-updated", "limit": "3"}, `"limit" does not apply`},
+		{"bad row", map[string]string{"row": "{date"}, "invalid row template"},
+		{"stamp with hash", map[string]string{"mode": "last-updated", "row": "Updated {date} ({short})"},
+			`row field {short} does not apply`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			diags := (&Rule{}).Validate("CHANGES.md", 1, tt.params, nil)
+			require.Len(t, diags, 1)
+			assert.Contains(t, diags[0].Message, tt.msg)
+			assert.Equal(t, "MDS080", diags[0].RuleID)
+		})
+	}
+}
+
+func TestAuthoredSource_SkipsHistoryBody(t *testing.T) {
+	src := "# Log\n\n<?history?>\n- 2026-01-01 Add intro\n<?/history?>\n"
+	assert.Equal(t, "# Log\n\n<?history?>\n<?/history?>\n",
+		string(gensection.AuthoredSource([]byte(src))))
+}
@@ -50,6 +50,7 @@ func mayContainIncludeDirective(source []byte) bool {
 
 func init() {
 	rule.Register(&Rule{})
+	gensection.RegisterGenerated("include")
 }
 
 // maxIncludeDepth is the maximum nesting depth for include chains.
@@ -168,12 +169,6 @@ func validateIncludeDirective(
 			"include directive has URL scheme in file path")}
 	}
 
-	// Validate wrap parameter if present.
-	if wrap, ok := params["wrap"]; ok && strings.TrimSpace(wrap) == "" {
-		return []lint.Diagnostic{makeDiag(filePath, line,
-			`include directive has empty "wrap" value`)}
-	}
-
 	// Validate strip-frontmatter parameter if present.
 	if sfm, ok := params["strip-frontmatter"]; ok {
 		if sfm != "true" && sfm != "false" {
@@ -197,7 +192,8 @@ func validateIncludeDirective(
 		return diags
 	}
 
-	return nil
+	// Validate lines, region, and dedent parameters if present.
+	return validateSnippetParams(filePath, line, params)
 }
 
 // validateExtractParam checks the extract parameter when present: a non-empty
@@ -360,6 +356,13 @@ func (r *Rule) generateIncludeContent(
 		return gensection.EnsureTrailingNewline(text), nil
 	}
 
+	// Cut the lines: or region: excerpt before nested includes expand,
+	// so line numbers and markers refer to the file as written.
+	data, err = selectSnippet(data, params, file)
+	if err != nil {
+		return "", []lint.Diagnostic{makeDiag(filePath, line, err.Error())}
+	}
+
 	// Track this file and recursively expand nested includes.
 	if r.visited != nil {
 		r.visited[resolvedFile] = struct{}{}
@@ -390,15 +393,24 @@ func (r *Rule) generateIncludeContent(
 }
 
 // processIncludedContent strips frontmatter, adjusts links/headings,
-// and optionally wraps in a code fence.
+// and optionally wraps in a code fence. An empty wrap value fences the
+// file with the language its extension implies.
 func processIncludedContent(
 	data []byte, params map[string]string,
 	f *lint.File, filePath, file string, line int,
 ) string {
 	content := data
-	stripFM := true
-	if sfm, ok := params["strip-frontmatter"]; ok && sfm == "false" {
-		stripFM = false
+	wrap, wrapped := params["wrap"]
+	lang := ""
+	if wrapped && strings.TrimSpace(wrap) == "" {
+		lang = inferLanguage(file)
+		wrap = lang
+	}
+	// An excerpt or a source file has no front matter to strip: a
+	// YAML file's leading "---" is a document marker.
+	stripFM := !hasSnippetParams(params) && lang == ""
+	if sfm, ok := params["strip-frontmatter"]; ok {
+		stripFM = sfm == "true"
 	}
 	if stripFM {
 		_, stripped := lint.StripFrontMatter(content)
@@ -407,14 +419,16 @@ func processIncludedContent(
 
 	text := strings.TrimLeft(string(content), "\n")
 	includedPath := path.Join(path.Dir(filePath), file)
-	text = adjustLinks(text, includedPath, filePath)
+	if lang == "" {
+		text = adjustLinks(text, includedPath, filePath)
+	}
 
 	// Inject source-dir into processing instructions so downstream
 	// directives (e.g. catalog) resolve globs relative to the included
 	// file's directory, not the including file's directory (#133).
 	// Skip when content will be wrapped in a code fence, since wrapped
 	// content is displayed as code and PIs are not parsed.
-	if _, wrapped := params["wrap"]; !wrapped {
+	if !wrapped {
 		includedDir := path.Dir(includedPath)
 		includerDir := path.Dir(filePath)
 		if includedDir != includerDir {
@@ -435,7 +449,7 @@ func processIncludedContent(
 		n, _ := strconv.Atoi(strings.TrimSpace(off))
 		text = adjustHeadingsByOffset(text, n)
 	}
-	if wrap, ok := params["wrap"]; ok {
+	if wrapped {
 		fence := strings.Repeat("`", minFenceLen(text))
 		if !strings.HasSuffix(text, "\n") {
 			text += "\n"
@@ -0,0 +1,298 @@
+package include
+
+import (
+	"bytes"
+	"fmt"
+	"path"
+	"strconv"
+	"strings"
+
+	"github.com/jeduden/mdsmith/internal/lint"
+)
+
+// snippetLanguages maps a file extension to the fence info string an
+// include of that file gets when the directive has no `wrap:`. An
+// extension missing here (Markdown, HTML, plain text, or anything
+// unknown) keeps the original behavior: the content is spliced in
+// as Markdown.
+var snippetLanguages = map[string]string{
+	".bash":  "bash",
+	".c":     "c",
+	".cc":    "cpp",
+	".cpp":   "cpp",
+	".cs":    "csharp",
+	".css":   "css",
+	".cue":   "cue",
+	".go":    "go",
+	".h":     "c",
+	".hpp":   "cpp",
+	".java":  "java",
+	".js":    "javascript",
+	".json":  "json",
+	".jsx":   "jsx",
+	".kt":    "kotlin",
+	".lua":   "lua",
+	".mjs":   "javascript",
+	".php":   "php",
+	".proto": "protobuf",
+	".ps1":   "powershell",
+	".py":    "python",
+	".rb":    "ruby",
+	".rs":    "rust",
+	".scala": "scala",
+	".sh":    "sh",
+	".sql":   "sql",
+	".swift": "swift",
+	".toml":  "toml",
+	".ts":    "typescript",
+	".tsx":   "tsx",
+	".xml":   "xml",
+	".yaml":  "yaml",
+	".yml":   "yaml",
+	".zsh":   "zsh",
+}
+
+// inferLanguage returns the fence language for file, or "" when its
+// extension is not a known source language.
+func inferLanguage(file string) string {
+	return snippetLanguages[strings.ToLower(path.Ext(file))]
+}
+
+// hasSnippetParams reports whether params select part of the file.
+func hasSnippetParams(params map[string]string) bool {
+	_, hasLines := params["lines"]
+	_, hasRegion := params["region"]
+	return hasLines || hasRegion
+}
+
+// validateSnippetParams checks lines, region, and dedent when
+// present. lines and region are rival ways to pick the excerpt, and
+// none of the three applies to an extract: leaf.
+func validateSnippetParams(
+	filePath string, line int, params map[string]string,
+) []lint.Diagnostic {
+	if v, ok := params["lines"]; ok {
+		if _, _, err := parseLineRange(v); err != nil {
+			return []lint.Diagnostic{makeDiag(filePath, line,
+				`include directive "lines" `+err.Error())}
+		}
+	}
+	if v, ok := params["region"]; ok && strings.TrimSpace(v) == "" {
+		return []lint.Diagnostic{makeDiag(filePath, line,
+			`include directive "region" value is empty`)}
+	}
+	if _, ok := params["lines"]; ok {
+		if _, hasRegion := params["region"]; hasRegion {
+			return []lint.Diagnostic{makeDiag(filePath, line,
+				`include directive "lines" cannot be combined with "region"`)}
+		}
+	}
+	if v, ok := params["dedent"]; ok && v != "true" && v != "false" {
+		return []lint.Diagnostic{makeDiag(filePath, line,
+			`include directive "dedent" must be "true" or "false"`)}
+	}
+	if _, ok := params["extract"]; ok {
+		for _, k := range []string{"lines", "region", "dedent"} {
+			if _, has := params[k]; has {
+				return []lint.Diagnostic{makeDiag(filePath, line,
+					`include directive "extract" cannot be combined with "`+k+`"`)}
+			}
+		}
+	}
+	return nil
+}
+
+// parseLineRange parses a 1-based inclusive range: "N", "N-M", or
+// "N-" (N to the end of the file). end is 0 for an open range.
+func parseLineRange(v string) (start, end int, err error) {
+	v = strings.TrimSpace(v)
+	lo, hi, isRange := strings.Cut(v, "-")
+	start, err = strconv.Atoi(strings.TrimSpace(lo))
+	if err != nil || start < 1 {
+		return 0, 0, fmt.Errorf(`must be "N", "N-M", or "N-" with N >= 1, got %q`, v)
+	}
+	if !isRange {
+		return start, start, nil
+	}
+	if strings.TrimSpace(hi) == "" {
+		return start, 0, nil
+	}
+	end, err = strconv.Atoi(strings.TrimSpace(hi))
+	if err != nil || end < start {
+		return 0, 0, fmt.Errorf(`must be "N", "N-M", or "N-" with N <= M, got %q`, v)
+	}
+	return start, end, nil
+}
+
+// selectSnippet returns the part of data the lines or region
+// parameter picks, dedented when dedent is "true". Without either
+// selector the data is returned unchanged, dedent aside.
+func selectSnippet(data []byte, params map[string]string, file string) ([]byte, error) {
+	out := data
+	if v, ok := params["lines"]; ok {
+		start, end, err := parseLineRange(v)
+		if err != nil {
+			return nil, err
+		}
+		if out, err = selectLines(data, start, end, file); err != nil {
+			return nil, err
+		}
+	} else if name, ok := params["region"]; ok {
+		var err error
+		if out, err = selectRegion(data, strings.TrimSpace(name), file); err != nil {
+			return nil, err
+		}
+	}
+	if params["dedent"] == "true" {
+		out = dedent(out)
+	}
+	return out, nil
+}
+
+// selectLines returns lines start..end (1-based, inclusive; end 0
+// means the last line) of data.
+func selectLines(data []byte, start, end int, file string) ([]byte, error) {
+	lines := splitKeepNewline(data)
+	if end == 0 {
+		end = len(lines)
+	}
+	if start > len(lines) || end > len(lines) {
+		return nil, fmt.Errorf("include lines %d-%d out of range: %q has %d lines",
+			start, end, file, len(lines))
+	}
+	return bytes.Join(lines[start-1:end], nil), nil
+}
+
+// selectRegion returns the lines between the `region <name>` marker
+// and its matching `endregion` marker, exclusive, without blank lines
+// at either end. Markers are
+// comment lines in any language: `// region setup`, `# endregion`,
+// `<!-- #region setup -->`, `-- region setup`. Marker lines of other
+// regions nested inside are dropped from the excerpt.
+func selectRegion(data []byte, name, file string) ([]byte, error) {
+	lines := splitKeepNewline(data)
+	var out [][]byte
+	depth := 0
+	for i, l := range lines {
+		kind, marker := regionMarker(l)
+		if depth == 0 {
+			if kind == regionStart && marker == name {
+				depth = 1
+				out = make([][]byte, 0, len(lines)-i)
+			}
+			continue
+		}
+		switch kind {
+		case regionStart:
+			depth++
+			continue
+		case regionEnd:
+			depth--
+			if depth == 0 {
+				return bytes.Join(trimBlankLines(out), nil), nil
+			}
+			continue
+		}
+		out = append(out, l)
+	}
+	if out == nil {
+		return nil, fmt.Errorf("include region %q not found in %q", name, file)
+	}
+	return nil, fmt.Errorf("include region %q in %q has no endregion marker", name, file)
+}
+
+// trimBlankLines drops whitespace-only lines from both ends of lines.
+func trimBlankLines(lines [][]byte) [][]byte {
+	for len(lines) > 0 && len(bytes.TrimSpace(lines[0])) == 0 {
+		lines = lines[1:]
+	}
+	for len(lines) > 0 && len(bytes.TrimSpace(lines[len(lines)-1])) == 0 {
+		lines = lines[:len(lines)-1]
+	}
+	return lines
+}
+
+type regionKind int
+
+const (
+	regionNone regionKind = iota
+	regionStart
+	regionEnd
+)
+
+// regionMarker classifies line as a region start (returning its
+// name), a region end, or neither. The comment leader is any run of
+// punctuation before the keyword, so `//`, `#`, `--`, `;`, `%`,
+// `/*`, and `<!--` all work, with an optional `#` before the keyword
+// (`#region`, the VS Code form). A trailing comment closer such as
+// `*/` or `-->` is ignored.
+func regionMarker(line []byte) (regionKind, string) {
+	s := strings.TrimSpace(string(line))
+	rest := strings.TrimLeft(s, "/#*-;%'!<({ \t")
+	if len(rest) == len(s) {
+		return regionNone, "" // no comment leader
+	}
+	rest = strings.TrimSpace(strings.TrimRight(rest, "*/->)} \t"))
+	if after, ok := strings.CutPrefix(rest, "endregion"); ok {
+		if after == "" || after[0] == ' ' || after[0] == '\t' {
+			return regionEnd, ""
+		}
+		return regionNone, ""
+	}
+	if after, ok := strings.CutPrefix(rest, "region"); ok {
+		if after != "" && (after[0] == ' ' || after[0] == '\t') {
+			return regionStart, strings.TrimSpace(after)
+		}
+	}
+	return regionNone, ""
+}
+
+// dedent removes the longest run of leading spaces and tabs shared by
+// every non-blank line.
+func dedent(data []byte) []byte {
+	lines := splitKeepNewline(data)
+	prefix := -1
+	var first []byte
+	for _, l := range lines {
+		if len(bytes.TrimSpace(l)) == 0 {
+			continue
+		}
+		indent := l[:len(l)-len(bytes.TrimLeft(l, " \t"))]
+		if prefix < 0 {
+			first, prefix = indent, len(indent)
+			continue
+		}
+		n := 0
+		for n < prefix && n < len(indent) && indent[n] == first[n] {
+			n++
+		}
+		prefix = n
+	}
+	if prefix <= 0 {
+		return data
+	}
+	var b bytes.Buffer
+	b.Grow(len(data))
+	for _, l := range lines {
+		if len(l) >= prefix && len(bytes.TrimSpace(l)) > 0 {
+			l = l[prefix:]
+		} else if len(bytes.TrimSpace(l)) == 0 {
+			l = bytes.TrimLeft(l, " \t")
+		}
+		b.Write(l)
+	}
+	return b.Bytes()
+}
+
+// splitKeepNewline splits data into lines that keep their "\n". A
+// trailing newline does not start an extra empty line.
+func splitKeepNewline(data []byte) [][]byte {
+	if len(data) == 0 {
+		return nil
+	}
+	lines := bytes.SplitAfter(data, []byte("\n"))
+	if len(lines[len(lines)-1]) == 0 {
+		lines = lines[:len(lines)-1]
+	}
+	return lines
+}
@@ -0,0 +1,145 @@
+package include
+
+import (
+	"testing"
+	"testing/fstest"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const snippetGo = `package client
+
+import "net/http"
+
+// region setup
+func newClient() *http.Client {
+	// #region timeout
+	c := &http.Client{}
+	// #endregion
+	return c
+}
+// endregion
+
+func unused() {}
+`
+
+func fixInclude(t *testing.T, body string, fsys fstest.MapFS) string {
+	t.Helper()
+	src := "# Doc\n\n<?include\n" + body + "?>\nold\n<?/include?>\n"
+	f := newTestFile(t, "doc.md", src, fsys)
+	got := string((&Rule{}).Fix(f))
+	prefix := "# Doc\n\n<?include\n" + body + "?>\n"
+	require.Contains(t, got, prefix)
+	return got[len(prefix) : len(got)-len("<?/include?>\n")]
+}
+
+func TestFix_LinesInfersLanguage(t *testing.T) {
+	fsys := fstest.MapFS{"client.go": {Data: []byte(snippetGo)}}
+	got := fixInclude(t, "file: client.go\nlines: 6-7\nwrap:\n", fsys)
+	assert.Equal(t, "\n```go\nfunc newClient() *http.Client {\n\t// #region timeout\n```\n\n", got)
+}
+
+func TestFix_LinesOpenRange(t *testing.T) {
+	fsys := fstest.MapFS{"client.go": {Data: []byte(snippetGo)}}
+	got := fixInclude(t, "file: client.go\nlines: 14-\nwrap:\n", fsys)
+	assert.Equal(t, "\n```go\nfunc unused() {}\n```\n\n", got)
+}
+
+func TestFix_RegionDropsNestedMarkers(t *testing.T) {
+	fsys := fstest.MapFS{"client.go": {Data: []byte(snippetGo)}}
+	got := fixInclude(t, "file: client.go\nregion: setup\nwrap:\n", fsys)
+	assert.Equal(t, "\n```go\nfunc newClient() *http.Client {\n\tc := &http.Client{}\n\treturn c\n}\n```\n\n", got)
+}
+
+func TestFix_RegionDedent(t *testing.T) {
+	fsys := fstest.MapFS{"client.go": {Data: []byte(snippetGo)}}
+	got := fixInclude(t, "file: client.go\nregion: timeout\ndedent: \"true\"\nwrap:\n", fsys)
+	assert.Equal(t, "\n```go\nc := &http.Client{}\n```\n\n", got)
+}
+
+func TestFix_WrapOverridesInferredLanguage(t *testing.T) {
+	fsys := fstest.MapFS{"client.go": {Data: []byte(snippetGo)}}
+	got := fixInclude(t, "file: client.go\nlines: \"14\"\nwrap: text\n", fsys)
+	assert.Equal(t, "\n```text\nfunc unused() {}\n```\n\n", got)
+}
+
+func TestFix_YAMLKeepsDocumentMarker(t *testing.T) {
+	fsys := fstest.MapFS{"config.yml": {Data: []byte("---\nkey: value\n---\nother: 1\n")}}
+	got := fixInclude(t, "file: config.yml\nwrap:\n", fsys)
+	assert.Equal(t, "\n```yaml\n---\nkey: value\n---\nother: 1\n```\n\n", got)
+}
+
+func TestFix_SourceWithoutWrapIsNotFenced(t *testing.T) {
+	fsys := fstest.MapFS{"client.go": {Data: []byte("---\nx: 1\n---\npackage client\n")}}
+	got := fixInclude(t, "file: client.go\n", fsys)
+	assert.Equal(t, "package client\n", got)
+}
+
+func TestFix_EmptyWrapUnknownExtension(t *testing.T) {
+	fsys := fstest.MapFS{"notes.txt": {Data: []byte("plain\n")}}
+	got := fixInclude(t, "file: notes.txt\nwrap:\n", fsys)
+	assert.Equal(t, "\n```\nplain\n```\n\n", got)
+}
+
+func TestFix_MarkdownLinesKeepsRawLines(t *testing.T) {
+	fsys := fstest.MapFS{"data.md": {Data: []byte("# Title\n\nOne.\n\nTwo.\n")}}
+	got := fixInclude(t, "file: data.md\nlines: 3-3\n", fsys)
+	assert.Equal(t, "One.\n", got)
+}
+
+func TestCheck_SnippetErrors(t *testing.T) {
+	fsys := fstest.MapFS{"client.go": {Data: []byte(snippetGo)}}
+	for name, tc := range map[string]struct{ body, want string }{
+		"bad lines":      {"lines: ten\n", `include directive "lines" must be "N", "N-M", or "N-" with N >= 1, got "ten"`},
+		"reversed lines": {"lines: 9-3\n", `include directive "lines" must be "N", "N-M", or "N-" with N <= M, got "9-3"`},
+		"out of range":   {"lines: 10-99\n", `include lines 10-99 out of range: "client.go" has 14 lines`},
+		"both":           {"lines: \"1\"\nregion: setup\n", `include directive "lines" cannot be combined with "region"`},
+		"empty region":   {"region: \"\"\n", `include directive "region" value is empty`},
+		"no region":      {"region: teardown\n", `include region "teardown" not found in "client.go"`},
+		"dedent":         {"dedent: yes\n", `include directive "dedent" must be "true" or "false"`},
+		"extract":        {"extract: title\nregion: setup\n", `include directive "extract" cannot be combined with "region"`},
+	} {
+		t.Run(name, func(t *testing.T) {
+			src := "# Doc\n\n<?include\nfile: client.go\n" + tc.body + "?>\nold\n<?/include?>\n"
+			diags := (&Rule{}).Check(newTestFile(t, "doc.md", src, fsys))
+			expectDiags(t, diags, 1)
+			assert.Equal(t, tc.want, diags[0].Message)
+		})
+	}
+}
+
+func TestSelectRegion_Unterminated(t *testing.T) {
+	_, err := selectRegion([]byte("# region a\nx\n"), "a", "f.py")
+	require.Error(t, err)
+	assert.Equal(t, `include region "a" in "f.py" has no endregion marker`, err.Error())
+}
+
+func TestRegionMarker(t *testing.T) {
+	for line, want := range map[string]struct {
+		kind regionKind
+		name string
+	}{
+		"// region setup":            {regionStart, "setup"},
+		"  # region setup":           {regionStart, "setup"},
+		"<!-- #region setup -->":     {regionStart, "setup"},
+		"/* region setup */":         {regionStart, "setup"},
+		"-- region setup":            {regionStart, "setup"},
+		"# endregion":                {regionEnd, ""},
+		"// #endregion setup":        {regionEnd, ""},
+		"region setup":               {regionNone, ""},
+		"// regional setup":          {regionNone, ""},
+		"// endregions":              {regionNone, ""},
+		"x := region(setup) // note": {regionNone, ""},
+	} {
+		kind, name := regionMarker([]byte(line))
+		assert.Equal(t, want.kind, kind, line)
+		assert.Equal(t, want.name, name, line)
+	}
+}
+
+func TestDedent(t *testing.T) {
+	assert.Equal(t, "a\n\n  b\n", string(dedent([]byte("\t\ta\n  \n\t\t  b\n"))))
+	assert.Equal(t, "a\n b\n", string(dedent([]byte("a\n b\n"))), "no shared indent")
+	assert.Equal(t, "\ta\n b\n", string(dedent([]byte(" \ta\n  b\n"))), "mixed tabs and spaces share one space")
+}
@@ -0,0 +1,148 @@
+package linelength
+
+import (
+	"strings"
+	"unicode/utf8"
+
+	"github.com/clipperhouse/uax29/v2/words"
+	"github.com/jeduden/mdsmith/internal/mdtext"
+)
+
+// hasCJK reports whether b holds a Chinese or Japanese character.
+// Every such rune encodes to three or more bytes, so ASCII bytes are
+// skipped without a decode.
+func hasCJK(b []byte) bool {
+	for i := 0; i < len(b); {
+		if b[i] < utf8.RuneSelf {
+			i++
+			continue
+		}
+		r, size := utf8.DecodeRune(b[i:])
+		if mdtext.IsCJK(r) {
+			return true
+		}
+		i += size
+	}
+	return false
+}
+
+// piece is one atomic wrap element of the CJK-aware reflow. tight
+// marks a piece that joins the previous one without a space, so a
+// line may break there but the rejoined text gains no space.
+type piece struct {
+	text  string
+	tight bool
+}
+
+// cjkPieces splits whitespace tokens into wrap pieces. Chinese and
+// Japanese text has no spaces, so each token is further divided at
+// its UAX #29 word boundaries wherever both sides are CJK and the
+// kinsoku rules allow a break (see splitCJKToken). A line break
+// between two CJK characters is dropped rather than read as a space
+// — the CSS rule for segment breaks in East Asian text — which keeps
+// a reflowed paragraph identical when it is reflowed again.
+func cjkPieces(tokens []string, lineBreaks []bool) []piece {
+	out := make([]piece, 0, len(tokens))
+	for i, tok := range tokens {
+		tight, join := false, false
+		if i > 0 && lineBreaks[i] {
+			last, _ := utf8.DecodeLastRuneInString(tokens[i-1])
+			first, _ := utf8.DecodeRuneInString(tok)
+			if mdtext.IsCJK(last) && mdtext.IsCJK(first) {
+				tight = true
+				join = noBreakAfter(last) || noBreakBefore(first)
+			}
+		}
+		n := len(out)
+		out = splitCJKToken(out, tok, tight)
+		if join {
+			// Kinsoku forbids a break here: the token's first piece
+			// continues the previous one.
+			out[n-1].text += out[n].text
+			out = append(out[:n], out[n+1:]...)
+		}
+	}
+	return out
+}
+
+// splitCJKToken appends tok to dst as one or more pieces. A break is
+// allowed between two UAX #29 segments only when the rune on each
+// side is CJK and the kinsoku rules permit it: no line starts with
+// closing punctuation, a small kana, or the long-vowel mark, and no
+// line ends with an opening bracket. A katakana word is one segment
+// and is never split; a Latin run next to CJK text stays attached.
+func splitCJKToken(dst []piece, tok string, tight bool) []piece {
+	start := 0
+	var prev rune
+	seg := words.FromString(tok)
+	for seg.Next() {
+		v := seg.Value()
+		pos := seg.Start()
+		first, _ := utf8.DecodeRuneInString(v)
+		if pos > 0 && mdtext.IsCJK(prev) && mdtext.IsCJK(first) &&
+			!noBreakAfter(prev) && !noBreakBefore(first) {
+			dst = append(dst, piece{text: tok[start:pos], tight: tight || start > 0})
+			start = pos
+		}
+		prev, _ = utf8.DecodeLastRuneInString(v)
+	}
+	return append(dst, piece{text: tok[start:], tight: tight || start > 0})
+}
+
+// noBreakBefore reports whether a line must not start with r
+// (gyōtō kinsoku).
+func noBreakBefore(r rune) bool {
+	return strings.ContainsRune(
+		"、。，．・：；？！ー…‥」』）］｝〕〉》】〙〗〟’”"+
+			"ゝゞヽヾ々〻ぁぃぅぇぉっゃゅょゎゕゖァィゥェォッャュョヮヵヶ"+
+			"｡､｣ｧｨｩｪｫｬｭｮｯｰ", r)
+}
+
+// noBreakAfter reports whether a line must not end with r
+// (gyōmatsu kinsoku).
+func noBreakAfter(r rune) bool {
+	return strings.ContainsRune("「『（［｛〔〈《【〘〖〝‘“｢", r)
+}
+
+// wrapPieces greedily packs pieces into lines no wider than width in
+// the rule's measure, each prefixed with indent. It follows
+// wrapTokens: spaced pieces glue across an abbreviation, and a unit
+// wider than width keeps its own line. A tight piece joins its line
+// without a space.
+func (r *Rule) wrapPieces(pieces []piece, indent string, width int) []string {
+	units := make([]piece, 0, len(pieces))
+	for i, p := range pieces {
+		if i > 0 && !p.tight && r.isAbbrev(pieces[i-1].text) {
+			units[len(units)-1].text += " " + p.text
+			continue
+		}
+		units = append(units, p)
+	}
+	indentW := r.width([]byte(indent))
+	var lines []string
+	var b strings.Builder
+	b.WriteString(indent)
+	b.WriteString(units[0].text)
+	curW := indentW + r.width([]byte(units[0].text))
+	for _, u := range units[1:] {
+		uW := r.width([]byte(u.text))
+		sep := 1
+		if u.tight {
+			sep = 0
+		}
+		if curW+sep+uW <= width {
+			if !u.tight {
+				b.WriteByte(' ')
+			}
+			b.WriteString(u.text)
+			curW += sep + uW
+			continue
+		}
+		lines = append(lines, b.String())
+		b.Reset()
+		b.WriteString(indent)
+		b.WriteString(u.text)
+		curW = indentW + uW
+	}
+	return append(lines, b.String())
+}
@@ -0,0 +1,93 @@
+package linelength
+
+import (
+	"strings"
+	"testing"
+	"unicode/utf8"
+
+	"github.com/jeduden/mdsmith/internal/lint"
+	"github.com/jeduden/mdsmith/pkg/runewidth"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const japanese = "日本語の文章は単語の間に空白を入れないので、行の折り返しは文字と文字の間で行います。" +
+	"ただし、句読点や閉じ括弧は行頭に置かず、開き括弧は行末に置きません。" +
+	"これを禁則処理と呼びます。"
+
+func TestCheck_DisplayMeasureCountsWideRunesTwice(t *testing.T) {
+	line := strings.Repeat("日", 45) // 45 runes, 90 columns
+	f, err := lint.NewFile("test.md", []byte(line+"\n"))
+	require.NoError(t, err)
+
+	assert.Empty(t, (&Rule{Max: 80}).Check(f), "45 runes fit the rune measure")
+
+	diags := (&Rule{Max: 80, Measure: measureDisplay}).Check(f)
+	require.Len(t, diags, 1)
+	assert.Equal(t, "line too long (90 > 80)", diags[0].Message)
+	assert.Equal(t, 41, diags[0].Column, "the 41st rune ends at column 82")
+}
+
+func TestCheck_SternTreatsCJKAsBreakable(t *testing.T) {
+	f, err := lint.NewFile("test.md", []byte(strings.Repeat("日", 90)+"\n"))
+	require.NoError(t, err)
+	assert.Len(t, (&Rule{Max: 80, Stern: true}).Check(f), 1,
+		"CJK text wraps without spaces, so stern still flags it")
+}
+
+func TestFix_CJKReflowBreaksBetweenCharacters(t *testing.T) {
+	r := &Rule{Max: 40, Reflow: true, Measure: measureDisplay}
+	got := fixSource(t, r, japanese+"\n")
+	lines := strings.Split(strings.TrimSuffix(got, "\n"), "\n")
+	require.Greater(t, len(lines), 1)
+	for _, line := range lines {
+		assert.LessOrEqual(t, runewidth.StringWidth(line), 40, "line %q", line)
+		assert.NotContains(t, line, " ", "no space is inserted between CJK characters")
+		first, _ := utf8.DecodeRuneInString(line)
+		last, _ := utf8.DecodeLastRuneInString(line)
+		assert.Falsef(t, noBreakBefore(first), "line starts with %q", first)
+		assert.Falsef(t, noBreakAfter(last), "line ends with %q", last)
+	}
+	assert.Equal(t, japanese, strings.Join(lines, ""), "reflow only moves line breaks")
+	assert.Equal(t, got, fixSource(t, r, got), "reflow is a fixpoint")
+}
+
+func TestFix_CJKReflowRejoinsBeforeRewrapping(t *testing.T) {
+	// A paragraph already broken between CJK characters rejoins
+	// without spaces before it is rewrapped at a new width.
+	r := &Rule{Max: 60, Reflow: true}
+	first := fixSource(t, &Rule{Max: 20, Reflow: true}, japanese+"\n")
+	got := fixSource(t, r, first)
+	assert.Equal(t, japanese, strings.ReplaceAll(strings.TrimSuffix(got, "\n"), "\n", ""))
+}
+
+func TestFix_CJKReflowKeepsSpacesAroundLatin(t *testing.T) {
+	r := &Rule{Max: 30, Reflow: true}
+	src := "この文書は mdsmith の設定ファイル README を説明します。各項目の意味を順番に見ていきましょう。\n"
+	got := fixSource(t, r, src)
+	joined := strings.ReplaceAll(strings.TrimSuffix(got, "\n"), "\n", " ")
+	assert.Contains(t, joined, "は mdsmith の")
+	assert.Contains(t, joined, "ファイル README を")
+	for _, line := range strings.Split(strings.TrimSuffix(got, "\n"), "\n") {
+		assert.LessOrEqual(t, utf8.RuneCountInString(line), 30, "line %q", line)
+	}
+}
+
+func TestSplitCJKToken_KatakanaWordStaysWhole(t *testing.T) {
+	pieces := splitCJKToken(nil, "新しいテキストです", false)
+	var texts []string
+	for _, p := range pieces {
+		texts = append(texts, p.text)
+	}
+	assert.Equal(t, []string{"新", "し", "い", "テキスト", "で", "す"}, texts)
+	assert.False(t, pieces[0].tight)
+	assert.True(t, pieces[1].tight)
+}
+
+func TestApplySettings_Measure(t *testing.T) {
+	r := &Rule{}
+	require.NoError(t, r.ApplySettings(map[string]any{"measure": "display"}))
+	assert.Equal(t, measureDisplay, r.Measure)
+	assert.Error(t, r.ApplySettings(map[string]any{"measure": "bytes"}))
+	assert.Equal(t, measureRunes, r.DefaultSettings()["measure"])
+}
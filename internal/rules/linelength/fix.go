@@ -2,7 +2,6 @@ package linelength
 
 import (
 	"bytes"
-	"unicode/utf8"
 
 	"github.com/jeduden/mdsmith/internal/lint"
 	"github.com/jeduden/mdsmith/internal/rule"
@@ -125,6 +124,12 @@ func (r *Rule) reflowParagraph(
 	indent := leadingWhitespace(f.Lines[startLine-1])
 	// A flagged line carries non-whitespace content, so tokenize always
 	// yields at least one token here.
+	if r.Measure == measureDisplay || hasCJK(f.Source[first.Start:last.Stop]) {
+		var breaks []bool
+		tokens := tokenizeParagraphInto(f.Source, first.Start, last.Stop, spans, &breaks)
+		out = r.wrapPieces(cjkPieces(tokens, breaks), indent, width)
+		return startLine, endLine, out, true
+	}
 	tokens := tokenizeParagraph(f.Source, first.Start, last.Stop, spans)
 	out = wrapTokens(tokens, indent, width, r.isAbbrev)
 	return startLine, endLine, out, true
@@ -141,13 +146,13 @@ func (r *Rule) paragraphHasFlaggedLine(f *lint.File, startLine, endLine, width i
 		if hasHardLineBreak(line) {
 			return false
 		}
-		if utf8.RuneCount(line) <= width {
+		if r.width(line) <= width {
 			continue
 		}
 		if r.isExcluded("urls") && isURLOnlyLine(line) {
 			continue
 		}
-		if r.Stern && !hasSpacePastLimit(line, width) {
+		if r.Stern && !r.hasBreakPastLimit(line, width) {
 			continue
 		}
 		return true
@@ -41,12 +41,25 @@ func (r *Rule) isAbbrev(tok string) bool {
 // code span adjacent to surrounding text with no intervening space stays
 // part of the same token (e.g. "pre`code`post").
 func tokenizeParagraph(src []byte, start, end int, spans []lint.Range) []string {
+	return tokenizeParagraphInto(src, start, end, spans, nil)
+}
+
+// tokenizeParagraphInto is tokenizeParagraph that, when lineBreaks is
+// non-nil, also records per token whether the whitespace before it
+// held a line break. The CJK reflow needs that: a line break between
+// two CJK characters is not a space (see cjkPieces).
+func tokenizeParagraphInto(src []byte, start, end int, spans []lint.Range, lineBreaks *[]bool) []string {
 	var tokens []string
 	var cur []byte
+	sawBreak := false
 	flush := func() {
 		if len(cur) > 0 {
 			tokens = append(tokens, string(cur))
 			cur = cur[:0]
+			if lineBreaks != nil {
+				*lineBreaks = append(*lineBreaks, sawBreak)
+			}
+			sawBreak = false
 		}
 	}
 	si := 0
@@ -73,7 +86,10 @@ func tokenizeParagraph(src []byte, start, end int, spans []lint.Range) []string
 			continue
 		}
 		switch src[pos] {
-		case ' ', '\t', '\n', '\r':
+		case '\n', '\r':
+			flush()
+			sawBreak = true
+		case ' ', '\t':
 			flush()
 		default:
 			cur = append(cur, src[pos])
@@ -10,9 +10,11 @@ import (
 	"unicode/utf8"
 
 	"github.com/jeduden/mdsmith/internal/lint"
+	"github.com/jeduden/mdsmith/internal/mdtext"
 	"github.com/jeduden/mdsmith/internal/rule"
 	"github.com/jeduden/mdsmith/internal/rules/settings"
 	"github.com/jeduden/mdsmith/pkg/goldmark/ast"
+	"github.com/jeduden/mdsmith/pkg/runewidth"
 )
 
 func init() {
@@ -40,8 +42,17 @@ type Rule struct {
 	// set used by the reflow fixer (see defaultAbbreviations). Tokens
 	// listed here are never left dangling at the end of a wrapped line.
 	Abbreviations []string
+	// Measure is how line length is counted: measureRunes (the
+	// default) counts code points, measureDisplay counts terminal
+	// columns, so a CJK character or emoji counts as two.
+	Measure string
 }
 
+const (
+	measureRunes   = "runes"
+	measureDisplay = "display"
+)
+
 // ID implements rule.Rule.
 func (r *Rule) ID() string { return "MDS001" }
 
@@ -101,6 +112,8 @@ func (r *Rule) applySetting(k string, v any) error {
 		return r.applyReflow(v)
 	case "abbreviations":
 		return r.applyAbbreviations(v)
+	case "measure":
+		return r.applyMeasure(v)
 	default:
 		return fmt.Errorf("line-length: unknown setting %q", k)
 	}
@@ -115,6 +128,15 @@ func (r *Rule) applyReflow(v any) error {
 	return nil
 }
 
+func (r *Rule) applyMeasure(v any) error {
+	s, _ := v.(string)
+	if s != measureRunes && s != measureDisplay {
+		return fmt.Errorf("line-length: measure must be %q or %q, got %v", measureRunes, measureDisplay, v)
+	}
+	r.Measure = s
+	return nil
+}
+
 func (r *Rule) applyAbbreviations(v any) error {
 	list, ok := settings.ToStringSlice(v)
 	if !ok {
@@ -201,6 +223,7 @@ func (r *Rule) DefaultSettings() map[string]any {
 		"stern":         false,
 		"reflow":        false,
 		"abbreviations": []string{},
+		"measure":       measureRunes,
 	}
 }
 
@@ -257,7 +280,7 @@ func (r *Rule) isSkipped(line []byte, lineNum, limit int, lc lineCategories) boo
 	if r.isExcluded("urls") && isURLOnlyLine(line) {
 		return true
 	}
-	if r.Stern && !hasSpacePastLimit(line, limit) {
+	if r.Stern && !r.hasBreakPastLimit(line, limit) {
 		return true
 	}
 	return false
@@ -338,13 +361,14 @@ func (r *Rule) Check(f *lint.File) []lint.Diagnostic {
 		lineNum := i + 1
 		limit := r.activeMax(baseMax, lc, lineNum)
 
-		// Fast path: byte length is always >= rune count, so if the
-		// byte length fits, the rune count will too.
+		// Fast path: byte length is always >= rune count and >=
+		// display width (a two-column rune takes at least three
+		// bytes), so if the byte length fits, the measure will too.
 		if len(line) <= limit {
 			continue
 		}
-		runeLen := utf8.RuneCount(line)
-		if runeLen <= limit {
+		width := r.width(line)
+		if width <= limit {
 			continue
 		}
 		if r.isSkipped(line, lineNum, limit, lc) {
@@ -354,11 +378,11 @@ func (r *Rule) Check(f *lint.File) []lint.Diagnostic {
 		diags = append(diags, lint.Diagnostic{
 			File:     f.Path,
 			Line:     lineNum,
-			Column:   limit + 1,
+			Column:   r.overflowColumn(line, limit),
 			RuleID:   r.ID(),
 			RuleName: r.Name(),
 			Severity: lint.Warning,
-			Message:  lineTooLongMessage(runeLen, limit),
+			Message:  lineTooLongMessage(width, limit),
 		})
 	}
 
@@ -424,17 +448,53 @@ var (
 	lineTooLongCacheCount atomic.Int32
 )
 
-// hasSpacePastLimit returns true if line contains a space at or beyond
-// the given limit column (0-indexed rune position).
-func hasSpacePastLimit(line []byte, limit int) bool {
-	i := 0
+// width returns the length of line under the configured measure.
+func (r *Rule) width(line []byte) int {
+	if r.Measure == measureDisplay {
+		return runewidth.StringWidth(string(line))
+	}
+	return utf8.RuneCount(line)
+}
+
+// runeWidth is width for a single rune.
+func (r *Rule) runeWidth(c rune) int {
+	if r.Measure == measureDisplay {
+		return runewidth.RuneWidth(c)
+	}
+	return 1
+}
+
+// overflowColumn returns the 1-based rune column of the first rune
+// that ends past limit. Under the rune measure that is always
+// limit+1; under the display measure wide runes shift it left.
+func (r *Rule) overflowColumn(line []byte, limit int) int {
+	if r.Measure != measureDisplay {
+		return limit + 1
+	}
+	col, w := 0, 0
+	for len(line) > 0 {
+		c, size := utf8.DecodeRune(line)
+		line = line[size:]
+		col++
+		if w += runewidth.RuneWidth(c); w > limit {
+			return col
+		}
+	}
+	return col + 1
+}
+
+// hasBreakPastLimit reports whether line has a break opportunity
+// starting at or beyond the limit column (0-indexed, in the rule's
+// measure): a space, or a CJK character, which wraps without one.
+func (r *Rule) hasBreakPastLimit(line []byte, limit int) bool {
+	pos := 0
 	for len(line) > 0 {
-		r, size := utf8.DecodeRune(line)
-		if i >= limit && r == ' ' {
+		c, size := utf8.DecodeRune(line)
+		if pos >= limit && (c == ' ' || mdtext.IsCJK(c)) {
 			return true
 		}
 		line = line[size:]
-		i++
+		pos += r.runeWidth(c)
 	}
 	return false
 }
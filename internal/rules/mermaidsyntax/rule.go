@@ -0,0 +1,182 @@
+// Package mermaidsyntax implements MDS084, which parses fenced code
+// blocks tagged mermaid and reports syntax errors and undefined
+// references at their line and column in the Markdown file.
+package mermaidsyntax
+
+import (
+	"bytes"
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/jeduden/mdsmith/internal/lint"
+	"github.com/jeduden/mdsmith/internal/mermaid"
+	"github.com/jeduden/mdsmith/internal/rule"
+	"github.com/jeduden/mdsmith/internal/rules/settings"
+	"github.com/jeduden/mdsmith/pkg/goldmark/ast"
+	"github.com/jeduden/mdsmith/pkg/goldmark/text"
+)
+
+func init() {
+	rule.Register(&Rule{Types: mermaid.Types})
+}
+
+// Rule reports problems in Mermaid diagrams.
+type Rule struct {
+	Types []string // diagram types to check
+}
+
+// ID implements rule.Rule.
+func (r *Rule) ID() string { return "MDS084" }
+
+// Name implements rule.Rule.
+func (r *Rule) Name() string { return "mermaid-syntax" }
+
+// Category implements rule.Rule.
+func (r *Rule) Category() string { return "code" }
+
+// EnabledByDefault implements rule.Defaultable. The parser covers the
+// common grammar, not all of it, so the rule is opt-in.
+func (r *Rule) EnabledByDefault() bool { return false }
+
+// Check implements rule.Rule.
+func (r *Rule) Check(f *lint.File) []lint.Diagnostic {
+	if f == nil || f.AST == nil {
+		return nil
+	}
+	return rule.WalkNodes(r, f)
+}
+
+// mermaidInfo is the info-string word that marks a Mermaid diagram.
+var mermaidInfo = []byte("mermaid")
+
+// CheckNode implements rule.NodeChecker.
+func (r *Rule) CheckNode(n ast.Node, entering bool, f *lint.File) []lint.Diagnostic {
+	if !entering {
+		return nil
+	}
+	fcb, ok := n.(*ast.FencedCodeBlock)
+	if !ok || fcb.Info == nil {
+		return nil
+	}
+	info := fcb.Info.Segment.Value(f.Source)
+	if i := bytes.IndexAny(info, " \t{"); i >= 0 {
+		info = info[:i]
+	}
+	segs := fcb.Lines()
+	if !bytes.Equal(info, mermaidInfo) || segs.Len() == 0 ||
+		inGeneratedRange(f, f.LineOfOffset(segs.At(0).Start)) {
+		return nil
+	}
+	typ, errs := mermaid.Check(string(content(f.Source, segs)))
+	if typ != "" && !slices.Contains(r.Types, typ) {
+		return nil
+	}
+	var diags []lint.Diagnostic
+	for _, e := range errs {
+		line, col := position(f, segs, e)
+		msg := "mermaid: " + e.Msg
+		if typ != "" {
+			msg = fmt.Sprintf("mermaid %s: %s", typ, e.Msg)
+		}
+		diags = append(diags, lint.Diagnostic{
+			File:     f.Path,
+			Line:     line,
+			Column:   col,
+			RuleID:   r.ID(),
+			RuleName: r.Name(),
+			Severity: lint.Warning,
+			Message:  msg,
+		})
+	}
+	return diags
+}
+
+// content returns a block's text. A block whose lines sit back to back
+// in the source, with no container prefix, is a slice of it.
+func content(src []byte, segs *text.Segments) []byte {
+	first := segs.At(0)
+	end := first.Stop
+	for i := 1; i < segs.Len(); i++ {
+		seg := segs.At(i)
+		if seg.Start != end || seg.Padding > 0 {
+			return segs.Value(src)
+		}
+		end = seg.Stop
+	}
+	if first.Padding > 0 {
+		return segs.Value(src)
+	}
+	return src[first.Start:end]
+}
+
+// position maps a diagram position onto the Markdown file. A position
+// past the last line sits at the end of that line.
+func position(f *lint.File, segs *text.Segments, e mermaid.Error) (int, int) {
+	line, col := max(e.Line, 1), e.Column
+	if line > segs.Len() {
+		line = segs.Len()
+		last := segs.At(line - 1)
+		col = len(bytes.TrimRight(last.Value(f.Source), "\r\n")) + 1
+	}
+	seg := segs.At(line - 1)
+	start := seg.Start
+	for start > 0 && f.Source[start-1] != '\n' {
+		start--
+	}
+	return f.LineOfOffset(seg.Start), seg.Start - start + max(col, 1)
+}
+
+func inGeneratedRange(f *lint.File, line int) bool {
+	for _, gr := range f.GeneratedRanges {
+		if gr.Contains(line) {
+			return true
+		}
+	}
+	return false
+}
+
+// ApplySettings implements rule.Configurable.
+func (r *Rule) ApplySettings(s map[string]any) error {
+	for k, v := range s {
+		switch k {
+		case "types":
+			list, ok := settings.ToStringSlice(v)
+			if !ok {
+				return fmt.Errorf("mermaid-syntax: types must be a list of strings, got %T", v)
+			}
+			for _, t := range list {
+				if !slices.Contains(mermaid.Types, t) {
+					return fmt.Errorf("mermaid-syntax: types: unsupported diagram type %q (supported: %s)",
+						t, strings.Join(mermaid.Types, ", "))
+				}
+			}
+			r.Types = list
+		default:
+			return fmt.Errorf("mermaid-syntax: unknown setting %q", k)
+		}
+	}
+	return nil
+}
+
+// DefaultSettings implements rule.Configurable.
+func (r *Rule) DefaultSettings() map[string]any {
+	return map[string]any{
+		"types": slices.Clone(mermaid.Types),
+	}
+}
+
+// enteringKinds is the static node-kind interest CheckNode declares
+// via rule.KindScopedChecker; package-level so EnteringKinds returns
+// it without allocating.
+var enteringKinds = []ast.NodeKind{ast.KindFencedCodeBlock}
+
+// EnteringKinds implements rule.KindScopedChecker.
+func (r *Rule) EnteringKinds() []ast.NodeKind { return enteringKinds }
+
+var (
+	_ rule.Configurable      = (*Rule)(nil)
+	_ rule.Defaultable       = (*Rule)(nil)
+	_ rule.NodeChecker       = (*Rule)(nil)
+	_ rule.KindScopedChecker = (*Rule)(nil)
+)
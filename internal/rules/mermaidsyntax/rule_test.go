@@ -0,0 +1,89 @@
+package mermaidsyntax
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/jeduden/mdsmith/internal/lint"
+)
+
+func newRule(t *testing.T, s map[string]any) *Rule {
+	t.Helper()
+	r := &Rule{}
+	require.NoError(t, r.ApplySettings(r.DefaultSettings()))
+	require.NoError(t, r.ApplySettings(s))
+	return r
+}
+
+func newFile(t *testing.T, src string) *lint.File {
+	t.Helper()
+	f, err := lint.NewFile("doc.md", []byte(src))
+	require.NoError(t, err)
+	return f
+}
+
+func TestCheck_ReportsPositionInMarkdown(t *testing.T) {
+	tests := []struct {
+		name      string
+		src       string
+		line, col int
+		msg       string
+	}{
+		{"syntax error", "# T\n\n```mermaid\ngraph TD\n  A --> B[Run (fast)]\n```\n", 5, 15,
+			"mermaid flowchart: unquoted '(' in node text; put the text in quotes"},
+		{"undefined node", "# T\n\n```mermaid\ngraph TD\n  A --> B\n  style C fill:#f9f\n```\n", 6, 9,
+			`mermaid flowchart: undefined node "C"`},
+		{"list item", "# T\n\n- item\n\n  ```mermaid\n  sequenceDiagram\n    A->>B\n  ```\n", 7, 10,
+			`mermaid sequence: message has no text; add ": text"`},
+		{"unknown type", "# T\n\n```mermaid\nflowchat LR\n```\n", 4, 1,
+			`mermaid: unknown diagram type "flowchat"`},
+		{"unclosed block at end", "# T\n\n```mermaid\nsequenceDiagram\n  loop x\n```\n", 5, 3,
+			`mermaid sequence: loop has no closing "end"`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			diags := newRule(t, nil).Check(newFile(t, tt.src))
+			require.Len(t, diags, 1)
+			assert.Equal(t, tt.line, diags[0].Line)
+			assert.Equal(t, tt.col, diags[0].Column)
+			assert.Equal(t, tt.msg, diags[0].Message)
+		})
+	}
+}
+
+func TestCheck_SkippedBlocks(t *testing.T) {
+	src := "# T\n\n" +
+		"```mermaid\ngraph LR\n  A --> B\n```\n\n" +
+		"```mermaid\npie title Pets\n  \"Dogs\" : 3\n```\n\n" +
+		"```mermaidjs\ngraph LR\n  A B\n```\n\n" +
+		"```text\ngraph LR\n  A B\n```\n\n" +
+		"```mermaid\n```\n"
+	assert.Empty(t, newRule(t, nil).Check(newFile(t, src)))
+}
+
+func TestCheck_TypesSetting(t *testing.T) {
+	src := "# T\n\n```mermaid\ngraph LR\n  A B\n```\n\n```mermaid\ngantt\n  Task 3d\n```\n"
+	diags := newRule(t, map[string]any{"types": []any{"gantt"}}).Check(newFile(t, src))
+	require.Len(t, diags, 1)
+	assert.Equal(t, 10, diags[0].Line)
+}
+
+func TestApplySettings_Errors(t *testing.T) {
+	tests := []struct {
+		name string
+		s    map[string]any
+		msg  string
+	}{
+		{"types type", map[string]any{"types": "gantt"}, "must be a list of strings"},
+		{"unknown type", map[string]any{"types": []any{"pie"}}, `unsupported diagram type "pie"`},
+		{"unknown", map[string]any{"strict": true}, `unknown setting "strict"`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := &Rule{}
+			assert.ErrorContains(t, r.ApplySettings(tt.s), tt.msg)
+		})
+	}
+}
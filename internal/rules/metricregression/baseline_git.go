@@ -0,0 +1,58 @@
+//go:build !(js && wasm)
+
+package metricregression
+
+import (
+	"bytes"
+	"fmt"
+	"os/exec"
+	"path/filepath"
+	"strings"
+
+	"github.com/jeduden/mdsmith/internal/build"
+	"github.com/jeduden/mdsmith/internal/lint"
+)
+
+// trustConfig applies the build trust gate to the config at path.
+func trustConfig(path string) (bool, string) {
+	res := build.CheckTrust(path, build.EnvIsSet)
+	return res.Trusted, res.Reason
+}
+
+// gitShowFile returns the file's content at ref. It runs git in the
+// file's directory with a `./`-relative object name so the lookup
+// does not depend on where the repository root is. ok is false when
+// the path did not exist at ref (a new file has no baseline).
+func gitShowFile(f *lint.File, ref string) ([]byte, bool, error) {
+	p := f.Path
+	if !filepath.IsAbs(p) && f.RootDir != "" {
+		p = filepath.Join(f.RootDir, p)
+	}
+	dir, base := filepath.Split(p)
+	if dir == "" {
+		dir = "."
+	}
+
+	var stderr bytes.Buffer
+	cmd := exec.Command("git", "-C", dir, "show", "--end-of-options", ref+":./"+base)
+	cmd.Stderr = &stderr
+	out, err := cmd.Output()
+	if err == nil {
+		return out, true, nil
+	}
+	msg := strings.TrimSpace(stderr.String())
+	if strings.Contains(msg, "does not exist in") || strings.Contains(msg, "exists on disk, but not in") {
+		return nil, false, nil
+	}
+	return nil, false, fmt.Errorf("cannot read baseline at git %s: %s", ref, firstLine(msg, err))
+}
+
+func firstLine(msg string, err error) string {
+	if msg == "" {
+		return err.Error()
+	}
+	if i := strings.IndexByte(msg, '\n'); i >= 0 {
+		return msg[:i]
+	}
+	return msg
+}
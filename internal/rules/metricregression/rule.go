@@ -0,0 +1,451 @@
+// Package metricregression implements MDS075, an opt-in rule that
+// fails a file whose metrics got measurably worse than a baseline.
+// The baseline is either a committed `mdsmith metrics snapshot`
+// record or the same file at a git revision. Which way is "worse"
+// comes from the metrics registry: Definition.Worsened follows each
+// metric's default rank order, so the rule carries no per-metric
+// direction table of its own.
+//
+// The git read lives in baseline_git.go, behind a `!(js && wasm)`
+// build tag; the js/wasm build cannot run git and reports no
+// baseline, which yields no diagnostic. Running git reads the
+// repository's config, which can name programs git then executes,
+// so a `ref` baseline is only read under the build trust gate.
+package metricregression
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"math"
+	"path"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/jeduden/mdsmith/internal/archetype/gensection"
+	"github.com/jeduden/mdsmith/internal/lint"
+	"github.com/jeduden/mdsmith/internal/metrics"
+	"github.com/jeduden/mdsmith/internal/rule"
+	"github.com/jeduden/mdsmith/internal/rules/settings"
+)
+
+func init() {
+	rule.Register(newRule())
+}
+
+func newRule() *Rule {
+	return &Rule{Deltas: defaultDeltas()}
+}
+
+// Delta is how far one metric may worsen before it is reported:
+// an absolute amount, or a percent of the baseline value.
+type Delta struct {
+	Amount  float64
+	Percent bool
+}
+
+// String renders the delta the way it is configured ("1.5", "10%").
+func (d Delta) String() string {
+	s := strconv.FormatFloat(d.Amount, 'f', -1, 64)
+	if d.Percent {
+		return s + "%"
+	}
+	return s
+}
+
+// allows reports whether moving from old to cur stays inside the
+// delta. A percent delta against a zero baseline allows nothing.
+func (d Delta) allows(old, cur float64) bool {
+	change := math.Abs(cur - old)
+	if !d.Percent {
+		return change <= d.Amount+1e-9
+	}
+	if old == 0 {
+		return change == 0
+	}
+	return change/math.Abs(old)*100 <= d.Amount+1e-9
+}
+
+func defaultDeltas() map[string]Delta {
+	return map[string]Delta{
+		"token-estimate": {Amount: 10, Percent: true},
+		"readability":    {Amount: 1},
+		"lines":          {Amount: 10, Percent: true},
+	}
+}
+
+// Rule compares each file's metrics against a baseline.
+//
+// trustOnce serialises the lazy trust check; the rule is a
+// registered singleton and the LSP server may call Check from
+// concurrent goroutines.
+type Rule struct {
+	// Baseline is a snapshot path relative to the project root.
+	Baseline string
+	// Ref is a git revision; the file's own content at Ref is the
+	// baseline. Mutually exclusive with Baseline.
+	Ref string
+	// Deltas maps a metric name to how far it may worsen.
+	Deltas map[string]Delta
+	// ConfigPath is the loaded .mdsmith.yml the trust gate pins,
+	// injected by config.InjectBuildConfig.
+	ConfigPath string
+
+	trustOnce sync.Once
+	trusted   bool
+	reason    string
+}
+
+// ID implements rule.Rule.
+func (r *Rule) ID() string { return "MDS075" }
+
+// Name implements rule.Rule.
+func (r *Rule) Name() string { return "metric-regression" }
+
+// Category implements rule.Rule.
+func (r *Rule) Category() string { return "prose" }
+
+// EnabledByDefault implements rule.Defaultable. The rule needs a
+// baseline to compare against, so it is opt-in.
+func (r *Rule) EnabledByDefault() bool { return false }
+
+// Check implements rule.Rule.
+func (r *Rule) Check(f *lint.File) []lint.Diagnostic {
+	if (r.Baseline == "" && r.Ref == "") || len(r.Deltas) == 0 {
+		return nil
+	}
+	defs := r.definitions()
+	if len(defs) == 0 {
+		return nil
+	}
+
+	old, ok, err := r.baselineValues(f, defs)
+	if err != nil {
+		return []lint.Diagnostic{r.diag(f, err.Error())}
+	}
+	if !ok {
+		// New file, or not recorded in the snapshot: nothing to
+		// regress from.
+		return nil
+	}
+	cur, err := fileValues(f.Path, fullSource(f), defs)
+	if err != nil {
+		return []lint.Diagnostic{r.diag(f, err.Error())}
+	}
+
+	var diags []lint.Diagnostic
+	for _, def := range defs {
+		a, b := old[def.Name], cur[def.Name]
+		if !a.Available || !b.Available || !def.Worsened(a.Number, b.Number) {
+			continue
+		}
+		delta := r.Deltas[def.Name]
+		if delta.allows(a.Number, b.Number) {
+			continue
+		}
+		diags = append(diags, r.diag(f, regressionMessage(def, a, b, delta, r.source())))
+	}
+	return diags
+}
+
+// definitions resolves the configured metric names in registry ID
+// order so diagnostics come out in a stable order.
+func (r *Rule) definitions() []metrics.Definition {
+	defs := make([]metrics.Definition, 0, len(r.Deltas))
+	for name := range r.Deltas {
+		if def, ok := metrics.LookupScope(metrics.ScopeFile, name); ok {
+			defs = append(defs, def)
+		}
+	}
+	sort.Slice(defs, func(i, j int) bool { return defs[i].ID < defs[j].ID })
+	return defs
+}
+
+func (r *Rule) source() string {
+	if r.Ref != "" {
+		return "git " + r.Ref
+	}
+	return r.Baseline
+}
+
+func (r *Rule) diag(f *lint.File, msg string) lint.Diagnostic {
+	return lint.Diagnostic{
+		File:     f.Path,
+		Line:     1,
+		Column:   1,
+		RuleID:   r.ID(),
+		RuleName: r.Name(),
+		Severity: lint.Warning,
+		Message:  msg,
+	}
+}
+
+func regressionMessage(def metrics.Definition, old, cur metrics.Value, delta Delta, source string) string {
+	change := metrics.AvailableValue(cur.Number - old.Number)
+	sign := ""
+	if change.Number > 0 {
+		sign = "+"
+	}
+	return def.Name + " regressed from " + metrics.FormatValue(def, old) +
+		" to " + metrics.FormatValue(def, cur) +
+		" (" + sign + metrics.FormatValue(def, change) + "; allowed " + delta.String() +
+		") against " + source
+}
+
+// checkTrust runs the build trust gate once per rule instance.
+func (r *Rule) checkTrust() (bool, string) {
+	r.trustOnce.Do(func() {
+		r.trusted, r.reason = trustConfig(r.ConfigPath)
+	})
+	return r.trusted, r.reason
+}
+
+// baselineValues returns the file's baseline metric values. ok is
+// false when the baseline has no entry for the file. An untrusted
+// config fails closed: git is not run and the reason is reported.
+func (r *Rule) baselineValues(f *lint.File, defs []metrics.Definition) (map[string]metrics.Value, bool, error) {
+	if r.Ref != "" {
+		if ok, reason := r.checkTrust(); !ok {
+			return nil, false, errors.New("git baseline not read: " + reason)
+		}
+		content, ok, err := gitShowFile(f, r.Ref)
+		if err != nil || !ok {
+			return nil, ok, err
+		}
+		values, err := fileValues(f.Path, content, defs)
+		return values, err == nil, err
+	}
+
+	rows, err := loadSnapshot(f, r.Baseline)
+	if err != nil {
+		return nil, false, err
+	}
+	for _, key := range pathKeys(f) {
+		if values, ok := rows[key]; ok {
+			return values, true, nil
+		}
+	}
+	return nil, false, nil
+}
+
+// fileValues computes defs over source exactly as metrics.Collect
+// does, so live values line up with snapshot values.
+func fileValues(p string, source []byte, defs []metrics.Definition) (map[string]metrics.Value, error) {
+	doc := metrics.NewDocument(p, gensection.AuthoredSource(source))
+	values := make(map[string]metrics.Value, len(defs))
+	for _, def := range defs {
+		v, err := def.Compute(doc)
+		if err != nil {
+			return nil, fmt.Errorf("computing %s: %w", def.Name, err)
+		}
+		values[def.Name] = rounded(def, v)
+	}
+	return values, nil
+}
+
+// rounded applies the rounding a snapshot records with, so an
+// unchanged file never shows a sub-precision drift.
+func rounded(def metrics.Definition, v metrics.Value) metrics.Value {
+	switch n := metrics.JSONValue(def, v).(type) {
+	case int64:
+		return metrics.AvailableValue(float64(n))
+	case float64:
+		return metrics.AvailableValue(n)
+	default:
+		return metrics.UnavailableValue()
+	}
+}
+
+// fullSource rebuilds the on-disk bytes: the engine strips front
+// matter into f.FrontMatter, while a snapshot measured the whole
+// file.
+func fullSource(f *lint.File) []byte {
+	if len(f.FrontMatter) == 0 {
+		return f.Source
+	}
+	out := make([]byte, 0, len(f.FrontMatter)+len(f.Source))
+	out = append(out, f.FrontMatter...)
+	return append(out, f.Source...)
+}
+
+// pathKeys lists the slash-separated spellings a snapshot may have
+// recorded the file under: as linted, and relative to the project
+// root.
+func pathKeys(f *lint.File) []string {
+	keys := []string{path.Clean(filepath.ToSlash(f.Path))}
+	if f.RootDir != "" {
+		if rel, err := filepath.Rel(f.RootDir, f.Path); err == nil && !strings.HasPrefix(rel, "..") {
+			keys = append(keys, path.Clean(filepath.ToSlash(rel)))
+		}
+	}
+	return keys
+}
+
+// snapshotEntry caches one parsed snapshot, keyed by where it was
+// read from and invalidated when its size or mtime changes, so a
+// long-lived LSP session picks up a re-taken snapshot.
+type snapshotEntry struct {
+	size    int64
+	modTime time.Time
+	rows    map[string]map[string]metrics.Value
+}
+
+var snapshotCache sync.Map
+
+// loadSnapshot reads the baseline snapshot from the project root
+// (f.RootFS), falling back to the file's own directory FS when no
+// root is configured. Only root-relative reads are cached: the
+// directory FS has no stable identity to key on.
+func loadSnapshot(f *lint.File, name string) (map[string]map[string]metrics.Value, error) {
+	fsys, cacheable := f.RootFS, true
+	if fsys == nil {
+		fsys, cacheable = f.FS, false
+	}
+	if fsys == nil {
+		return nil, errors.New("cannot read baseline " + name + ": no project root")
+	}
+	name = path.Clean(filepath.ToSlash(name))
+	info, err := fs.Stat(fsys, name)
+	if err != nil {
+		return nil, fmt.Errorf("cannot read baseline %s: %w", name, err)
+	}
+	cacheKey := f.RootDir + "\x00" + name
+	if cacheable {
+		if v, ok := snapshotCache.Load(cacheKey); ok {
+			e := v.(snapshotEntry)
+			if e.size == info.Size() && e.modTime.Equal(info.ModTime()) {
+				return e.rows, nil
+			}
+		}
+	}
+
+	data, err := fs.ReadFile(fsys, name)
+	if err != nil {
+		return nil, fmt.Errorf("cannot read baseline %s: %w", name, err)
+	}
+	snap, err := metrics.ParseSnapshot(data)
+	if err != nil {
+		return nil, fmt.Errorf("baseline %s: %w", name, err)
+	}
+	rows := make(map[string]map[string]metrics.Value, len(snap.Files))
+	for _, row := range snap.Rows() {
+		rows[path.Clean(row.Path)] = row.Metrics
+	}
+	if cacheable {
+		snapshotCache.Store(cacheKey, snapshotEntry{size: info.Size(), modTime: info.ModTime(), rows: rows})
+	}
+	return rows, nil
+}
+
+// ApplySettings implements rule.Configurable.
+func (r *Rule) ApplySettings(s map[string]any) error {
+	for k, v := range s {
+		switch k {
+		case "baseline":
+			str, ok := v.(string)
+			if !ok {
+				return fmt.Errorf("metric-regression: baseline must be a string, got %T", v)
+			}
+			r.Baseline = str
+		case "ref":
+			str, ok := v.(string)
+			if !ok {
+				return fmt.Errorf("metric-regression: ref must be a string, got %T", v)
+			}
+			if strings.HasPrefix(str, "-") {
+				return fmt.Errorf("metric-regression: ref %q must not start with '-'", str)
+			}
+			r.Ref = str
+		case "config-path":
+			str, ok := v.(string)
+			if !ok {
+				return fmt.Errorf("metric-regression: config-path must be a string, got %T", v)
+			}
+			r.ConfigPath = str
+		case "deltas":
+			deltas, err := parseDeltas(v)
+			if err != nil {
+				return err
+			}
+			r.Deltas = deltas
+		default:
+			return fmt.Errorf("metric-regression: unknown setting %q", k)
+		}
+	}
+	if r.Baseline != "" && r.Ref != "" {
+		return errors.New("metric-regression: set baseline or ref, not both")
+	}
+	return nil
+}
+
+// parseDeltas reads the `deltas:` map. Config layers deep-merge it
+// key by key, so a layer adds or retunes single metrics; `false`
+// turns a metric's check off.
+func parseDeltas(v any) (map[string]Delta, error) {
+	m, ok := v.(map[string]any)
+	if !ok {
+		return nil, fmt.Errorf("metric-regression: deltas must be a map of metric to delta, got %T", v)
+	}
+	out := make(map[string]Delta, len(m))
+	for name, raw := range m {
+		def, ok := metrics.LookupScope(metrics.ScopeFile, name)
+		if !ok {
+			return nil, fmt.Errorf("metric-regression: deltas: unknown metric %q", name)
+		}
+		if raw == false || raw == nil {
+			continue
+		}
+		d, err := parseDelta(raw)
+		if err != nil {
+			return nil, fmt.Errorf("metric-regression: deltas.%s: %w", name, err)
+		}
+		out[def.Name] = d
+	}
+	return out, nil
+}
+
+func parseDelta(v any) (Delta, error) {
+	if n, ok := settings.ToFloat(v); ok {
+		if n < 0 {
+			return Delta{}, fmt.Errorf("must be >= 0, got %v", n)
+		}
+		return Delta{Amount: n}, nil
+	}
+	s, ok := v.(string)
+	if !ok {
+		return Delta{}, fmt.Errorf("must be a number or a percent string like \"10%%\", got %T", v)
+	}
+	s = strings.TrimSpace(s)
+	percent := strings.HasSuffix(s, "%")
+	n, err := strconv.ParseFloat(strings.TrimSpace(strings.TrimSuffix(s, "%")), 64)
+	if err != nil || n < 0 || math.IsNaN(n) || math.IsInf(n, 0) {
+		return Delta{}, fmt.Errorf("must be a non-negative number or percent, got %q", s)
+	}
+	return Delta{Amount: n, Percent: percent}, nil
+}
+
+// DefaultSettings implements rule.Configurable.
+func (r *Rule) DefaultSettings() map[string]any {
+	deltas := make(map[string]any, 3)
+	for name, d := range defaultDeltas() {
+		if d.Percent {
+			deltas[name] = d.String()
+		} else {
+			deltas[name] = d.Amount
+		}
+	}
+	return map[string]any{
+		"baseline": "",
+		"ref":      "",
+		"deltas":   deltas,
+	}
+}
+
+var (
+	_ rule.Configurable = (*Rule)(nil)
+	_ rule.Defaultable  = (*Rule)(nil)
+)
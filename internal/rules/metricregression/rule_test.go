@@ -0,0 +1,262 @@
+package metricregression
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"strconv"
+	"testing"
+	"testing/fstest"
+
+	"github.com/jeduden/mdsmith/internal/lint"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const sample = "# Notes\n\nShort text here.\n\nMore text follows.\n"
+
+func snapshotJSON(path string, lines int) string {
+	return `{"version": 1, "created": "2026-10-01T00:00:00Z", "metrics": ["lines"],` +
+		` "files": [{"path": "` + path + `", "metrics": {"lines": ` + strconv.Itoa(lines) + `}}]}`
+}
+
+func fileWithRoot(t *testing.T, path, src string, root fstest.MapFS) *lint.File {
+	t.Helper()
+	f, err := lint.NewFile(path, []byte(src))
+	require.NoError(t, err)
+	f.RootFS = root
+	f.RootDir = "/root-" + t.Name()
+	return f
+}
+
+func TestRule_Metadata(t *testing.T) {
+	r := newRule()
+	assert.Equal(t, "MDS075", r.ID())
+	assert.Equal(t, "metric-regression", r.Name())
+	assert.Equal(t, "prose", r.Category())
+	assert.False(t, r.EnabledByDefault())
+}
+
+func TestCheck_NoBaselineIsInert(t *testing.T) {
+	f, err := lint.NewFile("doc.md", []byte(sample))
+	require.NoError(t, err)
+	assert.Empty(t, newRule().Check(f))
+}
+
+func TestCheck_SnapshotRegression(t *testing.T) {
+	root := fstest.MapFS{"base.json": {Data: []byte(snapshotJSON("doc.md", 2))}}
+	r := &Rule{Baseline: "base.json", Deltas: map[string]Delta{"lines": {Amount: 1}}}
+	diags := r.Check(fileWithRoot(t, "doc.md", sample, root))
+	require.Len(t, diags, 1)
+	assert.Equal(t, 1, diags[0].Line)
+	assert.Equal(t, "lines regressed from 2 to 5 (+3; allowed 1) against base.json", diags[0].Message)
+}
+
+func TestCheck_SnapshotWithinDelta(t *testing.T) {
+	root := fstest.MapFS{"base.json": {Data: []byte(snapshotJSON("doc.md", 4))}}
+	r := &Rule{Baseline: "base.json", Deltas: map[string]Delta{"lines": {Amount: 1}}}
+	assert.Empty(t, r.Check(fileWithRoot(t, "doc.md", sample, root)))
+}
+
+func TestCheck_SnapshotImprovementPasses(t *testing.T) {
+	root := fstest.MapFS{"base.json": {Data: []byte(snapshotJSON("doc.md", 40))}}
+	r := &Rule{Baseline: "base.json", Deltas: map[string]Delta{"lines": {}}}
+	assert.Empty(t, r.Check(fileWithRoot(t, "doc.md", sample, root)))
+}
+
+func TestCheck_SnapshotMissingFilePasses(t *testing.T) {
+	root := fstest.MapFS{"base.json": {Data: []byte(snapshotJSON("other.md", 1))}}
+	r := &Rule{Baseline: "base.json", Deltas: map[string]Delta{"lines": {}}}
+	assert.Empty(t, r.Check(fileWithRoot(t, "doc.md", sample, root)))
+}
+
+func TestCheck_SnapshotMatchesRootRelativePath(t *testing.T) {
+	root := fstest.MapFS{"base.json": {Data: []byte(snapshotJSON("docs/doc.md", 2))}}
+	r := &Rule{Baseline: "base.json", Deltas: map[string]Delta{"lines": {}}}
+	f := fileWithRoot(t, "/proj/docs/doc.md", sample, root)
+	f.RootDir = "/proj"
+	assert.Len(t, r.Check(f), 1)
+}
+
+func TestCheck_FrontMatterCountsTowardValues(t *testing.T) {
+	root := fstest.MapFS{"base.json": {Data: []byte(snapshotJSON("doc.md", 8))}}
+	r := &Rule{Baseline: "base.json", Deltas: map[string]Delta{"lines": {}}}
+	f := fileWithRoot(t, "doc.md", sample, root)
+	f.FrontMatter = []byte("---\ntitle: x\n---\n")
+	assert.Empty(t, r.Check(f), "5 body lines plus 3 front-matter lines match the snapshot")
+}
+
+func TestCheck_SnapshotMissingIsReported(t *testing.T) {
+	r := &Rule{Baseline: "nope.json", Deltas: map[string]Delta{"lines": {}}}
+	diags := r.Check(fileWithRoot(t, "doc.md", sample, fstest.MapFS{}))
+	require.Len(t, diags, 1)
+	assert.Contains(t, diags[0].Message, "cannot read baseline nope.json")
+}
+
+func TestCheck_SnapshotBadVersionIsReported(t *testing.T) {
+	root := fstest.MapFS{"base.json": {Data: []byte(`{"version": 9}`)}}
+	r := &Rule{Baseline: "base.json", Deltas: map[string]Delta{"lines": {}}}
+	diags := r.Check(fileWithRoot(t, "doc.md", sample, root))
+	require.Len(t, diags, 1)
+	assert.Contains(t, diags[0].Message, "unsupported snapshot version 9")
+}
+
+func TestCheck_SnapshotCacheInvalidatesOnChange(t *testing.T) {
+	root := fstest.MapFS{"base.json": {Data: []byte(snapshotJSON("doc.md", 2))}}
+	r := &Rule{Baseline: "base.json", Deltas: map[string]Delta{"lines": {}}}
+	f := fileWithRoot(t, "doc.md", sample, root)
+	require.Len(t, r.Check(f), 1)
+
+	root["base.json"] = &fstest.MapFile{Data: []byte(snapshotJSON("doc.md", 10))}
+	assert.Empty(t, r.Check(f))
+}
+
+// gitRepo returns a fresh repository and trusts the build through
+// the environment, so the rule may run git against it.
+func gitRepo(t *testing.T) string {
+	t.Helper()
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git not installed")
+	}
+	t.Setenv("MDSMITH_TRUST_BUILD", "1")
+	dir := t.TempDir()
+	git(t, dir, "init", "-q")
+	git(t, dir, "config", "user.email", "test@example.com")
+	git(t, dir, "config", "user.name", "test")
+	return dir
+}
+
+func git(t *testing.T, dir string, args ...string) {
+	t.Helper()
+	out, err := exec.Command("git", append([]string{"-C", dir}, args...)...).CombinedOutput()
+	require.NoError(t, err, string(out))
+}
+
+func TestCheck_GitRefRegression(t *testing.T) {
+	dir := gitRepo(t)
+	p := filepath.Join(dir, "doc.md")
+	require.NoError(t, os.WriteFile(p, []byte("# Notes\n"), 0o644))
+	git(t, dir, "add", "doc.md")
+	git(t, dir, "commit", "-q", "-m", "init")
+
+	f, err := lint.NewFile(p, []byte(sample))
+	require.NoError(t, err)
+	r := &Rule{Ref: "HEAD", Deltas: map[string]Delta{"lines": {Amount: 2}}}
+	diags := r.Check(f)
+	require.Len(t, diags, 1)
+	assert.Equal(t, "lines regressed from 1 to 5 (+4; allowed 2) against git HEAD", diags[0].Message)
+}
+
+func TestCheck_GitRefNewFilePasses(t *testing.T) {
+	dir := gitRepo(t)
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "a.md"), []byte("# A\n"), 0o644))
+	git(t, dir, "add", "a.md")
+	git(t, dir, "commit", "-q", "-m", "init")
+
+	f, err := lint.NewFile(filepath.Join(dir, "new.md"), []byte(sample))
+	require.NoError(t, err)
+	r := &Rule{Ref: "HEAD", Deltas: map[string]Delta{"lines": {}}}
+	assert.Empty(t, r.Check(f))
+}
+
+func TestCheck_GitRefUnknownIsReported(t *testing.T) {
+	dir := gitRepo(t)
+	f, err := lint.NewFile(filepath.Join(dir, "doc.md"), []byte(sample))
+	require.NoError(t, err)
+	r := &Rule{Ref: "no-such-ref", Deltas: map[string]Delta{"lines": {}}}
+	diags := r.Check(f)
+	require.Len(t, diags, 1)
+	assert.Contains(t, diags[0].Message, "cannot read baseline at git no-such-ref")
+}
+
+func TestCheck_GitRefUntrustedDoesNotRunGit(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("fake git is a shell script")
+	}
+	// A git stand-in that records being called.
+	bin := t.TempDir()
+	marker := filepath.Join(bin, "called")
+	script := "#!/bin/sh\n: > '" + marker + "'\n"
+	require.NoError(t, os.WriteFile(filepath.Join(bin, "git"), []byte(script), 0o755))
+	t.Setenv("PATH", bin)
+	t.Setenv("MDSMITH_TRUST_BUILD", "")
+
+	dir := t.TempDir()
+	cfg := filepath.Join(dir, ".mdsmith.yml")
+	require.NoError(t, os.WriteFile(cfg, []byte("rules: {}\n"), 0o644))
+	f, err := lint.NewFile(filepath.Join(dir, "doc.md"), []byte(sample))
+	require.NoError(t, err)
+	r := &Rule{Ref: "HEAD", ConfigPath: cfg, Deltas: map[string]Delta{"lines": {}}}
+	diags := r.Check(f)
+	require.Len(t, diags, 1)
+	assert.Contains(t, diags[0].Message, "git baseline not read: build not trusted")
+	_, err = os.Stat(marker)
+	assert.True(t, os.IsNotExist(err), "git must not run without trust")
+
+	// Trusting the config lets the same rule reach git.
+	require.NoError(t, os.WriteFile(cfg+".trust", []byte("rules: {}\n"), 0o644))
+	r = &Rule{Ref: "HEAD", ConfigPath: cfg, Deltas: map[string]Delta{"lines": {}}}
+	r.Check(f)
+	_, err = os.Stat(marker)
+	assert.NoError(t, err, "a trusted config runs git")
+}
+
+func TestDelta_Allows(t *testing.T) {
+	assert.True(t, Delta{Amount: 2}.allows(10, 12))
+	assert.False(t, Delta{Amount: 2}.allows(10, 12.5))
+	assert.True(t, Delta{Amount: 10, Percent: true}.allows(100, 110))
+	assert.False(t, Delta{Amount: 10, Percent: true}.allows(100, 111))
+	assert.False(t, Delta{Amount: 10, Percent: true}.allows(0, 1))
+	assert.Equal(t, "10%", Delta{Amount: 10, Percent: true}.String())
+	assert.Equal(t, "1.5", Delta{Amount: 1.5}.String())
+}
+
+func TestApplySettings(t *testing.T) {
+	r := newRule()
+	require.NoError(t, r.ApplySettings(map[string]any{
+		"baseline": "base.json",
+		"deltas": map[string]any{
+			"conciseness": 5,
+			"lines":       false,
+			"bytes":       "2.5%",
+		},
+	}))
+	assert.Equal(t, "base.json", r.Baseline)
+	assert.Equal(t, map[string]Delta{
+		"conciseness": {Amount: 5},
+		"bytes":       {Amount: 2.5, Percent: true},
+	}, r.Deltas)
+}
+
+func TestApplySettings_Errors(t *testing.T) {
+	cases := map[string]map[string]any{
+		"both sources":   {"baseline": "a.json", "ref": "HEAD"},
+		"dash ref":       {"ref": "--output=x"},
+		"unknown metric": {"deltas": map[string]any{"nope": 1}},
+		"negative delta": {"deltas": map[string]any{"lines": -1}},
+		"bad percent":    {"deltas": map[string]any{"lines": "x%"}},
+		"deltas type":    {"deltas": []any{"lines"}},
+		"baseline type":  {"baseline": 3},
+		"config-path":    {"config-path": 3},
+		"unknown key":    {"nope": true},
+	}
+	for name, s := range cases {
+		t.Run(name, func(t *testing.T) {
+			assert.Error(t, newRule().ApplySettings(s))
+		})
+	}
+}
+
+func TestDefaultSettings(t *testing.T) {
+	ds := newRule().DefaultSettings()
+	assert.Equal(t, map[string]any{
+		"token-estimate": "10%",
+		"readability":    float64(1),
+		"lines":          "10%",
+	}, ds["deltas"])
+
+	r := newRule()
+	require.NoError(t, r.ApplySettings(ds))
+	assert.Equal(t, defaultDeltas(), r.Deltas)
+}
@@ -3,10 +3,12 @@ package paragraphreadability
 import (
 	"fmt"
 	"math"
+	"strings"
 
 	"github.com/jeduden/mdsmith/internal/lint"
 	"github.com/jeduden/mdsmith/internal/mdtext"
 	"github.com/jeduden/mdsmith/internal/placeholders"
+	"github.com/jeduden/mdsmith/internal/readability"
 	"github.com/jeduden/mdsmith/internal/rule"
 	"github.com/jeduden/mdsmith/internal/rules/astutil"
 	"github.com/jeduden/mdsmith/internal/rules/settings"
@@ -15,18 +17,30 @@ import (
 func init() {
 	rule.Register(&Rule{
 		MaxIndex: 14.0,
+		MinEase:  30.0,
 		MinWords: 20,
-		Index:    ARI,
+		Lang:     mdtext.DefaultLang,
 	})
 }
 
-// Rule checks that the paragraph readability index does not exceed
-// a configured maximum. Uses the Automated Readability Index by
-// default.
+// Rule checks that each paragraph's readability stays within a
+// configured bound. The formula follows the file's language (see
+// readability.Default): English uses the Automated Readability
+// Index. A grade formula must not exceed MaxIndex; an ease formula
+// must not fall below MinEase.
 type Rule struct {
-	MaxIndex     float64
-	MinWords     int
-	Index        IndexFunc
+	MaxIndex float64
+	MinEase  float64
+	MinWords int
+	// Index, when set, replaces the formula with a custom grade
+	// index compared against MaxIndex.
+	Index IndexFunc
+	// Formula is a readability formula ID; "" picks the language's
+	// default.
+	Formula string
+	// Lang is the configured language; a front-matter `lang:`
+	// overrides it per file (see astutil.Lang).
+	Lang         string
 	Placeholders []string // placeholder tokens to treat as opaque
 }
 
@@ -48,12 +62,9 @@ func (r *Rule) Category() string { return "prose" }
 // Check implements rule.Rule.
 func (r *Rule) Check(f *lint.File) []lint.Diagnostic {
 	var diags []lint.Diagnostic
-	maxIndex := r.MaxIndex
 	minWords := r.MinWords
-	index := r.Index
-	if index == nil {
-		index = ARI
-	}
+	lang := astutil.Lang(f, r.Lang)
+	formula := r.formula(lang)
 
 	// Iterate the per-File memoized non-table paragraph collection so
 	// the AST walk is shared with other paragraph-walking rules
@@ -96,8 +107,7 @@ func (r *Rule) Check(f *lint.File) []lint.Diagnostic {
 			text = p.ExtractText(f.Source)
 		}
 
-		score := index(text)
-		if score > maxIndex {
+		if msg, bad := r.judge(formula, text, lang); bad {
 			diags = append(diags, lint.Diagnostic{
 				File:     f.Path,
 				Line:     p.Line,
@@ -105,7 +115,7 @@ func (r *Rule) Check(f *lint.File) []lint.Diagnostic {
 				RuleID:   r.ID(),
 				RuleName: r.Name(),
 				Severity: lint.Warning,
-				Message:  readabilityMessage(score, maxIndex, words, text),
+				Message:  readabilityMessage(msg, words, text),
 			})
 		}
 	}
@@ -113,17 +123,50 @@ func (r *Rule) Check(f *lint.File) []lint.Diagnostic {
 	return diags
 }
 
-func readabilityMessage(score, maxIndex float64, words int, text string) string {
+// formula resolves the formula for lang. A custom Index leaves it
+// zero-valued; judge checks Index first.
+func (r *Rule) formula(lang string) readability.Formula {
+	if r.Index != nil {
+		return readability.Formula{}
+	}
+	if f, ok := readability.Lookup(r.Formula); ok {
+		return f
+	}
+	return readability.Default(lang)
+}
+
+// judge scores text and reports whether it is out of bounds, along
+// with the score clause of the diagnostic. ARI and a custom Index
+// keep the historical "readability index" label; other formulas
+// name themselves so the number's scale is clear.
+func (r *Rule) judge(formula readability.Formula, text, lang string) (string, bool) {
+	var score float64
+	if r.Index != nil {
+		score = r.Index(text)
+	} else {
+		score = formula.Score(text, lang)
+	}
 	rounded := math.Round(score*10) / 10
+	switch {
+	case formula.Ease:
+		return fmt.Sprintf("%s ease: %.1f, min %.1f", formula.ID, rounded, r.MinEase), score < r.MinEase
+	case r.Index != nil || formula.ID == "ari":
+		return fmt.Sprintf("readability index: %.1f, max %.1f", rounded, r.MaxIndex), score > r.MaxIndex
+	default:
+		return fmt.Sprintf("%s: %.1f, max %.1f", formula.ID, rounded, r.MaxIndex), score > r.MaxIndex
+	}
+}
+
+func readabilityMessage(scoreClause string, words int, text string) string {
 	sentences := mdtext.CountSentences(text)
 	avgSentLen := 0
 	if sentences > 0 {
 		avgSentLen = words / sentences
 	}
 	return fmt.Sprintf(
-		"paragraph too hard to read (readability index: %.1f, max %.1f)"+
+		"paragraph too hard to read (%s)"+
 			"; avg sentence length %d words — try splitting long sentences",
-		rounded, maxIndex, avgSentLen,
+		scoreClause, avgSentLen,
 	)
 }
 
@@ -140,6 +183,40 @@ func (r *Rule) ApplySettings(s map[string]any) error {
 				)
 			}
 			r.MaxIndex = n
+		case "min-ease":
+			n, ok := settings.ToFloat(v)
+			if !ok {
+				return fmt.Errorf(
+					"paragraph-readability: min-ease must be a number, got %T",
+					v,
+				)
+			}
+			r.MinEase = n
+		case "formula":
+			id, ok := v.(string)
+			if !ok {
+				return fmt.Errorf(
+					"paragraph-readability: formula must be a string, got %T",
+					v,
+				)
+			}
+			if _, known := readability.Lookup(id); !known && id != "" {
+				return fmt.Errorf(
+					"paragraph-readability: unknown formula %q (supported: %s)",
+					id, readability.IDs(),
+				)
+			}
+			r.Formula = id
+		case "lang":
+			str, _ := v.(string)
+			lang, ok := mdtext.NormalizeLang(str)
+			if !ok {
+				return fmt.Errorf(
+					"paragraph-readability: lang must be one of %s, got %v",
+					strings.Join(mdtext.Langs, ", "), v,
+				)
+			}
+			r.Lang = lang
 		case "min-words":
 			n, ok := settings.ToInt(v)
 			if !ok {
@@ -174,7 +251,10 @@ func (r *Rule) ApplySettings(s map[string]any) error {
 func (r *Rule) DefaultSettings() map[string]any {
 	return map[string]any{
 		"max-index":    14.0,
+		"min-ease":     30.0,
 		"min-words":    20,
+		"formula":      "",
+		"lang":         mdtext.DefaultLang,
 		"placeholders": []string{},
 	}
 }
@@ -325,3 +325,74 @@ func TestSettingMergeMode_ParagraphReadability(t *testing.T) {
 func TestWordlistTarget(t *testing.T) {
 	assert.Equal(t, "placeholders", (&Rule{}).WordlistTarget())
 }
+
+// hardGerman is one long German sentence full of compounds; the
+// Wiener Sachtextformel rates it far above grade 14.
+const hardGerman = "Die Implementierung verteilter Datenverarbeitungssysteme " +
+	"erfordert ein umfassendes Verständnis grundlegender " +
+	"Synchronisationsmechanismen, Konsistenzgarantien und " +
+	"Fehlertoleranzstrategien in heterogenen Betriebsumgebungen " +
+	"sowie komplizierte Konfigurationsverwaltungswerkzeuge."
+
+func TestCheck_GermanUsesWienerSachtextformel(t *testing.T) {
+	f, err := lint.NewFile("test.md", []byte(hardGerman+"\n"))
+	require.NoError(t, err)
+	f.FrontMatter = []byte("---\nlang: de\n---\n")
+	r := &Rule{MaxIndex: 14.0, MinWords: 10, Lang: "en"}
+	diags := r.Check(f)
+	require.Len(t, diags, 1)
+	assert.Contains(t, diags[0].Message, "(wiener-sachtextformel: ")
+	assert.Contains(t, diags[0].Message, ", max 14.0)")
+}
+
+func TestCheck_SpanishUsesEaseFormula(t *testing.T) {
+	text := "La implementación de sistemas distribuidos concurrentes " +
+		"requiere una comprensión sofisticada de paradigmas computacionales " +
+		"fundamentales y mecanismos de sincronización heterogéneos."
+	f, err := lint.NewFile("test.md", []byte(text+"\n"))
+	require.NoError(t, err)
+	r := &Rule{MaxIndex: 14.0, MinEase: 30, MinWords: 10, Lang: "es"}
+	diags := r.Check(f)
+	require.Len(t, diags, 1)
+	assert.Contains(t, diags[0].Message, "(fernandez-huerta ease: ")
+	assert.Contains(t, diags[0].Message, ", min 30.0)")
+
+	r.MinEase = -100
+	assert.Empty(t, r.Check(f), "a low enough min-ease accepts the paragraph")
+}
+
+func TestCheck_FormulaSettingOverridesLanguageDefault(t *testing.T) {
+	f, err := lint.NewFile("test.md", []byte(hardText()+"\n"))
+	require.NoError(t, err)
+	r := &Rule{MaxIndex: 14.0, MinEase: 30, MinWords: 20, Lang: "en"}
+	require.NoError(t, r.ApplySettings(map[string]any{"formula": "flesch"}))
+	diags := r.Check(f)
+	require.Len(t, diags, 1)
+	assert.Contains(t, diags[0].Message, "(flesch ease: ")
+}
+
+func TestCheck_EnglishMessageUnchanged(t *testing.T) {
+	f, err := lint.NewFile("test.md", []byte(hardText()+"\n"))
+	require.NoError(t, err)
+	r := &Rule{MaxIndex: 14.0, MinWords: 20, Lang: "en"}
+	diags := r.Check(f)
+	require.Len(t, diags, 1)
+	assert.Contains(t, diags[0].Message, "(readability index: ")
+}
+
+func TestApplySettings_LangFormulaMinEase(t *testing.T) {
+	r := &Rule{}
+	require.NoError(t, r.ApplySettings(map[string]any{
+		"lang": "Deutsch", "formula": "flesch-amstad", "min-ease": 40,
+	}))
+	assert.Equal(t, "de", r.Lang)
+	assert.Equal(t, "flesch-amstad", r.Formula)
+	assert.Equal(t, 40.0, r.MinEase)
+
+	err := r.ApplySettings(map[string]any{"lang": "klingon"})
+	require.ErrorContains(t, err, "lang must be one of en, de, es, fr, it, nl")
+	err = r.ApplySettings(map[string]any{"formula": "smog"})
+	require.ErrorContains(t, err, `unknown formula "smog"`)
+	require.Error(t, r.ApplySettings(map[string]any{"formula": 3}))
+	require.Error(t, r.ApplySettings(map[string]any{"min-ease": "x"}))
+}
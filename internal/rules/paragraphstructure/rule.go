@@ -29,7 +29,7 @@ var sentBufPool = sync.Pool{
 }
 
 func init() {
-	rule.Register(&Rule{MaxSentences: 6, MaxWords: 40})
+	rule.Register(&Rule{MaxSentences: 6, MaxWords: 40, Lang: mdtext.DefaultLang})
 }
 
 // Rule checks that paragraphs do not exceed sentence and word limits.
@@ -37,6 +37,9 @@ type Rule struct {
 	MaxSentences int
 	MaxWords     int
 	Placeholders []string // placeholder tokens to treat as opaque
+	// Lang selects the trained Punkt model (see astutil.Lang); a
+	// front-matter `lang:` overrides it per file.
+	Lang string
 }
 
 // ID implements rule.Rule.
@@ -97,8 +100,9 @@ func (r *Rule) Check(f *lint.File) []lint.Diagnostic {
 	// extraction cost when MDS057/MDS058 are co-enabled is paid
 	// per-paragraph in the bare ExtractText calls.
 	var diags []lint.Diagnostic //nolint:prealloc // pre-sizing would exceed the 9-alloc/op budget (plan 193)
+	lang := astutil.Lang(f, r.Lang)
 	for _, p := range astutil.CollectSectionParagraphs(f) {
-		diags = append(diags, r.checkParagraph(p.ExtractText(f.Source), p.Line, f.Path)...)
+		diags = append(diags, r.checkParagraph(p.ExtractText(f.Source), p.Line, f.Path, lang)...)
 	}
 	return diags
 }
@@ -106,9 +110,10 @@ func (r *Rule) Check(f *lint.File) []lint.Diagnostic {
 // checkParagraph evaluates one paragraph against the sentence-count
 // and per-sentence word limits. text is the raw extracted plain
 // text; line is its 1-based source line; both come from the shared
-// collector. Placeholder masking stays per-rule so the shared text
-// is not coupled to one rule's config.
-func (r *Rule) checkParagraph(text string, line int, filePath string) []lint.Diagnostic {
+// collector; lang picks the segmenter's trained model. Placeholder
+// masking stays per-rule so the shared text is not coupled to one
+// rule's config.
+func (r *Rule) checkParagraph(text string, line int, filePath, lang string) []lint.Diagnostic {
 	if len(r.Placeholders) > 0 {
 		text = placeholders.MaskBodyTokens(text, r.Placeholders)
 	}
@@ -130,7 +135,7 @@ func (r *Rule) checkParagraph(text string, line int, filePath string) []lint.Dia
 	// must not escape this function — every call reaches the Put
 	// below before returning.
 	bufPtr := sentBufPool.Get().(*[]string)
-	*bufPtr = mdtext.SplitSentencesInto((*bufPtr)[:0], text)
+	*bufPtr = mdtext.SplitSentencesLangInto((*bufPtr)[:0], lang, text)
 	sentences := *bufPtr
 	var diags []lint.Diagnostic
 
@@ -194,41 +199,25 @@ func (r *Rule) checkParagraph(text string, line int, filePath string) []lint.Dia
 	return diags
 }
 
-// cheapBounds returns, in one allocation-free pass, an upper bound
-// on the Punkt sentence count (terminal-punct + 1) and the exact
-// word count (whitespace-delimited, matching strings.Fields). Both
-// are conservative for the rule's checks: Punkt never splits without
-// a terminal-punct rune and always yields >=1 sentence, and no
-// single sentence has more words than the whole paragraph.
+// cheapBounds returns, allocation-free, an upper bound on the
+// Punkt sentence count (terminal-punct + 1) and the exact word count
+// (mdtext.CountWords). Both are conservative for the rule's checks:
+// the segmenter never splits without a terminal-punct rune and
+// always yields >=1 sentence, and no single sentence has more words
+// than the whole paragraph.
 //
-// The terminal-punct set covers every rune the English Punkt
-// pipeline (and internal/punkt's vendored fork) actually flags as a
-// sentence break: ASCII `.!?` via HasSentEndChars + HasPeriodFinal,
-// and CJK `。` via HasPeriodFinal's full-width branch. The CJK
-// `！` and `？` runes are word boundaries (IsCjkPunct) but not
-// sentence boundaries — the English pipeline's hasSentEndChars set
-// is ASCII-only — so a paragraph that uses only `！`/`？` between
-// sentences segments as ONE sentence upstream. Counting `！`/`？`
-// here would let the guard fail to short-circuit safe input, not
-// cause a false positive — but it would also be misleading; the
-// set is kept aligned with actual SentBreak boundaries so the
-// guard stays a tight upper bound.
+// The terminal-punct set covers every rune mdtext.SplitSentences
+// breaks on: ASCII `.!?` (Punkt's sentence-end characters) and the
+// CJK terminals `。！？｡`, which split without a following space.
 func cheapBounds(s string) (sentUB, words int) {
 	punct := 0
-	inWord := false
 	for _, r := range s {
 		switch r {
-		case '.', '!', '?', '。':
+		case '.', '!', '?', '。', '！', '？', '｡':
 			punct++
 		}
-		if mdtext.IsSpace(r) {
-			inWord = false
-		} else if !inWord {
-			inWord = true
-			words++
-		}
 	}
-	return punct + 1, words
+	return punct + 1, mdtext.CountWords(s)
 }
 
 // sentencePreview returns a quoted preview of the sentence, truncated
@@ -272,6 +261,16 @@ func (r *Rule) ApplySettings(s map[string]any) error {
 				return fmt.Errorf("paragraph-structure: %w", err)
 			}
 			r.Placeholders = toks
+		case "lang":
+			str, _ := v.(string)
+			lang, ok := mdtext.NormalizeLang(str)
+			if !ok {
+				return fmt.Errorf(
+					"paragraph-structure: lang must be one of %s, got %v",
+					strings.Join(mdtext.Langs, ", "), v,
+				)
+			}
+			r.Lang = lang
 		default:
 			return fmt.Errorf("paragraph-structure: unknown setting %q", k)
 		}
@@ -285,6 +284,7 @@ func (r *Rule) DefaultSettings() map[string]any {
 		"max-sentences":          6,
 		"max-words-per-sentence": 40,
 		"placeholders":           []string{},
+		"lang":                   mdtext.DefaultLang,
 	}
 }
 
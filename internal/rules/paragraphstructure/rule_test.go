@@ -30,20 +30,20 @@ func TestRule_checkParagraph(t *testing.T) {
 
 	t.Run("guard short-circuits clean paragraph", func(t *testing.T) {
 		text, line, path := firstParagraph(t, "Short and safe.")
-		assert.Nil(t, r.checkParagraph(text, line, path))
+		assert.Nil(t, r.checkParagraph(text, line, path, "en"))
 	})
 
 	t.Run("too many sentences", func(t *testing.T) {
 		text, line, path := firstParagraph(t,
 			"One. Two. Three. Four. Five. Six. Seven. Eight.")
-		d := r.checkParagraph(text, line, path)
+		d := r.checkParagraph(text, line, path, "en")
 		require.Len(t, d, 1)
 		assert.Contains(t, d[0].Message, "too many sentences")
 	})
 
 	t.Run("sentence too long", func(t *testing.T) {
 		text, line, path := firstParagraph(t, strings.Repeat("word ", 45)+".")
-		d := r.checkParagraph(text, line, path)
+		d := r.checkParagraph(text, line, path, "en")
 		require.Len(t, d, 1)
 		assert.Contains(t, d[0].Message, "sentence too long")
 	})
@@ -62,16 +62,12 @@ func TestCheapBounds(t *testing.T) {
 		{"e.g. this is one sentence.", 4, 5},
 		{"a... b", 4, 2},
 		{"q? r? s?", 4, 3},
-		// CJK full-width period 。 counts toward sentUB — it IS
-		// a SentBreak (via HasPeriodFinal). The CJK enders run
-		// together with no whitespace, so word count is 1.
-		{"一。二。三。", 4, 1},
-		// Full-width ！ and ？ are NOT sentence breaks in the
-		// English pipeline (HasSentEndChars is ASCII-only). The
-		// guard counts only the single 。, not the ！ / ？.
-		{"问题？回答。继续！", 2, 1},
+		// Every CJK terminal counts toward sentUB; each ideograph
+		// is a word of its own.
+		{"一。二。三。", 4, 3},
+		{"问题？回答。继续！", 4, 6},
 		// Mixed ASCII + CJK: ASCII `.` and `!` both count, plus 。.
-		{"Hello. 中文。 World!", 4, 3},
+		{"Hello. 中文。 World!", 4, 4},
 	}
 	for _, c := range cases {
 		ub, w := cheapBounds(c.text)
@@ -183,24 +179,22 @@ func TestSentBufPool_ClearReleasesStringReferences(t *testing.T) {
 	})
 }
 
-// TestCheapBounds_FullWidthExclamQuestionNotSentBreaks pins the
-// invariant cheapBounds relies on: in the English Punkt pipeline
-// (the only one mdtext.SplitSentences runs), full-width ！ and ？
-// are word boundaries but NOT sentence boundaries. The cheapBounds
-// rune set excludes them on this basis; the test would have failed
-// red against an implementation that emitted SentBreak for either
-// rune, prompting a fix to keep the set aligned.
-func TestCheapBounds_FullWidthExclamQuestionNotSentBreaks(t *testing.T) {
+// TestCheapBounds_CoversCJKTerminals pins the invariant cheapBounds
+// relies on: every rune mdtext.SplitSentences breaks a sentence on
+// is in its terminal set, so the punct+1 bound holds for CJK text.
+func TestCheapBounds_CoversCJKTerminals(t *testing.T) {
 	for _, text := range []string{
 		"中文！更多",
 		"中文？更多",
 		"问题？回答！继续？",
+		"日本語です。「そうだ。」と言った。",
+		"半角｡句点",
 	} {
 		got := mdtext.SplitSentences(text)
-		require.Lenf(t, got, 1,
-			"text %q must segment as ONE sentence in the English "+
-				"pipeline because ！ / ？ are not sentence-break "+
-				"runes; got %v", text, got)
+		sentUB, _ := cheapBounds(text)
+		require.LessOrEqualf(t, len(got), sentUB,
+			"cheapBounds(%q) = %d must bound the %d sentences %v",
+			text, sentUB, len(got), got)
 	}
 }
 
@@ -494,3 +488,38 @@ func TestEnabledByDefault(t *testing.T) {
 func TestWordlistTarget(t *testing.T) {
 	assert.Equal(t, "placeholders", (&Rule{}).WordlistTarget())
 }
+
+func TestCheck_LangSelectsTrainedModel(t *testing.T) {
+	// "vgl." and "bzw." are German abbreviations: the German model
+	// keeps the paragraph at two sentences, the English one splits
+	// after each.
+	body := "Die Werte steigen, vgl. Tabelle drei. Das gilt bzw. Fall zwei auch."
+	f, err := lint.NewFile("t.md", []byte(body+"\n"))
+	require.NoError(t, err)
+
+	r := &Rule{MaxSentences: 2, MaxWords: 40, Lang: "en"}
+	assert.Len(t, r.Check(f), 1, "English model over-splits")
+
+	r.Lang = "de"
+	assert.Empty(t, r.Check(f))
+}
+
+func TestCheck_FrontMatterLangOverridesSetting(t *testing.T) {
+	body := "Die Werte steigen, vgl. Tabelle drei. Das gilt bzw. Fall zwei auch."
+	f, err := lint.NewFile("t.md", []byte(body+"\n"))
+	require.NoError(t, err)
+	f.FrontMatter = []byte("---\nlang: de\n---\n")
+
+	r := &Rule{MaxSentences: 2, MaxWords: 40, Lang: "en"}
+	assert.Empty(t, r.Check(f))
+}
+
+func TestApplySettings_Lang(t *testing.T) {
+	r := &Rule{}
+	require.NoError(t, r.ApplySettings(map[string]any{"lang": "de-CH"}))
+	assert.Equal(t, "de", r.Lang)
+
+	assert.Error(t, r.ApplySettings(map[string]any{"lang": "ja"}))
+	assert.Error(t, r.ApplySettings(map[string]any{"lang": 3}))
+	assert.Equal(t, mdtext.DefaultLang, r.DefaultSettings()["lang"])
+}
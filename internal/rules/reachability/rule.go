@@ -0,0 +1,344 @@
+// Package reachability implements MDS085, which flags Markdown files
+// no chain of links reaches from the workspace entry points, files
+// missing a link from a required index page, and, optionally, assets
+// no Markdown file references.
+package reachability
+
+import (
+	"fmt"
+	"io/fs"
+	"path"
+	"path/filepath"
+	"slices"
+	"strings"
+	"sync"
+
+	"github.com/bmatcuk/doublestar/v4"
+
+	"github.com/jeduden/mdsmith/internal/bytelimit"
+	"github.com/jeduden/mdsmith/internal/docgraph"
+	"github.com/jeduden/mdsmith/internal/globpath"
+	"github.com/jeduden/mdsmith/internal/linkgraph"
+	"github.com/jeduden/mdsmith/internal/lint"
+	"github.com/jeduden/mdsmith/internal/mdpath"
+	"github.com/jeduden/mdsmith/internal/rule"
+	"github.com/jeduden/mdsmith/internal/rules/settings"
+)
+
+func init() {
+	rule.Register(&Rule{EntryPoints: []string{"README.md"}})
+}
+
+// Rule checks that every Markdown file is reachable from an entry
+// point through the workspace document graph.
+type Rule struct {
+	// EntryPoints are globs naming the files readers start from.
+	EntryPoints []string
+	// LinkedFrom, when set, requires a direct edge from a file
+	// matching one of these globs. Set it per kind to say, for
+	// example, that every ADR must be linked from the ADR index.
+	LinkedFrom []string
+	// Exempt are globs of files the rule never reports.
+	Exempt []string
+	// Assets are globs of non-Markdown files that must be referenced
+	// by some Markdown file. Empty disables the asset check.
+	Assets []string
+	// Ignore are globs of files left out of the graph. The config
+	// layer adds the top-level `ignore:` list.
+	Ignore []string
+}
+
+// EnabledByDefault implements rule.Defaultable. MDS085 is opt-in: it
+// walks the whole workspace, and most trees hold drafts and notes that
+// are unreachable on purpose until a project lists them as exempt.
+func (r *Rule) EnabledByDefault() bool { return false }
+
+// ID implements rule.Rule.
+func (r *Rule) ID() string { return "MDS085" }
+
+// Name implements rule.Rule.
+func (r *Rule) Name() string { return "reachability" }
+
+// Category implements rule.Rule.
+func (r *Rule) Category() string { return "link" }
+
+// Check implements rule.Rule.
+func (r *Rule) Check(f *lint.File) []lint.Diagnostic {
+	// Stdin has no workspace to walk.
+	if f.FS == nil {
+		return nil
+	}
+	corpus, self, rootDir := resolveCorpus(f)
+	if !r.mayHaveEntries(corpus) {
+		return nil
+	}
+	g := r.corpusGraph(f, corpus, rootDir)
+	entries := matching(g.graph.Nodes, r.EntryPoints)
+
+	var diags []lint.Diagnostic
+	exempt := globpath.MatchAny(r.Exempt, self)
+	if len(entries) > 0 && !exempt && !slices.Contains(entries, self) &&
+		!g.reachable(entries)[self] {
+		diags = append(diags, r.diag(f, fmt.Sprintf(
+			"file is unreachable from the entry points (%s)",
+			strings.Join(r.EntryPoints, ", "))))
+	}
+	if len(r.LinkedFrom) > 0 && !exempt && !linkedFrom(g.graph.Sources(self), r.LinkedFrom) {
+		diags = append(diags, r.diag(f, fmt.Sprintf(
+			"file is not linked from %s", strings.Join(r.LinkedFrom, ", "))))
+	}
+	// Asset findings belong to no Markdown file; report them once, on
+	// the first entry point, so each shows up a single time per run.
+	if len(entries) > 0 && entries[0] == self {
+		for _, a := range g.assets {
+			if len(g.graph.Sources(a)) == 0 && !globpath.MatchAny(r.Exempt, a) {
+				diags = append(diags, r.diag(f, fmt.Sprintf(
+					"asset %q is not referenced by any Markdown file", a)))
+			}
+		}
+	}
+	return diags
+}
+
+func (r *Rule) diag(f *lint.File, msg string) lint.Diagnostic {
+	return lint.Diagnostic{
+		File:     f.Path,
+		Line:     1,
+		Column:   1,
+		RuleID:   r.ID(),
+		RuleName: r.Name(),
+		Severity: lint.Warning,
+		Message:  msg,
+	}
+}
+
+// mayHaveEntries reports whether an entry point can exist in corpus
+// without walking it: a literal path is checked with Stat, and any
+// glob is assumed to match. A tree without an entry point, such as a
+// scratch directory, then costs no walk.
+func (r *Rule) mayHaveEntries(corpus fs.FS) bool {
+	for _, p := range r.EntryPoints {
+		if strings.ContainsAny(p, "*?[{!") {
+			return true
+		}
+		if _, err := fs.Stat(corpus, p); err == nil {
+			return true
+		}
+	}
+	return len(r.LinkedFrom) > 0
+}
+
+// matching returns the nodes matching any of patterns, in node order.
+func matching(nodes, patterns []string) []string {
+	var out []string
+	for _, n := range nodes {
+		if globpath.MatchAny(patterns, n) {
+			out = append(out, n)
+		}
+	}
+	return out
+}
+
+func linkedFrom(sources, patterns []string) bool {
+	for _, s := range sources {
+		if globpath.MatchAny(patterns, s) {
+			return true
+		}
+	}
+	return false
+}
+
+// graphIndex is the document graph over the workspace's Markdown files
+// and the assets the rule tracks. Built once per run and read-only
+// afterwards apart from the reachability memo, which mu guards.
+type graphIndex struct {
+	graph  *docgraph.Graph
+	assets []string
+
+	mu    sync.Mutex
+	reach map[string]map[string]bool
+}
+
+// reachable returns the files reachable from entries, computed once
+// per distinct entry set: per-kind settings can give files different
+// entry points in the same run.
+func (g *graphIndex) reachable(entries []string) map[string]bool {
+	key := strings.Join(entries, "\x00")
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	if set, ok := g.reach[key]; ok {
+		return set
+	}
+	set := g.graph.Reachable(entries)
+	g.reach[key] = set
+	return set
+}
+
+// corpusGraph returns the graph for corpus, built at most once per run
+// via the RunCache when the corpus has a stable root. The per-File memo
+// fallback serves fixtures and in-memory callers. The asset and ignore
+// globs are part of the key because they decide which files become
+// nodes.
+func (r *Rule) corpusGraph(f *lint.File, corpus fs.FS, rootDir string) *graphIndex {
+	build := func() any { return r.buildGraph(f, corpus, rootDir) }
+	key := "MDS085\x00" + rootDir + "\x00" + strings.Join(r.Assets, "\x00") +
+		"\x00\x00" + strings.Join(r.Ignore, "\x00")
+	var v any
+	if f.RunCache != nil && rootDir != "" {
+		v = f.RunCache.CorpusIndex(key, build)
+	} else {
+		v = f.Memo(key, build)
+	}
+	return v.(*graphIndex)
+}
+
+// buildGraph walks corpus and collects the edges of every Markdown
+// file. Hidden entries, node_modules, gitignored paths, and paths
+// matching Ignore are left out, as lint file discovery leaves them
+// out. Unreadable files stay nodes without edges: one broken file
+// must not make its link targets look unreachable from everywhere
+// else, but it cannot vouch for them either.
+func (r *Rule) buildGraph(f *lint.File, corpus fs.FS, rootDir string) *graphIndex {
+	skip := r.skipper(f, rootDir)
+	var docs, others []string
+	_ = fs.WalkDir(corpus, ".", func(p string, d fs.DirEntry, err error) error {
+		if err != nil || p == "." {
+			return nil
+		}
+		if skip(p, d.IsDir()) {
+			if d.IsDir() {
+				return fs.SkipDir
+			}
+			return nil
+		}
+		if d.IsDir() {
+			return nil
+		}
+		switch {
+		case mdpath.IsMarkdownPath(p):
+			docs = append(docs, p)
+		case len(r.Assets) > 0 && globpath.MatchAny(r.Assets, p):
+			others = append(others, p)
+		}
+		return nil
+	})
+
+	var wikilinks *linkgraph.WikilinkIndex
+	if rootDir != "" {
+		wikilinks = linkgraph.WikilinkIndexFor(f.RunCache, rootDir, corpus)
+	} else {
+		wikilinks = linkgraph.NewWikilinkIndex(corpus)
+	}
+	var edges []docgraph.Edge
+	for _, p := range docs {
+		data, err := bytelimit.ReadFSFileLimited(corpus, p, f.MaxInputBytes)
+		if err != nil {
+			continue
+		}
+		src, _ := lint.NewFileFromSource(p, data, f.StripFrontMatter) //nolint:errcheck
+		edges = append(edges, docgraph.FileEdges(p, src, docs, wikilinks)...)
+	}
+	return &graphIndex{
+		graph:  docgraph.New(append(docs, others...), edges),
+		assets: others,
+		reach:  map[string]map[string]bool{},
+	}
+}
+
+// skipper returns the walk filter buildGraph applies to each corpus
+// path. Gitignore lookups need an absolute base: the project root, or
+// the checked file's directory when the corpus falls back to it.
+func (r *Rule) skipper(f *lint.File, rootDir string) func(p string, isDir bool) bool {
+	ignore := f.GetGitignore()
+	base := rootDir
+	if base == "" {
+		base, _ = filepath.Abs(filepath.Dir(f.Path))
+	}
+	return func(p string, isDir bool) bool {
+		name := path.Base(p)
+		if strings.HasPrefix(name, ".") || (isDir && name == "node_modules") {
+			return true
+		}
+		if globpath.MatchAny(r.Ignore, p) {
+			return true
+		}
+		return ignore != nil && base != "" &&
+			ignore.IsIgnored(filepath.Join(base, filepath.FromSlash(p)), isDir)
+	}
+}
+
+// resolveCorpus returns the filesystem to walk, the checked file's
+// path inside it, and the root directory that keys the run cache.
+// The project root wins when the file lies under it; otherwise the
+// file's own directory stands in, with no cache key.
+func resolveCorpus(f *lint.File) (corpus fs.FS, self string, rootDir string) {
+	if f.RootFS != nil && f.RootDir != "" {
+		if rel, ok := rootRelative(f.RootDir, f.Path); ok {
+			return f.RootFS, rel, f.RootDir
+		}
+	}
+	return f.FS, filepath.Base(f.Path), ""
+}
+
+// rootRelative returns p relative to rootDir with forward slashes,
+// or ok=false when p lies outside rootDir.
+func rootRelative(rootDir, p string) (string, bool) {
+	abs, err := filepath.Abs(p)
+	if err != nil {
+		return "", false
+	}
+	rel, err := filepath.Rel(rootDir, abs)
+	if err != nil {
+		return "", false
+	}
+	rel = filepath.ToSlash(rel)
+	if rel == ".." || strings.HasPrefix(rel, "../") {
+		return "", false
+	}
+	return rel, true
+}
+
+// ApplySettings implements rule.Configurable.
+func (r *Rule) ApplySettings(cfg map[string]any) error {
+	for k, v := range cfg {
+		var dst *[]string
+		switch k {
+		case "entry-points":
+			dst = &r.EntryPoints
+		case "linked-from":
+			dst = &r.LinkedFrom
+		case "exempt":
+			dst = &r.Exempt
+		case "assets":
+			dst = &r.Assets
+		case "ignore":
+			dst = &r.Ignore
+		default:
+			return fmt.Errorf("reachability: unknown setting %q", k)
+		}
+		list, ok := settings.ToStringSlice(v)
+		if !ok {
+			return fmt.Errorf("reachability: %s must be a list of strings, got %T", k, v)
+		}
+		for _, p := range list {
+			if !doublestar.ValidatePattern(strings.TrimPrefix(p, "!")) {
+				return fmt.Errorf("reachability: %s has invalid glob pattern %q", k, p)
+			}
+		}
+		*dst = list
+	}
+	return nil
+}
+
+// DefaultSettings implements rule.Configurable.
+func (r *Rule) DefaultSettings() map[string]any {
+	return map[string]any{
+		"entry-points": []string{"README.md"},
+		"linked-from":  []string{},
+		"exempt":       []string{},
+		"assets":       []string{},
+		"ignore":       []string{},
+	}
+}
+
+var _ rule.Configurable = (*Rule)(nil)
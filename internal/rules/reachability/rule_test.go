@@ -0,0 +1,173 @@
+package reachability
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"testing/fstest"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/jeduden/mdsmith/internal/gitignore"
+	"github.com/jeduden/mdsmith/internal/lint"
+)
+
+// handbookFS is a workspace where README reaches the guide through a
+// link, the ADR index through a catalog, and one ADR through a
+// wikilink; the stray page and the old ADR are reached by nothing.
+var handbookFS = fstest.MapFS{
+	"README.md": {Data: []byte("# Handbook\n\nRead the [guide](docs/guide.md) and the " +
+		"[decisions](docs/adr/README.md).\n\n![logo](img/logo.svg)\n")},
+	"docs/guide.md": {Data: []byte("# Guide\n\nSee [[0002-wikilinked]].\n")},
+	"docs/adr/README.md": {Data: []byte("# Decisions\n\n<?catalog\nglob: \"0001-*.md\"\n" +
+		"row: \"- {filename}\"\n?>\n<?/catalog?>\n")},
+	"docs/adr/0001-catalogued.md":  {Data: []byte("# One\n")},
+	"docs/adr/0002-wikilinked.md":  {Data: []byte("# Two\n")},
+	"docs/adr/0003-forgotten.md":   {Data: []byte("# Three\n")},
+	"docs/stray.md":                {Data: []byte("# Stray\n\nBack [home](../README.md).\n")},
+	"img/logo.svg":                 {Data: []byte("<svg/>\n")},
+	"img/unused.svg":               {Data: []byte("<svg/>\n")},
+	"node_modules/pkg/README.md":   {Data: []byte("# Vendored\n")},
+	"drafts/idea.md":               {Data: []byte("# Idea\n")},
+	"docs/adr/template/example.md": {Data: []byte("# Example\n")},
+}
+
+// check runs r on the workspace file at p with a shared RunCache.
+func check(t *testing.T, r *Rule, fsys fstest.MapFS, p string, rc *lint.RunCache) []string {
+	t.Helper()
+	f, err := lint.NewFile(p, fsys[p].Data)
+	require.NoError(t, err)
+	f.FS = fsys
+	f.RootFS = fsys
+	f.RootDir = "/ws"
+	f.Path = f.RootDir + "/" + p
+	f.RunCache = rc
+	var msgs []string
+	for _, d := range r.Check(f) {
+		msgs = append(msgs, d.Message)
+	}
+	return msgs
+}
+
+func newRule(t *testing.T, cfg map[string]any) *Rule {
+	t.Helper()
+	r := &Rule{EntryPoints: []string{"README.md"}}
+	require.NoError(t, r.ApplySettings(cfg))
+	return r
+}
+
+func TestCheck_Reachability(t *testing.T) {
+	r := newRule(t, nil)
+	rc := lint.NewRunCache()
+	for _, p := range []string{"README.md", "docs/guide.md", "docs/adr/README.md",
+		"docs/adr/0001-catalogued.md", "docs/adr/0002-wikilinked.md"} {
+		assert.Empty(t, check(t, r, handbookFS, p, rc), p)
+	}
+	assert.Equal(t, []string{"file is unreachable from the entry points (README.md)"},
+		check(t, r, handbookFS, "docs/stray.md", rc))
+	assert.Len(t, check(t, r, handbookFS, "docs/adr/0003-forgotten.md", rc), 1)
+}
+
+func TestCheck_Exempt(t *testing.T) {
+	r := newRule(t, map[string]any{"exempt": []any{"drafts/**", "docs/stray.md"}})
+	assert.Empty(t, check(t, r, handbookFS, "drafts/idea.md", nil))
+	assert.Empty(t, check(t, r, handbookFS, "docs/stray.md", nil))
+}
+
+func TestCheck_LinkedFrom(t *testing.T) {
+	// A per-kind override: ADRs must be listed on the ADR index, so a
+	// wikilink from the guide is not enough.
+	r := newRule(t, map[string]any{"linked-from": []any{"docs/adr/README.md"}})
+	assert.Empty(t, check(t, r, handbookFS, "docs/adr/0001-catalogued.md", nil))
+	assert.Equal(t, []string{"file is not linked from docs/adr/README.md"},
+		check(t, r, handbookFS, "docs/adr/0002-wikilinked.md", nil))
+}
+
+func TestCheck_EntryGlobs(t *testing.T) {
+	r := newRule(t, map[string]any{"entry-points": []any{"README.md", "docs/adr/*/**"}})
+	assert.Empty(t, check(t, r, handbookFS, "docs/adr/template/example.md", nil))
+}
+
+func TestCheck_Assets(t *testing.T) {
+	r := newRule(t, map[string]any{"assets": []any{"img/*.svg"}})
+	rc := lint.NewRunCache()
+	assert.Equal(t, []string{`asset "img/unused.svg" is not referenced by any Markdown file`},
+		check(t, r, handbookFS, "README.md", rc))
+	// Reported once, on the first entry point only.
+	assert.Empty(t, check(t, r, handbookFS, "docs/guide.md", rc))
+
+	r = newRule(t, map[string]any{"assets": []any{"img/*.svg"}, "exempt": []any{"img/unused.svg"}})
+	assert.Empty(t, check(t, r, handbookFS, "README.md", nil))
+}
+
+func TestCheck_IgnoredPathsLeaveTheGraph(t *testing.T) {
+	dir := t.TempDir()
+	write := func(name, content string) {
+		p := filepath.Join(dir, filepath.FromSlash(name))
+		require.NoError(t, os.MkdirAll(filepath.Dir(p), 0o755))
+		require.NoError(t, os.WriteFile(p, []byte(content), 0o644))
+	}
+	write(".gitignore", "build/\n")
+	write("README.md", "# Home\n\n![logo](img/logo.png)\n")
+	write("img/logo.png", "png")
+	write("img/unused.png", "png")
+	write("build/gen.png", "png")
+	write(".github/banner.png", "png")
+	write("tmp/old.png", "png")
+	// An ignored page's links must not make its targets reachable.
+	write("tmp/notes.md", "# Notes\n\nSee [orphan](../orphan.md).\n")
+	write("orphan.md", "# Orphan\n")
+
+	run := func(p string) []string {
+		r := newRule(t, map[string]any{"assets": []any{"**/*.png"}, "ignore": []any{"tmp/**"}})
+		data, err := os.ReadFile(filepath.Join(dir, p))
+		require.NoError(t, err)
+		f, err := lint.NewFile(filepath.Join(dir, p), data)
+		require.NoError(t, err)
+		f.FS = os.DirFS(dir)
+		f.RootFS, f.RootDir = f.FS, dir
+		f.GitignoreFunc = func() *gitignore.Matcher { return gitignore.NewMatcher(dir) }
+		var msgs []string
+		for _, d := range r.Check(f) {
+			msgs = append(msgs, d.Message)
+		}
+		return msgs
+	}
+	assert.Equal(t, []string{`asset "img/unused.png" is not referenced by any Markdown file`},
+		run("README.md"))
+	assert.Len(t, run("orphan.md"), 1)
+}
+
+func TestCheck_NoEntryPointIsInert(t *testing.T) {
+	fsys := fstest.MapFS{
+		"a.md": {Data: []byte("# A\n")},
+		"b.md": {Data: []byte("# B\n")},
+	}
+	assert.Empty(t, check(t, newRule(t, nil), fsys, "a.md", nil))
+}
+
+func TestCheck_NoFS(t *testing.T) {
+	f, err := lint.NewFile("-", []byte("# Stdin\n"))
+	require.NoError(t, err)
+	assert.Empty(t, newRule(t, nil).Check(f))
+}
+
+func TestApplySettings_Errors(t *testing.T) {
+	cases := map[string]struct {
+		cfg  map[string]any
+		want string
+	}{
+		"unknown":    {map[string]any{"entry": []any{"x"}}, `reachability: unknown setting "entry"`},
+		"not list":   {map[string]any{"exempt": 3}, "reachability: exempt must be a list of strings, got int"},
+		"bad glob":   {map[string]any{"assets": []any{"img/[a"}}, `reachability: assets has invalid glob pattern "img/[a"`},
+		"bad ignore": {map[string]any{"ignore": []any{"[a"}}, `reachability: ignore has invalid glob pattern "[a"`},
+	}
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			err := (&Rule{}).ApplySettings(tc.cfg)
+			require.Error(t, err)
+			assert.Equal(t, tc.want, err.Error())
+		})
+	}
+}
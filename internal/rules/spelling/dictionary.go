@@ -0,0 +1,105 @@
+package spelling
+
+import (
+	"fmt"
+	"io/fs"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/jeduden/mdsmith/internal/hunspell"
+	"github.com/jeduden/mdsmith/internal/lint"
+)
+
+// dictionariesDir is the directory under the project root that holds
+// project dictionaries: one `<name>.aff` + `<name>.dic` pair each.
+const dictionariesDir = ".mdsmith/dictionaries"
+
+// dictEntry caches one parsed project dictionary, invalidated when
+// either file's size or mtime changes, so a long-lived LSP session
+// picks up an edited word list.
+type dictEntry struct {
+	dict                   *hunspell.Dictionary
+	affSize, dicSize       int64
+	affModTime, dicModTime time.Time
+}
+
+var dictCache sync.Map
+
+// loadDictionary returns the dictionary called name: the project pair
+// under .mdsmith/dictionaries when its .aff exists — read from the
+// project root (f.RootFS), falling back to the file's directory FS —
+// else the built-in one. Only root-relative reads are cached, as in
+// metricregression: the directory FS has no stable identity to key
+// on. A nil dictionary with a nil error means this build has none
+// (js/wasm without a project dictionary), which checks nothing.
+func loadDictionary(f *lint.File, name string) (*hunspell.Dictionary, error) {
+	fsys, cacheable := f.RootFS, true
+	if fsys == nil {
+		fsys, cacheable = f.FS, false
+	}
+	if fsys != nil {
+		d, found, err := loadProject(fsys, f.RootDir, name, cacheable)
+		if found || err != nil {
+			return d, err
+		}
+	}
+	d, ok, err := hunspell.Embedded(name)
+	if err != nil {
+		return nil, fmt.Errorf("spelling: %w", err)
+	}
+	if ok {
+		return d, nil
+	}
+	builtin := hunspell.EmbeddedNames()
+	if len(builtin) == 0 {
+		return nil, nil
+	}
+	return nil, fmt.Errorf("spelling: dictionary %q not found in %s/ or built in (%s)",
+		name, dictionariesDir, strings.Join(builtin, ", "))
+}
+
+// loadProject reads the project dictionary pair. found is false when
+// there is no .aff file for name.
+func loadProject(fsys fs.FS, root, name string, cacheable bool) (*hunspell.Dictionary, bool, error) {
+	affPath := dictionariesDir + "/" + name + ".aff"
+	dicPath := dictionariesDir + "/" + name + ".dic"
+	affInfo, err := fs.Stat(fsys, affPath)
+	if err != nil {
+		return nil, false, nil
+	}
+	dicInfo, err := fs.Stat(fsys, dicPath)
+	if err != nil {
+		return nil, true, fmt.Errorf("spelling: cannot read %s: %w", dicPath, err)
+	}
+	key := root + "\x00" + name
+	if cacheable {
+		if v, ok := dictCache.Load(key); ok {
+			e := v.(dictEntry)
+			if e.affSize == affInfo.Size() && e.affModTime.Equal(affInfo.ModTime()) &&
+				e.dicSize == dicInfo.Size() && e.dicModTime.Equal(dicInfo.ModTime()) {
+				return e.dict, true, nil
+			}
+		}
+	}
+	aff, err := fs.ReadFile(fsys, affPath)
+	if err != nil {
+		return nil, true, fmt.Errorf("spelling: cannot read %s: %w", affPath, err)
+	}
+	dic, err := fs.ReadFile(fsys, dicPath)
+	if err != nil {
+		return nil, true, fmt.Errorf("spelling: cannot read %s: %w", dicPath, err)
+	}
+	d, err := hunspell.Parse(aff, dic)
+	if err != nil {
+		return nil, true, fmt.Errorf("spelling: dictionary %s: %w", name, err)
+	}
+	if cacheable {
+		dictCache.Store(key, dictEntry{
+			dict:    d,
+			affSize: affInfo.Size(), affModTime: affInfo.ModTime(),
+			dicSize: dicInfo.Size(), dicModTime: dicInfo.ModTime(),
+		})
+	}
+	return d, true, nil
+}
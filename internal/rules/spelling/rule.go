@@ -0,0 +1,237 @@
+// Package spelling implements MDS076, an opt-in rule that flags
+// words its Hunspell dictionary does not know. The dictionary is a
+// project's own `.mdsmith/dictionaries/<name>.aff` + `.dic` pair or
+// the en_US dictionary built into the binary (internal/hunspell).
+// Code, URLs, front-matter keys, and configured placeholders are
+// never checked, and the `words:` list (fed by `lists:` wordlists)
+// extends the dictionary with project vocabulary.
+//
+// The js/wasm build embeds no dictionary; without a project
+// dictionary there the rule reports nothing.
+package spelling
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/jeduden/mdsmith/internal/lint"
+	"github.com/jeduden/mdsmith/internal/placeholders"
+	"github.com/jeduden/mdsmith/internal/rule"
+	"github.com/jeduden/mdsmith/internal/rules/settings"
+)
+
+func init() {
+	rule.Register(newRule())
+}
+
+func newRule() *Rule {
+	return &Rule{Dictionary: defaultDictionary, FrontMatter: true, MinLength: 3, MaxSuggestions: 3}
+}
+
+// defaultDictionary is the built-in dictionary the rule checks
+// against when none is configured.
+const defaultDictionary = "en_US"
+
+// dictionaryNameRE is the name a dictionary setting must match: it
+// becomes a file name under .mdsmith/dictionaries, so no separators
+// and no leading dot.
+var dictionaryNameRE = regexp.MustCompile(`^[A-Za-z0-9][A-Za-z0-9_.-]*$`)
+
+// Rule reports words that are not in the configured dictionary.
+//
+// accepted is built once by ApplySettings from Words, for the same
+// CloneInstance reason propernames documents on its entries field:
+// a lazily-filled cache would race the worker copy.
+type Rule struct {
+	// Dictionary names the Hunspell dictionary: a project pair under
+	// .mdsmith/dictionaries, else a built-in one.
+	Dictionary string
+	// Words lists extra accepted words. It appends across config
+	// layers so kind layers extend the project vocabulary.
+	Words []string
+	// Placeholders lists the placeholder tokens whose text is not
+	// checked (see internal/placeholders).
+	Placeholders []string
+	// FrontMatter enables checking front-matter string values.
+	FrontMatter bool
+	// MinLength is the shortest word, in characters, that is checked.
+	MinLength int
+	// MaxSuggestions caps the replacements offered per word.
+	MaxSuggestions int
+
+	accepted map[string]bool
+}
+
+// ID implements rule.Rule.
+func (r *Rule) ID() string { return "MDS076" }
+
+// Name implements rule.Rule.
+func (r *Rule) Name() string { return "spelling" }
+
+// Category implements rule.Rule.
+func (r *Rule) Category() string { return "prose" }
+
+// EnabledByDefault implements rule.Defaultable. A dictionary never
+// knows every project's vocabulary, so the rule is opt-in.
+func (r *Rule) EnabledByDefault() bool { return false }
+
+// WordlistTarget implements rule.WordlistConsumer: resolved `lists:`
+// entries union into this rule's "words" setting.
+func (r *Rule) WordlistTarget() string { return "words" }
+
+// Check implements rule.Rule.
+func (r *Rule) Check(f *lint.File) []lint.Diagnostic {
+	dict, err := loadDictionary(f, r.Dictionary)
+	if err != nil {
+		return []lint.Diagnostic{r.diag(f, 1, 1, 0, err.Error())}
+	}
+	if dict == nil {
+		return nil
+	}
+	c := checker{r: r, dict: dict, accepted: r.accepted}
+	if c.accepted == nil {
+		c.accepted = acceptedSet(r.Words)
+	}
+
+	var diags []lint.Diagnostic
+	if r.FrontMatter && !placeholders.HasCUEFrontmatter(r.Placeholders) {
+		for _, m := range c.frontMatterMisses(f.FrontMatter) {
+			// m.line is the raw file line; rules emit body-coordinate
+			// lines and AdjustDiagnostics adds LineOffset back.
+			diags = append(diags, r.missDiag(f, m, m.line-f.LineOffset, m.col))
+		}
+	}
+	body := f
+	if body.AST == nil {
+		body = reparsed(f)
+	}
+	for _, m := range c.proseMisses(body) {
+		diags = append(diags, r.missDiag(f, m, f.LineOfOffset(m.start), f.ColumnOfOffset(m.start)))
+	}
+	return diags
+}
+
+// reparsed returns an AST-bearing File over f's source for the
+// parse-skipped path, as propernames does: ProseRanges needs the
+// tree, and every offset it yields is valid in f's identical Source.
+func reparsed(f *lint.File) *lint.File {
+	rf, err := lint.NewFile(f.Path, f.Source)
+	if err != nil {
+		return f
+	}
+	return rf
+}
+
+func (r *Rule) missDiag(f *lint.File, m miss, line, col int) lint.Diagnostic {
+	d := r.diag(f, line, col, col+len(m.word), unknownMessage(m.word, m.suggestions))
+	d.Suggestions = m.suggestions
+	return d
+}
+
+func (r *Rule) diag(f *lint.File, line, col, endCol int, msg string) lint.Diagnostic {
+	return lint.Diagnostic{
+		File:      f.Path,
+		Line:      line,
+		Column:    col,
+		EndColumn: endCol,
+		RuleID:    r.ID(),
+		RuleName:  r.Name(),
+		Severity:  lint.Warning,
+		Message:   msg,
+	}
+}
+
+func unknownMessage(word string, suggestions []string) string {
+	msg := "unknown word " + strconv.Quote(word)
+	if len(suggestions) == 0 {
+		return msg
+	}
+	quoted := make([]string, len(suggestions))
+	for i, s := range suggestions {
+		quoted[i] = strconv.Quote(s)
+	}
+	return msg + "; did you mean " + strings.Join(quoted, ", ") + "?"
+}
+
+// ApplySettings implements rule.Configurable.
+func (r *Rule) ApplySettings(s map[string]any) error {
+	for k, v := range s {
+		switch k {
+		case "dictionary":
+			name, ok := v.(string)
+			if !ok {
+				return fmt.Errorf("spelling: dictionary must be a string, got %T", v)
+			}
+			if !dictionaryNameRE.MatchString(name) {
+				return fmt.Errorf("spelling: dictionary %q must be a plain name like \"en_US\"", name)
+			}
+			r.Dictionary = name
+		case "words":
+			words, ok := settings.ToStringSlice(v)
+			if !ok {
+				return fmt.Errorf("spelling: words must be a list of strings, got %T", v)
+			}
+			r.Words = words
+			r.accepted = acceptedSet(words)
+		case "placeholders":
+			toks, ok := settings.ToStringSlice(v)
+			if !ok {
+				return fmt.Errorf("spelling: placeholders must be a list of strings, got %T", v)
+			}
+			if err := placeholders.Validate(toks); err != nil {
+				return fmt.Errorf("spelling: %w", err)
+			}
+			r.Placeholders = toks
+		case "front-matter":
+			b, ok := v.(bool)
+			if !ok {
+				return fmt.Errorf("spelling: front-matter must be a bool, got %T", v)
+			}
+			r.FrontMatter = b
+		case "min-length", "max-suggestions":
+			n, ok := settings.ToInt(v)
+			if !ok || n < 0 {
+				return fmt.Errorf("spelling: %s must be a non-negative integer, got %v", k, v)
+			}
+			if k == "min-length" {
+				r.MinLength = n
+			} else {
+				r.MaxSuggestions = n
+			}
+		default:
+			return fmt.Errorf("spelling: unknown setting %q", k)
+		}
+	}
+	return nil
+}
+
+// DefaultSettings implements rule.Configurable.
+func (r *Rule) DefaultSettings() map[string]any {
+	return map[string]any{
+		"dictionary":      defaultDictionary,
+		"words":           []string{},
+		"placeholders":    []string{},
+		"front-matter":    true,
+		"min-length":      3,
+		"max-suggestions": 3,
+	}
+}
+
+// SettingMergeMode implements rule.ListMerger. words and placeholders
+// append across config layers, matching proper-names and the
+// paragraph rules.
+func (r *Rule) SettingMergeMode(key string) rule.MergeMode {
+	if key == "words" || key == "placeholders" {
+		return rule.MergeAppend
+	}
+	return rule.MergeReplace
+}
+
+var (
+	_ rule.Configurable     = (*Rule)(nil)
+	_ rule.Defaultable      = (*Rule)(nil)
+	_ rule.ListMerger       = (*Rule)(nil)
+	_ rule.WordlistConsumer = (*Rule)(nil)
+)
@@ -0,0 +1,183 @@
+package spelling
+
+import (
+	"testing"
+	"testing/fstest"
+
+	"github.com/jeduden/mdsmith/internal/lint"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func check(t *testing.T, r *Rule, src string) []lint.Diagnostic {
+	t.Helper()
+	f, err := lint.NewFileFromSource("doc.md", []byte(src), true)
+	require.NoError(t, err)
+	diags := r.Check(f)
+	f.AdjustDiagnostics(diags)
+	return diags
+}
+
+func unknown(diags []lint.Diagnostic) []string {
+	var out []string
+	for _, d := range diags {
+		out = append(out, d.Message)
+	}
+	return out
+}
+
+func TestRule_Metadata(t *testing.T) {
+	r := newRule()
+	assert.Equal(t, "MDS076", r.ID())
+	assert.Equal(t, "spelling", r.Name())
+	assert.Equal(t, "prose", r.Category())
+	assert.False(t, r.EnabledByDefault())
+	assert.Equal(t, "words", r.WordlistTarget())
+}
+
+func TestCheck_FlagsUnknownWordWithSuggestions(t *testing.T) {
+	diags := check(t, newRule(), "# Notes\n\nWe recieve the files.\n")
+	require.Len(t, diags, 1)
+	d := diags[0]
+	assert.Equal(t, 3, d.Line)
+	assert.Equal(t, 4, d.Column)
+	assert.Equal(t, 11, d.EndColumn)
+	assert.Equal(t, `unknown word "recieve"; did you mean "receive", "relieve"?`, d.Message)
+	assert.Equal(t, []string{"receive", "relieve"}, d.Suggestions)
+}
+
+func TestCheck_SkipsNonProse(t *testing.T) {
+	src := "# Notes\n\n" +
+		"Code `teh` and <https://teh.example> and [link](https://x.y/teh).\n\n" +
+		"Paths like docs/teh.md, config.teh, and teh_var or me@teh.org.\n\n" +
+		"```go\nteh := 1\n```\n\n" +
+		"<div>teh</div>\n\n" +
+		"Acronyms TEH, camel tehThing, mixed GitHub, digits teh2 and 3rd.\n"
+	assert.Empty(t, check(t, newRule(), src))
+}
+
+func TestCheck_Apostrophes(t *testing.T) {
+	src := "# Notes\n\nThe parser's output, the users' files, don't and can’t, 'quoted'.\n"
+	assert.Empty(t, check(t, newRule(), src))
+}
+
+func TestCheck_HyphensSplitWords(t *testing.T) {
+	diags := check(t, newRule(), "# Notes\n\nA well-knwon fact.\n")
+	require.Len(t, diags, 1)
+	assert.Equal(t, 8, diags[0].Column)
+	assert.Contains(t, diags[0].Message, `"knwon"`)
+}
+
+func TestCheck_WordsSetting(t *testing.T) {
+	r := newRule()
+	require.NoError(t, r.ApplySettings(map[string]any{
+		"words":           []any{"mdsmith", "Kubernetes"},
+		"max-suggestions": 0,
+	}))
+	src := "# Notes\n\nRun mdsmith on Kubernetes. Mdsmith starts the kubernetes pods.\n"
+	assert.Equal(t, []string{`unknown word "kubernetes"`}, unknown(check(t, r, src)),
+		"a lowercase entry accepts its capitalized form; a capitalized one is exact")
+}
+
+func TestCheck_MinLength(t *testing.T) {
+	r := newRule()
+	assert.Empty(t, check(t, r, "# Notes\n\nA zz here.\n"))
+	require.NoError(t, r.ApplySettings(map[string]any{"min-length": 2, "max-suggestions": 0}))
+	assert.Equal(t, []string{`unknown word "zz"`}, unknown(check(t, r, "# Notes\n\nA zz here.\n")))
+}
+
+func TestCheck_Placeholders(t *testing.T) {
+	src := "# Notes\n\nHello {nmae}, welcome.\n"
+	require.Len(t, check(t, newRule(), src), 1)
+	r := newRule()
+	require.NoError(t, r.ApplySettings(map[string]any{"placeholders": []any{"var-token"}}))
+	assert.Empty(t, check(t, r, src))
+}
+
+func TestCheck_FrontMatterValues(t *testing.T) {
+	src := "---\ntitle: A speling note\nrecieve: fine\ntags: [docs, 'wrod']\nsummary: >-\n  teh folded text\n---\n# Notes\n\nBody.\n"
+	diags := check(t, newRule(), src)
+	require.Len(t, diags, 2, "keys and block scalars are not checked")
+	assert.Equal(t, 2, diags[0].Line)
+	assert.Equal(t, 10, diags[0].Column)
+	assert.Contains(t, diags[0].Message, `"speling"`)
+	assert.Equal(t, 4, diags[1].Line)
+	assert.Equal(t, 15, diags[1].Column, "column past the opening quote")
+	assert.Contains(t, diags[1].Message, `"wrod"`)
+
+	r := newRule()
+	require.NoError(t, r.ApplySettings(map[string]any{"front-matter": false}))
+	assert.Empty(t, check(t, r, src))
+}
+
+func TestCheck_NilASTMatchesAST(t *testing.T) {
+	src := []byte("# Notes\n\nWe recieve `teh` files and seperate them.\n")
+	withAST, err := lint.NewFile("doc.md", src)
+	require.NoError(t, err)
+	noAST := lint.NewFileLines("doc.md", src)
+	require.Nil(t, noAST.AST)
+	assert.Equal(t, newRule().Check(withAST), newRule().Check(noAST))
+}
+
+func TestCheck_ProjectDictionary(t *testing.T) {
+	root := fstest.MapFS{
+		".mdsmith/dictionaries/tiny.aff": {Data: []byte("SFX S Y 1\nSFX S 0 s .\n")},
+		".mdsmith/dictionaries/tiny.dic": {Data: []byte("3\nnote/S\nbody\nhere\n")},
+	}
+	r := newRule()
+	require.NoError(t, r.ApplySettings(map[string]any{"dictionary": "tiny"}))
+	f, err := lint.NewFile("doc.md", []byte("# Notes\n\nBody here. Other.\n"))
+	require.NoError(t, err)
+	f.RootFS, f.RootDir = root, "/"+t.Name()
+	assert.Equal(t, []string{`unknown word "Other"; did you mean "Here"?`}, unknown(r.Check(f)))
+
+	// Served from the cache on the second read.
+	assert.Len(t, r.Check(f), 1)
+}
+
+func TestCheck_DictionaryErrors(t *testing.T) {
+	for name, tc := range map[string]struct {
+		dict string
+		fs   fstest.MapFS
+		want string
+	}{
+		"missing": {"xx_XX", fstest.MapFS{}, `spelling: dictionary "xx_XX" not found in .mdsmith/dictionaries/ or built in (en_US)`},
+		"no dic": {"tiny", fstest.MapFS{".mdsmith/dictionaries/tiny.aff": {Data: []byte("")}},
+			"spelling: cannot read .mdsmith/dictionaries/tiny.dic"},
+		"bad aff": {"tiny", fstest.MapFS{
+			".mdsmith/dictionaries/tiny.aff": {Data: []byte("FLAG wide\n")},
+			".mdsmith/dictionaries/tiny.dic": {Data: []byte("")},
+		}, `spelling: dictionary tiny: affix file: line 1: unsupported FLAG "wide"`},
+	} {
+		t.Run(name, func(t *testing.T) {
+			f, err := lint.NewFile("doc.md", []byte("# Notes\n"))
+			require.NoError(t, err)
+			f.FS = tc.fs
+			diags := (&Rule{Dictionary: tc.dict, MinLength: 3}).Check(f)
+			require.Len(t, diags, 1)
+			assert.Contains(t, diags[0].Message, tc.want)
+			assert.Equal(t, 1, diags[0].Line)
+		})
+	}
+}
+
+func TestApplySettings_Errors(t *testing.T) {
+	for name, tc := range map[string]struct {
+		settings map[string]any
+		want     string
+	}{
+		"dictionary type": {map[string]any{"dictionary": 1}, "spelling: dictionary must be a string, got int"},
+		"dictionary path": {map[string]any{"dictionary": "../x"}, `spelling: dictionary "../x" must be a plain name like "en_US"`},
+		"words":           {map[string]any{"words": "x"}, "spelling: words must be a list of strings, got string"},
+		"placeholder":     {map[string]any{"placeholders": []any{"nope"}}, `spelling: unknown placeholder token "nope"`},
+		"front-matter":    {map[string]any{"front-matter": "yes"}, "spelling: front-matter must be a bool, got string"},
+		"min-length":      {map[string]any{"min-length": -1}, "spelling: min-length must be a non-negative integer, got -1"},
+		"unknown":         {map[string]any{"lang": "en"}, `spelling: unknown setting "lang"`},
+	} {
+		t.Run(name, func(t *testing.T) {
+			err := newRule().ApplySettings(tc.settings)
+			require.Error(t, err)
+			assert.Equal(t, tc.want, err.Error())
+		})
+	}
+}
@@ -0,0 +1,360 @@
+package spelling
+
+import (
+	"bytes"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/jeduden/mdsmith/internal/hunspell"
+	"github.com/jeduden/mdsmith/internal/lint"
+	"github.com/jeduden/mdsmith/internal/mdtext"
+	"github.com/jeduden/mdsmith/internal/placeholders"
+	"github.com/jeduden/mdsmith/internal/yamlutil"
+	"gopkg.in/yaml.v3"
+)
+
+// miss is one unknown word. word and suggestions are ordered first
+// as the struct's pointer-bearing fields
+// (docs/development/high-performance-go.md#struct-layout). start is
+// the byte offset in f.Source for a prose word; line and col place a
+// front-matter word in raw file coordinates.
+type miss struct {
+	word        string
+	suggestions []string
+	start       int
+	line        int
+	col         int
+}
+
+// checker holds the per-Check state: the dictionary, the accepted
+// words, and the suggestions already computed for a repeated word.
+type checker struct {
+	r        *Rule
+	dict     *hunspell.Dictionary
+	accepted map[string]bool
+	suggest  map[string][]string
+}
+
+// acceptedSet indexes the words setting, nil when it is empty.
+func acceptedSet(words []string) map[string]bool {
+	if len(words) == 0 {
+		return nil
+	}
+	set := make(map[string]bool, len(words))
+	for _, w := range words {
+		set[normalize(w)] = true
+	}
+	return set
+}
+
+// normalize folds the typographic apostrophe into the ASCII one the
+// dictionaries use.
+func normalize(w string) string {
+	if isASCII(w) {
+		return w
+	}
+	return strings.ReplaceAll(w, "’", "'")
+}
+
+func isASCII(w string) bool {
+	for i := 0; i < len(w); i++ {
+		if w[i] >= utf8.RuneSelf {
+			return false
+		}
+	}
+	return true
+}
+
+// proseMisses checks the prose of f: the text of paragraphs,
+// headings, list items, and blockquotes, with code, HTML, and
+// autolinks already excluded by ProseRanges.
+func (c *checker) proseMisses(f *lint.File) []miss {
+	var out []miss
+	ranges := f.ProseRanges()
+	for i := 0; i < len(ranges); {
+		// Neighbouring text segments (split by an escape or an
+		// entity) form one run, so a word is never cut in two.
+		start, end := ranges[i].Start, ranges[i].End
+		for i++; i < len(ranges) && ranges[i].Start == end; i++ {
+			end = ranges[i].End
+		}
+		c.scan(lint.BytesView(f.Source[start:end]), func(off int, word string) {
+			if m, bad := c.check(word); bad {
+				m.start = start + off
+				out = append(out, m)
+			}
+		})
+	}
+	return out
+}
+
+// frontMatterMisses checks the single-line string values of the
+// front-matter block fm (delimiters included). Keys are never
+// checked. A value is checked only when its text appears verbatim at
+// the node's position, so an escaped quoted string that cannot be
+// mapped back to a column is skipped rather than misplaced.
+func (c *checker) frontMatterMisses(fm []byte) []miss {
+	if len(fm) == 0 {
+		return nil
+	}
+	delim := []byte("---\n")
+	node, err := yamlutil.UnmarshalNodeSafe(bytes.TrimSuffix(bytes.TrimPrefix(fm, delim), delim))
+	if err != nil {
+		return nil // malformed front matter is other rules' business
+	}
+	lines := strings.Split(string(fm), "\n")
+	var out []miss
+	var walk func(n *yaml.Node)
+	walk = func(n *yaml.Node) {
+		switch n.Kind {
+		case yaml.DocumentNode, yaml.SequenceNode:
+			for _, child := range n.Content {
+				walk(child)
+			}
+		case yaml.MappingNode:
+			for i := 1; i < len(n.Content); i += 2 {
+				walk(n.Content[i])
+			}
+		case yaml.ScalarNode:
+			out = c.scalarMisses(n, lines, out)
+		}
+	}
+	walk(&node)
+	return out
+}
+
+// scalarMisses checks one front-matter scalar. YAML line 1 is file
+// line 2, after the opening delimiter, so the node's line indexes
+// lines directly and the file line is one more.
+func (c *checker) scalarMisses(n *yaml.Node, lines []string, out []miss) []miss {
+	if n.Tag != "!!str" || n.Style&(yaml.LiteralStyle|yaml.FoldedStyle) != 0 ||
+		strings.Contains(n.Value, "\n") || n.Line >= len(lines) {
+		return out
+	}
+	line := lines[n.Line]
+	at := byteColumn(line, n.Column-1)
+	if n.Style&(yaml.SingleQuotedStyle|yaml.DoubleQuotedStyle) != 0 {
+		at++
+	}
+	if at > len(line) || !strings.HasPrefix(line[at:], n.Value) {
+		return out
+	}
+	c.scan(n.Value, func(off int, word string) {
+		if m, bad := c.check(word); bad {
+			m.line, m.col = n.Line+1, at+off+1
+			out = append(out, m)
+		}
+	})
+	return out
+}
+
+// byteColumn returns the byte offset of the n-th rune of line.
+func byteColumn(line string, n int) int {
+	for i := range line {
+		if n == 0 {
+			return i
+		}
+		n--
+	}
+	return len(line)
+}
+
+// scan splits text into whitespace-separated chunks, drops the ones
+// that are not prose (URLs, paths, emails, identifiers, and
+// placeholders), and calls emit with each word of the rest and its
+// byte offset in text.
+func (c *checker) scan(text string, emit func(off int, word string)) {
+	for i := 0; i < len(text); {
+		for i < len(text) && isSpace(text[i]) {
+			i++
+		}
+		start := i
+		for i < len(text) && !isSpace(text[i]) {
+			i++
+		}
+		if start == i {
+			continue
+		}
+		chunk := text[start:i]
+		if skipChunk(chunk) || placeholders.ContainsBodyToken(chunk, c.r.Placeholders) {
+			continue
+		}
+		words(chunk, func(off int, word string) { emit(start+off, word) })
+	}
+}
+
+func isSpace(b byte) bool {
+	return b == ' ' || b == '\t' || b == '\n' || b == '\r'
+}
+
+// skipChunk reports whether a chunk is a URL, email, path, file
+// name, identifier, or entity rather than prose: it holds one of
+// "@/\\_", both "&" and ";", or a dot followed by a letter or digit
+// ("config.yml", ".mdsmith", "www.example", "v1.2", "e.g.").
+func skipChunk(chunk string) bool {
+	amp, semi := false, false
+	for i := 0; i < len(chunk); i++ {
+		switch chunk[i] {
+		case '@', '/', '\\', '_':
+			return true
+		case '.':
+			if i+1 < len(chunk) && isAlnum(chunk[i+1]) {
+				return true
+			}
+		case '&':
+			amp = true
+		case ';':
+			semi = true
+		}
+	}
+	return amp && semi
+}
+
+func isAlnum(b byte) bool {
+	return b >= 'a' && b <= 'z' || b >= 'A' && b <= 'Z' || b >= '0' && b <= '9'
+}
+
+// words calls emit for each word in chunk: a run of letters with
+// inner apostrophes. Hyphens and other punctuation separate words;
+// a run that touches a digit ("h264", "2nd") is not a word.
+func words(chunk string, emit func(off int, word string)) {
+	for i := 0; i < len(chunk); {
+		r, size := decodeRune(chunk[i:])
+		if !isWordRune(r) {
+			i += size
+			continue
+		}
+		start, digit := i, false
+		for i < len(chunk) {
+			r, size = decodeRune(chunk[i:])
+			if !isWordRune(r) {
+				break
+			}
+			digit = digit || r >= '0' && r <= '9' || r >= utf8.RuneSelf && unicode.IsDigit(r)
+			i += size
+		}
+		if digit {
+			continue
+		}
+		if w, off := trimApostrophes(chunk[start:i]); w != "" {
+			emit(start+off, w)
+		}
+	}
+}
+
+func decodeRune(s string) (rune, int) {
+	if s[0] < utf8.RuneSelf {
+		return rune(s[0]), 1
+	}
+	return utf8.DecodeRuneInString(s)
+}
+
+// trimApostrophes drops quote marks around a word ('word', users'),
+// returning the word and the byte offset where it starts.
+func trimApostrophes(w string) (string, int) {
+	off := 0
+	for {
+		switch {
+		case strings.HasPrefix(w, "'"):
+			w, off = w[1:], off+1
+		case strings.HasPrefix(w, "’"):
+			w, off = w[len("’"):], off+len("’")
+		case strings.HasSuffix(w, "'"):
+			w = w[:len(w)-1]
+		case strings.HasSuffix(w, "’"):
+			w = w[:len(w)-len("’")]
+		default:
+			return w, off
+		}
+	}
+}
+
+func isWordRune(r rune) bool {
+	if r < utf8.RuneSelf {
+		return r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z' || r >= '0' && r <= '9' || r == '\''
+	}
+	return unicode.IsLetter(r) || unicode.IsDigit(r) || unicode.Is(unicode.Mn, r) || r == '’'
+}
+
+// check reports whether word is unknown, with suggestions. Words
+// shorter than MinLength, words with a capital after the first
+// letter (acronyms, CamelCase identifiers), and Chinese or Japanese
+// runs (which have no spaces to delimit words) are not checked. A
+// possessive "'s" is accepted on any known word.
+func (c *checker) check(word string) (miss, bool) {
+	if utf8.RuneCountInString(word) < c.r.MinLength || innerUpper(word) || hasCJK(word) {
+		return miss{}, false
+	}
+	w := normalize(word)
+	if c.known(w) {
+		return miss{}, false
+	}
+	if stem, ok := strings.CutSuffix(w, "'s"); ok && c.known(stem) {
+		return miss{}, false
+	}
+	return miss{word: word, suggestions: c.suggestions(word, w)}, true
+}
+
+// known reports whether w is a dictionary word or an accepted one. An
+// accepted lowercase word also accepts its capitalized form, as a
+// dictionary entry does.
+func (c *checker) known(w string) bool {
+	if len(c.accepted) > 0 && (c.accepted[w] || c.accepted[strings.ToLower(w)]) {
+		return true
+	}
+	return c.dict.Check(w)
+}
+
+// suggestions returns the dictionary's alternatives for w, written
+// with the typographic apostrophe when word used one.
+func (c *checker) suggestions(word, w string) []string {
+	if c.r.MaxSuggestions == 0 {
+		return nil
+	}
+	if s, ok := c.suggest[word]; ok {
+		return s
+	}
+	s := c.dict.Suggest(w, c.r.MaxSuggestions)
+	if w != word {
+		for i := range s {
+			s[i] = strings.ReplaceAll(s[i], "'", "’")
+		}
+	}
+	if c.suggest == nil {
+		c.suggest = map[string][]string{}
+	}
+	c.suggest[word] = s
+	return s
+}
+
+func hasCJK(w string) bool {
+	if isASCII(w) {
+		return false
+	}
+	for _, r := range w {
+		if mdtext.IsCJK(r) {
+			return true
+		}
+	}
+	return false
+}
+
+// innerUpper reports whether a capital follows the first rune.
+func innerUpper(w string) bool {
+	if isASCII(w) {
+		for i := 1; i < len(w); i++ {
+			if w[i] >= 'A' && w[i] <= 'Z' {
+				return true
+			}
+		}
+		return false
+	}
+	_, size := utf8.DecodeRuneInString(w)
+	for _, r := range w[size:] {
+		if unicode.IsUpper(r) {
+			return true
+		}
+	}
+	return false
+}
@@ -150,6 +150,7 @@ func (r *Rule) Check(f *lint.File) []lint.Diagnostic {
 func (r *Rule) Fix(f *lint.File) []byte {
 	intermediate := applyStructureFix(f, r.style())
 	parsed, _ := lint.NewFile(f.Path, intermediate) // NewFile never errors today
+	parsed.GeneratedDirectives = f.GeneratedDirectives
 	parsed.GeneratedRanges = gensection.FindAllGeneratedRanges(parsed)
 	skipLines := formatSkipLines(parsed)
 	return tablefmt.FormatLines(parsed.Source, parsed.Lines, skipLines, r.config())
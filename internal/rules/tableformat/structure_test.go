@@ -9,6 +9,9 @@ import (
 	"github.com/jeduden/mdsmith/internal/rules/tablefmt"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
+
+	// Registers <?include?> as a generated directive.
+	_ "github.com/jeduden/mdsmith/internal/rules/include"
 )
 
 // check exercises the structure pass alone so existing MD055/056/058
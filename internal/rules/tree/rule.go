@@ -0,0 +1,273 @@
+// Package tree implements MDS079, the <?tree?> generated-section
+// directive that renders a directory as a nested list of pages.
+package tree
+
+import (
+	"fmt"
+	"path"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/jeduden/mdsmith/internal/archetype/gensection"
+	"github.com/jeduden/mdsmith/internal/lint"
+	"github.com/jeduden/mdsmith/internal/rule"
+)
+
+func init() {
+	rule.Register(&Rule{})
+	gensection.RegisterGenerated("tree")
+}
+
+// Rule checks and fixes <?tree?>...<?/tree?> generated sections.
+//
+// engineOnce serialises lazy engine init; the rule is a registered
+// singleton and the LSP server may call Check from concurrent
+// goroutines.
+type Rule struct {
+	engineOnce sync.Once
+	engine     *gensection.Engine
+}
+
+// ID implements rule.Rule.
+func (r *Rule) ID() string { return "MDS079" }
+
+// Name implements rule.Rule.
+func (r *Rule) Name() string { return "tree" }
+
+// Category implements rule.Rule.
+func (r *Rule) Category() string { return "directive" }
+
+// RuleID implements gensection.Directive.
+func (r *Rule) RuleID() string { return "MDS079" }
+
+// RuleName implements gensection.Directive.
+func (r *Rule) RuleName() string { return "tree" }
+
+func (r *Rule) getEngine() *gensection.Engine {
+	r.engineOnce.Do(func() {
+		r.engine = gensection.NewEngine(r)
+	})
+	return r.engine
+}
+
+// Check implements rule.Rule. Stdin and in-memory sources have no
+// directory to walk, so the rule skips them.
+func (r *Rule) Check(f *lint.File) []lint.Diagnostic {
+	if f.FS == nil {
+		return nil
+	}
+	return r.getEngine().Check(f)
+}
+
+// Fix implements rule.FixableRule.
+func (r *Rule) Fix(f *lint.File) []byte {
+	if f.FS == nil {
+		return f.Source
+	}
+	return r.getEngine().Fix(f)
+}
+
+// Validate implements gensection.Directive.
+func (r *Rule) Validate(filePath string, line int,
+	params map[string]string, _ map[string]gensection.ColumnConfig,
+) []lint.Diagnostic {
+	return validateTreeDirective(filePath, line, params)
+}
+
+// treeResult is the memoised walk for one dir and gitignore setting.
+type treeResult struct {
+	nodes []*node
+	ok    bool
+}
+
+// Generate implements gensection.Directive.
+func (r *Rule) Generate(f *lint.File, filePath string, line int,
+	params map[string]string, _ map[string]gensection.ColumnConfig,
+) (string, []lint.Diagnostic) {
+	src, msg := resolveSource(f, params["dir"])
+	if msg != "" {
+		return "", []lint.Diagnostic{makeDiag(filePath, line, msg)}
+	}
+	useGitignore := params["gitignore"] != "false"
+	res := f.Memo("tree\x00"+src.link+"\x00"+strconv.FormatBool(useGitignore), func() any {
+		ignore := f.GetGitignore()
+		if !useGitignore {
+			ignore = nil
+		}
+		nodes, ok := readTree(f, src, ignore)
+		return treeResult{nodes: nodes, ok: ok}
+	}).(treeResult)
+	if !res.ok {
+		return "", []lint.Diagnostic{makeDiag(filePath, line,
+			fmt.Sprintf("tree directive matched more than %d files", maxTreeFiles))}
+	}
+
+	nodes := res.nodes
+	if kinds := gensection.SplitList(params["kind"]); len(kinds) > 0 {
+		nodes = filterKinds(nodes, kinds)
+	}
+	if len(nodes) == 0 {
+		if empty := params["empty"]; empty != "" {
+			return gensection.EnsureTrailingNewline(empty), nil
+		}
+		return "", nil
+	}
+	depth, _ := strconv.Atoi(params["depth"])
+	var b strings.Builder
+	// Wrap with blank lines so the list satisfies MDS014, as <?toc?> does.
+	b.WriteByte('\n')
+	writeNodes(&b, nodes, 0, depth)
+	b.WriteByte('\n')
+	return b.String(), nil
+}
+
+// linkTextEscaper escapes the characters that would end link text.
+var linkTextEscaper = strings.NewReplacer(`\`, `\\`, `[`, `\[`, `]`, `\]`)
+
+// writeNodes renders nodes as a nested list, two spaces per level.
+// depth 0 is unlimited; otherwise children below depth levels are
+// left out.
+func writeNodes(b *strings.Builder, nodes []*node, level, depth int) {
+	for _, n := range nodes {
+		for range level {
+			b.WriteString("  ")
+		}
+		if n.link == "" {
+			b.WriteString("- " + n.title + "\n")
+		} else {
+			target := n.link
+			if strings.ContainsAny(target, " ()") {
+				target = "<" + target + ">"
+			}
+			b.WriteString("- [" + linkTextEscaper.Replace(n.title) + "](" + target + ")\n")
+		}
+		if len(n.children) > 0 && (depth == 0 || level+1 < depth) {
+			writeNodes(b, n.children, level+1, depth)
+		}
+	}
+}
+
+// resolveSource maps the `dir:` parameter to the FS and walk root.
+// Paths inside the host's directory read from f.FS; a ".." path
+// needs the project root, as in include. It returns a diagnostic
+// message instead when the directory cannot be reached.
+func resolveSource(f *lint.File, dir string) (treeSource, string) {
+	if dir == "" {
+		dir = "."
+	}
+	clean := path.Clean(dir)
+	hostPath := path.Clean(filepath.ToSlash(f.Path))
+	if !escapes(clean) {
+		src := treeSource{fsys: f.FS, root: clean, link: clean, self: path.Base(hostPath)}
+		src.base = path.Dir(hostPath)
+		if abs, ok := absHostDir(f); ok {
+			src.absRoot = filepath.Join(abs, filepath.FromSlash(clean))
+			if rel, ok := projectRelative(f, abs); ok {
+				src.base = rel
+			}
+		}
+		return src, ""
+	}
+	if f.RootFS == nil {
+		return treeSource{}, `tree directive "dir" contains ".." but project root is not configured`
+	}
+	resolved := path.Clean(path.Join(path.Dir(hostPath), clean))
+	if escapes(resolved) {
+		return treeSource{}, `tree directive "dir" escapes project root`
+	}
+	src := treeSource{fsys: f.RootFS, root: resolved, link: clean, self: hostPath}
+	if root, err := filepath.Abs(f.RootDir); err == nil {
+		src.absRoot = filepath.Join(root, filepath.FromSlash(resolved))
+	}
+	return src, ""
+}
+
+// projectRelative returns the absolute directory abs relative to the
+// project root, slash-separated, or ok=false without a root or when
+// abs lies outside it.
+func projectRelative(f *lint.File, abs string) (string, bool) {
+	if f.RootDir == "" {
+		return "", false
+	}
+	root, err := filepath.Abs(f.RootDir)
+	if err != nil {
+		return "", false
+	}
+	rel, err := filepath.Rel(root, abs)
+	if err != nil {
+		return "", false
+	}
+	rel = filepath.ToSlash(rel)
+	if escapes(rel) {
+		return "", false
+	}
+	return rel, true
+}
+
+func escapes(p string) bool {
+	return p == ".." || strings.HasPrefix(p, "../")
+}
+
+// absHostDir returns the absolute directory f.FS is rooted at, the
+// base gitignore lookups need. It follows catalog's absBaseDir: an
+// absolute path is used as is, a relative one is anchored at the
+// project root when set, else at the working directory.
+func absHostDir(f *lint.File) (string, bool) {
+	dir := filepath.Dir(f.Path)
+	if filepath.IsAbs(dir) {
+		return filepath.Clean(dir), true
+	}
+	if f.RootDir != "" {
+		if root, err := filepath.Abs(f.RootDir); err == nil {
+			return filepath.Join(root, dir), true
+		}
+	}
+	abs, err := filepath.Abs(dir)
+	return abs, err == nil
+}
+
+// validateTreeDirective validates the directive's parameters without
+// reading the directory.
+func validateTreeDirective(filePath string, line int, params map[string]string) []lint.Diagnostic {
+	if dir, ok := params["dir"]; ok {
+		switch {
+		case strings.TrimSpace(dir) == "":
+			return []lint.Diagnostic{makeDiag(filePath, line, `tree directive has empty "dir" value`)}
+		case path.IsAbs(dir) || filepath.IsAbs(dir):
+			return []lint.Diagnostic{makeDiag(filePath, line, `tree directive "dir" must be a relative path`)}
+		}
+	}
+	if v, ok := params["depth"]; ok {
+		if n, err := strconv.Atoi(v); err != nil || n < 1 {
+			return []lint.Diagnostic{makeDiag(filePath, line,
+				`tree directive has invalid "depth" value; must be a positive integer`)}
+		}
+	}
+	if v, ok := params["kind"]; ok && len(gensection.SplitList(v)) == 0 {
+		return []lint.Diagnostic{makeDiag(filePath, line, `tree directive has empty "kind" value`)}
+	}
+	if v, ok := params["gitignore"]; ok && v != "true" && v != "false" {
+		return []lint.Diagnostic{makeDiag(filePath, line,
+			`tree directive has invalid "gitignore" value; must be "true" or "false"`)}
+	}
+	return nil
+}
+
+func makeDiag(file string, line int, msg string) lint.Diagnostic {
+	return lint.Diagnostic{
+		File:     file,
+		Line:     line,
+		Column:   1,
+		RuleID:   "MDS079",
+		RuleName: "tree",
+		Severity: lint.Error,
+		Message:  msg,
+	}
+}
+
+var _ rule.FixableRule = (*Rule)(nil)
+
+// FixTitle implements rule.QuickFixTitler.
+func (r *Rule) FixTitle() string { return "Regenerate tree" }
@@ -0,0 +1,191 @@
+package tree
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"testing/fstest"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/jeduden/mdsmith/internal/archetype/gensection"
+	"github.com/jeduden/mdsmith/internal/config"
+	"github.com/jeduden/mdsmith/internal/gitignore"
+	"github.com/jeduden/mdsmith/internal/lint"
+)
+
+// handbookFS is a small handbook; index.md hosts the tree.
+var handbookFS = fstest.MapFS{
+	"index.md":                 {Data: []byte("# Handbook\n")},
+	"intro.md":                 {Data: []byte("---\ntitle: Introduction\nweight: 1\n---\n# Intro\n")},
+	"faq.md":                   {Data: []byte("# FAQ\n")},
+	"guides/index.md":          {Data: []byte("---\nweight: 2\n---\n# Guides\n")},
+	"guides/setup.md":          {Data: []byte("---\nkinds: [guide]\n---\n# Setup\n")},
+	"guides/deploy/README.md":  {Data: []byte("# Deploying\n")},
+	"guides/deploy/aws.md":     {Data: []byte("---\nkinds: [guide]\n---\n# AWS\n")},
+	"reference/api.md":         {Data: []byte("# API [v2]\n")},
+	"reference/notes.txt":      {Data: []byte("not markdown\n")},
+	"assets/logo.svg":          {Data: []byte("<svg/>\n")},
+	".hidden/secret.md":        {Data: []byte("# Secret\n")},
+	"node_modules/pkg/docs.md": {Data: []byte("# Vendored\n")},
+}
+
+// fixTree runs Fix on a tree directive in host with the given
+// parameter lines and returns the body between the markers.
+func fixTree(t *testing.T, host string, fsys fstest.MapFS, params string) string {
+	t.Helper()
+	src := "# Handbook\n\n<?tree\n" + params + "?>\n<?/tree?>\n"
+	f, err := lint.NewFile(host, []byte(src))
+	require.NoError(t, err)
+	f.FS = fsys
+	f.StripFrontMatter = true
+	out := string((&Rule{}).Fix(f))
+	start := strings.Index(out, "?>\n") + len("?>\n")
+	end := strings.LastIndex(out, "<?/tree?>")
+	return out[start:end]
+}
+
+func TestGenerate_NestedByWeightThenTitle(t *testing.T) {
+	got := fixTree(t, "index.md", handbookFS, "")
+	want := "\n" +
+		"- [Introduction](intro.md)\n" +
+		"- [Guides](guides/index.md)\n" +
+		"  - [Deploying](guides/deploy/README.md)\n" +
+		"    - [AWS](guides/deploy/aws.md)\n" +
+		"  - [Setup](guides/setup.md)\n" +
+		"- [FAQ](faq.md)\n" +
+		"- reference\n" +
+		"  - [API \\[v2\\]](reference/api.md)\n" +
+		"\n"
+	assert.Equal(t, want, got)
+}
+
+func TestGenerate_DepthLimit(t *testing.T) {
+	got := fixTree(t, "index.md", handbookFS, "depth: 1\n")
+	want := "\n- [Introduction](intro.md)\n- [Guides](guides/index.md)\n- [FAQ](faq.md)\n- reference\n\n"
+	assert.Equal(t, want, got)
+}
+
+func TestGenerate_KindFilterKeepsAncestorsAsText(t *testing.T) {
+	got := fixTree(t, "index.md", handbookFS, "kind: guide\n")
+	want := "\n- guides\n  - deploy\n    - [AWS](guides/deploy/aws.md)\n  - [Setup](guides/setup.md)\n\n"
+	assert.Equal(t, want, got)
+}
+
+func TestGenerate_KindFilterUsesKindAssignment(t *testing.T) {
+	cfg := &config.Config{KindAssignment: []config.KindAssignmentEntry{
+		{Glob: []string{"docs/reference/*.md"}, Kinds: []string{"ref"}},
+	}}
+	src := "<?tree\nkind: ref\n?>\n<?/tree?>\n"
+	f, err := lint.NewFile("docs/index.md", []byte(src))
+	require.NoError(t, err)
+	// The host sits in docs/, so globs see project-relative paths.
+	f.FS = fstest.MapFS{
+		"faq.md":           {Data: []byte("# FAQ\n")},
+		"reference/api.md": {Data: []byte("# API\n")},
+	}
+	f.RootDir = t.TempDir()
+	f.KindsFunc = func(p string, fm []byte) []string { return config.FileKinds(cfg, p, fm) }
+	out := string((&Rule{}).Fix(f))
+	assert.Contains(t, out, "?>\n\n- reference\n  - [API](reference/api.md)\n\n<?/tree?>")
+}
+
+func TestGenerate_SubdirectoryLinksFromHost(t *testing.T) {
+	got := fixTree(t, "index.md", handbookFS, "dir: guides/deploy\n")
+	assert.Equal(t, "\n- [AWS](guides/deploy/aws.md)\n- [Deploying](guides/deploy/README.md)\n\n", got)
+}
+
+func TestGenerate_EmptyFallback(t *testing.T) {
+	got := fixTree(t, "index.md", handbookFS, "kind: missing\nempty: No pages yet.\n")
+	assert.Equal(t, "No pages yet.\n", got)
+}
+
+func TestGenerate_ParentDirNeedsProjectRoot(t *testing.T) {
+	src := "<?tree\ndir: ../guides\n?>\n<?/tree?>\n"
+	f, err := lint.NewFile("docs/index.md", []byte(src))
+	require.NoError(t, err)
+	f.FS = fstest.MapFS{}
+	diags := (&Rule{}).Check(f)
+	require.Len(t, diags, 1)
+	assert.Contains(t, diags[0].Message, "project root is not configured")
+
+	f.RootFS = fstest.MapFS{"guides/setup.md": {Data: []byte("# Setup\n")}}
+	f.RootDir = t.TempDir()
+	out := string((&Rule{}).Fix(f))
+	assert.Contains(t, out, "- [Setup](../guides/setup.md)\n")
+
+	f, err = lint.NewFile("docs/index.md", []byte("<?tree\ndir: ../../x\n?>\n<?/tree?>\n"))
+	require.NoError(t, err)
+	f.FS, f.RootFS, f.RootDir = fstest.MapFS{}, fstest.MapFS{}, t.TempDir()
+	diags = (&Rule{}).Check(f)
+	require.Len(t, diags, 1)
+	assert.Contains(t, diags[0].Message, "escapes project root")
+}
+
+func TestGenerate_SkipsGitignoredPaths(t *testing.T) {
+	dir := t.TempDir()
+	write := func(name, content string) {
+		p := filepath.Join(dir, filepath.FromSlash(name))
+		require.NoError(t, os.MkdirAll(filepath.Dir(p), 0o755))
+		require.NoError(t, os.WriteFile(p, []byte(content), 0o644))
+	}
+	write(".gitignore", "build/\ndraft.md\n")
+	write("guide.md", "# Guide\n")
+	write("draft.md", "# Draft\n")
+	write("build/out.md", "# Out\n")
+	src := "<?tree\n?>\n<?/tree?>\n"
+	write("index.md", src)
+
+	newFile := func(src string) *lint.File {
+		f, err := lint.NewFile(filepath.Join(dir, "index.md"), []byte(src))
+		require.NoError(t, err)
+		f.FS = os.DirFS(dir)
+		f.GitignoreFunc = func() *gitignore.Matcher { return gitignore.NewMatcher(dir) }
+		return f
+	}
+	out := string((&Rule{}).Fix(newFile(src)))
+	assert.Contains(t, out, "- [Guide](guide.md)\n")
+	assert.NotContains(t, out, "draft.md")
+	assert.NotContains(t, out, "build/")
+
+	out = string((&Rule{}).Fix(newFile("<?tree\ngitignore: \"false\"\n?>\n<?/tree?>\n")))
+	assert.Contains(t, out, "- [Draft](draft.md)\n")
+	assert.Contains(t, out, "- [Out](build/out.md)\n")
+}
+
+func TestCheck_NoFSSkips(t *testing.T) {
+	f, err := lint.NewFile("index.md", []byte("<?tree\n?>\nstale\n<?/tree?>\n"))
+	require.NoError(t, err)
+	assert.Empty(t, (&Rule{}).Check(f))
+}
+
+func TestValidate_Errors(t *testing.T) {
+	tests := []struct {
+		name   string
+		params map[string]string
+		msg    string
+	}{
+		{"empty dir", map[string]string{"dir": " "}, `empty "dir"`},
+		{"absolute dir", map[string]string{"dir": "/etc"}, "must be a relative path"},
+		{"zero depth", map[string]string{"depth": "0"}, `invalid "depth"`},
+		{"word depth", map[string]string{"depth": "deep"}, `invalid "depth"`},
+		{"empty kind", map[string]string{"kind": ""}, `empty "kind"`},
+		{"bad gitignore", map[string]string{"gitignore": "no"}, `invalid "gitignore"`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			diags := (&Rule{}).Validate("index.md", 1, tt.params, nil)
+			require.Len(t, diags, 1)
+			assert.Contains(t, diags[0].Message, tt.msg)
+			assert.Equal(t, "MDS079", diags[0].RuleID)
+		})
+	}
+}
+
+func TestAuthoredSource_SkipsTreeBody(t *testing.T) {
+	src := "# Docs\n\n<?tree?>\n- [Guide](guide.md)\n<?/tree?>\n"
+	assert.Equal(t, "# Docs\n\n<?tree?>\n<?/tree?>\n",
+		string(gensection.AuthoredSource([]byte(src))))
+}
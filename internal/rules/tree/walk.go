@@ -0,0 +1,239 @@
+package tree
+
+import (
+	"io/fs"
+	"path"
+	"path/filepath"
+	"slices"
+	"sort"
+	"strings"
+
+	"github.com/jeduden/mdsmith/internal/bytelimit"
+	"github.com/jeduden/mdsmith/internal/gitignore"
+	"github.com/jeduden/mdsmith/internal/lint"
+	"github.com/jeduden/mdsmith/internal/mdpath"
+	"github.com/jeduden/mdsmith/internal/mdtext"
+)
+
+// maxTreeFiles caps the Markdown files one tree reads, so a directive
+// pointed at a huge checkout cannot stall a run.
+const maxTreeFiles = 10_000
+
+// indexNames are the pages that stand for their directory, in order
+// of preference.
+var indexNames = []string{"index.md", "README.md"}
+
+// node is one file or directory in the tree. A directory with an
+// index page links to it and takes the page's title, weight, and
+// kinds.
+type node struct {
+	name     string
+	link     string
+	title    string
+	weight   float64
+	weighted bool
+	kinds    []string
+	dir      bool
+	children []*node
+}
+
+// treeSource says where a tree is read from and how its paths map
+// back to the host file.
+type treeSource struct {
+	fsys    fs.FS
+	root    string // walk root within fsys, slash-separated
+	link    string // walk root relative to the host file's directory
+	absRoot string // absolute walk root for gitignore; "" disables it
+	self    string // host file's path within fsys, never listed
+	base    string // fsys root relative to the project root, for kinds
+}
+
+// builder reads one tree. files counts the pages read so far.
+type builder struct {
+	f      *lint.File
+	src    treeSource
+	ignore *gitignore.Matcher
+	files  int
+}
+
+// readTree returns the top-level nodes under src.root, sorted, with
+// directories that hold no Markdown dropped. The bool is false when
+// the walk hit maxTreeFiles.
+func readTree(f *lint.File, src treeSource, ignore *gitignore.Matcher) ([]*node, bool) {
+	b := &builder{f: f, src: src, ignore: ignore}
+	if src.absRoot == "" {
+		b.ignore = nil
+	}
+	nodes := pruneEmpty(b.readDir(src.root, src.link))
+	return nodes, b.files <= maxTreeFiles
+}
+
+// readDir returns the nodes for one directory. Hidden entries and
+// node_modules are skipped, as are gitignored paths when a matcher
+// is set. Unreadable directories and files are left out rather than
+// failing the whole tree.
+func (b *builder) readDir(dir, link string) []*node {
+	entries, err := fs.ReadDir(b.src.fsys, dir)
+	if err != nil {
+		return nil
+	}
+	var out []*node
+	for _, e := range entries {
+		name := e.Name()
+		if strings.HasPrefix(name, ".") || b.files > maxTreeFiles {
+			continue
+		}
+		p, l := path.Join(dir, name), path.Join(link, name)
+		if e.IsDir() {
+			if name == "node_modules" || b.ignored(p, true) {
+				continue
+			}
+			out = append(out, b.readSubdir(p, l, name))
+			continue
+		}
+		if !e.Type().IsRegular() || !mdpath.IsMarkdownPath(name) ||
+			p == b.src.self || b.ignored(p, false) {
+			continue
+		}
+		if n, ok := b.readPage(p, l, name); ok {
+			out = append(out, n)
+		}
+	}
+	sortNodes(out)
+	return out
+}
+
+// readSubdir returns the node for a subdirectory. Its index page, if
+// any, becomes the directory's own link instead of a child.
+func (b *builder) readSubdir(dir, link, name string) *node {
+	n := &node{name: name, title: name, dir: true}
+	children := b.readDir(dir, link)
+	for _, idx := range indexNames {
+		i := slices.IndexFunc(children, func(c *node) bool { return !c.dir && c.name == idx })
+		if i < 0 {
+			continue
+		}
+		page := children[i]
+		n.link, n.title, n.kinds = page.link, page.title, page.kinds
+		n.weight, n.weighted = page.weight, page.weighted
+		children = slices.Delete(children, i, i+1)
+		break
+	}
+	n.children = children
+	return n
+}
+
+// readPage reads one Markdown file's title, weight, and effective
+// kinds, kind-assignment matches included.
+func (b *builder) readPage(p, link, name string) (*node, bool) {
+	b.files++
+	data, err := bytelimit.ReadFSFileLimited(b.src.fsys, p, b.f.MaxInputBytes)
+	if err != nil {
+		return nil, false
+	}
+	src, err := lint.NewFileFromSource(p, data, b.f.StripFrontMatter)
+	if err != nil {
+		return nil, false
+	}
+	n := &node{name: name, link: link}
+	fields, _ := lint.ParseFrontMatterFields(src.FrontMatter)
+	n.kinds = b.f.Kinds(path.Join(b.src.base, p), src.FrontMatter)
+	n.weight, n.weighted = numericField(fields["weight"])
+	if t, ok := fields["title"].(string); ok && strings.TrimSpace(t) != "" {
+		n.title = strings.TrimSpace(t)
+	} else if t := mdtext.LeadingH1(src.AST, src.Source); t != "" {
+		n.title = t
+	} else {
+		n.title = strings.TrimSuffix(name, path.Ext(name))
+	}
+	return n, true
+}
+
+// ignored reports whether the gitignore matcher excludes p.
+func (b *builder) ignored(p string, isDir bool) bool {
+	if b.ignore == nil {
+		return false
+	}
+	rel := strings.TrimPrefix(strings.TrimPrefix(p, b.src.root), "/")
+	abs := filepath.Join(b.src.absRoot, filepath.FromSlash(rel))
+	return b.ignore.IsIgnored(abs, isDir)
+}
+
+// numericField reads a YAML number. Other types count as unset.
+func numericField(v any) (float64, bool) {
+	switch n := v.(type) {
+	case int:
+		return float64(n), true
+	case int64:
+		return float64(n), true
+	case uint64:
+		return float64(n), true
+	case float64:
+		return n, true
+	}
+	return 0, false
+}
+
+// sortNodes orders nodes by weight, unweighted last, then by title
+// case-insensitively, then by name.
+func sortNodes(nodes []*node) {
+	sort.SliceStable(nodes, func(i, j int) bool {
+		a, b := nodes[i], nodes[j]
+		if a.weighted != b.weighted {
+			return a.weighted
+		}
+		if a.weighted && a.weight != b.weight {
+			return a.weight < b.weight
+		}
+		if ta, tb := strings.ToLower(a.title), strings.ToLower(b.title); ta != tb {
+			return ta < tb
+		}
+		return a.name < b.name
+	})
+}
+
+// filterKinds drops files whose kinds miss every wanted kind and
+// directories left with nothing to show. A directory whose index
+// page is filtered out but whose children survive is kept as plain
+// text.
+func filterKinds(nodes []*node, kinds []string) []*node {
+	var out []*node
+	for _, n := range nodes {
+		match := slices.ContainsFunc(kinds, func(k string) bool {
+			return slices.Contains(n.kinds, k)
+		})
+		if !n.dir {
+			if match {
+				out = append(out, n)
+			}
+			continue
+		}
+		kept := *n
+		kept.children = filterKinds(n.children, kinds)
+		if !match {
+			kept.link, kept.title, kept.weighted = "", n.name, false
+		}
+		if kept.link != "" || len(kept.children) > 0 {
+			out = append(out, &kept)
+		}
+	}
+	// A directory that lost its page now sorts by its name.
+	sortNodes(out)
+	return out
+}
+
+// pruneEmpty drops directories with neither an index page nor any
+// Markdown below them.
+func pruneEmpty(nodes []*node) []*node {
+	var out []*node
+	for _, n := range nodes {
+		if n.dir {
+			n.children = pruneEmpty(n.children)
+			if n.link == "" && len(n.children) == 0 {
+				continue
+			}
+		}
+		out = append(out, n)
+	}
+	return out
+}
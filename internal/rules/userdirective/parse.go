@@ -0,0 +1,201 @@
+package userdirective
+
+import (
+	"fmt"
+	"slices"
+	"sort"
+	"strings"
+
+	"github.com/jeduden/mdsmith/internal/archetype/gensection"
+	"github.com/jeduden/mdsmith/internal/rule"
+)
+
+// parseDefinitions decodes the `directives` setting: a map from
+// directive name to its definition. Config validates the same shape
+// at load; the checks here cover settings set directly on the rule.
+func parseDefinitions(v any) ([]*definition, error) {
+	raw, ok := v.(map[string]any)
+	if !ok {
+		return nil, fmt.Errorf("directives must be a map, got %T", v)
+	}
+	defs := make([]*definition, 0, len(raw))
+	for name, body := range raw {
+		def, err := parseDefinition(name, body)
+		if err != nil {
+			return nil, err
+		}
+		defs = append(defs, def)
+	}
+	sort.Slice(defs, func(i, j int) bool { return defs[i].name < defs[j].name })
+	return defs, nil
+}
+
+func parseDefinition(name string, body any) (*definition, error) {
+	if !nameRe.MatchString(name) {
+		return nil, fmt.Errorf("directive %q: name must match %s", name, nameRe.String())
+	}
+	m, ok := body.(map[string]any)
+	if !ok {
+		return nil, fmt.Errorf("directive %q must be a map, got %T", name, body)
+	}
+	def := &definition{name: name, with: map[string]string{}}
+	for k, v := range m {
+		var err error
+		switch k {
+		case "description":
+			if _, ok := v.(string); !ok {
+				err = fmt.Errorf("description must be a string, got %T", v)
+			}
+		case "expand":
+			def.expand, err = parseExpand(v)
+		case "params":
+			def.required, def.optional, err = parseParams(v)
+		case "with":
+			def.with, err = parseWith(v)
+		default:
+			err = fmt.Errorf("unknown key %q", k)
+		}
+		if err != nil {
+			return nil, fmt.Errorf("directive %q: %w", name, err)
+		}
+	}
+	if def.expand == "" {
+		return nil, fmt.Errorf("directive %q: expand is required", name)
+	}
+	return def, nil
+}
+
+func parseExpand(v any) (string, error) {
+	s, ok := v.(string)
+	if !ok {
+		return "", fmt.Errorf("expand must be a string, got %T", v)
+	}
+	if !slices.Contains(expandable, s) {
+		return "", fmt.Errorf("expand %q must be one of %s", s, strings.Join(expandable, ", "))
+	}
+	return s, nil
+}
+
+func parseParams(v any) (required, optional []string, err error) {
+	m, ok := v.(map[string]any)
+	if !ok {
+		return nil, nil, fmt.Errorf("params must be a map, got %T", v)
+	}
+	for k := range m {
+		if k != "required" && k != "optional" {
+			return nil, nil, fmt.Errorf("params: unknown key %q", k)
+		}
+	}
+	if required, err = paramNames("required", m["required"]); err != nil {
+		return nil, nil, err
+	}
+	if optional, err = paramNames("optional", m["optional"]); err != nil {
+		return nil, nil, err
+	}
+	for _, p := range required {
+		if slices.Contains(optional, p) {
+			return nil, nil, fmt.Errorf("params: %q is both required and optional", p)
+		}
+	}
+	return required, optional, nil
+}
+
+func paramNames(key string, v any) ([]string, error) {
+	names, err := toStrings(v)
+	if err != nil {
+		return nil, fmt.Errorf("params.%s %w", key, err)
+	}
+	for _, n := range names {
+		if !paramRe.MatchString(n) {
+			return nil, fmt.Errorf("params.%s: %q must match %s", key, n, paramRe.String())
+		}
+	}
+	return names, nil
+}
+
+// parseWith decodes the base directive's parameters. A list value is
+// joined with newlines, the form directive YAML lists take.
+func parseWith(v any) (map[string]string, error) {
+	m, ok := v.(map[string]any)
+	if !ok {
+		return nil, fmt.Errorf("with must be a map, got %T", v)
+	}
+	out := make(map[string]string, len(m))
+	for k, raw := range m {
+		if s, ok := raw.(string); ok {
+			out[k] = s
+			continue
+		}
+		list, err := toStrings(raw)
+		if err != nil || list == nil {
+			return nil, fmt.Errorf("with.%s must be a string or a list of strings", k)
+		}
+		out[k] = strings.Join(list, "\n")
+	}
+	return out, nil
+}
+
+// toStrings converts []any or []string to []string.
+func toStrings(v any) ([]string, error) {
+	switch s := v.(type) {
+	case nil:
+		return nil, nil
+	case []string:
+		return s, nil
+	case []any:
+		out := make([]string, 0, len(s))
+		for _, item := range s {
+			str, ok := item.(string)
+			if !ok {
+				return nil, fmt.Errorf("must be a list of strings, got element %T", item)
+			}
+			out = append(out, str)
+		}
+		return out, nil
+	}
+	return nil, fmt.Errorf("must be a list of strings, got %T", v)
+}
+
+// parseBases decodes the `base-rules` setting config injects: a map
+// from built-in directive name to {enabled, settings}. Each directive
+// is cloned and configured as the engine would configure it; one
+// whose settings do not apply is left disabled, since the engine
+// already reports those settings against its own rule.
+func parseBases(v any) (map[string]base, error) {
+	raw, ok := v.(map[string]any)
+	if !ok {
+		return nil, fmt.Errorf("base-rules must be a map, got %T", v)
+	}
+	bases := make(map[string]base, len(raw))
+	for name, body := range raw {
+		m, ok := body.(map[string]any)
+		if !ok {
+			return nil, fmt.Errorf("base-rules.%s must be a map, got %T", name, body)
+		}
+		enabled, _ := m["enabled"].(bool)
+		settings, _ := m["settings"].(map[string]any)
+		d, ok := configureBase(name, settings)
+		bases[name] = base{directive: d, enabled: enabled && ok}
+	}
+	return bases, nil
+}
+
+// configureBase returns a clone of the registered directive name with
+// settings applied, and false when the settings are rejected. An
+// unknown name yields nil and true, left for Validate to report.
+func configureBase(name string, settings map[string]any) (gensection.Directive, bool) {
+	r := rule.ByName(name)
+	if r == nil {
+		return nil, true
+	}
+	if len(settings) > 0 {
+		r = rule.CloneRule(r)
+		if c, ok := r.(rule.Configurable); ok {
+			if err := c.ApplySettings(settings); err != nil {
+				return nil, false
+			}
+		}
+	}
+	d, _ := r.(gensection.Directive)
+	return d, true
+}
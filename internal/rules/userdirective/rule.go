@@ -0,0 +1,297 @@
+// Package userdirective implements MDS081, which runs the
+// generated-section directives a project declares in config: each
+// definition names its parameters and expands to a built-in
+// directive, so a team-specific section needs no Go code.
+package userdirective
+
+import (
+	"fmt"
+	"regexp"
+	"slices"
+	"sort"
+	"strings"
+
+	"github.com/jeduden/mdsmith/internal/archetype/gensection"
+	"github.com/jeduden/mdsmith/internal/lint"
+	"github.com/jeduden/mdsmith/internal/rule"
+)
+
+// expandable lists the built-in directives a definition may expand
+// to. build and history are left out: both run programs, so they
+// stay behind their own trust gate and config injection.
+var expandable = []string{"backlinks", "catalog", "data", "include", "tree"}
+
+// nameRe is the directive name pattern, matching the
+// `.mdsmith/directives/` basename rule.
+var nameRe = regexp.MustCompile(`^[a-z][a-z0-9-]*$`)
+
+// paramRe is the parameter name pattern, as for build recipes.
+var paramRe = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*$`)
+
+// placeholderRe finds {name} placeholders in `with` values.
+var placeholderRe = regexp.MustCompile(`\{([A-Za-z_][A-Za-z0-9_]*)\}`)
+
+func init() {
+	rule.Register(&Rule{})
+}
+
+// definition is one user-defined directive.
+type definition struct {
+	name     string
+	required []string
+	optional []string
+	expand   string
+	with     map[string]string
+}
+
+// Rule checks and fixes the sections of every user-defined directive.
+// Definitions arrive through the `directives` setting, which config
+// fills from the top-level `directives:` key and
+// `.mdsmith/directives/*.yml`; without any the rule is inert.
+type Rule struct {
+	defs  []*definition // sorted by name
+	bases map[string]base
+}
+
+// base is a built-in directive as the file configures it. Config
+// supplies it through the `base-rules` setting; a directive it does
+// not mention falls back to the registered default.
+type base struct {
+	directive gensection.Directive
+	enabled   bool
+}
+
+// ID implements rule.Rule.
+func (r *Rule) ID() string { return "MDS081" }
+
+// Name implements rule.Rule.
+func (r *Rule) Name() string { return "user-directive" }
+
+// Category implements rule.Rule.
+func (r *Rule) Category() string { return "directive" }
+
+// DefaultSettings implements rule.Configurable.
+func (r *Rule) DefaultSettings() map[string]any {
+	return map[string]any{}
+}
+
+// ApplySettings implements rule.Configurable.
+func (r *Rule) ApplySettings(settings map[string]any) error {
+	for k, v := range settings {
+		switch k {
+		case "directives":
+			defs, err := parseDefinitions(v)
+			if err != nil {
+				return fmt.Errorf("user-directive: %w", err)
+			}
+			r.defs = defs
+		case "base-rules":
+			bases, err := parseBases(v)
+			if err != nil {
+				return fmt.Errorf("user-directive: %w", err)
+			}
+			r.bases = bases
+		default:
+			return fmt.Errorf("user-directive: unknown setting %q", k)
+		}
+	}
+	return nil
+}
+
+// Directives implements gensection.DirectiveSet.
+func (r *Rule) Directives() []gensection.Directive {
+	out := make([]gensection.Directive, len(r.defs))
+	for i, d := range r.defs {
+		out[i] = r.directive(d)
+	}
+	return out
+}
+
+// Check implements rule.Rule. Unknown parameters are warnings, which
+// the gensection engine cannot report alongside a stale-body check,
+// so each pair is checked here, as in the build rule. A definition
+// whose base rule is disabled for the file is skipped.
+func (r *Rule) Check(f *lint.File) []lint.Diagnostic {
+	var diags []lint.Diagnostic
+	for _, def := range r.defs {
+		d := r.directive(def)
+		if !d.enabled {
+			continue
+		}
+		pairs, pDiags := gensection.FindMarkerPairs(f, def.name, d.RuleID(), d.RuleName())
+		diags = append(diags, pDiags...)
+		for _, mp := range pairs {
+			diags = append(diags, d.checkPair(f, mp)...)
+		}
+	}
+	return diags
+}
+
+// Fix implements rule.FixableRule.
+func (r *Rule) Fix(f *lint.File) []byte {
+	for _, def := range r.defs {
+		if d := r.directive(def); d.enabled {
+			gensection.NewEngine(d).Fix(f)
+		}
+	}
+	return f.Source
+}
+
+// FixTitle implements rule.QuickFixTitler.
+func (r *Rule) FixTitle() string { return "Regenerate user directive" }
+
+// directive returns def bound to the base directive it expands to.
+func (r *Rule) directive(def *definition) *directive {
+	if b, ok := r.bases[def.expand]; ok {
+		return &directive{def: def, base: b.directive, enabled: b.enabled}
+	}
+	d, _ := rule.ByName(def.expand).(gensection.Directive)
+	return &directive{def: def, base: d, enabled: true}
+}
+
+// directive adapts one definition to gensection.Directive.
+type directive struct {
+	def     *definition
+	base    gensection.Directive // nil for an unknown directive
+	enabled bool
+}
+
+// Name implements gensection.Directive.
+func (d *directive) Name() string { return d.def.name }
+
+// RuleID implements gensection.Directive.
+func (d *directive) RuleID() string { return "MDS081" }
+
+// RuleName implements gensection.Directive.
+func (d *directive) RuleName() string { return "user-directive" }
+
+// Expands implements gensection.Expander.
+func (d *directive) Expands() string { return d.def.expand }
+
+// Validate implements gensection.Directive. It reports missing
+// required parameters, then validates the expanded parameters with
+// the base directive.
+func (d *directive) Validate(filePath string, line int,
+	params map[string]string, columns map[string]gensection.ColumnConfig,
+) []lint.Diagnostic {
+	for _, p := range d.def.required {
+		if strings.TrimSpace(params[p]) == "" {
+			return []lint.Diagnostic{d.diag(filePath, line, lint.Error,
+				fmt.Sprintf("missing required parameter %q", p))}
+		}
+	}
+	if d.base == nil {
+		return []lint.Diagnostic{d.diag(filePath, line, lint.Error,
+			fmt.Sprintf("expands to unknown directive %q", d.def.expand))}
+	}
+	return d.retag(d.base.Validate(filePath, line, d.def.expandParams(params), columns))
+}
+
+// Generate implements gensection.Directive.
+func (d *directive) Generate(f *lint.File, filePath string, line int,
+	params map[string]string, columns map[string]gensection.ColumnConfig,
+) (string, []lint.Diagnostic) {
+	if d.base == nil {
+		return "", nil
+	}
+	content, diags := d.base.Generate(f, filePath, line, d.def.expandParams(params), columns)
+	return content, d.retag(diags)
+}
+
+// checkPair validates a single marker pair and checks its body.
+func (d *directive) checkPair(f *lint.File, mp gensection.MarkerPair) []lint.Diagnostic {
+	dir, diags := gensection.ParseDirective(f.Path, mp, d.RuleID(), d.RuleName())
+	if dir == nil || len(diags) > 0 {
+		return diags
+	}
+	if diags := d.Validate(f.Path, mp.StartLine, dir.Params, dir.Columns); len(diags) > 0 {
+		return diags
+	}
+	diags = d.warnUnknownParams(f.Path, mp.StartLine, dir.Params)
+
+	expected, genDiags := d.Generate(f, f.Path, mp.StartLine, dir.Params, dir.Columns)
+	diags = append(diags, genDiags...)
+	if len(genDiags) == 0 && gensection.ExtractContent(f, mp) != expected {
+		diags = append(diags, gensection.MakeDiag(d.RuleID(), d.RuleName(),
+			f.Path, mp.StartLine, "generated section is out of date"))
+	}
+	return diags
+}
+
+// warnUnknownParams returns one warning per parameter the definition
+// does not declare, sorted by name.
+func (d *directive) warnUnknownParams(filePath string, line int, params map[string]string) []lint.Diagnostic {
+	var unknown []string
+	for k := range params {
+		if !slices.Contains(d.def.required, k) && !slices.Contains(d.def.optional, k) {
+			unknown = append(unknown, k)
+		}
+	}
+	sort.Strings(unknown)
+	diags := make([]lint.Diagnostic, 0, len(unknown))
+	for _, k := range unknown {
+		diags = append(diags, d.diag(filePath, line, lint.Warning,
+			fmt.Sprintf("unknown parameter %q", k)))
+	}
+	return diags
+}
+
+// retag reports the base directive's diagnostics under this rule,
+// prefixed with the directive name so the user sees which definition
+// they came from.
+func (d *directive) retag(diags []lint.Diagnostic) []lint.Diagnostic {
+	for i := range diags {
+		diags[i].RuleID = d.RuleID()
+		diags[i].RuleName = d.RuleName()
+		diags[i].Message = d.def.name + " directive: " + diags[i].Message
+	}
+	return diags
+}
+
+func (d *directive) diag(file string, line int, sev lint.Severity, msg string) lint.Diagnostic {
+	return lint.Diagnostic{
+		File:     file,
+		Line:     line,
+		Column:   1,
+		RuleID:   d.RuleID(),
+		RuleName: d.RuleName(),
+		Severity: sev,
+		Message:  d.def.name + " directive: " + msg,
+	}
+}
+
+// expandParams substitutes the directive's parameters into the
+// definition's `with` values. A {name} placeholder is replaced only
+// when name is a declared parameter; others, such as a catalog row's
+// front-matter fields, pass through. An entry that references a
+// declared parameter the directive leaves unset is dropped, so an
+// optional parameter can switch a base parameter on.
+func (def *definition) expandParams(params map[string]string) map[string]string {
+	out := make(map[string]string, len(def.with))
+	for k, tmpl := range def.with {
+		missing := false
+		v := placeholderRe.ReplaceAllStringFunc(tmpl, func(m string) string {
+			p := m[1 : len(m)-1]
+			if !slices.Contains(def.required, p) && !slices.Contains(def.optional, p) {
+				return m
+			}
+			val, ok := params[p]
+			if !ok {
+				missing = true
+			}
+			return val
+		})
+		if !missing {
+			out[k] = v
+		}
+	}
+	return out
+}
+
+var (
+	_ rule.FixableRule        = (*Rule)(nil)
+	_ rule.Configurable       = (*Rule)(nil)
+	_ gensection.DirectiveSet = (*Rule)(nil)
+	_ gensection.Directive    = (*directive)(nil)
+	_ gensection.Expander     = (*directive)(nil)
+)
@@ -0,0 +1,226 @@
+package userdirective
+
+import (
+	"strings"
+	"testing"
+	"testing/fstest"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/jeduden/mdsmith/internal/archetype/gensection"
+	"github.com/jeduden/mdsmith/internal/lint"
+	_ "github.com/jeduden/mdsmith/internal/rules/catalog"
+	_ "github.com/jeduden/mdsmith/internal/rules/data"
+)
+
+// teamFS holds one page per team member.
+var teamFS = fstest.MapFS{
+	"team/ada.md":   {Data: []byte("---\nname: Ada\nrole: lead\n---\n# Ada\n")},
+	"team/grace.md": {Data: []byte("---\nname: Grace\nrole: dev\n---\n# Grace\n")},
+}
+
+// roster is a directive listing team pages with an optional role
+// filter.
+var roster = map[string]any{
+	"roster": map[string]any{
+		"description": "Team members with links to their pages.",
+		"params": map[string]any{
+			"required": []any{"team"},
+			"optional": []any{"role"},
+		},
+		"expand": "catalog",
+		"with": map[string]any{
+			"glob":  "{team}/*.md",
+			"where": `role: "{role}"`,
+			"sort":  "name",
+			"row":   "- [{name}]({filename})",
+		},
+	},
+}
+
+func rosterRule(t *testing.T) *Rule {
+	t.Helper()
+	r := &Rule{}
+	require.NoError(t, r.ApplySettings(map[string]any{"directives": roster}))
+	return r
+}
+
+// newFile parses src as README.md over teamFS.
+func newFile(t *testing.T, src string) *lint.File {
+	t.Helper()
+	f, err := lint.NewFile("README.md", []byte(src))
+	require.NoError(t, err)
+	f.FS = teamFS
+	return f
+}
+
+// fixRoster runs Fix on a roster directive with the given parameter
+// lines and returns the body between the markers.
+func fixRoster(t *testing.T, params string) string {
+	t.Helper()
+	out := string(rosterRule(t).Fix(newFile(t, "# Team\n\n<?roster\n"+params+"?>\n<?/roster?>\n")))
+	start := strings.Index(out, "?>\n") + len("?>\n")
+	end := strings.LastIndex(out, "<?/roster?>")
+	return out[start:end]
+}
+
+func TestFix_ExpandsToBaseDirective(t *testing.T) {
+	got := fixRoster(t, "team: team\n")
+	assert.Equal(t, "- [Ada](team/ada.md)\n- [Grace](team/grace.md)\n", got)
+}
+
+func TestFix_OptionalParamSwitchesFilterOn(t *testing.T) {
+	got := fixRoster(t, "team: team\nrole: dev\n")
+	assert.Equal(t, "- [Grace](team/grace.md)\n", got)
+}
+
+func TestCheck_StaleAndFresh(t *testing.T) {
+	r := rosterRule(t)
+	stale := "<?roster\nteam: team\n?>\n<?/roster?>\n"
+	diags := r.Check(newFile(t, stale))
+	require.Len(t, diags, 1)
+	assert.Equal(t, "MDS081", diags[0].RuleID)
+	assert.Equal(t, "generated section is out of date", diags[0].Message)
+
+	fixed := r.Fix(newFile(t, stale))
+	assert.Empty(t, r.Check(newFile(t, string(fixed))))
+}
+
+func TestCheck_MissingRequiredParam(t *testing.T) {
+	src := "<?roster\nrole: dev\n?>\nbody\n<?/roster?>\n"
+	r := rosterRule(t)
+	diags := r.Check(newFile(t, src))
+	require.Len(t, diags, 1)
+	assert.Equal(t, lint.Error, diags[0].Severity)
+	assert.Equal(t, `roster directive: missing required parameter "team"`, diags[0].Message)
+	assert.Equal(t, src, string(r.Fix(newFile(t, src))))
+}
+
+func TestCheck_UnknownParamWarnsAndStillFixes(t *testing.T) {
+	r := rosterRule(t)
+	src := "<?roster\nteam: team\nlead: ada\n?>\n<?/roster?>\n"
+	diags := r.Check(newFile(t, src))
+	require.Len(t, diags, 2)
+	assert.Equal(t, lint.Warning, diags[0].Severity)
+	assert.Equal(t, `roster directive: unknown parameter "lead"`, diags[0].Message)
+	assert.Equal(t, "generated section is out of date", diags[1].Message)
+	assert.Contains(t, string(r.Fix(newFile(t, src))), "- [Ada](team/ada.md)")
+}
+
+func TestCheck_BaseDiagnosticsAreRetagged(t *testing.T) {
+	r := &Rule{}
+	require.NoError(t, r.ApplySettings(map[string]any{"directives": map[string]any{
+		"pages": map[string]any{"expand": "catalog", "with": map[string]any{"sort": "-"}},
+	}}))
+	diags := r.Check(newFile(t, "<?pages\n?>\n<?/pages?>\n"))
+	require.Len(t, diags, 1)
+	assert.Equal(t, "MDS081", diags[0].RuleID)
+	assert.Equal(t, "user-directive", diags[0].RuleName)
+	assert.Equal(t, `pages directive: generated section directive missing required "glob" parameter`, diags[0].Message)
+}
+
+func TestCheck_NoDefinitionsIsInert(t *testing.T) {
+	assert.Empty(t, (&Rule{}).Check(newFile(t, "<?roster\n?>\n<?/roster?>\n")))
+}
+
+func TestExpandParams(t *testing.T) {
+	def := &definition{
+		required: []string{"team"},
+		optional: []string{"role"},
+		with: map[string]string{
+			"glob":  "{team}/*.md",
+			"where": "role: {role}",
+			"row":   "- {name} in {team}",
+		},
+	}
+	assert.Equal(t, map[string]string{
+		"glob": "docs/*.md",
+		"row":  "- {name} in docs",
+	}, def.expandParams(map[string]string{"team": "docs"}))
+	assert.Equal(t, "{team}/*.md", def.expandParams(map[string]string{"team": "{team}"})["glob"])
+}
+
+func TestDirectives(t *testing.T) {
+	ds := rosterRule(t).Directives()
+	require.Len(t, ds, 1)
+	var d gensection.Directive = ds[0]
+	assert.Equal(t, "roster", d.Name())
+	assert.Equal(t, "MDS081", d.RuleID())
+	assert.Equal(t, "user-directive", d.RuleName())
+}
+
+func TestApplySettings_Errors(t *testing.T) {
+	tests := []struct {
+		name string
+		def  any
+		msg  string
+	}{
+		{"not a map", "catalog", "must be a map"},
+		{"no expand", map[string]any{}, "expand is required"},
+		{"build", map[string]any{"expand": "build"}, `expand "build" must be one of`},
+		{"unknown key", map[string]any{"expand": "tree", "template": "x"}, `unknown key "template"`},
+		{"bad param", map[string]any{"expand": "tree", "params": map[string]any{"required": []any{"a-b"}}}, `"a-b" must match`},
+		{"overlap", map[string]any{"expand": "tree", "params": map[string]any{
+			"required": []any{"a"}, "optional": []any{"a"},
+		}}, "both required and optional"},
+		{"bad with", map[string]any{"expand": "tree", "with": map[string]any{"glob": 3}}, "with.glob must be a string or a list"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := (&Rule{}).ApplySettings(map[string]any{"directives": map[string]any{"pages": tt.def}})
+			require.Error(t, err)
+			assert.Contains(t, err.Error(), tt.msg)
+		})
+	}
+	err := (&Rule{}).ApplySettings(map[string]any{"directives": map[string]any{"Pages": map[string]any{"expand": "tree"}}})
+	assert.ErrorContains(t, err, "name must match")
+	assert.ErrorContains(t, (&Rule{}).ApplySettings(map[string]any{"templates": nil}), `unknown setting "templates"`)
+}
+
+func TestParseWith_ListJoinsLines(t *testing.T) {
+	with, err := parseWith(map[string]any{"glob": []any{"a/*.md", "b/*.md"}})
+	require.NoError(t, err)
+	assert.Equal(t, "a/*.md\nb/*.md", with["glob"])
+}
+
+// metrics is a directive that renders metrics.csv through data.
+var metrics = map[string]any{
+	"metrics": map[string]any{"expand": "data", "with": map[string]any{"file": "metrics.csv"}},
+}
+
+// fixMetrics runs Fix with the given base-rules setting and returns
+// the output.
+func fixMetrics(t *testing.T, bases map[string]any) string {
+	t.Helper()
+	r := &Rule{}
+	require.NoError(t, r.ApplySettings(map[string]any{"directives": metrics, "base-rules": bases}))
+	f := newFile(t, "# Doc\n\n<?metrics\n?>\n<?/metrics?>\n")
+	f.FS = fstest.MapFS{"metrics.csv": {Data: []byte("a,b\n1,2\n")}}
+	return string(r.Fix(f))
+}
+
+func TestFix_UsesConfiguredBaseRule(t *testing.T) {
+	got := fixMetrics(t, map[string]any{"data": map[string]any{
+		"enabled": true, "settings": map[string]any{"pad": 3},
+	}})
+	assert.Contains(t, got, "|   a     |   b     |\n")
+}
+
+func TestFix_DisabledBaseRuleIsSkipped(t *testing.T) {
+	bases := map[string]any{"data": map[string]any{"enabled": false}}
+	assert.Equal(t, "# Doc\n\n<?metrics\n?>\n<?/metrics?>\n", fixMetrics(t, bases))
+
+	r := &Rule{}
+	require.NoError(t, r.ApplySettings(map[string]any{"directives": metrics, "base-rules": bases}))
+	assert.Empty(t, r.Check(newFile(t, "# Doc\n\n<?metrics\n?>\nstale\n<?/metrics?>\n")))
+}
+
+func TestApplySettings_BadBaseRules(t *testing.T) {
+	err := (&Rule{}).ApplySettings(map[string]any{"base-rules": []any{}})
+	assert.EqualError(t, err, "user-directive: base-rules must be a map, got []interface {}")
+
+	// Settings the base rule rejects leave it disabled.
+	bases := map[string]any{"data": map[string]any{"enabled": true, "settings": map[string]any{"pad": -1}}}
+	assert.Equal(t, "# Doc\n\n<?metrics\n?>\n<?/metrics?>\n", fixMetrics(t, bases))
+}
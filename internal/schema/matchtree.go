@@ -2,6 +2,7 @@ package schema
 
 import (
 	"regexp"
+	"regexp/syntax"
 	"strings"
 
 	"github.com/jeduden/mdsmith/internal/fieldinterp"
@@ -50,6 +51,85 @@ func HeadingStem(sc *Scope) (stem string, fmvars []string, hasDigits bool) {
 	return strings.TrimSpace(s), fmvars, hasDigits
 }
 
+// HeadingText renders the heading text a scope's matcher claims,
+// the inverse of HeadingStem: each `\#(digits)` takes vals["n"] and
+// each `\#(fmvar(name))` takes vals[name]. It reports false when the
+// pattern between the interpolations is not a plain literal (`.+`,
+// an alternation, a class) or a value is missing, since no single
+// heading text then follows from the scope.
+func HeadingText(sc *Scope, vals map[string]string) (string, bool) {
+	if sc == nil || sc.Matcher == nil {
+		return "", false
+	}
+	pat := sc.Matcher.Regex
+	var b strings.Builder
+	ok := true
+	literal := func(seg string) {
+		lit, isLit := regexLiteral(seg)
+		ok = ok && isLit
+		b.WriteString(lit)
+	}
+	cursor := 0
+	err := scanInterps(pat, func(expr string, start, end int) error {
+		literal(pat[cursor:start])
+		expr = strings.TrimSpace(expr)
+		name := digitsCaptureName
+		if expr != "digits" {
+			fv, isFmvar := parseFmvarCall(expr)
+			if !isFmvar {
+				ok = false
+			}
+			name = fv
+		}
+		v, found := vals[name]
+		ok = ok && found
+		b.WriteString(v)
+		cursor = end
+		return nil
+	})
+	literal(pat[cursor:])
+	if err != nil || !ok {
+		return "", false
+	}
+	return b.String(), true
+}
+
+// regexLiteral returns the text a regex fragment matches when it
+// matches exactly one string: literals, with `^` and `$` anchors
+// allowed at the ends.
+func regexLiteral(seg string) (string, bool) {
+	if seg == "" {
+		return "", true
+	}
+	re, err := syntax.Parse(seg, syntax.Perl)
+	if err != nil {
+		return "", false
+	}
+	var b strings.Builder
+	var walk func(re *syntax.Regexp) bool
+	walk = func(re *syntax.Regexp) bool {
+		switch re.Op {
+		case syntax.OpLiteral:
+			b.WriteString(string(re.Rune))
+		case syntax.OpEmptyMatch, syntax.OpBeginText, syntax.OpEndText,
+			syntax.OpBeginLine, syntax.OpEndLine:
+		case syntax.OpConcat:
+			for _, sub := range re.Sub {
+				if !walk(sub) {
+					return false
+				}
+			}
+		default:
+			return false
+		}
+		return true
+	}
+	if !walk(re) {
+		return "", false
+	}
+	return b.String(), true
+}
+
 // MatchTree is the projection-ready record of how a document's AST
 // satisfied a composed Schema. It is produced after a successful
 // schema match (extraction is gated on conformance) and consumed by
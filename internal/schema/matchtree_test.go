@@ -410,3 +410,29 @@ func TestBodyBlocksInRange_KeepsHeadings(t *testing.T) {
 	stripped := blocksInRange(blocks, 1, len(f.Lines)+1)
 	assert.Greater(t, len(body), len(stripped))
 }
+
+func TestHeadingText(t *testing.T) {
+	vals := map[string]string{"n": "3", "id": "RFC-7"}
+	cases := []struct {
+		regex string
+		want  string
+		ok    bool
+	}{
+		{`^Overview$`, "Overview", true},
+		{`Step \#(digits)`, "Step 3", true},
+		{`\#(fmvar(id)): Summary`, "RFC-7: Summary", true},
+		{`Step \#(digits)\.`, "Step 3.", true},
+		{`Step .+`, "", false},
+		{`(Usage|Examples)`, "", false},
+		{`\#(fmvar(missing))`, "", false},
+		{`\#(bogus)`, "", false},
+	}
+	for _, c := range cases {
+		got, ok := HeadingText(&Scope{Matcher: &Matcher{Regex: c.regex}}, vals)
+		assert.Equal(t, c.ok, ok, c.regex)
+		assert.Equal(t, c.want, got, c.regex)
+	}
+
+	_, ok := HeadingText(&Scope{Heading: "Lead"}, vals)
+	assert.False(t, ok)
+}
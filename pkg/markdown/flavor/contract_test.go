@@ -58,6 +58,7 @@ func TestContract_FeatureIdentity(t *testing.T) {
 		flavor.FeatureMathInline,
 		flavor.FeatureAbbreviations,
 		flavor.FeatureGitHubAlerts,
+		flavor.FeatureMermaid,
 	}
 	_ = flavor.Supports(flavor.FlavorGFM, flavor.FeatureTables)
 }
@@ -152,6 +152,7 @@ func anyDualFeatureAccepted(keep func(Feature) bool) bool {
 		FeatureFootnotes, FeatureDefinitionLists, FeatureHeadingIDs,
 		FeatureSuperscript, FeatureSubscript,
 		FeatureMathBlock, FeatureMathInline, FeatureAbbreviations,
+		FeatureMermaid,
 	} {
 		if keep(feat) {
 			return true
@@ -163,8 +164,8 @@ func anyDualFeatureAccepted(keep func(Feature) bool) bool {
 // detectFromDual walks the dual-parser tree for every feature that
 // has an AST representation: the six built-in extensions (tables,
 // strikethrough, task lists, footnotes, definition lists, heading
-// IDs) plus the five MDS034 custom extensions (superscript,
-// subscript, math block, math inline, abbreviations).
+// IDs), the five MDS034 custom extensions (superscript, subscript,
+// math block, math inline, abbreviations), and Mermaid fences.
 func detectFromDual(source []byte, lineCol func(int) (int, int), doc ast.Node) []Finding {
 	var findings []Finding
 	_ = ast.Walk(doc, func(n ast.Node, entering bool) (ast.WalkStatus, error) {
@@ -194,7 +195,8 @@ func featureFindingFor(source []byte, lineCol func(int) (int, int), n ast.Node)
 }
 
 // builtinFindingFor handles the six features detected via goldmark's
-// built-in extensions plus the heading-ID attribute parser.
+// built-in extensions plus the heading-ID attribute parser, and
+// Mermaid fences, which are plain fenced code blocks.
 func builtinFindingFor(source []byte, lineCol func(int) (int, int), n ast.Node) (*Finding, ast.WalkStatus, bool) {
 	switch node := n.(type) {
 	case *extast.Table:
@@ -224,10 +226,41 @@ func builtinFindingFor(source []byte, lineCol func(int) (int, int), n ast.Node)
 			return &hf, ast.WalkContinue, true
 		}
 		return nil, ast.WalkContinue, true
+	case *ast.FencedCodeBlock:
+		if fin, ok := mermaidFinding(source, lineCol, node); ok {
+			return &fin, ast.WalkSkipChildren, true
+		}
+		return nil, ast.WalkSkipChildren, true
 	}
 	return nil, ast.WalkContinue, false
 }
 
+// mermaidLanguage is the info-string word GitHub renders as a diagram.
+var mermaidLanguage = []byte("mermaid")
+
+// mermaidFinding reports a fenced code block tagged mermaid at column 1
+// of its opening fence line. The content lines cannot anchor it: an
+// empty block has none.
+func mermaidFinding(source []byte, lineCol func(int) (int, int), n *ast.FencedCodeBlock) (Finding, bool) {
+	if n.Info == nil {
+		return Finding{}, false
+	}
+	info := n.Info.Segment.Value(source)
+	if i := bytes.IndexAny(info, " \t{"); i >= 0 {
+		info = info[:i]
+	}
+	if !bytes.Equal(info, mermaidLanguage) {
+		return Finding{}, false
+	}
+	start := lineStartOf(source, n.Info.Segment.Start)
+	_, end := nodeByteRange(n)
+	if end < start {
+		end = start
+	}
+	line, _ := lineCol(start)
+	return Finding{Feature: FeatureMermaid, Line: line, Column: 1, Start: start, End: end}, true
+}
+
 // customFindingFor handles the five features covered by MDS034
 // custom extensions: superscript, subscript, math block / inline,
 // and abbreviations (both definition and reference).
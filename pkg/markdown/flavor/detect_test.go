@@ -267,6 +267,29 @@ func TestDetectGitHubAlertsOnlyLine(t *testing.T) {
 	assert.True(t, hasFeature(fs, FeatureGitHubAlerts))
 }
 
+func TestDetectMermaid(t *testing.T) {
+	fs := findings(t, "# T\n\n- item\n\n  ```mermaid\n  graph TD\n  ```\n\n```mermaid\n```\n")
+	var got [][2]int
+	for _, f := range fs {
+		if f.Feature == FeatureMermaid {
+			got = append(got, [2]int{f.Line, f.Column})
+		}
+	}
+	assert.Equal(t, [][2]int{{5, 1}, {9, 1}}, got)
+}
+
+func TestDetectMermaidOtherLanguagesNoMatch(t *testing.T) {
+	for _, src := range []string{
+		"```mermaidjs\ngraph TD\n```\n",
+		"```Mermaid\ngraph TD\n```\n",
+		"    mermaid\n",
+		"```\nmermaid\n```\n",
+	} {
+		fs := findings(t, src)
+		assert.False(t, hasFeature(fs, FeatureMermaid), "should not match: %q", src)
+	}
+}
+
 // --- StripBlockquoteMarkers ---
 
 func TestStripBlockquoteMarkers_Simple(t *testing.T) {
@@ -6,7 +6,7 @@
 // CommonMark + <?...?> parser. This sub-package adds the GFM and
 // pandoc-style extensions (tables, task lists, strikethrough,
 // footnotes, definition lists, heading IDs, superscript, subscript,
-// math block, inline math, abbreviations, plus GitHub alerts and
-// bare URLs scanned from the CommonMark AST) and answers "which
+// math block, inline math, abbreviations, Mermaid fences, plus GitHub
+// alerts and bare URLs scanned from the CommonMark AST) and answers "which
 // features does this document use, and which flavors accept them".
 package flavor
@@ -19,6 +19,7 @@ const (
 	FeatureMathInline
 	FeatureAbbreviations
 	FeatureGitHubAlerts
+	FeatureMermaid
 )
 
 // AllFeatures returns every tracked feature in declaration order.
@@ -37,6 +38,7 @@ func AllFeatures() []Feature {
 		FeatureMathInline,
 		FeatureAbbreviations,
 		FeatureGitHubAlerts,
+		FeatureMermaid,
 	}
 }
 
@@ -69,6 +71,8 @@ func (f Feature) Name() string {
 		return "abbreviations"
 	case FeatureGitHubAlerts:
 		return "github alerts"
+	case FeatureMermaid:
+		return "mermaid diagrams"
 	}
 	return ""
 }
@@ -78,12 +82,13 @@ func (f Feature) Name() string {
 // the enum's underlying int.
 const (
 	flavorCount  = FlavorMyST + 1
-	featureCount = FeatureGitHubAlerts + 1
+	featureCount = FeatureMermaid + 1
 )
 
 // support indexes (flavor, feature) to whether the flavor accepts it.
 // CommonMark rejects every tracked feature. GFM adds tables, task
-// lists, strikethrough, and bare-URL autolinks. The goldmark flavor
+// lists, strikethrough, bare-URL autolinks, and the alerts and Mermaid
+// diagrams GitHub renders. The goldmark flavor
 // further adds heading IDs. Pandoc, PHP Markdown Extra, MultiMarkdown,
 // and MyST each pick a different combination of the optional
 // features; FlavorAny is handled specially in Supports.
@@ -101,6 +106,7 @@ var support = [flavorCount][featureCount]bool{
 		FeatureStrikethrough:    true,
 		FeatureBareURLAutolinks: true,
 		FeatureGitHubAlerts:     true,
+		FeatureMermaid:          true,
 	},
 	FlavorGoldmark: {
 		FeatureTables:           true,
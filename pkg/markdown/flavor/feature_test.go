@@ -35,7 +35,7 @@ func TestFeatureSupportCommonMark(t *testing.T) {
 func TestFeatureSupportGFM(t *testing.T) {
 	assertSupports(t, FlavorGFM,
 		FeatureTables, FeatureTaskLists, FeatureStrikethrough,
-		FeatureBareURLAutolinks, FeatureGitHubAlerts)
+		FeatureBareURLAutolinks, FeatureGitHubAlerts, FeatureMermaid)
 }
 
 func TestFeatureSupportGoldmark(t *testing.T) {
@@ -77,8 +77,8 @@ func TestFeatureSupportMyST(t *testing.T) {
 }
 
 func TestAllFeaturesComplete(t *testing.T) {
-	// Ensure AllFeatures enumerates exactly the 13 features we track.
-	require.Len(t, AllFeatures(), 13)
+	// Ensure AllFeatures enumerates exactly the 14 features we track.
+	require.Len(t, AllFeatures(), 14)
 }
 
 // TestSupportTable_IsArrayNotMap pins the (Flavor, Feature) support
@@ -115,7 +115,7 @@ func TestSupports_OutOfRangeReturnsFalse(t *testing.T) {
 
 // TestFeatureCount_MatchesAllFeatures pins featureCount (sizing the
 // support array) against the actual number of declared Feature
-// constants. featureCount = FeatureGitHubAlerts + 1 is a "last enum
+// constants. featureCount = FeatureMermaid + 1 is a "last enum
 // value" idiom: adding a new Feature constant without also touching
 // this line would silently undersize the array, and every flavor
 // would report false for the new feature with no compile error. This
@@ -160,4 +160,5 @@ func TestFeatureName(t *testing.T) {
 	assert.Equal(t, "inline math", FeatureMathInline.Name())
 	assert.Equal(t, "abbreviations", FeatureAbbreviations.Name())
 	assert.Equal(t, "github alerts", FeatureGitHubAlerts.Name())
+	assert.Equal(t, "mermaid diagrams", FeatureMermaid.Name())
 }
@@ -33,6 +33,39 @@ func allFlavorExtensions() []goldmark.Extender {
 	}
 }
 
+// featureExtensions pairs each parser-backed feature with the
+// extender that implements it. Bare-URL autolinks, GitHub alerts,
+// and Mermaid are detected without a parser extension, and heading
+// IDs come from parser.WithAttribute, so none of them appear here.
+var featureExtensions = []struct {
+	feature Feature
+	ext     goldmark.Extender
+}{
+	{FeatureTables, extension.Table},
+	{FeatureStrikethrough, extension.Strikethrough},
+	{FeatureTaskLists, extension.TaskList},
+	{FeatureFootnotes, extension.Footnote},
+	{FeatureDefinitionLists, extension.DefinitionList},
+	{FeatureSuperscript, ext.Superscript},
+	{FeatureSubscript, ext.Subscript},
+	{FeatureMathBlock, ext.MathBlock},
+	{FeatureMathInline, ext.MathInline},
+	{FeatureAbbreviations, ext.Abbreviation},
+}
+
+// Extensions returns the extenders for the features f supports, in
+// the allFlavorExtensions order. FlavorAny gets all ten; CommonMark
+// and an invalid flavor get none.
+func Extensions(f Flavor) []goldmark.Extender {
+	var out []goldmark.Extender
+	for _, fe := range featureExtensions {
+		if Supports(f, fe.feature) {
+			out = append(out, fe.ext)
+		}
+	}
+	return out
+}
+
 // linkRefResetter is implemented by goldmark's link-reference
 // paragraph transformer. It is identified by duck typing so pool
 // callers can clear the transformer's retained document bytes
@@ -122,14 +155,31 @@ func WithSharedParser(fn func(p parser.Parser)) {
 	fn(pp.parser)
 }
 
-// newParserInternal is the single goldmark.New call site in the tree.
+// NewMarkdown returns a goldmark.Markdown whose parser is the
+// canonical flavor parser with exts installed, and whose renderer
+// carries the HTML node renderers those extensions register. It is
+// for callers that render as well as parse (`mdsmith render`); the
+// instance is not pooled, so there is no reset closure.
+func NewMarkdown(exts ...goldmark.Extender) goldmark.Markdown {
+	md, _ := newMarkdownInternal(exts)
+	return md
+}
+
+// newParserInternal returns the parser newMarkdownInternal builds,
+// for the parse-only callers.
+func newParserInternal(exts []goldmark.Extender) (parser.Parser, linkRefResetter) {
+	md, lrp := newMarkdownInternal(exts)
+	return md.Parser(), lrp
+}
+
+// newMarkdownInternal is the single goldmark.New call site in the tree.
 // It builds the canonical parser explicitly so the link-reference
 // paragraph transformer captured below is the same instance the
 // parser uses — and the only one. goldmark.New's own DefaultParser
 // would install a second link-ref transformer that the returned
 // reset closure could not reach, leaving pinned document bytes
 // alive in the pool slot.
-func newParserInternal(exts []goldmark.Extender) (parser.Parser, linkRefResetter) {
+func newMarkdownInternal(exts []goldmark.Extender) (goldmark.Markdown, linkRefResetter) {
 	defaults := parser.DefaultParagraphTransformers()
 	var lrp linkRefResetter
 	for _, pv := range defaults {
@@ -150,10 +200,11 @@ func newParserInternal(exts []goldmark.Extender) (parser.Parser, linkRefResetter
 	)
 	// goldmark.New invokes each Extender's Extend(md) hook, which
 	// calls md.Parser().AddOptions(...) to register additional
-	// block / inline parsers on the parser installed by WithParser.
-	goldmark.New(
+	// block / inline parsers on the parser installed by WithParser,
+	// and md.Renderer().AddOptions(...) for its HTML node renderers.
+	md := goldmark.New(
 		goldmark.WithParser(p),
 		goldmark.WithExtensions(exts...),
 	)
-	return p, lrp
+	return md, lrp
 }
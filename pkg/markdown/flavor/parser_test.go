@@ -1,9 +1,12 @@
 package flavor
 
 import (
+	"bytes"
 	"testing"
 
+	"github.com/jeduden/mdsmith/pkg/goldmark"
 	"github.com/jeduden/mdsmith/pkg/goldmark/ast"
+	"github.com/jeduden/mdsmith/pkg/goldmark/extension"
 	extast "github.com/jeduden/mdsmith/pkg/goldmark/extension/ast"
 	gparser "github.com/jeduden/mdsmith/pkg/goldmark/parser"
 	"github.com/jeduden/mdsmith/pkg/goldmark/text"
@@ -167,3 +170,26 @@ func containsKind(root ast.Node, kind ast.NodeKind) bool {
 	})
 	return found
 }
+
+func TestExtensionsFollowFlavorSupport(t *testing.T) {
+	assert.Empty(t, Extensions(FlavorCommonMark))
+	assert.Empty(t, Extensions(Flavor(0)))
+	assert.Len(t, Extensions(FlavorAny), len(allFlavorExtensions()))
+	// GFM: tables, strikethrough, task lists. Its bare-URL, alert,
+	// and Mermaid support needs no parser extension.
+	assert.Equal(t,
+		[]goldmark.Extender{extension.Table, extension.Strikethrough, extension.TaskList},
+		Extensions(FlavorGFM))
+}
+
+func TestNewMarkdownRendersExtensions(t *testing.T) {
+	md := NewMarkdown(Extensions(FlavorGFM)...)
+	var buf bytes.Buffer
+	require.NoError(t, md.Convert([]byte("| a |\n| - |\n| ~~b~~ |\n"), &buf))
+	assert.Contains(t, buf.String(), "<table>")
+	assert.Contains(t, buf.String(), "<del>b</del>")
+
+	buf.Reset()
+	require.NoError(t, NewMarkdown().Convert([]byte("| a |\n| - |\n"), &buf))
+	assert.NotContains(t, buf.String(), "<table>")
+}